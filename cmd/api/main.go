@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/repository"
 	"internal-transfers-system/internal/server"
+	"internal-transfers-system/internal/tracing"
+	"internal-transfers-system/internal/validator"
 	config "internal-transfers-system/pkg/config"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pankajvermacr7/go-kit/logging"
 	"github.com/pankajvermacr7/go-kit/pgx"
 	"github.com/rs/zerolog/log"
@@ -29,28 +35,47 @@ func main() {
 
 	log.Info().
 		Str("server_address", cfg.Server.Address()).
+		Str("db_driver", cfg.Database.Driver).
 		Str("db_host", cfg.Database.Host).
 		Int("db_port", cfg.Database.Port).
 		Str("db_name", cfg.Database.Database).
 		Str("log_level", cfg.Log.Level).
 		Msg("Configuration loaded successfully")
 
-	// Connect to database using go-kit
-	db, err := pgx.NewDB(cfg.Database.ToPgxConfig())
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to database")
+	// Amount precision is process-wide, so apply it before anything parses money
+	if err := models.SetMaxMoneyScale(cfg.Money.MaxScale); err != nil {
+		log.Fatal().Err(err).Msg("Invalid money configuration")
 	}
-	defer db.Close()
-	log.Info().Msg("Database connection established")
 
-	// Run migrations
-	if err := db.RunMigrationsFromDir(cfg.Database.MigrationsPath); err != nil {
-		log.Fatal().Err(err).Msg("Failed to run migrations")
+	// Like the money scale, the account ID format is process-wide; Load has checked the name
+	accountIDs, _ := validator.AccountIDValidatorByName(cfg.Account.IDValidator)
+	validator.SetAccountIDValidator(accountIDs)
+
+	// Set up trace export before anything can start spans
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Enabled:     cfg.Tracing.Enabled,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up tracing")
+	}
+	if cfg.Tracing.Enabled {
+		log.Info().Str("endpoint", cfg.Tracing.Endpoint).Float64("sample_ratio", cfg.Tracing.SampleRatio).Msg("Tracing enabled")
 	}
-	log.Info().Str("path", cfg.Database.MigrationsPath).Msg("Database migrations applied")
 
 	// Create HTTP server
-	srv := server.New(cfg.Server, db.GetPool())
+	var srv *server.Server
+	if cfg.Database.Driver == config.DBDriverMemory {
+		log.Warn().Msg("Using the in-memory store; data is lost when the server stops")
+		srv = server.NewInMemory(cfg)
+	} else {
+		pools := connectDatabase(cfg.Database)
+		defer pools.Close()
+		srv = server.NewWithPools(cfg, pools)
+	}
 
 	// Channel to listen for errors from server
 	serverErrors := make(chan error, 1)
@@ -79,5 +104,89 @@ func main() {
 		}
 	}
 
+	// Flush spans from the final requests
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	log.Info().Msg("Server stopped")
 }
+
+// connectDatabase applies the migrations and opens the connection pools. It exits if the
+// database is unreachable.
+func connectDatabase(cfg config.DatabaseConfig) repository.Pools {
+	// Run migrations through go-kit on a connection of their own
+	db, err := pgx.NewDB(cfg.ToPgxConfig())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	if err := db.RunMigrationsFromDir(cfg.MigrationsPath); err != nil {
+		log.Fatal().Err(err).Msg("Failed to run migrations")
+	}
+	db.Close()
+	log.Info().Str("path", cfg.MigrationsPath).Msg("Database migrations applied")
+
+	// Separate pools keep slow reads and exports from starving transfers of connections
+	pools := repository.Pools{Transfer: openPool(cfg, cfg.MaxConns, "transfer"), AcquireTimeout: cfg.AcquireTimeout}
+	if cfg.ReadMaxConns > 0 {
+		pools.Read = openPool(cfg, cfg.ReadMaxConns, "read")
+	}
+	if cfg.ExportMaxConns > 0 {
+		pools.Export = openPool(cfg, cfg.ExportMaxConns, "export")
+	}
+	if replica, ok := cfg.Replica(); ok {
+		pools.Replica = openPool(replica, cfg.ReplicaMaxConns, "replica")
+	}
+	return pools
+}
+
+// openPool connects a pool of up to maxConns connections and opens its MinConns before
+// returning, so the first burst of requests finds them ready. It exits if the database
+// is unreachable.
+func openPool(cfg config.DatabaseConfig, maxConns int, name string) *pgxpool.Pool {
+	poolConfig, err := cfg.PoolConfig(maxConns)
+	if err != nil {
+		log.Fatal().Err(err).Str("pool", name).Msg("Invalid database configuration")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err == nil {
+		err = warmPool(ctx, pool, int(poolConfig.MinConns))
+	}
+	if err != nil {
+		log.Fatal().Err(err).Str("pool", name).Msg("Failed to connect to database")
+	}
+
+	log.Info().
+		Str("pool", name).
+		Int32("max_conns", poolConfig.MaxConns).
+		Int32("min_conns", poolConfig.MinConns).
+		Msg("Database pool established")
+	return pool
+}
+
+// warmPool pings the database over n connections held at once, so they are all open
+// when it returns. At least one connection is always checked.
+func warmPool(ctx context.Context, pool *pgxpool.Pool, n int) error {
+	conns := make([]*pgxpool.Conn, 0, max(n, 1))
+	defer func() {
+		for _, conn := range conns {
+			conn.Release()
+		}
+	}()
+	for i := 0; i < max(n, 1); i++ {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, conn)
+		if err := conn.Ping(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
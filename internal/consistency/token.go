@@ -0,0 +1,55 @@
+// Package consistency implements read-your-writes consistency tokens.
+//
+// A token is the Postgres WAL position (LSN) observed right after a write commits.
+// Clients echo it back on later reads via the X-Consistency-Token header; a read
+// path backed by a replica must only serve the request once the replica has replayed
+// at least that far (or fall back to the primary). The primary always satisfies any token.
+package consistency
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Header is the HTTP header used to return and accept consistency tokens.
+const Header = "X-Consistency-Token"
+
+// LSN is a Postgres write-ahead log position.
+type LSN uint64
+
+// ParseLSN parses the textual pg_lsn form "XXXXXXXX/XXXXXXXX".
+func ParseLSN(s string) (LSN, error) {
+	hi, lo, ok := strings.Cut(s, "/")
+	if !ok || hi == "" || lo == "" {
+		return 0, fmt.Errorf("invalid LSN %q: expected XXX/XXX", s)
+	}
+	h, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q: %w", s, err)
+	}
+	l, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q: %w", s, err)
+	}
+	return LSN(h<<32 | l), nil
+}
+
+// String formats the LSN the same way Postgres does.
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint64(l)>>32, uint64(l)&0xFFFFFFFF)
+}
+
+type contextKey struct{}
+
+// WithToken returns a copy of ctx carrying the minimum LSN a read must observe.
+func WithToken(ctx context.Context, lsn LSN) context.Context {
+	return context.WithValue(ctx, contextKey{}, lsn)
+}
+
+// FromContext returns the token carried by ctx, if any.
+func FromContext(ctx context.Context) (LSN, bool) {
+	lsn, ok := ctx.Value(contextKey{}).(LSN)
+	return lsn, ok
+}
@@ -0,0 +1,47 @@
+package consistency
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseLSN(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LSN
+		wantErr bool
+	}{
+		{"0/0", 0, false},
+		{"0/16B3748", 0x16B3748, false},
+		{"16/B374D848", 0x16B374D848, false},
+		{"", 0, true},
+		{"16B374D848", 0, true},
+		{"zz/1", 0, true},
+		{"1/", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLSN(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr=%v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+			if !tt.wantErr && got.String() != tt.input {
+				t.Errorf("round trip: expected %s, got %s", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("expected no token")
+	}
+	ctx := WithToken(context.Background(), 42)
+	if lsn, ok := FromContext(ctx); !ok || lsn != 42 {
+		t.Errorf("expected 42, got %d (ok=%v)", lsn, ok)
+	}
+}
@@ -0,0 +1,2 @@
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS max_balance NUMERIC NULL CHECK (max_balance IS NULL OR max_balance >= 0);
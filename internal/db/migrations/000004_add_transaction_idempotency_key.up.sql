@@ -0,0 +1,6 @@
+ALTER TABLE transactions
+  ADD COLUMN IF NOT EXISTS idempotency_key TEXT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_idempotency_key
+  ON transactions (idempotency_key)
+  WHERE idempotency_key IS NOT NULL;
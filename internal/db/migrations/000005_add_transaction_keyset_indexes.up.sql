@@ -0,0 +1,6 @@
+-- Support keyset pagination (transaction_id < cursor ORDER BY transaction_id DESC) per account
+CREATE INDEX IF NOT EXISTS idx_transactions_source_id
+  ON transactions (source_account_id, transaction_id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_destination_id
+  ON transactions (destination_account_id, transaction_id DESC);
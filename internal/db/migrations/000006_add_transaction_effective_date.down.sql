@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_destination_effective_date;
+DROP INDEX IF EXISTS idx_transactions_source_effective_date;
+ALTER TABLE transactions DROP COLUMN IF EXISTS effective_date;
@@ -0,0 +1,17 @@
+-- effective_date is the bookkeeping date used for statements; created_at stays the
+-- immutable system timestamp. Existing rows take the UTC date they were created.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS effective_date DATE;
+
+UPDATE transactions
+SET effective_date = (created_at AT TIME ZONE 'UTC')::date
+WHERE effective_date IS NULL;
+
+ALTER TABLE transactions
+  ALTER COLUMN effective_date SET NOT NULL,
+  ALTER COLUMN effective_date SET DEFAULT (now() AT TIME ZONE 'UTC')::date;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_source_effective_date
+  ON transactions (source_account_id, effective_date DESC);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_destination_effective_date
+  ON transactions (destination_account_id, effective_date DESC);
@@ -0,0 +1,7 @@
+ALTER TABLE transactions
+  ADD COLUMN IF NOT EXISTS reversal_of BIGINT NULL REFERENCES transactions(transaction_id);
+
+-- A transaction can be reversed at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reversal_of
+  ON transactions (reversal_of)
+  WHERE reversal_of IS NOT NULL;
@@ -0,0 +1,10 @@
+CREATE INDEX IF NOT EXISTS idx_transactions_source_created_at
+  ON transactions (source_account_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_destination_created_at
+  ON transactions (destination_account_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_source_id
+  ON transactions (source_account_id, transaction_id DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_destination_id
+  ON transactions (destination_account_id, transaction_id DESC);
+DROP INDEX IF EXISTS idx_transactions_destination_created_at_id;
+DROP INDEX IF EXISTS idx_transactions_source_created_at_id;
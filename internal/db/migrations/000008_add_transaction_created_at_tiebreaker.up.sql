@@ -0,0 +1,12 @@
+-- Order account history by (created_at, transaction_id) so rows sharing a created_at
+-- have a stable order. These replace both the created_at-only and the id-only indexes.
+CREATE INDEX IF NOT EXISTS idx_transactions_source_created_at_id
+  ON transactions (source_account_id, created_at DESC, transaction_id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_destination_created_at_id
+  ON transactions (destination_account_id, created_at DESC, transaction_id DESC);
+
+DROP INDEX IF EXISTS idx_transactions_source_created_at;
+DROP INDEX IF EXISTS idx_transactions_destination_created_at;
+DROP INDEX IF EXISTS idx_transactions_source_id;
+DROP INDEX IF EXISTS idx_transactions_destination_id;
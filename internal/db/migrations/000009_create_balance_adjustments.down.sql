@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS balance_adjustments;
+DROP SEQUENCE IF EXISTS balance_adjustment_batch_seq;
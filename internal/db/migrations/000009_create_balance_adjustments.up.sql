@@ -0,0 +1,16 @@
+-- Batch IDs group the per-account audit rows of one bulk adjustment
+CREATE SEQUENCE IF NOT EXISTS balance_adjustment_batch_seq;
+
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+  adjustment_id BIGSERIAL PRIMARY KEY,
+  batch_id      BIGINT NOT NULL,
+  account_id    BIGINT NOT NULL REFERENCES accounts(account_id),
+  delta         NUMERIC NOT NULL CHECK (delta <> 0),
+  balance_after NUMERIC NOT NULL CHECK (balance_after >= 0),
+  reason        TEXT NOT NULL,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (batch_id, account_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_account
+  ON balance_adjustments (account_id, created_at DESC);
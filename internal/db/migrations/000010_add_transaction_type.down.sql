@@ -0,0 +1,8 @@
+-- Single-sided entries cannot be represented without the type column
+DELETE FROM transactions WHERE type <> 'transfer';
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_sides_match_type;
+ALTER TABLE transactions
+  ALTER COLUMN source_account_id SET NOT NULL,
+  ALTER COLUMN destination_account_id SET NOT NULL;
+ALTER TABLE transactions DROP COLUMN IF EXISTS type;
@@ -0,0 +1,16 @@
+-- Deposits and withdrawals are single-sided: a deposit has no source account and a
+-- withdrawal has no destination. Transfers keep both sides.
+ALTER TABLE transactions
+  ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'transfer'
+  CHECK (type IN ('transfer', 'deposit', 'withdrawal'));
+
+ALTER TABLE transactions
+  ALTER COLUMN source_account_id DROP NOT NULL,
+  ALTER COLUMN destination_account_id DROP NOT NULL;
+
+ALTER TABLE transactions
+  ADD CONSTRAINT transactions_sides_match_type CHECK (
+    (type = 'transfer'   AND source_account_id IS NOT NULL AND destination_account_id IS NOT NULL) OR
+    (type = 'deposit'    AND source_account_id IS NULL     AND destination_account_id IS NOT NULL) OR
+    (type = 'withdrawal' AND source_account_id IS NOT NULL AND destination_account_id IS NULL)
+  );
@@ -0,0 +1,2 @@
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS last_sequence BIGINT NOT NULL DEFAULT 0 CHECK (last_sequence >= 0);
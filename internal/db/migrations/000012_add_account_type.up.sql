@@ -0,0 +1,2 @@
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS account_type TEXT NOT NULL DEFAULT 'standard';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_destination_category;
+DROP INDEX IF EXISTS idx_transactions_source_category;
+ALTER TABLE transactions DROP COLUMN IF EXISTS category;
@@ -0,0 +1,7 @@
+ALTER TABLE transactions
+  ADD COLUMN IF NOT EXISTS category TEXT NULL CHECK (category IS NULL OR length(category) BETWEEN 1 AND 64);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_source_category
+  ON transactions (source_account_id, effective_date, category);
+CREATE INDEX IF NOT EXISTS idx_transactions_destination_category
+  ON transactions (destination_account_id, effective_date, category);
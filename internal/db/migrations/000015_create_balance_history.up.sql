@@ -0,0 +1,15 @@
+-- One row per balance change, written in the same transaction as the change itself.
+-- Each row comes from either a transaction or a bulk adjustment.
+CREATE TABLE IF NOT EXISTS balance_history (
+  change_id      BIGSERIAL PRIMARY KEY,
+  account_id     BIGINT NOT NULL REFERENCES accounts(account_id),
+  old_balance    NUMERIC NOT NULL,
+  new_balance    NUMERIC NOT NULL CHECK (new_balance >= 0),
+  transaction_id BIGINT NULL REFERENCES transactions(transaction_id),
+  adjustment_id  BIGINT NULL REFERENCES balance_adjustments(adjustment_id),
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((transaction_id IS NULL) <> (adjustment_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_history_account
+  ON balance_history (account_id, change_id DESC);
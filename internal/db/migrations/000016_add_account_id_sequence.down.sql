@@ -0,0 +1,2 @@
+ALTER TABLE accounts ALTER COLUMN account_id DROP DEFAULT;
+DROP SEQUENCE IF EXISTS accounts_account_id_seq;
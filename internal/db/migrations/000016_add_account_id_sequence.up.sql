@@ -0,0 +1,9 @@
+-- Generated account IDs for clients that don't supply one. The sequence starts after the
+-- highest existing ID; client-supplied IDs can still land ahead of it, so inserts that use
+-- it skip over taken values (see AccountRepository.CreateWithGeneratedID).
+CREATE SEQUENCE IF NOT EXISTS accounts_account_id_seq OWNED BY accounts.account_id;
+
+SELECT setval('accounts_account_id_seq', COALESCE((SELECT MAX(account_id) FROM accounts), 0) + 1, false);
+
+ALTER TABLE accounts
+  ALTER COLUMN account_id SET DEFAULT nextval('accounts_account_id_seq');
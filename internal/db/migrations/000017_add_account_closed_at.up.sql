@@ -0,0 +1,4 @@
+-- Accounts are closed, never deleted: closed_at is set once and the row (with its history)
+-- stays readable.
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ NULL;
@@ -0,0 +1,21 @@
+-- Funds reserved by holds are tracked on the account so the available balance
+-- (balance - held_balance) can be checked under the same row lock as the balance.
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS held_balance NUMERIC NOT NULL DEFAULT 0;
+
+ALTER TABLE accounts
+  ADD CONSTRAINT accounts_held_balance_check CHECK (held_balance >= 0 AND held_balance <= balance);
+
+-- One row per hold. A hold is resolved once: released, or captured into a withdrawal.
+CREATE TABLE IF NOT EXISTS holds (
+  hold_id        BIGSERIAL PRIMARY KEY,
+  account_id     BIGINT NOT NULL REFERENCES accounts(account_id),
+  amount         NUMERIC NOT NULL CHECK (amount > 0),
+  status         TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'released', 'captured')),
+  transaction_id BIGINT NULL REFERENCES transactions(transaction_id),
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  resolved_at    TIMESTAMPTZ NULL,
+  CHECK ((status = 'captured') = (transaction_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_holds_account ON holds (account_id, hold_id DESC);
@@ -0,0 +1,15 @@
+-- Fails while any account, history entry, or adjustment record has a negative balance.
+ALTER TABLE balance_adjustments
+  ADD CONSTRAINT balance_adjustments_balance_after_check CHECK (balance_after >= 0);
+ALTER TABLE balance_history
+  ADD CONSTRAINT balance_history_new_balance_check CHECK (new_balance >= 0);
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_held_balance_check;
+ALTER TABLE accounts
+  ADD CONSTRAINT accounts_held_balance_check CHECK (held_balance >= 0 AND held_balance <= balance);
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_balance_check;
+ALTER TABLE accounts
+  ADD CONSTRAINT accounts_balance_check CHECK (balance >= 0);
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS overdraft_limit;
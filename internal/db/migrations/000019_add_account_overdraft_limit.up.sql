@@ -0,0 +1,17 @@
+-- Accounts may go negative down to -overdraft_limit. The default of 0 keeps the
+-- original balance >= 0 rule for every existing account.
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS overdraft_limit NUMERIC NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0);
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_balance_check;
+ALTER TABLE accounts
+  ADD CONSTRAINT accounts_balance_check CHECK (balance >= -overdraft_limit);
+
+-- Holds may reserve overdraft too
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_held_balance_check;
+ALTER TABLE accounts
+  ADD CONSTRAINT accounts_held_balance_check CHECK (held_balance >= 0 AND held_balance <= balance + overdraft_limit);
+
+-- Recorded balances may now be negative; the accounts constraint bounds them
+ALTER TABLE balance_history DROP CONSTRAINT IF EXISTS balance_history_new_balance_check;
+ALTER TABLE balance_adjustments DROP CONSTRAINT IF EXISTS balance_adjustments_balance_after_check;
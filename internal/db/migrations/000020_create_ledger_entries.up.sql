@@ -0,0 +1,28 @@
+-- Double-entry postings: every transaction debits its source and credits its
+-- destination. Amounts are signed from the account's point of view (debits negative,
+-- credits positive), so a transfer's entries sum to zero and an account's entries sum
+-- to its net movement. Deposits and withdrawals post only their one side.
+CREATE TABLE IF NOT EXISTS ledger_entries (
+  entry_id       BIGSERIAL PRIMARY KEY,
+  transaction_id BIGINT NOT NULL REFERENCES transactions(transaction_id),
+  account_id     BIGINT NOT NULL REFERENCES accounts(account_id),
+  amount         NUMERIC NOT NULL CHECK (amount <> 0),
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (transaction_id, account_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account
+  ON ledger_entries (account_id, entry_id DESC);
+
+-- Backfill existing transactions, debit first
+INSERT INTO ledger_entries (transaction_id, account_id, amount, created_at)
+SELECT transaction_id, account_id, amount, created_at
+FROM (
+  SELECT transaction_id, source_account_id AS account_id, -amount AS amount, created_at, 0 AS side
+  FROM transactions WHERE source_account_id IS NOT NULL
+  UNION ALL
+  SELECT transaction_id, destination_account_id, amount, created_at, 1
+  FROM transactions WHERE destination_account_id IS NOT NULL
+) postings
+ORDER BY transaction_id, side
+ON CONFLICT DO NOTHING;
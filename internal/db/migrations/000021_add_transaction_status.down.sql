@@ -0,0 +1,8 @@
+DELETE FROM transactions WHERE status = 'failed';
+
+DROP INDEX IF EXISTS idx_transactions_reversal_of;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reversal_of
+  ON transactions (reversal_of)
+  WHERE reversal_of IS NOT NULL;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS status;
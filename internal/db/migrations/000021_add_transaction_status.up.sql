@@ -0,0 +1,12 @@
+-- Every transaction recorded so far moved its funds, so existing rows are completed;
+-- new rows default to pending until the writer says otherwise.
+ALTER TABLE transactions
+  ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'completed'
+  CHECK (status IN ('pending', 'completed', 'failed'));
+ALTER TABLE transactions ALTER COLUMN status SET DEFAULT 'pending';
+
+-- A failed reversal attempt must not block the real one
+DROP INDEX IF EXISTS idx_transactions_reversal_of;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reversal_of
+  ON transactions (reversal_of)
+  WHERE reversal_of IS NOT NULL AND status <> 'failed';
@@ -0,0 +1,4 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_fee_consistent;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee_paid_by;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee_account_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee_amount;
@@ -0,0 +1,14 @@
+-- Fees are only charged on transfers. The fee account, payer, and amount are set
+-- together, and a fee paid by the destination must leave it something to receive.
+ALTER TABLE transactions
+  ADD COLUMN IF NOT EXISTS fee_amount NUMERIC NOT NULL DEFAULT 0 CHECK (fee_amount >= 0),
+  ADD COLUMN IF NOT EXISTS fee_account_id BIGINT NULL REFERENCES accounts(account_id),
+  ADD COLUMN IF NOT EXISTS fee_paid_by TEXT NULL CHECK (fee_paid_by IN ('source', 'destination'));
+
+ALTER TABLE transactions
+  ADD CONSTRAINT transactions_fee_consistent CHECK (
+    (fee_amount = 0 AND fee_account_id IS NULL AND fee_paid_by IS NULL) OR
+    (fee_amount > 0 AND fee_account_id IS NOT NULL AND fee_paid_by IS NOT NULL AND type = 'transfer'
+      AND fee_account_id <> source_account_id AND fee_account_id <> destination_account_id
+      AND (fee_paid_by = 'source' OR fee_amount < amount))
+  );
@@ -0,0 +1,25 @@
+-- One row per schedule. next_run_at is advanced by the scheduler after each run; the
+-- partial index keeps the due-runs scan to active schedules.
+CREATE TABLE IF NOT EXISTS recurring_transfers (
+  recurring_id           BIGSERIAL PRIMARY KEY,
+  source_account_id      BIGINT NOT NULL REFERENCES accounts(account_id),
+  destination_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+  amount                 NUMERIC NOT NULL CHECK (amount > 0),
+  category               TEXT NULL CHECK (category IS NULL OR length(category) BETWEEN 1 AND 64),
+  interval_seconds       BIGINT NOT NULL CHECK (interval_seconds >= 60),
+  next_run_at            TIMESTAMPTZ NOT NULL,
+  end_at                 TIMESTAMPTZ NULL,
+  status                 TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'canceled')),
+  runs                   INT NOT NULL DEFAULT 0,
+  last_run_at            TIMESTAMPTZ NULL,
+  last_transaction_id    BIGINT NULL REFERENCES transactions(transaction_id),
+  last_error             TEXT NULL,
+  created_at             TIMESTAMPTZ NOT NULL DEFAULT now(),
+  canceled_at            TIMESTAMPTZ NULL,
+  CHECK (source_account_id <> destination_account_id),
+  CHECK ((status = 'canceled') = (canceled_at IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_transfers_due
+  ON recurring_transfers (next_run_at)
+  WHERE status = 'active';
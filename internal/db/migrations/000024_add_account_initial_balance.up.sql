@@ -0,0 +1,14 @@
+-- The balance an account was opened with, so the ledger can be checked against it:
+-- balance = initial_balance + ledger entries + balance adjustments. Existing accounts
+-- are taken to be consistent now, so their initial balance is whatever their recorded
+-- movements don't explain.
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS initial_balance NUMERIC NULL;
+
+UPDATE accounts a
+SET initial_balance = a.balance
+  - COALESCE((SELECT SUM(amount) FROM ledger_entries e WHERE e.account_id = a.account_id), 0)
+  - COALESCE((SELECT SUM(delta) FROM balance_adjustments b WHERE b.account_id = a.account_id), 0)
+WHERE initial_balance IS NULL;
+
+ALTER TABLE accounts ALTER COLUMN initial_balance SET NOT NULL;
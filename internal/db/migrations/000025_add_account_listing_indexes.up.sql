@@ -0,0 +1,7 @@
+-- Back the admin account listing's sort orders, with account_id as the tie-breaker so
+-- offset pages stay stable between rows sharing a balance or created_at.
+CREATE INDEX IF NOT EXISTS idx_accounts_balance_id
+  ON accounts (balance, account_id);
+
+CREATE INDEX IF NOT EXISTS idx_accounts_created_at_id
+  ON accounts (created_at, account_id);
@@ -0,0 +1,3 @@
+ALTER TABLE accounts
+  DROP COLUMN IF EXISTS daily_transfer_count_limit,
+  DROP COLUMN IF EXISTS daily_transfer_amount_limit;
@@ -0,0 +1,5 @@
+-- Per-account overrides of the configured daily transfer limits. Null uses the
+-- configured limit; 0 lifts it for the account.
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS daily_transfer_amount_limit NUMERIC NULL CHECK (daily_transfer_amount_limit >= 0),
+  ADD COLUMN IF NOT EXISTS daily_transfer_count_limit INTEGER NULL CHECK (daily_transfer_count_limit >= 0);
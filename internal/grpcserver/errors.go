@@ -0,0 +1,70 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/validator"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the ErrorInfo domain attached to domain errors. The ErrorInfo reason is
+// the same error code the HTTP API returns, e.g. "insufficient_balance".
+const ErrorDomain = "internal-transfers-system"
+
+const internalMessage = "An unexpected error occurred. Please try again later."
+
+// errorStatus converts a service error to a gRPC status. Domain errors keep their message
+// and carry their code in an ErrorInfo detail; anything else is logged and reported as
+// INTERNAL without details.
+func errorStatus(ctx context.Context, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	var domainErr *models.DomainError
+	if !errors.As(err, &domainErr) {
+		logging.FromContext(ctx).Error().Err(err).Msg("Internal error")
+		return status.Error(codes.Internal, internalMessage)
+	}
+	code := domainCode(domainErr.Code)
+	if code == codes.Internal {
+		logging.FromContext(ctx).Error().Err(err).Str("code", string(domainErr.Code)).Msg("Internal error")
+		return status.Error(codes.Internal, internalMessage)
+	}
+
+	st := status.New(code, domainErr.Message)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: string(domainErr.Code), Domain: ErrorDomain}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// domainCode maps a domain error code to the gRPC code registered for it in models.
+// Unregistered and non-public codes are INTERNAL.
+func domainCode(code models.ErrorCode) codes.Code {
+	s, ok := models.StatusOf(code)
+	if !ok || !s.Public {
+		return codes.Internal
+	}
+	return s.GRPC
+}
+
+// validationStatus reports errs as INVALID_ARGUMENT with a BadRequest detail listing
+// every field violation, the counterpart of the HTTP API's validation_failed response.
+func validationStatus(errs validator.ValidationErrors) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(errs))
+	for i, e := range errs {
+		violations[i] = &errdetails.BadRequest_FieldViolation{Field: e.Field, Description: e.Message}
+	}
+	st := status.New(codes.InvalidArgument, errs.Error())
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,68 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"internal-transfers-system/internal/grpcserver/transferspb"
+	"internal-transfers-system/internal/logging"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// rateLimitedReason is the ErrorInfo reason of a call rejected by a rate limit, the same
+// error code the HTTP API returns with 429.
+const rateLimitedReason = "rate_limited"
+
+// rateLimitInterceptor applies Options.AccountCreateLimit to CreateAccount calls, keyed by
+// the caller's IP like the HTTP account creation limit. A call over the limit fails with
+// RESOURCE_EXHAUSTED and a RetryInfo detail saying how long to wait.
+func (s *Server) rateLimitInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if s.accountCreateLimit == nil || info.FullMethod != transferspb.Transfers_CreateAccount_FullMethodName {
+		return handler(ctx, req)
+	}
+
+	client := peerIP(ctx)
+	if delay := s.accountCreateLimit(client); delay > 0 {
+		logging.FromContext(ctx).Debug().
+			Str("client", client).
+			Dur("retry_after", delay).
+			Msg("Rate limit exceeded")
+		return nil, rateLimitedStatus(delay)
+	}
+	return handler(ctx, req)
+}
+
+// rateLimitedStatus is the RESOURCE_EXHAUSTED status for a call that may be retried after
+// delay.
+func rateLimitedStatus(delay time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "Too many requests. Please retry later.")
+	detailed, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: rateLimitedReason, Domain: ErrorDomain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)},
+	)
+	if err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// peerIP returns the host part of the caller's address, or the whole address if it has
+// no port.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,258 @@
+// Package grpcserver serves account and transfer operations over gRPC, alongside the HTTP
+// API. It calls the same services, so both transports share validation, business rules,
+// and the database pools.
+package grpcserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"internal-transfers-system/internal/grpcserver/transferspb"
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+	"internal-transfers-system/internal/validator"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDMetadata is the metadata key carrying a caller's request ID into the logs. A
+// call without one is given a random ID.
+const RequestIDMetadata = "x-request-id"
+
+// maxIdempotencyKeyLength matches the limit on the HTTP Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
+
+// Options configures a Server.
+type Options struct {
+	// ValidationMode is the request validation mode.
+	ValidationMode validator.Mode
+
+	// FixedAmounts renders amounts with at least MinorUnits decimal places, as the HTTP
+	// handler option of the same name does.
+	FixedAmounts bool
+	MinorUnits   int
+
+	// AccountCreateLimit, when set, is asked before each CreateAccount call with the
+	// caller's IP. It returns 0 to admit the call, or how long the caller should wait, in
+	// which case the call fails with RESOURCE_EXHAUSTED. The server shares it with the
+	// HTTP account creation limit, so both transports draw on the same per-client budget.
+	AccountCreateLimit func(client string) time.Duration
+}
+
+// Server implements transferspb.TransfersServer on top of the account and transfer
+// services.
+type Server struct {
+	transferspb.UnimplementedTransfersServer
+
+	accountService  *service.AccountService
+	transferService *service.TransferService
+	validationMode  validator.Mode
+	money           func(decimal.Decimal) string
+
+	accountCreateLimit func(client string) time.Duration
+}
+
+// New returns a Server calling accountService and transferService.
+func New(accountService *service.AccountService, transferService *service.TransferService, opts Options) *Server {
+	money := models.FormatMoney
+	if opts.FixedAmounts {
+		minorUnits := opts.MinorUnits
+		money = func(d decimal.Decimal) string {
+			return models.FormatMoneyFixed(d, minorUnits)
+		}
+	}
+	return &Server{
+		accountService:  accountService,
+		transferService: transferService,
+		validationMode:  opts.ValidationMode,
+		money:           money,
+
+		accountCreateLimit: opts.AccountCreateLimit,
+	}
+}
+
+// NewGRPCServer returns a grpc.Server serving s and the standard health service. Every
+// call gets a request-scoped logger and is logged on completion; a panic fails only the
+// call, with INTERNAL. CreateAccount calls are subject to Options.AccountCreateLimit.
+func NewGRPCServer(s *Server, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(loggingInterceptor, recoveryInterceptor, s.rateLimitInterceptor))
+	gs := grpc.NewServer(opts...)
+	transferspb.RegisterTransfersServer(gs, s)
+	healthpb.RegisterHealthServer(gs, health.NewServer())
+	return gs
+}
+
+// CreateAccount opens an account.
+func (s *Server) CreateAccount(ctx context.Context, in *transferspb.CreateAccountRequest) (*transferspb.Account, error) {
+	req := &models.CreateAccountRequest{
+		AccountID:      in.GetAccountId(),
+		InitialBalance: in.GetInitialBalance(),
+		MaxBalance:     in.GetMaxBalance(),
+		AccountType:    in.GetAccountType(),
+		OverdraftLimit: in.GetOverdraftLimit(),
+	}
+	if errs := validator.ValidateCreateAccountWithMode(req, s.validationMode); len(errs) > 0 {
+		return nil, validationStatus(errs)
+	}
+
+	account, err := s.accountService.CreateAccount(ctx, req)
+	if err != nil {
+		return nil, errorStatus(ctx, err)
+	}
+	return s.newAccount(account), nil
+}
+
+// GetAccount returns an account.
+func (s *Server) GetAccount(ctx context.Context, in *transferspb.GetAccountRequest) (*transferspb.Account, error) {
+	if in.GetAccountId() <= 0 {
+		return nil, validationStatus(validator.ValidationErrors{{Field: "account_id", Message: "must be a positive integer"}})
+	}
+
+	account, err := s.accountService.GetAccount(ctx, in.GetAccountId())
+	if err != nil {
+		return nil, errorStatus(ctx, err)
+	}
+	return s.newAccount(account), nil
+}
+
+// CreateTransaction moves funds between two accounts.
+func (s *Server) CreateTransaction(ctx context.Context, in *transferspb.CreateTransactionRequest) (*transferspb.Transaction, error) {
+	req := &models.CreateTransactionRequest{
+		SourceAccountID:      in.GetSourceAccountId(),
+		DestinationAccountID: in.GetDestinationAccountId(),
+		Amount:               in.GetAmount(),
+		EffectiveDate:        in.GetEffectiveDate(),
+		Category:             in.GetCategory(),
+		IdempotencyKey:       in.GetIdempotencyKey(),
+	}
+	if sequence := in.GetSequence(); sequence != 0 {
+		req.Sequence = &sequence
+	}
+	if len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+		return nil, validationStatus(validator.ValidationErrors{{Field: "idempotency_key", Message: "must be at most 255 characters"}})
+	}
+	if errs := validator.ValidateCreateTransactionWithMode(req, s.validationMode); len(errs) > 0 {
+		return nil, validationStatus(errs)
+	}
+
+	txn, err := s.transferService.Transfer(ctx, req)
+	if err != nil {
+		return nil, errorStatus(ctx, err)
+	}
+	return s.newTransaction(txn), nil
+}
+
+// GetTransaction returns a transaction.
+func (s *Server) GetTransaction(ctx context.Context, in *transferspb.GetTransactionRequest) (*transferspb.Transaction, error) {
+	if in.GetTransactionId() <= 0 {
+		return nil, validationStatus(validator.ValidationErrors{{Field: "transaction_id", Message: "must be a positive integer"}})
+	}
+
+	txn, err := s.transferService.GetTransaction(ctx, in.GetTransactionId())
+	if err != nil {
+		return nil, errorStatus(ctx, err)
+	}
+	return s.newTransaction(txn), nil
+}
+
+func (s *Server) newAccount(account *models.Account) *transferspb.Account {
+	resp := &transferspb.Account{
+		AccountId:        account.AccountID,
+		Balance:          s.money(account.Balance),
+		HeldBalance:      s.money(account.HeldBalance),
+		OverdraftLimit:   s.money(account.OverdraftLimit),
+		AvailableBalance: s.money(account.AvailableBalance()),
+		AccountType:      account.AccountType,
+		Status:           account.Status(),
+	}
+	if account.MaxBalance.Valid {
+		resp.MaxBalance = s.money(account.MaxBalance.Decimal)
+	}
+	if account.ClosedAt != nil {
+		resp.ClosedAt = account.ClosedAt.UTC().Format(models.TimestampLayout)
+	}
+	return resp
+}
+
+func (s *Server) newTransaction(txn *models.Transaction) *transferspb.Transaction {
+	resp := &transferspb.Transaction{
+		TransactionId:        txn.TransactionID,
+		Type:                 string(txn.Type),
+		SourceAccountId:      txn.SourceAccountID,
+		DestinationAccountId: txn.DestinationAccountID,
+		Amount:               s.money(txn.Amount),
+		EffectiveDate:        txn.EffectiveDate.Format(models.DateLayout),
+		Category:             txn.Category,
+		Status:               string(txn.Status),
+		FeeAmount:            s.money(txn.FeeAmount),
+		FeeAccountId:         txn.FeeAccountID,
+		FeePaidBy:            string(txn.FeePaidBy),
+		CreatedAt:            txn.CreatedAt.UTC().Format(models.TimestampLayout),
+		Replayed:             txn.Replayed,
+	}
+	if txn.ReversalOf != nil {
+		resp.ReversalOf = *txn.ReversalOf
+	}
+	return resp
+}
+
+// loggingInterceptor gives each call a logger tagged with its request ID and logs the
+// outcome, the gRPC counterpart of the HTTP logging middleware.
+func loggingInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDMetadata); len(ids) > 0 {
+			requestID = ids[0]
+		}
+	}
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	ctx = logging.WithRequestID(ctx, requestID)
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	event := logging.FromContext(ctx).Info()
+	if code == codes.Internal || code == codes.Unknown {
+		event = logging.FromContext(ctx).Error()
+	}
+	event.
+		Str("method", info.FullMethod).
+		Str("code", code.String()).
+		Dur("duration", time.Since(start)).
+		Msg("gRPC call completed")
+	return resp, err
+}
+
+// recoveryInterceptor turns a panic in a handler into an INTERNAL error for that call.
+func recoveryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			logging.FromContext(ctx).Error().
+				Interface("panic", p).
+				Str("method", info.FullMethod).
+				Msg("Panic recovered in gRPC handler")
+			err = status.Error(codes.Internal, internalMessage)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "req-fallback"
+	}
+	return hex.EncodeToString(b)
+}
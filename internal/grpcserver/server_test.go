@@ -0,0 +1,242 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/grpcserver/transferspb"
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+	"internal-transfers-system/internal/validator"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a Server backed by accRepo over an in-memory connection and
+// returns a client for it.
+func newTestClient(t *testing.T, accRepo *mocks.MockAccountRepository) transferspb.TransfersClient {
+	t.Helper()
+	return newTestClientWithOptions(t, accRepo, Options{ValidationMode: validator.CollectAll})
+}
+
+// newTestClientWithOptions is newTestClient for a Server configured with opts.
+func newTestClientWithOptions(t *testing.T, accRepo *mocks.MockAccountRepository, opts Options) transferspb.TransfersClient {
+	t.Helper()
+	txnRepo := mocks.NewMockTransactionRepository()
+	srv := New(service.NewAccountService(accRepo), service.NewTransferService(accRepo, txnRepo), opts)
+
+	lis := bufconn.Listen(1 << 20)
+	gs := NewGRPCServer(srv)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return transferspb.NewTransfersClient(conn)
+}
+
+// errorReason returns the ErrorInfo reason attached to err, if any.
+func errorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
+
+func TestCreateTransaction(t *testing.T) {
+	ctx := context.Background()
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+	client := newTestClient(t, accRepo)
+
+	txn, err := client.CreateTransaction(ctx, &transferspb.CreateTransactionRequest{
+		SourceAccountId: 1, DestinationAccountId: 2, Amount: "40.50", IdempotencyKey: "grpc-1",
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	if txn.GetTransactionId() == 0 || txn.GetAmount() != "40.5" || txn.GetStatus() != string(models.TransactionStatusCompleted) || txn.GetReplayed() {
+		t.Errorf("unexpected transaction %+v", txn)
+	}
+	src, _ := accRepo.GetAccount(1)
+	dst, _ := accRepo.GetAccount(2)
+	if src.Balance.String() != "59.5" || dst.Balance.String() != "40.5" {
+		t.Errorf("expected balances 59.5 and 40.5, got %s and %s", src.Balance, dst.Balance)
+	}
+
+	replay, err := client.CreateTransaction(ctx, &transferspb.CreateTransactionRequest{
+		SourceAccountId: 1, DestinationAccountId: 2, Amount: "40.50", IdempotencyKey: "grpc-1",
+	})
+	if err != nil || !replay.GetReplayed() || replay.GetTransactionId() != txn.GetTransactionId() {
+		t.Errorf("expected the original transaction replayed, got %+v, %v", replay, err)
+	}
+
+	got, err := client.GetTransaction(ctx, &transferspb.GetTransactionRequest{TransactionId: txn.GetTransactionId()})
+	if err != nil || got.GetAmount() != "40.5" || got.GetSourceAccountId() != 1 {
+		t.Errorf("expected the transaction back, got %+v, %v", got, err)
+	}
+
+	tests := []struct {
+		name       string
+		req        *transferspb.CreateTransactionRequest
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{"insufficient balance", &transferspb.CreateTransactionRequest{SourceAccountId: 1, DestinationAccountId: 2, Amount: "1000"},
+			codes.FailedPrecondition, string(models.CodeInsufficientBalance)},
+		{"unknown account", &transferspb.CreateTransactionRequest{SourceAccountId: 1, DestinationAccountId: 9, Amount: "1"},
+			codes.NotFound, string(models.CodeAccountNotFound)},
+		{"invalid request", &transferspb.CreateTransactionRequest{SourceAccountId: 1, DestinationAccountId: 1, Amount: "-1"},
+			codes.InvalidArgument, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.CreateTransaction(ctx, tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("expected %s, got %s: %v", tt.wantCode, code, err)
+			}
+			if reason := errorReason(err); reason != tt.wantReason {
+				t.Errorf("expected reason %q, got %q", tt.wantReason, reason)
+			}
+		})
+	}
+
+	src, _ = accRepo.GetAccount(1)
+	if src.Balance.String() != "59.5" {
+		t.Errorf("expected rejected transfers to leave the source at 59.5, got %s", src.Balance)
+	}
+}
+
+func TestCreateTransaction_ValidationDetails(t *testing.T) {
+	client := newTestClient(t, mocks.NewMockAccountRepository())
+
+	_, err := client.CreateTransaction(context.Background(), &transferspb.CreateTransactionRequest{Amount: "abc"})
+	var fields []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	if status.Code(err) != codes.InvalidArgument || len(fields) < 3 {
+		t.Errorf("expected INVALID_ARGUMENT naming every bad field, got %v with fields %v", err, fields)
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, mocks.NewMockAccountRepository())
+
+	created, err := client.CreateAccount(ctx, &transferspb.CreateAccountRequest{AccountId: 7, InitialBalance: "25.00"})
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	if created.GetAccountId() != 7 || created.GetBalance() != "25" || created.GetStatus() != models.AccountStatusOpen {
+		t.Errorf("unexpected account %+v", created)
+	}
+
+	_, err = client.CreateAccount(ctx, &transferspb.CreateAccountRequest{AccountId: 7, InitialBalance: "1"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("duplicate account: expected ALREADY_EXISTS, got %v", err)
+	}
+
+	got, err := client.GetAccount(ctx, &transferspb.GetAccountRequest{AccountId: 7})
+	if err != nil || got.GetBalance() != "25" {
+		t.Errorf("expected account 7 with balance 25, got %+v, %v", got, err)
+	}
+	if _, err := client.GetAccount(ctx, &transferspb.GetAccountRequest{AccountId: 8}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown account: expected NOT_FOUND, got %v", err)
+	}
+	if _, err := client.GetAccount(ctx, &transferspb.GetAccountRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("missing ID: expected INVALID_ARGUMENT, got %v", err)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"database timeout", models.WrapError(models.CodeDatabaseError, "failed to get account", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{"plain error", net.ErrClosed, codes.Internal},
+		{"canceled", context.Canceled, codes.Canceled},
+		{"concurrent modification", models.NewDomainError(models.CodeConcurrentModified, "retry"), codes.Aborted},
+		{"blackout", models.ErrTransferBlackout, codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(errorStatus(ctx, tt.err)); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	err := errorStatus(ctx, models.WrapError(models.CodeDatabaseError, "failed to get account", net.ErrClosed))
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != internalMessage || len(st.Details()) != 0 {
+		t.Errorf("expected a bare INTERNAL status, got %v", st)
+	}
+}
+
+func TestCreateAccount_RateLimited(t *testing.T) {
+	ctx := context.Background()
+	var clients []string
+	client := newTestClientWithOptions(t, mocks.NewMockAccountRepository(), Options{
+		ValidationMode: validator.CollectAll,
+		// Admits the first call and rejects the rest
+		AccountCreateLimit: func(client string) time.Duration {
+			clients = append(clients, client)
+			if len(clients) > 1 {
+				return 3 * time.Second
+			}
+			return 0
+		},
+	})
+
+	if _, err := client.CreateAccount(ctx, &transferspb.CreateAccountRequest{AccountId: 1, InitialBalance: "1"}); err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+	_, err := client.CreateAccount(ctx, &transferspb.CreateAccountRequest{AccountId: 2, InitialBalance: "1"})
+	if status.Code(err) != codes.ResourceExhausted || errorReason(err) != "rate_limited" {
+		t.Fatalf("second create: expected RESOURCE_EXHAUSTED rate_limited, got %v", err)
+	}
+	var retryDelay time.Duration
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			retryDelay = info.GetRetryDelay().AsDuration()
+		}
+	}
+	if retryDelay != 3*time.Second {
+		t.Errorf("expected a 3s retry delay, got %s", retryDelay)
+	}
+
+	// Other methods are not subject to the account creation limit
+	if _, err := client.GetAccount(ctx, &transferspb.GetAccountRequest{AccountId: 1}); err != nil {
+		t.Errorf("get account: %v", err)
+	}
+	if len(clients) != 2 {
+		t.Errorf("expected the limit consulted for the 2 creates only, got %d calls", len(clients))
+	}
+}
@@ -0,0 +1,671 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: internal/grpcserver/transferspb/transfers.proto
+
+package transferspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateAccountRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Zero has the server generate an ID.
+	AccountId      int64  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	InitialBalance string `protobuf:"bytes,2,opt,name=initial_balance,json=initialBalance,proto3" json:"initial_balance,omitempty"`
+	// Empty means no balance ceiling.
+	MaxBalance string `protobuf:"bytes,3,opt,name=max_balance,json=maxBalance,proto3" json:"max_balance,omitempty"`
+	// Empty means "standard".
+	AccountType string `protobuf:"bytes,4,opt,name=account_type,json=accountType,proto3" json:"account_type,omitempty"`
+	// Empty means no overdraft.
+	OverdraftLimit string `protobuf:"bytes,5,opt,name=overdraft_limit,json=overdraftLimit,proto3" json:"overdraft_limit,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateAccountRequest) Reset() {
+	*x = CreateAccountRequest{}
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountRequest) ProtoMessage() {}
+
+func (x *CreateAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountRequest.ProtoReflect.Descriptor instead.
+func (*CreateAccountRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcserver_transferspb_transfers_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateAccountRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *CreateAccountRequest) GetInitialBalance() string {
+	if x != nil {
+		return x.InitialBalance
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetMaxBalance() string {
+	if x != nil {
+		return x.MaxBalance
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetAccountType() string {
+	if x != nil {
+		return x.AccountType
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetOverdraftLimit() string {
+	if x != nil {
+		return x.OverdraftLimit
+	}
+	return ""
+}
+
+type GetAccountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountRequest) Reset() {
+	*x = GetAccountRequest{}
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountRequest) ProtoMessage() {}
+
+func (x *GetAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcserver_transferspb_transfers_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetAccountRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+type Account struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AccountId        int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Balance          string                 `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	HeldBalance      string                 `protobuf:"bytes,3,opt,name=held_balance,json=heldBalance,proto3" json:"held_balance,omitempty"`
+	OverdraftLimit   string                 `protobuf:"bytes,4,opt,name=overdraft_limit,json=overdraftLimit,proto3" json:"overdraft_limit,omitempty"`
+	AvailableBalance string                 `protobuf:"bytes,5,opt,name=available_balance,json=availableBalance,proto3" json:"available_balance,omitempty"`
+	// Empty when the account has no balance ceiling.
+	MaxBalance  string `protobuf:"bytes,6,opt,name=max_balance,json=maxBalance,proto3" json:"max_balance,omitempty"`
+	AccountType string `protobuf:"bytes,7,opt,name=account_type,json=accountType,proto3" json:"account_type,omitempty"`
+	Status      string `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	// RFC 3339, empty while the account is open.
+	ClosedAt      string `protobuf:"bytes,9,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_internal_grpcserver_transferspb_transfers_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Account) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *Account) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+func (x *Account) GetHeldBalance() string {
+	if x != nil {
+		return x.HeldBalance
+	}
+	return ""
+}
+
+func (x *Account) GetOverdraftLimit() string {
+	if x != nil {
+		return x.OverdraftLimit
+	}
+	return ""
+}
+
+func (x *Account) GetAvailableBalance() string {
+	if x != nil {
+		return x.AvailableBalance
+	}
+	return ""
+}
+
+func (x *Account) GetMaxBalance() string {
+	if x != nil {
+		return x.MaxBalance
+	}
+	return ""
+}
+
+func (x *Account) GetAccountType() string {
+	if x != nil {
+		return x.AccountType
+	}
+	return ""
+}
+
+func (x *Account) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Account) GetClosedAt() string {
+	if x != nil {
+		return x.ClosedAt
+	}
+	return ""
+}
+
+type CreateTransactionRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	SourceAccountId      int64                  `protobuf:"varint,1,opt,name=source_account_id,json=sourceAccountId,proto3" json:"source_account_id,omitempty"`
+	DestinationAccountId int64                  `protobuf:"varint,2,opt,name=destination_account_id,json=destinationAccountId,proto3" json:"destination_account_id,omitempty"`
+	Amount               string                 `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// YYYY-MM-DD. Empty means today (UTC).
+	EffectiveDate string `protobuf:"bytes,4,opt,name=effective_date,json=effectiveDate,proto3" json:"effective_date,omitempty"`
+	// Per-source replay protection. Zero means none.
+	Sequence int64  `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Category string `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
+	// Repeating a key with the same request returns the original transaction, with
+	// replayed set.
+	IdempotencyKey string `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateTransactionRequest) Reset() {
+	*x = CreateTransactionRequest{}
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransactionRequest) ProtoMessage() {}
+
+func (x *CreateTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransactionRequest.ProtoReflect.Descriptor instead.
+func (*CreateTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcserver_transferspb_transfers_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateTransactionRequest) GetSourceAccountId() int64 {
+	if x != nil {
+		return x.SourceAccountId
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetDestinationAccountId() int64 {
+	if x != nil {
+		return x.DestinationAccountId
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetEffectiveDate() string {
+	if x != nil {
+		return x.EffectiveDate
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type GetTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId int64                  `protobuf:"varint,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionRequest) Reset() {
+	*x = GetTransactionRequest{}
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionRequest) ProtoMessage() {}
+
+func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcserver_transferspb_transfers_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetTransactionRequest) GetTransactionId() int64 {
+	if x != nil {
+		return x.TransactionId
+	}
+	return 0
+}
+
+type Transaction struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId        int64                  `protobuf:"varint,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Type                 string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	SourceAccountId      int64                  `protobuf:"varint,3,opt,name=source_account_id,json=sourceAccountId,proto3" json:"source_account_id,omitempty"`
+	DestinationAccountId int64                  `protobuf:"varint,4,opt,name=destination_account_id,json=destinationAccountId,proto3" json:"destination_account_id,omitempty"`
+	Amount               string                 `protobuf:"bytes,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	EffectiveDate        string                 `protobuf:"bytes,6,opt,name=effective_date,json=effectiveDate,proto3" json:"effective_date,omitempty"`
+	// Zero unless this transaction reverses another.
+	ReversalOf   int64  `protobuf:"varint,7,opt,name=reversal_of,json=reversalOf,proto3" json:"reversal_of,omitempty"`
+	Category     string `protobuf:"bytes,8,opt,name=category,proto3" json:"category,omitempty"`
+	Status       string `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	FeeAmount    string `protobuf:"bytes,10,opt,name=fee_amount,json=feeAmount,proto3" json:"fee_amount,omitempty"`
+	FeeAccountId int64  `protobuf:"varint,11,opt,name=fee_account_id,json=feeAccountId,proto3" json:"fee_account_id,omitempty"`
+	FeePaidBy    string `protobuf:"bytes,12,opt,name=fee_paid_by,json=feePaidBy,proto3" json:"fee_paid_by,omitempty"`
+	// RFC 3339.
+	CreatedAt     string `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Replayed      bool   `protobuf:"varint,14,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcserver_transferspb_transfers_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_internal_grpcserver_transferspb_transfers_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Transaction) GetTransactionId() int64 {
+	if x != nil {
+		return x.TransactionId
+	}
+	return 0
+}
+
+func (x *Transaction) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Transaction) GetSourceAccountId() int64 {
+	if x != nil {
+		return x.SourceAccountId
+	}
+	return 0
+}
+
+func (x *Transaction) GetDestinationAccountId() int64 {
+	if x != nil {
+		return x.DestinationAccountId
+	}
+	return 0
+}
+
+func (x *Transaction) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *Transaction) GetEffectiveDate() string {
+	if x != nil {
+		return x.EffectiveDate
+	}
+	return ""
+}
+
+func (x *Transaction) GetReversalOf() int64 {
+	if x != nil {
+		return x.ReversalOf
+	}
+	return 0
+}
+
+func (x *Transaction) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Transaction) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Transaction) GetFeeAmount() string {
+	if x != nil {
+		return x.FeeAmount
+	}
+	return ""
+}
+
+func (x *Transaction) GetFeeAccountId() int64 {
+	if x != nil {
+		return x.FeeAccountId
+	}
+	return 0
+}
+
+func (x *Transaction) GetFeePaidBy() string {
+	if x != nil {
+		return x.FeePaidBy
+	}
+	return ""
+}
+
+func (x *Transaction) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Transaction) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+var File_internal_grpcserver_transferspb_transfers_proto protoreflect.FileDescriptor
+
+const file_internal_grpcserver_transferspb_transfers_proto_rawDesc = "" +
+	"\n" +
+	"/internal/grpcserver/transferspb/transfers.proto\x12\ftransfers.v1\"\xcb\x01\n" +
+	"\x14CreateAccountRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x12'\n" +
+	"\x0finitial_balance\x18\x02 \x01(\tR\x0einitialBalance\x12\x1f\n" +
+	"\vmax_balance\x18\x03 \x01(\tR\n" +
+	"maxBalance\x12!\n" +
+	"\faccount_type\x18\x04 \x01(\tR\vaccountType\x12'\n" +
+	"\x0foverdraft_limit\x18\x05 \x01(\tR\x0eoverdraftLimit\"2\n" +
+	"\x11GetAccountRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\"\xb4\x02\n" +
+	"\aAccount\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\tR\abalance\x12!\n" +
+	"\fheld_balance\x18\x03 \x01(\tR\vheldBalance\x12'\n" +
+	"\x0foverdraft_limit\x18\x04 \x01(\tR\x0eoverdraftLimit\x12+\n" +
+	"\x11available_balance\x18\x05 \x01(\tR\x10availableBalance\x12\x1f\n" +
+	"\vmax_balance\x18\x06 \x01(\tR\n" +
+	"maxBalance\x12!\n" +
+	"\faccount_type\x18\a \x01(\tR\vaccountType\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x1b\n" +
+	"\tclosed_at\x18\t \x01(\tR\bclosedAt\"\x9c\x02\n" +
+	"\x18CreateTransactionRequest\x12*\n" +
+	"\x11source_account_id\x18\x01 \x01(\x03R\x0fsourceAccountId\x124\n" +
+	"\x16destination_account_id\x18\x02 \x01(\x03R\x14destinationAccountId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\tR\x06amount\x12%\n" +
+	"\x0eeffective_date\x18\x04 \x01(\tR\reffectiveDate\x12\x1a\n" +
+	"\bsequence\x18\x05 \x01(\x03R\bsequence\x12\x1a\n" +
+	"\bcategory\x18\x06 \x01(\tR\bcategory\x12'\n" +
+	"\x0fidempotency_key\x18\a \x01(\tR\x0eidempotencyKey\">\n" +
+	"\x15GetTransactionRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\x03R\rtransactionId\"\xde\x03\n" +
+	"\vTransaction\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\x03R\rtransactionId\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12*\n" +
+	"\x11source_account_id\x18\x03 \x01(\x03R\x0fsourceAccountId\x124\n" +
+	"\x16destination_account_id\x18\x04 \x01(\x03R\x14destinationAccountId\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\tR\x06amount\x12%\n" +
+	"\x0eeffective_date\x18\x06 \x01(\tR\reffectiveDate\x12\x1f\n" +
+	"\vreversal_of\x18\a \x01(\x03R\n" +
+	"reversalOf\x12\x1a\n" +
+	"\bcategory\x18\b \x01(\tR\bcategory\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"fee_amount\x18\n" +
+	" \x01(\tR\tfeeAmount\x12$\n" +
+	"\x0efee_account_id\x18\v \x01(\x03R\ffeeAccountId\x12\x1e\n" +
+	"\vfee_paid_by\x18\f \x01(\tR\tfeePaidBy\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\r \x01(\tR\tcreatedAt\x12\x1a\n" +
+	"\breplayed\x18\x0e \x01(\bR\breplayed2\xc7\x02\n" +
+	"\tTransfers\x12J\n" +
+	"\rCreateAccount\x12\".transfers.v1.CreateAccountRequest\x1a\x15.transfers.v1.Account\x12D\n" +
+	"\n" +
+	"GetAccount\x12\x1f.transfers.v1.GetAccountRequest\x1a\x15.transfers.v1.Account\x12V\n" +
+	"\x11CreateTransaction\x12&.transfers.v1.CreateTransactionRequest\x1a\x19.transfers.v1.Transaction\x12P\n" +
+	"\x0eGetTransaction\x12#.transfers.v1.GetTransactionRequest\x1a\x19.transfers.v1.TransactionB;Z9internal-transfers-system/internal/grpcserver/transferspbb\x06proto3"
+
+var (
+	file_internal_grpcserver_transferspb_transfers_proto_rawDescOnce sync.Once
+	file_internal_grpcserver_transferspb_transfers_proto_rawDescData []byte
+)
+
+func file_internal_grpcserver_transferspb_transfers_proto_rawDescGZIP() []byte {
+	file_internal_grpcserver_transferspb_transfers_proto_rawDescOnce.Do(func() {
+		file_internal_grpcserver_transferspb_transfers_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_grpcserver_transferspb_transfers_proto_rawDesc), len(file_internal_grpcserver_transferspb_transfers_proto_rawDesc)))
+	})
+	return file_internal_grpcserver_transferspb_transfers_proto_rawDescData
+}
+
+var file_internal_grpcserver_transferspb_transfers_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_internal_grpcserver_transferspb_transfers_proto_goTypes = []any{
+	(*CreateAccountRequest)(nil),     // 0: transfers.v1.CreateAccountRequest
+	(*GetAccountRequest)(nil),        // 1: transfers.v1.GetAccountRequest
+	(*Account)(nil),                  // 2: transfers.v1.Account
+	(*CreateTransactionRequest)(nil), // 3: transfers.v1.CreateTransactionRequest
+	(*GetTransactionRequest)(nil),    // 4: transfers.v1.GetTransactionRequest
+	(*Transaction)(nil),              // 5: transfers.v1.Transaction
+}
+var file_internal_grpcserver_transferspb_transfers_proto_depIdxs = []int32{
+	0, // 0: transfers.v1.Transfers.CreateAccount:input_type -> transfers.v1.CreateAccountRequest
+	1, // 1: transfers.v1.Transfers.GetAccount:input_type -> transfers.v1.GetAccountRequest
+	3, // 2: transfers.v1.Transfers.CreateTransaction:input_type -> transfers.v1.CreateTransactionRequest
+	4, // 3: transfers.v1.Transfers.GetTransaction:input_type -> transfers.v1.GetTransactionRequest
+	2, // 4: transfers.v1.Transfers.CreateAccount:output_type -> transfers.v1.Account
+	2, // 5: transfers.v1.Transfers.GetAccount:output_type -> transfers.v1.Account
+	5, // 6: transfers.v1.Transfers.CreateTransaction:output_type -> transfers.v1.Transaction
+	5, // 7: transfers.v1.Transfers.GetTransaction:output_type -> transfers.v1.Transaction
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_internal_grpcserver_transferspb_transfers_proto_init() }
+func file_internal_grpcserver_transferspb_transfers_proto_init() {
+	if File_internal_grpcserver_transferspb_transfers_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_grpcserver_transferspb_transfers_proto_rawDesc), len(file_internal_grpcserver_transferspb_transfers_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_grpcserver_transferspb_transfers_proto_goTypes,
+		DependencyIndexes: file_internal_grpcserver_transferspb_transfers_proto_depIdxs,
+		MessageInfos:      file_internal_grpcserver_transferspb_transfers_proto_msgTypes,
+	}.Build()
+	File_internal_grpcserver_transferspb_transfers_proto = out.File
+	file_internal_grpcserver_transferspb_transfers_proto_goTypes = nil
+	file_internal_grpcserver_transferspb_transfers_proto_depIdxs = nil
+}
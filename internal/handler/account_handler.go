@@ -4,10 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"internal-transfers-system/internal/idcodec"
+	"internal-transfers-system/internal/logging"
 	"internal-transfers-system/internal/models"
 	"internal-transfers-system/internal/service"
 	"internal-transfers-system/internal/validator"
@@ -17,23 +25,90 @@ import (
 
 type AccountHandler struct {
 	accountService *service.AccountService
+	limits         PageLimits
+	validationMode validator.Mode
+	inFlight       *sync.WaitGroup
+	metrics        RequestMetrics
+	idCodec        idcodec.Codec
+	maxRequestBody int64
+	money          moneyFormat
+	currency       string
+}
+
+// BalanceChangeResponse is one entry of an account's balance history. Exactly one of
+// TransactionID and AdjustmentID is set.
+type BalanceChangeResponse struct {
+	ChangeID      int64     `json:"change_id"`
+	OldBalance    string    `json:"old_balance"`
+	NewBalance    string    `json:"new_balance"`
+	TransactionID *PublicID `json:"transaction_id,omitempty"`
+	AdjustmentID  *int64    `json:"adjustment_id,omitempty"`
+	CreatedAt     string    `json:"created_at"`
+}
+
+// BatchCreateAccountsResponse summarizes a bulk account creation: the IDs of the accounts
+// created, in request order, and every request that was rejected.
+type BatchCreateAccountsResponse struct {
+	Created []int64               `json:"created"`
+	Failed  []BatchAccountFailure `json:"failed"`
+}
+
+// GetAccountsResponse answers a multi-account lookup: the accounts found and the IDs of
+// those that don't exist, each in the order first requested.
+type GetAccountsResponse struct {
+	Accounts []models.GetAccountResponse `json:"accounts"`
+	Missing  []int64                     `json:"missing"`
+}
+
+// BatchAccountFailure is one rejected request of a bulk account creation. Errors lists the
+// field-level problems of a request that failed validation; Error and Message describe
+// any other rejection, such as an account ID that is already taken.
+type BatchAccountFailure struct {
+	Index     int                        `json:"index"`
+	AccountID int64                      `json:"account_id,omitempty"`
+	Error     string                     `json:"error"`
+	Message   string                     `json:"message,omitempty"`
+	Errors    validator.ValidationErrors `json:"errors,omitempty"`
 }
 
 func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
-	return &AccountHandler{accountService: accountService}
+	return NewAccountHandlerWithLimits(accountService, DefaultPageLimits())
+}
+
+func NewAccountHandlerWithLimits(accountService *service.AccountService, limits PageLimits) *AccountHandler {
+	opts := DefaultOptions()
+	opts.Limits = limits
+	return NewAccountHandlerWithOptions(accountService, opts)
 }
 
+func NewAccountHandlerWithOptions(accountService *service.AccountService, opts Options) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		limits:         opts.Limits,
+		validationMode: opts.ValidationMode,
+		inFlight:       opts.InFlight,
+		metrics:        opts.Metrics,
+		idCodec:        opts.IDCodec,
+		maxRequestBody: opts.MaxRequestBody,
+		money:          opts.moneyFormat(),
+		currency:       opts.currency(),
+	}
+}
+
+// CreateAccount opens an account and responds 201. An idempotent request for an ID that
+// is already taken responds 200 with the existing account instead, if its initial balance
+// matches.
 func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var req models.CreateAccountRequest
-	if err := decodeJSONBody(r, &req); err != nil {
+	if err := decodeJSONBody(w, r, h.maxRequestBody, &req); err != nil {
 		log.Debug().Err(err).Msg("Failed to decode create account request")
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeDecodeError(w, err)
 		return
 	}
 
-	if errs := validator.ValidateCreateAccount(&req); len(errs) > 0 {
+	if errs := validator.ValidateCreateAccountWithMode(&req, validationMode(r, h.validationMode)); len(errs) > 0 {
 		log.Debug().Int64("accountID", req.AccountID).Interface("errors", errs).Msg("Create account validation failed")
 		writeValidationError(w, errs)
 		return
@@ -41,52 +116,456 @@ func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 
 	account, err := h.accountService.CreateAccount(ctx, &req)
 	if err != nil {
-		handleServiceError(ctx, w, err)
+		handleServiceError(ctx, w, err, h.metrics)
 		return
 	}
 
-	resp := models.GetAccountResponse{
-		AccountID: account.AccountID,
-		Balance:   account.Balance.String(),
+	resp := newAccountResponse(account, h.money, amountCurrency(w, r, h.currency))
+	resp.Warnings = account.Warnings
+	if account.Replayed {
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		writeSuccess(w, http.StatusOK, resp)
+		return
 	}
 	writeSuccess(w, http.StatusCreated, resp)
 }
 
+// BatchCreateAccounts creates every account in a JSON array of create-account requests.
+// By default each request stands alone: invalid ones are reported by index and the rest
+// are still created. With ?atomic=true any invalid request fails the whole batch.
+func (h *AccountHandler) BatchCreateAccounts(w http.ResponseWriter, r *http.Request) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	var reqs []models.CreateAccountRequest
+	if err := decodeJSONBody(w, r, h.maxRequestBody, &reqs); err != nil {
+		log.Debug().Err(err).Msg("Failed to decode batch create accounts request")
+		writeDecodeErrorAt(w, err, "accounts")
+		return
+	}
+	atomic := r.URL.Query().Get("atomic") == "true"
+
+	batchErrs, itemErrs := validator.ValidateCreateAccountBatchWithMode(reqs, service.MaxAccountBatchSize, validationMode(r, h.validationMode))
+	if len(batchErrs) > 0 {
+		log.Debug().Int("accounts", len(reqs)).Interface("errors", batchErrs).Msg("Batch create accounts validation failed")
+		writeValidationError(w, batchErrs)
+		return
+	}
+
+	resp := BatchCreateAccountsResponse{Created: []int64{}, Failed: []BatchAccountFailure{}}
+	valid := make([]*models.CreateAccountRequest, 0, len(reqs))
+	indexes := make([]int, 0, len(reqs))
+	var allErrs validator.ValidationErrors
+	for i := range reqs {
+		if len(itemErrs[i]) > 0 {
+			allErrs = append(allErrs, itemErrs[i]...)
+			resp.Failed = append(resp.Failed, BatchAccountFailure{
+				Index: i, AccountID: reqs[i].AccountID, Error: "validation_failed", Errors: itemErrs[i],
+			})
+			continue
+		}
+		valid = append(valid, &reqs[i])
+		indexes = append(indexes, i)
+	}
+	if len(allErrs) > 0 && atomic {
+		log.Debug().Int("accounts", len(reqs)).Interface("errors", allErrs).Msg("Batch create accounts validation failed")
+		writeValidationError(w, allErrs)
+		return
+	}
+
+	if len(valid) > 0 {
+		result, err := h.accountService.CreateAccounts(ctx, valid, atomic)
+		if err != nil {
+			handleServiceError(ctx, w, err, h.metrics)
+			return
+		}
+		for _, account := range result.Created {
+			resp.Created = append(resp.Created, account.AccountID)
+		}
+		for _, f := range result.Failed {
+			failure := BatchAccountFailure{Index: indexes[f.Index], AccountID: f.AccountID}
+			var domainErr *models.DomainError
+			if errors.As(f.Err, &domainErr) {
+				_, failure.Error, failure.Message = mapDomainError(domainErr)
+			} else {
+				failure.Error, failure.Message = "internal_error", internalErrorMessage
+			}
+			resp.Failed = append(resp.Failed, failure)
+		}
+		sort.Slice(resp.Failed, func(i, j int) bool { return resp.Failed[i].Index < resp.Failed[j].Index })
+	}
+
+	status := http.StatusOK
+	if len(resp.Created) > 0 {
+		status = http.StatusCreated
+	}
+	writeSuccess(w, status, resp)
+}
+
+// GetAccounts looks up the accounts listed in ids (comma-separated, at most the
+// configured batch-get limit of distinct IDs) in one call. IDs that don't exist are
+// listed in missing rather than failing the request. An optional scale rounds displayed
+// amounts; see parseScale.
+func (h *AccountHandler) GetAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ids, errs := parseAccountIDList(r.URL.Query().Get("ids"), h.limits.MaxBatchGet)
+	if len(errs) > 0 {
+		log.Debug().Interface("errors", errs).Msg("Get accounts validation failed")
+		writeValidationError(w, errs)
+		return
+	}
+	format, ok := parseScale(w, r, h.money)
+	if !ok {
+		return
+	}
+
+	accounts, missing, err := h.accountService.GetAccounts(ctx, ids)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	currency := amountCurrency(w, r, h.currency)
+	resp := GetAccountsResponse{Accounts: make([]models.GetAccountResponse, 0, len(accounts)), Missing: missing}
+	for _, account := range accounts {
+		resp.Accounts = append(resp.Accounts, newAccountResponse(account, format, currency))
+	}
+	writeSuccess(w, http.StatusOK, resp)
+}
+
+// parseAccountIDList parses a comma-separated list of positive account IDs, holding at
+// most limit distinct IDs.
+func parseAccountIDList(raw string, limit int) ([]int64, validator.ValidationErrors) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, validator.ValidationErrors{{Field: "ids", Message: "is required"}}
+	}
+
+	parts := strings.Split(raw, ",")
+	ids := make([]int64, 0, len(parts))
+	distinct := make(map[int64]bool, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || id <= 0 {
+			return nil, validator.ValidationErrors{{Field: "ids", Message: "must be a comma-separated list of positive integers"}}
+		}
+		ids = append(ids, id)
+		distinct[id] = true
+	}
+	if len(distinct) > limit {
+		return nil, validator.ValidationErrors{{Field: "ids", Message: fmt.Sprintf("must list at most %d accounts", limit)}}
+	}
+	return ids, nil
+}
+
+// GetAccount returns account {id}. The response carries an ETag; a client polling the
+// balance can send it back in If-None-Match and gets 304 Not Modified, with no body,
+// until the account changes. An optional scale rounds displayed amounts; see parseScale.
 func (h *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	accountID, ok := parseAccountID(w, r)
+	if !ok {
+		return
+	}
+	format, ok := parseScale(w, r, h.money)
+	if !ok {
+		return
+	}
+
+	account, err := h.accountService.GetAccount(ctx, accountID)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	writeConditional(w, r, newAccountResponse(account, format, amountCurrency(w, r, h.currency)))
+}
+
+// GetBalanceHistory returns account {id}'s balance changes, newest first, paged with
+// limit and offset like the offset transaction listing.
+func (h *AccountHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	accountID, ok := parseAccountID(w, r)
+	if !ok {
+		return
+	}
+	format, ok := parseScale(w, r, h.money)
+	if !ok {
+		return
+	}
+
+	changes, err := h.accountService.GetBalanceHistory(ctx, accountID, h.limits.listingLimit(r), queryInt(r, "offset", 0))
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	resp := make([]BalanceChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		item := BalanceChangeResponse{
+			ChangeID:     change.ChangeID,
+			OldBalance:   format(change.OldBalance),
+			NewBalance:   format(change.NewBalance),
+			AdjustmentID: change.AdjustmentID,
+			CreatedAt:    change.CreatedAt.UTC().Format(models.TimestampLayout),
+		}
+		if change.TransactionID != nil {
+			transactionID := newPublicID(*change.TransactionID, h.idCodec)
+			item.TransactionID = &transactionID
+		}
+		resp = append(resp, item)
+	}
+	writeSuccess(w, http.StatusOK, resp)
+}
+
+// CloseAccount closes account {id} and returns it with status "closed". Accounts with a
+// non-zero balance are only closed when force=true is passed; the balance stays frozen.
+func (h *AccountHandler) CloseAccount(w http.ResponseWriter, r *http.Request) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	accountID, ok := parseAccountID(w, r)
+	if !ok {
+		return
+	}
+	force := r.URL.Query().Get("force") == "true"
+
+	account, err := h.accountService.CloseAccount(ctx, accountID, force)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, newAccountResponse(account, h.money, amountCurrency(w, r, h.currency)))
+}
+
+// ExportAccounts streams every account as newline-delimited JSON, ordered by account ID.
+// Rows are read in keyset pages of the configured export page size, so the response can
+// cover the whole table without buffering it in memory. Errors after the first row are
+// logged and end the stream early, since the status line has already been sent.
+func (h *AccountHandler) ExportAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rc := http.NewResponseController(w)
+	// The export runs as long as the table is big, not SERVER_WRITE_TIMEOUT
+	_ = rc.SetWriteDeadline(time.Time{})
+	encoder := json.NewEncoder(w)
+
+	batch := h.limits.exportBatch()
+	written := 0
+	err := h.accountService.StreamAccounts(ctx, batch, func(account *models.Account) error {
+		if written == 0 {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.WriteHeader(http.StatusOK)
+		}
+
+		record := models.AccountExportRecord{
+			AccountID: account.AccountID,
+			Balance:   h.money(account.Balance),
+			UpdatedAt: account.UpdatedAt.UTC().Format(models.TimestampLayout),
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+
+		written++
+		if written%batch == 0 {
+			_ = rc.Flush()
+		}
+		return nil
+	})
+
+	if err != nil {
+		if written == 0 {
+			handleServiceError(ctx, w, err, h.metrics)
+			return
+		}
+		log.Error().Err(err).Int("written", written).Msg("Account export aborted mid-stream")
+		return
+	}
+
+	if written == 0 {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	_ = rc.Flush()
+}
+
+// BatchAdjustBalances applies a bulk balance correction. The whole batch succeeds or
+// fails together; on success every account gets an audit entry sharing one batch ID.
+func (h *AccountHandler) BatchAdjustBalances(w http.ResponseWriter, r *http.Request) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	var req models.BatchAdjustRequest
+	if err := decodeJSONBody(w, r, h.maxRequestBody, &req); err != nil {
+		log.Debug().Err(err).Msg("Failed to decode batch adjust request")
+		writeDecodeError(w, err)
+		return
+	}
+
+	mode := validationMode(r, h.validationMode)
+	if errs := validator.ValidateBatchAdjustWithMode(&req, service.MaxAdjustmentBatchSize, mode); len(errs) > 0 {
+		log.Debug().Int("adjustments", len(req.Adjustments)).Interface("errors", errs).Msg("Batch adjust validation failed")
+		writeValidationError(w, errs)
+		return
+	}
+
+	deltas := make([]models.BalanceDelta, len(req.Adjustments))
+	for i, item := range req.Adjustments {
+		delta, err := models.ParseMoney(item.Delta)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_amount", "Delta must be a decimal value")
+			return
+		}
+		deltas[i] = models.BalanceDelta{AccountID: item.AccountID, Delta: delta}
+	}
+
+	adjustments, err := h.accountService.AdjustBalancesBatch(ctx, deltas, req.Reason)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	resp := models.BatchAdjustResponse{
+		BatchID:     adjustments[0].BatchID,
+		Reason:      adjustments[0].Reason,
+		Adjustments: make([]models.BalanceAdjustmentResult, len(adjustments)),
+	}
+	for i, adj := range adjustments {
+		resp.Adjustments[i] = models.BalanceAdjustmentResult{
+			AccountID: adj.AccountID,
+			Delta:     h.money(adj.Delta),
+			Balance:   h.money(adj.BalanceAfter),
+		}
+	}
+	writeSuccess(w, http.StatusOK, resp)
+}
+
+// ReconcileBalances compares imported authoritative balances with the stored ones and
+// returns a per-account diff. Nothing changes unless ?apply=true, which sets every
+// mismatched account to its expected balance through a balance adjustment recorded under
+// ?reason= (default "reconciliation").
+func (h *AccountHandler) ReconcileBalances(w http.ResponseWriter, r *http.Request) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	var items []models.ReconcileItem
+	if err := decodeJSONBody(w, r, h.maxRequestBody, &items); err != nil {
+		log.Debug().Err(err).Msg("Failed to decode reconcile request")
+		writeDecodeErrorAt(w, err, "accounts")
+		return
+	}
+	apply := r.URL.Query().Get("apply") == "true"
+
+	mode := validationMode(r, h.validationMode)
+	if errs := validator.ValidateReconcileWithMode(items, service.MaxAdjustmentBatchSize, mode); len(errs) > 0 {
+		log.Debug().Int("accounts", len(items)).Interface("errors", errs).Msg("Reconcile validation failed")
+		writeValidationError(w, errs)
+		return
+	}
+
+	expected := make([]models.ExpectedBalance, len(items))
+	for i, item := range items {
+		balance, err := models.ParseMoney(item.ExpectedBalance)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_amount", "Expected balance must be a decimal value")
+			return
+		}
+		expected[i] = models.ExpectedBalance{AccountID: item.AccountID, Balance: balance}
+	}
+
+	report, err := h.accountService.Reconcile(ctx, expected, apply, r.URL.Query().Get("reason"))
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	resp := models.ReconcileResponse{
+		Applied: report.Applied,
+		BatchID: report.BatchID,
+		Reason:  report.Reason,
+		Summary: models.ReconcileSummary{
+			Matched:    report.Count(models.ReconciliationMatched),
+			Mismatched: report.Count(models.ReconciliationMismatched),
+			Corrected:  report.Count(models.ReconciliationCorrected),
+			Missing:    report.Count(models.ReconciliationMissing),
+		},
+		Accounts: make([]models.ReconcileResult, len(report.Items)),
+	}
+	for i, item := range report.Items {
+		result := models.ReconcileResult{
+			AccountID:       item.AccountID,
+			Status:          string(item.Status),
+			ExpectedBalance: h.money(item.Expected),
+		}
+		if item.Status != models.ReconciliationMissing {
+			result.StoredBalance = h.money(item.Stored)
+			result.Difference = h.money(item.Difference())
+		}
+		if item.Adjustment != nil {
+			result.AdjustmentID = item.Adjustment.AdjustmentID
+		}
+		resp.Accounts[i] = result
+	}
+	writeSuccess(w, http.StatusOK, resp)
+}
+
+// parseAccountID reads the {id} path value, writing a 400 and returning false if it is
+// not a positive integer.
+func parseAccountID(w http.ResponseWriter, r *http.Request) (int64, bool) {
 	idStr := r.PathValue("id")
 	accountID, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
 		log.Debug().Str("id", idStr).Msg("Invalid account ID format")
 		writeError(w, http.StatusBadRequest, "invalid_id", "Account ID must be a valid integer")
-		return
+		return 0, false
 	}
 	if accountID <= 0 {
 		log.Debug().Int64("id", accountID).Msg("Account ID must be positive")
 		writeError(w, http.StatusBadRequest, "invalid_id", "Account ID must be a positive integer")
-		return
-	}
-
-	account, err := h.accountService.GetAccount(ctx, accountID)
-	if err != nil {
-		handleServiceError(ctx, w, err)
-		return
+		return 0, false
 	}
+	return accountID, true
+}
 
+func newAccountResponse(account *models.Account, format moneyFormat, currency string) models.GetAccountResponse {
 	resp := models.GetAccountResponse{
-		AccountID: account.AccountID,
-		Balance:   account.Balance.String(),
+		AccountID:        account.AccountID,
+		Balance:          format(account.Balance),
+		HeldBalance:      format(account.HeldBalance),
+		OverdraftLimit:   format(account.OverdraftLimit),
+		AvailableBalance: format(account.AvailableBalance()),
+		AccountType:      account.AccountType,
+		Status:           account.Status(),
+		AmountCurrency:   currency,
 	}
-	writeSuccess(w, http.StatusOK, resp)
+	if account.MaxBalance.Valid {
+		resp.MaxBalance = format(account.MaxBalance.Decimal)
+	}
+	if account.ClosedAt != nil {
+		resp.ClosedAt = account.ClosedAt.UTC().Format(models.TimestampLayout)
+	}
+	return resp
 }
 
-func decodeJSONBody(r *http.Request, target interface{}) error {
-	const maxBodySize = 1 << 20
-	r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+// decodeJSONBody decodes a single JSON object from r into target, rejecting unknown
+// fields. Bodies over limit bytes (DefaultMaxRequestBody when limit <= 0) fail with an
+// *http.MaxBytesError; writeDecodeError turns that into a 413. Numbers bound for
+// interface{} or json.Number values arrive as json.Number rather than float64, so large
+// values such as 9007199254740993 can be converted to decimal without losing precision.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, limit int64, target interface{}) error {
+	if limit <= 0 {
+		limit = DefaultMaxRequestBody
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
 
 	decoder := json.NewDecoder(r.Body)
 	decoder.DisallowUnknownFields()
+	decoder.UseNumber()
 
 	if err := decoder.Decode(target); err != nil {
 		return err
@@ -99,14 +578,113 @@ func decodeJSONBody(r *http.Request, target interface{}) error {
 	return nil
 }
 
-func handleServiceError(ctx context.Context, w http.ResponseWriter, err error) {
+// writeDecodeError writes the response for a decodeJSONBody failure: 413
+// request_too_large for an oversized body, 400 validation_failed naming the field for a
+// value of the wrong JSON type or an unknown field, otherwise 400 invalid_json.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	writeDecodeErrorAt(w, err, "")
+}
+
+// writeDecodeErrorAt is writeDecodeError for a body whose fields are named under root,
+// e.g. "accounts" for a top-level array, so entries read accounts[0].account_id.
+func writeDecodeErrorAt(w http.ResponseWriter, err error, root string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, "request_too_large",
+			fmt.Sprintf("Request body must be at most %d bytes", tooLarge.Limit))
+		return
+	}
+	if fieldErr, ok := decodeFieldError(err); ok {
+		if root != "" && strings.HasPrefix(fieldErr.Field, "[") {
+			fieldErr.Field = root + fieldErr.Field
+		}
+		writeValidationError(w, validator.ValidationErrors{fieldErr})
+		return
+	}
+	writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+}
+
+// decodeFieldError describes a decode failure caused by one field: a value of the wrong
+// JSON type, e.g. "account_id": "1", or a field the request doesn't have. Nested fields
+// are named as the validator names them, e.g. transfers[0].amount.
+func decodeFieldError(err error) (validator.ValidationError, bool) {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return validator.ValidationError{Field: jsonFieldPath(typeErr.Field), Message: typeMismatchMessage(typeErr)}, true
+	}
+	// encoding/json reports unknown fields only in the message
+	if name, ok := strings.CutPrefix(err.Error(), `json: unknown field "`); ok {
+		return validator.ValidationError{Field: strings.TrimSuffix(name, `"`), Message: "is not a known field"}, true
+	}
+	return validator.ValidationError{}, false
+}
+
+// jsonFieldPath rewrites encoding/json's dotted path, e.g. "transfers.0.amount", with
+// array indexes in brackets: "transfers[0].amount".
+func jsonFieldPath(path string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(path, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// typeMismatchMessage says what type the field expects.
+func typeMismatchMessage(err *json.UnmarshalTypeError) string {
+	t := err.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// A JSON number that didn't fit: a fraction, an exponent, or too many digits
+		if number, ok := strings.CutPrefix(err.Value, "number "); ok {
+			if strings.ContainsAny(number, ".eE") {
+				return "must be an integer"
+			}
+			return "is out of range"
+		}
+		return "must be a number"
+	case reflect.Float32, reflect.Float64:
+		return "must be a number"
+	case reflect.String:
+		return "must be a string"
+	case reflect.Bool:
+		return "must be a boolean"
+	case reflect.Slice, reflect.Array:
+		return "must be an array"
+	case reflect.Struct, reflect.Map:
+		return "must be an object"
+	default:
+		return "has the wrong type"
+	}
+}
+
+// handleServiceError writes the error response for err, counting client cancellations
+// and server timeouts separately in m (which may be nil). Domain errors, which include
+// every sentinel in models, are reported as models.StatusOf says.
+func handleServiceError(ctx context.Context, w http.ResponseWriter, err error, m RequestMetrics) {
+	if m == nil {
+		m = noopRequestMetrics{}
+	}
+
 	if errors.Is(err, context.Canceled) {
-		log.Debug().Msg("Request cancelled by client")
+		m.RequestCanceled()
+		logging.FromContext(ctx).Debug().Msg("Request cancelled by client")
 		writeError(w, http.StatusBadRequest, "request_cancelled", "Request was cancelled")
 		return
 	}
 	if errors.Is(err, context.DeadlineExceeded) {
-		log.Warn().Msg("Request timeout")
+		m.RequestTimedOut()
+		logging.FromContext(ctx).Warn().Msg("Request timeout")
 		writeError(w, http.StatusGatewayTimeout, "timeout", "Request timed out")
 		return
 	}
@@ -114,55 +692,79 @@ func handleServiceError(ctx context.Context, w http.ResponseWriter, err error) {
 	var domainErr *models.DomainError
 	if errors.As(err, &domainErr) {
 		status, errorCode, message := mapDomainError(domainErr)
+		resp := ErrorResponse{Error: errorCode, Message: message, Details: errorDetails(err)}
+		var blackout *models.BlackoutError
+		if errors.As(err, &blackout) {
+			resp.AvailableAt = blackout.Until.UTC().Format(models.TimestampLayout)
+		}
+		if retryAfter, ok := retryHint(err, domainErr); ok {
+			resp.Retryable = true
+			resp.RetryAfter = retryAfterSeconds(retryAfter)
+			w.Header().Set("Retry-After", strconv.Itoa(resp.RetryAfter))
+		}
 		if status >= 500 {
-			log.Error().Err(err).Str("code", string(domainErr.Code)).Msg("Internal error")
-		}
-		writeError(w, status, errorCode, message)
-		return
-	}
-
-	switch {
-	case errors.Is(err, models.ErrAccountNotFound):
-		writeError(w, http.StatusNotFound, "account_not_found", "Account not found")
-	case errors.Is(err, models.ErrAccountAlreadyExists):
-		writeError(w, http.StatusConflict, "account_exists", "Account already exists")
-	case errors.Is(err, models.ErrInsufficientBalance):
-		writeError(w, http.StatusUnprocessableEntity, "insufficient_balance", "Insufficient balance for this transaction")
-	case errors.Is(err, models.ErrInvalidAmount):
-		writeError(w, http.StatusBadRequest, "invalid_amount", "Amount must be a positive decimal value")
-	case errors.Is(err, models.ErrSameAccount):
-		writeError(w, http.StatusBadRequest, "same_account", "Source and destination accounts cannot be the same")
-	case errors.Is(err, models.ErrTransferNotFound):
-		writeError(w, http.StatusNotFound, "transaction_not_found", "Transaction not found")
-	case errors.Is(err, models.ErrDuplicateTransaction):
-		writeError(w, http.StatusConflict, "duplicate_transaction", "Duplicate transaction detected")
-	case errors.Is(err, io.EOF):
+			logging.FromContext(ctx).Error().Err(err).Str("code", string(domainErr.Code)).Msg("Internal error")
+		}
+		writeErrorResponse(w, status, resp)
+		return
+	}
+
+	if errors.Is(err, io.EOF) {
 		writeError(w, http.StatusBadRequest, "invalid_json", "Request body is empty")
-	default:
-		log.Error().Err(err).Msg("Unexpected error in handler")
-		writeError(w, http.StatusInternalServerError, "internal_error", "An unexpected error occurred. Please try again later.")
-	}
-}
-
-func mapDomainError(err *models.DomainError) (status int, errorCode string, message string) {
-	switch err.Code {
-	case models.CodeAccountNotFound:
-		return http.StatusNotFound, string(err.Code), err.Message
-	case models.CodeAccountAlreadyExists:
-		return http.StatusConflict, string(err.Code), err.Message
-	case models.CodeInsufficientBalance:
-		return http.StatusUnprocessableEntity, string(err.Code), err.Message
-	case models.CodeInvalidAmount:
-		return http.StatusBadRequest, string(err.Code), err.Message
-	case models.CodeSameAccount:
-		return http.StatusBadRequest, string(err.Code), err.Message
-	case models.CodeTransferNotFound:
-		return http.StatusNotFound, string(err.Code), err.Message
-	case models.CodeDuplicateTransaction:
-		return http.StatusConflict, string(err.Code), err.Message
-	case models.CodeDatabaseError, models.CodeTransactionFailed, models.CodeInternalError:
-		return http.StatusInternalServerError, "internal_error", "An unexpected error occurred. Please try again later."
-	default:
-		return http.StatusInternalServerError, "internal_error", "An unexpected error occurred. Please try again later."
+		return
 	}
+	logging.FromContext(ctx).Error().Err(err).Msg("Unexpected error in handler")
+	writeError(w, http.StatusInternalServerError, "internal_error", internalErrorMessage)
+}
+
+// transientRetryAfter is the wait suggested to clients after a transient database failure.
+const transientRetryAfter = time.Second
+
+// retryHint reports whether the request that failed with err is worth sending again, and
+// after how long. A cooldown, blackout, or daily limit says exactly when, and a service
+// too busy to start the request is worth trying again after a short wait. A transfer that
+// ran out of retries is retryable if its last failure was (deadlock, serialization,
+// connection). Other database errors only count when Postgres guarantees the transaction
+// rolled back (SQLSTATE class 40): a lost connection during COMMIT leaves the outcome
+// unknown, and a retry could apply the change twice.
+func retryHint(err error, domainErr *models.DomainError) (time.Duration, bool) {
+	var cooldown *models.CooldownError
+	if errors.As(err, &cooldown) {
+		return cooldown.RetryAfter, true
+	}
+	var blackout *models.BlackoutError
+	if errors.As(err, &blackout) {
+		return time.Until(blackout.Until), true
+	}
+	var velocity *models.VelocityLimitError
+	if errors.As(err, &velocity) {
+		return time.Until(velocity.ResetsAt), true
+	}
+	switch domainErr.Code {
+	case models.CodeServiceBusy:
+		return transientRetryAfter, true
+	case models.CodeTransactionFailed:
+		return transientRetryAfter, models.IsRetryable(domainErr.Cause)
+	case models.CodeDatabaseError:
+		return transientRetryAfter, models.IsSerializationFailure(domainErr.Cause)
+	}
+	return 0, false
+}
+
+// retryAfterSeconds rounds d up to whole seconds for a Retry-After header, never below 1.
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// errorDetails returns field-level detail for err, or nil if it carries none.
+func errorDetails(err error) *ErrorDetails {
+	var moneyErr *models.MoneyError
+	if errors.As(err, &moneyErr) {
+		return &ErrorDetails{Field: moneyErr.Field, Reason: string(moneyErr.Reason)}
+	}
+	return nil
 }
@@ -0,0 +1,91 @@
+//go:build integration
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/repository"
+	"internal-transfers-system/internal/service"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestIntegration_GetAccounts(t *testing.T) {
+	if err := testSuite.Clean(); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	accRepo := repository.NewAccountRepository(testSuite.Pool())
+	for id, balance := range map[int64]string{1: "100", 2: "250.5", 3: "0"} {
+		if err := accRepo.Create(context.Background(), &models.Account{AccountID: id, Balance: decimal.RequireFromString(balance)}); err != nil {
+			t.Fatalf("create account %d: %v", id, err)
+		}
+	}
+	limits := DefaultPageLimits()
+	limits.MaxBatchGet = 5
+	h := NewAccountHandlerWithLimits(service.NewAccountService(accRepo), limits)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/accounts", h.GetAccounts)
+
+	get := func(ids string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/accounts?ids="+ids, nil))
+		return rec
+	}
+
+	tests := []struct {
+		name        string
+		ids         string
+		wantFound   []int64
+		wantMissing []int64
+	}{
+		{"all found", "3,1,2", []int64{3, 1, 2}, []int64{}},
+		{"found and missing", "2,9,1,7", []int64{2, 1}, []int64{9, 7}},
+		{"all missing", "8,9", []int64{}, []int64{8, 9}},
+		{"repeated IDs", "1,1,9,9,1", []int64{1}, []int64{9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(tt.ids)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var resp GetAccountsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			found := make([]int64, 0, len(resp.Accounts))
+			for _, account := range resp.Accounts {
+				found = append(found, account.AccountID)
+			}
+			if fmt.Sprint(found) != fmt.Sprint(tt.wantFound) || fmt.Sprint(resp.Missing) != fmt.Sprint(tt.wantMissing) {
+				t.Errorf("expected found %v and missing %v, got %s", tt.wantFound, tt.wantMissing, rec.Body.String())
+			}
+		})
+	}
+
+	rec := get("2")
+	if !strings.Contains(rec.Body.String(), `"balance":"250.5"`) {
+		t.Errorf("expected account 2's balance, got %s", rec.Body.String())
+	}
+
+	// The cap counts distinct IDs
+	if rec := get("1,2,3,4,5,5,5"); rec.Code != http.StatusOK {
+		t.Errorf("expected 5 distinct IDs to be allowed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	for _, ids := range []string{"1,2,3,4,5,6", "", "1,abc", "0", "1,,2"} {
+		rec := get(ids)
+		var resp ValidationErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusBadRequest || len(resp.Errors) != 1 || resp.Errors[0].Field != "ids" {
+			t.Errorf("ids=%q: expected 400 naming ids, got %d %s", ids, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -4,11 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"internal-transfers-system/internal/mocks"
 	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/shopspring/decimal"
 )
 
 func TestDecodeJSONBody(t *testing.T) {
@@ -27,7 +36,7 @@ func TestDecodeJSONBody(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tt.body))
 			var target models.CreateAccountRequest
-			err := decodeJSONBody(req, &target)
+			err := decodeJSONBody(httptest.NewRecorder(), req, 0, &target)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("wantErr=%v, got err=%v", tt.wantErr, err)
 			}
@@ -35,6 +44,89 @@ func TestDecodeJSONBody(t *testing.T) {
 	}
 }
 
+func TestWriteDecodeError_FieldErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		target      interface{}
+		root        string
+		wantField   string
+		wantMessage string
+	}{
+		{"account_id as string", `{"account_id": "1"}`, &models.CreateAccountRequest{}, "", "account_id", "must be a number"},
+		{"account_id fractional", `{"account_id": 1.5}`, &models.CreateAccountRequest{}, "", "account_id", "must be an integer"},
+		{"account_id too large", `{"account_id": 99999999999999999999}`, &models.CreateAccountRequest{}, "", "account_id", "is out of range"},
+		{"initial_balance as number", `{"initial_balance": 100}`, &models.CreateAccountRequest{}, "", "initial_balance", "must be a string"},
+		{"max_balance as object", `{"max_balance": {}}`, &models.CreateAccountRequest{}, "", "max_balance", "must be a string"},
+		{"account_type as boolean", `{"account_type": true}`, &models.CreateAccountRequest{}, "", "account_type", "must be a string"},
+		{"overdraft_limit as array", `{"overdraft_limit": []}`, &models.CreateAccountRequest{}, "", "overdraft_limit", "must be a string"},
+		{"source_account_id as string", `{"source_account_id": "1"}`, &models.CreateTransactionRequest{}, "", "source_account_id", "must be a number"},
+		{"destination_account_id as boolean", `{"destination_account_id": false}`, &models.CreateTransactionRequest{}, "", "destination_account_id", "must be a number"},
+		{"amount as number", `{"amount": 10.5}`, &models.CreateTransactionRequest{}, "", "amount", "must be a string"},
+		{"effective_date as number", `{"effective_date": 20240301}`, &models.CreateTransactionRequest{}, "", "effective_date", "must be a string"},
+		{"sequence as string", `{"sequence": "3"}`, &models.CreateTransactionRequest{}, "", "sequence", "must be a number"},
+		{"category as number", `{"category": 7}`, &models.CreateTransactionRequest{}, "", "category", "must be a string"},
+		{"transfers as object", `{"transfers": {}}`, &models.CreateBatchTransferRequest{}, "", "transfers", "must be an array"},
+		{"nested amount", `{"transfers": [{"destination_account_id": 2, "amount": "1"}, {"amount": 1}]}`, &models.CreateBatchTransferRequest{}, "", "transfers[1].amount", "must be a string"},
+		{"batch item", `[{"account_id": 1}, {"account_id": "2"}]`, &[]models.CreateAccountRequest{}, "accounts", "accounts[1].account_id", "must be a number"},
+		{"unknown field", `{"account_id": 1, "extra": "field"}`, &models.CreateAccountRequest{}, "", "extra", "is not a known field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tt.body))
+			err := decodeJSONBody(rec, req, 0, tt.target)
+			if err == nil {
+				t.Fatal("expected a decode error")
+			}
+			writeDecodeErrorAt(rec, err, tt.root)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", rec.Code)
+			}
+			var resp ValidationErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Error != "validation_failed" || len(resp.Errors) != 1 ||
+				resp.Errors[0].Field != tt.wantField || resp.Errors[0].Message != tt.wantMessage {
+				t.Errorf("expected %s %q, got %s %+v", tt.wantField, tt.wantMessage, rec.Body.String(), resp)
+			}
+		})
+	}
+}
+
+func TestWriteDecodeError_MalformedJSON(t *testing.T) {
+	for _, body := range []string{`{invalid}`, `[1, 2]`, ``} {
+		rec := httptest.NewRecorder()
+		var target models.CreateAccountRequest
+		err := decodeJSONBody(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body)), 0, &target)
+		writeDecodeError(rec, err)
+		if rec.Code != http.StatusBadRequest || !bytes.Contains(rec.Body.Bytes(), []byte(`"invalid_json"`)) {
+			t.Errorf("%q: expected 400 invalid_json, got %d %s", body, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestDecodeJSONBody_PreservesLargeNumbers(t *testing.T) {
+	// 2^53 + 1 is the smallest integer float64 can't represent
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"amount": 9007199254740993.01}`))
+	var target map[string]interface{}
+	if err := decodeJSONBody(httptest.NewRecorder(), req, 0, &target); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	number, ok := target["amount"].(json.Number)
+	if !ok {
+		t.Fatalf("expected json.Number, got %T", target["amount"])
+	}
+	amount, err := decimal.NewFromString(number.String())
+	if err != nil || amount.String() != "9007199254740993.01" {
+		t.Errorf("expected 9007199254740993.01, got %s (%v)", amount, err)
+	}
+}
+
 func TestHandleServiceError(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -53,7 +145,7 @@ func TestHandleServiceError(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			rec := httptest.NewRecorder()
-			handleServiceError(context.Background(), rec, tt.err)
+			handleServiceError(context.Background(), rec, tt.err, nil)
 
 			if rec.Code != tt.wantStatus {
 				t.Errorf("expected %d, got %d", tt.wantStatus, rec.Code)
@@ -68,6 +160,98 @@ func TestHandleServiceError(t *testing.T) {
 	}
 }
 
+func TestHandleServiceError_CooldownRetryAfter(t *testing.T) {
+	err := models.WrapError(models.CodeCooldownActive, models.ErrCooldownActive.Message,
+		&models.CooldownError{RetryAfter: 2100 * time.Millisecond})
+	rec := httptest.NewRecorder()
+	handleServiceError(context.Background(), rec, err, nil)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "3" {
+		t.Errorf("expected Retry-After 3, got %q", got)
+	}
+	var resp ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Error != "cooldown_active" || !resp.Retryable || resp.RetryAfter != 3 {
+		t.Errorf("expected a retryable cooldown_active after 3s, got %+v", resp)
+	}
+}
+
+func TestHandleServiceError_Blackout(t *testing.T) {
+	until := time.Now().Add(90 * time.Second).Truncate(time.Second)
+	err := models.WrapError(models.CodeTransferBlackout, models.ErrTransferBlackout.Message,
+		&models.BlackoutError{Until: until})
+	rec := httptest.NewRecorder()
+	handleServiceError(context.Background(), rec, err, nil)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "90" && got != "89" {
+		t.Errorf("expected Retry-After of about 90, got %q", got)
+	}
+	var resp ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Error != "transfer_blackout" || !resp.Retryable || resp.AvailableAt != until.UTC().Format(models.TimestampLayout) {
+		t.Errorf("expected a retryable transfer_blackout available at %s, got %+v", until, resp)
+	}
+}
+
+func TestHandleServiceError_RetryHints(t *testing.T) {
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	tests := []struct {
+		name          string
+		err           error
+		wantRetryable bool
+	}{
+		{"retries exhausted on deadlock", models.WrapError(models.CodeTransactionFailed, "transfer failed after retries", deadlock), true},
+		{"retries exhausted on version conflicts", models.WrapError(models.CodeTransactionFailed, "transfer failed after retries", models.ErrConcurrentModification), true},
+		{"retries exhausted on connection loss", models.WrapError(models.CodeTransactionFailed, "transfer failed after retries", fmt.Errorf("connection reset by peer")), true},
+		{"database serialization failure", models.WrapError(models.CodeDatabaseError, "failed to commit transaction", deadlock), true},
+		{"commit connection loss", models.WrapError(models.CodeDatabaseError, "failed to commit transaction", fmt.Errorf("connection reset by peer")), false},
+		{"connection pool exhausted", models.ErrServiceBusy, true},
+		{"business rule", models.ErrInsufficientBalance, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handleServiceError(context.Background(), rec, tt.err, nil)
+
+			var resp ErrorResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if resp.Retryable != tt.wantRetryable {
+				t.Errorf("expected retryable=%v, got %+v", tt.wantRetryable, resp)
+			}
+			if tt.wantRetryable && (resp.RetryAfter != 1 || rec.Header().Get("Retry-After") != "1") {
+				t.Errorf("expected a 1s retry hint, got %d and header %q", resp.RetryAfter, rec.Header().Get("Retry-After"))
+			}
+			if !tt.wantRetryable && (resp.RetryAfter != 0 || rec.Header().Get("Retry-After") != "") {
+				t.Errorf("expected no retry hint, got %d and header %q", resp.RetryAfter, rec.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+type abortCounter struct{ canceled, timedOut int }
+
+func (c *abortCounter) RequestCanceled() { c.canceled++ }
+func (c *abortCounter) RequestTimedOut() { c.timedOut++ }
+
+func TestHandleServiceError_CountsAbortsByReason(t *testing.T) {
+	counter := &abortCounter{}
+
+	handleServiceError(context.Background(), httptest.NewRecorder(), context.Canceled, counter)
+	handleServiceError(context.Background(), httptest.NewRecorder(), fmt.Errorf("transfer: %w", context.Canceled), counter)
+	handleServiceError(context.Background(), httptest.NewRecorder(), context.DeadlineExceeded, counter)
+	handleServiceError(context.Background(), httptest.NewRecorder(), models.ErrAccountNotFound, counter)
+
+	if counter.canceled != 2 || counter.timedOut != 1 {
+		t.Errorf("expected 2 cancellations and 1 timeout, got %+v", *counter)
+	}
+}
+
 func TestMapDomainError(t *testing.T) {
 	tests := []struct {
 		code       models.ErrorCode
@@ -76,7 +260,13 @@ func TestMapDomainError(t *testing.T) {
 		{models.CodeAccountNotFound, http.StatusNotFound},
 		{models.CodeAccountAlreadyExists, http.StatusConflict},
 		{models.CodeInsufficientBalance, http.StatusUnprocessableEntity},
+		{models.CodeDestBalanceLimit, http.StatusUnprocessableEntity},
+		{models.CodeFeeExceedsAmount, http.StatusUnprocessableEntity},
+		{models.CodeAmountBelowMinimum, http.StatusUnprocessableEntity},
+		{models.CodeAmountAboveMaximum, http.StatusUnprocessableEntity},
+		{models.CodeVelocityLimit, http.StatusTooManyRequests},
 		{models.CodeInvalidAmount, http.StatusBadRequest},
+		{models.CodeInvalidAdjustment, http.StatusBadRequest},
 		{models.CodeDatabaseError, http.StatusInternalServerError},
 	}
 
@@ -90,3 +280,688 @@ func TestMapDomainError(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateAccount_Warnings(t *testing.T) {
+	svc := service.NewAccountServiceWithConfig(mocks.NewMockAccountRepository(), service.AccountServiceConfig{
+		InitialBalanceWarnThreshold: decimal.NewFromInt(1000000),
+	})
+	h := NewAccountHandler(svc)
+
+	create := func(body string) (*httptest.ResponseRecorder, map[string]json.RawMessage) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/accounts", bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		h.CreateAccount(rec, req)
+		var resp map[string]json.RawMessage
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	rec, resp := create(`{"account_id": 1, "initial_balance": "1000000000"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var warnings []models.Warning
+	json.Unmarshal(resp["warnings"], &warnings)
+	if len(warnings) != 1 || warnings[0].Code != "large_initial_balance" || warnings[0].Field != "initial_balance" {
+		t.Errorf("expected a large_initial_balance warning, got %s", rec.Body.String())
+	}
+
+	rec, resp = create(`{"account_id": 2, "initial_balance": "1000.00"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := resp["warnings"]; ok {
+		t.Errorf("expected no warnings field, got %s", rec.Body.String())
+	}
+}
+
+func TestCreateAccount_Idempotent(t *testing.T) {
+	h := NewAccountHandler(service.NewAccountService(mocks.NewMockAccountRepository()))
+
+	tests := []struct {
+		name         string
+		body         string
+		wantStatus   int
+		wantReplayed bool
+	}{
+		{"create", `{"account_id": 1, "initial_balance": "100", "idempotent": true}`, http.StatusCreated, false},
+		{"repeat with matching balance", `{"account_id": 1, "initial_balance": "100.00", "idempotent": true}`, http.StatusOK, true},
+		{"repeat with different balance", `{"account_id": 1, "initial_balance": "200", "idempotent": true}`, http.StatusConflict, false},
+		{"repeat without idempotent", `{"account_id": 1, "initial_balance": "100"}`, http.StatusConflict, false},
+	}
+
+	// Each case builds on the account the first one created
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/accounts", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			h.CreateAccount(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get(IdempotentReplayedHeader) == "true"; got != tt.wantReplayed {
+				t.Errorf("expected replayed header %v, got %v", tt.wantReplayed, got)
+			}
+			if rec.Code == http.StatusConflict {
+				var resp ErrorResponse
+				json.Unmarshal(rec.Body.Bytes(), &resp)
+				if resp.Error != "account_exists" {
+					t.Errorf("expected account_exists, got %s", rec.Body.String())
+				}
+				return
+			}
+
+			var resp models.GetAccountResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if resp.AccountID != 1 || resp.Balance != "100" {
+				t.Errorf("unexpected account %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestBatchCreateAccounts(t *testing.T) {
+	body := `[
+		{"account_id": 10, "initial_balance": "100"},
+		{"account_id": 1, "initial_balance": "5"},
+		{"account_id": -1, "initial_balance": "abc"},
+		{"initial_balance": "7.5"}
+	]`
+	newHandler := func() (*AccountHandler, *mocks.MockAccountRepository) {
+		repo := mocks.NewMockAccountRepository()
+		repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1)})
+		return NewAccountHandler(service.NewAccountService(repo)), repo
+	}
+	post := func(h *AccountHandler, query, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/accounts/batch"+query, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		h.BatchCreateAccounts(rec, req)
+		return rec
+	}
+
+	t.Run("partial", func(t *testing.T) {
+		h, repo := newHandler()
+		rec := post(h, "", body)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp BatchCreateAccountsResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if len(resp.Created) != 2 || resp.Created[0] != 10 || resp.Created[1] != 2 {
+			t.Errorf("expected accounts [10 2] created, got %v", resp.Created)
+		}
+		if len(resp.Failed) != 2 {
+			t.Fatalf("expected two failures, got %s", rec.Body.String())
+		}
+		if f := resp.Failed[0]; f.Index != 1 || f.AccountID != 1 || f.Error != "account_exists" {
+			t.Errorf("expected index 1 to fail as already existing, got %+v", f)
+		}
+		if f := resp.Failed[1]; f.Index != 2 || f.Error != "validation_failed" || len(f.Errors) != 2 || f.Errors[0].Field != "accounts[2].account_id" {
+			t.Errorf("expected index 2 to fail validation, got %+v", f)
+		}
+		if acc, ok := repo.GetAccount(2); !ok || acc.Balance.String() != "7.5" {
+			t.Errorf("expected generated account 2 with balance 7.5, got %+v", acc)
+		}
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		h, repo := newHandler()
+		rec := post(h, "?atomic=true", body)
+		if rec.Code != http.StatusBadRequest || !bytes.Contains(rec.Body.Bytes(), []byte(`"accounts[2].initial_balance"`)) {
+			t.Errorf("expected 400 with accounts[2] errors, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if _, ok := repo.GetAccount(10); ok {
+			t.Error("expected no account to be created")
+		}
+
+		rec = post(h, "?atomic=true", `[{"account_id": 10, "initial_balance": "1"}, {"account_id": 1, "initial_balance": "1"}]`)
+		if rec.Code != http.StatusConflict || !bytes.Contains(rec.Body.Bytes(), []byte("accounts[1]")) {
+			t.Errorf("expected 409 naming accounts[1], got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		rec = post(h, "?atomic=true", `[{"account_id": 20, "initial_balance": "1"}, {"account_id": 21, "initial_balance": "2"}]`)
+		if rec.Code != http.StatusCreated || !bytes.Contains(rec.Body.Bytes(), []byte(`"created":[20,21],"failed":[]`)) {
+			t.Errorf("expected both accounts created, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("nothing created", func(t *testing.T) {
+		h, _ := newHandler()
+		rec := post(h, "", `[{"account_id": 1, "initial_balance": "1"}]`)
+		if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"created":[]`)) {
+			t.Errorf("expected 200 with nothing created, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if rec := post(h, "", `[]`); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for an empty batch, got %d", rec.Code)
+		}
+	})
+}
+
+func TestGetAccount_Scale(t *testing.T) {
+	repo := mocks.NewMockAccountRepository()
+	repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.RequireFromString("100.125")})
+	h := NewAccountHandler(service.NewAccountService(repo))
+
+	tests := []struct {
+		query       string
+		wantStatus  int
+		wantBalance string
+	}{
+		{"", http.StatusOK, "100.125"},
+		{"?scale=2", http.StatusOK, "100.13"},
+		{"?scale=0", http.StatusOK, "100"},
+		{"?scale=4", http.StatusOK, "100.1250"},
+		{"?scale=-1", http.StatusBadRequest, ""},
+		{"?scale=19", http.StatusBadRequest, ""},
+		{"?scale=two", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/1"+tt.query, nil)
+			req.SetPathValue("id", "1")
+			rec := httptest.NewRecorder()
+			h.GetAccount(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var resp models.GetAccountResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if resp.Balance != tt.wantBalance {
+				t.Errorf("expected balance %s, got %s", tt.wantBalance, resp.Balance)
+			}
+		})
+	}
+
+	if acc, _ := repo.GetAccount(1); acc.Balance.String() != "100.125" {
+		t.Errorf("stored balance changed to %s", acc.Balance)
+	}
+}
+
+func TestGetAccount_MinorUnits(t *testing.T) {
+	repo := mocks.NewMockAccountRepository()
+	repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.RequireFromString("150.5")})
+	repo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(1500)})
+
+	tests := []struct {
+		name        string
+		minorUnits  int
+		id, query   string
+		wantBalance string
+	}{
+		{"padded to cents", 2, "1", "", "150.50"},
+		{"whole amount padded", 2, "2", "", "1500.00"},
+		{"zero-decimal currency", 0, "2", "", "1500"},
+		{"never rounded", 0, "1", "", "150.5"},
+		{"scale overrides", 2, "1", "?scale=0", "151"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.FixedAmounts, opts.MinorUnits = true, tt.minorUnits
+			h := NewAccountHandlerWithOptions(service.NewAccountService(repo), opts)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+tt.id+tt.query, nil)
+			req.SetPathValue("id", tt.id)
+			rec := httptest.NewRecorder()
+			h.GetAccount(rec, req)
+
+			var resp models.GetAccountResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if rec.Code != http.StatusOK || resp.Balance != tt.wantBalance {
+				t.Errorf("expected 200 with balance %s, got %d: %s", tt.wantBalance, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetAccount_AmountFormat(t *testing.T) {
+	repo := mocks.NewMockAccountRepository()
+	repo.SetAccount(&models.Account{
+		AccountID: 1, Balance: decimal.RequireFromString("150.5"),
+		MaxBalance: decimal.NewNullDecimal(decimal.NewFromInt(1000)),
+	})
+	h := NewAccountHandler(service.NewAccountService(repo))
+
+	get := func(format string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/1", nil)
+		req.SetPathValue("id", "1")
+		if format != "" {
+			req.Header.Set(AmountFormatHeader, format)
+		}
+		rec := httptest.NewRecorder()
+		h.GetAccount(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	plainRec, plain := get("")
+	if plain["balance"] != "150.5" || plain["available_balance"] != "150.5" || plain["max_balance"] != "1000" {
+		t.Errorf("expected plain decimal strings, got %v", plain)
+	}
+
+	objectRec, object := get(AmountFormatObject)
+	money := func(value string) map[string]interface{} {
+		return map[string]interface{}{"value": value, "currency": DefaultCurrency}
+	}
+	for field, want := range map[string]string{
+		"balance": "150.5", "held_balance": "0", "overdraft_limit": "0", "available_balance": "150.5", "max_balance": "1000",
+	} {
+		if got, ok := object[field].(map[string]interface{}); !ok || got["value"] != want || got["currency"] != "USD" {
+			t.Errorf("%s: expected %v, got %v", field, money(want), object[field])
+		}
+	}
+	if object["account_id"] != float64(1) || object["status"] != plain["status"] {
+		t.Errorf("expected the other fields unchanged, got %v", object)
+	}
+
+	// A cache must not serve one format for the other
+	if objectRec.Header().Get("Vary") != AmountFormatHeader || objectRec.Header().Get("ETag") == plainRec.Header().Get("ETag") {
+		t.Errorf("expected Vary: %s and distinct ETags, got %v and %v", AmountFormatHeader, plainRec.Header(), objectRec.Header())
+	}
+
+	// The admin listing embeds the account response and must keep its own fields
+	list := httptest.NewRequest(http.MethodGet, "/api/v1/admin/accounts", nil)
+	list.Header.Set(AmountFormatHeader, AmountFormatObject)
+	rec := httptest.NewRecorder()
+	h.ListAccounts(rec, list)
+	var page struct {
+		Accounts []map[string]interface{} `json:"accounts"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &page)
+	if len(page.Accounts) != 1 || page.Accounts[0]["created_at"] == nil || page.Accounts[0]["balance"] == nil {
+		t.Fatalf("expected one account with created_at, got %s", rec.Body.String())
+	}
+	if _, ok := page.Accounts[0]["balance"].(map[string]interface{}); !ok {
+		t.Errorf("expected a money object balance in the listing, got %v", page.Accounts[0]["balance"])
+	}
+}
+
+func TestCloseAccount(t *testing.T) {
+	repo := mocks.NewMockAccountRepository()
+	repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.Zero})
+	repo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(5)})
+	h := NewAccountHandler(service.NewAccountService(repo))
+
+	do := func(method, id, query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(method, "/api/v1/accounts/"+id+query, nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		if method == http.MethodDelete {
+			h.CloseAccount(rec, req)
+		} else {
+			h.GetAccount(rec, req)
+		}
+		var resp map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	rec, resp := do(http.MethodGet, "1", "")
+	if rec.Code != http.StatusOK || resp["status"] != "open" || resp["closed_at"] != nil {
+		t.Fatalf("expected an open account, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec, resp = do(http.MethodDelete, "1", "")
+	if rec.Code != http.StatusOK || resp["status"] != "closed" || resp["closed_at"] == nil {
+		t.Fatalf("expected the account to be closed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec, resp = do(http.MethodGet, "1", ""); rec.Code != http.StatusOK || resp["status"] != "closed" {
+		t.Errorf("expected GetAccount to report closed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec, resp = do(http.MethodDelete, "1", ""); rec.Code != http.StatusConflict || resp["error"] != "account_already_closed" {
+		t.Errorf("expected 409 account_already_closed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec, resp = do(http.MethodDelete, "2", ""); rec.Code != http.StatusConflict || resp["error"] != "account_not_empty" {
+		t.Errorf("expected 409 account_not_empty, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec, resp = do(http.MethodDelete, "2", "?force=true"); rec.Code != http.StatusOK || resp["balance"] != "5" {
+		t.Errorf("expected a forced close keeping the balance, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec, _ = do(http.MethodDelete, "9", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestBatchAdjustBalances(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBal1   string
+	}{
+		{"success", `{"reason": "fee refund", "adjustments": [{"account_id": 2, "delta": "-5"}, {"account_id": 1, "delta": "2.5"}]}`, http.StatusOK, "102.5"},
+		{"validation error", `{"reason": "", "adjustments": [{"account_id": 1, "delta": "0"}]}`, http.StatusBadRequest, "100"},
+		{"would go negative", `{"reason": "fix", "adjustments": [{"account_id": 1, "delta": "1"}, {"account_id": 2, "delta": "-51"}]}`, http.StatusUnprocessableEntity, "100"},
+		{"unknown account", `{"reason": "fix", "adjustments": [{"account_id": 1, "delta": "1"}, {"account_id": 9, "delta": "1"}]}`, http.StatusNotFound, "100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockAccountRepository()
+			repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+			repo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(50)})
+			h := NewAccountHandler(service.NewAccountService(repo))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/accounts:batchAdjust", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			h.BatchAdjustBalances(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if acc, _ := repo.GetAccount(1); acc.Balance.String() != tt.wantBal1 {
+				t.Errorf("expected account 1 balance %s, got %s", tt.wantBal1, acc.Balance)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var resp models.BatchAdjustResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if resp.BatchID == 0 || resp.Reason != "fee refund" || len(resp.Adjustments) != 2 {
+				t.Fatalf("unexpected response: %s", rec.Body.String())
+			}
+			if got := resp.Adjustments[1]; got.AccountID != 2 || got.Delta != "-5" || got.Balance != "45" {
+				t.Errorf("unexpected adjustment result: %+v", got)
+			}
+		})
+	}
+}
+
+func TestReconcileBalances(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		wantStatus int
+		wantBal1   string
+	}{
+		{"detect only", "", `[{"account_id": 2, "expected_balance": "50"}, {"account_id": 1, "expected_balance": "90"}, {"account_id": 9, "expected_balance": "1"}]`, http.StatusOK, "100"},
+		{"apply", "?apply=true&reason=month-end", `[{"account_id": 2, "expected_balance": "50"}, {"account_id": 1, "expected_balance": "90"}, {"account_id": 9, "expected_balance": "1"}]`, http.StatusOK, "90"},
+		{"validation error", "?apply=true", `[{"account_id": 1, "expected_balance": "abc"}]`, http.StatusBadRequest, "100"},
+		{"not an array", "", `{"account_id": 1, "expected_balance": "90"}`, http.StatusBadRequest, "100"},
+		{"correction would go negative", "?apply=true", `[{"account_id": 1, "expected_balance": "90"}, {"account_id": 2, "expected_balance": "-1"}]`, http.StatusUnprocessableEntity, "100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockAccountRepository()
+			repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+			repo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(50)})
+			h := NewAccountHandler(service.NewAccountService(repo))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/reconcile"+tt.query, bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			h.ReconcileBalances(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if acc, _ := repo.GetAccount(1); acc.Balance.String() != tt.wantBal1 {
+				t.Errorf("expected account 1 balance %s, got %s", tt.wantBal1, acc.Balance)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var got models.ReconcileResponse
+			json.Unmarshal(rec.Body.Bytes(), &got)
+			if len(got.Accounts) != 3 || got.Accounts[0].AccountID != 1 || got.Accounts[2].AccountID != 9 {
+				t.Fatalf("expected accounts ordered by ID, got %s", rec.Body.String())
+			}
+			if a := got.Accounts[0]; a.StoredBalance != "100" || a.ExpectedBalance != "90" || a.Difference != "-10" {
+				t.Errorf("unexpected diff for account 1: %+v", a)
+			}
+			if a := got.Accounts[1]; a.Status != "matched" || a.Difference != "0" {
+				t.Errorf("unexpected diff for account 2: %+v", a)
+			}
+			if a := got.Accounts[2]; a.Status != "missing" || a.StoredBalance != "" {
+				t.Errorf("unexpected diff for account 9: %+v", a)
+			}
+
+			if got.Applied {
+				if got.Accounts[0].Status != "corrected" || got.Accounts[0].AdjustmentID == 0 || got.BatchID == 0 || got.Reason != "month-end" {
+					t.Errorf("unexpected applied report: %s", rec.Body.String())
+				}
+				if got.Summary != (models.ReconcileSummary{Matched: 1, Corrected: 1, Missing: 1}) {
+					t.Errorf("unexpected summary %+v", got.Summary)
+				}
+				return
+			}
+			if got.Accounts[0].Status != "mismatched" || got.BatchID != 0 || got.Reason != "reconciliation" {
+				t.Errorf("unexpected detect-only report: %s", rec.Body.String())
+			}
+			if len(repo.Adjustments()) != 0 {
+				t.Error("expected no audit entries in detect-only mode")
+			}
+		})
+	}
+}
+
+func TestGetBalanceHistory(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	transferSvc := service.NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+	for _, amount := range []string{"100", "50"} {
+		if _, err := transferSvc.Transfer(context.Background(), &models.CreateTransactionRequest{
+			SourceAccountID: 1, DestinationAccountID: 2, Amount: amount,
+		}); err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+	}
+	h := NewAccountHandler(service.NewAccountService(accRepo))
+
+	get := func(id, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+id+"/balance-history"+query, nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		h.GetBalanceHistory(rec, req)
+		return rec
+	}
+
+	rec := get("2", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp []BalanceChangeResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", resp)
+	}
+	// Newest first
+	if resp[0].OldBalance != "600" || resp[0].NewBalance != "650" || resp[1].OldBalance != "500" || resp[1].NewBalance != "600" {
+		t.Errorf("unexpected history: %+v", resp)
+	}
+	if resp[0].TransactionID == nil || resp[0].AdjustmentID != nil {
+		t.Errorf("expected a transaction-sourced change, got %+v", resp[0])
+	}
+
+	rec = get("2", "?limit=1&offset=1")
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp) != 1 || resp[0].NewBalance != "600" {
+		t.Errorf("limit=1&offset=1: unexpected page %+v", resp)
+	}
+
+	if rec := get("999", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown account: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestListAccounts(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	for id, balance := range map[int64]int64{1: 50, 2: 200, 3: 300, 4: 200} {
+		accRepo.SetAccount(&models.Account{AccountID: id, Balance: decimal.NewFromInt(balance)})
+	}
+	h := NewAccountHandler(service.NewAccountService(accRepo))
+
+	list := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ListAccounts(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/accounts"+query, nil))
+		return rec
+	}
+
+	rec := list("?min_balance=100&sort=-balance&limit=2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp AccountListResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Total != 3 || resp.Limit != 2 || resp.Offset != 0 || len(resp.Accounts) != 2 {
+		t.Fatalf("unexpected page %s", rec.Body.String())
+	}
+	if resp.Accounts[0].AccountID != 3 || resp.Accounts[1].AccountID != 4 || resp.Accounts[1].Balance != "200" {
+		t.Errorf("expected accounts 3 then 4, got %+v", resp.Accounts)
+	}
+
+	rec = list("?offset=10")
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusOK || resp.Total != 4 || resp.Accounts == nil || len(resp.Accounts) != 0 {
+		t.Errorf("past the end: expected an empty page of 4, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	for query, field := range map[string]string{
+		"?min_balance=abc":                    "min_balance",
+		"?max_balance=1e":                     "max_balance",
+		"?min_balance=10&max_balance=5":       "max_balance",
+		"?sort=balance%3BDROP+TABLE+accounts": "sort",
+		"?sort=--balance":                     "sort",
+	} {
+		rec := list(query)
+		var errResp ValidationErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errResp)
+		if rec.Code != http.StatusBadRequest || len(errResp.Errors) != 1 || errResp.Errors[0].Field != field {
+			t.Errorf("%s: expected 400 naming %s, got %d %s", query, field, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestGetAccounts(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.RequireFromString("10.255")})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(20)})
+	limits := DefaultPageLimits()
+	limits.MaxBatchGet = 3
+	h := NewAccountHandlerWithLimits(service.NewAccountService(accRepo), limits)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.GetAccounts(rec, httptest.NewRequest(http.MethodGet, "/api/v1/accounts"+query, nil))
+		return rec
+	}
+
+	rec := get("?ids=2,%203,1,2&scale=2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp GetAccountsResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Accounts) != 2 || resp.Accounts[0].AccountID != 2 || resp.Accounts[1].AccountID != 1 ||
+		len(resp.Missing) != 1 || resp.Missing[0] != 3 {
+		t.Fatalf("expected accounts 2 and 1 with 3 missing, got %s", rec.Body.String())
+	}
+	if resp.Accounts[1].Balance != "10.26" {
+		t.Errorf("expected the scale applied, got %s", resp.Accounts[1].Balance)
+	}
+
+	if rec := get("?ids=1,2,3,4"); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "at most 3") {
+		t.Errorf("over the cap: expected 400, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	accRepo.GetByIDError = errors.New("connection refused")
+	if rec := get("?ids=1"); rec.Code != http.StatusInternalServerError {
+		t.Errorf("repository failure: expected 500, got %d", rec.Code)
+	}
+}
+
+func TestGetAccount_ETag(t *testing.T) {
+	repo := mocks.NewMockAccountRepository()
+	repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	h := NewAccountHandler(service.NewAccountService(repo))
+
+	get := func(id, query, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+id+query, nil)
+		req.SetPathValue("id", id)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		h.GetAccount(rec, req)
+		return rec
+	}
+
+	first := get("1", "", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Body.Len() == 0 {
+		t.Fatalf("expected 200 with a body, got %d", first.Code)
+	}
+	if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) || len(etag) < 3 {
+		t.Fatalf("expected a strong ETag, got %q", etag)
+	}
+	if got := first.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("expected Cache-Control no-cache, got %q", got)
+	}
+	if again := get("1", "", ""); again.Header().Get("ETag") != etag {
+		t.Errorf("expected a stable ETag, got %q then %q", etag, again.Header().Get("ETag"))
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{"matching tag", etag, http.StatusNotModified},
+		{"weakened tag", "W/" + etag, http.StatusNotModified},
+		{"tag in a list", `"stale", ` + etag, http.StatusNotModified},
+		{"any tag", "*", http.StatusNotModified},
+		{"other tag", `"stale"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get("1", "", tt.ifNoneMatch)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if rec.Header().Get("ETag") != etag {
+				t.Errorf("expected ETag %s, got %q", etag, rec.Header().Get("ETag"))
+			}
+			if tt.wantStatus == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("expected no body with 304, got %s", rec.Body.String())
+			}
+		})
+	}
+
+	// Rounding changes the representation, so it changes the tag
+	if scaled := get("1", "?scale=2", etag); scaled.Code != http.StatusOK || scaled.Header().Get("ETag") == etag {
+		t.Errorf("expected a different ETag for another scale, got %d with %q", scaled.Code, scaled.Header().Get("ETag"))
+	}
+
+	// A balance change invalidates the old tag
+	repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(90)})
+	rec := get("1", "", etag)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Fatalf("expected 200 with a new ETag after the balance changed, got %d with %q", rec.Code, rec.Header().Get("ETag"))
+	}
+	var resp models.GetAccountResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Balance != "90" {
+		t.Errorf("expected the new balance 90, got %s", resp.Balance)
+	}
+
+	if rec := get("999", "", "*"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown account regardless of If-None-Match, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/validator"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+)
+
+// accountSorts are the values the sort query parameter accepts. A leading "-" sorts
+// descending.
+var accountSorts = map[string]models.AccountSortField{
+	"account_id": models.AccountSortByID,
+	"balance":    models.AccountSortByBalance,
+	"created_at": models.AccountSortByCreatedAt,
+}
+
+// AdminAccountResponse is an account in the admin listing: the fields
+// GET /api/v1/accounts/{id} returns, plus when it was created.
+type AdminAccountResponse struct {
+	models.GetAccountResponse
+	CreatedAt string `json:"created_at"`
+}
+
+// MarshalJSON appends created_at to the account's own encoding. Without it the promoted
+// GetAccountResponse.MarshalJSON would encode the account alone and drop CreatedAt.
+func (r AdminAccountResponse) MarshalJSON() ([]byte, error) {
+	account, err := json.Marshal(r.GetAccountResponse)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := json.Marshal(r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	// account is a non-empty object, since account_id is never omitted
+	out := append(account[:len(account)-1], `,"created_at":`...)
+	out = append(out, createdAt...)
+	return append(out, '}'), nil
+}
+
+// AccountListResponse is a page of the admin account listing. Total counts every
+// matching account, not just this page.
+type AccountListResponse struct {
+	Accounts []AdminAccountResponse `json:"accounts"`
+	Total    int64                  `json:"total"`
+	Limit    int                    `json:"limit"`
+	Offset   int                    `json:"offset"`
+}
+
+// ListAccounts returns a page of accounts for support staff, optionally limited to
+// balances between min_balance and max_balance (inclusive) and ordered by sort:
+// account_id (the default), balance, or created_at, prefixed with "-" for descending.
+// limit and offset page like the offset transaction listing.
+func (h *AccountHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, errs := parseAccountListFilter(r)
+	if len(errs) > 0 {
+		log.Debug().Interface("errors", errs).Msg("List accounts validation failed")
+		writeValidationError(w, errs)
+		return
+	}
+	filter.Limit = h.limits.listingLimit(r)
+	filter.Offset = queryInt(r, "offset", 0)
+
+	accounts, total, err := h.accountService.ListAccounts(ctx, filter)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	resp := AccountListResponse{
+		Accounts: make([]AdminAccountResponse, 0, len(accounts)),
+		Total:    total,
+		Limit:    filter.Limit,
+		Offset:   filter.Offset,
+	}
+	currency := amountCurrency(w, r, h.currency)
+	for _, account := range accounts {
+		resp.Accounts = append(resp.Accounts, AdminAccountResponse{
+			GetAccountResponse: newAccountResponse(account, h.money, currency),
+			CreatedAt:          account.CreatedAt.UTC().Format(models.TimestampLayout),
+		})
+	}
+	writeSuccess(w, http.StatusOK, resp)
+}
+
+// parseAccountListFilter reads the balance bounds and sort order of an account listing.
+func parseAccountListFilter(r *http.Request) (models.AccountListFilter, validator.ValidationErrors) {
+	query := r.URL.Query()
+	var filter models.AccountListFilter
+	var errs validator.ValidationErrors
+
+	for _, bound := range []struct {
+		name string
+		dst  *decimal.NullDecimal
+	}{{"min_balance", &filter.MinBalance}, {"max_balance", &filter.MaxBalance}} {
+		raw := query.Get(bound.name)
+		if raw == "" {
+			continue
+		}
+		value, err := decimal.NewFromString(raw)
+		if err != nil {
+			errs = append(errs, validator.ValidationError{Field: bound.name, Message: "must be a valid decimal number"})
+			continue
+		}
+		*bound.dst = decimal.NewNullDecimal(value)
+	}
+	if filter.MinBalance.Valid && filter.MaxBalance.Valid && filter.MaxBalance.Decimal.LessThan(filter.MinBalance.Decimal) {
+		errs = append(errs, validator.ValidationError{Field: "max_balance", Message: "must not be less than min_balance"})
+	}
+
+	if raw := query.Get("sort"); raw != "" {
+		name, descending := strings.CutPrefix(raw, "-")
+		sortBy, ok := accountSorts[name]
+		if !ok {
+			errs = append(errs, validator.ValidationError{Field: "sort", Message: "must be account_id, balance, or created_at, optionally prefixed with -"})
+		}
+		filter.SortBy, filter.Descending = sortBy, descending
+	}
+
+	return filter, errs
+}
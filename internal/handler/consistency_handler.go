@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"internal-transfers-system/internal/models"
+)
+
+// LedgerConsistencyResponse reports whether account balances agree with the recorded
+// history. ExpectedBalance is initial_balances + net_transactions + adjustments, and
+// Drift is total_balance less that. DriftedAccounts lists at most
+// service.MaxDriftedAccounts accounts.
+type LedgerConsistencyResponse struct {
+	Consistent      bool                   `json:"consistent"`
+	Accounts        int64                  `json:"accounts"`
+	TotalBalance    string                 `json:"total_balance"`
+	InitialBalances string                 `json:"initial_balances"`
+	NetTransactions string                 `json:"net_transactions"`
+	Adjustments     string                 `json:"adjustments"`
+	ExpectedBalance string                 `json:"expected_balance"`
+	Drift           string                 `json:"drift"`
+	DriftedAccounts []AccountDriftResponse `json:"drifted_accounts"`
+	CheckedAt       string                 `json:"checked_at"`
+}
+
+// AccountDriftResponse is an account whose balance disagrees with its history.
+type AccountDriftResponse struct {
+	AccountID int64  `json:"account_id"`
+	Balance   string `json:"balance"`
+	Expected  string `json:"expected"`
+	Drift     string `json:"drift"`
+}
+
+// VerifyLedger compares account balances with the transactions, initial balances, and
+// adjustments that should explain them. A 200 is returned either way; consistent says
+// whether drift was found.
+func (h *TransactionHandler) VerifyLedger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v, err := h.transferService.VerifyLedger(ctx)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	resp := LedgerConsistencyResponse{
+		Consistent:      v.Consistent(),
+		Accounts:        v.Accounts,
+		TotalBalance:    models.FormatMoney(v.TotalBalance),
+		InitialBalances: models.FormatMoney(v.InitialBalances),
+		NetTransactions: models.FormatMoney(v.NetTransactions),
+		Adjustments:     models.FormatMoney(v.Adjustments),
+		ExpectedBalance: models.FormatMoney(v.Expected()),
+		Drift:           models.FormatMoney(v.Drift()),
+		DriftedAccounts: make([]AccountDriftResponse, 0, len(v.DriftedAccounts)),
+		CheckedAt:       time.Now().UTC().Format(models.TimestampLayout),
+	}
+	for _, d := range v.DriftedAccounts {
+		resp.DriftedAccounts = append(resp.DriftedAccounts, AccountDriftResponse{
+			AccountID: d.AccountID,
+			Balance:   models.FormatMoney(d.Balance),
+			Expected:  models.FormatMoney(d.Expected),
+			Drift:     models.FormatMoney(d.Drift()),
+		})
+	}
+	writeSuccess(w, http.StatusOK, resp)
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+
+	"internal-transfers-system/internal/models"
+)
+
+// internalErrorMessage is the message sent in place of one that isn't Public.
+const internalErrorMessage = "An unexpected error occurred. Please try again later."
+
+// mapDomainError returns the status, error code, and message to report err with.
+func mapDomainError(err *models.DomainError) (status int, errorCode string, message string) {
+	s, ok := models.StatusOf(err.Code)
+	if !ok || !s.Public {
+		return http.StatusInternalServerError, "internal_error", internalErrorMessage
+	}
+	return s.HTTP, string(err.Code), err.Message
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"internal-transfers-system/internal/models"
+)
+
+func TestMapDomainError_HidesNonPublicMessages(t *testing.T) {
+	status, errorCode, message := mapDomainError(&models.DomainError{Code: models.CodeInsufficientBalance, Message: "detail"})
+	if status != http.StatusUnprocessableEntity || errorCode != string(models.CodeInsufficientBalance) || message != "detail" {
+		t.Errorf("expected a public error with its own code and message, got %d %s %q", status, errorCode, message)
+	}
+
+	for _, code := range []models.ErrorCode{models.CodeDatabaseError, models.CodeTransactionFailed, models.CodeInternalError, "not_registered"} {
+		status, errorCode, message := mapDomainError(&models.DomainError{Code: code, Message: "secret detail"})
+		if status != http.StatusInternalServerError || errorCode != "internal_error" || message != internalErrorMessage {
+			t.Errorf("%s: expected a generic internal_error, got %d %s %q", code, status, errorCode, message)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"internal-transfers-system/internal/idcodec"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/validator"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HoldResponse represents a hold. TransactionID is the capturing withdrawal, set once
+// the hold is captured; ResolvedAt is omitted while it is active.
+type HoldResponse struct {
+	HoldID        int64     `json:"hold_id"`
+	AccountID     int64     `json:"account_id"`
+	Amount        string    `json:"amount"`
+	Status        string    `json:"status"`
+	TransactionID *PublicID `json:"transaction_id,omitempty"`
+	CreatedAt     string    `json:"created_at"`
+	ResolvedAt    string    `json:"resolved_at,omitempty"`
+}
+
+// PlaceHold reserves part of account {id}'s available balance, rejecting with 422
+// insufficient_balance if it can't cover the amount.
+func (h *TransactionHandler) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	accountID, ok := parseAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateHoldRequest
+	if err := decodeJSONBody(w, r, h.maxRequestBody, &req); err != nil {
+		log.Debug().Err(err).Msg("Failed to decode create hold request")
+		writeDecodeError(w, err)
+		return
+	}
+
+	if errs := validator.ValidateCreateHold(&req); len(errs) > 0 {
+		log.Debug().Int64("accountID", accountID).Interface("errors", errs).Msg("Create hold validation failed")
+		writeValidationError(w, errs)
+		return
+	}
+
+	amount, err := models.ParseAmount(req.Amount)
+	if err != nil {
+		handleServiceError(ctx, w, models.InvalidAmountError("amount", err), h.metrics)
+		return
+	}
+
+	hold, err := h.transferService.PlaceHold(ctx, accountID, amount)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	writeSuccess(w, http.StatusCreated, newHoldResponse(hold, h.idCodec, h.money))
+}
+
+// ReleaseHold gives hold {id}'s amount back to its account's available balance.
+func (h *TransactionHandler) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	h.resolveHold(w, r, h.transferService.ReleaseHold)
+}
+
+// CaptureHold debits hold {id}'s amount from its account as a withdrawal.
+func (h *TransactionHandler) CaptureHold(w http.ResponseWriter, r *http.Request) {
+	h.resolveHold(w, r, h.transferService.CaptureHold)
+}
+
+type holdFunc func(ctx context.Context, holdID int64) (*models.Hold, error)
+
+func (h *TransactionHandler) resolveHold(w http.ResponseWriter, r *http.Request, resolve holdFunc) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	holdID, ok := parseHoldID(w, r)
+	if !ok {
+		return
+	}
+
+	hold, err := resolve(ctx, holdID)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, newHoldResponse(hold, h.idCodec, h.money))
+}
+
+func parseHoldID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	idStr := r.PathValue("id")
+	holdID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || holdID <= 0 {
+		log.Debug().Str("id", idStr).Msg("Invalid hold ID")
+		writeError(w, http.StatusBadRequest, "invalid_id", "Hold ID must be a positive integer")
+		return 0, false
+	}
+	return holdID, true
+}
+
+func newHoldResponse(hold *models.Hold, codec idcodec.Codec, format moneyFormat) HoldResponse {
+	resp := HoldResponse{
+		HoldID:    hold.HoldID,
+		AccountID: hold.AccountID,
+		Amount:    format(hold.Amount),
+		Status:    string(hold.Status),
+		CreatedAt: hold.CreatedAt.UTC().Format(models.TimestampLayout),
+	}
+	if hold.TransactionID != nil {
+		id := newPublicID(*hold.TransactionID, codec)
+		resp.TransactionID = &id
+	}
+	if hold.ResolvedAt != nil {
+		resp.ResolvedAt = hold.ResolvedAt.UTC().Format(models.TimestampLayout)
+	}
+	return resp
+}
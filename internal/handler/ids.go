@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"internal-transfers-system/internal/idcodec"
+)
+
+// PublicID is a transaction ID as exposed by the API: the raw int64 by default, or an
+// opaque string when Options.IDCodec is set. ID always holds the database ID.
+type PublicID struct {
+	ID      int64
+	Encoded string
+}
+
+func newPublicID(id int64, codec idcodec.Codec) PublicID {
+	if codec == nil {
+		return PublicID{ID: id}
+	}
+	return PublicID{ID: id, Encoded: codec.Encode(id)}
+}
+
+// MarshalJSON writes the encoded string when set, otherwise the numeric ID.
+func (p PublicID) MarshalJSON() ([]byte, error) {
+	if p.Encoded != "" {
+		return json.Marshal(p.Encoded)
+	}
+	return json.Marshal(p.ID)
+}
+
+// UnmarshalJSON accepts either form. A string only fills Encoded; decoding it needs the
+// codec.
+func (p *PublicID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &p.Encoded)
+	}
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return errors.New("transaction ID must be an integer or string")
+	}
+	p.ID = id
+	return nil
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"internal-transfers-system/internal/idcodec"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+	"internal-transfers-system/internal/validator"
+
+	"github.com/rs/zerolog/log"
+)
+
+type LedgerHandler struct {
+	ledgerService  *service.LedgerService
+	inFlight       *sync.WaitGroup
+	metrics        RequestMetrics
+	idCodec        idcodec.Codec
+	maxRequestBody int64
+	money          moneyFormat
+	currency       string
+}
+
+func NewLedgerHandler(ledgerService *service.LedgerService) *LedgerHandler {
+	return NewLedgerHandlerWithOptions(ledgerService, DefaultOptions())
+}
+
+func NewLedgerHandlerWithOptions(ledgerService *service.LedgerService, opts Options) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService:  ledgerService,
+		inFlight:       opts.InFlight,
+		metrics:        opts.Metrics,
+		idCodec:        opts.IDCodec,
+		maxRequestBody: opts.MaxRequestBody,
+		money:          opts.moneyFormat(),
+		currency:       opts.currency(),
+	}
+}
+
+// Deposit credits account {id}.
+func (h *LedgerHandler) Deposit(w http.ResponseWriter, r *http.Request) {
+	h.createEntry(w, r, h.ledgerService.Deposit)
+}
+
+// Withdraw debits account {id}, rejecting with 422 insufficient_balance if it can't cover the amount.
+func (h *LedgerHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	h.createEntry(w, r, h.ledgerService.Withdraw)
+}
+
+type ledgerFunc func(ctx context.Context, accountID int64, req *models.LedgerEntryRequest) (*models.Transaction, error)
+
+func (h *LedgerHandler) createEntry(w http.ResponseWriter, r *http.Request, apply ledgerFunc) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	accountID, ok := parseAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.LedgerEntryRequest
+	if err := decodeJSONBody(w, r, h.maxRequestBody, &req); err != nil {
+		log.Debug().Err(err).Msg("Failed to decode ledger entry request")
+		writeDecodeError(w, err)
+		return
+	}
+
+	key, ok := parseIdempotencyKey(w, r)
+	if !ok {
+		return
+	}
+	req.IdempotencyKey = key
+
+	if errs := validator.ValidateLedgerEntry(&req); len(errs) > 0 {
+		log.Debug().Int64("accountID", accountID).Interface("errors", errs).Msg("Ledger entry validation failed")
+		writeValidationError(w, errs)
+		return
+	}
+
+	txn, err := apply(ctx, accountID, &req)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	resp := newTransactionResponse(txn, h.idCodec, h.money, amountCurrency(w, r, h.currency))
+	if txn.Replayed {
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		writeSuccess(w, http.StatusOK, resp)
+		return
+	}
+	writeSuccess(w, http.StatusCreated, resp)
+}
@@ -0,0 +1,119 @@
+//go:build integration
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/repository"
+	"internal-transfers-system/internal/service"
+	"internal-transfers-system/internal/testutil"
+
+	"github.com/shopspring/decimal"
+)
+
+var testSuite *testutil.TestContainerSuite
+
+func TestMain(m *testing.M) {
+	var err error
+	testSuite, err = testutil.NewTestContainerSuite()
+	if err != nil {
+		panic("failed to start test container: " + err.Error())
+	}
+	code := m.Run()
+	testSuite.Teardown()
+	os.Exit(code)
+}
+
+func setupLedger(t *testing.T) (*http.ServeMux, *repository.AccountRepository, *repository.TransactionRepository) {
+	t.Helper()
+	if err := testSuite.Clean(); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	accRepo := repository.NewAccountRepository(testSuite.Pool())
+	txnRepo := repository.NewTransactionRepository(testSuite.Pool())
+	h := NewLedgerHandler(service.NewLedgerService(accRepo, txnRepo))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/accounts/{id}/deposits", h.Deposit)
+	mux.HandleFunc("POST /api/v1/accounts/{id}/withdrawals", h.Withdraw)
+	return mux, accRepo, txnRepo
+}
+
+func postLedgerEntry(mux *http.ServeMux, accountID int64, kind, amount string) *httptest.ResponseRecorder {
+	path := fmt.Sprintf("/api/v1/accounts/%d/%s", accountID, kind)
+	body := fmt.Sprintf(`{"amount": %q}`, amount)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body)))
+	return rec
+}
+
+func TestIntegration_Deposit(t *testing.T) {
+	mux, accRepo, txnRepo := setupLedger(t)
+	ctx := context.Background()
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+
+	rec := postLedgerEntry(mux, 1, "deposits", "50.25")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp TransactionResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	acc, _ := accRepo.GetByID(ctx, 1)
+	if !acc.Balance.Equal(decimal.RequireFromString("150.25")) {
+		t.Errorf("expected 150.25, got %s", acc.Balance)
+	}
+
+	stored, err := txnRepo.GetByID(ctx, resp.TransactionID.ID)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if stored.Type != models.TransactionTypeDeposit || stored.DestinationAccountID != 1 || stored.SourceAccountID != 0 {
+		t.Errorf("expected a stored deposit into account 1, got %+v", stored)
+	}
+
+	history, _ := txnRepo.GetByAccountID(ctx, 1, 10, 0)
+	if len(history) != 1 || history[0].TransactionID != resp.TransactionID.ID {
+		t.Errorf("expected the deposit in account history, got %d entries", len(history))
+	}
+}
+
+func TestIntegration_Withdraw(t *testing.T) {
+	mux, accRepo, txnRepo := setupLedger(t)
+	ctx := context.Background()
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+
+	rec := postLedgerEntry(mux, 1, "withdrawals", "40")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp TransactionResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Type != string(models.TransactionTypeWithdrawal) || resp.SourceAccountID != 1 {
+		t.Errorf("unexpected response: %s", rec.Body.String())
+	}
+
+	// Overdrawing must fail and leave the balance and history untouched
+	rec = postLedgerEntry(mux, 1, "withdrawals", "60.01")
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	acc, _ := accRepo.GetByID(ctx, 1)
+	if !acc.Balance.Equal(decimal.NewFromInt(60)) {
+		t.Errorf("expected 60, got %s", acc.Balance)
+	}
+	history, _ := txnRepo.GetByAccountID(ctx, 1, 10, 0)
+	if len(history) != 1 {
+		t.Errorf("expected only the successful withdrawal in history, got %d entries", len(history))
+	}
+}
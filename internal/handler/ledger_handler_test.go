@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLedgerEntries(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		body        string
+		wantStatus  int
+		wantBalance string
+		wantType    string
+	}{
+		{"deposit", "/api/v1/accounts/1/deposits", `{"amount": "25"}`, http.StatusCreated, "125", "deposit"},
+		{"withdrawal", "/api/v1/accounts/1/withdrawals", `{"amount": "40"}`, http.StatusCreated, "60", "withdrawal"},
+		{"insufficient balance", "/api/v1/accounts/1/withdrawals", `{"amount": "100.01"}`, http.StatusUnprocessableEntity, "100", ""},
+		{"invalid amount", "/api/v1/accounts/1/deposits", `{"amount": "-1"}`, http.StatusBadRequest, "100", ""},
+		{"unknown account", "/api/v1/accounts/9/deposits", `{"amount": "1"}`, http.StatusNotFound, "100", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accRepo := mocks.NewMockAccountRepository()
+			accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+			h := NewLedgerHandler(service.NewLedgerService(accRepo, mocks.NewMockTransactionRepository()))
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /api/v1/accounts/{id}/deposits", h.Deposit)
+			mux.HandleFunc("POST /api/v1/accounts/{id}/withdrawals", h.Withdraw)
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != tt.wantBalance {
+				t.Errorf("expected balance %s, got %s", tt.wantBalance, acc.Balance)
+			}
+			if tt.wantType == "" {
+				return
+			}
+
+			var resp map[string]interface{}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if resp["type"] != tt.wantType {
+				t.Errorf("expected type %q, got %s", tt.wantType, rec.Body.String())
+			}
+			// Only the affected side of a ledger entry is present
+			_, hasSource := resp["source_account_id"]
+			_, hasDest := resp["destination_account_id"]
+			if hasSource == hasDest {
+				t.Errorf("expected exactly one account side, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestLedgerEntries_IdempotencyKey(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	h := NewLedgerHandler(service.NewLedgerService(accRepo, mocks.NewMockTransactionRepository()))
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/accounts/{id}/deposits", h.Deposit)
+
+	post := func(key, amount string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/accounts/1/deposits", bytes.NewBufferString(`{"amount": "`+amount+`"}`))
+		req.Header.Set(IdempotencyKeyHeader, key)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post("dep-1", "25"); rec.Code != http.StatusCreated {
+		t.Fatalf("first: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := post("dep-1", "25")
+	if rec.Code != http.StatusOK || rec.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("replay: expected 200 with %s, got %d", IdempotentReplayedHeader, rec.Code)
+	}
+	if rec := post("dep-1", "30"); rec.Code != http.StatusConflict {
+		t.Errorf("conflict: expected 409, got %d", rec.Code)
+	}
+	if rec := post(strings.Repeat("k", 256), "25"); rec.Code != http.StatusBadRequest {
+		t.Errorf("long key: expected 400, got %d", rec.Code)
+	}
+	if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "125" {
+		t.Errorf("expected a single credit to 125, got %s", acc.Balance)
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+
+	"internal-transfers-system/internal/idcodec"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/validator"
+
+	"github.com/shopspring/decimal"
+)
+
+// ValidationModeHeader lets a client override the configured validation mode for a single
+// request. Accepted values are "collect-all" and "fail-fast"; anything else is ignored.
+const ValidationModeHeader = "X-Validation-Mode"
+
+// DebugHeader requests diagnostic details in a response, e.g. the isolation level and
+// attempts a transfer used. Honoured only when Options.DebugResponses is enabled.
+const DebugHeader = "X-Debug"
+
+// AmountFormatHeader lets a client ask for amounts in transaction and account responses
+// as {"value": "150.50", "currency": "USD"} objects by sending AmountFormatObject.
+// Without it amounts stay plain decimal strings.
+const AmountFormatHeader = "Accept-Amount-Format"
+
+// AmountFormatObject is the AmountFormatHeader value that selects money objects.
+const AmountFormatObject = "object"
+
+// DefaultCurrency is the currency reported in money objects when none is configured.
+const DefaultCurrency = "USD"
+
+// Options configures the account and transaction handlers.
+type Options struct {
+	// Limits holds per-endpoint page size caps.
+	Limits PageLimits
+
+	// ValidationMode is the default request validation mode.
+	ValidationMode validator.Mode
+
+	// DebugResponses allows clients to request diagnostic details with DebugHeader.
+	// Must stay off in production: it discloses internal configuration.
+	DebugResponses bool
+
+	// InFlight, when set, counts running money-moving requests so shutdown can wait for
+	// them to finish before the database pool is closed.
+	InFlight *sync.WaitGroup
+
+	// Metrics counts aborted requests. Nil disables it. *metrics.Metrics implements it.
+	Metrics RequestMetrics
+
+	// MaxRequestBody caps JSON request bodies in bytes; larger bodies get 413.
+	// Zero means DefaultMaxRequestBody.
+	MaxRequestBody int64
+
+	// IDCodec, when set, encodes transaction IDs in responses and decodes them in paths.
+	// Nil exposes raw IDs.
+	IDCodec idcodec.Codec
+
+	// FixedAmounts renders amounts and balances in responses with at least MinorUnits
+	// decimal places, the currency's minor units, e.g. "150.50" rather than "150.5" with
+	// 2. Off shows them as stored, without trailing zeros. A scale query parameter
+	// overrides either on the endpoints that accept it.
+	FixedAmounts bool
+	MinorUnits   int
+
+	// Currency is the ISO 4217 code reported with amounts when a client asks for them as
+	// objects with AmountFormatHeader. Empty means DefaultCurrency.
+	Currency string
+}
+
+// RequestMetrics separates client cancellations from server-side timeouts, which mean
+// very different things operationally.
+type RequestMetrics interface {
+	RequestCanceled()
+	RequestTimedOut()
+}
+
+type noopRequestMetrics struct{}
+
+func (noopRequestMetrics) RequestCanceled() {}
+func (noopRequestMetrics) RequestTimedOut() {}
+
+// DefaultMaxRequestBody is the request body limit used when none is configured.
+const DefaultMaxRequestBody = 1 << 20
+
+// DefaultOptions returns the options used when none are configured.
+func DefaultOptions() Options {
+	return Options{Limits: DefaultPageLimits(), ValidationMode: validator.CollectAll, MaxRequestBody: DefaultMaxRequestBody}
+}
+
+// moneyFormat returns how responses render amounts by default.
+func (o Options) moneyFormat() moneyFormat {
+	if !o.FixedAmounts {
+		return models.FormatMoney
+	}
+	minorUnits := o.MinorUnits
+	return func(d decimal.Decimal) string {
+		return models.FormatMoneyFixed(d, minorUnits)
+	}
+}
+
+// currency returns the currency money objects are reported in.
+func (o Options) currency() string {
+	if o.Currency == "" {
+		return DefaultCurrency
+	}
+	return o.Currency
+}
+
+// amountCurrency returns currency if r asked for amounts as money objects with
+// AmountFormatHeader, or "" for plain strings. The response varies on the header either
+// way, so caches must key on it.
+func amountCurrency(w http.ResponseWriter, r *http.Request, currency string) string {
+	w.Header().Add("Vary", AmountFormatHeader)
+	if r.Header.Get(AmountFormatHeader) != AmountFormatObject {
+		return ""
+	}
+	return currency
+}
+
+// trackInFlight registers one unit of work with wg and returns the func that ends it.
+// A nil wg disables tracking.
+func trackInFlight(wg *sync.WaitGroup) (done func()) {
+	if wg == nil {
+		return func() {}
+	}
+	wg.Add(1)
+	return wg.Done
+}
+
+// debugRequested reports whether r asked for debug details and they are enabled.
+func debugRequested(r *http.Request, enabled bool) bool {
+	return enabled && r.Header.Get(DebugHeader) == "true"
+}
+
+// validationMode returns the mode requested via ValidationModeHeader, or def.
+func validationMode(r *http.Request, def validator.Mode) validator.Mode {
+	if mode, ok := validator.ParseMode(r.Header.Get(ValidationModeHeader)); ok {
+		return mode
+	}
+	return def
+}
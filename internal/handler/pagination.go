@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"internal-transfers-system/internal/service"
+)
+
+// PageLimits holds per-endpoint page size caps. Each handler clamps client-requested
+// sizes against its own maximum, so bulk endpoints can allow larger pages than
+// interactive listings.
+type PageLimits struct {
+	// DefaultSize is used when a listing request omits limit or sends an invalid one.
+	DefaultSize int
+
+	// MaxListing caps interactive listings such as an account's transaction history.
+	MaxListing int
+
+	// MaxBatchGet caps how many items a single batch-get request may ask for.
+	MaxBatchGet int
+
+	// MaxExport is the page (batch) size used when streaming exports.
+	MaxExport int
+}
+
+// DefaultPageLimits returns the limits used when none are configured.
+func DefaultPageLimits() PageLimits {
+	return PageLimits{
+		DefaultSize: service.DefaultPageSize,
+		MaxListing:  service.MaxPageSize,
+		MaxBatchGet: service.MaxPageSize,
+		MaxExport:   service.DefaultExportBatchSize,
+	}
+}
+
+// listingLimit reads the limit query param, falling back to DefaultSize and clamping to MaxListing.
+func (l PageLimits) listingLimit(r *http.Request) int {
+	limit := queryInt(r, "limit", l.DefaultSize)
+	if limit <= 0 {
+		limit = l.DefaultSize
+	}
+	if limit > l.MaxListing {
+		limit = l.MaxListing
+	}
+	return limit
+}
+
+// exportBatch is the batch size streaming exports read and flush in: MaxExport, or the
+// service default when it isn't set.
+func (l PageLimits) exportBatch() int {
+	if l.MaxExport <= 0 {
+		return service.DefaultExportBatchSize
+	}
+	return l.MaxExport
+}
+
+// queryInt parses a non-negative integer query parameter, returning def when it is
+// missing or invalid.
+func queryInt(r *http.Request, name string, def int) int {
+	v, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || v < 0 {
+		return def
+	}
+	return v
+}
+
+// PaginatedResponse is the envelope offset-paged listings return: one page of items and
+// where it sits in the full result.
+type PaginatedResponse[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Pagination describes the page in a PaginatedResponse. Total counts every item across
+// all pages, and HasMore reports whether any follow this page.
+type Pagination struct {
+	Limit   int   `json:"limit"`
+	Offset  int   `json:"offset"`
+	Total   int64 `json:"total"`
+	HasMore bool  `json:"has_more"`
+}
+
+// newPaginatedResponse wraps a page of data fetched with limit and offset from total items.
+func newPaginatedResponse[T any](data []T, limit, offset int, total int64) PaginatedResponse[T] {
+	return PaginatedResponse[T]{
+		Data: data,
+		Pagination: Pagination{
+			Limit:   limit,
+			Offset:  offset,
+			Total:   total,
+			HasMore: int64(offset+len(data)) < total,
+		},
+	}
+}
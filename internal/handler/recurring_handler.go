@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"internal-transfers-system/internal/idcodec"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/validator"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RecurringTransferResponse represents a recurring transfer. Every is a Go duration
+// string; the Last* fields describe the latest run and are omitted before the first.
+type RecurringTransferResponse struct {
+	RecurringID          int64     `json:"recurring_id"`
+	SourceAccountID      int64     `json:"source_account_id"`
+	DestinationAccountID int64     `json:"destination_account_id"`
+	Amount               string    `json:"amount"`
+	Category             string    `json:"category,omitempty"`
+	Every                string    `json:"every"`
+	NextRunAt            string    `json:"next_run_at"`
+	EndAt                string    `json:"end_at,omitempty"`
+	Status               string    `json:"status"`
+	Runs                 int       `json:"runs"`
+	LastRunAt            string    `json:"last_run_at,omitempty"`
+	LastTransactionID    *PublicID `json:"last_transaction_id,omitempty"`
+	LastError            string    `json:"last_error,omitempty"`
+	CreatedAt            string    `json:"created_at"`
+	CanceledAt           string    `json:"canceled_at,omitempty"`
+}
+
+// CreateRecurringTransfer schedules a transfer to repeat every `every`, from start_at
+// (default now) until end_at (default never). The first run is made by the scheduler
+// once start_at is due.
+func (h *TransactionHandler) CreateRecurringTransfer(w http.ResponseWriter, r *http.Request) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	var req models.CreateRecurringTransferRequest
+	if err := decodeJSONBody(w, r, h.maxRequestBody, &req); err != nil {
+		log.Debug().Err(err).Msg("Failed to decode create recurring transfer request")
+		writeDecodeError(w, err)
+		return
+	}
+
+	if errs := validator.ValidateCreateRecurringTransfer(&req); len(errs) > 0 {
+		log.Debug().Interface("errors", errs).Msg("Create recurring transfer validation failed")
+		writeValidationError(w, errs)
+		return
+	}
+
+	// The validator has checked every field parses
+	amount, err := models.ParseAmount(req.Amount)
+	if err != nil {
+		handleServiceError(ctx, w, models.InvalidAmountError("amount", err), h.metrics)
+		return
+	}
+	every, _ := time.ParseDuration(req.Every)
+	recurring := &models.RecurringTransfer{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               amount,
+		Category:             req.Category,
+		Every:                every,
+	}
+	if req.StartAt != "" {
+		recurring.NextRunAt, _ = time.Parse(time.RFC3339, req.StartAt)
+	}
+	if req.EndAt != "" {
+		endAt, _ := time.Parse(time.RFC3339, req.EndAt)
+		recurring.EndAt = &endAt
+	}
+
+	recurring, err = h.transferService.CreateRecurring(ctx, recurring)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	writeSuccess(w, http.StatusCreated, newRecurringTransferResponse(recurring, h.idCodec, h.money))
+}
+
+// GetRecurringTransfer returns recurring transfer {id} with the outcome of its latest run.
+func (h *TransactionHandler) GetRecurringTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	recurringID, ok := parseRecurringID(w, r)
+	if !ok {
+		return
+	}
+
+	recurring, err := h.transferService.GetRecurring(ctx, recurringID)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, newRecurringTransferResponse(recurring, h.idCodec, h.money))
+}
+
+// CancelRecurringTransfer stops recurring transfer {id}, rejecting with 409 if it has
+// already completed or been canceled.
+func (h *TransactionHandler) CancelRecurringTransfer(w http.ResponseWriter, r *http.Request) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	recurringID, ok := parseRecurringID(w, r)
+	if !ok {
+		return
+	}
+
+	recurring, err := h.transferService.CancelRecurring(ctx, recurringID)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, newRecurringTransferResponse(recurring, h.idCodec, h.money))
+}
+
+func parseRecurringID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	idStr := r.PathValue("id")
+	recurringID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || recurringID <= 0 {
+		log.Debug().Str("id", idStr).Msg("Invalid recurring transfer ID")
+		writeError(w, http.StatusBadRequest, "invalid_id", "Recurring transfer ID must be a positive integer")
+		return 0, false
+	}
+	return recurringID, true
+}
+
+func newRecurringTransferResponse(recurring *models.RecurringTransfer, codec idcodec.Codec, format moneyFormat) RecurringTransferResponse {
+	resp := RecurringTransferResponse{
+		RecurringID:          recurring.RecurringID,
+		SourceAccountID:      recurring.SourceAccountID,
+		DestinationAccountID: recurring.DestinationAccountID,
+		Amount:               format(recurring.Amount),
+		Category:             recurring.Category,
+		Every:                recurring.Every.String(),
+		NextRunAt:            recurring.NextRunAt.UTC().Format(models.TimestampLayout),
+		Status:               string(recurring.Status),
+		Runs:                 recurring.Runs,
+		LastError:            recurring.LastError,
+		CreatedAt:            recurring.CreatedAt.UTC().Format(models.TimestampLayout),
+	}
+	if recurring.EndAt != nil {
+		resp.EndAt = recurring.EndAt.UTC().Format(models.TimestampLayout)
+	}
+	if recurring.LastRunAt != nil {
+		resp.LastRunAt = recurring.LastRunAt.UTC().Format(models.TimestampLayout)
+	}
+	if recurring.LastTransactionID != nil {
+		id := newPublicID(*recurring.LastTransactionID, codec)
+		resp.LastTransactionID = &id
+	}
+	if recurring.CanceledAt != nil {
+		resp.CanceledAt = recurring.CanceledAt.UTC().Format(models.TimestampLayout)
+	}
+	return resp
+}
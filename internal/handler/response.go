@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"internal-transfers-system/internal/validator"
 
@@ -15,10 +18,26 @@ type APIResponse struct {
 }
 
 type ErrorResponse struct {
-	Success   bool   `json:"success"`
-	Error     string `json:"error"`
-	Message   string `json:"message"`
-	RequestID string `json:"request_id,omitempty"`
+	Success   bool          `json:"success"`
+	Error     string        `json:"error"`
+	Message   string        `json:"message"`
+	Details   *ErrorDetails `json:"details,omitempty"`
+	RequestID string        `json:"request_id,omitempty"`
+
+	// Retryable is set when the same request may succeed if sent again, and RetryAfter
+	// is the suggested wait in seconds before doing so. Both are omitted otherwise.
+	Retryable  bool `json:"retryable,omitempty"`
+	RetryAfter int  `json:"retry_after,omitempty"`
+
+	// AvailableAt is when a transfer blackout ends and transfers are accepted again.
+	AvailableAt string `json:"available_at,omitempty"`
+}
+
+// ErrorDetails pinpoints the request field an error is about and a machine-readable
+// reason, e.g. {"field": "amount", "reason": "too_many_decimal_places"}.
+type ErrorDetails struct {
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason"`
 }
 
 type ValidationErrorResponse struct {
@@ -41,15 +60,59 @@ func writeSuccess(w http.ResponseWriter, status int, data interface{}) {
 	writeJSON(w, status, data)
 }
 
+// writeConditional writes data as a 200 response with a strong ETag computed from its
+// encoding, so the tag changes whenever any field does. If r's If-None-Match already
+// names that tag, it writes 304 Not Modified with no body instead.
+func writeConditional(w http.ResponseWriter, r *http.Request, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode JSON response")
+		writeError(w, http.StatusInternalServerError, "internal_error", "An unexpected error occurred. Please try again later.")
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	// no-cache lets clients keep the response but makes them revalidate before reuse
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(body, '\n'))
+}
+
+// etagMatch reports whether an If-None-Match header names etag, or is "*". Tags are
+// compared weakly, as RFC 9110 requires for If-None-Match: W/"x" matches "x", since a
+// client or proxy may have weakened the tag it stored.
+func etagMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
 func writeError(w http.ResponseWriter, status int, errorCode, message string) {
-	requestID := w.Header().Get("X-Request-ID")
+	writeErrorWithDetails(w, status, errorCode, message, nil)
+}
 
-	writeJSON(w, status, ErrorResponse{
-		Success:   false,
-		Error:     errorCode,
-		Message:   message,
-		RequestID: requestID,
-	})
+func writeErrorWithDetails(w http.ResponseWriter, status int, errorCode, message string, details *ErrorDetails) {
+	writeErrorResponse(w, status, ErrorResponse{Error: errorCode, Message: message, Details: details})
+}
+
+// writeErrorResponse writes resp, filling in Success and the request ID.
+func writeErrorResponse(w http.ResponseWriter, status int, resp ErrorResponse) {
+	resp.Success = false
+	resp.RequestID = w.Header().Get("X-Request-ID")
+	writeJSON(w, status, resp)
 }
 
 func writeValidationError(w http.ResponseWriter, errs validator.ValidationErrors) {
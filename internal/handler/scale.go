@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+
+	"internal-transfers-system/internal/models"
+)
+
+// moneyFormat renders an amount for a response.
+type moneyFormat func(decimal.Decimal) string
+
+// parseScale reads the optional scale query parameter used by read endpoints to round
+// displayed amounts to N decimal places (0 through models.LimitMoneyScale). Without it,
+// amounts are shown in the handler's default format def. Invalid values write a 400 and
+// return false.
+func parseScale(w http.ResponseWriter, r *http.Request, def moneyFormat) (moneyFormat, bool) {
+	raw := r.URL.Query().Get("scale")
+	if raw == "" {
+		return def, true
+	}
+	scale, err := strconv.Atoi(raw)
+	if err != nil || scale < 0 || scale > models.LimitMoneyScale {
+		writeError(w, http.StatusBadRequest, "invalid_scale",
+			fmt.Sprintf("scale must be an integer between 0 and %d", models.LimitMoneyScale))
+		return nil, false
+	}
+	return func(d decimal.Decimal) string {
+		return models.FormatMoneyScale(d, int32(scale))
+	}, true
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"internal-transfers-system/internal/models"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+)
+
+// statementHeader is the header row of an account statement CSV.
+var statementHeader = []string{"transaction_id", "date", "direction", "counterparty", "amount", "fee"}
+
+// ExportAccountTransactions streams account {id}'s transactions as a CSV statement, newest
+// first, for import into accounting tools. Each row gives the effective date, whether the
+// transaction debited or credited the account, the other account (empty for deposits and
+// withdrawals), the unsigned amount, and the fee this account paid on it, so the rows
+// reconcile with the balance. Transactions that moved no funds (failed or still pending)
+// are left out. Rows are read in keyset pages of the configured export page size, so a
+// long history is never buffered in memory. Errors after the first row are logged and end
+// the stream early, since the status line has already been sent.
+func (h *TransactionHandler) ExportAccountTransactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	accountID, ok := parseAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The export runs as long as the history is long, not SERVER_WRITE_TIMEOUT
+	_ = rc.SetWriteDeadline(time.Time{})
+	writer := csv.NewWriter(w)
+
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="account-%d-transactions.csv"`, accountID))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		return writer.Write(statementHeader)
+	}
+
+	batch := h.limits.exportBatch()
+	written := 0
+	err := h.transferService.StreamAccountTransactions(ctx, accountID, batch, func(txn *models.Transaction) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if txn.Status != models.TransactionStatusCompleted {
+			return nil
+		}
+
+		if err := writer.Write(h.statementRow(accountID, txn)); err != nil {
+			return err
+		}
+
+		written++
+		if written%batch == 0 {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			_ = rc.Flush()
+		}
+		return nil
+	})
+
+	if err != nil {
+		if !started {
+			handleServiceError(ctx, w, err, h.metrics)
+			return
+		}
+		log.Error().Err(err).Int64("accountID", accountID).Int("written", written).Msg("Transaction export aborted mid-stream")
+		return
+	}
+
+	if !started {
+		// A write error surfaces from the flush below
+		_ = start()
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Error().Err(err).Int64("accountID", accountID).Int("written", written).Msg("Transaction export aborted mid-stream")
+		return
+	}
+	_ = rc.Flush()
+}
+
+// statementRow renders txn as a statement row from accountID's point of view.
+func (h *TransactionHandler) statementRow(accountID int64, txn *models.Transaction) []string {
+	direction, counterparty, payer := "credit", txn.SourceAccountID, models.FeePayerDestination
+	if txn.SourceAccountID == accountID {
+		direction, counterparty, payer = "debit", txn.DestinationAccountID, models.FeePayerSource
+	}
+	fee := decimal.Zero
+	if txn.FeePaidBy == payer {
+		fee = txn.FeeAmount
+	}
+
+	row := []string{
+		publicIDString(newPublicID(txn.TransactionID, h.idCodec)),
+		txn.EffectiveDate.Format(models.DateLayout),
+		direction,
+		"",
+		h.money(txn.Amount),
+		h.money(fee),
+	}
+	if counterparty != 0 {
+		row[3] = strconv.FormatInt(counterparty, 10)
+	}
+	return row
+}
+
+// publicIDString is the plain-text form of p: the encoded string when set, otherwise the
+// numeric ID.
+func publicIDString(p PublicID) string {
+	if p.Encoded != "" {
+		return p.Encoded
+	}
+	return strconv.FormatInt(p.ID, 10)
+}
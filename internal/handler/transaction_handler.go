@@ -1,43 +1,196 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
+	"internal-transfers-system/internal/idcodec"
 	"internal-transfers-system/internal/models"
 	"internal-transfers-system/internal/service"
+	"internal-transfers-system/internal/tracing"
 	"internal-transfers-system/internal/validator"
 
 	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
 )
 
 type TransactionResponse struct {
-	TransactionID        int64  `json:"transaction_id"`
+	TransactionID        PublicID  `json:"transaction_id"`
+	Type                 string    `json:"type"`
+	SourceAccountID      int64     `json:"source_account_id,omitempty"`
+	DestinationAccountID int64     `json:"destination_account_id,omitempty"`
+	Amount               string    `json:"amount"`
+	EffectiveDate        string    `json:"effective_date"`
+	ReversalOf           *PublicID `json:"reversal_of,omitempty"`
+	Category             string    `json:"category,omitempty"`
+	Status               string    `json:"status"`
+	FeeAmount            string    `json:"fee_amount"`
+	FeeAccountID         int64     `json:"fee_account_id,omitempty"`
+	FeePaidBy            string    `json:"fee_paid_by,omitempty"`
+	CreatedAt            string    `json:"created_at"`
+
+	// Debug is only set when debug responses are enabled and requested.
+	Debug *models.TransferDebug `json:"debug,omitempty"`
+
+	// AmountCurrency, when set, marshals Amount and FeeAmount as money objects in this
+	// currency. It is not part of the response itself.
+	AmountCurrency string `json:"-"`
+}
+
+// MarshalJSON writes the amounts as money objects when AmountCurrency is set.
+func (r TransactionResponse) MarshalJSON() ([]byte, error) {
+	type plain TransactionResponse
+	if r.AmountCurrency == "" {
+		return json.Marshal(plain(r))
+	}
+	return json.Marshal(struct {
+		plain
+		Amount    models.MoneyObject `json:"amount"`
+		FeeAmount models.MoneyObject `json:"fee_amount"`
+	}{
+		plain:     plain(r),
+		Amount:    models.MoneyObject{Value: r.Amount, Currency: r.AmountCurrency},
+		FeeAmount: models.MoneyObject{Value: r.FeeAmount, Currency: r.AmountCurrency},
+	})
+}
+
+// BatchTransferResponse lists the transactions created by a batch transfer, one per
+// requested leg and in request order.
+type BatchTransferResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+}
+
+// TransactionPage is the cursor-paginated envelope for an account's transactions.
+// NextCursor is omitted on the last page.
+type TransactionPage struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	NextCursor   string                `json:"next_cursor,omitempty"`
+}
+
+// CategorySummaryResponse reports an account's totals per transaction category.
+// From and To echo the requested effective-date range and are omitted when open.
+type CategorySummaryResponse struct {
+	AccountID  int64                   `json:"account_id"`
+	From       string                  `json:"from,omitempty"`
+	To         string                  `json:"to,omitempty"`
+	Categories []CategoryTotalResponse `json:"categories"`
+}
+
+// CategoryTotalResponse is one category's totals. Category is null for uncategorized
+// transactions.
+type CategoryTotalResponse struct {
+	Category *string `json:"category"`
+	Spent    string  `json:"spent"`
+	Received string  `json:"received"`
+	Count    int64   `json:"count"`
+}
+
+// ActiveTransfersResponse counts the transfers executing right now and describes the
+// oldest, which is omitted when there are none.
+type ActiveTransfersResponse struct {
+	Count  int                     `json:"count"`
+	Oldest *ActiveTransferResponse `json:"oldest,omitempty"`
+}
+
+// ActiveTransferResponse is a transfer still in progress. AgeMs is how long it has been
+// running when the response was built.
+type ActiveTransferResponse struct {
+	OperationID          string `json:"operation_id"`
 	SourceAccountID      int64  `json:"source_account_id"`
 	DestinationAccountID int64  `json:"destination_account_id"`
 	Amount               string `json:"amount"`
-	CreatedAt            string `json:"created_at"`
+	StartedAt            string `json:"started_at"`
+	AgeMs                int64  `json:"age_ms"`
+}
+
+// IdempotencyKeyHeader carries the client's idempotency key for transfers, deposits, and
+// withdrawals.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set to "true" on a 200 response that returns the result of
+// an earlier request with the same idempotency key, or the existing account for an
+// idempotent account creation.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength bounds the stored key size.
+const maxIdempotencyKeyLength = 255
+
+// parseIdempotencyKey reads the optional Idempotency-Key header, writing a 400 error
+// response and returning false when it is too long.
+func parseIdempotencyKey(w http.ResponseWriter, r *http.Request) (string, bool) {
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if len(key) > maxIdempotencyKeyLength {
+		writeError(w, http.StatusBadRequest, "invalid_idempotency_key", "Idempotency-Key must be at most 255 characters")
+		return "", false
+	}
+	return key, true
 }
 
 type TransactionHandler struct {
 	transferService *service.TransferService
+	limits          PageLimits
+	validationMode  validator.Mode
+	debugResponses  bool
+	inFlight        *sync.WaitGroup
+	metrics         RequestMetrics
+	idCodec         idcodec.Codec
+	maxRequestBody  int64
+	money           moneyFormat
+	currency        string
 }
 
 func NewTransactionHandler(transferService *service.TransferService) *TransactionHandler {
-	return &TransactionHandler{transferService: transferService}
+	return NewTransactionHandlerWithLimits(transferService, DefaultPageLimits())
+}
+
+func NewTransactionHandlerWithLimits(transferService *service.TransferService, limits PageLimits) *TransactionHandler {
+	opts := DefaultOptions()
+	opts.Limits = limits
+	return NewTransactionHandlerWithOptions(transferService, opts)
+}
+
+func NewTransactionHandlerWithOptions(transferService *service.TransferService, opts Options) *TransactionHandler {
+	return &TransactionHandler{
+		transferService: transferService,
+		limits:          opts.Limits,
+		validationMode:  opts.ValidationMode,
+		debugResponses:  opts.DebugResponses,
+		inFlight:        opts.InFlight,
+		metrics:         opts.Metrics,
+		idCodec:         opts.IDCodec,
+		maxRequestBody:  opts.MaxRequestBody,
+		money:           opts.moneyFormat(),
+		currency:        opts.currency(),
+	}
 }
 
 func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	defer trackInFlight(h.inFlight)()
+	ctx, span := tracing.Start(r.Context(), "TransactionHandler.CreateTransaction")
+	defer span.End()
 
 	var req models.CreateTransactionRequest
-	if err := decodeJSONBody(r, &req); err != nil {
+	if err := decodeJSONBody(w, r, h.maxRequestBody, &req); err != nil {
 		log.Debug().Err(err).Msg("Failed to decode create transaction request")
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		writeDecodeError(w, err)
 		return
 	}
+	span.SetAttributes(
+		tracing.AttrSourceAccountID.Int64(req.SourceAccountID),
+		tracing.AttrDestinationAccountID.Int64(req.DestinationAccountID),
+		tracing.AttrAmount.String(req.Amount),
+	)
 
-	if errs := validator.ValidateCreateTransaction(&req); len(errs) > 0 {
+	key, ok := parseIdempotencyKey(w, r)
+	if !ok {
+		return
+	}
+	req.IdempotencyKey = key
+
+	if errs := validator.ValidateCreateTransactionWithMode(&req, validationMode(r, h.validationMode)); len(errs) > 0 {
 		log.Debug().
 			Int64("sourceAccountID", req.SourceAccountID).
 			Int64("destAccountID", req.DestinationAccountID).
@@ -48,18 +201,421 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	var debug *models.TransferDebug
+	if debugRequested(r, h.debugResponses) {
+		ctx, debug = service.WithTransferDebug(ctx)
+	}
+
 	txn, err := h.transferService.Transfer(ctx, &req)
 	if err != nil {
-		handleServiceError(ctx, w, err)
+		tracing.RecordError(span, err)
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+	span.SetAttributes(tracing.AttrTransactionID.Int64(txn.TransactionID))
+
+	resp := newTransactionResponse(txn, h.idCodec, h.money, amountCurrency(w, r, h.currency))
+	resp.Debug = debug
+	if txn.Replayed {
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		writeSuccess(w, http.StatusOK, resp)
+		return
+	}
+	writeSuccess(w, http.StatusCreated, resp)
+}
+
+// CreateBatchTransfer moves funds from one source to many destinations in a single
+// all-or-nothing database transaction.
+func (h *TransactionHandler) CreateBatchTransfer(w http.ResponseWriter, r *http.Request) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	var req models.CreateBatchTransferRequest
+	if err := decodeJSONBody(w, r, h.maxRequestBody, &req); err != nil {
+		log.Debug().Err(err).Msg("Failed to decode batch transfer request")
+		writeDecodeError(w, err)
+		return
+	}
+
+	mode := validationMode(r, h.validationMode)
+	if errs := validator.ValidateCreateBatchTransferWithMode(&req, service.MaxBatchTransferSize, mode); len(errs) > 0 {
+		log.Debug().
+			Int64("sourceAccountID", req.SourceAccountID).
+			Int("transfers", len(req.Transfers)).
+			Interface("errors", errs).
+			Msg("Batch transfer validation failed")
+		writeValidationError(w, errs)
+		return
+	}
+
+	txns, err := h.transferService.BatchTransfer(ctx, &req)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	currency := amountCurrency(w, r, h.currency)
+	resp := BatchTransferResponse{Transactions: make([]TransactionResponse, len(txns))}
+	for i, txn := range txns {
+		resp.Transactions[i] = newTransactionResponse(txn, h.idCodec, h.money, currency)
+	}
+	writeSuccess(w, http.StatusCreated, resp)
+}
+
+// GetTransaction returns transaction {id}.
+func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	transactionID, ok := parseTransactionID(w, r, h.idCodec)
+	if !ok {
+		return
+	}
+	format, ok := parseScale(w, r, h.money)
+	if !ok {
+		return
+	}
+
+	txn, err := h.transferService.GetTransaction(ctx, transactionID)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, newTransactionResponse(txn, h.idCodec, format, amountCurrency(w, r, h.currency)))
+}
+
+// ReverseTransaction creates a compensating transaction that moves the funds of
+// transaction {id} back to its source account.
+func (h *TransactionHandler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	defer trackInFlight(h.inFlight)()
+	ctx := r.Context()
+
+	transactionID, ok := parseTransactionID(w, r, h.idCodec)
+	if !ok {
+		return
+	}
+
+	txn, err := h.transferService.Reverse(ctx, transactionID)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	writeSuccess(w, http.StatusCreated, newTransactionResponse(txn, h.idCodec, h.money, amountCurrency(w, r, h.currency)))
+}
+
+// ListAccountTransactions returns an account's transactions, newest first.
+//
+// Two paging modes are supported:
+//   - cursor (preferred): pass cursor= (empty for the first page) and follow next_cursor
+//     from the TransactionPage envelope. Stable while new transactions arrive.
+//   - offset: limit and offset, returning a PaginatedResponse with the total count and
+//     whether more pages follow.
+//
+// Missing or invalid limit/offset values fall back to the default page size and 0;
+// limit is clamped to the configured listing maximum. An optional scale rounds displayed
+// amounts; see parseScale.
+//
+// Offset mode also takes statement filters: from and to (RFC 3339) bound created_at, and
+// min_amount and max_amount bound the amount, all inclusive; see parseTransactionFilter.
+func (h *TransactionHandler) ListAccountTransactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	accountID, ok := parseAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	format, ok := parseScale(w, r, h.money)
+	if !ok {
+		return
+	}
+
+	filter, errs := parseTransactionFilter(r)
+	if len(errs) > 0 {
+		writeValidationError(w, errs)
+		return
+	}
+
+	limit := h.limits.listingLimit(r)
+
+	if r.URL.Query().Has("cursor") {
+		if !filter.IsZero() {
+			writeValidationError(w, validator.ValidationErrors{{Field: "cursor", Message: "can't be combined with from, to, min_amount, or max_amount; page filtered results with limit and offset"}})
+			return
+		}
+		h.listAccountTransactionsByCursor(w, r, accountID, limit, format)
+		return
+	}
+
+	offset := queryInt(r, "offset", 0)
+
+	txns, total, err := h.transferService.GetAccountTransactionsWithTotal(ctx, accountID, filter, limit, offset)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	currency := amountCurrency(w, r, h.currency)
+	data := make([]TransactionResponse, 0, len(txns))
+	for _, txn := range txns {
+		data = append(data, newTransactionResponse(txn, h.idCodec, format, currency))
+	}
+	writeSuccess(w, http.StatusOK, newPaginatedResponse(data, limit, offset, total))
+}
+
+func (h *TransactionHandler) listAccountTransactionsByCursor(w http.ResponseWriter, r *http.Request, accountID int64, limit int, format moneyFormat) {
+	ctx := r.Context()
+
+	var cursor models.TransactionCursor
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		var err error
+		cursor, err = models.ParseTransactionCursor(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_cursor", "Cursor must be a value returned as next_cursor")
+			return
+		}
+	}
+
+	txns, next, err := h.transferService.GetAccountTransactionsAfter(ctx, accountID, cursor, limit)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	currency := amountCurrency(w, r, h.currency)
+	page := TransactionPage{Transactions: make([]TransactionResponse, 0, len(txns))}
+	for _, txn := range txns {
+		page.Transactions = append(page.Transactions, newTransactionResponse(txn, h.idCodec, format, currency))
+	}
+	if !next.IsZero() {
+		page.NextCursor = next.String()
+	}
+	writeSuccess(w, http.StatusOK, page)
+}
+
+// LedgerEntryResponse is one side of a transaction in an account's ledger. Amount is
+// signed: negative for a debit, positive for a credit.
+type LedgerEntryResponse struct {
+	EntryID       int64    `json:"entry_id"`
+	TransactionID PublicID `json:"transaction_id"`
+	Side          string   `json:"side"`
+	Amount        string   `json:"amount"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+// ListAccountLedger returns an account's double-entry ledger entries, newest first,
+// paged by limit and offset like ListAccountTransactions' offset mode. An optional
+// scale rounds displayed amounts; see parseScale.
+func (h *TransactionHandler) ListAccountLedger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	accountID, ok := parseAccountID(w, r)
+	if !ok {
+		return
+	}
+	format, ok := parseScale(w, r, h.money)
+	if !ok {
+		return
+	}
+
+	entries, err := h.transferService.GetAccountLedger(ctx, accountID, h.limits.listingLimit(r), queryInt(r, "offset", 0))
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
 		return
 	}
 
+	resp := make([]LedgerEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		side := "credit"
+		if entry.IsDebit() {
+			side = "debit"
+		}
+		resp = append(resp, LedgerEntryResponse{
+			EntryID:       entry.EntryID,
+			TransactionID: newPublicID(entry.TransactionID, h.idCodec),
+			Side:          side,
+			Amount:        format(entry.Amount),
+			CreatedAt:     entry.CreatedAt.UTC().Format(models.TimestampLayout),
+		})
+	}
+	writeSuccess(w, http.StatusOK, resp)
+}
+
+// AccountCategorySummary returns an account's spent and received totals per transaction
+// category. Optional from and to query parameters (YYYY-MM-DD, inclusive) restrict the
+// effective-date range.
+func (h *TransactionHandler) AccountCategorySummary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	accountID, ok := parseAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	from, ok := parseDateQuery(w, r, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseDateQuery(w, r, "to")
+	if !ok {
+		return
+	}
+
+	totals, err := h.transferService.GetAccountCategoryTotals(ctx, accountID, from, to)
+	if err != nil {
+		handleServiceError(ctx, w, err, h.metrics)
+		return
+	}
+
+	resp := CategorySummaryResponse{
+		AccountID:  accountID,
+		From:       r.URL.Query().Get("from"),
+		To:         r.URL.Query().Get("to"),
+		Categories: make([]CategoryTotalResponse, 0, len(totals)),
+	}
+	for _, total := range totals {
+		item := CategoryTotalResponse{
+			Spent:    h.money(total.Spent),
+			Received: h.money(total.Received),
+			Count:    total.Count,
+		}
+		if total.Category != "" {
+			category := total.Category
+			item.Category = &category
+		}
+		resp.Categories = append(resp.Categories, item)
+	}
+	writeSuccess(w, http.StatusOK, resp)
+}
+
+// parseDateQuery reads an optional YYYY-MM-DD query parameter, returning the zero time
+// when absent. On a malformed value it writes a 400 and returns false.
+func parseDateQuery(w http.ResponseWriter, r *http.Request, name string) (time.Time, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, true
+	}
+	date, err := time.Parse(models.DateLayout, raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_date", name+" must be a date in YYYY-MM-DD format")
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// parseTransactionFilter reads the statement filters of a transaction listing: from and
+// to as RFC 3339 timestamps, with from not after to, and min_amount and max_amount as
+// non-negative decimals, with min_amount not above max_amount.
+func parseTransactionFilter(r *http.Request) (models.TransactionFilter, validator.ValidationErrors) {
+	query := r.URL.Query()
+	var filter models.TransactionFilter
+	var errs validator.ValidationErrors
+
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := query.Get(bound.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			errs = append(errs, validator.ValidationError{Field: bound.name, Message: "must be an RFC 3339 timestamp, e.g. 2024-03-01T00:00:00Z"})
+			continue
+		}
+		*bound.dst = t
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		errs = append(errs, validator.ValidationError{Field: "from", Message: "must not be after to"})
+	}
+
+	for _, bound := range []struct {
+		name string
+		dst  *decimal.NullDecimal
+	}{{"min_amount", &filter.MinAmount}, {"max_amount", &filter.MaxAmount}} {
+		raw := query.Get(bound.name)
+		if raw == "" {
+			continue
+		}
+		value, err := decimal.NewFromString(raw)
+		if err != nil {
+			errs = append(errs, validator.ValidationError{Field: bound.name, Message: "must be a valid decimal number"})
+			continue
+		}
+		if value.IsNegative() {
+			errs = append(errs, validator.ValidationError{Field: bound.name, Message: "must not be negative"})
+			continue
+		}
+		*bound.dst = decimal.NewNullDecimal(value)
+	}
+	if filter.MinAmount.Valid && filter.MaxAmount.Valid && filter.MaxAmount.Decimal.LessThan(filter.MinAmount.Decimal) {
+		errs = append(errs, validator.ValidationError{Field: "max_amount", Message: "must not be less than min_amount"})
+	}
+
+	return filter, errs
+}
+
+// parseTransactionID reads the {id} path value. With a codec only encoded IDs are
+// accepted, so raw IDs can't be enumerated.
+func parseTransactionID(w http.ResponseWriter, r *http.Request, codec idcodec.Codec) (int64, bool) {
+	idStr := r.PathValue("id")
+	if codec != nil {
+		transactionID, err := codec.Decode(idStr)
+		if err != nil {
+			log.Debug().Str("id", idStr).Msg("Invalid encoded transaction ID")
+			writeError(w, http.StatusBadRequest, "invalid_id", "Transaction ID is not valid")
+			return 0, false
+		}
+		return transactionID, true
+	}
+	transactionID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || transactionID <= 0 {
+		log.Debug().Str("id", idStr).Msg("Invalid transaction ID")
+		writeError(w, http.StatusBadRequest, "invalid_id", "Transaction ID must be a positive integer")
+		return 0, false
+	}
+	return transactionID, true
+}
+
+func newTransactionResponse(txn *models.Transaction, codec idcodec.Codec, format moneyFormat, currency string) TransactionResponse {
 	resp := TransactionResponse{
-		TransactionID:        txn.TransactionID,
+		TransactionID:        newPublicID(txn.TransactionID, codec),
+		Type:                 string(txn.Type),
 		SourceAccountID:      txn.SourceAccountID,
 		DestinationAccountID: txn.DestinationAccountID,
-		Amount:               txn.Amount.String(),
-		CreatedAt:            txn.CreatedAt.Format(time.RFC3339),
+		Amount:               format(txn.Amount),
+		EffectiveDate:        txn.EffectiveDate.Format(models.DateLayout),
+		Category:             txn.Category,
+		Status:               string(txn.Status),
+		FeeAmount:            format(txn.FeeAmount),
+		FeeAccountID:         txn.FeeAccountID,
+		FeePaidBy:            string(txn.FeePaidBy),
+		CreatedAt:            txn.CreatedAt.UTC().Format(models.TimestampLayout),
+		AmountCurrency:       currency,
 	}
-	writeSuccess(w, http.StatusCreated, resp)
+	if txn.ReversalOf != nil {
+		reversalOf := newPublicID(*txn.ReversalOf, codec)
+		resp.ReversalOf = &reversalOf
+	}
+	return resp
+}
+
+// ActiveTransfers reports how many transfers are in progress and the longest-running one,
+// for spotting transfers stuck on locks or the database.
+func (h *TransactionHandler) ActiveTransfers(w http.ResponseWriter, r *http.Request) {
+	count, oldest := h.transferService.ActiveTransfers()
+	resp := ActiveTransfersResponse{Count: count}
+	if oldest != nil {
+		resp.Oldest = &ActiveTransferResponse{
+			OperationID:          oldest.OperationID,
+			SourceAccountID:      oldest.SourceAccountID,
+			DestinationAccountID: oldest.DestinationAccountID,
+			Amount:               models.FormatMoney(oldest.Amount),
+			StartedAt:            oldest.StartedAt.UTC().Format(models.TimestampLayout),
+			AgeMs:                time.Since(oldest.StartedAt).Milliseconds(),
+		}
+	}
+	writeSuccess(w, http.StatusOK, resp)
 }
@@ -0,0 +1,126 @@
+//go:build integration
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/repository"
+	"internal-transfers-system/internal/service"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+func TestIntegration_ListAccountTransactions_EmptyVersusMissing(t *testing.T) {
+	if err := testSuite.Clean(); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	accRepo := repository.NewAccountRepository(testSuite.Pool())
+	txnRepo := repository.NewTransactionRepository(testSuite.Pool())
+	if err := accRepo.Create(context.Background(), &models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)}); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	h := NewTransactionHandler(service.NewTransferService(accRepo, txnRepo))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/accounts/{id}/transactions", h.ListAccountTransactions)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+		wantError  string
+	}{
+		{"existing account, offset paging", "/api/v1/accounts/1/transactions", http.StatusOK, `{"data":[],"pagination":{"limit":20,"offset":0,"total":0,"has_more":false}}`, ""},
+		{"existing account, cursor paging", "/api/v1/accounts/1/transactions?cursor=", http.StatusOK, `{"transactions":[]}`, ""},
+		{"missing account, offset paging", "/api/v1/accounts/2/transactions", http.StatusNotFound, "", "account_not_found"},
+		{"missing account, cursor paging", "/api/v1/accounts/2/transactions?cursor=", http.StatusNotFound, "", "account_not_found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantError != "" {
+				var resp ErrorResponse
+				json.Unmarshal(rec.Body.Bytes(), &resp)
+				if resp.Error != tt.wantError {
+					t.Errorf("expected error %q, got %q", tt.wantError, resp.Error)
+				}
+				return
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.wantBody {
+				t.Errorf("expected %s, got %s", tt.wantBody, got)
+			}
+		})
+	}
+}
+
+func TestIntegration_CreateTransaction_PoolExhausted(t *testing.T) {
+	if err := testSuite.Clean(); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	ctx := context.Background()
+
+	cfg := testSuite.Pool().Config().Copy()
+	cfg.MaxConns = 1
+	cfg.MinConns = 0
+	tiny, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatalf("pool: %v", err)
+	}
+	defer tiny.Close()
+
+	pools := repository.Pools{Transfer: tiny, Read: testSuite.Pool(), AcquireTimeout: 100 * time.Millisecond}
+	accRepo := repository.NewAccountRepositoryWithPools(pools, "")
+	txnRepo := repository.NewTransactionRepositoryWithPools(pools)
+	for _, id := range []int64{1, 2} {
+		if err := accRepo.Create(ctx, &models.Account{AccountID: id, Balance: decimal.NewFromInt(100)}); err != nil {
+			t.Fatalf("create account: %v", err)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/transactions", NewTransactionHandler(service.NewTransferService(accRepo, txnRepo)).CreateTransaction)
+	transfer := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		body := `{"source_account_id": 1, "destination_account_id": 2, "amount": "10"}`
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/transactions", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	// A transaction holding the pool's only connection
+	held, err := accRepo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+
+	rec := transfer()
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while the pool is exhausted, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("expected Retry-After 1, got %q", got)
+	}
+	var resp ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Error != "service_busy" || !resp.Retryable {
+		t.Errorf("expected a retryable service_busy, got %+v", resp)
+	}
+
+	held.Rollback(ctx)
+	if rec := transfer(); rec.Code != http.StatusCreated {
+		t.Errorf("expected the transfer to go through once the connection is free, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
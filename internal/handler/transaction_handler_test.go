@@ -1,12 +1,25 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"internal-transfers-system/internal/idcodec"
+	"internal-transfers-system/internal/mocks"
 	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+	"internal-transfers-system/internal/validator"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/shopspring/decimal"
 )
 
@@ -20,27 +33,49 @@ func TestTransactionResponse_Format(t *testing.T) {
 	}
 
 	resp := TransactionResponse{
-		TransactionID:        txn.TransactionID,
+		TransactionID:        PublicID{ID: txn.TransactionID},
 		SourceAccountID:      txn.SourceAccountID,
 		DestinationAccountID: txn.DestinationAccountID,
 		Amount:               txn.Amount.String(),
-		CreatedAt:            txn.CreatedAt.Format(time.RFC3339),
+		CreatedAt:            txn.CreatedAt.UTC().Format(models.TimestampLayout),
 	}
 
-	if resp.TransactionID != 1 {
-		t.Errorf("expected 1, got %d", resp.TransactionID)
+	if resp.TransactionID.ID != 1 {
+		t.Errorf("expected 1, got %d", resp.TransactionID.ID)
 	}
 	if resp.Amount != "150.5" {
 		t.Errorf("expected 150.5, got %s", resp.Amount)
 	}
 }
 
+func TestNewTransactionResponse_SubSecondCreatedAt(t *testing.T) {
+	// Two transactions in the same second must stay distinguishable and sortable
+	earlier := newTransactionResponse(&models.Transaction{
+		TransactionID: 1, CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 5_000, time.UTC),
+	}, nil, models.FormatMoney, "")
+	later := newTransactionResponse(&models.Transaction{
+		TransactionID: 2, CreatedAt: time.Date(2024, 1, 15, 11, 30, 0, 120_000_000, time.FixedZone("CET", 3600)),
+	}, nil, models.FormatMoney, "")
+
+	if earlier.CreatedAt != "2024-01-15T10:30:00.000005Z" {
+		t.Errorf("expected microsecond precision, got %s", earlier.CreatedAt)
+	}
+	if later.CreatedAt != "2024-01-15T10:30:00.120000Z" {
+		t.Errorf("expected UTC with fixed-width fraction, got %s", later.CreatedAt)
+	}
+	if earlier.CreatedAt >= later.CreatedAt {
+		t.Errorf("expected %s to sort before %s", earlier.CreatedAt, later.CreatedAt)
+	}
+}
+
 func TestTransactionResponse_JSON(t *testing.T) {
 	resp := TransactionResponse{
-		TransactionID:        1,
+		TransactionID:        PublicID{ID: 1},
 		SourceAccountID:      100,
 		DestinationAccountID: 200,
 		Amount:               "150.50",
+		EffectiveDate:        "2024-01-15",
+		Status:               "completed",
 		CreatedAt:            "2024-01-15T10:30:00Z",
 	}
 
@@ -48,10 +83,1080 @@ func TestTransactionResponse_JSON(t *testing.T) {
 	var parsed map[string]interface{}
 	json.Unmarshal(data, &parsed)
 
-	expected := []string{"transaction_id", "source_account_id", "destination_account_id", "amount", "created_at"}
+	expected := []string{"transaction_id", "source_account_id", "destination_account_id", "amount", "effective_date", "status", "created_at"}
 	for _, field := range expected {
 		if _, ok := parsed[field]; !ok {
 			t.Errorf("missing field %q", field)
 		}
 	}
 }
+
+func TestCreateTransaction_BodyTooLarge(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	opts := DefaultOptions()
+	opts.MaxRequestBody = 64
+	h := NewTransactionHandlerWithOptions(service.NewTransferService(accRepo, mocks.NewMockTransactionRepository()), opts)
+
+	body := `{"source_account_id":1,"destination_account_id":2,"amount":"10","category":"` + strings.Repeat("x", 64) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	h.CreateTransaction(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("expected a JSON error body: %v", err)
+	}
+	if resp.Error != "request_too_large" {
+		t.Errorf("expected request_too_large, got %q", resp.Error)
+	}
+
+	// Malformed bodies under the limit are still plain 400s
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/transactions", bytes.NewBufferString(`{"amount":`))
+	rec = httptest.NewRecorder()
+	h.CreateTransaction(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed body: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestCreateTransaction_IdempotencyKey(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	h := NewTransactionHandler(service.NewTransferService(accRepo, mocks.NewMockTransactionRepository()))
+
+	post := func(body, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions", bytes.NewBufferString(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		h.CreateTransaction(rec, req)
+		return rec
+	}
+
+	body := `{"source_account_id": 1, "destination_account_id": 2, "amount": "100"}`
+
+	if rec := post(body, "abc"); rec.Code != http.StatusCreated {
+		t.Fatalf("first request: expected 201, got %d", rec.Code)
+	}
+
+	rec := post(body, "abc")
+	if rec.Code != http.StatusOK {
+		t.Errorf("replay: expected 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replay: expected Idempotent-Replayed header")
+	}
+
+	rec = post(`{"source_account_id": 1, "destination_account_id": 2, "amount": "5"}`, "abc")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("conflict: expected 409, got %d", rec.Code)
+	}
+
+	if rec := post(body, strings.Repeat("k", 256)); rec.Code != http.StatusBadRequest {
+		t.Errorf("oversized key: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestCreateTransaction_StaleSequence(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	h := NewTransactionHandler(service.NewTransferService(accRepo, mocks.NewMockTransactionRepository()))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.CreateTransaction(rec, httptest.NewRequest(http.MethodPost, "/api/v1/transactions", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	body := `{"source_account_id": 1, "destination_account_id": 2, "amount": "100", "sequence": 7}`
+	if rec := post(body); rec.Code != http.StatusCreated {
+		t.Fatalf("first request: expected 201, got %d", rec.Code)
+	}
+	rec := post(body)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "stale_sequence") {
+		t.Errorf("replay: expected 409 stale_sequence, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post(`{"source_account_id": 1, "destination_account_id": 2, "amount": "100", "sequence": 0}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("zero sequence: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestCreateTransaction_InvalidAmountDetails(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	h := NewTransactionHandler(service.NewTransferService(accRepo, mocks.NewMockTransactionRepository()))
+
+	// Call the service path directly: the request validator would reject these first
+	tests := []struct {
+		amount     string
+		wantReason string
+		wantMsg    string
+	}{
+		{"-5", "not_positive", "amount must be positive"},
+		{"100.999", "too_many_decimal_places", "amount has more than 2 decimal places; amounts are never rounded or truncated"},
+		{"five", "not_numeric", "amount must be a valid decimal number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			_, err := h.transferService.Transfer(context.Background(), &models.CreateTransactionRequest{
+				SourceAccountID: 1, DestinationAccountID: 2, Amount: tt.amount,
+			})
+			rec := httptest.NewRecorder()
+			handleServiceError(context.Background(), rec, err, nil)
+
+			var resp ErrorResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if rec.Code != http.StatusBadRequest || resp.Error != "invalid_amount" || resp.Message != tt.wantMsg {
+				t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body.String())
+			}
+			if resp.Details == nil || resp.Details.Field != "amount" || resp.Details.Reason != tt.wantReason {
+				t.Errorf("expected details for amount/%s, got %+v", tt.wantReason, resp.Details)
+			}
+		})
+	}
+}
+
+func TestGetTransaction(t *testing.T) {
+	txnRepo := mocks.NewMockTransactionRepository()
+	txnRepo.SetTransaction(&models.Transaction{
+		TransactionID: 7, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.RequireFromString("42.50"),
+		EffectiveDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	})
+	h := NewTransactionHandler(service.NewTransferService(mocks.NewMockAccountRepository(), txnRepo))
+
+	tests := []struct {
+		name       string
+		id         string
+		query      string
+		wantStatus int
+		wantError  string
+		wantAmount string
+	}{
+		{"found", "7", "", http.StatusOK, "", "42.5"},
+		{"scaled", "7", "?scale=3", http.StatusOK, "", "42.500"},
+		{"invalid scale", "7", "?scale=99", http.StatusBadRequest, "invalid_scale", ""},
+		{"not found", "999", "", http.StatusNotFound, "transaction_not_found", ""},
+		{"non-integer id", "abc", "", http.StatusBadRequest, "invalid_id", ""},
+		{"zero id", "0", "", http.StatusBadRequest, "invalid_id", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions/"+tt.id+tt.query, nil)
+			req.SetPathValue("id", tt.id)
+			rec := httptest.NewRecorder()
+			h.GetTransaction(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantError != "" {
+				var resp ErrorResponse
+				json.Unmarshal(rec.Body.Bytes(), &resp)
+				if resp.Error != tt.wantError {
+					t.Errorf("expected error %q, got %q", tt.wantError, resp.Error)
+				}
+				return
+			}
+
+			var resp TransactionResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if resp.TransactionID.ID != 7 || resp.SourceAccountID != 1 || resp.DestinationAccountID != 2 ||
+				resp.Amount != tt.wantAmount || resp.EffectiveDate != "2024-01-15" || resp.Status != "completed" {
+				t.Errorf("unexpected transaction: %+v", resp)
+			}
+		})
+	}
+}
+
+func TestReverseTransaction(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	txnRepo := mocks.NewMockTransactionRepository()
+	txnRepo.SetTransaction(&models.Transaction{
+		TransactionID: 7, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100),
+	})
+	h := NewTransactionHandler(service.NewTransferService(accRepo, txnRepo))
+
+	reverse := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/"+id+"/reverse", nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		h.ReverseTransaction(rec, req)
+		return rec
+	}
+
+	rec := reverse("7")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp TransactionResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.ReversalOf == nil || resp.ReversalOf.ID != 7 || resp.SourceAccountID != 2 || resp.DestinationAccountID != 1 {
+		t.Errorf("unexpected reversal: %+v", resp)
+	}
+
+	if rec := reverse("7"); rec.Code != http.StatusConflict {
+		t.Errorf("double reversal: expected 409, got %d", rec.Code)
+	}
+	if rec := reverse("999"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown transaction: expected 404, got %d", rec.Code)
+	}
+	if rec := reverse("abc"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid id: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestHoldLifecycle(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	h := NewTransactionHandler(service.NewTransferService(accRepo, mocks.NewMockTransactionRepository()))
+
+	place := func(accountID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/accounts/"+accountID+"/holds", strings.NewReader(body))
+		req.SetPathValue("id", accountID)
+		rec := httptest.NewRecorder()
+		h.PlaceHold(rec, req)
+		return rec
+	}
+	resolve := func(handle http.HandlerFunc, holdID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/holds/"+holdID+"/capture", nil)
+		req.SetPathValue("id", holdID)
+		rec := httptest.NewRecorder()
+		handle(rec, req)
+		return rec
+	}
+
+	rec := place("1", `{"amount": "60"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var hold HoldResponse
+	json.Unmarshal(rec.Body.Bytes(), &hold)
+	if hold.HoldID != 1 || hold.AccountID != 1 || hold.Amount != "60" || hold.Status != "active" || hold.TransactionID != nil {
+		t.Errorf("unexpected hold: %+v", hold)
+	}
+
+	if rec := place("1", `{"amount": "50"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("hold over available balance: expected 422, got %d", rec.Code)
+	}
+	if rec := place("1", `{"amount": "-5"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative amount: expected 400, got %d", rec.Code)
+	}
+
+	rec = resolve(h.CaptureHold, "1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("capture: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	json.Unmarshal(rec.Body.Bytes(), &hold)
+	if hold.Status != "captured" || hold.TransactionID == nil || hold.ResolvedAt == "" {
+		t.Errorf("unexpected captured hold: %+v", hold)
+	}
+
+	if rec := resolve(h.ReleaseHold, "1"); rec.Code != http.StatusConflict {
+		t.Errorf("release after capture: expected 409, got %d", rec.Code)
+	}
+	if rec := resolve(h.ReleaseHold, "999"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown hold: expected 404, got %d", rec.Code)
+	}
+	if rec := resolve(h.CaptureHold, "abc"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid id: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestRecurringTransferLifecycle(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+	h := NewTransactionHandler(service.NewTransferService(accRepo, mocks.NewMockTransactionRepository()))
+
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transfers/recurring", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.CreateRecurringTransfer(rec, req)
+		return rec
+	}
+	byID := func(handle http.HandlerFunc, method, recurringID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/transfers/recurring/"+recurringID, nil)
+		req.SetPathValue("id", recurringID)
+		rec := httptest.NewRecorder()
+		handle(rec, req)
+		return rec
+	}
+
+	rec := create(`{"source_account_id": 1, "destination_account_id": 2, "amount": "10", "every": "24h", "start_at": "2030-01-01T09:00:00Z", "end_at": "2030-02-01T09:00:00Z"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var recurring RecurringTransferResponse
+	json.Unmarshal(rec.Body.Bytes(), &recurring)
+	if recurring.RecurringID != 1 || recurring.Amount != "10" || recurring.Every != "24h0m0s" || recurring.Status != "active" ||
+		recurring.NextRunAt != "2030-01-01T09:00:00.000000Z" || recurring.EndAt != "2030-02-01T09:00:00.000000Z" {
+		t.Errorf("unexpected recurring transfer: %+v", recurring)
+	}
+
+	if rec := create(`{"source_account_id": 1, "destination_account_id": 2, "amount": "10", "every": "10s"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("interval too short: expected 400, got %d", rec.Code)
+	}
+	if rec := create(`{"source_account_id": 1, "destination_account_id": 9, "amount": "10", "every": "1h"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown destination: expected 404, got %d", rec.Code)
+	}
+
+	if rec := byID(h.GetRecurringTransfer, http.MethodGet, "1"); rec.Code != http.StatusOK {
+		t.Errorf("get: expected 200, got %d", rec.Code)
+	}
+
+	rec = byID(h.CancelRecurringTransfer, http.MethodDelete, "1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("cancel: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	json.Unmarshal(rec.Body.Bytes(), &recurring)
+	if recurring.Status != "canceled" || recurring.CanceledAt == "" {
+		t.Errorf("unexpected canceled recurring transfer: %+v", recurring)
+	}
+
+	if rec := byID(h.CancelRecurringTransfer, http.MethodDelete, "1"); rec.Code != http.StatusConflict {
+		t.Errorf("cancel twice: expected 409, got %d", rec.Code)
+	}
+	if rec := byID(h.CancelRecurringTransfer, http.MethodDelete, "999"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown recurring transfer: expected 404, got %d", rec.Code)
+	}
+	if rec := byID(h.GetRecurringTransfer, http.MethodGet, "abc"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid id: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestCreateBatchTransfer(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantSource string
+	}{
+		{"success", `{"source_account_id": 1, "transfers": [{"destination_account_id": 3, "amount": "30"}, {"destination_account_id": 2, "amount": "20"}]}`, http.StatusCreated, "50"},
+		{"validation error", `{"source_account_id": 1, "transfers": [{"destination_account_id": 1, "amount": "0"}]}`, http.StatusBadRequest, "100"},
+		{"insufficient total", `{"source_account_id": 1, "transfers": [{"destination_account_id": 2, "amount": "60"}, {"destination_account_id": 3, "amount": "60"}]}`, http.StatusUnprocessableEntity, "100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accRepo := mocks.NewMockAccountRepository()
+			accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+			accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+			accRepo.SetAccount(&models.Account{AccountID: 3, Balance: decimal.NewFromInt(0)})
+			h := NewTransactionHandler(service.NewTransferService(accRepo, mocks.NewMockTransactionRepository()))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/batch", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			h.CreateBatchTransfer(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != tt.wantSource {
+				t.Errorf("expected source balance %s, got %s", tt.wantSource, acc.Balance)
+			}
+			if rec.Code != http.StatusCreated {
+				return
+			}
+
+			var resp BatchTransferResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if len(resp.Transactions) != 2 || resp.Transactions[0].DestinationAccountID != 3 || resp.Transactions[1].Amount != "20" {
+				t.Errorf("expected per-leg results in request order, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestListAccountTransactions(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	for i := int64(1); i <= 25; i++ {
+		txnRepo.SetTransaction(&models.Transaction{
+			TransactionID: i, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(i),
+		})
+	}
+	h := NewTransactionHandler(service.NewTransferService(accRepo, txnRepo))
+
+	list := func(id, query string) (*httptest.ResponseRecorder, PaginatedResponse[TransactionResponse]) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+id+"/transactions"+query, nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		h.ListAccountTransactions(rec, req)
+		var resp PaginatedResponse[TransactionResponse]
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		query      string
+		wantStatus int
+		wantCount  int
+	}{
+		{"default page size", "1", "", http.StatusOK, service.DefaultPageSize},
+		{"explicit limit", "1", "?limit=5", http.StatusOK, 5},
+		{"offset", "2", "?limit=10&offset=20", http.StatusOK, 5},
+		{"invalid limit falls back", "1", "?limit=abc", http.StatusOK, service.DefaultPageSize},
+		{"negative offset falls back", "1", "?limit=3&offset=-4", http.StatusOK, 3},
+		{"unknown account", "999", "", http.StatusNotFound, 0},
+		{"invalid id", "abc", "", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, resp := list(tt.id, tt.query)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusOK && len(resp.Data) != tt.wantCount {
+				t.Errorf("expected %d transactions, got %d", tt.wantCount, len(resp.Data))
+			}
+		})
+	}
+}
+
+func TestListAccountTransactions_Pagination(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	accRepo.SetAccount(&models.Account{AccountID: 3, Balance: decimal.NewFromInt(0)})
+	for i := int64(1); i <= 10; i++ {
+		txnRepo.SetTransaction(&models.Transaction{
+			TransactionID: i, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(i * 10),
+		})
+	}
+	h := NewTransactionHandler(service.NewTransferService(accRepo, txnRepo))
+
+	tests := []struct {
+		name      string
+		id        string
+		query     string
+		wantCount int
+		want      Pagination
+	}{
+		{"first page", "1", "?limit=4", 4, Pagination{Limit: 4, Offset: 0, Total: 10, HasMore: true}},
+		{"page ending one short of the total", "1", "?limit=4&offset=5", 4, Pagination{Limit: 4, Offset: 5, Total: 10, HasMore: true}},
+		{"page ending exactly at the total", "1", "?limit=5&offset=5", 5, Pagination{Limit: 5, Offset: 5, Total: 10, HasMore: false}},
+		{"partial last page", "1", "?limit=4&offset=8", 2, Pagination{Limit: 4, Offset: 8, Total: 10, HasMore: false}},
+		{"offset past the end", "1", "?limit=4&offset=12", 0, Pagination{Limit: 4, Offset: 12, Total: 10, HasMore: false}},
+		{"filtered total", "1", "?limit=2&min_amount=70", 2, Pagination{Limit: 2, Offset: 0, Total: 4, HasMore: true}},
+		{"no transactions", "3", "", 0, Pagination{Limit: service.DefaultPageSize, Offset: 0, Total: 0, HasMore: false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+tt.id+"/transactions"+tt.query, nil)
+			req.SetPathValue("id", tt.id)
+			rec := httptest.NewRecorder()
+			h.ListAccountTransactions(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var resp PaginatedResponse[TransactionResponse]
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if len(resp.Data) != tt.wantCount {
+				t.Errorf("expected %d transactions, got %d", tt.wantCount, len(resp.Data))
+			}
+			if resp.Pagination != tt.want {
+				t.Errorf("expected pagination %+v, got %+v", tt.want, resp.Pagination)
+			}
+		})
+	}
+
+	// The envelope's shape, with data an array even when empty
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/3/transactions", nil)
+	req.SetPathValue("id", "3")
+	rec := httptest.NewRecorder()
+	h.ListAccountTransactions(rec, req)
+	want := `{"data":[],"pagination":{"limit":20,"offset":0,"total":0,"has_more":false}}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestListAccountLedger(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	svc := service.NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+	for _, amount := range []string{"10", "2.5"} {
+		if _, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+			SourceAccountID: 1, DestinationAccountID: 2, Amount: amount,
+		}); err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+	}
+	h := NewTransactionHandler(svc)
+
+	list := func(id string) (*httptest.ResponseRecorder, []LedgerEntryResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+id+"/ledger", nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		h.ListAccountLedger(rec, req)
+		var resp []LedgerEntryResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	rec, resp := list("1")
+	if rec.Code != http.StatusOK || len(resp) != 2 {
+		t.Fatalf("expected 200 with 2 entries, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// Newest first
+	if resp[0].Side != "debit" || resp[0].Amount != "-2.5" || resp[0].TransactionID.ID != 2 {
+		t.Errorf("expected a -2.5 debit for transaction 2 first, got %+v", resp[0])
+	}
+
+	_, resp = list("2")
+	if len(resp) != 2 || resp[1].Side != "credit" || resp[1].Amount != "10" {
+		t.Errorf("expected account 2's oldest entry to be a 10 credit, got %+v", resp)
+	}
+
+	if rec, _ := list("999"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown account, got %d", rec.Code)
+	}
+}
+
+func TestListAccountTransactions_Filters(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	for i := int64(1); i <= 10; i++ {
+		txnRepo.SetTransaction(&models.Transaction{
+			TransactionID: i, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(i * 10),
+			CreatedAt: time.Date(2024, 3, int(i), 12, 0, 0, 0, time.UTC),
+		})
+	}
+	h := NewTransactionHandler(service.NewTransferService(accRepo, txnRepo))
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []int64
+		wantField  string
+	}{
+		{"date range", "?from=2024-03-03T00:00:00Z&to=2024-03-05T23:59:59Z", http.StatusOK, []int64{5, 4, 3}, ""},
+		{"bounds are inclusive", "?from=2024-03-09T12:00:00Z&min_amount=90", http.StatusOK, []int64{10, 9}, ""},
+		{"amount range", "?min_amount=25&max_amount=40.00", http.StatusOK, []int64{4, 3}, ""},
+		{"date and amount", "?to=2024-03-06T00:00:00Z&min_amount=50", http.StatusOK, []int64{5}, ""},
+		{"filtered page", "?min_amount=10&limit=2&offset=1", http.StatusOK, []int64{9, 8}, ""},
+		{"invalid from", "?from=2024-03-01", http.StatusBadRequest, nil, "from"},
+		{"from after to", "?from=2024-03-05T00:00:00Z&to=2024-03-01T00:00:00Z", http.StatusBadRequest, nil, "from"},
+		{"invalid amount", "?max_amount=abc", http.StatusBadRequest, nil, "max_amount"},
+		{"negative amount", "?min_amount=-1", http.StatusBadRequest, nil, "min_amount"},
+		{"max below min", "?min_amount=50&max_amount=10", http.StatusBadRequest, nil, "max_amount"},
+		{"with cursor", "?cursor=&min_amount=10", http.StatusBadRequest, nil, "cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/1/transactions"+tt.query, nil)
+			req.SetPathValue("id", "1")
+			rec := httptest.NewRecorder()
+			h.ListAccountTransactions(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				var resp ValidationErrorResponse
+				json.Unmarshal(rec.Body.Bytes(), &resp)
+				if len(resp.Errors) == 0 || resp.Errors[0].Field != tt.wantField {
+					t.Errorf("expected a validation error on %q, got %+v", tt.wantField, resp)
+				}
+				return
+			}
+			var resp PaginatedResponse[TransactionResponse]
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			var ids []int64
+			for _, txn := range resp.Data {
+				ids = append(ids, txn.TransactionID.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("expected transactions %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestListAccountTransactions_Cursor(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	for i := int64(1); i <= 5; i++ {
+		txnRepo.SetTransaction(&models.Transaction{
+			TransactionID: i, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(i),
+		})
+	}
+	h := NewTransactionHandler(service.NewTransferService(accRepo, txnRepo))
+
+	get := func(query string) (*httptest.ResponseRecorder, TransactionPage) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/1/transactions"+query, nil)
+		req.SetPathValue("id", "1")
+		rec := httptest.NewRecorder()
+		h.ListAccountTransactions(rec, req)
+		var page TransactionPage
+		json.Unmarshal(rec.Body.Bytes(), &page)
+		return rec, page
+	}
+
+	_, page := get("?cursor=&limit=3")
+	if len(page.Transactions) != 3 || page.Transactions[0].TransactionID.ID != 5 || page.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+
+	_, page = get("?cursor=" + page.NextCursor + "&limit=3")
+	if len(page.Transactions) != 2 || page.Transactions[0].TransactionID.ID != 2 || page.NextCursor != "" {
+		t.Fatalf("unexpected last page: %+v", page)
+	}
+
+	if rec, _ := get("?cursor=abc"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid cursor, got %d", rec.Code)
+	}
+}
+
+func TestExportAccountTransactions(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	march := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	for _, txn := range []*models.Transaction{
+		{TransactionID: 1, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.RequireFromString("25.5"), EffectiveDate: march(1), CreatedAt: march(1)},
+		{TransactionID: 2, SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(10), EffectiveDate: march(2), CreatedAt: march(2)},
+		{TransactionID: 3, Type: models.TransactionTypeDeposit, DestinationAccountID: 1, Amount: decimal.NewFromInt(5), EffectiveDate: march(3), CreatedAt: march(3)},
+		{TransactionID: 4, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(7), EffectiveDate: march(4), CreatedAt: march(4), Status: models.TransactionStatusFailed},
+		{TransactionID: 5, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(20), EffectiveDate: march(5), CreatedAt: march(5),
+			FeeAmount: decimal.NewFromInt(1), FeeAccountID: 9, FeePaidBy: models.FeePayerSource},
+		{TransactionID: 6, SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(30), EffectiveDate: march(6), CreatedAt: march(6),
+			FeeAmount: decimal.NewFromInt(2), FeeAccountID: 9, FeePaidBy: models.FeePayerDestination},
+	} {
+		txnRepo.SetTransaction(txn)
+	}
+	// A page size below the row count makes the export read several pages
+	limits := DefaultPageLimits()
+	limits.MaxExport = 2
+	h := NewTransactionHandlerWithLimits(service.NewTransferService(accRepo, txnRepo), limits)
+
+	export := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+id+"/transactions.csv", nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		h.ExportAccountTransactions(rec, req)
+		return rec
+	}
+
+	rec := export("1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected a text/csv content type, got %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="account-1-transactions.csv"` {
+		t.Errorf("unexpected Content-Disposition %q", cd)
+	}
+	// The failed transfer is left out, and each side shows only the fee it paid
+	want := "transaction_id,date,direction,counterparty,amount,fee\n" +
+		"6,2024-03-06,credit,2,30,2\n" +
+		"5,2024-03-05,debit,2,20,1\n" +
+		"3,2024-03-03,credit,,5,0\n" +
+		"2,2024-03-02,credit,2,10,0\n" +
+		"1,2024-03-01,debit,2,25.5,0\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	// The counterparty sees the same transfers with the directions swapped
+	rec = export("2")
+	want = "transaction_id,date,direction,counterparty,amount,fee\n" +
+		"6,2024-03-06,debit,1,30,0\n" +
+		"5,2024-03-05,credit,1,20,0\n" +
+		"2,2024-03-02,debit,1,10,0\n" +
+		"1,2024-03-01,credit,1,25.5,0\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	accRepo.SetAccount(&models.Account{AccountID: 3})
+	if rec := export("3"); rec.Code != http.StatusOK || rec.Body.String() != "transaction_id,date,direction,counterparty,amount,fee\n" {
+		t.Errorf("expected only the header row for an account without transactions, got %d: %q", rec.Code, rec.Body.String())
+	}
+	if rec := export("999"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown account, got %d", rec.Code)
+	}
+
+	// An unset export page size falls back to the default batch size
+	h = NewTransactionHandlerWithLimits(service.NewTransferService(accRepo, txnRepo), PageLimits{})
+	if rec := export("2"); rec.Code != http.StatusOK || strings.Count(rec.Body.String(), "\n") != 5 {
+		t.Errorf("expected the full export without a configured page size, got %d: %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestListAccountTransactions_ConfiguredListingMax(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	for i := int64(1); i <= 300; i++ {
+		txnRepo.SetTransaction(&models.Transaction{
+			TransactionID: i, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1),
+		})
+	}
+
+	tests := []struct {
+		name   string
+		limits PageLimits
+		query  string
+		want   int
+	}{
+		{"clamped to small listing max", PageLimits{DefaultSize: 5, MaxListing: 7}, "?limit=50", 7},
+		{"large listing max allowed", PageLimits{DefaultSize: 5, MaxListing: 250}, "?limit=250", 250},
+		{"configured default", PageLimits{DefaultSize: 5, MaxListing: 7}, "", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewTransactionHandlerWithLimits(service.NewTransferService(accRepo, txnRepo), tt.limits)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/1/transactions"+tt.query, nil)
+			req.SetPathValue("id", "1")
+			rec := httptest.NewRecorder()
+			h.ListAccountTransactions(rec, req)
+
+			var resp PaginatedResponse[TransactionResponse]
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if len(resp.Data) != tt.want {
+				t.Errorf("expected %d transactions, got %d", tt.want, len(resp.Data))
+			}
+		})
+	}
+}
+
+func TestCreateTransaction_ValidationMode(t *testing.T) {
+	svc := service.NewTransferService(mocks.NewMockAccountRepository(), mocks.NewMockTransactionRepository())
+	body := `{"source_account_id": 0, "destination_account_id": 0, "amount": "abc"}`
+
+	post := func(h *TransactionHandler, mode string) ValidationErrorResponse {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions", bytes.NewBufferString(body))
+		if mode != "" {
+			req.Header.Set(ValidationModeHeader, mode)
+		}
+		rec := httptest.NewRecorder()
+		h.CreateTransaction(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", rec.Code)
+		}
+		var resp ValidationErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+
+	collectAll := NewTransactionHandler(svc)
+	if resp := post(collectAll, ""); len(resp.Errors) != 3 {
+		t.Errorf("default: expected all 3 errors, got %v", resp.Errors)
+	}
+	if resp := post(collectAll, "fail-fast"); len(resp.Errors) != 1 {
+		t.Errorf("header override: expected 1 error, got %v", resp.Errors)
+	}
+
+	opts := DefaultOptions()
+	opts.ValidationMode = validator.FailFast
+	failFast := NewTransactionHandlerWithOptions(svc, opts)
+	if resp := post(failFast, ""); len(resp.Errors) != 1 {
+		t.Errorf("configured fail-fast: expected 1 error, got %v", resp.Errors)
+	}
+	if resp := post(failFast, "collect-all"); len(resp.Errors) != 3 {
+		t.Errorf("header override: expected 3 errors, got %v", resp.Errors)
+	}
+}
+
+func TestCreateTransaction_DebugResponse(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	accRepo.CommitErrors = []error{&pgconn.PgError{Code: "40001"}}
+	config := service.TransferServiceConfig{MaxRetries: 2, RetryBaseDelay: time.Millisecond, RetryOnCommitFailure: true}
+	svc := service.NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), config)
+
+	post := func(h *TransactionHandler, debugHeader string) TransactionResponse {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions",
+			bytes.NewBufferString(`{"source_account_id": 1, "destination_account_id": 2, "amount": "1"}`))
+		if debugHeader != "" {
+			req.Header.Set(DebugHeader, debugHeader)
+		}
+		rec := httptest.NewRecorder()
+		h.CreateTransaction(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp TransactionResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+
+	opts := DefaultOptions()
+	opts.DebugResponses = true
+	debugHandler := NewTransactionHandlerWithOptions(svc, opts)
+
+	resp := post(debugHandler, "true")
+	if resp.Debug == nil {
+		t.Fatal("expected debug details")
+	}
+	if resp.Debug.IsolationLevel != "read committed" || resp.Debug.MaxRetries != 2 || resp.Debug.Attempts != 2 {
+		t.Errorf("unexpected debug details: %+v", resp.Debug)
+	}
+
+	if resp := post(debugHandler, ""); resp.Debug != nil {
+		t.Error("debug details must not be returned unless requested")
+	}
+	if resp := post(NewTransactionHandler(svc), "true"); resp.Debug != nil {
+		t.Error("debug details must not be returned when disabled")
+	}
+}
+
+func TestCreateTransaction_AmountFormat(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	svc := service.NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+
+	post := func(h *TransactionHandler, format string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions",
+			bytes.NewBufferString(`{"source_account_id": 1, "destination_account_id": 2, "amount": "150.5"}`))
+		if format != "" {
+			req.Header.Set(AmountFormatHeader, format)
+		}
+		rec := httptest.NewRecorder()
+		h.CreateTransaction(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if rec.Header().Get("Vary") != AmountFormatHeader {
+			t.Errorf("expected Vary: %s, got %q", AmountFormatHeader, rec.Header().Get("Vary"))
+		}
+		var resp map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+
+	opts := DefaultOptions()
+	opts.FixedAmounts, opts.MinorUnits, opts.Currency = true, 2, "EUR"
+	h := NewTransactionHandlerWithOptions(svc, opts)
+
+	tests := []struct {
+		name          string
+		handler       *TransactionHandler
+		format        string
+		wantAmount    interface{}
+		wantFeeAmount interface{}
+	}{
+		{"plain by default", h, "", "150.50", "0.00"},
+		{"unknown format stays plain", h, "number", "150.50", "0.00"},
+		{"object", h, AmountFormatObject, map[string]interface{}{"value": "150.50", "currency": "EUR"}, map[string]interface{}{"value": "0.00", "currency": "EUR"}},
+		{"object in the default currency", NewTransactionHandler(svc), AmountFormatObject, map[string]interface{}{"value": "150.5", "currency": "USD"}, map[string]interface{}{"value": "0", "currency": "USD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(tt.handler, tt.format)
+			if !reflect.DeepEqual(resp["amount"], tt.wantAmount) || !reflect.DeepEqual(resp["fee_amount"], tt.wantFeeAmount) {
+				t.Errorf("expected amount %v and fee %v, got %v and %v", tt.wantAmount, tt.wantFeeAmount, resp["amount"], resp["fee_amount"])
+			}
+			if resp["source_account_id"] != float64(1) || resp["status"] == nil {
+				t.Errorf("expected the other fields unchanged, got %v", resp)
+			}
+		})
+	}
+}
+
+func TestAccountCategorySummary(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(1000)})
+	march := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	txnRepo.SetTransaction(&models.Transaction{
+		TransactionID: 1, SourceAccountID: 1, DestinationAccountID: 2,
+		Amount: decimal.RequireFromString("12.50"), Category: "rent", EffectiveDate: march(1),
+	})
+	txnRepo.SetTransaction(&models.Transaction{
+		TransactionID: 2, SourceAccountID: 2, DestinationAccountID: 1,
+		Amount: decimal.NewFromInt(4), EffectiveDate: march(15),
+	})
+	h := NewTransactionHandler(service.NewTransferService(accRepo, txnRepo))
+
+	get := func(id, query string) (*httptest.ResponseRecorder, CategorySummaryResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+id+"/transactions/by-category"+query, nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		h.AccountCategorySummary(rec, req)
+		var resp CategorySummaryResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	rec, resp := get("1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(resp.Categories) != 2 {
+		t.Fatalf("expected 2 categories, got %+v", resp.Categories)
+	}
+	if resp.Categories[0].Category != nil || resp.Categories[0].Received != "4" {
+		t.Errorf("expected uncategorized with 4 received, got %+v", resp.Categories[0])
+	}
+	if c := resp.Categories[1]; c.Category == nil || *c.Category != "rent" || c.Spent != "12.5" || c.Received != "0" || c.Count != 1 {
+		t.Errorf("unexpected rent totals: %+v", c)
+	}
+	if !strings.Contains(rec.Body.String(), `"category":null`) {
+		t.Errorf("expected uncategorized bucket to report a null category: %s", rec.Body.String())
+	}
+
+	rec, resp = get("1", "?from=2024-03-10&to=2024-03-31")
+	if rec.Code != http.StatusOK || len(resp.Categories) != 1 || resp.From != "2024-03-10" || resp.To != "2024-03-31" {
+		t.Errorf("expected only the uncategorized entry in range, got %d %+v", rec.Code, resp)
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		query      string
+		wantStatus int
+	}{
+		{"malformed from", "1", "?from=03/10/2024", http.StatusBadRequest},
+		{"inverted range", "1", "?from=2024-03-31&to=2024-03-01", http.StatusBadRequest},
+		{"unknown account", "999", "", http.StatusNotFound},
+		{"invalid id", "abc", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec, _ := get(tt.id, tt.query); rec.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestTransactionHandler_OpaqueIDs(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	codec, err := idcodec.NewOpaque("handler-test-salt-0123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultOptions()
+	opts.IDCodec = codec
+	h := NewTransactionHandlerWithOptions(service.NewTransferService(accRepo, mocks.NewMockTransactionRepository()), opts)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions",
+		bytes.NewBufferString(`{"source_account_id":1,"destination_account_id":2,"amount":"10"}`))
+	rec := httptest.NewRecorder()
+	h.CreateTransaction(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var raw map[string]any
+	json.Unmarshal(rec.Body.Bytes(), &raw)
+	encoded, ok := raw["transaction_id"].(string)
+	if !ok || encoded == "" {
+		t.Fatalf("expected a string transaction_id, got %v", raw["transaction_id"])
+	}
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions/"+id, nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		h.GetTransaction(rec, req)
+		return rec
+	}
+
+	rec = get(encoded)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp TransactionResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.TransactionID.Encoded != encoded {
+		t.Errorf("expected transaction_id %q, got %+v", encoded, resp.TransactionID)
+	}
+
+	id, _ := codec.Decode(encoded)
+	rec = get(strconv.FormatInt(id, 10))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("raw id: expected 400, got %d", rec.Code)
+	}
+	var errResp ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &errResp)
+	if errResp.Error != "invalid_id" {
+		t.Errorf("expected invalid_id, got %q", errResp.Error)
+	}
+}
+
+func TestActiveTransfers_None(t *testing.T) {
+	h := NewTransactionHandler(service.NewTransferService(mocks.NewMockAccountRepository(), mocks.NewMockTransactionRepository()))
+
+	rec := httptest.NewRecorder()
+	h.ActiveTransfers(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/transfers/active", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"count":0}` {
+		t.Errorf(`expected {"count":0} with no oldest transfer, got %s`, got)
+	}
+}
+
+func TestVerifyLedger_ReportsDrift(t *testing.T) {
+	txnRepo := mocks.NewMockTransactionRepository()
+	txnRepo.Verification = &models.LedgerVerification{
+		Accounts:        2,
+		TotalBalance:    decimal.RequireFromString("151.5"),
+		InitialBalances: decimal.NewFromInt(100),
+		NetTransactions: decimal.NewFromInt(50),
+		DriftedAccounts: []*models.AccountDrift{{AccountID: 7, Balance: decimal.RequireFromString("11.5"), Expected: decimal.NewFromInt(10)}},
+	}
+	h := NewTransactionHandler(service.NewTransferService(mocks.NewMockAccountRepository(), txnRepo))
+
+	rec := httptest.NewRecorder()
+	h.VerifyLedger(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/consistency", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp LedgerConsistencyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Consistent || resp.ExpectedBalance != "150" || resp.Drift != "1.5" {
+		t.Errorf("expected drift of 1.5 against 150, got %+v", resp)
+	}
+	want := AccountDriftResponse{AccountID: 7, Balance: "11.5", Expected: "10", Drift: "1.5"}
+	if len(resp.DriftedAccounts) != 1 || resp.DriftedAccounts[0] != want {
+		t.Errorf("expected %+v, got %+v", want, resp.DriftedAccounts)
+	}
+
+	txnRepo.Verification = nil
+	rec = httptest.NewRecorder()
+	h.VerifyLedger(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/consistency", nil))
+	resp = LedgerConsistencyResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || !resp.Consistent || resp.DriftedAccounts == nil {
+		t.Errorf("expected a consistent ledger with an empty drift list, got %+v, %v", resp, err)
+	}
+}
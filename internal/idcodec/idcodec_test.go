@@ -0,0 +1,63 @@
+package idcodec
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+const testSalt = "test-salt-0123456789"
+
+func TestOpaque_RoundTrip(t *testing.T) {
+	codec, err := NewOpaque(testSalt)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	seen := make(map[string]int64)
+	for _, id := range []int64{1, 2, 3, 42, 1000, 1 << 32, math.MaxInt64} {
+		encoded := codec.Encode(id)
+		if len(encoded) != 11 {
+			t.Errorf("%d: expected 11 characters, got %q", id, encoded)
+		}
+		if prev, ok := seen[encoded]; ok {
+			t.Errorf("%d and %d both encode to %q", prev, id, encoded)
+		}
+		seen[encoded] = id
+
+		decoded, err := codec.Decode(encoded)
+		if err != nil || decoded != id {
+			t.Errorf("%d: round trip gave %d, %v", id, decoded, err)
+		}
+	}
+
+	if codec.Encode(1)[:6] == codec.Encode(2)[:6] {
+		t.Errorf("sequential IDs should not share a visible prefix: %s %s", codec.Encode(1), codec.Encode(2))
+	}
+}
+
+func TestOpaque_SaltChangesEncoding(t *testing.T) {
+	a, _ := NewOpaque(testSalt)
+	b, _ := NewOpaque(testSalt + "x")
+	if a.Encode(7) == b.Encode(7) {
+		t.Error("expected different salts to give different encodings")
+	}
+	if id, err := b.Decode(a.Encode(7)); err == nil && id == 7 {
+		t.Error("expected an encoding not to decode under another salt")
+	}
+}
+
+func TestOpaque_DecodeRejectsMalformed(t *testing.T) {
+	codec, _ := NewOpaque(testSalt)
+	for _, input := range []string{"", "42", "abc", "0000000000!", "zzzzzzzzzzz", codec.Encode(5) + "0"} {
+		if _, err := codec.Decode(input); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("%q: expected ErrInvalidID, got %v", input, err)
+		}
+	}
+}
+
+func TestNewOpaque_ShortSalt(t *testing.T) {
+	if _, err := NewOpaque("short"); err == nil {
+		t.Error("expected an error for a short salt")
+	}
+}
@@ -5,7 +5,9 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
+	"internal-transfers-system/internal/consistency"
 	"internal-transfers-system/internal/models"
 
 	"github.com/jackc/pgx/v5"
@@ -20,10 +22,34 @@ type AccountRepository interface {
 	// Returns an error if the account already exists (duplicate key) or on database failure.
 	Create(ctx context.Context, account *models.Account) error
 
-	// GetByID retrieves an account by its ID.
+	// CreateWithGeneratedID inserts a new account with an ID taken from the database
+	// sequence, ignoring account.AccountID, and sets AccountID, CreatedAt, and UpdatedAt.
+	// IDs already taken by client-supplied accounts are skipped.
+	CreateWithGeneratedID(ctx context.Context, account *models.Account) error
+
+	// CreateBatch inserts accounts within tx using one multi-row INSERT per round and
+	// reports, for each account, whether it was created. An account whose ID is already
+	// taken is skipped instead of failing the batch. Accounts with a zero AccountID get
+	// an ID from the database sequence, skipping taken values as CreateWithGeneratedID
+	// does. Created accounts have AccountID, CreatedAt, and UpdatedAt set.
+	CreateBatch(ctx context.Context, tx pgx.Tx, accounts []*models.Account) ([]bool, error)
+
+	// GetByID retrieves an account by its ID. It may be served by a read replica, so
+	// it can miss writes committed moments ago unless ctx carries a consistency token
+	// covering them. Use GetByIDInTx for a read that a write will depend on.
 	// Returns ErrAccountNotFound if the account does not exist.
 	GetByID(ctx context.Context, accountID int64) (*models.Account, error)
 
+	// GetByIDs retrieves the accounts among ids that exist, ordered by account ID, in
+	// one query. Missing IDs are left out rather than failing the call. Like GetByID, it
+	// may be served by a read replica.
+	GetByIDs(ctx context.Context, ids []int64) ([]*models.Account, error)
+
+	// GetByIDInTx retrieves an account within a transaction without locking it, seeing
+	// the latest committed version and tx's own writes.
+	// Returns ErrAccountNotFound if the account does not exist.
+	GetByIDInTx(ctx context.Context, tx pgx.Tx, accountID int64) (*models.Account, error)
+
 	// GetByIDForUpdate retrieves an account with a row-level lock for update.
 	// This prevents other transactions from modifying or locking the row until
 	// the current transaction completes. Must be called within a transaction.
@@ -36,16 +62,103 @@ type AccountRepository interface {
 	// Returns ErrAccountNotFound if the account does not exist.
 	GetByIDForUpdate(ctx context.Context, tx pgx.Tx, accountID int64) (*models.Account, error)
 
-	// UpdateBalance updates the balance of an account within a transaction.
-	// Returns an error if the update fails or if no rows were affected (account not found).
+	// LockAccountAdvisory takes a transaction-scoped advisory lock keyed on accountID,
+	// waiting while another transaction holds it. The lock is released when tx ends. It
+	// serializes transactions that take it without locking the account row, so it only
+	// guards against writers that take it too.
+	LockAccountAdvisory(ctx context.Context, tx pgx.Tx, accountID int64) error
+
+	// UpdateBalance updates the balance of an account within a transaction and increments
+	// its version. Returns an error if the update fails or if no rows were affected (account not found).
 	// The database CHECK constraint ensures the balance cannot go negative.
 	UpdateBalance(ctx context.Context, tx pgx.Tx, accountID int64, newBalance decimal.Decimal) error
 
-	// Exists checks if an account with the given ID exists.
+	// UpdateBalanceCAS sets the balance of an account within a transaction only if its
+	// version still equals expectedVersion, incrementing the version. It takes no lock
+	// beforehand, so callers read the account without FOR UPDATE.
+	// Returns ErrConcurrentModification, which is retryable, if the version has moved on.
+	UpdateBalanceCAS(ctx context.Context, tx pgx.Tx, accountID int64, newBalance decimal.Decimal, expectedVersion int) error
+
+	// UpdateLastSequence records sequence as the last accepted transfer sequence for an
+	// account within a transaction. The caller must hold the account's row lock and have
+	// checked that sequence is greater than the current value.
+	// Returns ErrAccountNotFound if the account does not exist.
+	UpdateLastSequence(ctx context.Context, tx pgx.Tx, accountID int64, sequence int64) error
+
+	// Close marks an account closed within a transaction and returns the closing time.
+	// The caller must hold the account's row lock and have checked it is still open.
+	// Returns ErrAccountNotFound if the account does not exist.
+	Close(ctx context.Context, tx pgx.Tx, accountID int64) (time.Time, error)
+
+	// UpdateHeldBalance sets the amount reserved by an account's active holds within a
+	// transaction and increments its version. The caller must hold the account's row lock.
+	// The database CHECK constraint keeps it between zero and the balance.
+	// Returns ErrAccountNotFound if the account does not exist.
+	UpdateHeldBalance(ctx context.Context, tx pgx.Tx, accountID int64, heldBalance decimal.Decimal) error
+
+	// CreateHold inserts an active hold within a transaction. The hold's HoldID, Status,
+	// and CreatedAt fields are populated from the database.
+	CreateHold(ctx context.Context, tx pgx.Tx, hold *models.Hold) error
+
+	// GetHoldForUpdate retrieves a hold with a row-level lock for update, so it can be
+	// resolved only once. Must be called within a transaction.
+	// Returns ErrHoldNotFound if the hold does not exist.
+	GetHoldForUpdate(ctx context.Context, tx pgx.Tx, holdID int64) (*models.Hold, error)
+
+	// ResolveHold marks a hold released or captured within a transaction and returns the
+	// resolution time. transactionID is the capturing withdrawal, or nil for a release.
+	// The caller must hold the hold's row lock and have checked it is still active.
+	// Returns ErrHoldNotFound if the hold does not exist.
+	ResolveHold(ctx context.Context, tx pgx.Tx, holdID int64, status models.HoldStatus, transactionID *int64) (time.Time, error)
+
+	// Exists checks if an account with the given ID exists. Like GetByID, it may be
+	// served by a read replica.
 	// Returns (false, nil) if the account doesn't exist, (true, nil) if it does.
 	Exists(ctx context.Context, accountID int64) (bool, error)
 
-	// BeginTx starts a new database transaction with appropriate isolation level.
+	// ListAfter retrieves up to limit accounts with an ID greater than afterID,
+	// ordered by account ID ascending. Pass afterID = 0 to start from the beginning.
+	//
+	// This is keyset pagination: each page is an index range scan on the primary key,
+	// so memory stays flat and no locks are taken regardless of table size.
+	//
+	// Returns an empty slice once there are no more accounts (not an error).
+	ListAfter(ctx context.Context, afterID int64, limit int) ([]*models.Account, error)
+
+	// List retrieves a page of the accounts matching filter, in its sort order, along
+	// with the total number of matching accounts for pagination. An unknown
+	// filter.SortBy is an error.
+	//
+	// Returns an empty slice past the last page (not an error).
+	List(ctx context.Context, filter models.AccountListFilter) ([]*models.Account, int64, error)
+
+	// VisibleLSN returns the WAL position up to which this repository's reads are guaranteed
+	// to see committed writes. For the primary this is its current WAL position.
+	// Used to decide whether a read-your-writes consistency token has been satisfied.
+	VisibleLSN(ctx context.Context) (consistency.LSN, error)
+
+	// NextAdjustmentBatchID allocates a new, never reused batch ID for a bulk balance
+	// adjustment. Must be called within the transaction that records the batch.
+	NextAdjustmentBatchID(ctx context.Context, tx pgx.Tx) (int64, error)
+
+	// CreateAdjustment inserts a balance adjustment audit record within a transaction.
+	// The adjustment's AdjustmentID and CreatedAt fields are populated from the database.
+	CreateAdjustment(ctx context.Context, tx pgx.Tx, adjustment *models.BalanceAdjustment) error
+
+	// RecordBalanceChange appends an entry to the account's balance history within a
+	// transaction. It must be the transaction that updated the balance, so the history
+	// can never diverge from it. ChangeID and CreatedAt are populated from the database.
+	RecordBalanceChange(ctx context.Context, tx pgx.Tx, change *models.BalanceChange) error
+
+	// ListBalanceHistory retrieves an account's balance changes, newest first.
+	// Returns an empty slice past the end of the history (not an error).
+	ListBalanceHistory(ctx context.Context, accountID int64, limit, offset int) ([]*models.BalanceChange, error)
+
+	// TxIsolationLevel reports the isolation level tx is actually running at, as Postgres
+	// names it (e.g. "read committed"). Intended for diagnostics only.
+	TxIsolationLevel(ctx context.Context, tx pgx.Tx) (string, error)
+
+	// BeginTx starts a new database transaction at the repository's configured isolation level.
 	// The caller is responsible for calling Commit() or Rollback() on the returned transaction.
 	BeginTx(ctx context.Context) (pgx.Tx, error)
 }
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"internal-transfers-system/internal/models"
 
@@ -13,6 +14,7 @@ import (
 type TransactionRepository interface {
 	// Create inserts a new transaction record within a database transaction.
 	// The transaction's TransactionID and CreatedAt fields are populated from the database.
+	// An empty Status is stored as pending.
 	//
 	// This method must be called within an active database transaction (tx).
 	// The caller is responsible for committing or rolling back the transaction.
@@ -21,15 +23,51 @@ type TransactionRepository interface {
 	//   - amount > 0 via CHECK constraint
 	//   - source and destination accounts exist via FOREIGN KEY constraints
 	//   - source != destination via CHECK constraint
+	//   - idempotency_key uniqueness via a partial UNIQUE index
+	//   - reversal_of uniqueness among transactions that didn't fail via a partial UNIQUE index
+	//
+	// Returns ErrDuplicateTransaction if the idempotency key is already taken, or
+	// ErrAlreadyReversed if ReversalOf names a transaction that already has a reversal.
 	Create(ctx context.Context, tx pgx.Tx, transaction *models.Transaction) error
 
+	// CreateLedgerEntries inserts a transaction's double-entry postings within tx, filling
+	// in each entry's EntryID and CreatedAt. Callers write them in the same database
+	// transaction as the transaction they post (see models.Transaction.LedgerEntries).
+	CreateLedgerEntries(ctx context.Context, tx pgx.Tx, entries ...*models.LedgerEntry) error
+
+	// GetLedgerByAccountID retrieves an account's ledger entries with pagination, newest
+	// (highest EntryID) first. Returns an empty slice if there are none (not an error).
+	GetLedgerByAccountID(ctx context.Context, accountID int64, limit, offset int) ([]*models.LedgerEntry, error)
+
+	// GetByIdempotencyKey retrieves the transaction created with the given idempotency key.
+	// Returns ErrTransferNotFound if no transaction uses the key.
+	GetByIdempotencyKey(ctx context.Context, key string) (*models.Transaction, error)
+
 	// GetByID retrieves a transaction by its ID.
 	// Returns ErrTransferNotFound if the transaction does not exist.
 	GetByID(ctx context.Context, transactionID int64) (*models.Transaction, error)
 
+	// GetReversal retrieves the transaction that reverses the given transaction, ignoring
+	// failed reversal attempts. Returns ErrTransferNotFound if the transaction has not
+	// been reversed.
+	GetReversal(ctx context.Context, transactionID int64) (*models.Transaction, error)
+
+	// SinceLastOutboundTransfer returns how long ago the account last sent a transfer
+	// (batch legs included, reversals and failed transfers excluded), by the database
+	// clock, and false if it never has. It reads within tx so that, under the source's row lock, it sees every
+	// transfer committed before the lock was taken.
+	SinceLastOutboundTransfer(ctx context.Context, tx pgx.Tx, accountID int64) (time.Duration, bool, error)
+
+	// DailyOutbound totals the transfers the account has sent today (UTC, by the database
+	// clock; batch legs included, reversals and failed transfers excluded) and reads its
+	// daily limit overrides. Like SinceLastOutboundTransfer it reads within tx. Returns
+	// ErrAccountNotFound if the account doesn't exist.
+	DailyOutbound(ctx context.Context, tx pgx.Tx, accountID int64) (*models.DailyOutbound, error)
+
 	// GetByAccountID retrieves transactions for a given account with pagination.
 	// Returns transactions where the account is either source or destination,
-	// ordered by creation time (newest first).
+	// ordered by creation time (newest first). Transactions sharing a created_at
+	// are ordered by transaction ID (highest first) so pages are stable.
 	//
 	// Parameters:
 	//   - accountID: The account to get transactions for
@@ -38,4 +76,64 @@ type TransactionRepository interface {
 	//
 	// Returns an empty slice if no transactions are found (not an error).
 	GetByAccountID(ctx context.Context, accountID int64, limit, offset int) ([]*models.Transaction, error)
+
+	// GetByAccountIDFiltered is GetByAccountID restricted to the transactions within
+	// filter's bounds, in the same order.
+	//
+	// Returns an empty slice if no transactions match (not an error).
+	GetByAccountIDFiltered(ctx context.Context, accountID int64, filter models.TransactionFilter, limit, offset int) ([]*models.Transaction, error)
+
+	// CountByAccountID counts the account's transactions within filter's bounds (all of
+	// them for the zero filter), the total GetByAccountIDFiltered pages through.
+	CountByAccountID(ctx context.Context, accountID int64, filter models.TransactionFilter) (int64, error)
+
+	// GetByAccountIDAfter retrieves transactions for a given account using keyset pagination.
+	// Returns up to limit transactions positioned strictly before the cursor, in the same
+	// order as GetByAccountID: created_at descending, then transaction ID descending.
+	//
+	// Unlike offset pagination, pages stay stable when new transactions are inserted
+	// mid-scroll: new rows sort ahead of the cursor and never shift older pages.
+	// The transaction ID tiebreaker keeps rows that share a created_at from being
+	// skipped or repeated at page boundaries.
+	//
+	// Returns an empty slice if no transactions are found (not an error).
+	GetByAccountIDAfter(ctx context.Context, accountID int64, before models.TransactionCursor, limit int) ([]*models.Transaction, error)
+
+	// SumByCategory totals an account's transactions per category, split into amounts
+	// debited from (spent) and credited to (received) the account. Only transactions with
+	// an effective date in [from, to] are counted; a zero from or to is unbounded. Failed
+	// transactions moved nothing and are skipped. Uncategorized transactions are grouped
+	// under the empty category.
+	//
+	// Returns one row per category, ordered by category, or an empty slice if none match.
+	SumByCategory(ctx context.Context, accountID int64, from, to time.Time) ([]*models.CategoryTotal, error)
+
+	// CreateRecurring inserts an active recurring transfer, filling in its RecurringID,
+	// Status, and CreatedAt.
+	CreateRecurring(ctx context.Context, recurring *models.RecurringTransfer) error
+
+	// GetRecurring retrieves a recurring transfer by its ID.
+	// Returns ErrRecurringTransferNotFound if it does not exist.
+	GetRecurring(ctx context.Context, recurringID int64) (*models.RecurringTransfer, error)
+
+	// ListDueRecurring returns up to limit active recurring transfers whose NextRunAt is
+	// at or before now, earliest first. Returns an empty slice if none are due.
+	ListDueRecurring(ctx context.Context, now time.Time, limit int) ([]*models.RecurringTransfer, error)
+
+	// AdvanceRecurring stores a run's outcome: recurring's NextRunAt, Status, Runs,
+	// LastRunAt, LastTransactionID, and LastError. It only applies while the stored
+	// schedule is still active and due at slot, so a run that raced a cancellation or
+	// another scheduler doesn't overwrite it; it returns ErrRecurringTransferNotActive then.
+	AdvanceRecurring(ctx context.Context, recurring *models.RecurringTransfer, slot time.Time) error
+
+	// CancelRecurring marks an active recurring transfer canceled and returns it.
+	// Returns ErrRecurringTransferNotFound if it does not exist, or
+	// ErrRecurringTransferNotActive if it already completed or was canceled.
+	CancelRecurring(ctx context.Context, recurringID int64) (*models.RecurringTransfer, error)
+
+	// VerifyLedger totals account balances, initial balances, completed deposits and
+	// withdrawals, and balance adjustments, and lists up to limit accounts whose balance
+	// doesn't match their own history. Everything is read in one read-only snapshot, so
+	// transfers committing meanwhile can't show up as drift.
+	VerifyLedger(ctx context.Context, limit int) (*models.LedgerVerification, error)
 }
@@ -0,0 +1,36 @@
+// Package logging carries a request-scoped zerolog.Logger through context, so service
+// code logs with the fields of the request it is serving (e.g. request_id).
+package logging
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// contextKey is a custom type for context keys to avoid collisions.
+type contextKey struct{}
+
+// RequestIDField is the log field holding the request ID.
+const RequestIDField = "request_id"
+
+// WithLogger returns a copy of ctx carrying logger.
+func WithLogger(ctx context.Context, logger zerolog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, &logger)
+}
+
+// WithRequestID returns a copy of ctx carrying a logger derived from the global logger
+// with the request_id field set.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return WithLogger(ctx, log.Logger.With().Str(RequestIDField, requestID).Logger())
+}
+
+// FromContext returns the logger stored in ctx, or the global logger when there is none
+// (e.g. background work not tied to a request).
+func FromContext(ctx context.Context) *zerolog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*zerolog.Logger); ok {
+		return logger
+	}
+	return &log.Logger
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = orig }()
+
+	FromContext(context.Background()).Info().Msg("no request")
+	FromContext(WithRequestID(context.Background(), "req-1")).Info().Msg("with request")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d", len(lines))
+	}
+
+	var first, second map[string]interface{}
+	json.Unmarshal(lines[0], &first)
+	json.Unmarshal(lines[1], &second)
+
+	if _, ok := first[RequestIDField]; ok {
+		t.Errorf("expected no request_id without a request logger, got %v", first)
+	}
+	if second[RequestIDField] != "req-1" {
+		t.Errorf("expected request_id req-1, got %v", second)
+	}
+}
@@ -0,0 +1,170 @@
+// Package metrics defines the service's instrumentation events and the sinks that
+// export them: Prometheus collectors served at /metrics, a StatsD emitter, or nothing.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"internal-transfers-system/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unknownCode labels failures that are not domain errors.
+const unknownCode = "unknown"
+
+// Reasons a request was aborted before the service produced a result.
+const (
+	// ReasonClientCanceled means the client went away (context.Canceled).
+	ReasonClientCanceled = "client_canceled"
+
+	// ReasonServerTimeout means a server-side deadline expired (context.DeadlineExceeded).
+	ReasonServerTimeout = "server_timeout"
+)
+
+// Recorder receives the service's instrumentation events. *Metrics exports them to
+// Prometheus, *StatsD pushes them to a StatsD agent, and Nop drops them.
+type Recorder interface {
+	TransferAttempted()
+	TransferRetried()
+	TransferCompleted(d time.Duration, err error)
+	ObserveHTTPRequest(path string, status int, d time.Duration)
+	RequestCanceled()
+	RequestTimedOut()
+}
+
+// Nop is a Recorder that discards every event.
+type Nop struct{}
+
+func (Nop) TransferAttempted()                            {}
+func (Nop) TransferRetried()                              {}
+func (Nop) TransferCompleted(time.Duration, error)        {}
+func (Nop) ObserveHTTPRequest(string, int, time.Duration) {}
+func (Nop) RequestCanceled()                              {}
+func (Nop) RequestTimedOut()                              {}
+
+// failureCode returns the label for a failed transfer: its domain error code, or
+// "unknown" for anything else.
+func failureCode(err error) string {
+	if code, ok := models.IsDomainError(err); ok {
+		return string(code)
+	}
+	return unknownCode
+}
+
+// Metrics holds the service's collectors on a dedicated registry, so tests can create
+// independent instances and nothing depends on the global default registry.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	TransferAttempts  prometheus.Counter
+	TransferSuccesses prometheus.Counter
+	TransferFailures  *prometheus.CounterVec
+	TransferRetries   prometheus.Counter
+	TransferDuration  prometheus.Histogram
+
+	HTTPRequestDuration *prometheus.HistogramVec
+	HTTPRequestsAborted *prometheus.CounterVec
+}
+
+// New creates and registers all collectors, plus the standard Go runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		TransferAttempts: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "transfer_attempts_total",
+			Help: "Transfer requests received by the transfer service.",
+		}),
+		TransferSuccesses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "transfer_successes_total",
+			Help: "Transfers that completed, including idempotent replays.",
+		}),
+		TransferFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "transfer_failures_total",
+			Help: "Transfers that failed, by domain error code.",
+		}, []string{"code"}),
+		TransferRetries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "transfer_retries_total",
+			Help: "Transfer attempts retried after a transient error.",
+		}),
+		TransferDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "transfer_duration_seconds",
+			Help:    "End-to-end transfer latency, including retries.",
+			Buckets: prometheus.DefBuckets,
+		}),
+
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by matched route pattern and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"path", "status"}),
+		HTTPRequestsAborted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_aborted_total",
+			Help: "Requests abandoned before completion, by reason (client_canceled or server_timeout).",
+		}, []string{"reason"}),
+	}
+
+	// Export both series from the start so alerts on server_timeout see a zero, not no data
+	m.HTTPRequestsAborted.WithLabelValues(ReasonClientCanceled)
+	m.HTTPRequestsAborted.WithLabelValues(ReasonServerTimeout)
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.TransferAttempts,
+		m.TransferSuccesses,
+		m.TransferFailures,
+		m.TransferRetries,
+		m.TransferDuration,
+		m.HTTPRequestDuration,
+		m.HTTPRequestsAborted,
+	)
+
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// TransferAttempted records an incoming transfer request.
+func (m *Metrics) TransferAttempted() {
+	m.TransferAttempts.Inc()
+}
+
+// TransferRetried records one retry of a transfer attempt.
+func (m *Metrics) TransferRetried() {
+	m.TransferRetries.Inc()
+}
+
+// TransferCompleted records a transfer's outcome and latency. Failures are labelled
+// with the domain error code, or "unknown" for anything else.
+func (m *Metrics) TransferCompleted(d time.Duration, err error) {
+	m.TransferDuration.Observe(d.Seconds())
+	if err == nil {
+		m.TransferSuccesses.Inc()
+		return
+	}
+	m.TransferFailures.WithLabelValues(failureCode(err)).Inc()
+}
+
+// ObserveHTTPRequest records one HTTP request under its route pattern.
+func (m *Metrics) ObserveHTTPRequest(path string, status int, d time.Duration) {
+	m.HTTPRequestDuration.WithLabelValues(path, strconv.Itoa(status)).Observe(d.Seconds())
+}
+
+// RequestCanceled records a request abandoned by the client.
+func (m *Metrics) RequestCanceled() {
+	m.HTTPRequestsAborted.WithLabelValues(ReasonClientCanceled).Inc()
+}
+
+// RequestTimedOut records a request that hit a server-side deadline.
+func (m *Metrics) RequestTimedOut() {
+	m.HTTPRequestsAborted.WithLabelValues(ReasonServerTimeout).Inc()
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTransferCompleted(t *testing.T) {
+	m := New()
+
+	m.TransferCompleted(10*time.Millisecond, nil)
+	m.TransferCompleted(time.Millisecond, models.ErrInsufficientBalance)
+	m.TransferCompleted(time.Millisecond, models.ErrInsufficientBalance)
+	m.TransferCompleted(time.Millisecond, errors.New("boom"))
+
+	if got := testutil.ToFloat64(m.TransferSuccesses); got != 1 {
+		t.Errorf("successes: expected 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.TransferFailures.WithLabelValues("insufficient_balance")); got != 2 {
+		t.Errorf("insufficient_balance failures: expected 2, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.TransferFailures.WithLabelValues("unknown")); got != 1 {
+		t.Errorf("unknown failures: expected 1, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.TransferDuration); got != 1 {
+		t.Errorf("expected one duration histogram, got %d", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.TransferAttempted()
+	m.ObserveHTTPRequest("GET /api/v1/accounts/{id}", http.StatusOK, 5*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"transfer_attempts_total 1",
+		`http_request_duration_seconds_count{path="GET /api/v1/accounts/{id}",status="200"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in metrics output", want)
+		}
+	}
+}
+
+func TestRequestsAborted(t *testing.T) {
+	m := New()
+	m.RequestCanceled()
+	m.RequestCanceled()
+	m.RequestTimedOut()
+
+	if got := testutil.ToFloat64(m.HTTPRequestsAborted.WithLabelValues(ReasonClientCanceled)); got != 2 {
+		t.Errorf("client_canceled: expected 2, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.HTTPRequestsAborted.WithLabelValues(ReasonServerTimeout)); got != 1 {
+		t.Errorf("server_timeout: expected 1, got %v", got)
+	}
+}
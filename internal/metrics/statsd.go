@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatsD pushes the service's events over UDP to a StatsD agent, one packet per event.
+// Metric names mirror the Prometheus ones (transfer.attempts, transfer.failures,
+// http.request.duration, ...) under a configurable prefix. Labels are sent as DogStatsD
+// tags (|#key:value), which Datadog and Telegraf agents understand. Sends are
+// fire-and-forget: a missing agent never slows down or fails a request.
+type StatsD struct {
+	conn   net.Conn
+	prefix string
+}
+
+// NewStatsD returns an emitter sending to addr (host:port). A non-empty prefix is
+// prepended to every metric name with a dot. Only the address is resolved here; nothing
+// needs to be listening yet.
+func NewStatsD(addr, prefix string) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dialing statsd agent %s: %w", addr, err)
+	}
+	if prefix != "" {
+		prefix += "."
+	}
+	return &StatsD{conn: conn, prefix: prefix}, nil
+}
+
+// Close releases the emitter's socket.
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
+
+// TransferAttempted records an incoming transfer request.
+func (s *StatsD) TransferAttempted() {
+	s.send("transfer.attempts", "1|c")
+}
+
+// TransferRetried records one retry of a transfer attempt.
+func (s *StatsD) TransferRetried() {
+	s.send("transfer.retries", "1|c")
+}
+
+// TransferCompleted records a transfer's outcome and latency. Failures are tagged with
+// the domain error code, or "unknown" for anything else.
+func (s *StatsD) TransferCompleted(d time.Duration, err error) {
+	s.send("transfer.duration", millis(d)+"|ms")
+	if err == nil {
+		s.send("transfer.successes", "1|c")
+		return
+	}
+	s.send("transfer.failures", "1|c", "code", failureCode(err))
+}
+
+// ObserveHTTPRequest records one HTTP request's latency under its route pattern.
+func (s *StatsD) ObserveHTTPRequest(path string, status int, d time.Duration) {
+	s.send("http.request.duration", millis(d)+"|ms", "path", path, "status", strconv.Itoa(status))
+}
+
+// RequestCanceled records a request abandoned by the client.
+func (s *StatsD) RequestCanceled() {
+	s.send("http.requests.aborted", "1|c", "reason", ReasonClientCanceled)
+}
+
+// RequestTimedOut records a request that hit a server-side deadline.
+func (s *StatsD) RequestTimedOut() {
+	s.send("http.requests.aborted", "1|c", "reason", ReasonServerTimeout)
+}
+
+// send writes one "name:value|type[|#k:v,...]" line. tags alternates keys and values.
+// Write errors, e.g. no agent listening, are deliberately dropped.
+func (s *StatsD) send(name, value string, tags ...string) {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	b.WriteString(name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	for i := 0; i+1 < len(tags); i += 2 {
+		if i == 0 {
+			b.WriteString("|#")
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(tags[i])
+		b.WriteByte(':')
+		b.WriteString(tagValue(tags[i+1]))
+	}
+	_, _ = s.conn.Write([]byte(b.String()))
+}
+
+// millis formats d in milliseconds, keeping sub-millisecond precision.
+func millis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64)
+}
+
+// tagValue replaces the characters that delimit DogStatsD lines and tags, plus
+// whitespace, so a route pattern like "GET /accounts/{id}" stays one tag.
+func tagValue(v string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '|', ',', '#', ' ', '\t', '\n':
+			return '_'
+		}
+		return r
+	}, v)
+}
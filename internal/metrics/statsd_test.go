@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/models"
+)
+
+func TestStatsD(t *testing.T) {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer agent.Close()
+
+	s, err := NewStatsD(agent.LocalAddr().String(), "transfers")
+	if err != nil {
+		t.Fatalf("NewStatsD: %v", err)
+	}
+	defer s.Close()
+
+	s.TransferAttempted()
+	s.TransferCompleted(1500*time.Microsecond, nil)
+	s.TransferCompleted(time.Millisecond, models.ErrInsufficientBalance)
+	s.TransferCompleted(time.Millisecond, errors.New("boom"))
+	s.ObserveHTTPRequest("GET /api/v1/accounts/{id}", http.StatusOK, 5*time.Millisecond)
+	s.RequestTimedOut()
+
+	want := []string{
+		"transfers.transfer.attempts:1|c",
+		"transfers.transfer.duration:1.5|ms",
+		"transfers.transfer.successes:1|c",
+		"transfers.transfer.duration:1|ms",
+		"transfers.transfer.failures:1|c|#code:insufficient_balance",
+		"transfers.transfer.duration:1|ms",
+		"transfers.transfer.failures:1|c|#code:unknown",
+		"transfers.http.request.duration:5|ms|#path:GET_/api/v1/accounts/{id},status:200",
+		"transfers.http.requests.aborted:1|c|#reason:server_timeout",
+	}
+	buf := make([]byte, 512)
+	for i, w := range want {
+		_ = agent.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := agent.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("packet %d: %v", i, err)
+		}
+		if got := string(buf[:n]); got != w {
+			t.Errorf("packet %d: expected %q, got %q", i, w, got)
+		}
+	}
+}
+
+func TestStatsD_NoAgent(t *testing.T) {
+	// Nothing listens here; sends must neither block nor panic
+	s, err := NewStatsD("127.0.0.1:1", "")
+	if err != nil {
+		t.Fatalf("NewStatsD: %v", err)
+	}
+	defer s.Close()
+
+	for i := 0; i < 3; i++ {
+		s.TransferAttempted()
+		s.RequestCanceled()
+	}
+}
@@ -2,8 +2,11 @@ package mocks
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
+	"internal-transfers-system/internal/consistency"
 	"internal-transfers-system/internal/models"
 
 	"github.com/jackc/pgx/v5"
@@ -12,21 +15,39 @@ import (
 )
 
 type MockAccountRepository struct {
-	mu       sync.RWMutex
-	accounts map[int64]*models.Account
+	mu          sync.RWMutex
+	accounts    map[int64]*models.Account
+	holds       map[int64]*models.Hold
+	adjustments []*models.BalanceAdjustment
+	history     []*models.BalanceChange
+	locked      []int64
+	lastBatchID int64
+	lastGenID   int64
 
 	CreateError           error
 	GetByIDError          error
 	GetByIDForUpdateError error
 	UpdateBalanceError    error
+	UpdateBalanceCASError error
+	UpdateSequenceError   error
+	CloseError            error
+	HoldError             error
 	ExistsError           error
+	ListAfterError        error
+	ListError             error
 	BeginTxError          error
+	CreateAdjustmentError error
+	RecordChangeError     error
+
+	// CommitErrors are handed out one per BeginTx call; the returned MockTx fails Commit with it.
+	CommitErrors []error
 
 	OnGetByIDForUpdate func(ctx context.Context, tx interface{}, accountID int64) (*models.Account, error)
+	OnVisibleLSN       func(ctx context.Context) (consistency.LSN, error)
 }
 
 func NewMockAccountRepository() *MockAccountRepository {
-	return &MockAccountRepository{accounts: make(map[int64]*models.Account)}
+	return &MockAccountRepository{accounts: make(map[int64]*models.Account), holds: make(map[int64]*models.Hold)}
 }
 
 func (m *MockAccountRepository) Create(ctx context.Context, account *models.Account) error {
@@ -38,13 +59,85 @@ func (m *MockAccountRepository) Create(ctx context.Context, account *models.Acco
 	if _, exists := m.accounts[account.AccountID]; exists {
 		return models.ErrAccountAlreadyExists
 	}
+	if account.AccountType == "" {
+		account.AccountType = models.DefaultAccountType
+	}
+	m.accounts[account.AccountID] = &models.Account{
+		AccountID:      account.AccountID,
+		AccountType:    account.AccountType,
+		Balance:        account.Balance,
+		InitialBalance: account.Balance,
+		MaxBalance:     account.MaxBalance,
+		OverdraftLimit: account.OverdraftLimit,
+	}
+	return nil
+}
+
+// CreateWithGeneratedID assigns the next ID not already in use, counting up from 1.
+func (m *MockAccountRepository) CreateWithGeneratedID(ctx context.Context, account *models.Account) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	for {
+		m.lastGenID++
+		if _, exists := m.accounts[m.lastGenID]; !exists {
+			break
+		}
+	}
+	account.AccountID = m.lastGenID
+	if account.AccountType == "" {
+		account.AccountType = models.DefaultAccountType
+	}
 	m.accounts[account.AccountID] = &models.Account{
-		AccountID: account.AccountID,
-		Balance:   account.Balance,
+		AccountID:      account.AccountID,
+		AccountType:    account.AccountType,
+		Balance:        account.Balance,
+		InitialBalance: account.Balance,
+		MaxBalance:     account.MaxBalance,
+		OverdraftLimit: account.OverdraftLimit,
 	}
 	return nil
 }
 
+// CreateBatch skips accounts whose ID is taken and assigns generated IDs as
+// CreateWithGeneratedID does. Accounts created before a rollback are kept.
+func (m *MockAccountRepository) CreateBatch(ctx context.Context, tx pgx.Tx, accounts []*models.Account) ([]bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.CreateError != nil {
+		return nil, m.CreateError
+	}
+	created := make([]bool, len(accounts))
+	for i, account := range accounts {
+		if account.AccountID == 0 {
+			for {
+				m.lastGenID++
+				if _, exists := m.accounts[m.lastGenID]; !exists {
+					break
+				}
+			}
+			account.AccountID = m.lastGenID
+		} else if _, exists := m.accounts[account.AccountID]; exists {
+			continue
+		}
+		if account.AccountType == "" {
+			account.AccountType = models.DefaultAccountType
+		}
+		m.accounts[account.AccountID] = &models.Account{
+			AccountID:      account.AccountID,
+			AccountType:    account.AccountType,
+			Balance:        account.Balance,
+			InitialBalance: account.Balance,
+			MaxBalance:     account.MaxBalance,
+			OverdraftLimit: account.OverdraftLimit,
+		}
+		created[i] = true
+	}
+	return created, nil
+}
+
 func (m *MockAccountRepository) GetByID(ctx context.Context, id int64) (*models.Account, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -55,7 +148,39 @@ func (m *MockAccountRepository) GetByID(ctx context.Context, id int64) (*models.
 	if !exists {
 		return nil, models.ErrAccountNotFound
 	}
-	return &models.Account{AccountID: acc.AccountID, Balance: acc.Balance}, nil
+	return &models.Account{AccountID: acc.AccountID, AccountType: acc.AccountType, Balance: acc.Balance, InitialBalance: acc.InitialBalance, HeldBalance: acc.HeldBalance, OverdraftLimit: acc.OverdraftLimit, MaxBalance: acc.MaxBalance, LastSequence: acc.LastSequence, Version: acc.Version, ClosedAt: acc.ClosedAt}, nil
+}
+
+func (m *MockAccountRepository) GetByIDs(ctx context.Context, ids []int64) ([]*models.Account, error) {
+	if m.GetByIDError != nil {
+		return nil, m.GetByIDError
+	}
+	var result []*models.Account
+	for _, id := range ids {
+		if acc, err := m.GetByID(ctx, id); err == nil {
+			result = append(result, acc)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].AccountID < result[j].AccountID })
+	return result, nil
+}
+
+func (m *MockAccountRepository) GetByIDInTx(ctx context.Context, tx pgx.Tx, id int64) (*models.Account, error) {
+	return m.GetByID(ctx, id)
+}
+
+// LockAccountAdvisory records accountID; AdvisoryLocks returns the IDs in locking order.
+func (m *MockAccountRepository) LockAccountAdvisory(ctx context.Context, tx pgx.Tx, accountID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locked = append(m.locked, accountID)
+	return nil
+}
+
+func (m *MockAccountRepository) AdvisoryLocks() []int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]int64(nil), m.locked...)
 }
 
 func (m *MockAccountRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, id int64) (*models.Account, error) {
@@ -71,7 +196,7 @@ func (m *MockAccountRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx,
 	if !exists {
 		return nil, models.ErrAccountNotFound
 	}
-	return &models.Account{AccountID: acc.AccountID, Balance: acc.Balance}, nil
+	return &models.Account{AccountID: acc.AccountID, AccountType: acc.AccountType, Balance: acc.Balance, InitialBalance: acc.InitialBalance, HeldBalance: acc.HeldBalance, OverdraftLimit: acc.OverdraftLimit, MaxBalance: acc.MaxBalance, LastSequence: acc.LastSequence, Version: acc.Version, ClosedAt: acc.ClosedAt}, nil
 }
 
 func (m *MockAccountRepository) UpdateBalance(ctx context.Context, tx pgx.Tx, id int64, balance decimal.Decimal) error {
@@ -85,9 +210,120 @@ func (m *MockAccountRepository) UpdateBalance(ctx context.Context, tx pgx.Tx, id
 		return models.ErrAccountNotFound
 	}
 	acc.Balance = balance
+	acc.Version++
+	return nil
+}
+
+func (m *MockAccountRepository) UpdateBalanceCAS(ctx context.Context, tx pgx.Tx, id int64, balance decimal.Decimal, expectedVersion int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.UpdateBalanceCASError != nil {
+		return m.UpdateBalanceCASError
+	}
+	acc, exists := m.accounts[id]
+	if !exists || acc.Version != expectedVersion {
+		return models.ErrConcurrentModification
+	}
+	acc.Balance = balance
+	acc.Version++
+	return nil
+}
+
+func (m *MockAccountRepository) UpdateLastSequence(ctx context.Context, tx pgx.Tx, id int64, sequence int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.UpdateSequenceError != nil {
+		return m.UpdateSequenceError
+	}
+	acc, exists := m.accounts[id]
+	if !exists {
+		return models.ErrAccountNotFound
+	}
+	acc.LastSequence = sequence
+	return nil
+}
+
+func (m *MockAccountRepository) Close(ctx context.Context, tx pgx.Tx, id int64) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.CloseError != nil {
+		return time.Time{}, m.CloseError
+	}
+	acc, exists := m.accounts[id]
+	if !exists {
+		return time.Time{}, models.ErrAccountNotFound
+	}
+	closedAt := time.Now()
+	acc.ClosedAt = &closedAt
+	acc.Version++
+	return closedAt, nil
+}
+
+func (m *MockAccountRepository) UpdateHeldBalance(ctx context.Context, tx pgx.Tx, id int64, heldBalance decimal.Decimal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.HoldError != nil {
+		return m.HoldError
+	}
+	acc, exists := m.accounts[id]
+	if !exists {
+		return models.ErrAccountNotFound
+	}
+	acc.HeldBalance = heldBalance
+	acc.Version++
+	return nil
+}
+
+func (m *MockAccountRepository) CreateHold(ctx context.Context, tx pgx.Tx, hold *models.Hold) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.HoldError != nil {
+		return m.HoldError
+	}
+	hold.HoldID = int64(len(m.holds) + 1)
+	hold.Status = models.HoldStatusActive
+	hold.CreatedAt = time.Now()
+	stored := *hold
+	m.holds[hold.HoldID] = &stored
 	return nil
 }
 
+func (m *MockAccountRepository) GetHoldForUpdate(ctx context.Context, tx pgx.Tx, holdID int64) (*models.Hold, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hold, exists := m.holds[holdID]
+	if !exists {
+		return nil, models.ErrHoldNotFound
+	}
+	copied := *hold
+	return &copied, nil
+}
+
+func (m *MockAccountRepository) ResolveHold(ctx context.Context, tx pgx.Tx, holdID int64, status models.HoldStatus, transactionID *int64) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.HoldError != nil {
+		return time.Time{}, m.HoldError
+	}
+	hold, exists := m.holds[holdID]
+	if !exists {
+		return time.Time{}, models.ErrHoldNotFound
+	}
+	resolvedAt := time.Now()
+	hold.Status = status
+	hold.TransactionID = transactionID
+	hold.ResolvedAt = &resolvedAt
+	return resolvedAt, nil
+}
+
+// GetHold returns the stored hold, for assertions.
+func (m *MockAccountRepository) GetHold(id int64) (*models.Hold, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	hold, exists := m.holds[id]
+	return hold, exists
+}
+
 func (m *MockAccountRepository) Exists(ctx context.Context, id int64) (bool, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -98,11 +334,156 @@ func (m *MockAccountRepository) Exists(ctx context.Context, id int64) (bool, err
 	return exists, nil
 }
 
+func (m *MockAccountRepository) ListAfter(ctx context.Context, afterID int64, limit int) ([]*models.Account, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.ListAfterError != nil {
+		return nil, m.ListAfterError
+	}
+	var result []*models.Account
+	for id, acc := range m.accounts {
+		if id > afterID {
+			result = append(result, &models.Account{AccountID: acc.AccountID, AccountType: acc.AccountType, Balance: acc.Balance, MaxBalance: acc.MaxBalance})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].AccountID < result[j].AccountID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockAccountRepository) List(ctx context.Context, filter models.AccountListFilter) ([]*models.Account, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.ListError != nil {
+		return nil, 0, m.ListError
+	}
+	var result []*models.Account
+	for _, acc := range m.accounts {
+		if filter.MinBalance.Valid && acc.Balance.LessThan(filter.MinBalance.Decimal) ||
+			filter.MaxBalance.Valid && acc.Balance.GreaterThan(filter.MaxBalance.Decimal) {
+			continue
+		}
+		copied := *acc
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if filter.Descending {
+			a, b = b, a
+		}
+		switch filter.SortBy {
+		case models.AccountSortByBalance:
+			if c := a.Balance.Cmp(b.Balance); c != 0 {
+				return c < 0
+			}
+		case models.AccountSortByCreatedAt:
+			if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+				return c < 0
+			}
+		}
+		return a.AccountID < b.AccountID
+	})
+	total := int64(len(result))
+	if filter.Offset >= len(result) {
+		return []*models.Account{}, total, nil
+	}
+	result = result[filter.Offset:]
+	if len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, total, nil
+}
+
+func (m *MockAccountRepository) VisibleLSN(ctx context.Context) (consistency.LSN, error) {
+	if m.OnVisibleLSN != nil {
+		return m.OnVisibleLSN(ctx)
+	}
+	return 0, nil
+}
+
+func (m *MockAccountRepository) NextAdjustmentBatchID(ctx context.Context, tx pgx.Tx) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastBatchID++
+	return m.lastBatchID, nil
+}
+
+func (m *MockAccountRepository) CreateAdjustment(ctx context.Context, tx pgx.Tx, adjustment *models.BalanceAdjustment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.CreateAdjustmentError != nil {
+		return m.CreateAdjustmentError
+	}
+	adjustment.AdjustmentID = int64(len(m.adjustments) + 1)
+	adjustment.CreatedAt = time.Now()
+	stored := *adjustment
+	m.adjustments = append(m.adjustments, &stored)
+	return nil
+}
+
+// Adjustments returns every adjustment recorded so far, in insertion order.
+func (m *MockAccountRepository) Adjustments() []*models.BalanceAdjustment {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*models.BalanceAdjustment(nil), m.adjustments...)
+}
+
+func (m *MockAccountRepository) RecordBalanceChange(ctx context.Context, tx pgx.Tx, change *models.BalanceChange) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.RecordChangeError != nil {
+		return m.RecordChangeError
+	}
+	change.ChangeID = int64(len(m.history) + 1)
+	change.CreatedAt = time.Now()
+	stored := *change
+	m.history = append(m.history, &stored)
+	return nil
+}
+
+func (m *MockAccountRepository) ListBalanceHistory(ctx context.Context, accountID int64, limit, offset int) ([]*models.BalanceChange, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var changes []*models.BalanceChange
+	for i := len(m.history) - 1; i >= 0; i-- {
+		if m.history[i].AccountID == accountID {
+			changes = append(changes, m.history[i])
+		}
+	}
+	if offset >= len(changes) {
+		return []*models.BalanceChange{}, nil
+	}
+	changes = changes[offset:]
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
+
+// BalanceHistory returns every balance change recorded so far, in insertion order.
+func (m *MockAccountRepository) BalanceHistory() []*models.BalanceChange {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*models.BalanceChange(nil), m.history...)
+}
+
+func (m *MockAccountRepository) TxIsolationLevel(ctx context.Context, tx pgx.Tx) (string, error) {
+	return "read committed", nil
+}
+
 func (m *MockAccountRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
 	if m.BeginTxError != nil {
 		return nil, m.BeginTxError
 	}
-	return &MockTx{}, nil
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tx := &MockTx{}
+	if len(m.CommitErrors) > 0 {
+		tx.CommitError, m.CommitErrors = m.CommitErrors[0], m.CommitErrors[1:]
+	}
+	return tx, nil
 }
 
 func (m *MockAccountRepository) SetAccount(acc *models.Account) {
@@ -123,10 +504,12 @@ func (m *MockAccountRepository) GetAccountUnsafe(id int64) (*models.Account, boo
 	return acc, exists
 }
 
-type MockTx struct{}
+type MockTx struct {
+	CommitError error
+}
 
 func (m *MockTx) Begin(ctx context.Context) (pgx.Tx, error)         { return &MockTx{}, nil }
-func (m *MockTx) Commit(ctx context.Context) error                  { return nil }
+func (m *MockTx) Commit(ctx context.Context) error                  { return m.CommitError }
 func (m *MockTx) Rollback(ctx context.Context) error                { return nil }
 func (m *MockTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
 	return 0, nil
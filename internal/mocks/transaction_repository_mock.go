@@ -2,8 +2,10 @@ package mocks
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"internal-transfers-system/internal/models"
 
@@ -14,14 +16,26 @@ type MockTransactionRepository struct {
 	mu           sync.RWMutex
 	transactions map[int64]*models.Transaction
 	nextID       atomic.Int64
+	ledger       []*models.LedgerEntry
+	recurring    map[int64]*models.RecurringTransfer
 
-	CreateError         error
-	GetByIDError        error
-	GetByAccountIDError error
+	CreateError              error
+	GetByIDError             error
+	GetByIdempotencyKeyError error
+	GetByAccountIDError      error
+	RecurringError           error
+
+	// DailyLimits holds per-account daily limit overrides, as the accounts table's
+	// daily_transfer_* columns would; only AmountLimit and CountLimit are read.
+	DailyLimits map[int64]models.DailyOutbound
+
+	// Verification is what VerifyLedger returns; nil reports an empty, consistent ledger.
+	Verification      *models.LedgerVerification
+	VerifyLedgerError error
 }
 
 func NewMockTransactionRepository() *MockTransactionRepository {
-	m := &MockTransactionRepository{transactions: make(map[int64]*models.Transaction)}
+	m := &MockTransactionRepository{transactions: make(map[int64]*models.Transaction), recurring: make(map[int64]*models.RecurringTransfer)}
 	m.nextID.Store(1)
 	return m
 }
@@ -32,16 +46,153 @@ func (m *MockTransactionRepository) Create(ctx context.Context, tx pgx.Tx, txn *
 	if m.CreateError != nil {
 		return m.CreateError
 	}
+	if txn.IdempotencyKey != "" {
+		for _, existing := range m.transactions {
+			if existing.IdempotencyKey == txn.IdempotencyKey {
+				return models.ErrDuplicateTransaction
+			}
+		}
+	}
+	if txn.Status == "" {
+		txn.Status = models.TransactionStatusPending
+	}
+	if txn.ReversalOf != nil && txn.Status != models.TransactionStatusFailed {
+		for _, existing := range m.transactions {
+			if existing.ReversalOf != nil && *existing.ReversalOf == *txn.ReversalOf && existing.Status != models.TransactionStatusFailed {
+				return models.ErrAlreadyReversed
+			}
+		}
+	}
 	txn.TransactionID = m.nextID.Add(1) - 1
+	if txn.Type == "" {
+		txn.Type = models.TransactionTypeTransfer
+	}
+	if txn.EffectiveDate.IsZero() {
+		txn.EffectiveDate = time.Now().UTC().Truncate(24 * time.Hour)
+	}
 	m.transactions[txn.TransactionID] = &models.Transaction{
 		TransactionID:        txn.TransactionID,
+		Type:                 txn.Type,
 		SourceAccountID:      txn.SourceAccountID,
 		DestinationAccountID: txn.DestinationAccountID,
 		Amount:               txn.Amount,
+		EffectiveDate:        txn.EffectiveDate,
+		IdempotencyKey:       txn.IdempotencyKey,
+		ReversalOf:           txn.ReversalOf,
+		Category:             txn.Category,
+		Status:               txn.Status,
+		FeeAmount:            txn.FeeAmount,
+		FeeAccountID:         txn.FeeAccountID,
+		FeePaidBy:            txn.FeePaidBy,
+		CreatedAt:            time.Now(),
+	}
+	return nil
+}
+
+func (m *MockTransactionRepository) CreateLedgerEntries(ctx context.Context, tx pgx.Tx, entries ...*models.LedgerEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	for _, entry := range entries {
+		entry.EntryID = int64(len(m.ledger)) + 1
+		entry.CreatedAt = time.Now()
+		copied := *entry
+		m.ledger = append(m.ledger, &copied)
 	}
 	return nil
 }
 
+func (m *MockTransactionRepository) GetLedgerByAccountID(ctx context.Context, accountID int64, limit, offset int) ([]*models.LedgerEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.GetByAccountIDError != nil {
+		return nil, m.GetByAccountIDError
+	}
+	result := []*models.LedgerEntry{}
+	for i := len(m.ledger) - 1; i >= 0; i-- {
+		if m.ledger[i].AccountID == accountID {
+			result = append(result, m.ledger[i])
+		}
+	}
+	if offset >= len(result) {
+		return []*models.LedgerEntry{}, nil
+	}
+	end := offset + limit
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end], nil
+}
+
+// LedgerEntries returns every ledger entry written so far, in insertion order.
+func (m *MockTransactionRepository) LedgerEntries() []*models.LedgerEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*models.LedgerEntry(nil), m.ledger...)
+}
+
+func (m *MockTransactionRepository) DailyOutbound(ctx context.Context, tx pgx.Tx, accountID int64) (*models.DailyOutbound, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	out := &models.DailyOutbound{ResetsAt: start.Add(24 * time.Hour)}
+	if limits, ok := m.DailyLimits[accountID]; ok {
+		out.AmountLimit, out.CountLimit = limits.AmountLimit, limits.CountLimit
+	}
+	for _, txn := range m.transactions {
+		if txn.SourceAccountID == accountID && txn.Type == models.TransactionTypeTransfer && txn.ReversalOf == nil && txn.Status != models.TransactionStatusFailed && !txn.CreatedAt.Before(start) {
+			out.Amount = out.Amount.Add(txn.Amount)
+			out.Count++
+		}
+	}
+	return out, nil
+}
+
+func (m *MockTransactionRepository) SinceLastOutboundTransfer(ctx context.Context, tx pgx.Tx, accountID int64) (time.Duration, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var last time.Time
+	for _, txn := range m.transactions {
+		if txn.SourceAccountID == accountID && txn.Type == models.TransactionTypeTransfer && txn.ReversalOf == nil && txn.Status != models.TransactionStatusFailed && txn.CreatedAt.After(last) {
+			last = txn.CreatedAt
+		}
+	}
+	if last.IsZero() {
+		return 0, false, nil
+	}
+	return time.Since(last), true, nil
+}
+
+func (m *MockTransactionRepository) GetByIdempotencyKey(ctx context.Context, key string) (*models.Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.GetByIdempotencyKeyError != nil {
+		return nil, m.GetByIdempotencyKeyError
+	}
+	for _, txn := range m.transactions {
+		if txn.IdempotencyKey == key {
+			return &models.Transaction{
+				TransactionID:        txn.TransactionID,
+				Type:                 txn.Type,
+				SourceAccountID:      txn.SourceAccountID,
+				DestinationAccountID: txn.DestinationAccountID,
+				Amount:               txn.Amount,
+				EffectiveDate:        txn.EffectiveDate,
+				IdempotencyKey:       txn.IdempotencyKey,
+				ReversalOf:           txn.ReversalOf,
+				Category:             txn.Category,
+				Status:               txn.Status,
+				FeeAmount:            txn.FeeAmount,
+				FeeAccountID:         txn.FeeAccountID,
+				FeePaidBy:            txn.FeePaidBy,
+			}, nil
+		}
+	}
+	return nil, models.ErrTransferNotFound
+}
+
 func (m *MockTransactionRepository) GetByID(ctx context.Context, id int64) (*models.Transaction, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -54,12 +205,35 @@ func (m *MockTransactionRepository) GetByID(ctx context.Context, id int64) (*mod
 	}
 	return &models.Transaction{
 		TransactionID:        txn.TransactionID,
+		Type:                 txn.Type,
 		SourceAccountID:      txn.SourceAccountID,
 		DestinationAccountID: txn.DestinationAccountID,
 		Amount:               txn.Amount,
+		EffectiveDate:        txn.EffectiveDate,
+		ReversalOf:           txn.ReversalOf,
+		Category:             txn.Category,
+		Status:               txn.Status,
+		FeeAmount:            txn.FeeAmount,
+		FeeAccountID:         txn.FeeAccountID,
+		FeePaidBy:            txn.FeePaidBy,
 	}, nil
 }
 
+func (m *MockTransactionRepository) GetReversal(ctx context.Context, transactionID int64) (*models.Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.GetByIDError != nil {
+		return nil, m.GetByIDError
+	}
+	for _, txn := range m.transactions {
+		if txn.ReversalOf != nil && *txn.ReversalOf == transactionID && txn.Status != models.TransactionStatusFailed {
+			copied := *txn
+			return &copied, nil
+		}
+	}
+	return nil, models.ErrTransferNotFound
+}
+
 func (m *MockTransactionRepository) GetByAccountID(ctx context.Context, accountID int64, limit, offset int) ([]*models.Transaction, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -72,6 +246,30 @@ func (m *MockTransactionRepository) GetByAccountID(ctx context.Context, accountI
 			result = append(result, txn)
 		}
 	}
+	sortNewestFirst(result)
+	if offset >= len(result) {
+		return []*models.Transaction{}, nil
+	}
+	end := offset + limit
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end], nil
+}
+
+func (m *MockTransactionRepository) GetByAccountIDFiltered(ctx context.Context, accountID int64, filter models.TransactionFilter, limit, offset int) ([]*models.Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.GetByAccountIDError != nil {
+		return nil, m.GetByAccountIDError
+	}
+	var result []*models.Transaction
+	for _, txn := range m.transactions {
+		if (txn.SourceAccountID == accountID || txn.DestinationAccountID == accountID) && filter.Matches(txn) {
+			result = append(result, txn)
+		}
+	}
+	sortNewestFirst(result)
 	if offset >= len(result) {
 		return []*models.Transaction{}, nil
 	}
@@ -82,8 +280,200 @@ func (m *MockTransactionRepository) GetByAccountID(ctx context.Context, accountI
 	return result[offset:end], nil
 }
 
+func (m *MockTransactionRepository) CountByAccountID(ctx context.Context, accountID int64, filter models.TransactionFilter) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.GetByAccountIDError != nil {
+		return 0, m.GetByAccountIDError
+	}
+	var total int64
+	for _, txn := range m.transactions {
+		if (txn.SourceAccountID == accountID || txn.DestinationAccountID == accountID) && filter.Matches(txn) {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (m *MockTransactionRepository) GetByAccountIDAfter(ctx context.Context, accountID int64, before models.TransactionCursor, limit int) ([]*models.Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.GetByAccountIDError != nil {
+		return nil, m.GetByAccountIDError
+	}
+	var result []*models.Transaction
+	for _, txn := range m.transactions {
+		if (txn.SourceAccountID == accountID || txn.DestinationAccountID == accountID) && models.CursorAfter(txn).Less(before) {
+			result = append(result, txn)
+		}
+	}
+	sortNewestFirst(result)
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockTransactionRepository) SumByCategory(ctx context.Context, accountID int64, from, to time.Time) ([]*models.CategoryTotal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.GetByAccountIDError != nil {
+		return nil, m.GetByAccountIDError
+	}
+	byCategory := make(map[string]*models.CategoryTotal)
+	for _, txn := range m.transactions {
+		if (txn.SourceAccountID != accountID && txn.DestinationAccountID != accountID) || txn.Status == models.TransactionStatusFailed {
+			continue
+		}
+		if (!from.IsZero() && txn.EffectiveDate.Before(from)) || (!to.IsZero() && txn.EffectiveDate.After(to)) {
+			continue
+		}
+		total, ok := byCategory[txn.Category]
+		if !ok {
+			total = &models.CategoryTotal{Category: txn.Category}
+			byCategory[txn.Category] = total
+		}
+		if txn.SourceAccountID == accountID {
+			total.Spent = total.Spent.Add(txn.Amount)
+		} else {
+			total.Received = total.Received.Add(txn.Amount)
+		}
+		total.Count++
+	}
+	result := make([]*models.CategoryTotal, 0, len(byCategory))
+	for _, total := range byCategory {
+		result = append(result, total)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Category < result[j].Category })
+	return result, nil
+}
+
+func (m *MockTransactionRepository) CreateRecurring(ctx context.Context, recurring *models.RecurringTransfer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.RecurringError != nil {
+		return m.RecurringError
+	}
+	recurring.RecurringID = int64(len(m.recurring) + 1)
+	recurring.Status = models.RecurringStatusActive
+	recurring.CreatedAt = time.Now()
+	stored := *recurring
+	m.recurring[recurring.RecurringID] = &stored
+	return nil
+}
+
+func (m *MockTransactionRepository) GetRecurring(ctx context.Context, recurringID int64) (*models.RecurringTransfer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.RecurringError != nil {
+		return nil, m.RecurringError
+	}
+	recurring, ok := m.recurring[recurringID]
+	if !ok {
+		return nil, models.ErrRecurringTransferNotFound
+	}
+	copied := *recurring
+	return &copied, nil
+}
+
+func (m *MockTransactionRepository) ListDueRecurring(ctx context.Context, now time.Time, limit int) ([]*models.RecurringTransfer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.RecurringError != nil {
+		return nil, m.RecurringError
+	}
+	due := make([]*models.RecurringTransfer, 0)
+	for _, recurring := range m.recurring {
+		if recurring.Status == models.RecurringStatusActive && !recurring.NextRunAt.After(now) {
+			copied := *recurring
+			due = append(due, &copied)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextRunAt.Equal(due[j].NextRunAt) {
+			return due[i].NextRunAt.Before(due[j].NextRunAt)
+		}
+		return due[i].RecurringID < due[j].RecurringID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (m *MockTransactionRepository) AdvanceRecurring(ctx context.Context, recurring *models.RecurringTransfer, slot time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.RecurringError != nil {
+		return m.RecurringError
+	}
+	stored, ok := m.recurring[recurring.RecurringID]
+	if !ok || stored.Status != models.RecurringStatusActive || !stored.NextRunAt.Equal(slot) {
+		return models.ErrRecurringTransferNotActive
+	}
+	stored.NextRunAt = recurring.NextRunAt
+	stored.Status = recurring.Status
+	stored.Runs = recurring.Runs
+	stored.LastRunAt = recurring.LastRunAt
+	stored.LastTransactionID = recurring.LastTransactionID
+	stored.LastError = recurring.LastError
+	return nil
+}
+
+func (m *MockTransactionRepository) CancelRecurring(ctx context.Context, recurringID int64) (*models.RecurringTransfer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.RecurringError != nil {
+		return nil, m.RecurringError
+	}
+	stored, ok := m.recurring[recurringID]
+	if !ok {
+		return nil, models.ErrRecurringTransferNotFound
+	}
+	if stored.Status != models.RecurringStatusActive {
+		return nil, models.ErrRecurringTransferNotActive
+	}
+	now := time.Now()
+	stored.Status = models.RecurringStatusCanceled
+	stored.CanceledAt = &now
+	copied := *stored
+	return &copied, nil
+}
+
+// sortNewestFirst orders txns by created_at then transaction ID, both descending,
+// matching the repository's ORDER BY.
+func sortNewestFirst(txns []*models.Transaction) {
+	sort.Slice(txns, func(i, j int) bool {
+		return models.CursorAfter(txns[j]).Less(models.CursorAfter(txns[i]))
+	})
+}
+
+// SetTransaction stores txn as-is, defaulting an empty Type to transfer and an empty
+// Status to completed, as for rows written before statuses existed.
 func (m *MockTransactionRepository) SetTransaction(txn *models.Transaction) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if txn.Type == "" {
+		txn.Type = models.TransactionTypeTransfer
+	}
+	if txn.Status == "" {
+		txn.Status = models.TransactionStatusCompleted
+	}
 	m.transactions[txn.TransactionID] = txn
 }
+
+func (m *MockTransactionRepository) VerifyLedger(ctx context.Context, limit int) (*models.LedgerVerification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.VerifyLedgerError != nil {
+		return nil, m.VerifyLedgerError
+	}
+	if m.Verification == nil {
+		return &models.LedgerVerification{DriftedAccounts: []*models.AccountDrift{}}, nil
+	}
+	v := *m.Verification
+	if len(v.DriftedAccounts) > limit {
+		v.DriftedAccounts = v.DriftedAccounts[:limit]
+	}
+	return &v, nil
+}
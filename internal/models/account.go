@@ -9,28 +9,99 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// DefaultAccountType is the type given to accounts created without one.
+const DefaultAccountType = "standard"
+
+// Account statuses reported by GetAccountResponse.Status.
+const (
+	AccountStatusOpen   = "open"
+	AccountStatusClosed = "closed"
+)
+
 // Account represents a bank account in the system.
 //
 // Business rules:
-//   - AccountID is provided by the client and must be unique
-//   - Balance cannot be negative (enforced at database level)
+//   - AccountID is provided by the client or generated, and must be unique
+//   - Balance cannot go below -OverdraftLimit (enforced at database level)
+//   - HeldBalance is between zero and Balance + OverdraftLimit (enforced at database
+//     level); debits other than hold captures may only spend the available balance
+//   - Balance cannot be credited above MaxBalance, when set
+//   - Transfer sequence numbers out of the account must strictly increase
+//   - Closed accounts are kept but can't send or receive funds
 //   - All monetary operations use decimal.Decimal for precision
 //
 // Uses db tags for go-kit/pgx reflection-based CRUD operations.
 type Account struct {
 	// AccountID is the unique identifier for the account.
-	// This is provided by the client during account creation.
+	// It is provided by the client during account creation, or generated when omitted.
 	AccountID int64 `db:"account_id" id:"true" json:"account_id"`
 
+	// AccountType is a free-form category (e.g. "standard", "escrow") that balance
+	// policies are configured against. Defaults to DefaultAccountType.
+	AccountType string `db:"account_type" json:"account_type"`
+
 	// Balance is the current balance of the account.
 	// Uses decimal.Decimal for precise monetary calculations.
 	Balance decimal.Decimal `db:"balance" json:"balance"`
 
+	// InitialBalance is the balance the account was opened with. It never changes.
+	InitialBalance decimal.Decimal `db:"initial_balance" json:"initial_balance"`
+
+	// MaxBalance is an optional ceiling on the balance (e.g. a regulatory wallet cap).
+	// Credits that would push the balance above it are rejected. Null means no ceiling.
+	MaxBalance decimal.NullDecimal `db:"max_balance" json:"max_balance"`
+
+	// OverdraftLimit is how far below zero the balance may go. Zero, the default, means
+	// the account can't go negative.
+	OverdraftLimit decimal.Decimal `db:"overdraft_limit" json:"overdraft_limit"`
+
+	// HeldBalance is the total reserved by the account's active holds. Reserved funds
+	// stay in Balance until the hold is captured or released.
+	HeldBalance decimal.Decimal `db:"held_balance" json:"held_balance"`
+
+	// LastSequence is the highest client sequence number accepted for a transfer out of
+	// this account, or 0 if the client has never sent one.
+	LastSequence int64 `db:"last_sequence" json:"-"`
+
+	// Version is incremented on every balance update. Optimistic transfers only write
+	// the balance if the version is still the one they read.
+	Version int `db:"version" json:"-"`
+
+	// ClosedAt is when the account was closed, or nil while it is open.
+	ClosedAt *time.Time `db:"closed_at" json:"closed_at,omitempty"`
+
 	// CreatedAt is the timestamp when the account was created.
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 
 	// UpdatedAt is the timestamp when the account was last updated.
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	// Warnings are set by the service when a create request was accepted but looks
+	// suspicious. Not persisted.
+	Warnings []Warning `db:"-" json:"-"`
+
+	// Replayed is set when an idempotent create returns the account that already had
+	// the requested ID instead of creating one. Not persisted.
+	Replayed bool `db:"-" json:"-"`
+}
+
+// AvailableBalance returns what transfers and withdrawals may spend: the balance plus
+// the overdraft limit, less what holds have reserved.
+func (a *Account) AvailableBalance() decimal.Decimal {
+	return a.Balance.Add(a.OverdraftLimit).Sub(a.HeldBalance)
+}
+
+// IsClosed reports whether the account has been closed.
+func (a *Account) IsClosed() bool {
+	return a.ClosedAt != nil
+}
+
+// Status returns AccountStatusClosed or AccountStatusOpen.
+func (a *Account) Status() string {
+	if a.IsClosed() {
+		return AccountStatusClosed
+	}
+	return AccountStatusOpen
 }
 
 // TableName returns the database table name for Account.
@@ -38,3 +109,46 @@ type Account struct {
 func (a Account) TableName() string {
 	return "accounts"
 }
+
+// AccountBatchResult reports a bulk account creation. Created holds the new accounts in
+// request order; Failed holds every rejected request, ordered by Index.
+type AccountBatchResult struct {
+	Created []*Account
+	Failed  []AccountBatchFailure
+}
+
+// AccountBatchFailure is one rejected request of a bulk account creation.
+type AccountBatchFailure struct {
+	// Index is the request's position in the batch.
+	Index int
+
+	// AccountID is the requested ID, or 0 if the server was to generate one.
+	AccountID int64
+
+	// Err is why the account wasn't created, e.g. ErrAccountAlreadyExists.
+	Err error
+}
+
+// AccountSortField is a column an account listing can be ordered by.
+type AccountSortField string
+
+const (
+	AccountSortByID        AccountSortField = "account_id"
+	AccountSortByBalance   AccountSortField = "balance"
+	AccountSortByCreatedAt AccountSortField = "created_at"
+)
+
+// AccountListFilter selects and orders a page of accounts. Unset balance bounds don't
+// filter; both bounds are inclusive. Ties in SortBy are broken by account ID, in the
+// same direction, so offset pages don't overlap.
+type AccountListFilter struct {
+	MinBalance decimal.NullDecimal
+	MaxBalance decimal.NullDecimal
+
+	// SortBy defaults to AccountSortByID when empty.
+	SortBy     AccountSortField
+	Descending bool
+
+	Limit  int
+	Offset int
+}
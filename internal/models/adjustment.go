@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceDelta is one requested change in a bulk balance adjustment.
+type BalanceDelta struct {
+	AccountID int64
+	Delta     decimal.Decimal
+}
+
+// BalanceAdjustment is the audit record of an operational balance correction.
+//
+// Business rules:
+//   - Every account in a batch gets exactly one record, sharing BatchID and Reason
+//   - Delta is non-zero and may be negative
+//   - BalanceAfter is the account balance once the whole batch was applied
+type BalanceAdjustment struct {
+	// AdjustmentID is the unique identifier of this audit record.
+	AdjustmentID int64 `db:"adjustment_id" id:"true" json:"adjustment_id"`
+
+	// BatchID identifies the bulk adjustment this record belongs to.
+	BatchID int64 `db:"batch_id" json:"batch_id"`
+
+	// AccountID is the adjusted account.
+	AccountID int64 `db:"account_id" json:"account_id"`
+
+	// Delta is the signed amount applied to the balance.
+	Delta decimal.Decimal `db:"delta" json:"delta"`
+
+	// BalanceAfter is the resulting balance.
+	BalanceAfter decimal.Decimal `db:"balance_after" json:"balance_after"`
+
+	// Reason is the operator-supplied justification, shared by the whole batch.
+	Reason string `db:"reason" json:"reason"`
+
+	// CreatedAt is when the adjustment was recorded.
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// TableName returns the database table name for BalanceAdjustment.
+func (a BalanceAdjustment) TableName() string {
+	return "balance_adjustments"
+}
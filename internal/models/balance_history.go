@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceChange is one entry in an account's balance history.
+//
+// Business rules:
+//   - Written in the same database transaction as the balance update it records
+//   - Exactly one of TransactionID and AdjustmentID is set
+//   - An account's changes, ordered by ChangeID, chain: each OldBalance is the previous
+//     NewBalance
+type BalanceChange struct {
+	// ChangeID is the unique, increasing identifier of this history entry.
+	ChangeID int64 `db:"change_id" id:"true" json:"change_id"`
+
+	// AccountID is the account whose balance changed.
+	AccountID int64 `db:"account_id" json:"account_id"`
+
+	// OldBalance and NewBalance are the balance before and after the change.
+	OldBalance decimal.Decimal `db:"old_balance" json:"old_balance"`
+	NewBalance decimal.Decimal `db:"new_balance" json:"new_balance"`
+
+	// TransactionID is the transfer, deposit, or withdrawal that caused the change.
+	TransactionID *int64 `db:"transaction_id" json:"transaction_id,omitempty"`
+
+	// AdjustmentID is the bulk adjustment record that caused the change.
+	AdjustmentID *int64 `db:"adjustment_id" json:"adjustment_id,omitempty"`
+
+	// CreatedAt is when the change was recorded.
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// TableName returns the database table name for BalanceChange.
+func (c BalanceChange) TableName() string {
+	return "balance_history"
+}
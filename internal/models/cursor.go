@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// TransactionCursor is a keyset position in an account's transaction history, which is
+// ordered by CreatedAt then TransactionID, both descending. CreatedAt alone is not unique
+// (transfers in one database transaction share NOW()), so TransactionID breaks ties.
+type TransactionCursor struct {
+	CreatedAt     time.Time
+	TransactionID int64
+}
+
+// ErrInvalidCursor is returned by ParseTransactionCursor for malformed input.
+var ErrInvalidCursor = errors.New("invalid transaction cursor")
+
+// maxCursorTime sorts after any stored created_at.
+var maxCursorTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// CursorAfter returns the cursor positioned just past txn, i.e. the cursor for the page
+// that follows a page ending in txn.
+func CursorAfter(txn *Transaction) TransactionCursor {
+	return TransactionCursor{CreatedAt: txn.CreatedAt, TransactionID: txn.TransactionID}
+}
+
+// StartCursor returns a cursor that sorts before every transaction, for the first page.
+func StartCursor() TransactionCursor {
+	return TransactionCursor{CreatedAt: maxCursorTime, TransactionID: math.MaxInt64}
+}
+
+// IsZero reports whether c is the zero cursor, meaning "no further pages".
+func (c TransactionCursor) IsZero() bool {
+	return c.TransactionID == 0
+}
+
+// Less reports whether c precedes other in (CreatedAt, TransactionID) order. The page
+// starting at cursor k holds the rows whose position is Less than k.
+func (c TransactionCursor) Less(other TransactionCursor) bool {
+	if !c.CreatedAt.Equal(other.CreatedAt) {
+		return c.CreatedAt.Before(other.CreatedAt)
+	}
+	return c.TransactionID < other.TransactionID
+}
+
+// String encodes c as an opaque URL-safe token. Timestamps are kept at microsecond
+// precision, matching Postgres TIMESTAMPTZ.
+func (c TransactionCursor) String() string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedAt.UnixMicro(), c.TransactionID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseTransactionCursor decodes a token produced by TransactionCursor.String.
+func ParseTransactionCursor(s string) (TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return TransactionCursor{}, ErrInvalidCursor
+	}
+
+	var micros, id int64
+	if n, err := fmt.Sscanf(string(raw), "%d:%d", &micros, &id); err != nil || n != 2 || id <= 0 {
+		return TransactionCursor{}, ErrInvalidCursor
+	}
+
+	return TransactionCursor{CreatedAt: time.UnixMicro(micros).UTC(), TransactionID: id}, nil
+}
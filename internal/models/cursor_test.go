@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestTransactionCursor_RoundTrip(t *testing.T) {
+	c := TransactionCursor{CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC), TransactionID: 42}
+
+	parsed, err := ParseTransactionCursor(c.String())
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if !parsed.CreatedAt.Equal(c.CreatedAt) || parsed.TransactionID != c.TransactionID {
+		t.Errorf("expected %+v, got %+v", c, parsed)
+	}
+}
+
+func TestParseTransactionCursor_Invalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	for _, input := range []string{"", "42", "!!!", encode("abc"), encode("123"), encode("123:0"), encode("123:-5")} {
+		if _, err := ParseTransactionCursor(input); err != ErrInvalidCursor {
+			t.Errorf("%q: expected ErrInvalidCursor, got %v", input, err)
+		}
+	}
+}
+
+func TestTransactionCursor_Less(t *testing.T) {
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	older := TransactionCursor{CreatedAt: at, TransactionID: 9}
+	sameTimeLowerID := TransactionCursor{CreatedAt: at.Add(time.Second), TransactionID: 3}
+	sameTimeHigherID := TransactionCursor{CreatedAt: at.Add(time.Second), TransactionID: 4}
+
+	if !older.Less(sameTimeLowerID) {
+		t.Error("created_at must order before transaction ID")
+	}
+	if !sameTimeLowerID.Less(sameTimeHigherID) || sameTimeHigherID.Less(sameTimeLowerID) {
+		t.Error("transaction ID must break created_at ties")
+	}
+	if !sameTimeHigherID.Less(StartCursor()) {
+		t.Error("start cursor must sort after every transaction")
+	}
+}
@@ -1,16 +1,37 @@
 package models
 
+import "encoding/json"
+
 // CreateAccountRequest represents the request body for creating a new account.
 // POST /api/v1/accounts
 type CreateAccountRequest struct {
 	// AccountID is the unique identifier for the account.
-	// Must be a positive integer provided by the client.
-	AccountID int64 `json:"account_id"`
+	// Must be a positive integer when provided. Omit it (or send 0) to have the
+	// server generate one; the generated ID is returned in the response.
+	AccountID int64 `json:"account_id,omitempty"`
 
 	// InitialBalance is the starting balance for the account.
 	// Must be a valid decimal string (e.g., "1000.00", "0", "100.50").
 	// Cannot be negative.
 	InitialBalance string `json:"initial_balance"`
+
+	// MaxBalance is an optional balance ceiling as a decimal string.
+	// When set, it must be at least InitialBalance. Omit for no ceiling.
+	MaxBalance string `json:"max_balance,omitempty"`
+
+	// AccountType is an optional category such as "escrow", used to apply balance
+	// policies. Lowercase letters, digits, and underscores. Defaults to "standard".
+	AccountType string `json:"account_type,omitempty"`
+
+	// OverdraftLimit is an optional decimal string saying how far below zero the balance
+	// may go. Cannot be negative. Omit for no overdraft.
+	OverdraftLimit string `json:"overdraft_limit,omitempty"`
+
+	// Idempotent makes creating an account whose ID is taken succeed with the existing
+	// account, provided it was opened with the same initial balance, so a retried
+	// request doesn't fail with account_exists. Only meaningful with an AccountID, and
+	// ignored by bulk creation.
+	Idempotent bool `json:"idempotent,omitempty"`
 }
 
 // GetAccountResponse represents the response body for account retrieval.
@@ -22,6 +43,104 @@ type GetAccountResponse struct {
 	// Balance is the current balance as a decimal string.
 	// Returned as string to preserve decimal precision.
 	Balance string `json:"balance"`
+
+	// HeldBalance is the amount reserved by active holds.
+	HeldBalance string `json:"held_balance"`
+
+	// OverdraftLimit is how far below zero the balance may go.
+	OverdraftLimit string `json:"overdraft_limit"`
+
+	// AvailableBalance is Balance plus OverdraftLimit minus HeldBalance, the amount
+	// transfers may spend.
+	AvailableBalance string `json:"available_balance"`
+
+	// MaxBalance is the balance ceiling, omitted when the account has none.
+	MaxBalance string `json:"max_balance,omitempty"`
+
+	// AccountType is the account's category.
+	AccountType string `json:"account_type"`
+
+	// Status is AccountStatusOpen or AccountStatusClosed.
+	Status string `json:"status"`
+
+	// ClosedAt is when the account was closed, omitted while it is open.
+	ClosedAt string `json:"closed_at,omitempty"`
+
+	// Warnings lists non-fatal notices about the create request, e.g. an unusually
+	// large initial balance. Only ever set on creation.
+	Warnings []Warning `json:"warnings,omitempty"`
+
+	// AmountCurrency, when set, marshals each amount above as a MoneyObject in this
+	// currency rather than a bare decimal string. It is not part of the response itself.
+	AmountCurrency string `json:"-"`
+}
+
+// MarshalJSON writes the amounts as MoneyObjects when AmountCurrency is set.
+func (r GetAccountResponse) MarshalJSON() ([]byte, error) {
+	type plain GetAccountResponse
+	if r.AmountCurrency == "" {
+		return json.Marshal(plain(r))
+	}
+	money := func(value string) MoneyObject {
+		return MoneyObject{Value: value, Currency: r.AmountCurrency}
+	}
+	resp := struct {
+		plain
+		Balance          MoneyObject  `json:"balance"`
+		HeldBalance      MoneyObject  `json:"held_balance"`
+		OverdraftLimit   MoneyObject  `json:"overdraft_limit"`
+		AvailableBalance MoneyObject  `json:"available_balance"`
+		MaxBalance       *MoneyObject `json:"max_balance,omitempty"`
+	}{
+		plain:            plain(r),
+		Balance:          money(r.Balance),
+		HeldBalance:      money(r.HeldBalance),
+		OverdraftLimit:   money(r.OverdraftLimit),
+		AvailableBalance: money(r.AvailableBalance),
+	}
+	if r.MaxBalance != "" {
+		maxBalance := money(r.MaxBalance)
+		resp.MaxBalance = &maxBalance
+	}
+	return json.Marshal(resp)
+}
+
+// MoneyObject is an amount together with its currency, the structured alternative to a
+// bare decimal string that clients can ask for in responses.
+type MoneyObject struct {
+	// Value is the amount as a decimal string, formatted as the plain form would be.
+	Value string `json:"value"`
+
+	// Currency is the ISO 4217 code of the amount's currency, e.g. "USD".
+	Currency string `json:"currency"`
+}
+
+// AccountExportRecord is a single line of the NDJSON account export.
+// GET /api/v1/accounts.ndjson
+type AccountExportRecord struct {
+	// AccountID is the unique identifier of the account.
+	AccountID int64 `json:"account_id"`
+
+	// Balance is the balance at the time the row was read, as a decimal string.
+	Balance string `json:"balance"`
+
+	// UpdatedAt is when the balance last changed, in RFC 3339 format.
+	UpdatedAt string `json:"updated_at"`
+}
+
+// TransferDebug reports how a transfer was executed, for diagnosing contention.
+// It is only returned when debug responses are enabled and requested.
+type TransferDebug struct {
+	// IsolationLevel is the isolation level of the final attempt's database transaction.
+	// Empty for idempotent replays, which don't open one.
+	IsolationLevel string `json:"isolation_level,omitempty"`
+
+	// MaxRetries is the configured retry budget.
+	MaxRetries int `json:"max_retries"`
+
+	// Attempts is how many times the transfer was executed (1 means no retries).
+	// Zero for idempotent replays.
+	Attempts int `json:"attempts"`
 }
 
 // CreateTransactionRequest represents the request body for creating a transfer.
@@ -38,4 +157,189 @@ type CreateTransactionRequest struct {
 	// Amount is the transfer amount as a decimal string.
 	// Must be a positive decimal (e.g., "100.00", "50.50").
 	Amount string `json:"amount"`
+
+	// EffectiveDate is the optional bookkeeping date in YYYY-MM-DD format.
+	// Defaults to today (UTC). Must fall within the configured back-dating window.
+	EffectiveDate string `json:"effective_date,omitempty"`
+
+	// Sequence is an optional per-source sequence number for replay protection.
+	// When set it must be positive and greater than the last sequence accepted for
+	// the source account, otherwise the transfer is rejected as stale.
+	Sequence *int64 `json:"sequence,omitempty"`
+
+	// Category is an optional reporting label of up to 64 characters (e.g. "payroll").
+	Category string `json:"category,omitempty"`
+
+	// IdempotencyKey is taken from the Idempotency-Key header, not the body.
+	// Repeating a key with the same body returns the original transaction.
+	IdempotencyKey string `json:"-"`
+}
+
+// LedgerEntryRequest represents the request body for a deposit or withdrawal.
+// POST /api/v1/accounts/{id}/deposits
+// POST /api/v1/accounts/{id}/withdrawals
+type LedgerEntryRequest struct {
+	// Amount is the positive amount to credit or debit, as a decimal string.
+	Amount string `json:"amount"`
+
+	// Category is an optional reporting label of up to 64 characters.
+	Category string `json:"category,omitempty"`
+
+	// IdempotencyKey is taken from the Idempotency-Key header, not the body.
+	IdempotencyKey string `json:"-"`
+}
+
+// CreateHoldRequest represents the request body for placing a hold.
+// POST /api/v1/accounts/{id}/holds
+type CreateHoldRequest struct {
+	// Amount is the positive amount to reserve, as a decimal string.
+	Amount string `json:"amount"`
+}
+
+// CreateRecurringTransferRequest represents the request body for scheduling a transfer.
+// POST /api/v1/transfers/recurring
+type CreateRecurringTransferRequest struct {
+	// SourceAccountID and DestinationAccountID must be distinct existing accounts.
+	SourceAccountID      int64 `json:"source_account_id"`
+	DestinationAccountID int64 `json:"destination_account_id"`
+
+	// Amount is the positive amount every run moves, as a decimal string.
+	Amount string `json:"amount"`
+
+	// Category is an optional reporting label for every run's transaction.
+	Category string `json:"category,omitempty"`
+
+	// Every is the time between runs as a Go duration (e.g. "24h", "30m"), in whole
+	// seconds and at least MinRecurringInterval.
+	Every string `json:"every"`
+
+	// StartAt is the optional RFC 3339 time of the first run. Defaults to now.
+	StartAt string `json:"start_at,omitempty"`
+
+	// EndAt is the optional RFC 3339 time after which no more runs are due.
+	EndAt string `json:"end_at,omitempty"`
+}
+
+// CreateBatchTransferRequest represents the request body for an atomic one-to-many transfer.
+// POST /api/v1/transactions/batch
+type CreateBatchTransferRequest struct {
+	// SourceAccountID is the account every transfer in the batch is debited from.
+	SourceAccountID int64 `json:"source_account_id"`
+
+	// Transfers lists the legs. Either all of them are applied or none are.
+	// A destination may appear more than once.
+	Transfers []BatchTransferItem `json:"transfers"`
+}
+
+// BatchTransferItem is a single leg of a CreateBatchTransferRequest.
+type BatchTransferItem struct {
+	// DestinationAccountID is the account to credit. Must differ from the source.
+	DestinationAccountID int64 `json:"destination_account_id"`
+
+	// Amount is the positive amount to move, as a decimal string.
+	Amount string `json:"amount"`
+}
+
+// BatchAdjustRequest represents the request body for a bulk balance adjustment.
+// POST /api/v1/admin/accounts:batchAdjust
+type BatchAdjustRequest struct {
+	// Reason is recorded on every account's audit entry. Required.
+	Reason string `json:"reason"`
+
+	// Adjustments lists one signed delta per account. Account IDs must be distinct.
+	Adjustments []BalanceAdjustmentItem `json:"adjustments"`
+}
+
+// BalanceAdjustmentItem is a single account's change within a BatchAdjustRequest.
+type BalanceAdjustmentItem struct {
+	// AccountID is the account to adjust.
+	AccountID int64 `json:"account_id"`
+
+	// Delta is the signed, non-zero amount to add as a decimal string (e.g., "-12.50").
+	Delta string `json:"delta"`
+}
+
+// BatchAdjustResponse represents the response body for a bulk balance adjustment.
+type BatchAdjustResponse struct {
+	// BatchID identifies the batch; every audit entry references it.
+	BatchID int64 `json:"batch_id"`
+
+	// Reason is the reason recorded for the batch.
+	Reason string `json:"reason"`
+
+	// Adjustments are the applied changes, ordered by account ID.
+	Adjustments []BalanceAdjustmentResult `json:"adjustments"`
+}
+
+// BalanceAdjustmentResult reports one applied change of a batch.
+type BalanceAdjustmentResult struct {
+	// AccountID is the adjusted account.
+	AccountID int64 `json:"account_id"`
+
+	// Delta is the applied amount as a decimal string.
+	Delta string `json:"delta"`
+
+	// Balance is the account balance after the batch, as a decimal string.
+	Balance string `json:"balance"`
+}
+
+// ReconcileItem is one account's authoritative balance in a reconciliation import.
+// POST /api/v1/admin/reconcile takes a JSON array of them.
+type ReconcileItem struct {
+	// AccountID is the account to reconcile. Each account may be listed once.
+	AccountID int64 `json:"account_id"`
+
+	// ExpectedBalance is the balance the account should have, as a decimal string. It
+	// may be negative for an account with an overdraft.
+	ExpectedBalance string `json:"expected_balance"`
+}
+
+// ReconcileResponse represents the discrepancy report of a reconciliation import.
+type ReconcileResponse struct {
+	// Applied says whether corrections were requested with ?apply=true.
+	Applied bool `json:"applied"`
+
+	// BatchID identifies the adjustment batch holding the corrections. Omitted when
+	// nothing was corrected.
+	BatchID int64 `json:"batch_id,omitempty"`
+
+	// Reason is the reason recorded on the corrections.
+	Reason string `json:"reason"`
+
+	// Summary counts the accounts by status.
+	Summary ReconcileSummary `json:"summary"`
+
+	// Accounts reports each imported account, ordered by account ID.
+	Accounts []ReconcileResult `json:"accounts"`
+}
+
+// ReconcileSummary counts the accounts of a reconciliation by status.
+type ReconcileSummary struct {
+	Matched    int `json:"matched"`
+	Mismatched int `json:"mismatched"`
+	Corrected  int `json:"corrected"`
+	Missing    int `json:"missing"`
+}
+
+// ReconcileResult compares one account's stored and expected balances.
+type ReconcileResult struct {
+	// AccountID is the reconciled account.
+	AccountID int64 `json:"account_id"`
+
+	// Status is "matched", "mismatched", "corrected", or "missing".
+	Status string `json:"status"`
+
+	// ExpectedBalance is the imported balance as a decimal string.
+	ExpectedBalance string `json:"expected_balance"`
+
+	// StoredBalance is the balance before any correction, as a decimal string. Omitted
+	// for a missing account.
+	StoredBalance string `json:"stored_balance,omitempty"`
+
+	// Difference is expected_balance less stored_balance, the amount a correction adds.
+	// Omitted for a missing account.
+	Difference string `json:"difference,omitempty"`
+
+	// AdjustmentID is the audit entry of the correction, for a corrected account.
+	AdjustmentID int64 `json:"adjustment_id,omitempty"`
 }
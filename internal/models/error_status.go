@@ -0,0 +1,71 @@
+package models
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ErrorStatus says how a domain error code is reported to clients. Public errors are
+// sent with their own code and message; any other is reported as a generic internal
+// error, so database and programming errors don't leak to clients.
+type ErrorStatus struct {
+	HTTP   int
+	GRPC   codes.Code
+	Public bool
+}
+
+// errorStatuses maps every domain error code to its HTTP status and gRPC code. The gRPC
+// codes follow the HTTP ones: 404s are NotFound, requests that can't succeed in the
+// current state (the 409s and 422s) are FailedPrecondition, except duplicates, which are
+// AlreadyExists, and a lost optimistic race, which is Aborted so clients know to retry.
+var errorStatuses = map[ErrorCode]ErrorStatus{
+	CodeAccountNotFound:   {http.StatusNotFound, codes.NotFound, true},
+	CodeTransferNotFound:  {http.StatusNotFound, codes.NotFound, true},
+	CodeHoldNotFound:      {http.StatusNotFound, codes.NotFound, true},
+	CodeRecurringNotFound: {http.StatusNotFound, codes.NotFound, true},
+
+	CodeInvalidAmount:        {http.StatusBadRequest, codes.InvalidArgument, true},
+	CodeSameAccount:          {http.StatusBadRequest, codes.InvalidArgument, true},
+	CodeInvalidAdjustment:    {http.StatusBadRequest, codes.InvalidArgument, true},
+	CodeInvalidBatch:         {http.StatusBadRequest, codes.InvalidArgument, true},
+	CodeInvalidEffectiveDate: {http.StatusBadRequest, codes.InvalidArgument, true},
+	CodeInvalidDateRange:     {http.StatusBadRequest, codes.InvalidArgument, true},
+	CodeCurrencyMismatch:     {http.StatusBadRequest, codes.InvalidArgument, true},
+
+	CodeAccountAlreadyExists: {http.StatusConflict, codes.AlreadyExists, true},
+	CodeAccountAlreadyClosed: {http.StatusConflict, codes.FailedPrecondition, true},
+	CodeAccountNotEmpty:      {http.StatusConflict, codes.FailedPrecondition, true},
+	CodeDuplicateTransaction: {http.StatusConflict, codes.AlreadyExists, true},
+	CodeIdempotencyConflict:  {http.StatusConflict, codes.FailedPrecondition, true},
+	CodeAlreadyReversed:      {http.StatusConflict, codes.FailedPrecondition, true},
+	CodeStaleSequence:        {http.StatusConflict, codes.FailedPrecondition, true},
+	CodeConcurrentModified:   {http.StatusConflict, codes.Aborted, true},
+	CodeHoldNotActive:        {http.StatusConflict, codes.FailedPrecondition, true},
+	CodeRecurringNotActive:   {http.StatusConflict, codes.FailedPrecondition, true},
+
+	CodeAccountClosed:       {http.StatusUnprocessableEntity, codes.FailedPrecondition, true},
+	CodeInsufficientBalance: {http.StatusUnprocessableEntity, codes.FailedPrecondition, true},
+	CodeDestBalanceLimit:    {http.StatusUnprocessableEntity, codes.FailedPrecondition, true},
+	CodeNotReversible:       {http.StatusUnprocessableEntity, codes.FailedPrecondition, true},
+	CodeFeeExceedsAmount:    {http.StatusUnprocessableEntity, codes.FailedPrecondition, true},
+	CodeAmountBelowMinimum:  {http.StatusUnprocessableEntity, codes.FailedPrecondition, true},
+	CodeAmountAboveMaximum:  {http.StatusUnprocessableEntity, codes.FailedPrecondition, true},
+
+	CodeCooldownActive: {http.StatusTooManyRequests, codes.ResourceExhausted, true},
+	CodeVelocityLimit:  {http.StatusTooManyRequests, codes.ResourceExhausted, true},
+
+	CodeTransferBlackout: {http.StatusServiceUnavailable, codes.Unavailable, true},
+	CodeServiceBusy:      {http.StatusServiceUnavailable, codes.Unavailable, true},
+
+	CodeDatabaseError:     {http.StatusInternalServerError, codes.Internal, false},
+	CodeTransactionFailed: {http.StatusInternalServerError, codes.Internal, false},
+	CodeInternalError:     {http.StatusInternalServerError, codes.Internal, false},
+}
+
+// StatusOf returns how code is reported to clients over HTTP and gRPC. ok is false for
+// a code that isn't registered, which callers report as an internal error.
+func StatusOf(code ErrorCode) (status ErrorStatus, ok bool) {
+	status, ok = errorStatuses[code]
+	return status, ok
+}
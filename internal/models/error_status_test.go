@@ -0,0 +1,71 @@
+package models
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+// declaredErrorCodes returns the ErrorCode constants declared in this package by name,
+// read from its source so a newly added code can't be missed.
+func declaredErrorCodes(t *testing.T) map[string]ErrorCode {
+	t.Helper()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("parse package: %v", err)
+	}
+
+	declared := make(map[string]ErrorCode)
+	for _, file := range pkgs["models"].Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if ident, ok := vs.Type.(*ast.Ident); !ok || ident.Name != "ErrorCode" {
+					continue
+				}
+				for i, name := range vs.Names {
+					lit, ok := vs.Values[i].(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						t.Fatalf("%s: expected a string literal value", name.Name)
+					}
+					value, _ := strconv.Unquote(lit.Value)
+					declared[name.Name] = ErrorCode(value)
+				}
+			}
+		}
+	}
+	if len(declared) == 0 {
+		t.Fatal("found no ErrorCode constants")
+	}
+	return declared
+}
+
+func TestStatusOf_CoversEveryCode(t *testing.T) {
+	for name, code := range declaredErrorCodes(t) {
+		s, ok := StatusOf(code)
+		if !ok {
+			t.Errorf("%s (%q) has no errorStatuses entry", name, code)
+			continue
+		}
+		if s.HTTP == 0 || s.GRPC == codes.OK {
+			t.Errorf("%s (%q) needs both an HTTP status and a gRPC code, got %+v", name, code, s)
+		}
+		if !s.Public && (s.HTTP != http.StatusInternalServerError || s.GRPC != codes.Internal) {
+			t.Errorf("%s (%q) is not public, so it must be a 500 and INTERNAL, got %+v", name, code, s)
+		}
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type ErrorCode string
@@ -11,12 +14,36 @@ type ErrorCode string
 const (
 	CodeAccountNotFound      ErrorCode = "account_not_found"
 	CodeInsufficientBalance  ErrorCode = "insufficient_balance"
+	CodeDestBalanceLimit     ErrorCode = "destination_balance_limit"
 	CodeInvalidAmount        ErrorCode = "invalid_amount"
+	CodeInvalidEffectiveDate ErrorCode = "invalid_effective_date"
 	CodeCurrencyMismatch     ErrorCode = "currency_mismatch"
 	CodeSameAccount          ErrorCode = "same_account"
 	CodeTransferNotFound     ErrorCode = "transaction_not_found"
 	CodeAccountAlreadyExists ErrorCode = "account_exists"
 	CodeDuplicateTransaction ErrorCode = "duplicate_transaction"
+	CodeIdempotencyConflict  ErrorCode = "idempotency_key_conflict"
+	CodeAlreadyReversed      ErrorCode = "already_reversed"
+	CodeInvalidAdjustment    ErrorCode = "invalid_adjustment"
+	CodeInvalidBatch         ErrorCode = "invalid_batch"
+	CodeNotReversible        ErrorCode = "not_reversible"
+	CodeStaleSequence        ErrorCode = "stale_sequence"
+	CodeInvalidDateRange     ErrorCode = "invalid_date_range"
+	CodeConcurrentModified   ErrorCode = "concurrent_modification"
+	CodeAccountClosed        ErrorCode = "account_closed"
+	CodeAccountAlreadyClosed ErrorCode = "account_already_closed"
+	CodeAccountNotEmpty      ErrorCode = "account_not_empty"
+	CodeCooldownActive       ErrorCode = "cooldown_active"
+	CodeHoldNotFound         ErrorCode = "hold_not_found"
+	CodeHoldNotActive        ErrorCode = "hold_not_active"
+	CodeFeeExceedsAmount     ErrorCode = "fee_exceeds_amount"
+	CodeRecurringNotFound    ErrorCode = "recurring_transfer_not_found"
+	CodeRecurringNotActive   ErrorCode = "recurring_transfer_not_active"
+	CodeTransferBlackout     ErrorCode = "transfer_blackout"
+	CodeAmountBelowMinimum   ErrorCode = "amount_below_minimum"
+	CodeAmountAboveMaximum   ErrorCode = "amount_above_maximum"
+	CodeVelocityLimit        ErrorCode = "velocity_limit_exceeded"
+	CodeServiceBusy          ErrorCode = "service_busy"
 	CodeDatabaseError        ErrorCode = "database_error"
 	CodeTransactionFailed    ErrorCode = "transaction_failed"
 	CodeInternalError        ErrorCode = "internal_error"
@@ -63,10 +90,18 @@ var (
 		Code:    CodeInsufficientBalance,
 		Message: "insufficient balance for this transaction",
 	}
+	ErrDestinationBalanceLimit = &DomainError{
+		Code:    CodeDestBalanceLimit,
+		Message: "transfer would exceed the destination account's maximum balance",
+	}
 	ErrInvalidAmount = &DomainError{
 		Code:    CodeInvalidAmount,
 		Message: "amount must be a positive decimal value",
 	}
+	ErrInvalidEffectiveDate = &DomainError{
+		Code:    CodeInvalidEffectiveDate,
+		Message: "effective date is outside the allowed range",
+	}
 	ErrCurrencyMismatch = &DomainError{
 		Code:    CodeCurrencyMismatch,
 		Message: "currency mismatch between accounts",
@@ -87,8 +122,126 @@ var (
 		Code:    CodeDuplicateTransaction,
 		Message: "duplicate transaction detected",
 	}
+	ErrIdempotencyKeyConflict = &DomainError{
+		Code:    CodeIdempotencyConflict,
+		Message: "idempotency key was already used with a different request",
+	}
+	ErrAlreadyReversed = &DomainError{
+		Code:    CodeAlreadyReversed,
+		Message: "transaction has already been reversed",
+	}
+	ErrInvalidAdjustment = &DomainError{
+		Code:    CodeInvalidAdjustment,
+		Message: "adjustment batch must have a reason and non-zero deltas for distinct accounts",
+	}
+	ErrNotReversible = &DomainError{
+		Code:    CodeNotReversible,
+		Message: "only completed transfers can be reversed",
+	}
+	ErrInvalidBatch = &DomainError{
+		Code:    CodeInvalidBatch,
+		Message: "batch must contain between 1 and the maximum number of transfers",
+	}
+	ErrInvalidDateRange = &DomainError{
+		Code:    CodeInvalidDateRange,
+		Message: "from must not be after to",
+	}
+	ErrStaleSequence = &DomainError{
+		Code:    CodeStaleSequence,
+		Message: "sequence must be greater than the last one accepted for the source account",
+	}
+	ErrConcurrentModification = &DomainError{
+		Code:    CodeConcurrentModified,
+		Message: "account was modified concurrently",
+	}
+	ErrAccountClosed = &DomainError{
+		Code:    CodeAccountClosed,
+		Message: "account is closed",
+	}
+	ErrAccountAlreadyClosed = &DomainError{
+		Code:    CodeAccountAlreadyClosed,
+		Message: "account is already closed",
+	}
+	ErrAccountNotEmpty = &DomainError{
+		Code:    CodeAccountNotEmpty,
+		Message: "account has a non-zero balance; move the funds out or close with force=true",
+	}
+	ErrCooldownActive = &DomainError{
+		Code:    CodeCooldownActive,
+		Message: "source account sent a transfer too recently; retry later",
+	}
+	ErrHoldNotFound = &DomainError{
+		Code:    CodeHoldNotFound,
+		Message: "hold not found",
+	}
+	ErrHoldNotActive = &DomainError{
+		Code:    CodeHoldNotActive,
+		Message: "hold has already been released or captured",
+	}
+	ErrFeeExceedsAmount = &DomainError{
+		Code:    CodeFeeExceedsAmount,
+		Message: "transfer fee would exceed the amount being transferred",
+	}
+	ErrRecurringTransferNotFound = &DomainError{
+		Code:    CodeRecurringNotFound,
+		Message: "recurring transfer not found",
+	}
+	ErrRecurringTransferNotActive = &DomainError{
+		Code:    CodeRecurringNotActive,
+		Message: "recurring transfer has already completed or been canceled",
+	}
+	ErrTransferBlackout = &DomainError{
+		Code:    CodeTransferBlackout,
+		Message: "transfers are paused for a scheduled blackout; retry later",
+	}
+	ErrAmountBelowMinimum = &DomainError{
+		Code:    CodeAmountBelowMinimum,
+		Message: "amount is below the minimum transfer amount",
+	}
+	ErrAmountAboveMaximum = &DomainError{
+		Code:    CodeAmountAboveMaximum,
+		Message: "amount is above the maximum transfer amount",
+	}
+	ErrVelocityLimitExceeded = &DomainError{
+		Code:    CodeVelocityLimit,
+		Message: "source account has reached its daily transfer limit; retry after it resets",
+	}
+	ErrServiceBusy = &DomainError{
+		Code:    CodeServiceBusy,
+		Message: "the service is too busy to take this request; retry later",
+	}
 )
 
+// CooldownError is the cause of an ErrCooldownActive and says how long until the source
+// account may send again.
+type CooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("transfer cooldown active for another %s", e.RetryAfter)
+}
+
+// BlackoutError is the cause of an ErrTransferBlackout and says when transfers are
+// accepted again.
+type BlackoutError struct {
+	Until time.Time
+}
+
+func (e *BlackoutError) Error() string {
+	return fmt.Sprintf("transfer blackout until %s", e.Until.UTC().Format(time.RFC3339))
+}
+
+// VelocityLimitError is the cause of an ErrVelocityLimitExceeded and says when the source
+// account's daily limits reset.
+type VelocityLimitError struct {
+	ResetsAt time.Time
+}
+
+func (e *VelocityLimitError) Error() string {
+	return fmt.Sprintf("daily transfer limit reached until %s", e.ResetsAt.UTC().Format(time.RFC3339))
+}
+
 func IsDomainError(err error) (ErrorCode, bool) {
 	var domainErr *DomainError
 	if errors.As(err, &domainErr) {
@@ -97,10 +250,34 @@ func IsDomainError(err error) (ErrorCode, bool) {
 	return "", false
 }
 
+// IsSerializationFailure reports whether err carries a Postgres SQLSTATE in class 40
+// (transaction rollback), e.g. 40001 serialization_failure or 40P01 deadlock_detected.
+// These guarantee the server rolled the transaction back, so the whole unit is safe to retry.
+func IsSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "40")
+	}
+	return false
+}
+
+// IsRetryable reports whether the operation that failed with err may succeed if run
+// again. A Postgres error is judged by its SQLSTATE alone. Class 40 (serialization
+// failure, deadlock), class 08 (connection exception), 57014 (query canceled, e.g. by
+// statement_timeout), and 57P01-57P03 (server shutting down or starting) are retryable;
+// any other SQLSTATE is not. Errors that never reached Postgres as a query, such as a
+// failed dial, carry no SQLSTATE and are judged by their message instead.
 func IsRetryable(err error) bool {
 	if err == nil {
 		return false
 	}
+	if errors.Is(err, ErrConcurrentModification) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return retryableSQLState(pgErr.Code)
+	}
 	errStr := strings.ToLower(err.Error())
 	patterns := []string{"deadlock", "serialize", "connection", "timeout"}
 	for _, p := range patterns {
@@ -110,3 +287,19 @@ func IsRetryable(err error) bool {
 	}
 	return false
 }
+
+// retryableSQLState reports whether a query failing with SQLSTATE code is worth retrying.
+func retryableSQLState(code string) bool {
+	switch {
+	case strings.HasPrefix(code, "40"): // transaction_rollback: 40001 serialization_failure, 40P01 deadlock_detected
+		return true
+	case strings.HasPrefix(code, "08"): // connection_exception
+		return true
+	case code == "57014": // query_canceled
+		return true
+	case strings.HasPrefix(code, "57P"): // admin_shutdown, crash_shutdown, cannot_connect_now
+		return true
+	default:
+		return false
+	}
+}
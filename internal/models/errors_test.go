@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func TestDomainError(t *testing.T) {
@@ -54,6 +56,20 @@ func TestIsRetryable(t *testing.T) {
 		{fmt.Errorf("timeout"), true},
 		{ErrAccountNotFound, false},
 		{fmt.Errorf("random error"), false},
+		{&pgconn.PgError{Code: "40001"}, true},
+		{fmt.Errorf("commit: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{&pgconn.PgError{Code: "08006", Message: "connection failure"}, true},
+		{&pgconn.PgError{Code: "08P01"}, true},
+		{&pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}, true},
+		{WrapError(CodeDatabaseError, "insert", &pgconn.PgError{Code: "40001"}), true},
+		{fmt.Errorf("update: %w", ErrConcurrentModification), true},
+		// The SQLSTATE decides, whatever the (possibly localized) message says
+		{&pgconn.PgError{Code: "40P01", Message: "se detectó un deadlock"}, true},
+		{&pgconn.PgError{Code: "40P01", Message: "Verklemmung entdeckt"}, true},
+		{&pgconn.PgError{Code: "23505", Message: "duplicate key on connection_id"}, false},
+		{&pgconn.PgError{Code: "57P01", Message: "terminating connection due to administrator command"}, true},
+		{&pgconn.PgError{Code: "22003", Message: "numeric field overflow; timeout"}, false},
+		{fmt.Errorf("dial tcp 10.0.0.1:5432: connection refused"), true},
 	}
 
 	for _, tt := range tests {
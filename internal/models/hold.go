@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// HoldStatus is the lifecycle state of a hold.
+type HoldStatus string
+
+const (
+	// HoldStatusActive holds reserve their amount in the account's HeldBalance.
+	HoldStatusActive HoldStatus = "active"
+
+	// HoldStatusReleased holds gave their amount back to the available balance.
+	HoldStatusReleased HoldStatus = "released"
+
+	// HoldStatusCaptured holds were turned into a withdrawal of their amount.
+	HoldStatusCaptured HoldStatus = "captured"
+)
+
+// Hold reserves part of an account's balance, e.g. for a card authorization, until it
+// is captured or released.
+//
+// Business rules:
+//   - Placing a hold requires the account's available balance to cover Amount
+//   - A hold is resolved at most once; only active holds can be released or captured
+//   - Capturing debits the full Amount as a withdrawal recorded in TransactionID
+type Hold struct {
+	// HoldID is the unique identifier, auto-generated by the database.
+	HoldID int64 `db:"hold_id" id:"true" json:"hold_id"`
+
+	// AccountID is the account whose funds are reserved.
+	AccountID int64 `db:"account_id" json:"account_id"`
+
+	// Amount is the reserved amount.
+	Amount decimal.Decimal `db:"amount" json:"amount"`
+
+	// Status is active, released, or captured.
+	Status HoldStatus `db:"status" json:"status"`
+
+	// TransactionID is the withdrawal created by capturing the hold, or nil.
+	TransactionID *int64 `db:"transaction_id" json:"transaction_id,omitempty"`
+
+	// CreatedAt is when the hold was placed.
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+
+	// ResolvedAt is when the hold was released or captured, or nil while it is active.
+	ResolvedAt *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+}
+
+// TableName returns the database table name for Hold.
+func (h Hold) TableName() string {
+	return "holds"
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// LedgerEntry is one side of a transaction in the double-entry ledger: the debit from
+// its source or the credit to its destination.
+//
+// Business rules:
+//   - Amount is signed from the account's point of view: negative for a debit,
+//     positive for a credit, never zero
+//   - A transfer has two entries, or three with a fee, which sum to zero; a deposit or
+//     withdrawal has only its one side
+//   - Written in the same database transaction as the transaction it posts
+type LedgerEntry struct {
+	// EntryID is the unique, increasing identifier of this entry.
+	EntryID int64 `db:"entry_id" id:"true" json:"entry_id"`
+
+	// TransactionID is the transaction this entry posts.
+	TransactionID int64 `db:"transaction_id" json:"transaction_id"`
+
+	// AccountID is the account debited or credited.
+	AccountID int64 `db:"account_id" json:"account_id"`
+
+	// Amount is the signed amount: negative debits, positive credits.
+	Amount decimal.Decimal `db:"amount" json:"amount"`
+
+	// CreatedAt is when the entry was recorded.
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// TableName returns the database table name for LedgerEntry.
+func (e LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
+
+// IsDebit reports whether the entry takes funds out of its account.
+func (e *LedgerEntry) IsDebit() bool {
+	return e.Amount.IsNegative()
+}
+
+// LedgerEntries returns the entries that post t: a debit of Amount from the source and
+// a credit of Amount to the destination, skipping whichever side t doesn't have. The
+// debit comes first. A fee is added to the source's debit or taken from the
+// destination's credit, depending on FeePaidBy, and credited to the fee account last.
+func (t *Transaction) LedgerEntries() []*LedgerEntry {
+	debit, credit := t.Amount, t.Amount
+	if t.FeeAmount.IsPositive() {
+		if t.FeePaidBy == FeePayerDestination {
+			credit = credit.Sub(t.FeeAmount)
+		} else {
+			debit = debit.Add(t.FeeAmount)
+		}
+	}
+
+	entries := make([]*LedgerEntry, 0, 3)
+	if t.SourceAccountID != 0 {
+		entries = append(entries, &LedgerEntry{TransactionID: t.TransactionID, AccountID: t.SourceAccountID, Amount: debit.Neg()})
+	}
+	if t.DestinationAccountID != 0 {
+		entries = append(entries, &LedgerEntry{TransactionID: t.TransactionID, AccountID: t.DestinationAccountID, Amount: credit})
+	}
+	if t.FeeAmount.IsPositive() {
+		entries = append(entries, &LedgerEntry{TransactionID: t.TransactionID, AccountID: t.FeeAccountID, Amount: t.FeeAmount})
+	}
+	return entries
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// LedgerVerification compares the balances held on accounts with the balances their
+// recorded history accounts for, all read from one snapshot.
+//
+// Business rules:
+//   - Every account's balance should equal its initial balance plus its ledger entries
+//     plus its balance adjustments
+//   - Transfers, including their fees, move money between accounts and net to zero, so
+//     NetTransactions is completed deposits less completed withdrawals
+//   - Failed transactions move nothing and are skipped
+type LedgerVerification struct {
+	// Accounts is the number of accounts checked.
+	Accounts int64 `json:"accounts"`
+
+	// TotalBalance is the sum of every account's balance.
+	TotalBalance decimal.Decimal `json:"total_balance"`
+
+	// InitialBalances is the sum of the balances accounts were opened with.
+	InitialBalances decimal.Decimal `json:"initial_balances"`
+
+	// NetTransactions is completed deposits less completed withdrawals.
+	NetTransactions decimal.Decimal `json:"net_transactions"`
+
+	// Adjustments is the sum of every balance adjustment's delta.
+	Adjustments decimal.Decimal `json:"adjustments"`
+
+	// DriftedAccounts lists accounts whose balance disagrees with their history, up to
+	// the limit the check was run with, lowest account ID first.
+	DriftedAccounts []*AccountDrift `json:"drifted_accounts"`
+}
+
+// AccountDrift is an account whose balance disagrees with its recorded history.
+type AccountDrift struct {
+	AccountID int64           `json:"account_id"`
+	Balance   decimal.Decimal `json:"balance"`
+	Expected  decimal.Decimal `json:"expected"`
+}
+
+// Drift returns how far Balance is from Expected; positive means the account holds more
+// than its history explains.
+func (d *AccountDrift) Drift() decimal.Decimal {
+	return d.Balance.Sub(d.Expected)
+}
+
+// Expected returns the total balance the recorded history accounts for.
+func (v *LedgerVerification) Expected() decimal.Decimal {
+	return v.InitialBalances.Add(v.NetTransactions).Add(v.Adjustments)
+}
+
+// Drift returns how far TotalBalance is from Expected.
+func (v *LedgerVerification) Drift() decimal.Decimal {
+	return v.TotalBalance.Sub(v.Expected())
+}
+
+// Consistent reports whether the totals agree and no account drifted. Drift in opposite
+// directions on two accounts can cancel out in the totals, hence the per-account check.
+func (v *LedgerVerification) Consistent() bool {
+	return v.Drift().IsZero() && len(v.DriftedAccounts) == 0
+}
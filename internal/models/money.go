@@ -1,22 +1,165 @@
 package models
 
 import (
+	"errors"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/shopspring/decimal"
 )
 
+// DefaultMaxMoneyScale is the default number of decimal places an amount may carry.
+// LimitMoneyScale is the most SetMaxMoneyScale accepts.
+const (
+	DefaultMaxMoneyScale = 2
+	LimitMoneyScale      = 18
+)
+
+// maxMoneyExponent bounds an amount's magnitude below 10^308. decimal.Decimal has no NaN
+// or Infinity, but an input like "1e9999" is still a valid decimal; past float64's range a
+// client reading it as a JSON number would get Infinity, so it is rejected as not finite.
+const maxMoneyExponent = 308
+
+var maxMoneyScale atomic.Int32
+
+func init() {
+	maxMoneyScale.Store(DefaultMaxMoneyScale)
+}
+
+// MaxMoneyScale returns the number of decimal places amounts are currently limited to.
+func MaxMoneyScale() int32 {
+	return maxMoneyScale.Load()
+}
+
+// SetMaxMoneyScale changes the decimal-place limit for all amounts parsed afterwards. It is
+// meant to be called once at startup from configuration.
+func SetMaxMoneyScale(scale int) error {
+	if scale < 0 || scale > LimitMoneyScale {
+		return fmt.Errorf("money scale must be between 0 and %d, got %d", LimitMoneyScale, scale)
+	}
+	maxMoneyScale.Store(int32(scale))
+	return nil
+}
+
+// MoneyErrorReason says why an amount string was rejected.
+type MoneyErrorReason string
+
+const (
+	MoneyEmpty           MoneyErrorReason = "empty"
+	MoneyNotNumeric      MoneyErrorReason = "not_numeric"
+	MoneyNotFinite       MoneyErrorReason = "not_finite"
+	MoneyTooManyDecimals MoneyErrorReason = "too_many_decimal_places"
+	MoneyNotPositive     MoneyErrorReason = "not_positive"
+)
+
+// Description is a short, client-facing phrase for the reason, e.g. "must be positive".
+func (r MoneyErrorReason) Description() string {
+	switch r {
+	case MoneyEmpty:
+		return "is required"
+	case MoneyNotNumeric:
+		return "must be a valid decimal number"
+	case MoneyNotFinite:
+		return "must be a finite number"
+	case MoneyTooManyDecimals:
+		return fmt.Sprintf("has more than %d decimal places; amounts are never rounded or truncated", MaxMoneyScale())
+	case MoneyNotPositive:
+		return "must be positive"
+	}
+	return "is invalid"
+}
+
+// MoneyError is returned by ParseMoney and ParseAmount for input that isn't a usable amount.
+type MoneyError struct {
+	Input  string
+	Reason MoneyErrorReason
+
+	// Field is the request field the amount came from, when known (see InvalidAmountError).
+	Field string
+}
+
+func (e *MoneyError) Error() string {
+	return fmt.Sprintf("invalid amount %q: %s", e.Input, e.Reason.Description())
+}
+
+// ParseMoney parses a decimal amount of any sign. It fails with a *MoneyError for empty
+// or non-numeric input (including NaN and Infinity), amounts too large to be finite as a
+// float64, or input that fails ValidateMoneyScale.
 func ParseMoney(s string) (decimal.Decimal, error) {
 	if s == "" {
-		return decimal.Decimal{}, fmt.Errorf("empty amount string")
+		return decimal.Decimal{}, &MoneyError{Input: s, Reason: MoneyEmpty}
 	}
 	d, err := decimal.NewFromString(s)
 	if err != nil {
-		return decimal.Decimal{}, fmt.Errorf("invalid decimal %q: %w", s, err)
+		return decimal.Decimal{}, &MoneyError{Input: s, Reason: MoneyNotNumeric}
+	}
+	if !isFiniteMoney(d) {
+		return decimal.Decimal{}, &MoneyError{Input: s, Reason: MoneyNotFinite}
+	}
+	if ValidateMoneyScale(d) != nil {
+		return decimal.Decimal{}, &MoneyError{Input: s, Reason: MoneyTooManyDecimals}
 	}
 	return d, nil
 }
 
+// ValidateMoneyScale rejects d if it has non-zero digits beyond MaxMoneyScale decimal
+// places. Amounts are never rounded or truncated to fit; trailing zeros are ignored, so
+// "100.000" passes at scale 2 but "100.001" does not.
+func ValidateMoneyScale(d decimal.Decimal) error {
+	if scale := MaxMoneyScale(); !d.Equal(d.Truncate(scale)) {
+		return &MoneyError{Input: d.String(), Reason: MoneyTooManyDecimals}
+	}
+	return nil
+}
+
+// isFiniteMoney reports whether d is below 10^maxMoneyExponent in magnitude. It looks at
+// the digit count and exponent only, so it never expands a huge exponent.
+func isFiniteMoney(d decimal.Decimal) bool {
+	return d.IsZero() || d.NumDigits()+int(d.Exponent()) <= maxMoneyExponent
+}
+
+// ParseAmount is ParseMoney for amounts that must be greater than zero, such as transfers.
+func ParseAmount(s string) (decimal.Decimal, error) {
+	d, err := ParseMoney(s)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if !d.IsPositive() {
+		return decimal.Decimal{}, &MoneyError{Input: s, Reason: MoneyNotPositive}
+	}
+	return d, nil
+}
+
+// InvalidAmountError turns a ParseMoney or ParseAmount failure for field into an
+// ErrInvalidAmount whose message names the field and reason, e.g. "amount must be positive".
+// The *MoneyError stays reachable with errors.As for clients that need the reason code.
+func InvalidAmountError(field string, err error) *DomainError {
+	var moneyErr *MoneyError
+	if !errors.As(err, &moneyErr) {
+		return ErrInvalidAmount
+	}
+	moneyErr.Field = field
+	return WrapError(CodeInvalidAmount, field+" "+moneyErr.Reason.Description(), moneyErr)
+}
+
+// FormatMoney formats d at its stored precision with trailing zeros dropped, e.g. "150.5".
 func FormatMoney(d decimal.Decimal) string {
 	return d.String()
 }
+
+// FormatMoneyFixed formats d with at least scale decimal places, e.g. "150.50" and
+// "100.00" at scale 2, or "1500" at scale 0 for a currency without minor units. Unlike
+// FormatMoneyScale it never rounds: digits beyond scale are kept, so a display scale below
+// MaxMoneyScale can't misstate a stored amount.
+func FormatMoneyFixed(d decimal.Decimal, scale int) string {
+	if !d.Equal(d.Truncate(int32(scale))) {
+		return d.String()
+	}
+	return d.StringFixed(int32(scale))
+}
+
+// FormatMoneyScale formats d with exactly scale decimal places, rounding half away from
+// zero or padding with zeros. It only affects display; callers keep the stored value.
+func FormatMoneyScale(d decimal.Decimal, scale int32) string {
+	return d.StringFixed(scale)
+}
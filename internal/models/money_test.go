@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/shopspring/decimal"
@@ -20,6 +21,16 @@ func TestParseMoney(t *testing.T) {
 		{"", "", true},
 		{"abc", "", true},
 		{"$100", "", true},
+		{"100.00", "100", false},
+		{"100.000", "100", false},
+		{"100.001", "", true},
+		{"100.999", "", true},
+		{"Inf", "", true},
+		{"-Infinity", "", true},
+		{"NaN", "", true},
+		{"1e9999", "", true},
+		{"-1e9999", "", true},
+		{"1e307", "1e307", false},
 	}
 
 	for _, tt := range tests {
@@ -39,6 +50,84 @@ func TestParseMoney(t *testing.T) {
 	}
 }
 
+func TestValidateMoneyScale(t *testing.T) {
+	tests := []struct {
+		scale   int
+		input   string
+		wantErr bool
+	}{
+		{2, "100.00", false},
+		{2, "0.01", false},
+		{2, "100.001", true},
+		{2, "-0.005", true},
+		{0, "100", false},
+		{0, "0.5", true},
+		{4, "100.0001", false},
+		{4, "100.00001", true},
+	}
+
+	defer SetMaxMoneyScale(DefaultMaxMoneyScale)
+	for _, tt := range tests {
+		if err := SetMaxMoneyScale(tt.scale); err != nil {
+			t.Fatalf("set scale %d: %v", tt.scale, err)
+		}
+		err := ValidateMoneyScale(decimal.RequireFromString(tt.input))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("scale %d, %s: wantErr=%v, got %v", tt.scale, tt.input, tt.wantErr, err)
+		}
+	}
+
+	if err := SetMaxMoneyScale(LimitMoneyScale + 1); err == nil {
+		t.Error("expected an error for a scale above the limit")
+	}
+	if err := SetMaxMoneyScale(-1); err == nil {
+		t.Error("expected an error for a negative scale")
+	}
+}
+
+func TestParseAmount_Reasons(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantReason  MoneyErrorReason
+		wantMessage string
+	}{
+		{"", MoneyEmpty, "is required"},
+		{"abc", MoneyNotNumeric, "must be a valid decimal number"},
+		{"NaN", MoneyNotNumeric, "must be a valid decimal number"},
+		{"Inf", MoneyNotNumeric, "must be a valid decimal number"},
+		{"1e9999", MoneyNotFinite, "must be a finite number"},
+		{"100.999", MoneyTooManyDecimals, "has more than 2 decimal places; amounts are never rounded or truncated"},
+		{"-5", MoneyNotPositive, "must be positive"},
+		{"0", MoneyNotPositive, "must be positive"},
+		{"0.01", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseAmount(tt.input)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var moneyErr *MoneyError
+			if !errors.As(err, &moneyErr) || moneyErr.Reason != tt.wantReason {
+				t.Fatalf("expected reason %s, got %v", tt.wantReason, err)
+			}
+
+			wrapped := InvalidAmountError("amount", err)
+			if !errors.Is(wrapped, ErrInvalidAmount) {
+				t.Errorf("expected wrapped error to match ErrInvalidAmount")
+			}
+			if wrapped.Message != "amount "+tt.wantMessage {
+				t.Errorf("expected message %q, got %q", "amount "+tt.wantMessage, wrapped.Message)
+			}
+		})
+	}
+}
+
 func TestFormatMoney(t *testing.T) {
 	tests := []struct {
 		input decimal.Decimal
@@ -55,3 +144,46 @@ func TestFormatMoney(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatMoneyScale(t *testing.T) {
+	tests := []struct {
+		input string
+		scale int32
+		want  string
+	}{
+		{"100", 2, "100.00"},
+		{"100.125", 2, "100.13"},
+		{"-100.125", 2, "-100.13"},
+		{"100.5", 0, "101"},
+		{"0.1", 4, "0.1000"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatMoneyScale(decimal.RequireFromString(tt.input), tt.scale); got != tt.want {
+			t.Errorf("FormatMoneyScale(%s, %d) = %s, want %s", tt.input, tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMoneyFixed(t *testing.T) {
+	tests := []struct {
+		input string
+		scale int
+		want  string
+	}{
+		{"150.5", 2, "150.50"},
+		{"100", 2, "100.00"},
+		{"-0.5", 2, "-0.50"},
+		{"0", 2, "0.00"},
+		{"100.125", 2, "100.125"},
+		{"1500", 0, "1500"},
+		{"1500.00", 0, "1500"},
+		{"1500.5", 0, "1500.5"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatMoneyFixed(decimal.RequireFromString(tt.input), tt.scale); got != tt.want {
+			t.Errorf("FormatMoneyFixed(%s, %d) = %s, want %s", tt.input, tt.scale, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// ExpectedBalance is one account's authoritative balance in a reconciliation import.
+type ExpectedBalance struct {
+	AccountID int64
+	Balance   decimal.Decimal
+}
+
+// ReconciliationStatus is the outcome of reconciling one account.
+type ReconciliationStatus string
+
+const (
+	// ReconciliationMatched means the stored balance already equals the expected one.
+	ReconciliationMatched ReconciliationStatus = "matched"
+
+	// ReconciliationMismatched means the balances differ and nothing was changed.
+	ReconciliationMismatched ReconciliationStatus = "mismatched"
+
+	// ReconciliationCorrected means the balances differed and an adjustment set the
+	// stored balance to the expected one.
+	ReconciliationCorrected ReconciliationStatus = "corrected"
+
+	// ReconciliationMissing means no account has the imported ID.
+	ReconciliationMissing ReconciliationStatus = "missing"
+)
+
+// ReconciliationItem compares one account's stored balance with its expected balance.
+//
+// Business rules:
+//   - Stored is the balance before any correction; it is zero for a missing account
+//   - Adjustment is set only when Status is ReconciliationCorrected
+type ReconciliationItem struct {
+	AccountID  int64
+	Expected   decimal.Decimal
+	Stored     decimal.Decimal
+	Status     ReconciliationStatus
+	Adjustment *BalanceAdjustment
+}
+
+// Difference returns how far the stored balance was from the expected one, as the amount
+// that had to be added to it: Expected - Stored.
+func (i *ReconciliationItem) Difference() decimal.Decimal {
+	return i.Expected.Sub(i.Stored)
+}
+
+// Reconciliation is the discrepancy report of a reconciliation import, one item per
+// imported account in ascending account ID order.
+type Reconciliation struct {
+	// Applied says whether corrections were requested. When it is set and any account
+	// was corrected, BatchID identifies the adjustment batch that holds them.
+	Applied bool
+	BatchID int64
+	Reason  string
+	Items   []*ReconciliationItem
+}
+
+// Count returns how many items have status.
+func (r *Reconciliation) Count(status ReconciliationStatus) int {
+	n := 0
+	for _, item := range r.Items {
+		if item.Status == status {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MinRecurringInterval is the shortest schedule a recurring transfer may have.
+const MinRecurringInterval = time.Minute
+
+// RecurringStatus is the lifecycle state of a recurring transfer.
+type RecurringStatus string
+
+const (
+	// RecurringStatusActive schedules are run by the scheduler when NextRunAt is due.
+	RecurringStatusActive RecurringStatus = "active"
+
+	// RecurringStatusCompleted schedules have passed their EndAt.
+	RecurringStatusCompleted RecurringStatus = "completed"
+
+	// RecurringStatusCanceled schedules were stopped by a client.
+	RecurringStatusCanceled RecurringStatus = "canceled"
+)
+
+// RecurringTransfer moves Amount from the source to the destination account every
+// Every, starting at its first NextRunAt, until EndAt or until it is canceled.
+//
+// Business rules:
+//   - Each run is an ordinary transfer, subject to the same balance and limit checks
+//   - A run that is rejected (e.g. insufficient balance) is recorded in LastError and
+//     skipped; the schedule carries on
+//   - Only active schedules run or can be canceled
+type RecurringTransfer struct {
+	// RecurringID is the unique identifier, auto-generated by the database.
+	RecurringID int64 `db:"recurring_id" id:"true" json:"recurring_id"`
+
+	// SourceAccountID is the account debited by every run.
+	SourceAccountID int64 `db:"source_account_id" json:"source_account_id"`
+
+	// DestinationAccountID is the account credited by every run.
+	DestinationAccountID int64 `db:"destination_account_id" json:"destination_account_id"`
+
+	// Amount is moved by every run.
+	Amount decimal.Decimal `db:"amount" json:"amount"`
+
+	// Category labels every run's transaction, or is empty.
+	Category string `db:"category" json:"category,omitempty"`
+
+	// Every is the time between runs, in whole seconds.
+	Every time.Duration `db:"interval_seconds" json:"every"`
+
+	// NextRunAt is when the next run is due.
+	NextRunAt time.Time `db:"next_run_at" json:"next_run_at"`
+
+	// EndAt is the last instant a run may be due, or nil to run until canceled.
+	EndAt *time.Time `db:"end_at" json:"end_at,omitempty"`
+
+	// Status is active, completed, or canceled.
+	Status RecurringStatus `db:"status" json:"status"`
+
+	// Runs counts the runs attempted, rejected ones included.
+	Runs int `db:"runs" json:"runs"`
+
+	// LastRunAt is when the latest run was attempted, or nil before the first.
+	LastRunAt *time.Time `db:"last_run_at" json:"last_run_at,omitempty"`
+
+	// LastTransactionID is the transfer made by the latest run, or nil if it was rejected.
+	LastTransactionID *int64 `db:"last_transaction_id" json:"last_transaction_id,omitempty"`
+
+	// LastError is why the latest run was rejected, or empty if it succeeded.
+	LastError string `db:"last_error" json:"last_error,omitempty"`
+
+	// CreatedAt is when the schedule was created.
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+
+	// CanceledAt is when the schedule was canceled, or nil.
+	CanceledAt *time.Time `db:"canceled_at" json:"canceled_at,omitempty"`
+}
+
+// TableName returns the database table name for RecurringTransfer.
+func (r RecurringTransfer) TableName() string {
+	return "recurring_transfers"
+}
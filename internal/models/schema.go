@@ -0,0 +1,10 @@
+package models
+
+// SchemaVersion is the database migration state recorded by golang-migrate.
+type SchemaVersion struct {
+	// Version is the number of the last applied migration (e.g. 10 for 000010_*.sql).
+	Version int64 `json:"version"`
+
+	// Dirty is set when a migration failed part-way and the schema needs manual repair.
+	Dirty bool `json:"dirty"`
+}
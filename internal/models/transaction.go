@@ -6,31 +6,185 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-// Transaction represents a completed money transfer between two accounts.
+// DateLayout is the wire format for calendar dates such as a transaction's effective date.
+const DateLayout = "2006-01-02"
+
+// TimestampLayout is the wire format for instants such as created_at: RFC 3339 with a
+// fixed six fractional digits, matching Postgres' microsecond precision. Timestamps are
+// written in UTC, so they sort correctly as strings.
+const TimestampLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// TransactionType distinguishes peer transfers from single-sided balance changes.
+type TransactionType string
+
+const (
+	// TransactionTypeTransfer moves funds between two accounts.
+	TransactionTypeTransfer TransactionType = "transfer"
+
+	// TransactionTypeDeposit credits an account from outside the system (no source).
+	TransactionTypeDeposit TransactionType = "deposit"
+
+	// TransactionTypeWithdrawal debits an account to outside the system (no destination).
+	TransactionTypeWithdrawal TransactionType = "withdrawal"
+)
+
+// TransactionStatus is where a transaction is in its lifecycle.
+type TransactionStatus string
+
+const (
+	// TransactionStatusPending transactions are recorded but not yet applied. It is the
+	// database default, reserved for queued transfers.
+	TransactionStatusPending TransactionStatus = "pending"
+
+	// TransactionStatusCompleted transactions moved their funds.
+	TransactionStatusCompleted TransactionStatus = "completed"
+
+	// TransactionStatusFailed transactions record a transfer that failed for a system
+	// reason (e.g. a database error) rather than a rejection. They moved no funds.
+	TransactionStatusFailed TransactionStatus = "failed"
+)
+
+// FeePayer says which side of a transfer pays its fee.
+type FeePayer string
+
+const (
+	// FeePayerSource debits the fee from the source on top of the amount; the destination
+	// receives the full amount.
+	FeePayerSource FeePayer = "source"
+
+	// FeePayerDestination takes the fee out of the amount; the destination receives the
+	// amount less the fee.
+	FeePayerDestination FeePayer = "destination"
+)
+
+// Transaction represents a balance change: a transfer between two accounts, or a
+// single-sided deposit or withdrawal. Its Status says whether the funds actually moved.
 // Once created, transactions are immutable and serve as an audit trail.
 //
 // Business rules:
 //   - TransactionID is auto-generated by the database
 //   - Amount must be positive (enforced at database level)
 //   - Source and destination must be different accounts
-//   - Both source and destination accounts must exist
+//   - Transfers have both sides; deposits have no source and withdrawals no destination
+//     (0 in Go, NULL in the database)
+//   - Every account referenced must exist
+//   - IdempotencyKey, when set, identifies at most one transaction
+//   - A transaction is reversed at most once (ReversalOf is unique among transactions
+//     that didn't fail)
+//   - Failed transactions carry no IdempotencyKey, so the key stays free for a retry
+//   - A transfer with a fee names the fee account and who paid; without one, FeeAmount
+//     is zero and neither is set
 //
 // Uses db tags for go-kit/pgx reflection-based CRUD operations.
 type Transaction struct {
 	// TransactionID is the unique identifier, auto-generated by the database.
 	TransactionID int64 `db:"transaction_id" id:"true" json:"transaction_id"`
 
-	// SourceAccountID is the account from which funds are transferred.
+	// Type is transfer, deposit, or withdrawal. Empty is treated as transfer on insert.
+	Type TransactionType `db:"type" json:"type"`
+
+	// SourceAccountID is the account from which funds are transferred, or 0 for a deposit.
 	SourceAccountID int64 `db:"source_account_id" json:"source_account_id"`
 
-	// DestinationAccountID is the account to which funds are transferred.
+	// DestinationAccountID is the account to which funds are transferred, or 0 for a withdrawal.
 	DestinationAccountID int64 `db:"destination_account_id" json:"destination_account_id"`
 
 	// Amount is the transfer amount. Uses decimal.Decimal for precision.
 	Amount decimal.Decimal `db:"amount" json:"amount"`
 
 	// CreatedAt is the timestamp when the transaction was created.
+	// This is the immutable system timestamp.
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
+
+	// EffectiveDate is the bookkeeping date the transfer applies to (UTC, midnight).
+	// Defaults to the creation date but may be back-dated; statements filter on it.
+	EffectiveDate time.Time `db:"effective_date" json:"effective_date"`
+
+	// IdempotencyKey is the client-supplied key the transfer was created with, if any.
+	// Unique across all transactions.
+	IdempotencyKey string `db:"idempotency_key" json:"-"`
+
+	// ReversalOf is the ID of the transaction this one compensates, or nil for
+	// an ordinary transfer.
+	ReversalOf *int64 `db:"reversal_of" json:"reversal_of,omitempty"`
+
+	// Category is an optional client-supplied label (e.g. "payroll") used for reporting.
+	// Empty means uncategorized.
+	Category string `db:"category" json:"category,omitempty"`
+
+	// Status is pending, completed, or failed. Only completed transactions moved funds.
+	// Empty is treated as pending on insert.
+	Status TransactionStatus `db:"status" json:"status"`
+
+	// FeeAmount is the fee charged on top of (or out of) Amount and credited to
+	// FeeAccountID. Zero when no fee was charged.
+	FeeAmount decimal.Decimal `db:"fee_amount" json:"fee_amount"`
+
+	// FeeAccountID is the account the fee was credited to, or 0 without a fee.
+	FeeAccountID int64 `db:"fee_account_id" json:"fee_account_id,omitempty"`
+
+	// FeePaidBy is the side that paid the fee, or empty without a fee.
+	FeePaidBy FeePayer `db:"fee_paid_by" json:"fee_paid_by,omitempty"`
+
+	// Sequence is the client's sequence number for the source account, or 0 if none was
+	// sent. It is recorded on the account as LastSequence, not on the transaction.
+	Sequence int64 `db:"-" json:"-"`
+
+	// Replayed is set when the transaction is returned for a repeated idempotency key
+	// rather than newly created. It is not persisted.
+	Replayed bool `db:"-" json:"-"`
+}
+
+// DailyOutbound is what an account has sent in transfers so far today, with its own
+// daily limits where it overrides the configured ones. The day is the UTC calendar day
+// by the database clock and ends at ResetsAt.
+type DailyOutbound struct {
+	Amount decimal.Decimal
+	Count  int64
+
+	// AmountLimit and CountLimit are the account's overrides, unset when it uses the
+	// configured limits. Zero lifts a limit for the account.
+	AmountLimit decimal.NullDecimal
+	CountLimit  *int64
+
+	ResetsAt time.Time
+}
+
+// CategoryTotal aggregates an account's transactions with one category over a period.
+// Spent sums transactions debiting the account and Received those crediting it.
+type CategoryTotal struct {
+	// Category is the label, or empty for uncategorized transactions.
+	Category string
+	Spent    decimal.Decimal
+	Received decimal.Decimal
+	Count    int64
+}
+
+// TransactionFilter narrows an account's transaction history, e.g. for an audit
+// statement. From and To bound CreatedAt and MinAmount and MaxAmount bound Amount; every
+// bound is inclusive, and a zero time or unset amount doesn't filter.
+type TransactionFilter struct {
+	From      time.Time
+	To        time.Time
+	MinAmount decimal.NullDecimal
+	MaxAmount decimal.NullDecimal
+}
+
+// IsZero reports whether f filters nothing.
+func (f TransactionFilter) IsZero() bool {
+	return f.From.IsZero() && f.To.IsZero() && !f.MinAmount.Valid && !f.MaxAmount.Valid
+}
+
+// Matches reports whether txn falls within every bound of f.
+func (f TransactionFilter) Matches(txn *Transaction) bool {
+	switch {
+	case !f.From.IsZero() && txn.CreatedAt.Before(f.From),
+		!f.To.IsZero() && txn.CreatedAt.After(f.To),
+		f.MinAmount.Valid && txn.Amount.LessThan(f.MinAmount.Decimal),
+		f.MaxAmount.Valid && txn.Amount.GreaterThan(f.MaxAmount.Decimal):
+		return false
+	}
+	return true
 }
 
 // TableName returns the database table name for Transaction.
@@ -0,0 +1,18 @@
+package models
+
+// Warning is a non-fatal notice returned alongside a successful response, for input that
+// was accepted but looks like a likely mistake.
+type Warning struct {
+	// Code is a machine-readable identifier, e.g. "large_initial_balance".
+	Code string `json:"code"`
+
+	// Field is the request field the warning is about, when there is one.
+	Field string `json:"field,omitempty"`
+
+	// Message is a human-readable explanation.
+	Message string `json:"message"`
+}
+
+// WarningLargeInitialBalance flags an account created with an initial balance above the
+// configured warning threshold.
+const WarningLargeInitialBalance = "large_initial_balance"
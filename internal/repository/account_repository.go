@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"internal-transfers-system/internal/consistency"
 	"internal-transfers-system/internal/interfaces"
 	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/tracing"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -19,24 +22,49 @@ var _ interfaces.AccountRepository = (*AccountRepository)(nil)
 // AccountRepository provides data access operations for accounts.
 // All methods are safe for concurrent use.
 type AccountRepository struct {
-	db *pgxpool.Pool
+	pools          Pools
+	isolationLevel pgx.TxIsoLevel
 }
 
 // NewAccountRepository creates a new AccountRepository with the given connection pool.
+// Transactions run at READ COMMITTED.
 func NewAccountRepository(db *pgxpool.Pool) *AccountRepository {
-	return &AccountRepository{db: db}
+	return NewAccountRepositoryWithIsolation(db, pgx.ReadCommitted)
+}
+
+// NewAccountRepositoryWithIsolation creates an AccountRepository whose transactions run
+// at level. An empty level means READ COMMITTED.
+func NewAccountRepositoryWithIsolation(db *pgxpool.Pool, level pgx.TxIsoLevel) *AccountRepository {
+	return NewAccountRepositoryWithPools(SinglePool(db), level)
+}
+
+// NewAccountRepositoryWithPools creates an AccountRepository that runs transactions and
+// writes on pools.Transfer, lookups on pools.Read, ListAfter on pools.Export, and GetByID,
+// GetByIDs, and Exists on pools.Replica.
+func NewAccountRepositoryWithPools(pools Pools, level pgx.TxIsoLevel) *AccountRepository {
+	if level == "" {
+		level = pgx.ReadCommitted
+	}
+	return &AccountRepository{pools: pools.withDefaults(), isolationLevel: level}
 }
 
 // Create inserts a new account into the database.
 // The account's CreatedAt and UpdatedAt fields are populated from the database.
 // Returns an error if the account already exists (duplicate key) or on database failure.
-func (r *AccountRepository) Create(ctx context.Context, account *models.Account) error {
+func (r *AccountRepository) Create(ctx context.Context, account *models.Account) (err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.Create", tracing.AttrAccountID.Int64(account.AccountID))
+	defer func() { tracing.End(span, err) }()
+
 	query := `
-		INSERT INTO accounts (account_id, balance, created_at, updated_at)
-		VALUES ($1, $2, NOW(), NOW())
+		INSERT INTO accounts (account_id, account_type, balance, initial_balance, max_balance, overdraft_limit, created_at, updated_at)
+		VALUES ($1, $2, $3, $3, $4, $5, NOW(), NOW())
 		RETURNING created_at, updated_at`
 
-	err := r.db.QueryRow(ctx, query, account.AccountID, account.Balance).
+	if account.AccountType == "" {
+		account.AccountType = models.DefaultAccountType
+	}
+
+	err = r.pools.Transfer.QueryRow(ctx, query, account.AccountID, account.AccountType, account.Balance, account.MaxBalance, account.OverdraftLimit).
 		Scan(&account.CreatedAt, &account.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("insert account %d: %w", account.AccountID, err)
@@ -44,17 +72,245 @@ func (r *AccountRepository) Create(ctx context.Context, account *models.Account)
 	return nil
 }
 
+// maxGeneratedIDAttempts bounds how many taken sequence values CreateWithGeneratedID
+// skips before giving up.
+const maxGeneratedIDAttempts = 100
+
+// CreateWithGeneratedID inserts a new account whose ID comes from accounts_account_id_seq.
+// A sequence value a client already claimed conflicts and inserts nothing, so the insert
+// is retried with the next value.
+func (r *AccountRepository) CreateWithGeneratedID(ctx context.Context, account *models.Account) (err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.CreateWithGeneratedID")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		INSERT INTO accounts (account_type, balance, initial_balance, max_balance, overdraft_limit, created_at, updated_at)
+		VALUES ($1, $2, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (account_id) DO NOTHING
+		RETURNING account_id, created_at, updated_at`
+
+	if account.AccountType == "" {
+		account.AccountType = models.DefaultAccountType
+	}
+
+	for attempt := 0; attempt < maxGeneratedIDAttempts; attempt++ {
+		err = r.pools.Transfer.QueryRow(ctx, query, account.AccountType, account.Balance, account.MaxBalance, account.OverdraftLimit).
+			Scan(&account.AccountID, &account.CreatedAt, &account.UpdatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("insert account with generated ID: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("insert account with generated ID: no free ID after %d attempts", maxGeneratedIDAttempts)
+}
+
+// CreateBatch inserts accounts within tx and reports which were created. Client-supplied
+// IDs go in the first round; generated IDs are drawn from the sequence each round, and
+// any that turn out to be taken are drawn again, up to maxGeneratedIDAttempts rounds.
+func (r *AccountRepository) CreateBatch(ctx context.Context, tx pgx.Tx, accounts []*models.Account) (_ []bool, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.CreateBatch")
+	defer func() { tracing.End(span, err) }()
+
+	created := make([]bool, len(accounts))
+	generated := make([]bool, len(accounts))
+	taken := make(map[int64]bool, len(accounts))
+	var batch, pending []int
+	for i, account := range accounts {
+		if account.AccountType == "" {
+			account.AccountType = models.DefaultAccountType
+		}
+		if account.AccountID == 0 {
+			generated[i] = true
+			pending = append(pending, i)
+			continue
+		}
+		taken[account.AccountID] = true
+		batch = append(batch, i)
+	}
+
+	for attempt := 0; len(batch) > 0 || len(pending) > 0; attempt++ {
+		if attempt == maxGeneratedIDAttempts {
+			return nil, fmt.Errorf("insert account batch: no free ID after %d attempts", maxGeneratedIDAttempts)
+		}
+
+		// A generated ID equal to another account's in this batch waits for the next round,
+		// so every ID within one INSERT is distinct
+		var retry []int
+		if len(pending) > 0 {
+			ids, err := r.nextAccountIDs(ctx, tx, len(pending))
+			if err != nil {
+				return nil, err
+			}
+			for k, i := range pending {
+				if taken[ids[k]] {
+					retry = append(retry, i)
+					continue
+				}
+				taken[ids[k]] = true
+				accounts[i].AccountID = ids[k]
+				batch = append(batch, i)
+			}
+		}
+
+		inserted, err := r.insertAccounts(ctx, tx, accounts, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range batch {
+			if times, ok := inserted[accounts[i].AccountID]; ok {
+				accounts[i].CreatedAt, accounts[i].UpdatedAt = times[0], times[1]
+				created[i] = true
+			} else if generated[i] {
+				retry = append(retry, i)
+			}
+		}
+		batch, pending = nil, retry
+	}
+	return created, nil
+}
+
+// nextAccountIDs draws n values from the account ID sequence.
+func (r *AccountRepository) nextAccountIDs(ctx context.Context, tx pgx.Tx, n int) ([]int64, error) {
+	rows, err := tx.Query(ctx, `SELECT nextval('accounts_account_id_seq') FROM generate_series(1, $1)`, n)
+	if err != nil {
+		return nil, fmt.Errorf("draw %d account IDs: %w", n, err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("draw %d account IDs: %w", n, err)
+	}
+	return ids, nil
+}
+
+// insertAccounts inserts accounts[i] for every i in batch with a single INSERT, skipping
+// taken IDs, and returns the created and updated times of each inserted ID.
+func (r *AccountRepository) insertAccounts(ctx context.Context, tx pgx.Tx, accounts []*models.Account, batch []int) (map[int64][2]time.Time, error) {
+	if len(batch) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		INSERT INTO accounts (account_id, account_type, balance, initial_balance, max_balance, overdraft_limit, created_at, updated_at)
+		SELECT a.account_id, a.account_type, a.balance::numeric, a.balance::numeric, a.max_balance::numeric, a.overdraft_limit::numeric, NOW(), NOW()
+		FROM unnest($1::bigint[], $2::text[], $3::text[], $4::text[], $5::text[])
+		     AS a(account_id, account_type, balance, max_balance, overdraft_limit)
+		ON CONFLICT (account_id) DO NOTHING
+		RETURNING account_id, created_at, updated_at`
+
+	ids := make([]int64, len(batch))
+	types := make([]string, len(batch))
+	balances := make([]string, len(batch))
+	maxBalances := make([]*string, len(batch))
+	overdraftLimits := make([]string, len(batch))
+	for k, i := range batch {
+		account := accounts[i]
+		ids[k] = account.AccountID
+		types[k] = account.AccountType
+		balances[k] = account.Balance.String()
+		if account.MaxBalance.Valid {
+			maxBalance := account.MaxBalance.Decimal.String()
+			maxBalances[k] = &maxBalance
+		}
+		overdraftLimits[k] = account.OverdraftLimit.String()
+	}
+
+	rows, err := tx.Query(ctx, query, ids, types, balances, maxBalances, overdraftLimits)
+	if err != nil {
+		return nil, fmt.Errorf("insert %d accounts: %w", len(batch), err)
+	}
+	defer rows.Close()
+
+	inserted := make(map[int64][2]time.Time, len(batch))
+	for rows.Next() {
+		var id int64
+		var times [2]time.Time
+		if err := rows.Scan(&id, &times[0], &times[1]); err != nil {
+			return nil, fmt.Errorf("scan inserted account row: %w", err)
+		}
+		inserted[id] = times
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("insert %d accounts: %w", len(batch), err)
+	}
+	return inserted, nil
+}
+
 // GetByID retrieves an account by its ID.
 // Returns ErrAccountNotFound if the account does not exist.
-func (r *AccountRepository) GetByID(ctx context.Context, accountID int64) (*models.Account, error) {
+func (r *AccountRepository) GetByID(ctx context.Context, accountID int64) (_ *models.Account, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.GetByID", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
 	query := `
-		SELECT account_id, balance, created_at, updated_at
+		SELECT account_id, account_type, balance, initial_balance, held_balance, overdraft_limit, max_balance, last_sequence, version, closed_at, created_at, updated_at
 		FROM accounts
 		WHERE account_id = $1`
 
 	account := &models.Account{}
-	err := r.db.QueryRow(ctx, query, accountID).
-		Scan(&account.AccountID, &account.Balance, &account.CreatedAt, &account.UpdatedAt)
+	err = r.pools.replicaFor(ctx).QueryRow(ctx, query, accountID).
+		Scan(&account.AccountID, &account.AccountType, &account.Balance, &account.InitialBalance, &account.HeldBalance, &account.OverdraftLimit, &account.MaxBalance, &account.LastSequence, &account.Version, &account.ClosedAt, &account.CreatedAt, &account.UpdatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get account %d: %w", accountID, err)
+	}
+	return account, nil
+}
+
+// GetByIDs retrieves the accounts among ids that exist, ordered by account ID, in one
+// query. Missing IDs are left out rather than failing the call.
+func (r *AccountRepository) GetByIDs(ctx context.Context, ids []int64) (_ []*models.Account, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.GetByIDs")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT account_id, account_type, balance, initial_balance, held_balance, overdraft_limit, max_balance, last_sequence, version, closed_at, created_at, updated_at
+		FROM accounts
+		WHERE account_id = ANY($1)
+		ORDER BY account_id`
+
+	rows, err := r.pools.replicaFor(ctx).Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get %d accounts: %w", len(ids), err)
+	}
+	defer rows.Close()
+
+	accounts := make([]*models.Account, 0, len(ids))
+	for rows.Next() {
+		account := &models.Account{}
+		if err := rows.Scan(&account.AccountID, &account.AccountType, &account.Balance, &account.InitialBalance, &account.HeldBalance, &account.OverdraftLimit, &account.MaxBalance, &account.LastSequence, &account.Version, &account.ClosedAt, &account.CreatedAt, &account.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan account row: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate account rows: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// GetByIDInTx retrieves an account within tx without locking it, so the read sees the
+// primary and anything tx has already written.
+// Returns ErrAccountNotFound if the account does not exist.
+func (r *AccountRepository) GetByIDInTx(ctx context.Context, tx pgx.Tx, accountID int64) (_ *models.Account, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.GetByIDInTx", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT account_id, account_type, balance, initial_balance, held_balance, overdraft_limit, max_balance, last_sequence, version, closed_at, created_at, updated_at
+		FROM accounts
+		WHERE account_id = $1`
+
+	account := &models.Account{}
+	err = tx.QueryRow(ctx, query, accountID).
+		Scan(&account.AccountID, &account.AccountType, &account.Balance, &account.InitialBalance, &account.HeldBalance, &account.OverdraftLimit, &account.MaxBalance, &account.LastSequence, &account.Version, &account.ClosedAt, &account.CreatedAt, &account.UpdatedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, models.ErrAccountNotFound
@@ -75,16 +331,19 @@ func (r *AccountRepository) GetByID(ctx context.Context, accountID int64) (*mode
 //   - Balance consistency is maintained during multi-step operations
 //
 // Returns ErrAccountNotFound if the account does not exist.
-func (r *AccountRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, accountID int64) (*models.Account, error) {
+func (r *AccountRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, accountID int64) (_ *models.Account, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.GetByIDForUpdate", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
 	query := `
-		SELECT account_id, balance, created_at, updated_at
+		SELECT account_id, account_type, balance, initial_balance, held_balance, overdraft_limit, max_balance, last_sequence, version, closed_at, created_at, updated_at
 		FROM accounts
 		WHERE account_id = $1
 		FOR UPDATE`
 
 	account := &models.Account{}
-	err := tx.QueryRow(ctx, query, accountID).
-		Scan(&account.AccountID, &account.Balance, &account.CreatedAt, &account.UpdatedAt)
+	err = tx.QueryRow(ctx, query, accountID).
+		Scan(&account.AccountID, &account.AccountType, &account.Balance, &account.InitialBalance, &account.HeldBalance, &account.OverdraftLimit, &account.MaxBalance, &account.LastSequence, &account.Version, &account.ClosedAt, &account.CreatedAt, &account.UpdatedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, models.ErrAccountNotFound
@@ -95,11 +354,29 @@ func (r *AccountRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, acc
 	return account, nil
 }
 
-// UpdateBalance updates the balance of an account within a transaction.
+// LockAccountAdvisory takes pg_advisory_xact_lock keyed on accountID, waiting while
+// another transaction holds it. The lock is released when tx commits or rolls back.
+// Account IDs are used as lock keys directly, so nothing else in the database should take
+// advisory locks in the same key space.
+func (r *AccountRepository) LockAccountAdvisory(ctx context.Context, tx pgx.Tx, accountID int64) (err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.LockAccountAdvisory", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, accountID); err != nil {
+		return fmt.Errorf("advisory lock account %d: %w", accountID, err)
+	}
+	return nil
+}
+
+// UpdateBalance updates the balance of an account within a transaction and increments its
+// version, so optimistic writers that read the old balance fail their CAS.
 // Returns an error if the update fails or if no rows were affected (account not found).
 // The database CHECK constraint ensures the balance cannot go negative.
-func (r *AccountRepository) UpdateBalance(ctx context.Context, tx pgx.Tx, accountID int64, newBalance decimal.Decimal) error {
-	query := `UPDATE accounts SET balance = $1, updated_at = NOW() WHERE account_id = $2`
+func (r *AccountRepository) UpdateBalance(ctx context.Context, tx pgx.Tx, accountID int64, newBalance decimal.Decimal) (err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.UpdateBalance", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `UPDATE accounts SET balance = $1, version = version + 1, updated_at = NOW() WHERE account_id = $2`
 
 	result, err := tx.Exec(ctx, query, newBalance, accountID)
 	if err != nil {
@@ -118,28 +395,457 @@ func (r *AccountRepository) UpdateBalance(ctx context.Context, tx pgx.Tx, accoun
 	return nil
 }
 
+// UpdateBalanceCAS sets the balance of an account within a transaction only if its version
+// is still expectedVersion, incrementing the version. Under READ COMMITTED a concurrent
+// writer's committed update re-evaluates the WHERE clause, so a stale version matches no
+// row. Returns ErrConcurrentModification when nothing was updated.
+func (r *AccountRepository) UpdateBalanceCAS(ctx context.Context, tx pgx.Tx, accountID int64, newBalance decimal.Decimal, expectedVersion int) (err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.UpdateBalanceCAS", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		UPDATE accounts SET balance = $1, version = version + 1, updated_at = NOW()
+		WHERE account_id = $2 AND version = $3`
+
+	result, err := tx.Exec(ctx, query, newBalance, accountID, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("update balance for account %d at version %d: %w", accountID, expectedVersion, err)
+	}
+	if result.RowsAffected() == 0 {
+		return models.ErrConcurrentModification
+	}
+	return nil
+}
+
+// UpdateLastSequence records sequence as the last accepted transfer sequence for an
+// account within a transaction. The caller must hold the account's row lock and have
+// checked that sequence is greater than the current value.
+func (r *AccountRepository) UpdateLastSequence(ctx context.Context, tx pgx.Tx, accountID int64, sequence int64) (err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.UpdateLastSequence", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `UPDATE accounts SET last_sequence = $1 WHERE account_id = $2`
+
+	result, err := tx.Exec(ctx, query, sequence, accountID)
+	if err != nil {
+		return fmt.Errorf("update last sequence for account %d: %w", accountID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return models.ErrAccountNotFound
+	}
+	return nil
+}
+
+// Close sets the account's closed_at within a transaction and increments its version, so
+// an optimistic transfer that read it while open fails its CAS and sees it closed on retry.
+// Callers lock the account and check it is open first. Returns the closing time, or
+// ErrAccountNotFound.
+func (r *AccountRepository) Close(ctx context.Context, tx pgx.Tx, accountID int64) (_ time.Time, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.Close", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		UPDATE accounts SET closed_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE account_id = $1
+		RETURNING closed_at`
+
+	var closedAt time.Time
+	err = tx.QueryRow(ctx, query, accountID).Scan(&closedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, models.ErrAccountNotFound
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("close account %d: %w", accountID, err)
+	}
+	return closedAt, nil
+}
+
+// UpdateHeldBalance sets the account's held balance within a transaction and increments
+// its version, so an optimistic transfer that read the old available balance fails its CAS.
+// The database CHECK constraint keeps the held balance between zero and the balance.
+func (r *AccountRepository) UpdateHeldBalance(ctx context.Context, tx pgx.Tx, accountID int64, heldBalance decimal.Decimal) (err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.UpdateHeldBalance", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `UPDATE accounts SET held_balance = $1, version = version + 1, updated_at = NOW() WHERE account_id = $2`
+
+	result, err := tx.Exec(ctx, query, heldBalance, accountID)
+	if err != nil {
+		return fmt.Errorf("update held balance for account %d: %w", accountID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return models.ErrAccountNotFound
+	}
+	return nil
+}
+
+// CreateHold inserts an active hold within a transaction. HoldID, Status, and CreatedAt
+// are populated from the database.
+func (r *AccountRepository) CreateHold(ctx context.Context, tx pgx.Tx, hold *models.Hold) (err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.CreateHold", tracing.AttrAccountID.Int64(hold.AccountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		INSERT INTO holds (account_id, amount, status, created_at)
+		VALUES ($1, $2, 'active', NOW())
+		RETURNING hold_id, status, created_at`
+
+	err = tx.QueryRow(ctx, query, hold.AccountID, hold.Amount).
+		Scan(&hold.HoldID, &hold.Status, &hold.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert hold for account %d: %w", hold.AccountID, err)
+	}
+	return nil
+}
+
+// GetHoldForUpdate retrieves a hold with a row-level lock, so concurrent release and
+// capture requests for it are serialized. Returns ErrHoldNotFound if it does not exist.
+func (r *AccountRepository) GetHoldForUpdate(ctx context.Context, tx pgx.Tx, holdID int64) (_ *models.Hold, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.GetHoldForUpdate")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT hold_id, account_id, amount, status, transaction_id, created_at, resolved_at
+		FROM holds
+		WHERE hold_id = $1
+		FOR UPDATE`
+
+	hold := &models.Hold{}
+	err = tx.QueryRow(ctx, query, holdID).
+		Scan(&hold.HoldID, &hold.AccountID, &hold.Amount, &hold.Status, &hold.TransactionID, &hold.CreatedAt, &hold.ResolvedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get hold %d for update: %w", holdID, err)
+	}
+	return hold, nil
+}
+
+// ResolveHold sets a hold's final status, and for a capture its withdrawal, within a
+// transaction. Callers lock the hold and check it is active first. Returns the resolution
+// time, or ErrHoldNotFound.
+func (r *AccountRepository) ResolveHold(ctx context.Context, tx pgx.Tx, holdID int64, status models.HoldStatus, transactionID *int64) (_ time.Time, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.ResolveHold")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		UPDATE holds SET status = $1, transaction_id = $2, resolved_at = NOW()
+		WHERE hold_id = $3
+		RETURNING resolved_at`
+
+	var resolvedAt time.Time
+	err = tx.QueryRow(ctx, query, status, transactionID, holdID).Scan(&resolvedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, models.ErrHoldNotFound
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("resolve hold %d: %w", holdID, err)
+	}
+	return resolvedAt, nil
+}
+
 // Exists checks if an account with the given ID exists.
 // Returns (false, nil) if the account doesn't exist, (true, nil) if it does.
-func (r *AccountRepository) Exists(ctx context.Context, accountID int64) (bool, error) {
+func (r *AccountRepository) Exists(ctx context.Context, accountID int64) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.Exists", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
 	query := `SELECT EXISTS(SELECT 1 FROM accounts WHERE account_id = $1)`
 	var exists bool
-	err := r.db.QueryRow(ctx, query, accountID).Scan(&exists)
+	err = r.pools.replicaFor(ctx).QueryRow(ctx, query, accountID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("check account %d exists: %w", accountID, err)
 	}
 	return exists, nil
 }
 
-// BeginTx starts a new database transaction with READ COMMITTED isolation level.
-// This isolation level prevents dirty reads while allowing better concurrency.
+// ListAfter retrieves up to limit accounts with an ID greater than afterID,
+// ordered by account ID ascending. Pass afterID = 0 to start from the beginning.
+//
+// This is keyset pagination: each page is an index range scan on the primary key,
+// so memory stays flat and no locks are taken regardless of table size.
+//
+// Returns an empty slice once there are no more accounts (not an error).
+func (r *AccountRepository) ListAfter(ctx context.Context, afterID int64, limit int) (_ []*models.Account, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.ListAfter")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT account_id, account_type, balance, max_balance, created_at, updated_at
+		FROM accounts
+		WHERE account_id > $1
+		ORDER BY account_id ASC
+		LIMIT $2`
+
+	rows, err := r.pools.Export.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts after %d: %w", afterID, err)
+	}
+	defer rows.Close()
+
+	accounts := make([]*models.Account, 0, limit)
+
+	for rows.Next() {
+		account := &models.Account{}
+		if err := rows.Scan(
+			&account.AccountID,
+			&account.AccountType,
+			&account.Balance,
+			&account.MaxBalance,
+			&account.CreatedAt,
+			&account.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan account row: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate account rows: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// accountSortColumns whitelists the columns List may order by. ORDER BY can't take a
+// bind parameter, so only these constants ever reach the query text.
+var accountSortColumns = map[models.AccountSortField]string{
+	"":                            "account_id",
+	models.AccountSortByID:        "account_id",
+	models.AccountSortByBalance:   "balance",
+	models.AccountSortByCreatedAt: "created_at",
+}
+
+// List retrieves a page of the accounts matching filter, in its sort order, and the
+// total number of matching accounts. The count and the page are read from one
+// snapshot, so they agree. An unknown sort field is an error.
+func (r *AccountRepository) List(ctx context.Context, filter models.AccountListFilter) (_ []*models.Account, _ int64, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.List")
+	defer func() { tracing.End(span, err) }()
+
+	column, ok := accountSortColumns[filter.SortBy]
+	if !ok {
+		return nil, 0, fmt.Errorf("list accounts: unknown sort field %q", filter.SortBy)
+	}
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+	orderBy := column + " " + direction
+	if column != "account_id" {
+		orderBy += ", account_id " + direction
+	}
+
+	const where = `
+		WHERE ($1::numeric IS NULL OR balance >= $1)
+		  AND ($2::numeric IS NULL OR balance <= $2)`
+
+	tx, err := r.pools.Read.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, 0, fmt.Errorf("begin account listing: %w", err)
+	}
+	defer tx.Rollback(ctx) // read only, nothing to commit
+
+	var total int64
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM accounts`+where, filter.MinBalance, filter.MaxBalance).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count accounts: %w", err)
+	}
+
+	query := `
+		SELECT account_id, account_type, balance, held_balance, overdraft_limit, max_balance, closed_at, created_at, updated_at
+		FROM accounts` + where + `
+		ORDER BY ` + orderBy + `
+		LIMIT $3 OFFSET $4`
+
+	rows, err := tx.Query(ctx, query, filter.MinBalance, filter.MaxBalance, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make([]*models.Account, 0, filter.Limit)
+	for rows.Next() {
+		account := &models.Account{}
+		if err := rows.Scan(
+			&account.AccountID,
+			&account.AccountType,
+			&account.Balance,
+			&account.HeldBalance,
+			&account.OverdraftLimit,
+			&account.MaxBalance,
+			&account.ClosedAt,
+			&account.CreatedAt,
+			&account.UpdatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan account row: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate account rows: %w", err)
+	}
+
+	return accounts, total, nil
+}
+
+// VisibleLSN returns the primary's current WAL position. Every write committed at or
+// before it is visible to this repository's reads.
+func (r *AccountRepository) VisibleLSN(ctx context.Context) (_ consistency.LSN, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.VisibleLSN")
+	defer func() { tracing.End(span, err) }()
+
+	var text string
+	if err := r.pools.Transfer.QueryRow(ctx, `SELECT pg_current_wal_lsn()::text`).Scan(&text); err != nil {
+		return 0, fmt.Errorf("read current WAL position: %w", err)
+	}
+	return consistency.ParseLSN(text)
+}
+
+// NextAdjustmentBatchID allocates a new, never reused batch ID for a bulk balance
+// adjustment. Must be called within the transaction that records the batch.
+func (r *AccountRepository) NextAdjustmentBatchID(ctx context.Context, tx pgx.Tx) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.NextAdjustmentBatchID")
+	defer func() { tracing.End(span, err) }()
+
+	var batchID int64
+	if err := tx.QueryRow(ctx, `SELECT nextval('balance_adjustment_batch_seq')`).Scan(&batchID); err != nil {
+		return 0, fmt.Errorf("allocate adjustment batch ID: %w", err)
+	}
+	return batchID, nil
+}
+
+// CreateAdjustment inserts a balance adjustment audit record within a transaction.
+// The adjustment's AdjustmentID and CreatedAt fields are populated from the database.
+func (r *AccountRepository) CreateAdjustment(ctx context.Context, tx pgx.Tx, adjustment *models.BalanceAdjustment) (err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.CreateAdjustment", tracing.AttrAccountID.Int64(adjustment.AccountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		INSERT INTO balance_adjustments (batch_id, account_id, delta, balance_after, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING adjustment_id, created_at`
+
+	err = tx.QueryRow(ctx, query,
+		adjustment.BatchID,
+		adjustment.AccountID,
+		adjustment.Delta,
+		adjustment.BalanceAfter,
+		adjustment.Reason,
+	).Scan(&adjustment.AdjustmentID, &adjustment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert adjustment for account %d: %w", adjustment.AccountID, err)
+	}
+	return nil
+}
+
+// RecordBalanceChange appends an entry to the account's balance history within tx, the
+// transaction that updated the balance. ChangeID and CreatedAt are populated from the database.
+func (r *AccountRepository) RecordBalanceChange(ctx context.Context, tx pgx.Tx, change *models.BalanceChange) (err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.RecordBalanceChange", tracing.AttrAccountID.Int64(change.AccountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		INSERT INTO balance_history (account_id, old_balance, new_balance, transaction_id, adjustment_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING change_id, created_at`
+
+	err = tx.QueryRow(ctx, query,
+		change.AccountID,
+		change.OldBalance,
+		change.NewBalance,
+		change.TransactionID,
+		change.AdjustmentID,
+	).Scan(&change.ChangeID, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert balance change for account %d: %w", change.AccountID, err)
+	}
+	return nil
+}
+
+// ListBalanceHistory retrieves an account's balance changes, newest first.
+// Returns an empty slice past the end of the history (not an error).
+func (r *AccountRepository) ListBalanceHistory(ctx context.Context, accountID int64, limit, offset int) (_ []*models.BalanceChange, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.ListBalanceHistory", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT change_id, account_id, old_balance, new_balance, transaction_id, adjustment_id, created_at
+		FROM balance_history
+		WHERE account_id = $1
+		ORDER BY change_id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.pools.Read.Query(ctx, query, accountID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list balance history for account %d: %w", accountID, err)
+	}
+	defer rows.Close()
+
+	changes := make([]*models.BalanceChange, 0, limit)
+	for rows.Next() {
+		change := &models.BalanceChange{}
+		if err := rows.Scan(
+			&change.ChangeID,
+			&change.AccountID,
+			&change.OldBalance,
+			&change.NewBalance,
+			&change.TransactionID,
+			&change.AdjustmentID,
+			&change.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan balance change row: %w", err)
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate balance change rows: %w", err)
+	}
+
+	return changes, nil
+}
+
+// TxIsolationLevel reports the isolation level tx is actually running at, as Postgres
+// names it (e.g. "read committed"). Intended for diagnostics only.
+func (r *AccountRepository) TxIsolationLevel(ctx context.Context, tx pgx.Tx) (_ string, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.TxIsolationLevel")
+	defer func() { tracing.End(span, err) }()
+
+	var level string
+	if err := tx.QueryRow(ctx, `SHOW transaction_isolation`).Scan(&level); err != nil {
+		return "", fmt.Errorf("read transaction isolation: %w", err)
+	}
+	return level, nil
+}
+
+// BeginTx starts a new database transaction at the repository's isolation level
+// (READ COMMITTED unless configured otherwise). Under REPEATABLE READ or SERIALIZABLE,
+// statements and COMMIT may fail with serialization failures that callers should retry.
 // The caller is responsible for calling Commit() or Rollback() on the returned transaction.
-func (r *AccountRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
+// If no connection frees up within the pools' AcquireTimeout it fails with ErrServiceBusy.
+func (r *AccountRepository) BeginTx(ctx context.Context) (_ pgx.Tx, err error) {
+	ctx, span := startSpan(ctx, "AccountRepository.BeginTx")
+	defer func() { tracing.End(span, err) }()
+
 	txOptions := pgx.TxOptions{
-		IsoLevel:   pgx.ReadCommitted,
+		IsoLevel:   r.isolationLevel,
 		AccessMode: pgx.ReadWrite,
 	}
-	tx, err := r.db.BeginTx(ctx, txOptions)
+	acquireCtx := ctx
+	if r.pools.AcquireTimeout > 0 {
+		var cancel context.CancelFunc
+		acquireCtx, cancel = context.WithTimeout(ctx, r.pools.AcquireTimeout)
+		defer cancel()
+	}
+	tx, err := r.pools.Transfer.BeginTx(acquireCtx, txOptions)
 	if err != nil {
+		// Every connection stayed busy for the whole acquire timeout, while the caller
+		// still had time left
+		if ctx.Err() == nil && acquireCtx.Err() != nil {
+			return nil, models.ErrServiceBusy
+		}
 		return nil, fmt.Errorf("begin transaction: %w", err)
 	}
 	return tx, nil
@@ -4,6 +4,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -11,6 +13,7 @@ import (
 	"internal-transfers-system/internal/models"
 	"internal-transfers-system/internal/testutil"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shopspring/decimal"
 )
 
@@ -48,6 +51,106 @@ func TestAccountRepository_Create(t *testing.T) {
 	}
 }
 
+func TestAccountRepository_CreateWithGeneratedID(t *testing.T) {
+	repo := setupAccountRepo(t)
+	ctx := context.Background()
+
+	// A client-supplied ID ahead of the sequence is skipped rather than failing the insert
+	if err := repo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(10)}); err != nil {
+		t.Fatalf("create with ID: %v", err)
+	}
+
+	var ids []int64
+	for i := 0; i < 2; i++ {
+		acc := &models.Account{Balance: decimal.NewFromInt(100)}
+		if err := repo.CreateWithGeneratedID(ctx, acc); err != nil {
+			t.Fatalf("create with generated ID: %v", err)
+		}
+		if acc.CreatedAt.IsZero() || acc.AccountType != models.DefaultAccountType {
+			t.Errorf("expected CreatedAt and default type, got %+v", acc)
+		}
+		ids = append(ids, acc.AccountID)
+	}
+	if ids[0] != 1 || ids[1] != 3 {
+		t.Errorf("expected generated IDs [1 3], got %v", ids)
+	}
+
+	acc, err := repo.GetByID(ctx, 3)
+	if err != nil || !acc.Balance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected account 3 with balance 100, got %+v, %v", acc, err)
+	}
+}
+
+func TestAccountRepository_CreateBatch(t *testing.T) {
+	repo := setupAccountRepo(t)
+	ctx := context.Background()
+
+	repo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(10)})
+
+	accounts := []*models.Account{
+		{AccountID: 5, Balance: decimal.NewFromInt(50), AccountType: "escrow"},
+		{Balance: decimal.NewFromInt(1)},
+		{AccountID: 2, Balance: decimal.NewFromInt(20)},
+		{Balance: decimal.NewFromInt(3), MaxBalance: decimal.NewNullDecimal(decimal.NewFromInt(100))},
+	}
+	tx, err := repo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	created, err := repo.CreateBatch(ctx, tx, accounts)
+	if err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	// The generated IDs skip 2, which was already taken
+	if !created[0] || !created[1] || created[2] || !created[3] {
+		t.Errorf("expected all but the taken ID created, got %v", created)
+	}
+	if accounts[1].AccountID != 1 || accounts[3].AccountID != 3 || accounts[0].CreatedAt.IsZero() {
+		t.Errorf("expected generated IDs 1 and 3 and CreatedAt, got %+v and %+v", accounts[1], accounts[3])
+	}
+	if acc, err := repo.GetByID(ctx, 2); err != nil || !acc.Balance.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected account 2 to keep balance 10, got %+v, %v", acc, err)
+	}
+	if acc, err := repo.GetByID(ctx, 3); err != nil || !acc.MaxBalance.Valid || acc.AccountType != models.DefaultAccountType {
+		t.Errorf("expected account 3 with a max balance and the default type, got %+v, %v", acc, err)
+	}
+	if acc, err := repo.GetByID(ctx, 5); err != nil || acc.AccountType != "escrow" {
+		t.Errorf("expected escrow account 5, got %+v, %v", acc, err)
+	}
+
+	// Nothing is kept if the transaction is rolled back
+	tx, _ = repo.BeginTx(ctx)
+	if _, err := repo.CreateBatch(ctx, tx, []*models.Account{{AccountID: 9, Balance: decimal.NewFromInt(1)}}); err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	tx.Rollback(ctx)
+	if _, err := repo.GetByID(ctx, 9); !errors.Is(err, models.ErrAccountNotFound) {
+		t.Errorf("expected account 9 to be rolled back, got %v", err)
+	}
+}
+
+func TestAccountRepository_AccountType(t *testing.T) {
+	repo := setupAccountRepo(t)
+	ctx := context.Background()
+
+	repo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(10)})
+	repo.Create(ctx, &models.Account{AccountID: 2, AccountType: "escrow", Balance: decimal.NewFromInt(10)})
+
+	for id, want := range map[int64]string{1: models.DefaultAccountType, 2: "escrow"} {
+		acc, err := repo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("get %d: %v", id, err)
+		}
+		if acc.AccountType != want {
+			t.Errorf("account %d: expected type %q, got %q", id, want, acc.AccountType)
+		}
+	}
+}
+
 func TestAccountRepository_Create_Duplicate(t *testing.T) {
 	repo := setupAccountRepo(t)
 	ctx := context.Background()
@@ -74,6 +177,31 @@ func TestAccountRepository_GetByID(t *testing.T) {
 	}
 }
 
+func TestAccountRepository_GetByIDs(t *testing.T) {
+	repo := setupAccountRepo(t)
+	ctx := context.Background()
+
+	for _, id := range []int64{4, 1, 3} {
+		repo.Create(ctx, &models.Account{AccountID: id, Balance: decimal.NewFromInt(id * 10)})
+	}
+
+	accounts, err := repo.GetByIDs(ctx, []int64{3, 2, 4, 9, 1})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(accounts) != 3 || accounts[0].AccountID != 1 || accounts[1].AccountID != 3 || accounts[2].AccountID != 4 {
+		t.Fatalf("expected accounts 1, 3, and 4 in ID order, got %+v", accounts)
+	}
+	if !accounts[1].Balance.Equal(decimal.NewFromInt(30)) || accounts[1].CreatedAt.IsZero() {
+		t.Errorf("expected account 3 fully loaded, got %+v", accounts[1])
+	}
+
+	accounts, err = repo.GetByIDs(ctx, []int64{7, 8})
+	if err != nil || len(accounts) != 0 {
+		t.Errorf("expected no accounts for missing IDs, got %+v, %v", accounts, err)
+	}
+}
+
 func TestAccountRepository_GetByID_NotFound(t *testing.T) {
 	repo := setupAccountRepo(t)
 	_, err := repo.GetByID(context.Background(), 999)
@@ -98,6 +226,77 @@ func TestAccountRepository_UpdateBalance(t *testing.T) {
 	}
 }
 
+func TestAccountRepository_UpdateBalanceCAS(t *testing.T) {
+	repo := setupAccountRepo(t)
+	ctx := context.Background()
+
+	repo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	acc, _ := repo.GetByID(ctx, 1)
+
+	tx, _ := repo.BeginTx(ctx)
+	if err := repo.UpdateBalanceCAS(ctx, tx, 1, decimal.NewFromInt(900), acc.Version); err != nil {
+		t.Fatalf("CAS at current version: %v", err)
+	}
+	tx.Commit(ctx)
+
+	// acc.Version is now stale
+	tx, _ = repo.BeginTx(ctx)
+	if err := repo.UpdateBalanceCAS(ctx, tx, 1, decimal.NewFromInt(800), acc.Version); !errors.Is(err, models.ErrConcurrentModification) {
+		t.Fatalf("CAS at stale version: expected ErrConcurrentModification, got %v", err)
+	}
+	tx.Rollback(ctx)
+
+	got, _ := repo.GetByID(ctx, 1)
+	if !got.Balance.Equal(decimal.NewFromInt(900)) || got.Version != acc.Version+1 {
+		t.Errorf("expected balance 900 at version %d, got %s at %d", acc.Version+1, got.Balance, got.Version)
+	}
+}
+
+func TestAccountRepository_Holds(t *testing.T) {
+	repo := setupAccountRepo(t)
+	ctx := context.Background()
+
+	repo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+
+	tx, _ := repo.BeginTx(ctx)
+	if err := repo.UpdateHeldBalance(ctx, tx, 1, decimal.NewFromInt(101)); err == nil {
+		t.Error("expected the CHECK constraint to reject a held balance above the balance")
+	}
+	tx.Rollback(ctx)
+
+	tx, _ = repo.BeginTx(ctx)
+	if err := repo.UpdateHeldBalance(ctx, tx, 1, decimal.NewFromInt(40)); err != nil {
+		t.Fatalf("update held balance: %v", err)
+	}
+	hold := &models.Hold{AccountID: 1, Amount: decimal.NewFromInt(40)}
+	if err := repo.CreateHold(ctx, tx, hold); err != nil {
+		t.Fatalf("create hold: %v", err)
+	}
+	tx.Commit(ctx)
+	if hold.HoldID == 0 || hold.Status != models.HoldStatusActive || hold.CreatedAt.IsZero() {
+		t.Errorf("expected database-populated fields, got %+v", hold)
+	}
+
+	acc, _ := repo.GetByID(ctx, 1)
+	if !acc.HeldBalance.Equal(decimal.NewFromInt(40)) || acc.Version != 1 {
+		t.Errorf("expected 40 held at version 1, got %s at %d", acc.HeldBalance, acc.Version)
+	}
+
+	tx, _ = repo.BeginTx(ctx)
+	defer tx.Rollback(ctx)
+	got, err := repo.GetHoldForUpdate(ctx, tx, hold.HoldID)
+	if err != nil || !got.Amount.Equal(hold.Amount) || got.ResolvedAt != nil {
+		t.Fatalf("expected the active hold back, got %+v, %v", got, err)
+	}
+	resolvedAt, err := repo.ResolveHold(ctx, tx, hold.HoldID, models.HoldStatusReleased, nil)
+	if err != nil || resolvedAt.IsZero() {
+		t.Fatalf("resolve hold: %v", err)
+	}
+	if _, err := repo.GetHoldForUpdate(ctx, tx, 999); !errors.Is(err, models.ErrHoldNotFound) {
+		t.Errorf("expected ErrHoldNotFound, got %v", err)
+	}
+}
+
 func TestAccountRepository_GetByIDForUpdate_Locking(t *testing.T) {
 	repo := setupAccountRepo(t)
 	ctx := context.Background()
@@ -150,3 +349,216 @@ func TestAccountRepository_Exists(t *testing.T) {
 		t.Error("should exist")
 	}
 }
+
+func TestAccountRepository_ListAfter(t *testing.T) {
+	repo := setupAccountRepo(t)
+	ctx := context.Background()
+
+	for _, id := range []int64{5, 1, 3, 2, 4} {
+		repo.Create(ctx, &models.Account{AccountID: id, Balance: decimal.NewFromInt(100)})
+	}
+
+	page, err := repo.ListAfter(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page) != 2 || page[0].AccountID != 1 || page[1].AccountID != 2 {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+
+	page, _ = repo.ListAfter(ctx, 2, 10)
+	if len(page) != 3 || page[0].AccountID != 3 || page[2].AccountID != 5 {
+		t.Fatalf("unexpected second page: %+v", page)
+	}
+
+	page, _ = repo.ListAfter(ctx, 5, 10)
+	if len(page) != 0 {
+		t.Errorf("expected empty page, got %d", len(page))
+	}
+}
+
+func TestAccountRepository_List(t *testing.T) {
+	repo := setupAccountRepo(t)
+	ctx := context.Background()
+
+	// Created in this order, so created_at follows it; 2 and 4 share a balance
+	for _, acc := range []struct{ id, balance int64 }{{3, 300}, {1, 50}, {4, 200}, {2, 200}, {5, 0}} {
+		if err := repo.Create(ctx, &models.Account{AccountID: acc.id, Balance: decimal.NewFromInt(acc.balance)}); err != nil {
+			t.Fatalf("create %d: %v", acc.id, err)
+		}
+	}
+
+	bound := func(v int64) decimal.NullDecimal { return decimal.NewNullDecimal(decimal.NewFromInt(v)) }
+	tests := []struct {
+		name      string
+		filter    models.AccountListFilter
+		wantIDs   []int64
+		wantTotal int64
+	}{
+		{"default order", models.AccountListFilter{Limit: 10}, []int64{1, 2, 3, 4, 5}, 5},
+		{"by balance", models.AccountListFilter{SortBy: models.AccountSortByBalance, Limit: 10}, []int64{5, 1, 2, 4, 3}, 5},
+		{"by balance descending", models.AccountListFilter{SortBy: models.AccountSortByBalance, Descending: true, Limit: 10}, []int64{3, 4, 2, 1, 5}, 5},
+		{"by created_at", models.AccountListFilter{SortBy: models.AccountSortByCreatedAt, Limit: 10}, []int64{3, 1, 4, 2, 5}, 5},
+		{"by created_at descending", models.AccountListFilter{SortBy: models.AccountSortByCreatedAt, Descending: true, Limit: 10}, []int64{5, 2, 4, 1, 3}, 5},
+		{"min balance inclusive", models.AccountListFilter{MinBalance: bound(200), Limit: 10}, []int64{2, 3, 4}, 3},
+		{"max balance inclusive", models.AccountListFilter{MaxBalance: bound(50), Limit: 10}, []int64{1, 5}, 2},
+		{"balance range", models.AccountListFilter{MinBalance: bound(1), MaxBalance: bound(200), SortBy: models.AccountSortByBalance, Limit: 10}, []int64{1, 2, 4}, 3},
+		{"page", models.AccountListFilter{SortBy: models.AccountSortByBalance, Limit: 2, Offset: 2}, []int64{2, 4}, 5},
+		{"past the end", models.AccountListFilter{Limit: 10, Offset: 10}, []int64{}, 5},
+		{"nothing matches", models.AccountListFilter{MinBalance: bound(1000), Limit: 10}, []int64{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts, total, err := repo.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			ids := make([]int64, 0, len(accounts))
+			for _, acc := range accounts {
+				ids = append(ids, acc.AccountID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) || total != tt.wantTotal {
+				t.Errorf("expected %v of %d, got %v of %d", tt.wantIDs, tt.wantTotal, ids, total)
+			}
+		})
+	}
+
+	if _, _, err := repo.List(ctx, models.AccountListFilter{SortBy: "balance; DROP TABLE accounts", Limit: 10}); err == nil {
+		t.Error("expected an unknown sort field to be rejected")
+	}
+}
+
+func TestAccountRepository_MaxBalance(t *testing.T) {
+	repo := setupAccountRepo(t)
+	ctx := context.Background()
+
+	repo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	repo.Create(ctx, &models.Account{
+		AccountID:  2,
+		Balance:    decimal.NewFromInt(100),
+		MaxBalance: decimal.NewNullDecimal(decimal.NewFromInt(500)),
+	})
+
+	acc1, _ := repo.GetByID(ctx, 1)
+	if acc1.MaxBalance.Valid {
+		t.Errorf("expected no max balance, got %s", acc1.MaxBalance.Decimal)
+	}
+
+	acc2, _ := repo.GetByID(ctx, 2)
+	if !acc2.MaxBalance.Valid || !acc2.MaxBalance.Decimal.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("expected max balance 500, got %+v", acc2.MaxBalance)
+	}
+}
+
+func TestAccountRepository_ReplicaReads(t *testing.T) {
+	if err := testSuite.Clean(); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	ctx := context.Background()
+
+	// A second pool on the same server stands in for the replica
+	replica, err := pgxpool.NewWithConfig(ctx, testSuite.Pool().Config().Copy())
+	if err != nil {
+		t.Fatalf("replica pool: %v", err)
+	}
+	defer replica.Close()
+	primary := testSuite.Pool()
+
+	pools := Pools{Transfer: primary, Replica: replica}
+	repo := NewAccountRepositoryWithPools(pools, "")
+	txnRepo := NewTransactionRepositoryWithPools(pools)
+	if err := repo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	primaryBefore, replicaBefore := primary.Stat().AcquireCount(), replica.Stat().AcquireCount()
+	if _, err := repo.GetByID(ctx, 1); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if exists, err := repo.Exists(ctx, 1); err != nil || !exists {
+		t.Fatalf("exists: %v, %v", exists, err)
+	}
+	if _, err := txnRepo.GetByAccountID(ctx, 1, 10, 0); err != nil {
+		t.Fatalf("list transactions: %v", err)
+	}
+	if got := replica.Stat().AcquireCount() - replicaBefore; got != 3 {
+		t.Errorf("expected 3 reads on the replica, got %d", got)
+	}
+	if got := primary.Stat().AcquireCount() - primaryBefore; got != 0 {
+		t.Errorf("expected no reads on the primary, got %d", got)
+	}
+
+	// Reads within a transaction stay on its connection to the primary
+	replicaBefore = replica.Stat().AcquireCount()
+	tx, err := repo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := repo.UpdateBalance(ctx, tx, 1, decimal.NewFromInt(150)); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	acc, err := repo.GetByIDInTx(ctx, tx, 1)
+	if err != nil || !acc.Balance.Equal(decimal.NewFromInt(150)) {
+		t.Errorf("expected the transaction's own write, got %+v, %v", acc, err)
+	}
+	tx.Rollback(ctx)
+	if got := replica.Stat().AcquireCount() - replicaBefore; got != 0 {
+		t.Errorf("expected the transaction not to touch the replica, got %d acquires", got)
+	}
+
+	// Without a replica, the same reads fall back to the primary
+	repo = NewAccountRepositoryWithPools(SinglePool(primary), "")
+	replicaBefore = replica.Stat().AcquireCount()
+	if _, err := repo.GetByID(ctx, 1); err != nil {
+		t.Fatalf("get without replica: %v", err)
+	}
+	if got := replica.Stat().AcquireCount() - replicaBefore; got != 0 {
+		t.Errorf("expected no replica reads once unset, got %d", got)
+	}
+}
+
+func TestAccountRepository_PoolIsolation(t *testing.T) {
+	if err := testSuite.Clean(); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	ctx := context.Background()
+
+	exportCfg := testSuite.Pool().Config().Copy()
+	exportCfg.MaxConns = 1
+	exportPool, err := pgxpool.NewWithConfig(ctx, exportCfg)
+	if err != nil {
+		t.Fatalf("export pool: %v", err)
+	}
+	defer exportPool.Close()
+
+	repo := NewAccountRepositoryWithPools(Pools{Transfer: testSuite.Pool(), Export: exportPool}, "")
+	repo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+
+	// A slow export holding every export connection
+	held, err := exportPool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer held.Release()
+
+	shortCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	tx, err := repo.BeginTx(shortCtx)
+	if err != nil {
+		t.Fatalf("transfers should not wait on the export pool: %v", err)
+	}
+	if _, err := repo.GetByIDForUpdate(shortCtx, tx, 1); err != nil {
+		t.Errorf("lock: %v", err)
+	}
+	tx.Rollback(ctx)
+
+	if _, err := repo.GetByID(shortCtx, 1); err != nil {
+		t.Errorf("reads should not wait on the export pool: %v", err)
+	}
+
+	if _, err := repo.ListAfter(shortCtx, 0, 10); err == nil {
+		t.Error("expected the export to wait for its exhausted pool")
+	}
+}
@@ -0,0 +1,517 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"internal-transfers-system/internal/consistency"
+	"internal-transfers-system/internal/interfaces"
+	"internal-transfers-system/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// Compile-time check to ensure AccountRepository implements interfaces.AccountRepository.
+var _ interfaces.AccountRepository = (*AccountRepository)(nil)
+
+// AccountRepository provides account data operations on a Store.
+// All methods are safe for concurrent use.
+type AccountRepository struct {
+	store *Store
+}
+
+// NewAccountRepository creates an AccountRepository backed by store.
+func NewAccountRepository(store *Store) *AccountRepository {
+	return &AccountRepository{store: store}
+}
+
+// Create inserts a new account. Returns ErrAccountAlreadyExists if the ID is taken.
+func (r *AccountRepository) Create(ctx context.Context, account *models.Account) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.accounts[account.AccountID]; exists {
+		return models.ErrAccountAlreadyExists
+	}
+	s.insertAccount(account)
+	return nil
+}
+
+// CreateWithGeneratedID inserts a new account with the next ID not already taken.
+func (r *AccountRepository) CreateWithGeneratedID(ctx context.Context, account *models.Account) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account.AccountID = s.nextAccountID()
+	s.insertAccount(account)
+	return nil
+}
+
+// CreateBatch inserts accounts within tx, skipping those whose ID is taken and giving
+// those without one the next free ID.
+func (r *AccountRepository) CreateBatch(ctx context.Context, tx pgx.Tx, accounts []*models.Account) ([]bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]bool, len(accounts))
+	for i, account := range accounts {
+		if account.AccountID == 0 {
+			account.AccountID = s.nextAccountID()
+		} else if _, exists := s.accounts[account.AccountID]; exists {
+			continue
+		}
+		s.insertAccount(account)
+		id := account.AccountID
+		t.onRollback(func() { delete(s.accounts, id) })
+		created[i] = true
+	}
+	return created, nil
+}
+
+// insertAccount stores a new account, defaulting its type and setting its timestamps.
+// The caller holds s.mu and has checked the ID is free.
+func (s *Store) insertAccount(account *models.Account) {
+	if account.AccountType == "" {
+		account.AccountType = models.DefaultAccountType
+	}
+	now := time.Now()
+	account.CreatedAt, account.UpdatedAt = now, now
+	s.accounts[account.AccountID] = &accountRow{
+		account: models.Account{
+			AccountID:      account.AccountID,
+			AccountType:    account.AccountType,
+			Balance:        account.Balance,
+			InitialBalance: account.Balance,
+			MaxBalance:     account.MaxBalance,
+			OverdraftLimit: account.OverdraftLimit,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		},
+	}
+}
+
+// nextAccountID draws account IDs until one isn't taken. The caller holds s.mu.
+func (s *Store) nextAccountID() int64 {
+	for {
+		s.lastAccountID++
+		if _, exists := s.accounts[s.lastAccountID]; !exists {
+			return s.lastAccountID
+		}
+	}
+}
+
+// GetByID retrieves an account by its ID.
+// Returns ErrAccountNotFound if the account does not exist.
+func (r *AccountRepository) GetByID(ctx context.Context, accountID int64) (*models.Account, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	row, exists := s.accounts[accountID]
+	if !exists {
+		return nil, models.ErrAccountNotFound
+	}
+	return copyAccount(&row.account), nil
+}
+
+// GetByIDs retrieves the accounts among ids that exist, ordered by account ID.
+func (r *AccountRepository) GetByIDs(ctx context.Context, ids []int64) ([]*models.Account, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	accounts := make([]*models.Account, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if row, exists := s.accounts[id]; exists && !seen[id] {
+			seen[id] = true
+			accounts = append(accounts, copyAccount(&row.account))
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].AccountID < accounts[j].AccountID })
+	return accounts, nil
+}
+
+// GetByIDInTx retrieves an account within tx.
+// Returns ErrAccountNotFound if the account does not exist.
+func (r *AccountRepository) GetByIDInTx(ctx context.Context, tx pgx.Tx, accountID int64) (*models.Account, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, err := s.open(tx); err != nil {
+		return nil, err
+	}
+	row, exists := s.accounts[accountID]
+	if !exists {
+		return nil, models.ErrAccountNotFound
+	}
+	return copyAccount(&row.account), nil
+}
+
+// GetByIDForUpdate retrieves an account within tx. tx already holds the store's
+// transaction lock, so no other transaction can change the account until it ends.
+// Returns ErrAccountNotFound if the account does not exist.
+func (r *AccountRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, accountID int64) (*models.Account, error) {
+	return r.GetByIDInTx(ctx, tx, accountID)
+}
+
+// LockAccountAdvisory does nothing but check tx: tx already holds the store's transaction
+// lock, which serializes it with every other transaction.
+func (r *AccountRepository) LockAccountAdvisory(ctx context.Context, tx pgx.Tx, accountID int64) error {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, err := s.open(tx)
+	return err
+}
+
+// update applies fn to account accountID within tx, undoing it if tx rolls back, and
+// bumps updated_at. fn may reject the change, leaving the account untouched.
+// Returns ErrAccountNotFound if the account does not exist.
+func (s *Store) update(tx pgx.Tx, accountID int64, fn func(a *models.Account) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.open(tx)
+	if err != nil {
+		return err
+	}
+	row, exists := s.accounts[accountID]
+	if !exists {
+		return models.ErrAccountNotFound
+	}
+
+	updated := *copyAccount(&row.account)
+	if err := fn(&updated); err != nil {
+		return err
+	}
+	if err := checkAccount(&updated); err != nil {
+		return err
+	}
+	updated.UpdatedAt = time.Now()
+
+	prev := row.account
+	row.account = updated
+	t.onRollback(func() { row.account = prev })
+	return nil
+}
+
+// checkAccount enforces the accounts table's CHECK constraints.
+func checkAccount(a *models.Account) error {
+	floor := a.OverdraftLimit.Neg()
+	if a.Balance.LessThan(floor) {
+		return fmt.Errorf("update account %d: balance %s is below %s, violating accounts_balance_check", a.AccountID, a.Balance, floor)
+	}
+	if a.HeldBalance.IsNegative() || a.HeldBalance.GreaterThan(a.Balance.Add(a.OverdraftLimit)) {
+		return fmt.Errorf("update account %d: held balance %s is out of range, violating accounts_held_balance_check", a.AccountID, a.HeldBalance)
+	}
+	return nil
+}
+
+// UpdateBalance sets the balance of an account within tx and increments its version.
+func (r *AccountRepository) UpdateBalance(ctx context.Context, tx pgx.Tx, accountID int64, newBalance decimal.Decimal) error {
+	return r.store.update(tx, accountID, func(a *models.Account) error {
+		a.Balance = newBalance
+		a.Version++
+		return nil
+	})
+}
+
+// UpdateBalanceCAS sets the balance of an account within tx only if its version is
+// still expectedVersion. Returns ErrConcurrentModification otherwise.
+func (r *AccountRepository) UpdateBalanceCAS(ctx context.Context, tx pgx.Tx, accountID int64, newBalance decimal.Decimal, expectedVersion int) error {
+	err := r.store.update(tx, accountID, func(a *models.Account) error {
+		if a.Version != expectedVersion {
+			return models.ErrConcurrentModification
+		}
+		a.Balance = newBalance
+		a.Version++
+		return nil
+	})
+	if err == models.ErrAccountNotFound {
+		return models.ErrConcurrentModification
+	}
+	return err
+}
+
+// UpdateLastSequence records sequence as the last accepted transfer sequence for an
+// account within tx.
+func (r *AccountRepository) UpdateLastSequence(ctx context.Context, tx pgx.Tx, accountID int64, sequence int64) error {
+	return r.store.update(tx, accountID, func(a *models.Account) error {
+		a.LastSequence = sequence
+		return nil
+	})
+}
+
+// Close marks an account closed within tx and returns the closing time.
+func (r *AccountRepository) Close(ctx context.Context, tx pgx.Tx, accountID int64) (time.Time, error) {
+	closedAt := time.Now()
+	err := r.store.update(tx, accountID, func(a *models.Account) error {
+		a.ClosedAt = &closedAt
+		a.Version++
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return closedAt, nil
+}
+
+// UpdateHeldBalance sets an account's held balance within tx and increments its version.
+func (r *AccountRepository) UpdateHeldBalance(ctx context.Context, tx pgx.Tx, accountID int64, heldBalance decimal.Decimal) error {
+	return r.store.update(tx, accountID, func(a *models.Account) error {
+		a.HeldBalance = heldBalance
+		a.Version++
+		return nil
+	})
+}
+
+// CreateHold inserts an active hold within tx.
+func (r *AccountRepository) CreateHold(ctx context.Context, tx pgx.Tx, hold *models.Hold) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.open(tx)
+	if err != nil {
+		return err
+	}
+	if _, exists := s.accounts[hold.AccountID]; !exists {
+		return models.ErrAccountNotFound
+	}
+
+	s.lastHoldID++
+	hold.HoldID = s.lastHoldID
+	hold.Status = models.HoldStatusActive
+	hold.CreatedAt = time.Now()
+	stored := *hold
+	s.holds[hold.HoldID] = &stored
+	id := hold.HoldID
+	t.onRollback(func() { delete(s.holds, id) })
+	return nil
+}
+
+// GetHoldForUpdate retrieves a hold within tx.
+// Returns ErrHoldNotFound if the hold does not exist.
+func (r *AccountRepository) GetHoldForUpdate(ctx context.Context, tx pgx.Tx, holdID int64) (*models.Hold, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, err := s.open(tx); err != nil {
+		return nil, err
+	}
+	hold, exists := s.holds[holdID]
+	if !exists {
+		return nil, models.ErrHoldNotFound
+	}
+	copied := *hold
+	return &copied, nil
+}
+
+// ResolveHold marks a hold released or captured within tx and returns the resolution time.
+// Returns ErrHoldNotFound if the hold does not exist.
+func (r *AccountRepository) ResolveHold(ctx context.Context, tx pgx.Tx, holdID int64, status models.HoldStatus, transactionID *int64) (time.Time, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.open(tx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	hold, exists := s.holds[holdID]
+	if !exists {
+		return time.Time{}, models.ErrHoldNotFound
+	}
+
+	prev := *hold
+	resolvedAt := time.Now()
+	hold.Status = status
+	hold.TransactionID = transactionID
+	hold.ResolvedAt = &resolvedAt
+	t.onRollback(func() { *hold = prev })
+	return resolvedAt, nil
+}
+
+// Exists checks if an account with the given ID exists.
+func (r *AccountRepository) Exists(ctx context.Context, accountID int64) (bool, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, exists := s.accounts[accountID]
+	return exists, nil
+}
+
+// ListAfter retrieves up to limit accounts with an ID greater than afterID, ordered by
+// account ID ascending.
+func (r *AccountRepository) ListAfter(ctx context.Context, afterID int64, limit int) ([]*models.Account, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	accounts := make([]*models.Account, 0, limit)
+	for id, row := range s.accounts {
+		if id > afterID {
+			accounts = append(accounts, &models.Account{
+				AccountID:   row.account.AccountID,
+				AccountType: row.account.AccountType,
+				Balance:     row.account.Balance,
+				MaxBalance:  row.account.MaxBalance,
+				CreatedAt:   row.account.CreatedAt,
+				UpdatedAt:   row.account.UpdatedAt,
+			})
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].AccountID < accounts[j].AccountID })
+	if len(accounts) > limit {
+		accounts = accounts[:limit]
+	}
+	return accounts, nil
+}
+
+// List retrieves a page of the accounts matching filter, in its sort order, and the
+// total number of matching accounts. An unknown sort field is an error.
+func (r *AccountRepository) List(ctx context.Context, filter models.AccountListFilter) ([]*models.Account, int64, error) {
+	var compare func(a, b *models.Account) int
+	switch filter.SortBy {
+	case "", models.AccountSortByID:
+		compare = func(a, b *models.Account) int { return 0 }
+	case models.AccountSortByBalance:
+		compare = func(a, b *models.Account) int { return a.Balance.Cmp(b.Balance) }
+	case models.AccountSortByCreatedAt:
+		compare = func(a, b *models.Account) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	default:
+		return nil, 0, fmt.Errorf("list accounts: unknown sort field %q", filter.SortBy)
+	}
+
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var accounts []*models.Account
+	for _, row := range s.accounts {
+		balance := row.account.Balance
+		if filter.MinBalance.Valid && balance.LessThan(filter.MinBalance.Decimal) ||
+			filter.MaxBalance.Valid && balance.GreaterThan(filter.MaxBalance.Decimal) {
+			continue
+		}
+		account := copyAccount(&row.account)
+		account.LastSequence, account.Version = 0, 0
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		a, b := accounts[i], accounts[j]
+		if filter.Descending {
+			a, b = b, a
+		}
+		if c := compare(a, b); c != 0 {
+			return c < 0
+		}
+		return a.AccountID < b.AccountID
+	})
+
+	total := int64(len(accounts))
+	if filter.Offset >= len(accounts) {
+		return []*models.Account{}, total, nil
+	}
+	accounts = accounts[filter.Offset:]
+	if len(accounts) > filter.Limit {
+		accounts = accounts[:filter.Limit]
+	}
+	return accounts, total, nil
+}
+
+// VisibleLSN always returns 0: every read sees every committed write, so any
+// consistency token is already satisfied.
+func (r *AccountRepository) VisibleLSN(ctx context.Context) (consistency.LSN, error) {
+	return 0, nil
+}
+
+// NextAdjustmentBatchID allocates a new, never reused batch ID for a bulk balance
+// adjustment.
+func (r *AccountRepository) NextAdjustmentBatchID(ctx context.Context, tx pgx.Tx) (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.open(tx); err != nil {
+		return 0, err
+	}
+	s.lastBatchID++
+	return s.lastBatchID, nil
+}
+
+// CreateAdjustment inserts a balance adjustment audit record within tx.
+func (r *AccountRepository) CreateAdjustment(ctx context.Context, tx pgx.Tx, adjustment *models.BalanceAdjustment) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.open(tx)
+	if err != nil {
+		return err
+	}
+	if _, exists := s.accounts[adjustment.AccountID]; !exists {
+		return models.ErrAccountNotFound
+	}
+
+	s.lastAdjustmentID++
+	adjustment.AdjustmentID = s.lastAdjustmentID
+	adjustment.CreatedAt = time.Now()
+	stored := *adjustment
+	n := len(s.adjustments)
+	s.adjustments = append(s.adjustments, &stored)
+	t.onRollback(func() { s.adjustments = s.adjustments[:n] })
+	return nil
+}
+
+// RecordBalanceChange appends an entry to the account's balance history within tx.
+func (r *AccountRepository) RecordBalanceChange(ctx context.Context, tx pgx.Tx, change *models.BalanceChange) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.open(tx)
+	if err != nil {
+		return err
+	}
+
+	s.lastChangeID++
+	change.ChangeID = s.lastChangeID
+	change.CreatedAt = time.Now()
+	stored := *change
+	n := len(s.history)
+	s.history = append(s.history, &stored)
+	t.onRollback(func() { s.history = s.history[:n] })
+	return nil
+}
+
+// ListBalanceHistory retrieves an account's balance changes, newest first.
+func (r *AccountRepository) ListBalanceHistory(ctx context.Context, accountID int64, limit, offset int) ([]*models.BalanceChange, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	changes := make([]*models.BalanceChange, 0, limit)
+	for i := len(s.history) - 1; i >= 0 && len(changes) < limit; i-- {
+		if s.history[i].AccountID != accountID {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		copied := *s.history[i]
+		changes = append(changes, &copied)
+	}
+	return changes, nil
+}
+
+// TxIsolationLevel reports "serializable": transactions on the store run one at a time.
+func (r *AccountRepository) TxIsolationLevel(ctx context.Context, tx pgx.Tx) (string, error) {
+	return "serializable", nil
+}
+
+// BeginTx starts a transaction, waiting until no other transaction on the store is open
+// or ctx is done. The caller is responsible for calling Commit() or Rollback() on the
+// returned transaction; until then every other BeginTx waits.
+func (r *AccountRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return r.store.begin(ctx)
+}
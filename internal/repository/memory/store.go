@@ -0,0 +1,78 @@
+// Package memory implements the repository interfaces on in-process maps, so the API
+// can run without PostgreSQL for demos, local development, and CI smoke tests. Nothing
+// is persisted: the data lives as long as the Store.
+//
+// Database transactions are emulated with one global lock. BeginTx blocks until no other
+// transaction is open, so transactions run one at a time, which is serializable and keeps
+// balances conserved however many transfers race. Writes made in a transaction are
+// applied immediately and undone on Rollback; reads outside a transaction don't wait for
+// the lock and may see a transaction's writes before it commits.
+package memory
+
+import (
+	"sync"
+
+	"internal-transfers-system/internal/models"
+)
+
+// Store holds the data shared by an AccountRepository and a TransactionRepository.
+// It is safe for concurrent use.
+type Store struct {
+	// txSlot holds a token while a transaction is open
+	txSlot chan struct{}
+
+	mu           sync.RWMutex
+	accounts     map[int64]*accountRow
+	holds        map[int64]*models.Hold
+	adjustments  []*models.BalanceAdjustment
+	history      []*models.BalanceChange
+	transactions map[int64]*models.Transaction
+	ledger       []*models.LedgerEntry
+	recurring    map[int64]*models.RecurringTransfer
+
+	// Sequences, which like Postgres sequences are never rolled back
+	lastAccountID     int64
+	lastHoldID        int64
+	lastAdjustmentID  int64
+	lastBatchID       int64
+	lastChangeID      int64
+	lastTransactionID int64
+	lastEntryID       int64
+	lastRecurringID   int64
+}
+
+// accountRow is a stored account.
+type accountRow struct {
+	account models.Account
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{
+		txSlot:       make(chan struct{}, 1),
+		accounts:     make(map[int64]*accountRow),
+		holds:        make(map[int64]*models.Hold),
+		transactions: make(map[int64]*models.Transaction),
+		recurring:    make(map[int64]*models.RecurringTransfer),
+	}
+}
+
+// copyAccount returns a copy of a that shares nothing with it.
+func copyAccount(a *models.Account) *models.Account {
+	copied := *a
+	if a.ClosedAt != nil {
+		closedAt := *a.ClosedAt
+		copied.ClosedAt = &closedAt
+	}
+	return &copied
+}
+
+// copyTransaction returns a copy of t that shares nothing with it.
+func copyTransaction(t *models.Transaction) *models.Transaction {
+	copied := *t
+	if t.ReversalOf != nil {
+		reversalOf := *t.ReversalOf
+		copied.ReversalOf = &reversalOf
+	}
+	return &copied
+}
@@ -0,0 +1,493 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"internal-transfers-system/internal/interfaces"
+	"internal-transfers-system/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// Compile-time check to ensure TransactionRepository implements interfaces.TransactionRepository.
+var _ interfaces.TransactionRepository = (*TransactionRepository)(nil)
+
+// TransactionRepository provides transaction data operations on a Store.
+// All methods are safe for concurrent use.
+type TransactionRepository struct {
+	store *Store
+}
+
+// NewTransactionRepository creates a TransactionRepository backed by store.
+func NewTransactionRepository(store *Store) *TransactionRepository {
+	return &TransactionRepository{store: store}
+}
+
+// Create inserts a new transaction record within tx, enforcing the constraints the
+// transactions table does. An empty Type is stored as a transfer and an empty Status as
+// pending.
+//
+// Returns ErrDuplicateTransaction if the idempotency key is already taken,
+// ErrAlreadyReversed if ReversalOf names a transaction that already has a reversal,
+// ErrAccountNotFound if an account doesn't exist, ErrTransferNotFound if ReversalOf
+// doesn't, or ErrInvalidAmount if the amount isn't positive.
+func (r *TransactionRepository) Create(ctx context.Context, tx pgx.Tx, transaction *models.Transaction) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.open(tx)
+	if err != nil {
+		return err
+	}
+
+	if transaction.Type == "" {
+		transaction.Type = models.TransactionTypeTransfer
+	}
+	if transaction.Status == "" {
+		transaction.Status = models.TransactionStatusPending
+	}
+	if err := s.checkTransaction(transaction); err != nil {
+		return err
+	}
+
+	s.lastTransactionID++
+	transaction.TransactionID = s.lastTransactionID
+	transaction.CreatedAt = time.Now()
+	if transaction.EffectiveDate.IsZero() {
+		transaction.EffectiveDate = transaction.CreatedAt.UTC().Truncate(24 * time.Hour)
+	}
+	s.transactions[transaction.TransactionID] = copyTransaction(transaction)
+	id := transaction.TransactionID
+	t.onRollback(func() { delete(s.transactions, id) })
+	return nil
+}
+
+// checkTransaction enforces the transactions table's constraints on txn before it is
+// inserted. The caller holds s.mu.
+func (s *Store) checkTransaction(txn *models.Transaction) error {
+	if !txn.Amount.IsPositive() {
+		return models.ErrInvalidAmount
+	}
+	for _, id := range []int64{txn.SourceAccountID, txn.DestinationAccountID, txn.FeeAccountID} {
+		if _, exists := s.accounts[id]; id != 0 && !exists {
+			return models.ErrAccountNotFound
+		}
+	}
+	if txn.SourceAccountID == txn.DestinationAccountID {
+		return fmt.Errorf("insert transaction: source and destination are both account %d", txn.SourceAccountID)
+	}
+	if txn.ReversalOf != nil {
+		if _, exists := s.transactions[*txn.ReversalOf]; !exists {
+			return models.ErrTransferNotFound
+		}
+	}
+
+	for _, existing := range s.transactions {
+		if txn.IdempotencyKey != "" && existing.IdempotencyKey == txn.IdempotencyKey {
+			return models.ErrDuplicateTransaction
+		}
+		if txn.ReversalOf != nil && txn.Status != models.TransactionStatusFailed &&
+			existing.ReversalOf != nil && *existing.ReversalOf == *txn.ReversalOf && existing.Status != models.TransactionStatusFailed {
+			return models.ErrAlreadyReversed
+		}
+	}
+	return nil
+}
+
+// CreateLedgerEntries inserts a transaction's ledger entries within tx.
+func (r *TransactionRepository) CreateLedgerEntries(ctx context.Context, tx pgx.Tx, entries ...*models.LedgerEntry) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.open(tx)
+	if err != nil {
+		return err
+	}
+
+	n := len(s.ledger)
+	for _, entry := range entries {
+		if _, exists := s.transactions[entry.TransactionID]; !exists {
+			s.ledger = s.ledger[:n]
+			return fmt.Errorf("insert ledger entry for transaction %d: no such transaction", entry.TransactionID)
+		}
+		s.lastEntryID++
+		entry.EntryID = s.lastEntryID
+		entry.CreatedAt = time.Now()
+		copied := *entry
+		s.ledger = append(s.ledger, &copied)
+	}
+	t.onRollback(func() { s.ledger = s.ledger[:n] })
+	return nil
+}
+
+// GetLedgerByAccountID retrieves an account's ledger entries, newest first.
+func (r *TransactionRepository) GetLedgerByAccountID(ctx context.Context, accountID int64, limit, offset int) ([]*models.LedgerEntry, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := make([]*models.LedgerEntry, 0, limit)
+	for i := len(s.ledger) - 1; i >= 0 && len(entries) < limit; i-- {
+		if s.ledger[i].AccountID != accountID {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		copied := *s.ledger[i]
+		entries = append(entries, &copied)
+	}
+	return entries, nil
+}
+
+// GetByIdempotencyKey retrieves the transaction created with key.
+// Returns ErrTransferNotFound if no transaction uses the key.
+func (r *TransactionRepository) GetByIdempotencyKey(ctx context.Context, key string) (*models.Transaction, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, txn := range s.transactions {
+		if txn.IdempotencyKey == key {
+			return copyTransaction(txn), nil
+		}
+	}
+	return nil, models.ErrTransferNotFound
+}
+
+// GetByID retrieves a transaction by its ID.
+// Returns ErrTransferNotFound if the transaction does not exist.
+func (r *TransactionRepository) GetByID(ctx context.Context, transactionID int64) (*models.Transaction, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	txn, exists := s.transactions[transactionID]
+	if !exists {
+		return nil, models.ErrTransferNotFound
+	}
+	return copyTransaction(txn), nil
+}
+
+// GetReversal retrieves the transaction that reverses transactionID, ignoring failed
+// reversal attempts. Returns ErrTransferNotFound if it has not been reversed.
+func (r *TransactionRepository) GetReversal(ctx context.Context, transactionID int64) (*models.Transaction, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, txn := range s.transactions {
+		if txn.ReversalOf != nil && *txn.ReversalOf == transactionID && txn.Status != models.TransactionStatusFailed {
+			return copyTransaction(txn), nil
+		}
+	}
+	return nil, models.ErrTransferNotFound
+}
+
+// countsAsOutbound reports whether txn is a transfer accountID sent that counts toward
+// its cooldown and daily limits: batch legs count, reversals and failed transfers don't.
+func countsAsOutbound(txn *models.Transaction, accountID int64) bool {
+	return txn.SourceAccountID == accountID && txn.Type == models.TransactionTypeTransfer &&
+		txn.ReversalOf == nil && txn.Status != models.TransactionStatusFailed
+}
+
+// SinceLastOutboundTransfer returns how long ago accountID last sent a transfer, and
+// false if it never has.
+func (r *TransactionRepository) SinceLastOutboundTransfer(ctx context.Context, tx pgx.Tx, accountID int64) (time.Duration, bool, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, err := s.open(tx); err != nil {
+		return 0, false, err
+	}
+	var last time.Time
+	for _, txn := range s.transactions {
+		if countsAsOutbound(txn, accountID) && txn.CreatedAt.After(last) {
+			last = txn.CreatedAt
+		}
+	}
+	if last.IsZero() {
+		return 0, false, nil
+	}
+	return time.Since(last), true, nil
+}
+
+// DailyOutbound totals the transfers accountID has sent since the start of the current
+// UTC day. Accounts in the store have no daily limit overrides.
+// Returns ErrAccountNotFound if the account doesn't exist.
+func (r *TransactionRepository) DailyOutbound(ctx context.Context, tx pgx.Tx, accountID int64) (*models.DailyOutbound, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, err := s.open(tx); err != nil {
+		return nil, err
+	}
+	if _, exists := s.accounts[accountID]; !exists {
+		return nil, models.ErrAccountNotFound
+	}
+
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	out := &models.DailyOutbound{ResetsAt: start.Add(24 * time.Hour)}
+	for _, txn := range s.transactions {
+		if countsAsOutbound(txn, accountID) && !txn.CreatedAt.Before(start) {
+			out.Amount = out.Amount.Add(txn.Amount)
+			out.Count++
+		}
+	}
+	return out, nil
+}
+
+// accountTransactions returns copies of accountID's transactions that match, newest
+// first. The caller holds s.mu.
+func (s *Store) accountTransactions(accountID int64, match func(*models.Transaction) bool) []*models.Transaction {
+	var txns []*models.Transaction
+	for _, txn := range s.transactions {
+		if (txn.SourceAccountID == accountID || txn.DestinationAccountID == accountID) && match(txn) {
+			txns = append(txns, copyTransaction(txn))
+		}
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		return models.CursorAfter(txns[j]).Less(models.CursorAfter(txns[i]))
+	})
+	return txns
+}
+
+// page returns the limit items of items after the first offset, never nil.
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// GetByAccountID retrieves an account's transactions with pagination, newest first.
+func (r *TransactionRepository) GetByAccountID(ctx context.Context, accountID int64, limit, offset int) ([]*models.Transaction, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	txns := s.accountTransactions(accountID, func(*models.Transaction) bool { return true })
+	return page(txns, limit, offset), nil
+}
+
+// GetByAccountIDFiltered is GetByAccountID restricted to the transactions within
+// filter's bounds.
+func (r *TransactionRepository) GetByAccountIDFiltered(ctx context.Context, accountID int64, filter models.TransactionFilter, limit, offset int) ([]*models.Transaction, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	txns := s.accountTransactions(accountID, filter.Matches)
+	return page(txns, limit, offset), nil
+}
+
+// CountByAccountID counts the account's transactions within filter's bounds.
+func (r *TransactionRepository) CountByAccountID(ctx context.Context, accountID int64, filter models.TransactionFilter) (int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var total int64
+	for _, txn := range s.transactions {
+		if (txn.SourceAccountID == accountID || txn.DestinationAccountID == accountID) && filter.Matches(txn) {
+			total++
+		}
+	}
+	return total, nil
+}
+
+// GetByAccountIDAfter retrieves up to limit of an account's transactions positioned
+// strictly before the cursor, newest first.
+func (r *TransactionRepository) GetByAccountIDAfter(ctx context.Context, accountID int64, before models.TransactionCursor, limit int) ([]*models.Transaction, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	txns := s.accountTransactions(accountID, func(txn *models.Transaction) bool {
+		return models.CursorAfter(txn).Less(before)
+	})
+	return page(txns, limit, 0), nil
+}
+
+// SumByCategory totals an account's transactions per category over effective dates in
+// [from, to], skipping failed transactions.
+func (r *TransactionRepository) SumByCategory(ctx context.Context, accountID int64, from, to time.Time) ([]*models.CategoryTotal, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	byCategory := make(map[string]*models.CategoryTotal)
+	for _, txn := range s.transactions {
+		if (txn.SourceAccountID != accountID && txn.DestinationAccountID != accountID) || txn.Status == models.TransactionStatusFailed {
+			continue
+		}
+		if (!from.IsZero() && txn.EffectiveDate.Before(from)) || (!to.IsZero() && txn.EffectiveDate.After(to)) {
+			continue
+		}
+		total, ok := byCategory[txn.Category]
+		if !ok {
+			total = &models.CategoryTotal{Category: txn.Category}
+			byCategory[txn.Category] = total
+		}
+		if txn.SourceAccountID == accountID {
+			total.Spent = total.Spent.Add(txn.Amount)
+		}
+		if txn.DestinationAccountID == accountID {
+			total.Received = total.Received.Add(txn.Amount)
+		}
+		total.Count++
+	}
+
+	totals := make([]*models.CategoryTotal, 0, len(byCategory))
+	for _, total := range byCategory {
+		totals = append(totals, total)
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Category < totals[j].Category })
+	return totals, nil
+}
+
+// CreateRecurring inserts an active recurring transfer.
+func (r *TransactionRepository) CreateRecurring(ctx context.Context, recurring *models.RecurringTransfer) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range []int64{recurring.SourceAccountID, recurring.DestinationAccountID} {
+		if _, exists := s.accounts[id]; !exists {
+			return models.ErrAccountNotFound
+		}
+	}
+
+	s.lastRecurringID++
+	recurring.RecurringID = s.lastRecurringID
+	recurring.Status = models.RecurringStatusActive
+	recurring.CreatedAt = time.Now()
+	stored := *recurring
+	s.recurring[recurring.RecurringID] = &stored
+	return nil
+}
+
+// GetRecurring retrieves a recurring transfer by its ID.
+// Returns ErrRecurringTransferNotFound if it does not exist.
+func (r *TransactionRepository) GetRecurring(ctx context.Context, recurringID int64) (*models.RecurringTransfer, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	recurring, exists := s.recurring[recurringID]
+	if !exists {
+		return nil, models.ErrRecurringTransferNotFound
+	}
+	copied := *recurring
+	return &copied, nil
+}
+
+// ListDueRecurring returns up to limit active recurring transfers due at or before now,
+// earliest first.
+func (r *TransactionRepository) ListDueRecurring(ctx context.Context, now time.Time, limit int) ([]*models.RecurringTransfer, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	due := make([]*models.RecurringTransfer, 0)
+	for _, recurring := range s.recurring {
+		if recurring.Status == models.RecurringStatusActive && !recurring.NextRunAt.After(now) {
+			copied := *recurring
+			due = append(due, &copied)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextRunAt.Equal(due[j].NextRunAt) {
+			return due[i].NextRunAt.Before(due[j].NextRunAt)
+		}
+		return due[i].RecurringID < due[j].RecurringID
+	})
+	return page(due, limit, 0), nil
+}
+
+// AdvanceRecurring stores a run's outcome while the stored schedule is still active and
+// due at slot. Returns ErrRecurringTransferNotActive otherwise.
+func (r *TransactionRepository) AdvanceRecurring(ctx context.Context, recurring *models.RecurringTransfer, slot time.Time) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, exists := s.recurring[recurring.RecurringID]
+	if !exists || stored.Status != models.RecurringStatusActive || !stored.NextRunAt.Equal(slot) {
+		return models.ErrRecurringTransferNotActive
+	}
+	stored.NextRunAt = recurring.NextRunAt
+	stored.Status = recurring.Status
+	stored.Runs = recurring.Runs
+	stored.LastRunAt = recurring.LastRunAt
+	stored.LastTransactionID = recurring.LastTransactionID
+	stored.LastError = recurring.LastError
+	return nil
+}
+
+// CancelRecurring marks an active recurring transfer canceled and returns it.
+func (r *TransactionRepository) CancelRecurring(ctx context.Context, recurringID int64) (*models.RecurringTransfer, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, exists := s.recurring[recurringID]
+	if !exists {
+		return nil, models.ErrRecurringTransferNotFound
+	}
+	if stored.Status != models.RecurringStatusActive {
+		return nil, models.ErrRecurringTransferNotActive
+	}
+	canceledAt := time.Now()
+	stored.Status = models.RecurringStatusCanceled
+	stored.CanceledAt = &canceledAt
+	copied := *stored
+	return &copied, nil
+}
+
+// VerifyLedger checks every account's balance against its initial balance, ledger
+// entries, and adjustments. It reads under the transaction lock, so no transfer is
+// half-applied while it runs.
+func (r *TransactionRepository) VerifyLedger(ctx context.Context, limit int) (*models.LedgerVerification, error) {
+	s := r.store
+	t, err := s.begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin ledger verification: %w", err)
+	}
+	// Nothing is written, so rolling back only releases the lock
+	defer t.Rollback(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v := &models.LedgerVerification{Accounts: int64(len(s.accounts))}
+	expected := make(map[int64]decimal.Decimal, len(s.accounts))
+	for id, row := range s.accounts {
+		v.TotalBalance = v.TotalBalance.Add(row.account.Balance)
+		v.InitialBalances = v.InitialBalances.Add(row.account.InitialBalance)
+		expected[id] = row.account.InitialBalance
+	}
+	for _, txn := range s.transactions {
+		if txn.Status != models.TransactionStatusCompleted {
+			continue
+		}
+		switch txn.Type {
+		case models.TransactionTypeDeposit:
+			v.NetTransactions = v.NetTransactions.Add(txn.Amount)
+		case models.TransactionTypeWithdrawal:
+			v.NetTransactions = v.NetTransactions.Sub(txn.Amount)
+		}
+	}
+	for _, entry := range s.ledger {
+		expected[entry.AccountID] = expected[entry.AccountID].Add(entry.Amount)
+	}
+	for _, adjustment := range s.adjustments {
+		v.Adjustments = v.Adjustments.Add(adjustment.Delta)
+		expected[adjustment.AccountID] = expected[adjustment.AccountID].Add(adjustment.Delta)
+	}
+
+	v.DriftedAccounts = make([]*models.AccountDrift, 0)
+	for id, row := range s.accounts {
+		if !row.account.Balance.Equal(expected[id]) {
+			v.DriftedAccounts = append(v.DriftedAccounts, &models.AccountDrift{AccountID: id, Balance: row.account.Balance, Expected: expected[id]})
+		}
+	}
+	sort.Slice(v.DriftedAccounts, func(i, j int) bool { return v.DriftedAccounts[i].AccountID < v.DriftedAccounts[j].AccountID })
+	v.DriftedAccounts = page(v.DriftedAccounts, limit, 0)
+	return v, nil
+}
@@ -0,0 +1,718 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// These mirror the service package's integration tests, run against the memory store.
+
+func setup(t *testing.T) (*service.TransferService, *service.AccountService, *AccountRepository, *TransactionRepository) {
+	t.Helper()
+	store := NewStore()
+	accRepo := NewAccountRepository(store)
+	txnRepo := NewTransactionRepository(store)
+	return service.NewTransferService(accRepo, txnRepo), service.NewAccountService(accRepo), accRepo, txnRepo
+}
+
+func createAccount(t *testing.T, svc *service.AccountService, id int64, balance string) {
+	t.Helper()
+	_, err := svc.CreateAccount(context.Background(), &models.CreateAccountRequest{
+		AccountID: id, InitialBalance: balance,
+	})
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+}
+
+func TestTx_Rollback(t *testing.T) {
+	_, accSvc, accRepo, _ := setup(t)
+	ctx := context.Background()
+	createAccount(t, accSvc, 1, "100")
+
+	tx, err := accRepo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := accRepo.UpdateBalance(ctx, tx, 1, decimal.NewFromInt(40)); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if acc, _ := accRepo.GetByID(ctx, 1); !acc.Balance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected the rollback to restore 100, got %s", acc.Balance)
+	}
+
+	if err := tx.Rollback(ctx); !errors.Is(err, pgx.ErrTxClosed) {
+		t.Errorf("expected ErrTxClosed rolling back twice, got %v", err)
+	}
+	if err := accRepo.UpdateBalance(ctx, tx, 1, decimal.NewFromInt(40)); !errors.Is(err, pgx.ErrTxClosed) {
+		t.Errorf("expected ErrTxClosed writing through a finished transaction, got %v", err)
+	}
+
+	// The lock was released, so the next transaction starts
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	tx, err = accRepo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin after rollback: %v", err)
+	}
+	tx.Commit(ctx)
+}
+
+func TestTx_BeginWaitsForOpenTransaction(t *testing.T) {
+	_, _, accRepo, _ := setup(t)
+	ctx := context.Background()
+
+	tx, err := accRepo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := accRepo.BeginTx(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the second transaction to wait until the deadline, got %v", err)
+	}
+}
+
+func TestTransactionRepository_Create_ConstraintViolations(t *testing.T) {
+	_, accSvc, accRepo, txnRepo := setup(t)
+	ctx := context.Background()
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "100")
+
+	missing := int64(999)
+	cases := []struct {
+		name string
+		txn  models.Transaction
+		want error
+	}{
+		{"unknown destination", models.Transaction{SourceAccountID: 1, DestinationAccountID: 99, Amount: decimal.NewFromInt(10)}, models.ErrAccountNotFound},
+		{"unknown source", models.Transaction{SourceAccountID: 99, DestinationAccountID: 1, Amount: decimal.NewFromInt(10)}, models.ErrAccountNotFound},
+		{"zero amount", models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.Zero}, models.ErrInvalidAmount},
+		{"unknown reversal", models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10), ReversalOf: &missing}, models.ErrTransferNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tx, _ := accRepo.BeginTx(ctx)
+			defer tx.Rollback(ctx)
+			if err := txnRepo.Create(ctx, tx, &tc.txn); !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	tx, _ := accRepo.BeginTx(ctx)
+	defer tx.Rollback(ctx)
+	err := txnRepo.Create(ctx, tx, &models.Transaction{SourceAccountID: 1, DestinationAccountID: 1, Amount: decimal.NewFromInt(10)})
+	if _, ok := models.IsDomainError(err); err == nil || ok {
+		t.Errorf("expected a plain error for a self-transfer, got %v", err)
+	}
+}
+
+func TestBasicTransfer(t *testing.T) {
+	transferSvc, accSvc, accRepo, _ := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500")
+
+	txn, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if txn.TransactionID == 0 {
+		t.Error("expected transaction ID")
+	}
+
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	acc2, _ := accRepo.GetByID(ctx, 2)
+	if !acc1.Balance.Equal(decimal.NewFromInt(900)) {
+		t.Errorf("source balance: got %s", acc1.Balance)
+	}
+	if !acc2.Balance.Equal(decimal.NewFromInt(600)) {
+		t.Errorf("dest balance: got %s", acc2.Balance)
+	}
+}
+
+func TestInsufficientBalance(t *testing.T) {
+	transferSvc, accSvc, _, _ := setup(t)
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "500")
+
+	_, err := transferSvc.Transfer(context.Background(), &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "200",
+	})
+	if !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Errorf("expected insufficient balance, got %v", err)
+	}
+}
+
+func TestClosedAccount(t *testing.T) {
+	transferSvc, accSvc, accRepo, _ := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+
+	closed, err := accSvc.CloseAccount(ctx, 2, false)
+	if err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := accSvc.CloseAccount(ctx, 2, false); !errors.Is(err, models.ErrAccountAlreadyClosed) {
+		t.Errorf("expected ErrAccountAlreadyClosed, got %v", err)
+	}
+
+	acc, err := accRepo.GetByID(ctx, 2)
+	if err != nil || acc.ClosedAt == nil || !acc.ClosedAt.Equal(*closed.ClosedAt) {
+		t.Fatalf("expected a stored closed_at, got %+v, %v", acc, err)
+	}
+
+	_, err = transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "10",
+	})
+	if !errors.Is(err, models.ErrAccountClosed) {
+		t.Errorf("expected ErrAccountClosed, got %v", err)
+	}
+	if _, err := accSvc.CloseAccount(ctx, 1, false); !errors.Is(err, models.ErrAccountNotEmpty) {
+		t.Errorf("expected ErrAccountNotEmpty, got %v", err)
+	}
+}
+
+func TestOverdraft(t *testing.T) {
+	transferSvc, accSvc, accRepo, _ := setup(t)
+	ctx := context.Background()
+
+	if _, err := accSvc.CreateAccount(ctx, &models.CreateAccountRequest{
+		AccountID: 1, InitialBalance: "50", OverdraftLimit: "100",
+	}); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	createAccount(t, accSvc, 2, "0")
+
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "120",
+	}); err != nil {
+		t.Fatalf("transfer within overdraft: %v", err)
+	}
+	acc, _ := accRepo.GetByID(ctx, 1)
+	if !acc.Balance.Equal(decimal.NewFromInt(-70)) || !acc.OverdraftLimit.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected balance -70 with a 100 limit, got %s and %s", acc.Balance, acc.OverdraftLimit)
+	}
+	history, _ := accRepo.ListBalanceHistory(ctx, 1, 10, 0)
+	if len(history) == 0 || !history[0].NewBalance.Equal(decimal.NewFromInt(-70)) {
+		t.Errorf("expected the negative balance in the history, got %+v", history)
+	}
+
+	_, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "30.01",
+	})
+	if !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance beyond the overdraft, got %v", err)
+	}
+	if acc, _ := accRepo.GetByID(ctx, 1); !acc.Balance.Equal(decimal.NewFromInt(-70)) {
+		t.Errorf("expected the balance unchanged at -70, got %s", acc.Balance)
+	}
+
+	// The store enforces the limit too, as the CHECK constraint does
+	tx, _ := accRepo.BeginTx(ctx)
+	defer tx.Rollback(ctx)
+	if err := accRepo.UpdateBalance(ctx, tx, 1, decimal.NewFromInt(-101)); err == nil {
+		t.Error("expected the store to reject a balance below -overdraft_limit")
+	}
+}
+
+func TestConcurrentTransfers(t *testing.T) {
+	transferSvc, accSvc, accRepo, _ := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "10000")
+	createAccount(t, accSvc, 2, "10000")
+
+	var wg sync.WaitGroup
+	var success atomic.Int32
+
+	// 50 transfers each direction
+	for i := 0; i < 50; i++ {
+		for _, pair := range [][2]int64{{1, 2}, {2, 1}} {
+			wg.Add(1)
+			go func(source, dest int64) {
+				defer wg.Done()
+				_, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+					SourceAccountID: source, DestinationAccountID: dest, Amount: "10",
+				})
+				if err == nil {
+					success.Add(1)
+				}
+			}(pair[0], pair[1])
+		}
+	}
+	wg.Wait()
+
+	// Transactions run one at a time, so none fail
+	if success.Load() != 100 {
+		t.Errorf("expected 100 successful transfers, got %d", success.Load())
+	}
+
+	// Total balance must be conserved
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	acc2, _ := accRepo.GetByID(ctx, 2)
+	total := acc1.Balance.Add(acc2.Balance)
+	if !total.Equal(decimal.NewFromInt(20000)) {
+		t.Errorf("balance mismatch: %s + %s = %s", acc1.Balance, acc2.Balance, total)
+	}
+}
+
+func TestConcurrentTransfers_Optimistic(t *testing.T) {
+	_, accSvc, accRepo, txnRepo := setup(t)
+	ctx := context.Background()
+
+	cfg := service.DefaultTransferConfig()
+	cfg.ConcurrencyMode = service.ConcurrencyOptimistic
+	cfg.MaxRetries = 20
+	cfg.RetryBaseDelay = time.Millisecond
+	transferSvc := service.NewTransferServiceWithConfig(accRepo, txnRepo, cfg)
+
+	createAccount(t, accSvc, 1, "10000")
+	createAccount(t, accSvc, 2, "10000")
+	createAccount(t, accSvc, 3, "10000")
+
+	var wg sync.WaitGroup
+	var success atomic.Int32
+
+	for i := 0; i < 30; i++ {
+		for _, pair := range [][2]int64{{1, 2}, {2, 3}, {3, 1}} {
+			wg.Add(1)
+			go func(source, dest int64) {
+				defer wg.Done()
+				_, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+					SourceAccountID: source, DestinationAccountID: dest, Amount: "10",
+				})
+				if err == nil {
+					success.Add(1)
+				} else if code, _ := models.IsDomainError(err); code != models.CodeTransactionFailed {
+					// Version conflicts must be retried, surfacing only once retries run out
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(pair[0], pair[1])
+		}
+	}
+	wg.Wait()
+
+	if success.Load() == 0 {
+		t.Fatal("expected some optimistic transfers to succeed")
+	}
+
+	total := decimal.Zero
+	for _, id := range []int64{1, 2, 3} {
+		acc, err := accRepo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("get account %d: %v", id, err)
+		}
+		total = total.Add(acc.Balance)
+	}
+	if !total.Equal(decimal.NewFromInt(30000)) {
+		t.Errorf("balance not conserved: total %s", total)
+	}
+
+	// No lost updates: account 1's balance must match its committed transfers exactly
+	txns, _ := transferSvc.GetAccountTransactions(ctx, 1, 1000, 0)
+	var in, out int
+	for _, txn := range txns {
+		if txn.SourceAccountID == 1 {
+			out++
+		} else {
+			in++
+		}
+	}
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	if want := decimal.NewFromInt(10000 + 10*int64(in-out)); !acc1.Balance.Equal(want) {
+		t.Errorf("account 1: expected %s from its %d in / %d out transfers, got %s", want, in, out, acc1.Balance)
+	}
+	if acc1.Version != in+out {
+		t.Errorf("account 1: expected version %d after %d transfers, got %d", in+out, in+out, acc1.Version)
+	}
+}
+
+func TestRaceForSameBalance(t *testing.T) {
+	transferSvc, accSvc, accRepo, _ := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+
+	var wg sync.WaitGroup
+	var success atomic.Int32
+
+	// 20 goroutines try to transfer entire balance
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+				SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+			})
+			if err == nil {
+				success.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Exactly one should succeed
+	if success.Load() != 1 {
+		t.Errorf("expected 1 success, got %d", success.Load())
+	}
+
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	acc2, _ := accRepo.GetByID(ctx, 2)
+	if !acc1.Balance.IsZero() || !acc2.Balance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("unexpected balances: %s, %s", acc1.Balance, acc2.Balance)
+	}
+}
+
+func TestCaptureHold(t *testing.T) {
+	transferSvc, accSvc, accRepo, _ := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+
+	hold, err := transferSvc.PlaceHold(ctx, 1, decimal.NewFromInt(40))
+	if err != nil {
+		t.Fatalf("place hold: %v", err)
+	}
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "61",
+	}); !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Errorf("expected the hold to block a transfer of 61, got %v", err)
+	}
+
+	captured, err := transferSvc.CaptureHold(ctx, hold.HoldID)
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if captured.Status != models.HoldStatusCaptured || captured.TransactionID == nil || captured.ResolvedAt == nil {
+		t.Fatalf("expected a captured hold with a withdrawal, got %+v", captured)
+	}
+
+	acc, _ := accRepo.GetByID(ctx, 1)
+	if !acc.Balance.Equal(decimal.NewFromInt(60)) || !acc.HeldBalance.IsZero() {
+		t.Errorf("expected balance 60 with nothing held, got %s and %s", acc.Balance, acc.HeldBalance)
+	}
+
+	if _, err := transferSvc.CaptureHold(ctx, hold.HoldID); !errors.Is(err, models.ErrHoldNotActive) {
+		t.Errorf("expected ErrHoldNotActive capturing twice, got %v", err)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	transferSvc, accSvc, accRepo, txnRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500")
+
+	original, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	reversal, err := transferSvc.Reverse(ctx, original.TransactionID)
+	if err != nil {
+		t.Fatalf("reverse: %v", err)
+	}
+
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	acc2, _ := accRepo.GetByID(ctx, 2)
+	if !acc1.Balance.Equal(decimal.NewFromInt(1000)) || !acc2.Balance.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("balances not restored: %s, %s", acc1.Balance, acc2.Balance)
+	}
+
+	stored, err := txnRepo.GetByID(ctx, reversal.TransactionID)
+	if err != nil || stored.ReversalOf == nil || *stored.ReversalOf != original.TransactionID {
+		t.Errorf("reversal link not stored: %+v, %v", stored, err)
+	}
+
+	_, err = transferSvc.Reverse(ctx, original.TransactionID)
+	if !errors.Is(err, models.ErrAlreadyReversed) {
+		t.Errorf("expected ErrAlreadyReversed, got %v", err)
+	}
+}
+
+func TestConcurrentReverse(t *testing.T) {
+	transferSvc, accSvc, accRepo, _ := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500")
+
+	original, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var success, alreadyReversed atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := transferSvc.Reverse(ctx, original.TransactionID)
+			switch {
+			case err == nil:
+				success.Add(1)
+			case errors.Is(err, models.ErrAlreadyReversed):
+				alreadyReversed.Add(1)
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if success.Load() != 1 || alreadyReversed.Load() != 9 {
+		t.Errorf("expected 1 reversal and 9 rejections, got %d and %d", success.Load(), alreadyReversed.Load())
+	}
+
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	acc2, _ := accRepo.GetByID(ctx, 2)
+	if !acc1.Balance.Equal(decimal.NewFromInt(1000)) || !acc2.Balance.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("balances not restored exactly once: %s, %s", acc1.Balance, acc2.Balance)
+	}
+}
+
+// failOnLeg aborts the batch when the transaction for the given destination is inserted.
+type failOnLeg struct{ dest int64 }
+
+func (f failOnLeg) PreCommit(_ context.Context, _ pgx.Tx, txn *models.Transaction) error {
+	if txn.DestinationAccountID == f.dest {
+		return errors.New("leg rejected")
+	}
+	return nil
+}
+func (f failOnLeg) PostCommit(context.Context, *models.Transaction) {}
+
+func TestBatchTransfer_NoPartialApplication(t *testing.T) {
+	transferSvc, accSvc, accRepo, txnRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+	createAccount(t, accSvc, 3, "0")
+
+	// The second leg fails after the first leg's balances and row were written
+	transferSvc.AddHook(failOnLeg{dest: 3})
+	_, err := transferSvc.BatchTransfer(ctx, &models.CreateBatchTransferRequest{
+		SourceAccountID: 1,
+		Transfers: []models.BatchTransferItem{
+			{DestinationAccountID: 2, Amount: "10"},
+			{DestinationAccountID: 3, Amount: "20"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected the batch to fail")
+	}
+
+	for id, want := range map[int64]int64{1: 100, 2: 0, 3: 0} {
+		acc, _ := accRepo.GetByID(ctx, id)
+		if !acc.Balance.Equal(decimal.NewFromInt(want)) {
+			t.Errorf("account %d: expected %d, got %s", id, want, acc.Balance)
+		}
+	}
+
+	if txns, _ := txnRepo.GetByAccountID(ctx, 1, 10, 0); len(txns) != 0 {
+		t.Errorf("expected no transactions after rollback, got %d", len(txns))
+	}
+}
+
+func TestBatchTransfer(t *testing.T) {
+	transferSvc, accSvc, accRepo, _ := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+	createAccount(t, accSvc, 3, "0")
+
+	txns, err := transferSvc.BatchTransfer(ctx, &models.CreateBatchTransferRequest{
+		SourceAccountID: 1,
+		Transfers: []models.BatchTransferItem{
+			{DestinationAccountID: 3, Amount: "25"},
+			{DestinationAccountID: 2, Amount: "75"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("batch transfer: %v", err)
+	}
+	if len(txns) != 2 || txns[0].TransactionID == 0 || txns[1].TransactionID == 0 {
+		t.Fatalf("expected two stored transactions, got %+v", txns)
+	}
+
+	for id, want := range map[int64]int64{1: 0, 2: 75, 3: 25} {
+		acc, _ := accRepo.GetByID(ctx, id)
+		if !acc.Balance.Equal(decimal.NewFromInt(want)) {
+			t.Errorf("account %d: expected %d, got %s", id, want, acc.Balance)
+		}
+	}
+}
+
+func TestConcurrentIdempotentDeposits(t *testing.T) {
+	_, accSvc, accRepo, txnRepo := setup(t)
+	ctx := context.Background()
+	ledgerSvc := service.NewLedgerService(accRepo, txnRepo)
+	createAccount(t, accSvc, 1, "100")
+
+	// Every replay must return the one deposit, whichever request committed it
+	const replays = 10
+	ids := make([]int64, replays)
+	var wg sync.WaitGroup
+	for i := 0; i < replays; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry, err := ledgerSvc.Deposit(ctx, 1, &models.LedgerEntryRequest{Amount: "25", IdempotencyKey: "deposit-1"})
+			if err != nil {
+				t.Errorf("deposit %d: %v", i, err)
+				return
+			}
+			ids[i] = entry.TransactionID
+		}(i)
+	}
+	wg.Wait()
+
+	for i, id := range ids {
+		if id != ids[0] {
+			t.Errorf("deposit %d returned transaction %d, expected %d", i, id, ids[0])
+		}
+	}
+	acc, _ := accRepo.GetByID(ctx, 1)
+	if !acc.Balance.Equal(decimal.NewFromInt(125)) {
+		t.Errorf("expected a single credit to 125, got %s", acc.Balance)
+	}
+	if history, _ := accRepo.ListBalanceHistory(ctx, 1, 20, 0); len(history) != 1 {
+		t.Errorf("expected one balance change, got %d", len(history))
+	}
+}
+
+func TestVerifyLedger(t *testing.T) {
+	transferSvc, accSvc, accRepo, txnRepo := setup(t)
+	ctx := context.Background()
+	ledgerSvc := service.NewLedgerService(accRepo, txnRepo)
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500.25")
+	createAccount(t, accSvc, 3, "0")
+
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100"}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 3, DestinationAccountID: 1, Amount: "5"}); !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Fatalf("expected the overdrawing transfer to fail, got %v", err)
+	}
+	if _, err := ledgerSvc.Deposit(ctx, 3, &models.LedgerEntryRequest{Amount: "40"}); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	if _, err := ledgerSvc.Withdraw(ctx, 2, &models.LedgerEntryRequest{Amount: "0.25"}); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if _, err := accSvc.AdjustBalancesBatch(ctx, []models.BalanceDelta{{AccountID: 1, Delta: decimal.NewFromInt(-3)}}, "correction"); err != nil {
+		t.Fatalf("adjust: %v", err)
+	}
+
+	v, err := transferSvc.VerifyLedger(ctx)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !v.Consistent() {
+		t.Fatalf("expected a consistent ledger, got %+v", v)
+	}
+	if v.Accounts != 3 || !v.TotalBalance.Equal(decimal.NewFromInt(1537)) || !v.InitialBalances.Equal(decimal.RequireFromString("1500.25")) ||
+		!v.NetTransactions.Equal(decimal.RequireFromString("39.75")) || !v.Adjustments.Equal(decimal.NewFromInt(-3)) {
+		t.Errorf("unexpected totals %+v", v)
+	}
+
+	// A balance changed behind the ledger's back
+	store := accRepo.store
+	store.mu.Lock()
+	store.accounts[2].account.Balance = store.accounts[2].account.Balance.Add(decimal.NewFromInt(1))
+	store.mu.Unlock()
+
+	v, err = transferSvc.VerifyLedger(ctx)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if v.Consistent() || !v.Drift().Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected drift of 1, got %s", v.Drift())
+	}
+	if len(v.DriftedAccounts) != 1 || v.DriftedAccounts[0].AccountID != 2 ||
+		!v.DriftedAccounts[0].Balance.Equal(decimal.NewFromInt(601)) || !v.DriftedAccounts[0].Expected.Equal(decimal.NewFromInt(600)) {
+		t.Errorf("expected account 2 reported as drifted, got %+v", v.DriftedAccounts)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	transferSvc, accSvc, accRepo, _ := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "50")
+
+	expected := []models.ExpectedBalance{
+		{AccountID: 2, Balance: decimal.NewFromInt(50)},
+		{AccountID: 1, Balance: decimal.RequireFromString("120.25")},
+		{AccountID: 7, Balance: decimal.NewFromInt(1)},
+	}
+
+	report, err := accSvc.Reconcile(ctx, expected, false, "")
+	if err != nil {
+		t.Fatalf("detect: %v", err)
+	}
+	if report.Count(models.ReconciliationMismatched) != 1 || report.Count(models.ReconciliationMissing) != 1 || report.BatchID != 0 {
+		t.Fatalf("unexpected detect-only report %+v", report)
+	}
+	if acc, _ := accRepo.GetByID(ctx, 1); !acc.Balance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("detect-only reconcile changed the balance to %s", acc.Balance)
+	}
+
+	report, err = accSvc.Reconcile(ctx, expected, true, "month-end import")
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if report.Items[0].Status != models.ReconciliationCorrected || report.Items[0].Adjustment == nil || report.BatchID == 0 {
+		t.Fatalf("unexpected applied report %+v", report)
+	}
+	if acc, _ := accRepo.GetByID(ctx, 1); !acc.Balance.Equal(decimal.RequireFromString("120.25")) {
+		t.Errorf("expected 120.25, got %s", acc.Balance)
+	}
+	if v, err := transferSvc.VerifyLedger(ctx); err != nil || !v.Consistent() {
+		t.Errorf("expected a consistent ledger, got %+v err=%v", v, err)
+	}
+
+	again, err := accSvc.Reconcile(ctx, expected, true, "month-end import")
+	if err != nil {
+		t.Fatalf("apply again: %v", err)
+	}
+	if again.Count(models.ReconciliationCorrected) != 0 || again.BatchID != 0 {
+		t.Errorf("expected a repeated import to change nothing, got %+v", again)
+	}
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// errNoSQL is returned by the pgx.Tx methods that would run SQL; the memory store has
+// no database to run it on.
+var errNoSQL = errors.New("memory: SQL is not supported by the in-memory store")
+
+// Tx is a transaction on a Store. It implements pgx.Tx so it can be handed to the
+// repository methods, but only Commit and Rollback do anything; the query methods fail
+// with errNoSQL.
+type Tx struct {
+	store *Store
+
+	// undo reverts each write made in the transaction, in the order they were made.
+	// Guarded by store.mu.
+	undo []func()
+	done bool
+}
+
+// Compile-time check to ensure Tx implements pgx.Tx.
+var _ pgx.Tx = (*Tx)(nil)
+
+// begin waits for the store's transaction lock and returns a transaction holding it.
+func (s *Store) begin(ctx context.Context) (*Tx, error) {
+	select {
+	case s.txSlot <- struct{}{}:
+		return &Tx{store: s}, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("begin transaction: %w", ctx.Err())
+	}
+}
+
+// open returns tx as a live transaction of s, or an error if it isn't one.
+func (s *Store) open(tx pgx.Tx) (*Tx, error) {
+	t, ok := tx.(*Tx)
+	if !ok || t == nil || t.store != s {
+		return nil, errors.New("memory: transaction does not belong to this store")
+	}
+	if t.done {
+		return nil, pgx.ErrTxClosed
+	}
+	return t, nil
+}
+
+// onRollback records how to revert a write. The caller holds store.mu.
+func (t *Tx) onRollback(fn func()) {
+	t.undo = append(t.undo, fn)
+}
+
+// Commit keeps the transaction's writes and releases the store's transaction lock.
+func (t *Tx) Commit(ctx context.Context) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.done {
+		return pgx.ErrTxClosed
+	}
+	t.done, t.undo = true, nil
+	<-t.store.txSlot
+	return nil
+}
+
+// Rollback reverts the transaction's writes and releases the store's transaction lock.
+// Rolling back a finished transaction returns pgx.ErrTxClosed, as pgx does.
+func (t *Tx) Rollback(ctx context.Context) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.done {
+		return pgx.ErrTxClosed
+	}
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+	t.done, t.undo = true, nil
+	<-t.store.txSlot
+	return nil
+}
+
+// Begin would start a savepoint, which the memory store doesn't support.
+func (t *Tx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, errors.New("memory: nested transactions are not supported")
+}
+
+func (t *Tx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, errNoSQL
+}
+
+func (t *Tx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return errBatchResults{}
+}
+
+func (t *Tx) LargeObjects() pgx.LargeObjects { return pgx.LargeObjects{} }
+
+func (t *Tx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	return nil, errNoSQL
+}
+
+func (t *Tx) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errNoSQL
+}
+
+func (t *Tx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, errNoSQL
+}
+
+func (t *Tx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row { return errRow{} }
+
+func (t *Tx) Conn() *pgx.Conn { return nil }
+
+// errRow is the pgx.Row QueryRow returns; scanning it fails with errNoSQL.
+type errRow struct{}
+
+func (errRow) Scan(dest ...any) error { return errNoSQL }
+
+// errBatchResults is the pgx.BatchResults SendBatch returns; every result is errNoSQL.
+type errBatchResults struct{}
+
+func (errBatchResults) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, errNoSQL }
+func (errBatchResults) Query() (pgx.Rows, error)         { return nil, errNoSQL }
+func (errBatchResults) QueryRow() pgx.Row                { return errRow{} }
+func (errBatchResults) Close() error                     { return nil }
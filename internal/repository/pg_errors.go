@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"errors"
+
+	"internal-transfers-system/internal/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATEs for the integrity constraint violations the repositories translate.
+const (
+	foreignKeyViolation = "23503"
+	checkViolation      = "23514"
+	uniqueViolation     = "23505"
+)
+
+// transactionsAmountCheck is the CHECK constraint keeping transactions.amount positive.
+const transactionsAmountCheck = "transactions_amount_check"
+
+// transactionsReversalOfFK is the FOREIGN KEY from transactions.reversal_of to the
+// reversed transaction.
+const transactionsReversalOfFK = "transactions_reversal_of_fkey"
+
+// classifyTransactionError maps a constraint violation raised by writing a transaction
+// row to the domain error it stands for, so a request that slips past the service checks
+// (an account deleted mid-request, say) is reported as the caller's mistake rather than
+// a database failure. It returns nil if err is not one it recognises.
+func classifyTransactionError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch pgErr.Code {
+	case uniqueViolation:
+		switch pgErr.ConstraintName {
+		case idempotencyKeyIndex:
+			return models.ErrDuplicateTransaction
+		case reversalOfIndex:
+			return models.ErrAlreadyReversed
+		}
+	case foreignKeyViolation:
+		if pgErr.ConstraintName == transactionsReversalOfFK {
+			return models.ErrTransferNotFound
+		}
+		return models.ErrAccountNotFound
+	case checkViolation:
+		if pgErr.ConstraintName == transactionsAmountCheck {
+			return models.ErrInvalidAmount
+		}
+	}
+	return nil
+}
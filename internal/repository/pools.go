@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"internal-transfers-system/internal/consistency"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Pools are the connection pools repositories route queries through. Locking transfers,
+// lightweight reads, and long exports hold connections for very different lengths of
+// time, so giving each its own independently sized pool keeps a few slow exports from
+// starving transfers of connections.
+type Pools struct {
+	// Transfer serves read-write transactions (BeginTx) and standalone writes.
+	Transfer *pgxpool.Pool
+
+	// Read serves short lookups and page reads. Nil means Transfer.
+	Read *pgxpool.Pool
+
+	// Export serves long scans such as the account export. Nil means Read.
+	Export *pgxpool.Pool
+
+	// Replica serves the hottest lookups (account GetByID and Exists, and an account's
+	// transaction page) from a read replica, to offload the primary. Nil means Read.
+	// Reads inside a transaction always run on the transaction's own connection.
+	Replica *pgxpool.Pool
+
+	// AcquireTimeout bounds how long BeginTx waits for a free Transfer connection before
+	// failing with ErrServiceBusy. Zero waits as long as the caller's context allows.
+	AcquireTimeout time.Duration
+}
+
+// SinglePool routes every operation through db.
+func SinglePool(db *pgxpool.Pool) Pools {
+	return Pools{Transfer: db}
+}
+
+// withDefaults fills unset pools from the ones they fall back to.
+func (p Pools) withDefaults() Pools {
+	if p.Read == nil {
+		p.Read = p.Transfer
+	}
+	if p.Export == nil {
+		p.Export = p.Read
+	}
+	if p.Replica == nil {
+		p.Replica = p.Read
+	}
+	return p
+}
+
+// ByName returns the pools keyed "transfer", "read", "export", and "replica", with unset
+// pools resolved to the ones they fall back to. A shared pool appears under each of its
+// names.
+func (p Pools) ByName() map[string]*pgxpool.Pool {
+	p = p.withDefaults()
+	return map[string]*pgxpool.Pool{"transfer": p.Transfer, "read": p.Read, "export": p.Export, "replica": p.Replica}
+}
+
+// Close closes each distinct pool once.
+func (p Pools) Close() {
+	p = p.withDefaults()
+	closed := make(map[*pgxpool.Pool]bool, 4)
+	for _, pool := range []*pgxpool.Pool{p.Transfer, p.Read, p.Export, p.Replica} {
+		if pool != nil && !closed[pool] {
+			closed[pool] = true
+			pool.Close()
+		}
+	}
+}
+
+// replicaFor returns the pool for a lookup the replica may serve. That is Replica,
+// unless ctx carries a consistency token the replica hasn't replayed yet, or its replay
+// position can't be read, in which case the lookup goes to Read so the caller sees its
+// own writes.
+func (p Pools) replicaFor(ctx context.Context) *pgxpool.Pool {
+	token, ok := consistency.FromContext(ctx)
+	if !ok || p.Replica == p.Read {
+		return p.Replica
+	}
+
+	// NULL when the server isn't in recovery, i.e. it is a primary and sees every write
+	var text *string
+	if err := p.Replica.QueryRow(ctx, `SELECT pg_last_wal_replay_lsn()::text`).Scan(&text); err != nil {
+		return p.Read
+	}
+	if text == nil {
+		return p.Replica
+	}
+	if replayed, err := consistency.ParseLSN(*text); err != nil || replayed < token {
+		return p.Read
+	}
+	return p.Replica
+}
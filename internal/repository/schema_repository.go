@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationsTable is the tracking table golang-migrate writes to.
+const migrationsTable = "schema_migrations"
+
+// undefinedTable is the SQLSTATE Postgres returns for a missing relation.
+const undefinedTable = "42P01"
+
+// SchemaRepository reads database migration state for diagnostics.
+// All methods are read-only and safe for concurrent use.
+type SchemaRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSchemaRepository creates a new SchemaRepository with the given connection pool.
+func NewSchemaRepository(db *pgxpool.Pool) *SchemaRepository {
+	return &SchemaRepository{db: db}
+}
+
+// Version returns the applied migration version. It returns (nil, nil) when migrations
+// are not tracked in this database, i.e. the tracking table is absent or empty.
+func (r *SchemaRepository) Version(ctx context.Context) (_ *models.SchemaVersion, err error) {
+	ctx, span := startSpan(ctx, "SchemaRepository.Version")
+	defer func() { tracing.End(span, err) }()
+
+	query := fmt.Sprintf(`SELECT version, dirty FROM %s LIMIT 1`, migrationsTable)
+
+	var v models.SchemaVersion
+	err = r.db.QueryRow(ctx, query).Scan(&v.Version, &v.Dirty)
+
+	var pgErr *pgconn.PgError
+	if errors.Is(err, pgx.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == undefinedTable) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read schema version: %w", err)
+	}
+	return &v, nil
+}
@@ -0,0 +1,42 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSchemaRepository_Version(t *testing.T) {
+	repo := NewSchemaRepository(testSuite.Pool())
+	ctx := context.Background()
+
+	// The test suite builds its schema directly, so there is no tracking table yet
+	if _, err := testSuite.Pool().Exec(ctx, `DROP TABLE IF EXISTS schema_migrations`); err != nil {
+		t.Fatalf("drop: %v", err)
+	}
+	v, err := repo.Version(ctx)
+	if err != nil || v != nil {
+		t.Fatalf("absent table: expected nil version and no error, got %+v, %v", v, err)
+	}
+
+	if _, err := testSuite.Pool().Exec(ctx, `CREATE TABLE schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	defer testSuite.Pool().Exec(ctx, `DROP TABLE IF EXISTS schema_migrations`)
+
+	if v, err := repo.Version(ctx); err != nil || v != nil {
+		t.Fatalf("empty table: expected nil version and no error, got %+v, %v", v, err)
+	}
+
+	if _, err := testSuite.Pool().Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES (10, false)`); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	v, err = repo.Version(ctx)
+	if err != nil {
+		t.Fatalf("version: %v", err)
+	}
+	if v == nil || v.Version != 10 || v.Dirty {
+		t.Errorf("expected version 10 clean, got %+v", v)
+	}
+}
@@ -4,30 +4,47 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"internal-transfers-system/internal/interfaces"
 	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/tracing"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// idempotencyKeyIndex is the unique index guarding transactions.idempotency_key.
+const idempotencyKeyIndex = "idx_transactions_idempotency_key"
+
+// reversalOfIndex is the unique index guarding transactions.reversal_of.
+const reversalOfIndex = "idx_transactions_reversal_of"
+
 // Compile-time check to ensure TransactionRepository implements interfaces.TransactionRepository.
 var _ interfaces.TransactionRepository = (*TransactionRepository)(nil)
 
 // TransactionRepository provides data access operations for transactions.
 // All methods are safe for concurrent use.
 type TransactionRepository struct {
-	db *pgxpool.Pool
+	pools Pools
 }
 
 // NewTransactionRepository creates a new TransactionRepository with the given connection pool.
 func NewTransactionRepository(db *pgxpool.Pool) *TransactionRepository {
-	return &TransactionRepository{db: db}
+	return NewTransactionRepositoryWithPools(SinglePool(db))
+}
+
+// NewTransactionRepositoryWithPools creates a TransactionRepository that serves its reads
+// from pools.Read, except GetByAccountID, which pools.Replica serves. Create runs inside the caller's transaction, so it uses whichever pool
+// began it.
+func NewTransactionRepositoryWithPools(pools Pools) *TransactionRepository {
+	return &TransactionRepository{pools: pools.withDefaults()}
 }
 
 // Create inserts a new transaction record within a database transaction.
 // The transaction's TransactionID and CreatedAt fields are populated from the database.
+// An empty Status is stored as pending.
 //
 // This method must be called within an active database transaction (tx).
 // The caller is responsible for committing or rolling back the transaction.
@@ -36,35 +53,121 @@ func NewTransactionRepository(db *pgxpool.Pool) *TransactionRepository {
 //   - amount > 0 via CHECK constraint
 //   - source and destination accounts exist via FOREIGN KEY constraints
 //   - source != destination via CHECK constraint
-func (r *TransactionRepository) Create(ctx context.Context, tx pgx.Tx, transaction *models.Transaction) error {
+//   - only the sides the type allows are set via CHECK constraint (0 is stored as NULL)
+//   - idempotency_key uniqueness via a partial UNIQUE index
+//   - reversal_of uniqueness among transactions that didn't fail via a partial UNIQUE index
+//   - fee amount, account, and payer set together, on transfers only, via CHECK constraint
+//
+// Returns ErrDuplicateTransaction if the idempotency key is already taken,
+// ErrAlreadyReversed if ReversalOf names a transaction that already has a reversal,
+// ErrAccountNotFound if an account doesn't exist, ErrTransferNotFound if ReversalOf
+// doesn't, or ErrInvalidAmount if the amount isn't positive.
+func (r *TransactionRepository) Create(ctx context.Context, tx pgx.Tx, transaction *models.Transaction) (err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.Create", tracing.AttrSourceAccountID.Int64(transaction.SourceAccountID), tracing.AttrDestinationAccountID.Int64(transaction.DestinationAccountID), tracing.AttrAmount.String(transaction.Amount.String()))
+	defer func() { tracing.End(span, err) }()
+
 	query := `
-		INSERT INTO transactions (source_account_id, destination_account_id, amount, created_at)
-		VALUES ($1, $2, $3, NOW())
-		RETURNING transaction_id, created_at`
+		INSERT INTO transactions (type, source_account_id, destination_account_id, amount, idempotency_key, effective_date, reversal_of, category, status, fee_amount, fee_account_id, fee_paid_by, created_at)
+		VALUES ($1, NULLIF($2::bigint, 0), NULLIF($3::bigint, 0), $4, NULLIF($5, ''), COALESCE($6::date, (NOW() AT TIME ZONE 'UTC')::date), $7, NULLIF($8, ''), $9, $10, NULLIF($11::bigint, 0), NULLIF($12, ''), NOW())
+		RETURNING transaction_id, effective_date, created_at`
 
-	err := tx.QueryRow(ctx, query,
+	if transaction.Type == "" {
+		transaction.Type = models.TransactionTypeTransfer
+	}
+	if transaction.Status == "" {
+		transaction.Status = models.TransactionStatusPending
+	}
+
+	err = tx.QueryRow(ctx, query,
+		string(transaction.Type),
 		transaction.SourceAccountID,
 		transaction.DestinationAccountID,
 		transaction.Amount,
-	).Scan(&transaction.TransactionID, &transaction.CreatedAt)
+		transaction.IdempotencyKey,
+		nullableDate(transaction.EffectiveDate),
+		transaction.ReversalOf,
+		transaction.Category,
+		string(transaction.Status),
+		transaction.FeeAmount,
+		transaction.FeeAccountID,
+		string(transaction.FeePaidBy),
+	).Scan(&transaction.TransactionID, &transaction.EffectiveDate, &transaction.CreatedAt)
 
 	if err != nil {
+		if domainErr := classifyTransactionError(err); domainErr != nil {
+			return domainErr
+		}
 		return fmt.Errorf("insert transaction: %w", err)
 	}
 	return nil
 }
 
+// CreateLedgerEntries inserts a transaction's ledger entries within tx.
+func (r *TransactionRepository) CreateLedgerEntries(ctx context.Context, tx pgx.Tx, entries ...*models.LedgerEntry) (err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.CreateLedgerEntries")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		INSERT INTO ledger_entries (transaction_id, account_id, amount, created_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING entry_id, created_at`
+
+	for _, entry := range entries {
+		if err = tx.QueryRow(ctx, query, entry.TransactionID, entry.AccountID, entry.Amount).Scan(&entry.EntryID, &entry.CreatedAt); err != nil {
+			return fmt.Errorf("insert ledger entry for transaction %d, account %d: %w", entry.TransactionID, entry.AccountID, err)
+		}
+	}
+	return nil
+}
+
+// GetLedgerByAccountID retrieves an account's ledger entries, newest first.
+func (r *TransactionRepository) GetLedgerByAccountID(ctx context.Context, accountID int64, limit, offset int) (_ []*models.LedgerEntry, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.GetLedgerByAccountID", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT entry_id, transaction_id, account_id, amount, created_at
+		FROM ledger_entries
+		WHERE account_id = $1
+		ORDER BY entry_id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.pools.Read.Query(ctx, query, accountID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list ledger entries for account %d: %w", accountID, err)
+	}
+	defer rows.Close()
+
+	entries := make([]*models.LedgerEntry, 0, limit)
+	for rows.Next() {
+		entry := &models.LedgerEntry{}
+		if err := rows.Scan(&entry.EntryID, &entry.TransactionID, &entry.AccountID, &entry.Amount, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan ledger entry row: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate ledger entry rows: %w", err)
+	}
+
+	return entries, nil
+}
+
 // GetByID retrieves a transaction by its ID.
 // Returns ErrTransferNotFound if the transaction does not exist.
-func (r *TransactionRepository) GetByID(ctx context.Context, transactionID int64) (*models.Transaction, error) {
+func (r *TransactionRepository) GetByID(ctx context.Context, transactionID int64) (_ *models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.GetByID", tracing.AttrTransactionID.Int64(transactionID))
+	defer func() { tracing.End(span, err) }()
+
 	query := `
-		SELECT transaction_id, source_account_id, destination_account_id, amount, created_at
+		SELECT transaction_id, type, COALESCE(source_account_id, 0), COALESCE(destination_account_id, 0), amount, effective_date, reversal_of, COALESCE(category, ''), created_at, status, fee_amount, COALESCE(fee_account_id, 0), COALESCE(fee_paid_by, '')
 		FROM transactions
 		WHERE transaction_id = $1`
 
 	txn := &models.Transaction{}
-	err := r.db.QueryRow(ctx, query, transactionID).
-		Scan(&txn.TransactionID, &txn.SourceAccountID, &txn.DestinationAccountID, &txn.Amount, &txn.CreatedAt)
+	err = r.pools.Read.QueryRow(ctx, query, transactionID).
+		Scan(&txn.TransactionID, &txn.Type, &txn.SourceAccountID, &txn.DestinationAccountID, &txn.Amount, &txn.EffectiveDate, &txn.ReversalOf, &txn.Category, &txn.CreatedAt, &txn.Status, &txn.FeeAmount, &txn.FeeAccountID, &txn.FeePaidBy)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, models.ErrTransferNotFound
@@ -75,9 +178,116 @@ func (r *TransactionRepository) GetByID(ctx context.Context, transactionID int64
 	return txn, nil
 }
 
+// GetReversal retrieves the transaction that reverses the given transaction, ignoring
+// failed attempts. Returns ErrTransferNotFound if the transaction has not been reversed.
+func (r *TransactionRepository) GetReversal(ctx context.Context, transactionID int64) (_ *models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.GetReversal", tracing.AttrTransactionID.Int64(transactionID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT transaction_id, type, COALESCE(source_account_id, 0), COALESCE(destination_account_id, 0), amount, effective_date, reversal_of, COALESCE(category, ''), created_at, status, fee_amount, COALESCE(fee_account_id, 0), COALESCE(fee_paid_by, '')
+		FROM transactions
+		WHERE reversal_of = $1 AND status <> 'failed'`
+
+	txn := &models.Transaction{}
+	err = r.pools.Read.QueryRow(ctx, query, transactionID).
+		Scan(&txn.TransactionID, &txn.Type, &txn.SourceAccountID, &txn.DestinationAccountID, &txn.Amount, &txn.EffectiveDate, &txn.ReversalOf, &txn.Category, &txn.CreatedAt, &txn.Status, &txn.FeeAmount, &txn.FeeAccountID, &txn.FeePaidBy)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get reversal of transaction %d: %w", transactionID, err)
+	}
+	return txn, nil
+}
+
+// SinceLastOutboundTransfer returns how long ago accountID last sent a transfer, by the
+// database clock, and false if it never has. Reversals and failed transfers don't count.
+// The lookup walks the (source_account_id, created_at) index newest first.
+func (r *TransactionRepository) SinceLastOutboundTransfer(ctx context.Context, tx pgx.Tx, accountID int64) (_ time.Duration, _ bool, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.SinceLastOutboundTransfer", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT EXTRACT(EPOCH FROM clock_timestamp() - created_at)::float8
+		FROM transactions
+		WHERE source_account_id = $1 AND type = 'transfer' AND reversal_of IS NULL AND status <> 'failed'
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	var seconds float64
+	err = tx.QueryRow(ctx, query, accountID).Scan(&seconds)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("last outbound transfer for account %d: %w", accountID, err)
+	}
+	return time.Duration(seconds * float64(time.Second)), true, nil
+}
+
+// DailyOutbound totals accountID's outbound transfers since the start of the current UTC
+// day, by the database clock, alongside the account's daily limit overrides. Reversals
+// and failed transfers don't count. The sum walks the (source_account_id, created_at)
+// index over today's rows only.
+func (r *TransactionRepository) DailyOutbound(ctx context.Context, tx pgx.Tx, accountID int64) (_ *models.DailyOutbound, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.DailyOutbound", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		WITH today AS (
+			SELECT date_trunc('day', clock_timestamp() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS start
+		)
+		SELECT COALESCE(SUM(t.amount), 0), COUNT(t.transaction_id),
+		       a.daily_transfer_amount_limit, a.daily_transfer_count_limit,
+		       (SELECT start FROM today) + interval '1 day'
+		FROM accounts a
+		LEFT JOIN transactions t
+		  ON t.source_account_id = a.account_id AND t.type = 'transfer' AND t.reversal_of IS NULL
+		 AND t.status <> 'failed' AND t.created_at >= (SELECT start FROM today)
+		WHERE a.account_id = $1
+		GROUP BY a.account_id`
+
+	var out models.DailyOutbound
+	err = tx.QueryRow(ctx, query, accountID).Scan(&out.Amount, &out.Count, &out.AmountLimit, &out.CountLimit, &out.ResetsAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("daily outbound transfers for account %d: %w", accountID, err)
+	}
+	return &out, nil
+}
+
+// GetByIdempotencyKey retrieves the transaction created with the given idempotency key.
+// Returns ErrTransferNotFound if no transaction uses the key.
+func (r *TransactionRepository) GetByIdempotencyKey(ctx context.Context, key string) (_ *models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.GetByIdempotencyKey")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT transaction_id, type, COALESCE(source_account_id, 0), COALESCE(destination_account_id, 0), amount, effective_date, reversal_of, COALESCE(category, ''), created_at, status, fee_amount, COALESCE(fee_account_id, 0), COALESCE(fee_paid_by, ''), idempotency_key
+		FROM transactions
+		WHERE idempotency_key = $1`
+
+	txn := &models.Transaction{}
+	err = r.pools.Read.QueryRow(ctx, query, key).
+		Scan(&txn.TransactionID, &txn.Type, &txn.SourceAccountID, &txn.DestinationAccountID, &txn.Amount, &txn.EffectiveDate, &txn.ReversalOf, &txn.Category, &txn.CreatedAt, &txn.Status, &txn.FeeAmount, &txn.FeeAccountID, &txn.FeePaidBy, &txn.IdempotencyKey)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get transaction by idempotency key: %w", err)
+	}
+	return txn, nil
+}
+
 // GetByAccountID retrieves transactions for a given account with pagination.
 // Returns transactions where the account is either source or destination,
-// ordered by creation time (newest first).
+// ordered by creation time (newest first). Transactions sharing a created_at
+// are ordered by transaction ID (highest first) so pages are stable.
 //
 // Parameters:
 //   - accountID: The account to get transactions for
@@ -85,31 +295,367 @@ func (r *TransactionRepository) GetByID(ctx context.Context, transactionID int64
 //   - offset: Number of transactions to skip for pagination
 //
 // Returns an empty slice if no transactions are found (not an error).
-func (r *TransactionRepository) GetByAccountID(ctx context.Context, accountID int64, limit, offset int) ([]*models.Transaction, error) {
+func (r *TransactionRepository) GetByAccountID(ctx context.Context, accountID int64, limit, offset int) (_ []*models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.GetByAccountID", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
 	query := `
-		SELECT transaction_id, source_account_id, destination_account_id, amount, created_at
+		SELECT transaction_id, type, COALESCE(source_account_id, 0), COALESCE(destination_account_id, 0), amount, effective_date, reversal_of, COALESCE(category, ''), created_at, status, fee_amount, COALESCE(fee_account_id, 0), COALESCE(fee_paid_by, '')
 		FROM transactions
 		WHERE source_account_id = $1 OR destination_account_id = $1
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, transaction_id DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.Query(ctx, query, accountID, limit, offset)
+	rows, err := r.pools.replicaFor(ctx).Query(ctx, query, accountID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("query transactions for account %d: %w", accountID, err)
 	}
+
+	return scanTransactions(rows, limit)
+}
+
+// GetByAccountIDFiltered is GetByAccountID restricted to the transactions within filter's
+// bounds, in the same order.
+//
+// Only the bounds that are set become predicates, each a plain range condition, so the
+// planner can use the (account, created_at, transaction_id) indexes of both sides for a
+// date range instead of scanning the account's whole history.
+//
+// Returns an empty slice if no transactions match (not an error).
+func (r *TransactionRepository) GetByAccountIDFiltered(ctx context.Context, accountID int64, filter models.TransactionFilter, limit, offset int) (_ []*models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.GetByAccountIDFiltered", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	where, args := accountFilterClause(accountID, filter)
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`
+		SELECT transaction_id, type, COALESCE(source_account_id, 0), COALESCE(destination_account_id, 0), amount, effective_date, reversal_of, COALESCE(category, ''), created_at, status, fee_amount, COALESCE(fee_account_id, 0), COALESCE(fee_paid_by, '')
+		FROM transactions
+		WHERE %s
+		ORDER BY created_at DESC, transaction_id DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+
+	rows, err := r.pools.replicaFor(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query filtered transactions for account %d: %w", accountID, err)
+	}
+
+	return scanTransactions(rows, limit)
+}
+
+// CountByAccountID counts the transactions GetByAccountIDFiltered pages through: the
+// account's transactions within filter's bounds, or all of them for the zero filter.
+func (r *TransactionRepository) CountByAccountID(ctx context.Context, accountID int64, filter models.TransactionFilter) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.CountByAccountID", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	where, args := accountFilterClause(accountID, filter)
+	var total int64
+	if err := r.pools.replicaFor(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM transactions WHERE `+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count transactions for account %d: %w", accountID, err)
+	}
+	return total, nil
+}
+
+// accountFilterClause returns the WHERE clause selecting accountID's transactions within
+// filter's bounds, and its arguments, numbered from $1.
+func accountFilterClause(accountID int64, filter models.TransactionFilter) (string, []any) {
+	conditions := []string{"(source_account_id = $1 OR destination_account_id = $1)"}
+	args := []any{accountID}
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at <= $%d", filter.To)
+	}
+	if filter.MinAmount.Valid {
+		where("amount >= $%d", filter.MinAmount.Decimal)
+	}
+	if filter.MaxAmount.Valid {
+		where("amount <= $%d", filter.MaxAmount.Decimal)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// GetByAccountIDAfter retrieves transactions for a given account using keyset pagination.
+// Returns up to limit transactions positioned strictly before the cursor, in the same
+// order as GetByAccountID: created_at descending, then transaction ID descending.
+//
+// Unlike offset pagination, pages stay stable when new transactions are inserted
+// mid-scroll: new rows sort ahead of the cursor and never shift older pages.
+// The transaction ID tiebreaker keeps rows that share a created_at from being
+// skipped or repeated at page boundaries.
+//
+// Returns an empty slice if no transactions are found (not an error).
+func (r *TransactionRepository) GetByAccountIDAfter(ctx context.Context, accountID int64, before models.TransactionCursor, limit int) (_ []*models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.GetByAccountIDAfter", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT transaction_id, type, COALESCE(source_account_id, 0), COALESCE(destination_account_id, 0), amount, effective_date, reversal_of, COALESCE(category, ''), created_at, status, fee_amount, COALESCE(fee_account_id, 0), COALESCE(fee_paid_by, '')
+		FROM transactions
+		WHERE (source_account_id = $1 OR destination_account_id = $1)
+		  AND (created_at, transaction_id) < ($2, $3)
+		ORDER BY created_at DESC, transaction_id DESC
+		LIMIT $4`
+
+	rows, err := r.pools.Read.Query(ctx, query, accountID, before.CreatedAt, before.TransactionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query transactions for account %d before %d: %w", accountID, before.TransactionID, err)
+	}
+
+	return scanTransactions(rows, limit)
+}
+
+// SumByCategory totals an account's transactions per category, split into amounts
+// debited from (spent) and credited to (received) the account. Only transactions with
+// an effective date in [from, to] are counted; a zero from or to is unbounded. Failed
+// transactions are skipped. Uncategorized transactions are grouped under the empty
+// category.
+//
+// Returns one row per category, ordered by category, or an empty slice if none match.
+func (r *TransactionRepository) SumByCategory(ctx context.Context, accountID int64, from, to time.Time) (_ []*models.CategoryTotal, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.SumByCategory", tracing.AttrAccountID.Int64(accountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT COALESCE(category, ''),
+		       COALESCE(SUM(amount) FILTER (WHERE source_account_id = $1), 0),
+		       COALESCE(SUM(amount) FILTER (WHERE destination_account_id = $1), 0),
+		       COUNT(*)
+		FROM transactions
+		WHERE (source_account_id = $1 OR destination_account_id = $1)
+		  AND status <> 'failed'
+		  AND ($2::date IS NULL OR effective_date >= $2::date)
+		  AND ($3::date IS NULL OR effective_date <= $3::date)
+		GROUP BY 1
+		ORDER BY 1`
+
+	rows, err := r.pools.Read.Query(ctx, query, accountID, nullableDate(from), nullableDate(to))
+	if err != nil {
+		return nil, fmt.Errorf("sum transactions by category for account %d: %w", accountID, err)
+	}
+	defer rows.Close()
+
+	totals := make([]*models.CategoryTotal, 0)
+	for rows.Next() {
+		total := &models.CategoryTotal{}
+		if err := rows.Scan(&total.Category, &total.Spent, &total.Received, &total.Count); err != nil {
+			return nil, fmt.Errorf("scan category total row: %w", err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate category total rows: %w", err)
+	}
+	return totals, nil
+}
+
+// recurringColumns are the columns scanRecurring reads, in order.
+const recurringColumns = `recurring_id, source_account_id, destination_account_id, amount, COALESCE(category, ''),
+		interval_seconds, next_run_at, end_at, status, runs, last_run_at, last_transaction_id,
+		COALESCE(last_error, ''), created_at, canceled_at`
+
+// CreateRecurring inserts an active recurring transfer. Every is stored in whole seconds.
+func (r *TransactionRepository) CreateRecurring(ctx context.Context, recurring *models.RecurringTransfer) (err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.CreateRecurring", tracing.AttrSourceAccountID.Int64(recurring.SourceAccountID), tracing.AttrDestinationAccountID.Int64(recurring.DestinationAccountID))
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		INSERT INTO recurring_transfers (source_account_id, destination_account_id, amount, category, interval_seconds, next_run_at, end_at, status, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, 'active', NOW())
+		RETURNING recurring_id, status, created_at`
+
+	err = r.pools.Transfer.QueryRow(ctx, query,
+		recurring.SourceAccountID,
+		recurring.DestinationAccountID,
+		recurring.Amount,
+		recurring.Category,
+		int64(recurring.Every/time.Second),
+		recurring.NextRunAt,
+		recurring.EndAt,
+	).Scan(&recurring.RecurringID, &recurring.Status, &recurring.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert recurring transfer: %w", err)
+	}
+	return nil
+}
+
+// GetRecurring retrieves a recurring transfer by its ID.
+// Returns ErrRecurringTransferNotFound if it does not exist.
+func (r *TransactionRepository) GetRecurring(ctx context.Context, recurringID int64) (_ *models.RecurringTransfer, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.GetRecurring")
+	defer func() { tracing.End(span, err) }()
+
+	query := `SELECT ` + recurringColumns + ` FROM recurring_transfers WHERE recurring_id = $1`
+
+	recurring, err := scanRecurring(r.pools.Read.QueryRow(ctx, query, recurringID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrRecurringTransferNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get recurring transfer %d: %w", recurringID, err)
+	}
+	return recurring, nil
+}
+
+// ListDueRecurring returns active recurring transfers due at now, earliest first. It
+// reads from the primary: a lagging replica would hand back runs already made.
+func (r *TransactionRepository) ListDueRecurring(ctx context.Context, now time.Time, limit int) (_ []*models.RecurringTransfer, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.ListDueRecurring")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT ` + recurringColumns + `
+		FROM recurring_transfers
+		WHERE status = 'active' AND next_run_at <= $1
+		ORDER BY next_run_at, recurring_id
+		LIMIT $2`
+
+	rows, err := r.pools.Transfer.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list due recurring transfers: %w", err)
+	}
+	defer rows.Close()
+
+	due := make([]*models.RecurringTransfer, 0)
+	for rows.Next() {
+		recurring, err := scanRecurring(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan recurring transfer row: %w", err)
+		}
+		due = append(due, recurring)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate recurring transfer rows: %w", err)
+	}
+	return due, nil
+}
+
+// AdvanceRecurring stores a run's outcome, provided the schedule is still active and due
+// at slot. Returns ErrRecurringTransferNotActive otherwise.
+func (r *TransactionRepository) AdvanceRecurring(ctx context.Context, recurring *models.RecurringTransfer, slot time.Time) (err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.AdvanceRecurring")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		UPDATE recurring_transfers
+		SET next_run_at = $1, status = $2, runs = $3, last_run_at = $4, last_transaction_id = $5, last_error = NULLIF($6, '')
+		WHERE recurring_id = $7 AND status = 'active' AND next_run_at = $8`
+
+	tag, err := r.pools.Transfer.Exec(ctx, query,
+		recurring.NextRunAt,
+		string(recurring.Status),
+		recurring.Runs,
+		recurring.LastRunAt,
+		recurring.LastTransactionID,
+		recurring.LastError,
+		recurring.RecurringID,
+		slot,
+	)
+	if err != nil {
+		return fmt.Errorf("advance recurring transfer %d: %w", recurring.RecurringID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrRecurringTransferNotActive
+	}
+	return nil
+}
+
+// CancelRecurring marks an active recurring transfer canceled. Returns
+// ErrRecurringTransferNotFound or ErrRecurringTransferNotActive.
+func (r *TransactionRepository) CancelRecurring(ctx context.Context, recurringID int64) (_ *models.RecurringTransfer, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.CancelRecurring")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		UPDATE recurring_transfers
+		SET status = 'canceled', canceled_at = NOW()
+		WHERE recurring_id = $1 AND status = 'active'
+		RETURNING ` + recurringColumns
+
+	recurring, err := scanRecurring(r.pools.Transfer.QueryRow(ctx, query, recurringID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Tell a missing schedule from one that already ended
+		var exists bool
+		if err := r.pools.Transfer.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM recurring_transfers WHERE recurring_id = $1)`, recurringID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("check recurring transfer %d exists: %w", recurringID, err)
+		}
+		if !exists {
+			return nil, models.ErrRecurringTransferNotFound
+		}
+		return nil, models.ErrRecurringTransferNotActive
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cancel recurring transfer %d: %w", recurringID, err)
+	}
+	return recurring, nil
+}
+
+// scanRecurring reads one row of recurringColumns.
+func scanRecurring(row pgx.Row) (*models.RecurringTransfer, error) {
+	recurring := &models.RecurringTransfer{}
+	var intervalSeconds int64
+	err := row.Scan(
+		&recurring.RecurringID,
+		&recurring.SourceAccountID,
+		&recurring.DestinationAccountID,
+		&recurring.Amount,
+		&recurring.Category,
+		&intervalSeconds,
+		&recurring.NextRunAt,
+		&recurring.EndAt,
+		&recurring.Status,
+		&recurring.Runs,
+		&recurring.LastRunAt,
+		&recurring.LastTransactionID,
+		&recurring.LastError,
+		&recurring.CreatedAt,
+		&recurring.CanceledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	recurring.Every = time.Duration(intervalSeconds) * time.Second
+	return recurring, nil
+}
+
+// nullableDate returns nil for the zero time so it binds as SQL NULL.
+func nullableDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// scanTransactions reads all rows into transactions and closes rows.
+func scanTransactions(rows pgx.Rows, capacity int) ([]*models.Transaction, error) {
 	defer rows.Close()
 
 	// Pre-allocate with expected capacity to reduce allocations
-	transactions := make([]*models.Transaction, 0, limit)
+	transactions := make([]*models.Transaction, 0, capacity)
 
 	for rows.Next() {
 		txn := &models.Transaction{}
 		if err := rows.Scan(
 			&txn.TransactionID,
+			&txn.Type,
 			&txn.SourceAccountID,
 			&txn.DestinationAccountID,
 			&txn.Amount,
+			&txn.EffectiveDate,
+			&txn.ReversalOf,
+			&txn.Category,
 			&txn.CreatedAt,
+			&txn.Status,
+			&txn.FeeAmount,
+			&txn.FeeAccountID,
+			&txn.FeePaidBy,
 		); err != nil {
 			return nil, fmt.Errorf("scan transaction row: %w", err)
 		}
@@ -122,3 +668,73 @@ func (r *TransactionRepository) GetByAccountID(ctx context.Context, accountID in
 
 	return transactions, nil
 }
+
+// VerifyLedger totals account balances, initial balances, completed deposits and
+// withdrawals, and balance adjustments, and lists up to limit accounts whose balance
+// doesn't match their initial balance plus their ledger entries and adjustments.
+//
+// The aggregates run in one REPEATABLE READ, READ ONLY transaction on the export pool,
+// so they all see the same snapshot and a transfer committing between two of them can't
+// show up as drift.
+func (r *TransactionRepository) VerifyLedger(ctx context.Context, limit int) (_ *models.LedgerVerification, err error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.VerifyLedger")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.pools.Export.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("begin ledger verification: %w", err)
+	}
+	defer tx.Rollback(ctx) // read only, nothing to commit
+
+	v := &models.LedgerVerification{}
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(balance), 0), COALESCE(SUM(initial_balance), 0)
+		FROM accounts`).
+		Scan(&v.Accounts, &v.TotalBalance, &v.InitialBalances)
+	if err != nil {
+		return nil, fmt.Errorf("sum account balances: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount) FILTER (WHERE type = 'deposit'), 0)
+		     - COALESCE(SUM(amount) FILTER (WHERE type = 'withdrawal'), 0)
+		FROM transactions
+		WHERE status = 'completed'`).
+		Scan(&v.NetTransactions)
+	if err != nil {
+		return nil, fmt.Errorf("sum deposits and withdrawals: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `SELECT COALESCE(SUM(delta), 0) FROM balance_adjustments`).Scan(&v.Adjustments)
+	if err != nil {
+		return nil, fmt.Errorf("sum balance adjustments: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT a.account_id, a.balance, a.initial_balance + COALESCE(e.total, 0) + COALESCE(b.total, 0)
+		FROM accounts a
+		LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM ledger_entries GROUP BY account_id) e
+		  ON e.account_id = a.account_id
+		LEFT JOIN (SELECT account_id, SUM(delta) AS total FROM balance_adjustments GROUP BY account_id) b
+		  ON b.account_id = a.account_id
+		WHERE a.balance <> a.initial_balance + COALESCE(e.total, 0) + COALESCE(b.total, 0)
+		ORDER BY a.account_id
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("find drifted accounts: %w", err)
+	}
+	defer rows.Close()
+
+	v.DriftedAccounts = make([]*models.AccountDrift, 0)
+	for rows.Next() {
+		drift := &models.AccountDrift{}
+		if err := rows.Scan(&drift.AccountID, &drift.Balance, &drift.Expected); err != nil {
+			return nil, fmt.Errorf("scan drifted account row: %w", err)
+		}
+		v.DriftedAccounts = append(v.DriftedAccounts, drift)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate drifted account rows: %w", err)
+	}
+	return v, nil
+}
@@ -4,7 +4,10 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
+	"time"
 
 	"internal-transfers-system/internal/models"
 
@@ -41,6 +44,135 @@ func TestTransactionRepository_Create(t *testing.T) {
 	if txn.TransactionID == 0 {
 		t.Error("expected transaction ID")
 	}
+	if txn.Status != models.TransactionStatusPending {
+		t.Errorf("expected an unset status to be stored as pending, got %q", txn.Status)
+	}
+}
+
+func TestTransactionRepository_Create_ConstraintViolations(t *testing.T) {
+	txnRepo, accRepo := setupTxnRepo(t)
+	ctx := context.Background()
+
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	missing := int64(999)
+
+	tests := []struct {
+		name string
+		txn  *models.Transaction
+		want error
+	}{
+		{"unknown destination", &models.Transaction{SourceAccountID: 1, DestinationAccountID: 9, Amount: decimal.NewFromInt(10)}, models.ErrAccountNotFound},
+		{"unknown source", &models.Transaction{SourceAccountID: 9, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)}, models.ErrAccountNotFound},
+		{"zero amount", &models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.Zero}, models.ErrInvalidAmount},
+		{"negative amount", &models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(-5)}, models.ErrInvalidAmount},
+		{"unknown reversed transaction", &models.Transaction{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(10), ReversalOf: &missing}, models.ErrTransferNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := accRepo.BeginTx(ctx)
+			if err != nil {
+				t.Fatalf("begin: %v", err)
+			}
+			defer tx.Rollback(ctx)
+			if err := txnRepo.Create(ctx, tx, tt.txn); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Other violations still surface as database errors
+	tx, _ := accRepo.BeginTx(ctx)
+	defer tx.Rollback(ctx)
+	err := txnRepo.Create(ctx, tx, &models.Transaction{SourceAccountID: 1, DestinationAccountID: 1, Amount: decimal.NewFromInt(10)})
+	if _, ok := models.IsDomainError(err); err == nil || ok {
+		t.Errorf("expected a plain error for a self-transfer, got %v", err)
+	}
+}
+
+func TestTransactionRepository_Status(t *testing.T) {
+	txnRepo, accRepo := setupTxnRepo(t)
+	ctx := context.Background()
+
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+
+	create := func(txn *models.Transaction) {
+		t.Helper()
+		tx, _ := accRepo.BeginTx(ctx)
+		if err := txnRepo.Create(ctx, tx, txn); err != nil {
+			tx.Rollback(ctx)
+			t.Fatalf("create %s: %v", txn.Status, err)
+		}
+		tx.Commit(ctx)
+	}
+
+	original := &models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100), Status: models.TransactionStatusCompleted}
+	create(original)
+	// A failed reversal attempt doesn't take the reversal slot
+	failed := &models.Transaction{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(100), ReversalOf: &original.TransactionID, Status: models.TransactionStatusFailed}
+	create(failed)
+	if _, err := txnRepo.GetReversal(ctx, original.TransactionID); !errors.Is(err, models.ErrTransferNotFound) {
+		t.Errorf("expected a failed reversal to be ignored, got %v", err)
+	}
+	reversal := &models.Transaction{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(100), ReversalOf: &original.TransactionID, Status: models.TransactionStatusCompleted}
+	create(reversal)
+
+	got, err := txnRepo.GetReversal(ctx, original.TransactionID)
+	if err != nil || got.TransactionID != reversal.TransactionID {
+		t.Errorf("expected reversal %d, got %+v, %v", reversal.TransactionID, got, err)
+	}
+	stored, err := txnRepo.GetByID(ctx, failed.TransactionID)
+	if err != nil || stored.Status != models.TransactionStatusFailed {
+		t.Errorf("expected the failed row to read back as failed, got %+v, %v", stored, err)
+	}
+
+	totals, err := txnRepo.SumByCategory(ctx, 1, time.Time{}, time.Time{})
+	if err != nil || len(totals) != 1 || totals[0].Count != 2 || totals[0].Received.String() != "100" {
+		t.Errorf("expected failed rows to be left out of the totals, got %+v, %v", totals, err)
+	}
+}
+
+func TestTransactionRepository_SinceLastOutboundTransfer(t *testing.T) {
+	txnRepo, accRepo := setupTxnRepo(t)
+	ctx := context.Background()
+
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+
+	since := func(accountID int64) (time.Duration, bool) {
+		tx, _ := accRepo.BeginTx(ctx)
+		defer tx.Rollback(ctx)
+		elapsed, sent, err := txnRepo.SinceLastOutboundTransfer(ctx, tx, accountID)
+		if err != nil {
+			t.Fatalf("since last outbound for %d: %v", accountID, err)
+		}
+		return elapsed, sent
+	}
+
+	if _, sent := since(1); sent {
+		t.Fatal("expected no outbound transfer yet")
+	}
+
+	tx, _ := accRepo.BeginTx(ctx)
+	original := &models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100)}
+	if err := txnRepo.Create(ctx, tx, original); err != nil {
+		tx.Rollback(ctx)
+		t.Fatalf("create: %v", err)
+	}
+	reversal := &models.Transaction{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(100), ReversalOf: &original.TransactionID}
+	if err := txnRepo.Create(ctx, tx, reversal); err != nil {
+		tx.Rollback(ctx)
+		t.Fatalf("create reversal: %v", err)
+	}
+	tx.Commit(ctx)
+
+	if elapsed, sent := since(1); !sent || elapsed < 0 || elapsed > time.Minute {
+		t.Errorf("expected a recent outbound transfer, got %s, %v", elapsed, sent)
+	}
+	if _, sent := since(2); sent {
+		t.Error("expected the reversal not to count as an outbound transfer")
+	}
 }
 
 func TestTransactionRepository_GetByID(t *testing.T) {
@@ -100,3 +232,348 @@ func TestTransactionRepository_GetByAccountID(t *testing.T) {
 		t.Errorf("expected 3, got %d", len(txns))
 	}
 }
+
+func TestTransactionRepository_GetByAccountIDFiltered(t *testing.T) {
+	txnRepo, accRepo := setupTxnRepo(t)
+	ctx := context.Background()
+
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(1000)})
+	accRepo.Create(ctx, &models.Account{AccountID: 3, Balance: decimal.NewFromInt(1000)})
+
+	// Day d of March carries an amount of d*10, so each filter picks out a known subset
+	march := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC) }
+	ids := map[int]int64{}
+	for d := 1; d <= 6; d++ {
+		tx, _ := accRepo.BeginTx(ctx)
+		txn := &models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(int64(d * 10))}
+		if err := txnRepo.Create(ctx, tx, txn); err != nil {
+			tx.Rollback(ctx)
+			t.Fatalf("create: %v", err)
+		}
+		tx.Commit(ctx)
+		if _, err := testSuite.Pool().Exec(ctx, "UPDATE transactions SET created_at = $1 WHERE transaction_id = $2", march(d), txn.TransactionID); err != nil {
+			t.Fatalf("backdate: %v", err)
+		}
+		ids[d] = txn.TransactionID
+	}
+	// Another account's transaction matches every filter but must never be returned
+	tx, _ := accRepo.BeginTx(ctx)
+	txnRepo.Create(ctx, tx, &models.Transaction{SourceAccountID: 2, DestinationAccountID: 3, Amount: decimal.NewFromInt(30)})
+	tx.Commit(ctx)
+
+	tests := []struct {
+		name   string
+		filter models.TransactionFilter
+		want   []int
+	}{
+		{"no bounds", models.TransactionFilter{}, []int{6, 5, 4, 3, 2, 1}},
+		{"date range", models.TransactionFilter{From: march(2), To: march(4)}, []int{4, 3, 2}},
+		{"from only", models.TransactionFilter{From: march(5).Add(time.Second)}, []int{6}},
+		{"amount range", models.TransactionFilter{
+			MinAmount: decimal.NewNullDecimal(decimal.NewFromInt(20)),
+			MaxAmount: decimal.NewNullDecimal(decimal.RequireFromString("40.00")),
+		}, []int{4, 3, 2}},
+		{"date and amount", models.TransactionFilter{
+			To:        march(5),
+			MinAmount: decimal.NewNullDecimal(decimal.NewFromInt(35)),
+		}, []int{5, 4}},
+		{"nothing matches", models.TransactionFilter{From: march(7)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txns, err := txnRepo.GetByAccountIDFiltered(ctx, 1, tt.filter, 10, 0)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			var want, got []int64
+			for _, d := range tt.want {
+				want = append(want, ids[d])
+			}
+			for _, txn := range txns {
+				got = append(got, txn.TransactionID)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("expected transactions %v, got %v", want, got)
+			}
+
+			total, err := txnRepo.CountByAccountID(ctx, 1, tt.filter)
+			if err != nil || total != int64(len(tt.want)) {
+				t.Errorf("expected a count of %d, got %d (err %v)", len(tt.want), total, err)
+			}
+		})
+	}
+
+	// Filtered results page like unfiltered ones
+	txns, _ := txnRepo.GetByAccountIDFiltered(ctx, 1, models.TransactionFilter{From: march(2)}, 2, 2)
+	if len(txns) != 2 || txns[0].TransactionID != ids[4] || txns[1].TransactionID != ids[3] {
+		t.Errorf("expected the second page to hold days 4 and 3, got %v", txns)
+	}
+}
+
+func TestTransactionRepository_SumByCategory(t *testing.T) {
+	txnRepo, accRepo := setupTxnRepo(t)
+	ctx := context.Background()
+
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+
+	march := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	tx, _ := accRepo.BeginTx(ctx)
+	for _, txn := range []*models.Transaction{
+		{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.RequireFromString("10.25"), Category: "rent", EffectiveDate: march(1)},
+		{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(5), Category: "rent", EffectiveDate: march(2)},
+		{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(3), EffectiveDate: march(20)},
+	} {
+		if err := txnRepo.Create(ctx, tx, txn); err != nil {
+			tx.Rollback(ctx)
+			t.Fatalf("create: %v", err)
+		}
+	}
+	tx.Commit(ctx)
+
+	totals, err := txnRepo.SumByCategory(ctx, 1, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("sum: %v", err)
+	}
+	if len(totals) != 2 {
+		t.Fatalf("expected 2 categories, got %d", len(totals))
+	}
+	if totals[0].Category != "" || !totals[0].Received.Equal(decimal.NewFromInt(3)) || totals[0].Count != 1 {
+		t.Errorf("unexpected uncategorized totals: %+v", totals[0])
+	}
+	rent := totals[1]
+	if rent.Category != "rent" || !rent.Spent.Equal(decimal.RequireFromString("10.25")) ||
+		!rent.Received.Equal(decimal.NewFromInt(5)) || rent.Count != 2 {
+		t.Errorf("unexpected rent totals: %+v", rent)
+	}
+
+	totals, err = txnRepo.SumByCategory(ctx, 1, march(2), march(19))
+	if err != nil {
+		t.Fatalf("sum in range: %v", err)
+	}
+	if len(totals) != 1 || totals[0].Count != 1 || !totals[0].Spent.IsZero() {
+		t.Errorf("expected one received rent entry in range, got %+v", totals)
+	}
+}
+
+func TestTransactionRepository_IdempotencyKey(t *testing.T) {
+	txnRepo, accRepo := setupTxnRepo(t)
+	ctx := context.Background()
+
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+
+	tx, _ := accRepo.BeginTx(ctx)
+	first := &models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100), IdempotencyKey: "k1"}
+	if err := txnRepo.Create(ctx, tx, first); err != nil {
+		tx.Rollback(ctx)
+		t.Fatalf("create: %v", err)
+	}
+	// Transactions without a key must not collide with each other
+	for i := 0; i < 2; i++ {
+		if err := txnRepo.Create(ctx, tx, &models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1)}); err != nil {
+			tx.Rollback(ctx)
+			t.Fatalf("create without key: %v", err)
+		}
+	}
+	tx.Commit(ctx)
+
+	found, err := txnRepo.GetByIdempotencyKey(ctx, "k1")
+	if err != nil || found.TransactionID != first.TransactionID {
+		t.Fatalf("expected transaction %d, got %+v err=%v", first.TransactionID, found, err)
+	}
+
+	if _, err := txnRepo.GetByIdempotencyKey(ctx, "missing"); err != models.ErrTransferNotFound {
+		t.Errorf("expected ErrTransferNotFound, got %v", err)
+	}
+
+	tx, _ = accRepo.BeginTx(ctx)
+	defer tx.Rollback(ctx)
+	dup := &models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100), IdempotencyKey: "k1"}
+	if err := txnRepo.Create(ctx, tx, dup); err != models.ErrDuplicateTransaction {
+		t.Errorf("expected ErrDuplicateTransaction, got %v", err)
+	}
+}
+
+func TestTransactionRepository_GetByAccountIDAfter_ConcurrentInserts(t *testing.T) {
+	txnRepo, accRepo := setupTxnRepo(t)
+	ctx := context.Background()
+
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000000)})
+	accRepo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+
+	insert := func() int64 {
+		tx, _ := accRepo.BeginTx(ctx)
+		txn := &models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1)}
+		if err := txnRepo.Create(ctx, tx, txn); err != nil {
+			tx.Rollback(ctx)
+			t.Errorf("create: %v", err)
+			return 0
+		}
+		tx.Commit(ctx)
+		return txn.TransactionID
+	}
+
+	var maxSeeded int64
+	for i := 0; i < 50; i++ {
+		maxSeeded = insert()
+	}
+
+	// Keep inserting while paginating
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				insert()
+			}
+		}
+	}()
+
+	seen := make(map[int64]bool)
+	last, err := txnRepo.GetByID(ctx, maxSeeded)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	// Start just ahead of the newest seeded row so it is included in the first page
+	cursor := models.CursorAfter(last)
+	cursor.TransactionID++
+	for {
+		page, err := txnRepo.GetByAccountIDAfter(ctx, 1, cursor, 7)
+		if err != nil {
+			t.Fatalf("page: %v", err)
+		}
+		for _, txn := range page {
+			if seen[txn.TransactionID] {
+				t.Fatalf("duplicate transaction %d", txn.TransactionID)
+			}
+			if !models.CursorAfter(txn).Less(cursor) {
+				t.Fatalf("transaction %d not below cursor %v", txn.TransactionID, cursor)
+			}
+			seen[txn.TransactionID] = true
+		}
+		if len(page) < 7 {
+			break
+		}
+		cursor = models.CursorAfter(page[len(page)-1])
+	}
+	close(stop)
+	<-done
+
+	if len(seen) != 50 {
+		t.Errorf("expected exactly the 50 seeded transactions, got %d", len(seen))
+	}
+}
+
+func TestTransactionRepository_GetByAccountIDAfter_SharedCreatedAt(t *testing.T) {
+	txnRepo, accRepo := setupTxnRepo(t)
+	ctx := context.Background()
+
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+
+	// All rows in one database transaction share NOW(), so created_at ties everywhere
+	tx, _ := accRepo.BeginTx(ctx)
+	for i := 0; i < 10; i++ {
+		if err := txnRepo.Create(ctx, tx, &models.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1)}); err != nil {
+			tx.Rollback(ctx)
+			t.Fatalf("create: %v", err)
+		}
+	}
+	tx.Commit(ctx)
+
+	var seen []int64
+	cursor := models.StartCursor()
+	for {
+		page, err := txnRepo.GetByAccountIDAfter(ctx, 1, cursor, 3)
+		if err != nil {
+			t.Fatalf("page: %v", err)
+		}
+		for _, txn := range page {
+			seen = append(seen, txn.TransactionID)
+		}
+		if len(page) < 3 {
+			break
+		}
+		cursor = models.CursorAfter(page[len(page)-1])
+	}
+
+	if len(seen) != 10 {
+		t.Fatalf("expected 10 distinct rows, got %v", seen)
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i] >= seen[i-1] {
+			t.Fatalf("expected transaction ID descending within a shared created_at, got %v", seen)
+		}
+	}
+
+	offset, _ := txnRepo.GetByAccountID(ctx, 1, 10, 0)
+	for i, txn := range offset {
+		if txn.TransactionID != seen[i] {
+			t.Fatalf("offset and cursor ordering differ at %d: %d vs %d", i, txn.TransactionID, seen[i])
+		}
+	}
+}
+
+func TestTransactionRepository_Recurring(t *testing.T) {
+	txnRepo, accRepo := setupTxnRepo(t)
+	ctx := context.Background()
+
+	accRepo.Create(ctx, &models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.Create(ctx, &models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+
+	start := time.Now().UTC().Truncate(time.Second).Add(-time.Minute)
+	recurring := &models.RecurringTransfer{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(25), Category: "rent", Every: time.Hour, NextRunAt: start,
+	}
+	if err := txnRepo.CreateRecurring(ctx, recurring); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if recurring.RecurringID == 0 || recurring.Status != models.RecurringStatusActive {
+		t.Fatalf("unexpected created schedule %+v", recurring)
+	}
+
+	due, err := txnRepo.ListDueRecurring(ctx, time.Now(), 10)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("expected one due schedule, got %d, %v", len(due), err)
+	}
+	if due[0].Every != time.Hour || due[0].Category != "rent" || !due[0].NextRunAt.Equal(start) {
+		t.Errorf("unexpected due schedule %+v", due[0])
+	}
+
+	advanced := *due[0]
+	ranAt := time.Now()
+	advanced.NextRunAt, advanced.Runs, advanced.LastRunAt, advanced.LastError = start.Add(time.Hour), 1, &ranAt, "insufficient_balance: insufficient balance"
+	if err := txnRepo.AdvanceRecurring(ctx, &advanced, start); err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	// A second scheduler that ran the same slot loses
+	if err := txnRepo.AdvanceRecurring(ctx, &advanced, start); !errors.Is(err, models.ErrRecurringTransferNotActive) {
+		t.Errorf("stale advance: expected ErrRecurringTransferNotActive, got %v", err)
+	}
+	if due, _ := txnRepo.ListDueRecurring(ctx, time.Now(), 10); len(due) != 0 {
+		t.Errorf("expected nothing due after advancing, got %d", len(due))
+	}
+
+	canceled, err := txnRepo.CancelRecurring(ctx, recurring.RecurringID)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if canceled.Status != models.RecurringStatusCanceled || canceled.CanceledAt == nil || canceled.Runs != 1 || canceled.LastError == "" {
+		t.Errorf("unexpected canceled schedule %+v", canceled)
+	}
+	if _, err := txnRepo.CancelRecurring(ctx, recurring.RecurringID); !errors.Is(err, models.ErrRecurringTransferNotActive) {
+		t.Errorf("cancel again: expected ErrRecurringTransferNotActive, got %v", err)
+	}
+	if _, err := txnRepo.CancelRecurring(ctx, 999); !errors.Is(err, models.ErrRecurringTransferNotFound) {
+		t.Errorf("cancel unknown: expected ErrRecurringTransferNotFound, got %v", err)
+	}
+	if _, err := txnRepo.GetRecurring(ctx, 999); !errors.Is(err, models.ErrRecurringTransferNotFound) {
+		t.Errorf("get unknown: expected ErrRecurringTransferNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"internal-transfers-system/internal/consistency"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// LSNFunc returns the current WAL position of the primary.
+type LSNFunc func(ctx context.Context) (consistency.LSN, error)
+
+// PoolLSN reads the primary's current WAL position from pool.
+func PoolLSN(pool *pgxpool.Pool) LSNFunc {
+	return func(ctx context.Context) (consistency.LSN, error) {
+		var text string
+		if err := pool.QueryRow(ctx, `SELECT pg_current_wal_lsn()::text`).Scan(&text); err != nil {
+			return 0, err
+		}
+		return consistency.ParseLSN(text)
+	}
+}
+
+// ConsistencyTokenMiddleware implements read-your-writes tokens.
+//
+// On incoming requests, a valid X-Consistency-Token header is parsed and placed in the
+// request context for read paths to honor; a malformed one is rejected with 400.
+//
+// On successful writes (any method other than GET/HEAD answering 2xx), the primary's
+// current LSN is returned in X-Consistency-Token. Handlers write their status only
+// after the database commit, so this LSN is at or beyond the write's commit record.
+func ConsistencyTokenMiddleware(currentLSN LSNFunc, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if header := r.Header.Get(consistency.Header); header != "" {
+			lsn, err := consistency.ParseLSN(header)
+			if err != nil {
+				writeServerJSON(w, http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"error":   "invalid_consistency_token",
+					"message": "X-Consistency-Token is not a valid token",
+				})
+				return
+			}
+			ctx = consistency.WithToken(ctx, lsn)
+		}
+
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			serveWithContext(next, w, r, ctx)
+			return
+		}
+
+		serveWithContext(next, &tokenWriter{ResponseWriter: w, ctx: ctx, currentLSN: currentLSN}, r, ctx)
+	})
+}
+
+// tokenWriter attaches a consistency token header just before a 2xx status is written.
+type tokenWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	currentLSN  LSNFunc
+	wroteHeader bool
+}
+
+// WriteHeader adds the token header for successful responses, then writes the status.
+func (tw *tokenWriter) WriteHeader(code int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+
+	if code >= 200 && code < 300 {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(tw.ctx), time.Second)
+		defer cancel()
+
+		if lsn, err := tw.currentLSN(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to read WAL position for consistency token")
+		} else {
+			tw.Header().Set(consistency.Header, lsn.String())
+		}
+	}
+
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+// Write ensures the header hook runs for handlers that never call WriteHeader.
+func (tw *tokenWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController can reach it.
+func (tw *tokenWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"internal-transfers-system/internal/consistency"
+)
+
+func TestConsistencyTokenMiddleware(t *testing.T) {
+	currentLSN := func(context.Context) (consistency.LSN, error) { return 0x16B3748, nil }
+
+	var seen consistency.LSN
+	var seenOK bool
+	h := ConsistencyTokenMiddleware(currentLSN, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, seenOK = consistency.FromContext(r.Context())
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+
+	t.Run("write returns token", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		if got := rec.Header().Get(consistency.Header); got != "0/16B3748" {
+			t.Errorf("expected token 0/16B3748, got %q", got)
+		}
+	})
+
+	t.Run("read does not return token", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if got := rec.Header().Get(consistency.Header); got != "" {
+			t.Errorf("expected no token, got %q", got)
+		}
+		if seenOK {
+			t.Error("expected no token in context")
+		}
+	})
+
+	t.Run("read token propagated to context", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(consistency.Header, "1/A")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if !seenOK || seen != consistency.LSN(1<<32|0xA) {
+			t.Errorf("expected token in context, got %v (ok=%v)", seen, seenOK)
+		}
+	})
+
+	t.Run("malformed token rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(consistency.Header, "garbage")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", rec.Code)
+		}
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"time"
 
+	"internal-transfers-system/internal/models"
+
 	"github.com/rs/zerolog/log"
 )
 
@@ -32,6 +34,9 @@ type ReadyResponse struct {
 	Status    string            `json:"status"`
 	Timestamp time.Time         `json:"timestamp"`
 	Checks    map[string]string `json:"checks,omitempty"`
+
+	// Schema is the applied migration version, omitted when it can't be determined.
+	Schema *models.SchemaVersion `json:"schema,omitempty"`
 }
 
 // handleHealth returns the health status of the service.
@@ -61,44 +66,67 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 //   - Readiness probes: Determines if the pod should receive traffic
 //   - Deployment strategies: Determines when new pods are ready
 //
-// The readiness check verifies:
-//   - Database connectivity and responsiveness
+// The readiness check runs every check registered with RegisterReadyCheck (by default
+// just database connectivity) concurrently, each under its own timeout, and reports
+// each one's status in Checks. The service is ready only if all of them pass.
+//
+// It also reports the applied schema migration version, so a deploy can be checked
+// against the migrations it shipped. This is informational and never affects readiness.
 //
 // Responses:
 //   - 200 OK: Service is ready to accept traffic
 //   - 503 Service Unavailable: Service is not ready (dependencies failing)
 func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
-	checks := make(map[string]string)
 	statusCode := http.StatusOK
 	readyStatus := "ready"
 
-	// Check database connectivity with timeout
-	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
-	defer cancel()
-
-	if s.db == nil {
-		checks["database"] = "uninitialized"
+	checks, ready := s.runReadyChecks(r.Context())
+	if !ready {
 		statusCode = http.StatusServiceUnavailable
 		readyStatus = "not_ready"
-		log.Warn().Msg("Readiness check failed: database not initialized")
-	} else if err := s.db.Ping(ctx); err != nil {
-		checks["database"] = "unavailable"
-		statusCode = http.StatusServiceUnavailable
-		readyStatus = "not_ready"
-		log.Warn().Err(err).Msg("Readiness check failed: database ping error")
-	} else {
-		checks["database"] = "ok"
+	}
+
+	var schema *models.SchemaVersion
+	if checks["database"] == "ok" {
+		ctx, cancel := context.WithTimeout(r.Context(), DefaultReadyCheckTimeout)
+		defer cancel()
+		schema = s.readSchemaVersion(ctx, checks)
 	}
 
 	response := ReadyResponse{
 		Status:    readyStatus,
 		Timestamp: time.Now().UTC(),
 		Checks:    checks,
+		Schema:    schema,
 	}
 
 	writeServerJSON(w, statusCode, response)
 }
 
+// readSchemaVersion returns the applied migration version and records the outcome in
+// checks["migrations"]: "ok", "dirty" (a migration failed part-way), "untracked" (no
+// tracking table, e.g. migrations are managed elsewhere), or "unavailable".
+func (s *Server) readSchemaVersion(ctx context.Context, checks map[string]string) *models.SchemaVersion {
+	if s.schemaVersion == nil {
+		return nil
+	}
+
+	version, err := s.schemaVersion(ctx)
+	switch {
+	case err != nil:
+		checks["migrations"] = "unavailable"
+		log.Warn().Err(err).Msg("Readiness check: failed to read schema version")
+	case version == nil:
+		checks["migrations"] = "untracked"
+	case version.Dirty:
+		checks["migrations"] = "dirty"
+		log.Warn().Int64("version", version.Version).Msg("Readiness check: schema migration is dirty")
+	default:
+		checks["migrations"] = "ok"
+	}
+	return version
+}
+
 // writeServerJSON writes a JSON response with the given status code.
 // This is a server-specific helper that doesn't depend on the handler package.
 func writeServerJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/models"
+)
+
+func TestReadSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     *models.SchemaVersion
+		err         error
+		wantCheck   string
+		wantVersion int64
+	}{
+		{"applied", &models.SchemaVersion{Version: 11}, nil, "ok", 11},
+		{"dirty", &models.SchemaVersion{Version: 11, Dirty: true}, nil, "dirty", 11},
+		{"untracked", nil, nil, "untracked", 0},
+		{"query failed", nil, errors.New("connection reset"), "unavailable", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{schemaVersion: func(context.Context) (*models.SchemaVersion, error) {
+				return tt.version, tt.err
+			}}
+			checks := make(map[string]string)
+
+			got := s.readSchemaVersion(context.Background(), checks)
+			if checks["migrations"] != tt.wantCheck {
+				t.Errorf("expected check %q, got %q", tt.wantCheck, checks["migrations"])
+			}
+			if (got == nil) != (tt.wantVersion == 0) || (got != nil && got.Version != tt.wantVersion) {
+				t.Errorf("expected version %d, got %+v", tt.wantVersion, got)
+			}
+		})
+	}
+}
+
+func TestHandleReady(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	ok := func(context.Context) error { return nil }
+
+	tests := []struct {
+		name       string
+		register   func(s *Server)
+		wantStatus int
+		wantChecks map[string]string
+	}{
+		{
+			name: "all pass",
+			register: func(s *Server) {
+				s.RegisterReadyCheck(NewHealthCheck("replica", ok), 0)
+				s.RegisterReadyCheck(NewHealthCheck("webhooks", ok), 0)
+			},
+			wantStatus: http.StatusOK,
+			wantChecks: map[string]string{"replica": "ok", "webhooks": "ok"},
+		},
+		{
+			name: "one fails",
+			register: func(s *Server) {
+				s.RegisterReadyCheck(NewHealthCheck("replica", ok), 0)
+				s.RegisterReadyCheck(NewHealthCheck("webhooks", func(context.Context) error { return errors.New("connection refused") }), 0)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"replica": "ok", "webhooks": "unavailable"},
+		},
+		{
+			name: "per-check timeouts run concurrently",
+			register: func(s *Server) {
+				s.RegisterReadyCheck(NewHealthCheck("replica", slow), 300*time.Millisecond)
+				s.RegisterReadyCheck(NewHealthCheck("outbox", slow), 300*time.Millisecond)
+				s.RegisterReadyCheck(NewHealthCheck("webhooks", ok), 0)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"replica": "timeout", "outbox": "timeout", "webhooks": "ok"},
+		},
+		{
+			name:       "uninitialized database",
+			register:   func(s *Server) { s.RegisterReadyCheck(DatabaseCheck(nil), 0) },
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"database": "uninitialized"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{}
+			tt.register(s)
+
+			start := time.Now()
+			rec := httptest.NewRecorder()
+			s.handleReady(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+			// One after another, the two slow checks would take 600ms
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("expected checks to run concurrently within their timeouts, took %s", elapsed)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			var resp ReadyResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(resp.Checks) != len(tt.wantChecks) {
+				t.Errorf("expected checks %v, got %v", tt.wantChecks, resp.Checks)
+			}
+			for name, want := range tt.wantChecks {
+				if resp.Checks[name] != want {
+					t.Errorf("check %s: expected %q, got %q", name, want, resp.Checks[name])
+				}
+			}
+		})
+	}
+}
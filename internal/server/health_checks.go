@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultReadyCheckTimeout bounds each readiness check registered without its own timeout.
+const DefaultReadyCheckTimeout = 2 * time.Second
+
+// HealthCheck is one dependency /ready verifies, e.g. the database or a replica.
+// Check returns nil when the dependency is usable; it must honour ctx's deadline.
+type HealthCheck interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// errCheckUninitialized is returned by checks whose dependency was never set up.
+var errCheckUninitialized = errors.New("dependency not initialized")
+
+type healthCheckFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c healthCheckFunc) Name() string                    { return c.name }
+func (c healthCheckFunc) Check(ctx context.Context) error { return c.check(ctx) }
+
+// NewHealthCheck returns a HealthCheck named name that runs check.
+func NewHealthCheck(name string, check func(ctx context.Context) error) HealthCheck {
+	return healthCheckFunc{name: name, check: check}
+}
+
+// DatabaseCheck pings db. It reports "uninitialized" when db is nil.
+func DatabaseCheck(db *pgxpool.Pool) HealthCheck {
+	return NewHealthCheck("database", func(ctx context.Context) error {
+		if db == nil {
+			return errCheckUninitialized
+		}
+		return db.Ping(ctx)
+	})
+}
+
+type readyCheck struct {
+	check   HealthCheck
+	timeout time.Duration
+}
+
+// RegisterReadyCheck adds check to /ready. Every check must pass for the service to be
+// ready. timeout bounds this check alone; zero means DefaultReadyCheckTimeout. Checks
+// should be registered before the server starts.
+func (s *Server) RegisterReadyCheck(check HealthCheck, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultReadyCheckTimeout
+	}
+	s.readyChecks = append(s.readyChecks, readyCheck{check: check, timeout: timeout})
+}
+
+// runReadyChecks runs every registered check concurrently, each under its own timeout,
+// and returns each check's status by name ("ok", "timeout", "uninitialized", or
+// "unavailable") and whether all of them passed.
+func (s *Server) runReadyChecks(ctx context.Context) (map[string]string, bool) {
+	statuses := make([]string, len(s.readyChecks))
+	var wg sync.WaitGroup
+	for i, rc := range s.readyChecks {
+		wg.Add(1)
+		go func(i int, rc readyCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, rc.timeout)
+			defer cancel()
+			statuses[i] = checkStatus(rc.check.Name(), rc.check.Check(checkCtx))
+		}(i, rc)
+	}
+	wg.Wait()
+
+	checks := make(map[string]string, len(statuses))
+	ready := true
+	for i, rc := range s.readyChecks {
+		checks[rc.check.Name()] = statuses[i]
+		if statuses[i] != "ok" {
+			ready = false
+		}
+	}
+	return checks, ready
+}
+
+func checkStatus(name string, err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, errCheckUninitialized):
+		log.Warn().Str("check", name).Msg("Readiness check failed: dependency not initialized")
+		return "uninitialized"
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Warn().Err(err).Str("check", name).Msg("Readiness check timed out")
+		return "timeout"
+	default:
+		log.Warn().Err(err).Str("check", name).Msg("Readiness check failed")
+		return "unavailable"
+	}
+}
@@ -4,10 +4,16 @@ package server
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/metrics"
+
 	"github.com/rs/zerolog/log"
 )
 
@@ -28,6 +34,8 @@ const (
 // The request ID is added to:
 //   - The request context (for logging and tracing)
 //   - The response header (for client correlation)
+//   - A request-scoped logger in the context (see logging.FromContext), so service
+//     log lines carry the request_id field
 func RequestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check for existing request ID from client
@@ -41,6 +49,7 @@ func RequestIDMiddleware(next http.Handler) http.Handler {
 
 		// Add to request context
 		ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+		ctx = logging.WithRequestID(ctx, requestID)
 
 		// Continue with the updated context
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -61,10 +70,65 @@ func generateRequestID() string {
 // LoggingMiddleware logs HTTP requests with timing and status information.
 // It captures:
 //   - Request method, path, and remote address
+//   - Matched route pattern (e.g. "GET /api/v1/accounts/{id}"), for low-cardinality grouping
 //   - Response status code and size
 //   - Request duration
 //   - Request ID (if present)
 func LoggingMiddleware(next http.Handler) http.Handler {
+	return LoggingMiddlewareWithMetrics(nil, next)
+}
+
+// LoggingMiddlewareWithMetrics is LoggingMiddleware that also records each request's
+// duration in m, labelled by route pattern and status. A nil m only logs.
+func LoggingMiddlewareWithMetrics(m metrics.Recorder, next http.Handler) http.Handler {
+	return LoggingMiddlewareWithPolicy(m, RequestLogPolicy{SampleRate: 1}, next)
+}
+
+// RequestLogPolicy decides which requests LoggingMiddlewareWithPolicy logs, to keep
+// request logs readable at high QPS.
+type RequestLogPolicy struct {
+	// SampleRate is the fraction of requests answered below 400 that are logged, from 0
+	// (none) to 1 (all). Client and server errors are always logged.
+	SampleRate float64
+
+	// SkipPaths are URL paths, such as health and readiness probes, that are never
+	// logged whatever their status.
+	SkipPaths []string
+
+	// SlowThreshold is how long a request may take before it is logged at warn level.
+	// Slow requests are always logged, like errors, unless their path is skipped. Zero
+	// disables it.
+	SlowThreshold time.Duration
+}
+
+// requestLogSampler logs an exact fraction of successful requests by counting them, so
+// a rate of 0.1 logs every tenth one rather than roughly one in ten.
+type requestLogSampler struct {
+	rate float64
+	seen atomic.Uint64
+}
+
+// sample reports whether the next successful request should be logged.
+func (s *requestLogSampler) sample() bool {
+	if s.rate >= 1 {
+		return true
+	}
+	if s.rate <= 0 {
+		return false
+	}
+	n := s.seen.Add(1)
+	return uint64(float64(n)*s.rate) > uint64(float64(n-1)*s.rate)
+}
+
+// LoggingMiddlewareWithPolicy is LoggingMiddlewareWithMetrics that logs only the
+// requests policy selects. Metrics are still recorded for every request.
+func LoggingMiddlewareWithPolicy(m metrics.Recorder, policy RequestLogPolicy, next http.Handler) http.Handler {
+	skip := make(map[string]bool, len(policy.SkipPaths))
+	for _, path := range policy.SkipPaths {
+		skip[path] = true
+	}
+	sampler := &requestLogSampler{rate: policy.SampleRate}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
@@ -80,19 +144,37 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		// Calculate duration
 		duration := time.Since(start)
 
+		if m != nil {
+			m.ObserveHTTPRequest(routeKey(r), wrapped.statusCode, duration)
+		}
+
+		slow := policy.SlowThreshold > 0 && duration > policy.SlowThreshold
+		if skip[r.URL.Path] || (wrapped.statusCode < 400 && !slow && !sampler.sample()) {
+			return
+		}
+
 		// Get request ID from context
 		requestID, _ := r.Context().Value(RequestIDKey).(string)
 
 		// Log the request
-		log.Info().
+		event := log.Info()
+		if slow {
+			event = log.Warn().Dur("slow_threshold", policy.SlowThreshold)
+		}
+		event = event.
 			Str("method", r.Method).
 			Str("path", r.URL.Path).
+			Str("route", routeKey(r)).
 			Str("remote_addr", r.RemoteAddr).
 			Int("status", wrapped.statusCode).
 			Int64("size", wrapped.bytesWritten).
 			Dur("duration", duration).
-			Str("request_id", requestID).
-			Msg("HTTP request")
+			Str("request_id", requestID)
+		if wrapped.statusCode < 400 && !slow && policy.SampleRate < 1 {
+			// Lets log queries scale sampled counts back up
+			event = event.Float64("sample_rate", policy.SampleRate)
+		}
+		event.Msg("HTTP request")
 	})
 }
 
@@ -119,6 +201,63 @@ func RecoveryMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// TimeoutMiddleware gives each request a context deadline of d. Handlers pass that
+// context to the database, so work still running at the deadline is cancelled and
+// handleServiceError answers 504. Handlers that ignore their context are not interrupted.
+// Requests for which exempt returns true, such as streaming exports, get no deadline; a
+// nil exempt applies the deadline to every request.
+func TimeoutMiddleware(d time.Duration, exempt func(*http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt != nil && exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			serveWithContext(next, w, r, ctx)
+		})
+	}
+}
+
+// serveWithContext serves a copy of r carrying ctx. The router records the route it
+// matched on the request it is given, so the pattern is copied back onto r for the
+// logging, tracing, and route metrics middleware that label r by route once next returns.
+func serveWithContext(next http.Handler, w http.ResponseWriter, r *http.Request, ctx context.Context) {
+	routed := r.WithContext(ctx)
+	next.ServeHTTP(w, routed)
+	r.Pattern = routed.Pattern
+}
+
+// RequireAdminToken guards admin-only endpoints with a static bearer token.
+// Requests must send "Authorization: Bearer <token>". If no token is configured
+// the endpoint is disabled and every request is rejected with 403.
+func RequireAdminToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			writeServerJSON(w, http.StatusForbidden, map[string]interface{}{
+				"success": false,
+				"error":   "forbidden",
+				"message": "Admin endpoints are disabled",
+			})
+			return
+		}
+
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeServerJSON(w, http.StatusUnauthorized, map[string]interface{}{
+				"success": false,
+				"error":   "unauthorized",
+				"message": "A valid admin bearer token is required",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // responseWriter wraps http.ResponseWriter to capture response metadata.
 type responseWriter struct {
 	http.ResponseWriter
@@ -139,6 +278,12 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap returns the wrapped writer, so http.ResponseController can reach its Flush and
+// SetWriteDeadline for streaming handlers.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // GetRequestID extracts the request ID from the context.
 // Returns an empty string if no request ID is present.
 func GetRequestID(ctx context.Context) string {
@@ -0,0 +1,255 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/handler"
+	"internal-transfers-system/internal/interfaces"
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/metrics"
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestLoggingMiddleware_LogsRoutePattern(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = orig }()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	h := LoggingMiddleware(mux)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/accounts/42", nil))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decode log line: %v", err)
+	}
+	if entry["route"] != "GET /api/v1/accounts/{id}" {
+		t.Errorf("expected route pattern, got %v", entry["route"])
+	}
+	if entry["path"] != "/api/v1/accounts/42" {
+		t.Errorf("expected concrete path, got %v", entry["path"])
+	}
+}
+
+func TestLoggingMiddleware_SlowRequests(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = orig }()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /fast", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) { time.Sleep(20 * time.Millisecond) })
+
+	// Sampling every successful request out shows that slow ones are logged regardless
+	h := LoggingMiddlewareWithPolicy(nil, RequestLogPolicy{SampleRate: 0, SlowThreshold: 10 * time.Millisecond}, mux)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected exactly the slow request logged, got %q: %v", buf.String(), err)
+	}
+	if entry["level"] != "warn" || entry["path"] != "/slow" {
+		t.Errorf("expected a warning for /slow, got %v", entry)
+	}
+	if entry["slow_threshold"] != float64(10) || entry["sample_rate"] != nil {
+		t.Errorf("expected the threshold and no sample rate, got %v", entry)
+	}
+}
+
+func TestLoggingMiddlewareWithPolicy(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = orig }()
+
+	m := metrics.New()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /missing", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) })
+	mux.HandleFunc("GET /broken", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) })
+	mux.HandleFunc("GET /ready", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) })
+
+	h := LoggingMiddlewareWithPolicy(m, RequestLogPolicy{SampleRate: 0.25, SkipPaths: []string{"/health", "/ready"}}, mux)
+	serve := func(path string, times int) {
+		for i := 0; i < times; i++ {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+	}
+	serve("/ok", 8)
+	serve("/missing", 3)
+	serve("/broken", 2)
+	serve("/ready", 5)
+
+	logged := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("decode log line %q: %v", line, err)
+		}
+		logged[entry["path"].(string)]++
+		if entry["path"] == "/ok" && entry["sample_rate"] != 0.25 {
+			t.Errorf("expected sampled lines to carry the sample rate, got %v", entry)
+		}
+		if entry["path"] != "/ok" && entry["sample_rate"] != nil {
+			t.Errorf("expected unsampled lines without a sample rate, got %v", entry)
+		}
+	}
+	if logged["/ok"] != 2 {
+		t.Errorf("expected a quarter of 8 successful requests logged, got %d", logged["/ok"])
+	}
+	if logged["/missing"] != 3 || logged["/broken"] != 2 {
+		t.Errorf("expected every error response logged, got %v", logged)
+	}
+	if logged["/ready"] != 0 {
+		t.Errorf("expected probe paths never logged, even failing, got %d", logged["/ready"])
+	}
+
+	// Skipped and unsampled requests are still measured
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		`http_request_duration_seconds_count{path="GET /ok",status="200"} 8`,
+		`http_request_duration_seconds_count{path="GET /ready",status="503"} 5`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("expected %s in metrics", want)
+		}
+	}
+}
+
+func TestRequestLogSampler(t *testing.T) {
+	for _, tt := range []struct {
+		rate float64
+		want int
+	}{{0, 0}, {0.1, 10}, {0.5, 50}, {1, 100}} {
+		s := &requestLogSampler{rate: tt.rate}
+		got := 0
+		for i := 0; i < 100; i++ {
+			if s.sample() {
+				got++
+			}
+		}
+		if got != tt.want {
+			t.Errorf("rate %v: expected %d of 100 sampled, got %d", tt.rate, tt.want, got)
+		}
+	}
+}
+
+func TestLoggingMiddlewareWithMetrics(t *testing.T) {
+	m := metrics.New()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	h := LoggingMiddlewareWithMetrics(m, mux)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/accounts/1", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/accounts/2", nil))
+
+	// Both requests share the route pattern, so they land in a single series
+	if got := testutil.CollectAndCount(m.HTTPRequestDuration); got != 1 {
+		t.Fatalf("expected 1 series, got %d", got)
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	want := `http_request_duration_seconds_count{path="GET /api/v1/accounts/{id}",status="404"} 2`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("expected %q in metrics output", want)
+	}
+}
+
+func TestRequestIDMiddleware_StoresRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = orig }()
+
+	h := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logging.FromContext(r.Context()).Info().Msg("from service")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "client-id")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid log line: %v", err)
+	}
+	if entry["request_id"] != "client-id" {
+		t.Errorf("expected request_id client-id, got %v", entry)
+	}
+}
+
+// slowAccountRepo blocks in GetByID like a query stuck on a lock, until ctx ends.
+type slowAccountRepo struct {
+	interfaces.AccountRepository
+}
+
+func (slowAccountRepo) GetByID(ctx context.Context, _ int64) (*models.Account, error) {
+	select {
+	case <-time.After(5 * time.Second):
+		return &models.Account{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	h := handler.NewAccountHandler(service.NewAccountService(slowAccountRepo{mocks.NewMockAccountRepository()}))
+	mw := TimeoutMiddleware(50*time.Millisecond, nil)(http.HandlerFunc(h.GetAccount))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/1", nil)
+	req.SetPathValue("id", "1")
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	mw.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the request to end at the deadline, took %s", elapsed)
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp["error"] != "timeout" {
+		t.Errorf("expected timeout error, got %s", rec.Body.String())
+	}
+}
+
+func TestTimeoutMiddleware_Exempt(t *testing.T) {
+	exempt := func(r *http.Request) bool { return r.URL.Path == "/export" }
+	var hasDeadline bool
+	mw := TimeoutMiddleware(time.Minute, exempt)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline = r.Context().Deadline()
+	}))
+
+	mw.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/accounts", nil))
+	if !hasDeadline {
+		t.Error("expected a deadline on a regular request")
+	}
+	mw.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/export", nil))
+	if hasDeadline {
+		t.Error("expected no deadline on an exempt request")
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolStatsResponse is the body of GET /health/db: a snapshot of every connection pool,
+// keyed "transfer", "read", "export", and "replica". Pools that share a connection pool report the
+// same numbers.
+type PoolStatsResponse struct {
+	Timestamp time.Time            `json:"timestamp"`
+	Pools     map[string]PoolStats `json:"pools"`
+}
+
+// PoolStats mirrors pgxpool.Stat. EmptyAcquireCount counts acquires that had to wait for
+// a connection; if it climbs while AcquiredConns sits at MaxConns, the pool is exhausted.
+type PoolStats struct {
+	TotalConns           int32   `json:"total_conns"`
+	IdleConns            int32   `json:"idle_conns"`
+	AcquiredConns        int32   `json:"acquired_conns"`
+	ConstructingConns    int32   `json:"constructing_conns"`
+	MaxConns             int32   `json:"max_conns"`
+	AcquireCount         int64   `json:"acquire_count"`
+	EmptyAcquireCount    int64   `json:"empty_acquire_count"`
+	CanceledAcquireCount int64   `json:"canceled_acquire_count"`
+	AcquireDurationMs    float64 `json:"acquire_duration_ms"`
+}
+
+func newPoolStats(stat *pgxpool.Stat) PoolStats {
+	return PoolStats{
+		TotalConns:           stat.TotalConns(),
+		IdleConns:            stat.IdleConns(),
+		AcquiredConns:        stat.AcquiredConns(),
+		ConstructingConns:    stat.ConstructingConns(),
+		MaxConns:             stat.MaxConns(),
+		AcquireCount:         stat.AcquireCount(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		CanceledAcquireCount: stat.CanceledAcquireCount(),
+		AcquireDurationMs:    float64(stat.AcquireDuration()) / float64(time.Millisecond),
+	}
+}
+
+// poolStats reads Stat() from each configured pool. Unset pools are omitted.
+func (s *Server) poolStats() map[string]PoolStats {
+	stats := make(map[string]PoolStats, 3)
+	for name, pool := range s.pools.ByName() {
+		if pool != nil {
+			stats[name] = newPoolStats(pool.Stat())
+		}
+	}
+	return stats
+}
+
+// handleDBHealth reports connection pool statistics for capacity planning and for
+// spotting pool exhaustion during incidents. It never touches the database, so it answers
+// even when every connection is busy.
+//
+// Response: 200 OK with PoolStatsResponse body
+func (s *Server) handleDBHealth(w http.ResponseWriter, r *http.Request) {
+	writeServerJSON(w, http.StatusOK, PoolStatsResponse{
+		Timestamp: time.Now().UTC(),
+		Pools:     s.poolStats(),
+	})
+}
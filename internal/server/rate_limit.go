@@ -0,0 +1,129 @@
+package server
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitIdleTTL is how long a client's limiter is kept after its last request.
+// An idle bucket refills completely long before this, so dropping it loses nothing.
+const rateLimitIdleTTL = 10 * time.Minute
+
+// clientLimiter is a single client's token bucket.
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// clientLimiters holds one token bucket per client key and evicts idle ones.
+type clientLimiters struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newClientLimiters(limit rate.Limit, burst int) *clientLimiters {
+	return &clientLimiters{
+		limit:     limit,
+		burst:     burst,
+		clients:   make(map[string]*clientLimiter),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// reserve takes a token for key. It returns 0 if the request may proceed, or how long
+// the client should wait before retrying. A rejected request does not consume a token.
+func (c *clientLimiters) reserve(key string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.Sub(c.lastSweep) > rateLimitIdleTTL {
+		c.sweep(now)
+	}
+
+	cl, ok := c.clients[key]
+	if !ok {
+		cl = &clientLimiter{limiter: rate.NewLimiter(c.limit, c.burst)}
+		c.clients[key] = cl
+	}
+	cl.lastSeen = now
+
+	res := cl.limiter.ReserveN(now, 1)
+	if !res.OK() {
+		// burst is 0: no request can ever be admitted
+		return rateLimitIdleTTL
+	}
+	delay := res.DelayFrom(now)
+	if delay > 0 {
+		res.CancelAt(now)
+	}
+	return delay
+}
+
+// sweep drops limiters idle for longer than rateLimitIdleTTL. Must hold c.mu.
+func (c *clientLimiters) sweep(now time.Time) {
+	for key, cl := range c.clients {
+		if now.Sub(cl.lastSeen) > rateLimitIdleTTL {
+			delete(c.clients, key)
+		}
+	}
+	c.lastSweep = now
+}
+
+// RateLimitMiddleware applies a token-bucket rate limit per client IP: each client may
+// make burst requests at once and limit requests per second sustained. Requests over the
+// limit get 429 with a Retry-After header (whole seconds) and a JSON error body.
+//
+// The client is identified by the IP in RemoteAddr. Forwarding headers such as
+// X-Forwarded-For are ignored because clients can forge them to dodge the limit.
+func RateLimitMiddleware(limit rate.Limit, burst int) func(http.Handler) http.Handler {
+	limiters := newClientLimiters(limit, burst)
+	return func(next http.Handler) http.Handler {
+		return rateLimit(limiters, next)
+	}
+}
+
+func rateLimit(limiters *clientLimiters, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := clientIP(r)
+		if delay := limiters.reserve(key); delay > 0 {
+			retryAfter := int(math.Ceil(delay.Seconds()))
+			log.Debug().
+				Str("client", key).
+				Int("retry_after", retryAfter).
+				Str("request_id", GetRequestID(r.Context())).
+				Msg("Rate limit exceeded")
+
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			writeServerJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+				"success": false,
+				"error":   "rate_limited",
+				"message": "Too many requests. Please retry later.",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the host part of r.RemoteAddr, or RemoteAddr itself if it has no port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := RateLimitMiddleware(rate.Limit(1), 3)(ok)
+
+	do := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/1", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		if rec := do("10.0.0.1:5000"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d within burst: expected 200, got %d", i+1, rec.Code)
+		}
+	}
+
+	// Same IP from a different source port shares the bucket
+	rec := do("10.0.0.1:6000")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("past burst: expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected Retry-After 1, got %q", rec.Header().Get("Retry-After"))
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["error"] != "rate_limited" {
+		t.Errorf("expected JSON rate_limited error, got %s", rec.Body.String())
+	}
+
+	// Other clients are unaffected
+	for i := 0; i < 3; i++ {
+		if rec := do("10.0.0.2:5000"); rec.Code != http.StatusOK {
+			t.Fatalf("unrelated IP request %d: expected 200, got %d", i+1, rec.Code)
+		}
+	}
+}
+
+func TestClientLimiters_RefillAndSweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiters := newClientLimiters(rate.Limit(2), 1)
+	limiters.now = func() time.Time { return now }
+	limiters.lastSweep = now
+
+	if d := limiters.reserve("a"); d != 0 {
+		t.Fatalf("first request: expected admit, got delay %s", d)
+	}
+	if d := limiters.reserve("a"); d != 500*time.Millisecond {
+		t.Fatalf("over limit: expected 500ms delay, got %s", d)
+	}
+
+	// Rejected requests don't consume tokens, so the bucket refills on schedule
+	now = now.Add(500 * time.Millisecond)
+	if d := limiters.reserve("a"); d != 0 {
+		t.Fatalf("after refill: expected admit, got delay %s", d)
+	}
+
+	now = now.Add(rateLimitIdleTTL + time.Second)
+	limiters.reserve("b")
+	if _, ok := limiters.clients["a"]; ok {
+		t.Error("expected idle client to be swept")
+	}
+}
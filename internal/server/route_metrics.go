@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// latencyBuckets are the upper bounds of the in-memory latency histogram.
+// Anything slower than the last bound falls into an implicit +Inf bucket.
+var latencyBuckets = []time.Duration{
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// unmatchedRoute is the key used for requests that did not match any registered pattern.
+const unmatchedRoute = "unmatched"
+
+// routeStats accumulates request counts and a latency histogram for a single route.
+type routeStats struct {
+	count   int64
+	errors  int64
+	sum     time.Duration
+	max     time.Duration
+	buckets []int64
+}
+
+func newRouteStats() *routeStats {
+	return &routeStats{buckets: make([]int64, len(latencyBuckets)+1)}
+}
+
+func (s *routeStats) observe(d time.Duration, status int) {
+	s.count++
+	if status >= 500 {
+		s.errors++
+	}
+	s.sum += d
+	if d > s.max {
+		s.max = d
+	}
+	i := sort.Search(len(latencyBuckets), func(i int) bool { return d <= latencyBuckets[i] })
+	s.buckets[i]++
+}
+
+// percentile returns the upper bound of the bucket containing quantile q.
+// Observations beyond the last bucket report the maximum seen.
+func (s *routeStats) percentile(q float64) time.Duration {
+	if s.count == 0 {
+		return 0
+	}
+	rank := int64(q * float64(s.count))
+	if rank < 1 {
+		rank = 1
+	}
+	var cumulative int64
+	for i, n := range s.buckets {
+		cumulative += n
+		if cumulative >= rank {
+			if i < len(latencyBuckets) {
+				return latencyBuckets[i]
+			}
+			return s.max
+		}
+	}
+	return s.max
+}
+
+// RouteMetrics keeps lightweight per-route request counts and latency histograms
+// in memory and periodically logs a summary line per route. It is intended for
+// environments without a metrics backend.
+type RouteMetrics struct {
+	mu     sync.Mutex
+	routes map[string]*routeStats
+}
+
+// NewRouteMetrics creates an empty RouteMetrics collector.
+func NewRouteMetrics() *RouteMetrics {
+	return &RouteMetrics{routes: make(map[string]*routeStats)}
+}
+
+// Middleware records the latency and status of each request under its matched
+// route pattern (e.g. "GET /api/v1/accounts/{id}"), so path parameters don't
+// explode the number of keys.
+func (m *RouteMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		wrapped := &responseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		next.ServeHTTP(wrapped, r)
+
+		m.Observe(routeKey(r), time.Since(start), wrapped.statusCode)
+	})
+}
+
+// Observe records a single request against route.
+func (m *RouteMetrics) Observe(route string, d time.Duration, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.routes[route]
+	if !ok {
+		stats = newRouteStats()
+		m.routes[route] = stats
+	}
+	stats.observe(d, status)
+}
+
+// Flush logs one summary line per route observed since the previous flush and
+// resets the counters, so each line describes a single interval.
+func (m *RouteMetrics) Flush(interval time.Duration) {
+	m.mu.Lock()
+	routes := m.routes
+	m.routes = make(map[string]*routeStats)
+	m.mu.Unlock()
+
+	keys := make([]string, 0, len(routes))
+	for k := range routes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, route := range keys {
+		stats := routes[route]
+		log.Info().
+			Str("route", route).
+			Dur("interval", interval).
+			Int64("count", stats.count).
+			Int64("errors", stats.errors).
+			Dur("mean", stats.sum/time.Duration(stats.count)).
+			Dur("p50", stats.percentile(0.50)).
+			Dur("p90", stats.percentile(0.90)).
+			Dur("p99", stats.percentile(0.99)).
+			Dur("max", stats.max).
+			Msg("Route metrics rollup")
+	}
+}
+
+// Run flushes a rollup every interval until ctx is cancelled, then flushes once more
+// so the final partial interval isn't lost on shutdown.
+func (m *RouteMetrics) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.Flush(interval)
+		case <-ctx.Done():
+			m.Flush(interval)
+			return
+		}
+	}
+}
+
+// routeKey returns the pattern the router matched for r, or unmatchedRoute.
+// http.ServeMux sets r.Pattern on the request it is handed, so this is only
+// populated after the router has run.
+func routeKey(r *http.Request) string {
+	if r.Pattern == "" {
+		return unmatchedRoute
+	}
+	return r.Pattern
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRouteStats_Percentile(t *testing.T) {
+	stats := newRouteStats()
+	for i := 0; i < 90; i++ {
+		stats.observe(3*time.Millisecond, http.StatusOK)
+	}
+	for i := 0; i < 9; i++ {
+		stats.observe(80*time.Millisecond, http.StatusOK)
+	}
+	stats.observe(30*time.Second, http.StatusInternalServerError)
+
+	if got := stats.percentile(0.50); got != 5*time.Millisecond {
+		t.Errorf("p50: expected 5ms, got %s", got)
+	}
+	if got := stats.percentile(0.99); got != 100*time.Millisecond {
+		t.Errorf("p99: expected 100ms, got %s", got)
+	}
+	if got := stats.percentile(1.0); got != 30*time.Second {
+		t.Errorf("p100: expected max 30s, got %s", got)
+	}
+	if stats.count != 100 || stats.errors != 1 {
+		t.Errorf("expected count=100 errors=1, got %d/%d", stats.count, stats.errors)
+	}
+}
+
+func TestRouteMetrics_KeysByPattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	metrics := NewRouteMetrics()
+	h := metrics.Middleware(mux)
+
+	for _, path := range []string{"/api/v1/accounts/1", "/api/v1/accounts/2", "/nope"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := metrics.routes["GET /api/v1/accounts/{id}"]; got == nil || got.count != 2 {
+		t.Errorf("expected 2 requests under pattern, got %+v", got)
+	}
+	if got := metrics.routes[unmatchedRoute]; got == nil || got.count != 1 {
+		t.Errorf("expected 1 unmatched request, got %+v", got)
+	}
+
+	metrics.Flush(time.Minute)
+	if len(metrics.routes) != 0 {
+		t.Error("expected counters reset after flush")
+	}
+}
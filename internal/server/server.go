@@ -5,16 +5,30 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"sync"
 	"time"
 
+	"internal-transfers-system/internal/grpcserver"
 	"internal-transfers-system/internal/handler"
+	"internal-transfers-system/internal/idcodec"
+	"internal-transfers-system/internal/interfaces"
+	"internal-transfers-system/internal/metrics"
+	"internal-transfers-system/internal/models"
 	"internal-transfers-system/internal/repository"
+	"internal-transfers-system/internal/repository/memory"
 	"internal-transfers-system/internal/service"
+	"internal-transfers-system/internal/validator"
+	"internal-transfers-system/internal/webhook"
 	config "internal-transfers-system/pkg/config"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
 )
 
 // Server represents the HTTP server for the internal transfers API.
@@ -22,11 +36,58 @@ import (
 type Server struct {
 	httpServer *http.Server
 	router     *http.ServeMux
+
+	// tls names the certificate files the HTTP server serves HTTPS with; the zero value
+	// serves plain HTTP
+	tls tlsFiles
+
 	db         *pgxpool.Pool
+	pools      repository.Pools
+	adminToken string
+
+	// metrics serves /metrics with the Prometheus backend and is nil otherwise.
+	// closeMetrics releases the StatsD socket, when there is one.
+	metrics      *metrics.Metrics
+	closeMetrics func() error
+
+	// inFlight counts running transfer and adjustment requests; Shutdown waits for it
+	// before closing the database pools.
+	inFlight  *sync.WaitGroup
+	closePool func()
+
+	// readyChecks are run by /ready; see RegisterReadyCheck
+	readyChecks []readyCheck
+
+	// schemaVersion reads the applied migration version for /ready
+	schemaVersion func(ctx context.Context) (*models.SchemaVersion, error)
+
+	// Optional stricter rate limit for account creation routes (nil when disabled)
+	accountCreateLimit func(http.Handler) http.Handler
+
+	// Optional per-route metrics rollup (nil when disabled)
+	routeMetrics         *RouteMetrics
+	routeMetricsInterval time.Duration
+	stopRouteMetrics     context.CancelFunc
+
+	// Optional completed-transfer webhooks (nil when no URLs are configured)
+	webhooks     *webhook.Notifier
+	stopWebhooks context.CancelFunc
+
+	// Recurring transfer scheduler (not started when recurringInterval is zero).
+	// stopRecurring cancels it; recurringDone is closed once the run under way returns.
+	transferService   *service.TransferService
+	recurringInterval time.Duration
+	stopRecurring     context.CancelFunc
+	recurringDone     chan struct{}
+
+	// Optional gRPC API (nil when SERVER_GRPC_PORT is unset), served on grpcAddr
+	grpcServer *grpc.Server
+	grpcAddr   string
 
 	// Handlers for different API endpoints
 	accountHandler     *handler.AccountHandler
 	transactionHandler *handler.TransactionHandler
+	ledgerHandler      *handler.LedgerHandler
 }
 
 // New creates a new Server instance with all dependencies configured.
@@ -36,92 +97,568 @@ type Server struct {
 //   - HTTP handlers
 //   - Middleware chain (recovery, request ID, logging)
 //   - Route registration
-func New(cfg config.ServerConfig, db *pgxpool.Pool) *Server {
-	router := http.NewServeMux()
+//
+// Every operation shares db; see NewWithPools to isolate reads and exports.
+func New(cfg *config.Config, db *pgxpool.Pool) *Server {
+	return NewWithPools(cfg, repository.SinglePool(db))
+}
 
+// NewWithPools is New with separate connection pools for transfers, reads, and exports.
+// Health checks, consistency tokens, and migrations status use pools.Transfer. Shutdown
+// closes every pool.
+func NewWithPools(cfg *config.Config, pools repository.Pools) *Server {
 	// Create repositories (data access layer)
-	accountRepo := repository.NewAccountRepository(db)
-	transactionRepo := repository.NewTransactionRepository(db)
+	accountRepo := repository.NewAccountRepositoryWithPools(pools, pgx.TxIsoLevel(cfg.Database.IsolationLevel))
+	transactionRepo := repository.NewTransactionRepositoryWithPools(pools)
+	return newServer(cfg, pools, accountRepo, transactionRepo)
+}
+
+// NewInMemory is New backed by an empty in-memory store instead of PostgreSQL, for
+// DB_DRIVER=memory. Nothing is persisted across restarts, /ready has no database check,
+// and consistency tokens are disabled.
+func NewInMemory(cfg *config.Config) *Server {
+	store := memory.NewStore()
+	return newServer(cfg, repository.Pools{}, memory.NewAccountRepository(store), memory.NewTransactionRepository(store))
+}
+
+// newServer wires the services, handlers, and middleware around the repositories. The
+// database checks and consistency tokens are set up only when pools has a transfer pool.
+func newServer(cfg *config.Config, pools repository.Pools, accountRepo interfaces.AccountRepository, transactionRepo interfaces.TransactionRepository) *Server {
+	router := http.NewServeMux()
+	db := pools.Transfer
+
+	// Metrics sink, shared by the transfer service, the handlers, and the logging middleware
+	m, prom, closeMetrics := newMetricsRecorder(cfg.Metrics)
 
 	// Create services (business logic layer)
-	accountService := service.NewAccountService(accountRepo)
-	transferService := service.NewTransferService(accountRepo, transactionRepo)
+	// Load has already checked the threshold parses
+	warnThreshold, _ := decimal.NewFromString(cfg.Account.InitialBalanceWarnThreshold)
+	accountService := service.NewAccountServiceWithConfig(accountRepo, service.AccountServiceConfig{
+		InitialBalanceWarnThreshold: warnThreshold,
+	})
+	// Load has already checked the transfer limits parse; empty means no limit
+	minTransfer, _ := decimal.NewFromString(cfg.Transfer.MinAmount)
+	maxTransfer, _ := decimal.NewFromString(cfg.Transfer.MaxAmount)
+	dailyAmount, _ := decimal.NewFromString(cfg.Transfer.DailyAmountLimit)
+	transferService := service.NewTransferServiceWithConfig(accountRepo, transactionRepo, service.TransferServiceConfig{
+		MaxRetries:           cfg.Transfer.MaxRetries,
+		RetryBaseDelay:       cfg.Transfer.RetryBaseDelay,
+		RetryJitter:          cfg.Transfer.RetryJitter,
+		RetryOnCommitFailure: cfg.Transfer.RetryOnCommitFailure,
+		RetryUnseenAccounts:  cfg.Transfer.RetryUnseenAccounts,
+		ConcurrencyMode:      service.ConcurrencyMode(cfg.Transfer.ConcurrencyMode),
+		LockStrategy:         service.LockStrategy(cfg.Transfer.LockStrategy),
+
+		EffectiveDateMaxPastDays:   cfg.Transfer.EffectiveDateMaxPastDays,
+		EffectiveDateMaxFutureDays: cfg.Transfer.EffectiveDateMaxFutureDays,
+
+		ForbidZeroBalanceTypes: cfg.Transfer.ForbidZeroBalanceTypes,
+		SourceCooldown:         cfg.Transfer.SourceCooldown,
+		DailyAmountLimit:       dailyAmount,
+		DailyCountLimit:        cfg.Transfer.DailyCountLimit,
+		StuckTransferThreshold: cfg.Transfer.StuckThreshold,
+		SlowTransferThreshold:  cfg.Transfer.SlowThreshold,
+		RecurringCatchUp:       service.CatchUpPolicy(cfg.Transfer.RecurringCatchUp),
+		RecurringBatchSize:     cfg.Transfer.RecurringBatchSize,
+
+		MinTransferAmount: minTransfer,
+		MaxTransferAmount: maxTransfer,
+
+		Fees:     feePolicy(cfg.Transfer),
+		Blackout: blackoutSchedule(cfg.Transfer),
+	})
+	transferService.SetMetrics(m)
+	var webhooks *webhook.Notifier
+	if len(cfg.Webhook.URLs) > 0 {
+		webhooks = webhook.NewNotifier(webhook.Config{
+			URLs:           cfg.Webhook.URLs,
+			Secret:         cfg.Webhook.Secret,
+			QueueSize:      cfg.Webhook.QueueSize,
+			MaxAttempts:    cfg.Webhook.MaxAttempts,
+			RetryBaseDelay: cfg.Webhook.RetryBaseDelay,
+			Timeout:        cfg.Webhook.Timeout,
+		})
+		transferService.AddHook(webhooks)
+	}
+	ledgerService := service.NewLedgerServiceWithConfig(accountRepo, transactionRepo, service.LedgerServiceConfig{
+		ForbidZeroBalanceTypes: cfg.Transfer.ForbidZeroBalanceTypes,
+	})
 
 	// Create handlers (presentation layer)
-	accountHandler := handler.NewAccountHandler(accountService)
-	transactionHandler := handler.NewTransactionHandler(transferService)
+	handlerOpts := handler.Options{
+		Limits: handler.PageLimits{
+			DefaultSize: cfg.Pagination.DefaultSize,
+			MaxListing:  cfg.Pagination.MaxListing,
+			MaxBatchGet: cfg.Pagination.MaxBatchGet,
+			MaxExport:   cfg.Pagination.MaxExport,
+		},
+		ValidationMode: validator.CollectAll,
+		DebugResponses: cfg.Server.DebugResponsesEnabled,
+		MaxRequestBody: cfg.Server.MaxRequestBody,
+		InFlight:       &sync.WaitGroup{},
+		Metrics:        m,
+		FixedAmounts:   cfg.Money.MinorUnits >= 0,
+		MinorUnits:     cfg.Money.MinorUnits,
+		Currency:       cfg.Money.Currency,
+	}
+	if cfg.Server.ValidationFailFast {
+		handlerOpts.ValidationMode = validator.FailFast
+	}
+	if cfg.Server.IDEncoding == config.IDEncodingOpaque {
+		// Load has already checked the salt length
+		codec, _ := idcodec.NewOpaque(cfg.Server.OpaqueIDSalt)
+		handlerOpts.IDCodec = codec
+	}
+	if cfg.Server.DebugResponsesEnabled {
+		log.Warn().Msg("Debug responses are enabled; do not use this setting in production")
+	}
+	accountHandler := handler.NewAccountHandlerWithOptions(accountService, handlerOpts)
+	transactionHandler := handler.NewTransactionHandlerWithOptions(transferService, handlerOpts)
+	ledgerHandler := handler.NewLedgerHandlerWithOptions(ledgerService, handlerOpts)
 
 	srv := &Server{
-		router: router,
-		db:     db,
+		router:       router,
+		db:           db,
+		pools:        pools,
+		adminToken:   cfg.Server.AdminToken,
+		metrics:      prom,
+		closeMetrics: closeMetrics,
+		webhooks:     webhooks,
+
+		transferService:   transferService,
+		recurringInterval: cfg.Transfer.RecurringPollInterval,
+		inFlight:          handlerOpts.InFlight,
+		closePool:         pools.Close,
+		tls: tlsFiles{
+			CertFile:     cfg.Server.TLSCertFile,
+			KeyFile:      cfg.Server.TLSKeyFile,
+			ClientCAFile: cfg.Server.TLSClientCAFile,
+		},
 		httpServer: &http.Server{
-			Addr:         cfg.Address(),
-			ReadTimeout:  cfg.ReadTimeout,
-			WriteTimeout: cfg.WriteTimeout,
-			IdleTimeout:  cfg.IdleTimeout,
+			Addr:         cfg.Server.Address(),
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
 		},
 		accountHandler:     accountHandler,
 		transactionHandler: transactionHandler,
+		ledgerHandler:      ledgerHandler,
+	}
+
+	// The account creation limit is shared by the HTTP routes and gRPC CreateAccount
+	grpcOpts := grpcserver.Options{
+		ValidationMode: handlerOpts.ValidationMode,
+		FixedAmounts:   handlerOpts.FixedAmounts,
+		MinorUnits:     handlerOpts.MinorUnits,
+	}
+	if cfg.Server.AccountCreateRateLimitEnabled {
+		limiters := newClientLimiters(rate.Limit(cfg.Server.AccountCreateRateLimitRPS), cfg.Server.AccountCreateRateLimitBurst)
+		srv.accountCreateLimit = func(next http.Handler) http.Handler {
+			return rateLimit(limiters, next)
+		}
+		grpcOpts.AccountCreateLimit = limiters.reserve
+	}
+
+	if cfg.Server.GRPCPort > 0 {
+		srv.grpcServer = grpcserver.NewGRPCServer(grpcserver.New(accountService, transferService, grpcOpts))
+		srv.grpcAddr = cfg.Server.GRPCAddress()
+	}
+
+	if db != nil {
+		srv.schemaVersion = repository.NewSchemaRepository(db).Version
+		srv.RegisterReadyCheck(DatabaseCheck(db), DefaultReadyCheckTimeout)
 	}
 
 	// Register routes with handlers
 	srv.registerRoutes()
 
-	// Apply middleware chain (order matters: outermost first)
-	// Recovery -> RequestID -> Logging -> Router
-	handler := RecoveryMiddleware(
-		RequestIDMiddleware(
-			LoggingMiddleware(router),
-		),
-	)
-	srv.httpServer.Handler = handler
+	var currentLSN LSNFunc
+	if cfg.Server.ConsistencyTokensEnabled {
+		if db != nil {
+			currentLSN = PoolLSN(db)
+		} else {
+			log.Warn().Msg("Consistency tokens need PostgreSQL; ignoring SERVER_CONSISTENCY_TOKENS_ENABLED")
+		}
+	}
+	srv.httpServer.Handler = srv.middleware(cfg, m, currentLSN)
 
 	return srv
 }
 
+// middleware wraps the router in the middleware chain configured by cfg. Consistency
+// tokens are issued only when currentLSN is non-nil.
+func (s *Server) middleware(cfg *config.Config, m metrics.Recorder, currentLSN LSNFunc) http.Handler {
+	// Order matters: outermost first
+	// Recovery -> RequestID -> [Tracing] -> Logging -> [RateLimit] -> [ConsistencyToken] -> [RouteMetrics] -> [Timeout] -> Router
+	var inner http.Handler = s.router
+	if cfg.Server.RequestTimeout > 0 {
+		inner = TimeoutMiddleware(cfg.Server.RequestTimeout, func(r *http.Request) bool {
+			_, pattern := s.router.Handler(r)
+			return streamingRoutes[pattern]
+		})(inner)
+	}
+	if cfg.Server.RouteMetricsEnabled {
+		s.routeMetrics = NewRouteMetrics()
+		s.routeMetricsInterval = cfg.Server.RouteMetricsInterval
+		inner = s.routeMetrics.Middleware(inner)
+	}
+	if currentLSN != nil {
+		inner = ConsistencyTokenMiddleware(currentLSN, inner)
+	}
+	if cfg.Server.RateLimitEnabled {
+		inner = RateLimitMiddleware(rate.Limit(cfg.Server.RateLimitRPS), cfg.Server.RateLimitBurst)(inner)
+	}
+
+	handler := LoggingMiddlewareWithPolicy(m, RequestLogPolicy{
+		SampleRate:    cfg.Log.SampleRate,
+		SkipPaths:     cfg.Log.SkipPaths,
+		SlowThreshold: cfg.Log.SlowRequestThreshold,
+	}, inner)
+	if cfg.Tracing.Enabled {
+		handler = TracingMiddleware(handler)
+	}
+	return RecoveryMiddleware(RequestIDMiddleware(handler))
+}
+
+// streamingRoutes write their response as rows are read, for as long as the export
+// takes, so SERVER_REQUEST_TIMEOUT does not apply to them. A client that disconnects
+// still cancels the export.
+var streamingRoutes = map[string]bool{
+	"GET /api/v1/accounts.ndjson":                true,
+	"GET /api/v1/accounts/{id}/transactions.csv": true,
+}
+
 // registerRoutes sets up all HTTP routes for the API.
 // Routes are organized by resource type and versioned under /api/v1.
 func (s *Server) registerRoutes() {
 	// Health check endpoints (no versioning for infrastructure endpoints)
 	s.router.HandleFunc("GET /health", s.handleHealth)
 	s.router.HandleFunc("GET /ready", s.handleReady)
+	s.router.HandleFunc("GET /health/db", s.handleDBHealth)
+	if s.metrics != nil {
+		s.router.Handle("GET /metrics", s.metrics.Handler())
+	}
 
 	// Account endpoints
-	// POST /api/v1/accounts - Create a new account
+	// POST /api/v1/accounts - Create a new account (subject to the account creation rate limit)
+	// POST /api/v1/accounts/batch - Create many accounts at once (same rate limit, per request)
+	// GET /api/v1/accounts?ids=1,2,3 - Get several accounts in one call
 	// GET /api/v1/accounts/{id} - Get account details
-	s.router.HandleFunc("POST /api/v1/accounts", s.accountHandler.CreateAccount)
+	// DELETE /api/v1/accounts/{id} - Close an account (kept for history, never deleted)
+	s.router.Handle("POST /api/v1/accounts", s.limitAccountCreate(http.HandlerFunc(s.accountHandler.CreateAccount)))
+	s.router.Handle("POST /api/v1/accounts/batch", s.limitAccountCreate(http.HandlerFunc(s.accountHandler.BatchCreateAccounts)))
+	s.router.HandleFunc("GET /api/v1/accounts", s.accountHandler.GetAccounts)
 	s.router.HandleFunc("GET /api/v1/accounts/{id}", s.accountHandler.GetAccount)
+	s.router.HandleFunc("DELETE /api/v1/accounts/{id}", s.accountHandler.CloseAccount)
+
+	// Admin endpoints (require SERVER_ADMIN_TOKEN)
+	// GET /api/v1/accounts.ndjson - Stream all accounts for reconciliation
+	s.router.Handle("GET /api/v1/accounts.ndjson",
+		RequireAdminToken(s.adminToken, http.HandlerFunc(s.accountHandler.ExportAccounts)))
+
+	// GET /api/v1/admin/accounts - Browse accounts by balance range, sorted and paged
+	s.router.Handle("GET /api/v1/admin/accounts",
+		RequireAdminToken(s.adminToken, http.HandlerFunc(s.accountHandler.ListAccounts)))
+
+	// POST /api/v1/admin/accounts:batchAdjust - Apply a bulk balance correction
+	s.router.Handle("POST /api/v1/admin/accounts:batchAdjust",
+		RequireAdminToken(s.adminToken, http.HandlerFunc(s.accountHandler.BatchAdjustBalances)))
+
+	// POST /api/v1/admin/reconcile - Compare imported balances with stored ones, optionally correcting them
+	s.router.Handle("POST /api/v1/admin/reconcile",
+		RequireAdminToken(s.adminToken, http.HandlerFunc(s.accountHandler.ReconcileBalances)))
+
+	// GET /api/v1/admin/transfers/active - Count transfers in progress and show the oldest
+	s.router.Handle("GET /api/v1/admin/transfers/active",
+		RequireAdminToken(s.adminToken, http.HandlerFunc(s.transactionHandler.ActiveTransfers)))
+
+	// GET /api/v1/admin/consistency - Check account balances against the recorded history
+	s.router.Handle("GET /api/v1/admin/consistency",
+		RequireAdminToken(s.adminToken, http.HandlerFunc(s.transactionHandler.VerifyLedger)))
+
+	// GET /api/v1/accounts/{id}/balance-history - List an account's balance changes
+	s.router.HandleFunc("GET /api/v1/accounts/{id}/balance-history", s.accountHandler.GetBalanceHistory)
+
+	// GET /api/v1/accounts/{id}/transactions - List an account's transactions
+	s.router.HandleFunc("GET /api/v1/accounts/{id}/transactions", s.transactionHandler.ListAccountTransactions)
+
+	// GET /api/v1/accounts/{id}/transactions.csv - Download an account statement as CSV
+	s.router.HandleFunc("GET /api/v1/accounts/{id}/transactions.csv", s.transactionHandler.ExportAccountTransactions)
+
+	// GET /api/v1/accounts/{id}/ledger - List an account's double-entry ledger entries
+	s.router.HandleFunc("GET /api/v1/accounts/{id}/ledger", s.transactionHandler.ListAccountLedger)
+
+	// GET /api/v1/accounts/{id}/transactions/by-category - Totals per category over a date range
+	s.router.HandleFunc("GET /api/v1/accounts/{id}/transactions/by-category", s.transactionHandler.AccountCategorySummary)
+
+	// Ledger endpoints (single-sided balance changes)
+	// POST /api/v1/accounts/{id}/deposits - Credit an account
+	// POST /api/v1/accounts/{id}/withdrawals - Debit an account
+	s.router.HandleFunc("POST /api/v1/accounts/{id}/deposits", s.ledgerHandler.Deposit)
+	s.router.HandleFunc("POST /api/v1/accounts/{id}/withdrawals", s.ledgerHandler.Withdraw)
+
+	// Hold endpoints (funds reserved ahead of a capture)
+	// POST /api/v1/accounts/{id}/holds - Reserve part of an account's available balance
+	// POST /api/v1/holds/{id}/release - Give a hold's amount back
+	// POST /api/v1/holds/{id}/capture - Debit a hold's amount as a withdrawal
+	s.router.HandleFunc("POST /api/v1/accounts/{id}/holds", s.transactionHandler.PlaceHold)
+	s.router.HandleFunc("POST /api/v1/holds/{id}/release", s.transactionHandler.ReleaseHold)
+	s.router.HandleFunc("POST /api/v1/holds/{id}/capture", s.transactionHandler.CaptureHold)
 
 	// Transaction endpoints
 	// POST /api/v1/transactions - Create a money transfer
+	// POST /api/v1/transactions/batch - Atomic one-to-many transfer
+	// GET /api/v1/transactions/{id} - Get a single transaction
+	// POST /api/v1/transactions/{id}/reverse - Reverse a transfer
 	s.router.HandleFunc("POST /api/v1/transactions", s.transactionHandler.CreateTransaction)
+	s.router.HandleFunc("POST /api/v1/transactions/batch", s.transactionHandler.CreateBatchTransfer)
+	s.router.HandleFunc("GET /api/v1/transactions/{id}", s.transactionHandler.GetTransaction)
+	s.router.HandleFunc("POST /api/v1/transactions/{id}/reverse", s.transactionHandler.ReverseTransaction)
+
+	// Recurring transfer endpoints (runs are made by the scheduler)
+	// POST /api/v1/transfers/recurring - Schedule a repeating transfer
+	// GET /api/v1/transfers/recurring/{id} - Get a schedule and its latest run
+	// DELETE /api/v1/transfers/recurring/{id} - Cancel a schedule
+	s.router.HandleFunc("POST /api/v1/transfers/recurring", s.transactionHandler.CreateRecurringTransfer)
+	s.router.HandleFunc("GET /api/v1/transfers/recurring/{id}", s.transactionHandler.GetRecurringTransfer)
+	s.router.HandleFunc("DELETE /api/v1/transfers/recurring/{id}", s.transactionHandler.CancelRecurringTransfer)
+}
+
+// feePolicy builds the transfer fee policy from config. Load has already checked that
+// every amount parses.
+func feePolicy(cfg config.TransferConfig) service.FeePolicy {
+	amount := func(s string) decimal.Decimal {
+		d, _ := decimal.NewFromString(s)
+		return d
+	}
+	return service.FeePolicy{
+		AccountID: cfg.FeeAccountID,
+		Flat:      amount(cfg.FeeFlat),
+		Percent:   amount(cfg.FeePercent),
+		Min:       amount(cfg.FeeMin),
+		Max:       amount(cfg.FeeMax),
+		PaidBy:    models.FeePayer(cfg.FeePaidBy),
+	}
+}
+
+// blackoutSchedule builds the transfer blackout schedule from config. Load has already
+// checked that the time zone loads and every window parses.
+func blackoutSchedule(cfg config.TransferConfig) service.BlackoutSchedule {
+	loc, _ := time.LoadLocation(cfg.BlackoutTimezone)
+	schedule := service.BlackoutSchedule{Location: loc}
+	for _, s := range cfg.BlackoutWindows {
+		window, _ := service.ParseBlackoutWindow(s)
+		schedule.Windows = append(schedule.Windows, window)
+	}
+	return schedule
+}
+
+// newMetricsRecorder builds the sink selected by METRICS_BACKEND. It also returns the
+// Prometheus collectors to serve at /metrics (nil for other backends) and a function
+// releasing the sink (nil when there is nothing to release). An empty backend means
+// Prometheus. A StatsD agent whose address can't be resolved is logged and metrics are
+// dropped rather than failing startup.
+func newMetricsRecorder(cfg config.MetricsConfig) (metrics.Recorder, *metrics.Metrics, func() error) {
+	switch cfg.Backend {
+	case config.MetricsBackendNone:
+		return metrics.Nop{}, nil, nil
+	case config.MetricsBackendStatsD:
+		s, err := metrics.NewStatsD(cfg.StatsDAddr, cfg.StatsDPrefix)
+		if err != nil {
+			log.Error().Err(err).Msg("StatsD metrics disabled")
+			return metrics.Nop{}, nil, nil
+		}
+		log.Info().Str("addr", cfg.StatsDAddr).Str("prefix", cfg.StatsDPrefix).Msg("Pushing metrics to StatsD")
+		return s, nil, s.Close
+	default:
+		m := metrics.New()
+		return m, m, nil
+	}
+}
+
+// limitAccountCreate wraps an account creation route with the account creation rate
+// limit, when enabled. All wrapped routes share one set of per-client buckets.
+func (s *Server) limitAccountCreate(h http.Handler) http.Handler {
+	if s.accountCreateLimit == nil {
+		return h
+	}
+	return s.accountCreateLimit(h)
 }
 
 // Start begins listening for HTTP requests.
 func (s *Server) Start() error {
+	// Load the certificates first, so a bad file fails startup before anything runs
+	if s.tls.enabled() {
+		tlsConfig, err := s.tls.config()
+		if err != nil {
+			return fmt.Errorf("HTTP server TLS: %w", err)
+		}
+		s.httpServer.TLSConfig = tlsConfig
+	}
+
 	log.Info().
 		Str("address", s.httpServer.Addr).
+		Bool("tls", s.tls.enabled()).
+		Bool("clientCertRequired", s.tls.ClientCAFile != "").
 		Msg("Starting HTTP server")
 
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if s.routeMetrics != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopRouteMetrics = cancel
+		go s.routeMetrics.Run(ctx, s.routeMetricsInterval)
+		log.Info().Dur("interval", s.routeMetricsInterval).Msg("Route metrics rollup enabled")
+	}
+
+	if s.webhooks != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopWebhooks = cancel
+		go s.webhooks.Run(ctx)
+		log.Info().Msg("Transfer webhooks enabled")
+	}
+
+	if s.recurringInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopRecurring = cancel
+		s.recurringDone = make(chan struct{})
+		go func() {
+			defer close(s.recurringDone)
+			s.transferService.RunRecurring(ctx, s.recurringInterval)
+		}()
+		log.Info().Dur("interval", s.recurringInterval).Msg("Recurring transfer scheduler enabled")
+	}
+
+	if s.grpcServer != nil {
+		lis, err := net.Listen("tcp", s.grpcAddr)
+		if err != nil {
+			return fmt.Errorf("gRPC server error: %w", err)
+		}
+		log.Info().Str("address", s.grpcAddr).Msg("Starting gRPC server")
+		go func() {
+			if err := s.grpcServer.Serve(lis); err != nil {
+				log.Error().Err(err).Msg("gRPC server error")
+			}
+		}()
+	}
+
+	var err error
+	if s.tls.enabled() {
+		// The certificate is already in TLSConfig
+		err = s.httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = s.httpServer.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("HTTP server error: %w", err)
 	}
 
 	return nil
 }
 
-// Shutdown gracefully stops the HTTP server.
-func (s *Server) Shutdown(ctx context.Context) error {
+// Shutdown stops the recurring transfer scheduler, gracefully stops the gRPC and HTTP
+// servers, waits for in-flight transfers and the scheduler's current run to finish, and
+// then closes the database pools. Waiting is bounded by ctx; the pools are closed either
+// way, which itself blocks until connections still in use are released.
+func (s *Server) Shutdown(ctx context.Context) (err error) {
 	log.Info().Msg("Shutting down HTTP server...")
 
+	// Cancelled first so no scheduled transfer starts once draining begins
+	if s.stopRecurring != nil {
+		s.stopRecurring()
+	}
+
+	// Flush the final metrics interval once in-flight requests have drained
+	if s.stopRouteMetrics != nil {
+		defer s.stopRouteMetrics()
+	}
+	// Stopped once in-flight transfers have queued their events
+	if s.stopWebhooks != nil {
+		defer s.stopWebhooks()
+	}
+	if s.closePool != nil {
+		defer s.closePool()
+	}
+	if s.closeMetrics != nil {
+		defer s.closeMetrics()
+	}
+	// Deferred after closePool so the scheduler's run under way finishes before the pools
+	// close, whichever path Shutdown returns by
+	if s.recurringDone != nil {
+		defer func() {
+			if waitErr := s.waitRecurring(ctx); waitErr != nil && err == nil {
+				err = fmt.Errorf("waiting for the recurring transfer scheduler: %w", waitErr)
+			}
+		}()
+	}
+
+	if err := s.stopGRPC(ctx); err != nil {
+		return fmt.Errorf("gRPC shutdown error: %w", err)
+	}
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("server shutdown error: %w", err)
 	}
-
 	log.Info().Msg("HTTP server stopped")
+
+	if err := s.waitInFlight(ctx); err != nil {
+		return fmt.Errorf("waiting for in-flight transfers: %w", err)
+	}
 	return nil
 }
 
+// waitRecurring blocks until the recurring transfer scheduler's run under way has
+// returned or ctx is done.
+func (s *Server) waitRecurring(ctx context.Context) error {
+	select {
+	case <-s.recurringDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stopGRPC stops accepting gRPC calls and waits for those under way to finish. Calls
+// still running when ctx is done are cancelled.
+func (s *Server) stopGRPC(ctx context.Context) error {
+	if s.grpcServer == nil {
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		log.Info().Msg("gRPC server stopped")
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		<-stopped
+		return ctx.Err()
+	}
+}
+
+// waitInFlight blocks until every tracked transfer has finished or ctx is done.
+func (s *Server) waitInFlight(ctx context.Context) error {
+	if s.inFlight == nil {
+		return nil
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // GracefulShutdown waits for the given duration before forcing shutdown.
 func (s *Server) GracefulShutdown(timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/consistency"
+	"internal-transfers-system/internal/grpcserver/transferspb"
+	config "internal-transfers-system/pkg/config"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestNew_AccountCreateRateLimit(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.AccountCreateRateLimitEnabled = true
+	cfg.Server.AccountCreateRateLimitRPS = 0.001
+	cfg.Server.AccountCreateRateLimitBurst = 1
+
+	// Malformed bodies are rejected before any database access, so no pool is needed
+	srv := New(cfg, nil)
+	post := func(path string) int {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(`{`))
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := post("/api/v1/accounts"); code != http.StatusBadRequest {
+		t.Fatalf("first account create: expected 400, got %d", code)
+	}
+	if code := post("/api/v1/accounts"); code != http.StatusTooManyRequests {
+		t.Fatalf("second account create: expected 429, got %d", code)
+	}
+
+	// Transfers are not subject to the account creation limit
+	for i := 0; i < 3; i++ {
+		if code := post("/api/v1/transactions"); code != http.StatusBadRequest {
+			t.Fatalf("transfer %d: expected 400, got %d", i, code)
+		}
+	}
+}
+
+func TestNew_MetricsBackend(t *testing.T) {
+	tests := []struct {
+		backend string
+		want    int
+	}{
+		{"", http.StatusOK},
+		{config.MetricsBackendPrometheus, http.StatusOK},
+		{config.MetricsBackendStatsD, http.StatusNotFound},
+		{config.MetricsBackendNone, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Metrics.Backend = tt.backend
+			cfg.Metrics.StatsDAddr = "127.0.0.1:8125"
+
+			srv := New(cfg, nil)
+			defer func() {
+				if srv.closeMetrics != nil {
+					_ = srv.closeMetrics()
+				}
+			}()
+
+			// /metrics is only served when Prometheus is the backend
+			rec := httptest.NewRecorder()
+			srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			if rec.Code != tt.want {
+				t.Errorf("GET /metrics: expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestNew_MiddlewareChainKeepsRoute(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = orig }()
+
+	// The request timeout replaces the request's context inside logging and route metrics
+	cfg := &config.Config{}
+	cfg.Log.SampleRate = 1
+	cfg.Server.RequestTimeout = 10 * time.Second
+	cfg.Server.RouteMetricsEnabled = true
+	srv := NewInMemory(cfg)
+
+	srv.httpServer.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/accounts/42", nil))
+
+	const route = "GET /api/v1/accounts/{id}"
+	if got := srv.routeMetrics.routes[route]; got == nil || got.count != 1 {
+		t.Errorf("expected 1 request under %q, got %v", route, srv.routeMetrics.routes)
+	}
+	var logged bool
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if json.Unmarshal([]byte(line), &entry) != nil || entry["message"] != "HTTP request" {
+			continue
+		}
+		logged = true
+		if entry["route"] != route {
+			t.Errorf("expected route %q in request log, got %v", route, entry["route"])
+		}
+	}
+	if !logged {
+		t.Errorf("expected a request log line, got %s", buf.String())
+	}
+}
+
+func TestNew_MiddlewareChainKeepsRouteWithConsistencyToken(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = orig }()
+
+	cfg := &config.Config{}
+	cfg.Log.SampleRate = 1
+	srv := NewInMemory(cfg)
+
+	// The in-memory store has no WAL, so the chain is rebuilt around a fixed position
+	currentLSN := func(context.Context) (consistency.LSN, error) { return 0x16B3748, nil }
+	h := srv.middleware(cfg, nil, currentLSN)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/42", nil)
+	req.Header.Set(consistency.Header, "0/16B3748")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decode log line: %v (%s)", err, buf.String())
+	}
+	if entry["route"] != "GET /api/v1/accounts/{id}" {
+		t.Errorf("expected route pattern in request log, got %v", entry["route"])
+	}
+}
+
+func TestNew_MiddlewareChainFlushesExports(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.AdminToken = "secret"
+	cfg.Server.RouteMetricsEnabled = true
+	cfg.Server.RequestTimeout = 10 * time.Second
+	cfg.Pagination.MaxExport = 1
+	srv := NewInMemory(cfg)
+
+	// Every wrapper in the chain must let the export flush through to the connection
+	currentLSN := func(context.Context) (consistency.LSN, error) { return 0x16B3748, nil }
+	h := srv.middleware(cfg, nil, currentLSN)
+
+	create := httptest.NewRequest(http.MethodPost, "/api/v1/accounts", bytes.NewBufferString(`{"account_id": 1, "initial_balance": "10"}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, create)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create account: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts.ndjson", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !rec.Flushed {
+		t.Error("expected the export to be flushed through the middleware chain")
+	}
+}
+
+func TestNew_ExportOutlivesWriteTimeout(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.AdminToken = "secret"
+	cfg.Pagination.MaxExport = 100
+	srv := NewInMemory(cfg)
+	h := srv.middleware(cfg, nil, nil)
+
+	create := httptest.NewRequest(http.MethodPost, "/api/v1/accounts", bytes.NewBufferString(`{"account_id": 1, "initial_balance": "10"}`))
+	h.ServeHTTP(httptest.NewRecorder(), create)
+
+	// The export starts writing only after the server's write deadline has passed
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		h.ServeHTTP(w, r)
+	}))
+	ts.Config.WriteTimeout = 20 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/accounts.ndjson", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || !strings.Contains(string(body), `"account_id":1`) {
+		t.Errorf("expected the whole export, got %q (%v)", body, err)
+	}
+}
+
+func TestNew_AccountCreateRateLimitCoversGRPC(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.GRPCPort = 9090
+	cfg.Server.AccountCreateRateLimitEnabled = true
+	cfg.Server.AccountCreateRateLimitRPS = 0.001
+	cfg.Server.AccountCreateRateLimitBurst = 1
+	srv := NewInMemory(cfg)
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.grpcServer.Serve(lis)
+	defer srv.grpcServer.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := transferspb.NewTransfersClient(conn)
+
+	ctx := context.Background()
+	if _, err := client.CreateAccount(ctx, &transferspb.CreateAccountRequest{AccountId: 1, InitialBalance: "1"}); err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+	if _, err := client.CreateAccount(ctx, &transferspb.CreateAccountRequest{AccountId: 2, InitialBalance: "1"}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("second create: expected RESOURCE_EXHAUSTED, got %v", err)
+	}
+}
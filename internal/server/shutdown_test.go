@@ -0,0 +1,203 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/handler"
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// eventLog records shutdown milestones in the order they happen.
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) snapshot() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+type commitRecorder struct{ log *eventLog }
+
+func (c commitRecorder) PreCommit(context.Context, pgx.Tx, *models.Transaction) error { return nil }
+func (c commitRecorder) PostCommit(context.Context, *models.Transaction) {
+	c.log.add("transfer committed")
+}
+
+func TestShutdown_WaitsForInFlightTransfer(t *testing.T) {
+	events := &eventLog{}
+
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+
+	// The first row lock blocks until released, simulating a slow transfer
+	started, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	accRepo.OnGetByIDForUpdate = func(ctx context.Context, _ interface{}, id int64) (*models.Account, error) {
+		once.Do(func() {
+			close(started)
+			<-release
+		})
+		return accRepo.GetByID(ctx, id)
+	}
+
+	transferSvc := service.NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+	transferSvc.AddHook(commitRecorder{log: events})
+
+	opts := handler.DefaultOptions()
+	opts.InFlight = &sync.WaitGroup{}
+	txnHandler := handler.NewTransactionHandlerWithOptions(transferSvc, opts)
+
+	router := http.NewServeMux()
+	router.HandleFunc("POST /api/v1/transactions", txnHandler.CreateTransaction)
+	srv := &Server{
+		httpServer: &http.Server{Handler: router},
+		router:     router,
+		inFlight:   opts.InFlight,
+		closePool:  func() { events.add("pool closed") },
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go srv.httpServer.Serve(ln)
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Post("http://"+ln.Addr().String()+"/api/v1/transactions", "application/json",
+			bytes.NewBufferString(`{"source_account_id": 1, "destination_account_id": 2, "amount": "10"}`))
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- srv.GracefulShutdown(5 * time.Second) }()
+
+	// Shutdown must not close the pool while the transfer is still running
+	time.Sleep(50 * time.Millisecond)
+	if got := events.snapshot(); len(got) != 0 {
+		t.Fatalf("expected shutdown to wait for the transfer, got events %v", got)
+	}
+
+	close(release)
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if code := <-status; code != http.StatusCreated {
+		t.Errorf("expected in-flight transfer to succeed with 201, got %d", code)
+	}
+
+	got := events.snapshot()
+	if len(got) != 2 || got[0] != "transfer committed" || got[1] != "pool closed" {
+		t.Errorf("expected transfer to commit before the pool closes, got %v", got)
+	}
+	if acc, _ := accRepo.GetAccount(2); !acc.Balance.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected destination balance 10, got %s", acc.Balance)
+	}
+}
+
+func TestShutdown_InFlightWaitBoundedByTimeout(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	defer wg.Done()
+
+	closed := false
+	srv := &Server{
+		httpServer: &http.Server{},
+		inFlight:   wg,
+		closePool:  func() { closed = true },
+	}
+
+	err := srv.GracefulShutdown(20 * time.Millisecond)
+	if err == nil {
+		t.Fatal("expected a timeout error while work is still in flight")
+	}
+	if !closed {
+		t.Error("expected the pool to be closed even after the wait timed out")
+	}
+}
+
+func TestShutdown_StopsRecurringFirst(t *testing.T) {
+	// newServer simulates a scheduler whose run under way returns once cancelled
+	newServer := func(events *eventLog, inFlight *sync.WaitGroup) *Server {
+		ctx, cancel := context.WithCancel(context.Background())
+		srv := &Server{
+			httpServer:    &http.Server{},
+			inFlight:      inFlight,
+			closePool:     func() { events.add("pool closed") },
+			stopRecurring: func() { events.add("scheduler stopped"); cancel() },
+			recurringDone: make(chan struct{}),
+		}
+		go func() {
+			<-ctx.Done()
+			events.add("scheduled run finished")
+			close(srv.recurringDone)
+		}()
+		return srv
+	}
+
+	t.Run("before draining", func(t *testing.T) {
+		events := &eventLog{}
+		inFlight := &sync.WaitGroup{}
+		inFlight.Add(1)
+		srv := newServer(events, inFlight)
+
+		shutdownErr := make(chan error, 1)
+		go func() { shutdownErr <- srv.GracefulShutdown(5 * time.Second) }()
+
+		// The scheduler is stopped while requests are still draining
+		time.Sleep(50 * time.Millisecond)
+		if got := events.snapshot(); len(got) != 2 || got[0] != "scheduler stopped" {
+			t.Fatalf("expected the scheduler stopped before draining finishes, got %v", got)
+		}
+
+		inFlight.Done()
+		if err := <-shutdownErr; err != nil {
+			t.Fatalf("shutdown: %v", err)
+		}
+		if got := events.snapshot(); len(got) != 3 || got[2] != "pool closed" {
+			t.Errorf("expected the pool closed after the scheduled run, got %v", got)
+		}
+	})
+
+	t.Run("on an early error return", func(t *testing.T) {
+		events := &eventLog{}
+		inFlight := &sync.WaitGroup{}
+		inFlight.Add(1)
+		defer inFlight.Done()
+		srv := newServer(events, inFlight)
+
+		if err := srv.GracefulShutdown(20 * time.Millisecond); err == nil {
+			t.Fatal("expected a timeout error while work is still in flight")
+		}
+		if got := events.snapshot(); len(got) == 0 || got[0] != "scheduler stopped" || got[len(got)-1] != "pool closed" {
+			t.Errorf("expected the scheduler stopped before the pool closes, got %v", got)
+		}
+	})
+}
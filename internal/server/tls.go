@@ -0,0 +1,49 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// tlsFiles names the PEM files the HTTP server serves HTTPS with. CertFile and KeyFile
+// hold the server's certificate chain and private key; ClientCAFile, when set, holds the
+// CAs client certificates must be signed by, and makes a client certificate required.
+type tlsFiles struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// enabled reports whether HTTPS is configured.
+func (f tlsFiles) enabled() bool {
+	return f.CertFile != "" && f.KeyFile != ""
+}
+
+// config loads the files into a TLS configuration. TLS 1.2 is the minimum version.
+func (f tlsFiles) config() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading certificate: %w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if f.ClientCAFile == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(f.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("client CA file %s contains no PEM certificates", f.ClientCAFile)
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	return cfg, nil
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net/http"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts the root server span for each request, continuing the trace
+// from an incoming traceparent header when there is one. The span is renamed to the
+// matched route pattern once the router has run, and the request-scoped logger gains a
+// trace_id field so log lines can be joined to traces.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tracing.Propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Tracer().Start(ctx, r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("request_id", GetRequestID(ctx)),
+			),
+		)
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.IsValid() {
+			logger := logging.FromContext(ctx).With().Str("trace_id", sc.TraceID().String()).Logger()
+			ctx = logging.WithLogger(ctx, logger)
+		}
+
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		traced := r.WithContext(ctx)
+		next.ServeHTTP(wrapped, traced)
+
+		if traced.Pattern != "" {
+			span.SetName(traced.Pattern)
+			span.SetAttributes(attribute.String("http.route", traced.Pattern))
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", wrapped.statusCode))
+		if wrapped.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(wrapped.statusCode))
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"internal-transfers-system/internal/handler"
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+	"internal-transfers-system/internal/tracing"
+
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingMiddleware_TransferSpanHierarchy(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	orig := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(orig)
+
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	h := handler.NewTransactionHandler(service.NewTransferService(accRepo, mocks.NewMockTransactionRepository()))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/transactions", h.CreateTransaction)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions",
+		bytes.NewBufferString(`{"source_account_id": 1, "destination_account_id": 2, "amount": "25.50"}`))
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	TracingMiddleware(mux).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	hierarchy := []struct{ name, parent string }{
+		{"POST /api/v1/transactions", ""},
+		{"TransactionHandler.CreateTransaction", "POST /api/v1/transactions"},
+		{"TransferService.Transfer", "TransactionHandler.CreateTransaction"},
+		{"TransferService.executeTransfer", "TransferService.Transfer"},
+	}
+	for _, want := range hierarchy {
+		span, ok := spans[want.name]
+		if !ok {
+			t.Fatalf("missing span %q; got %d spans", want.name, len(spans))
+		}
+		if got := span.SpanContext().TraceID().String(); got != traceID {
+			t.Errorf("%s: expected trace %s from traceparent, got %s", want.name, traceID, got)
+		}
+		if want.parent == "" {
+			if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" || !span.Parent().IsRemote() {
+				t.Errorf("root span should continue the remote parent, got %s", got)
+			}
+			continue
+		}
+		if span.Parent().SpanID() != spans[want.parent].SpanContext().SpanID() {
+			t.Errorf("%s: expected parent %s", want.name, want.parent)
+		}
+	}
+
+	attrs := attribute.NewSet(spans["TransferService.executeTransfer"].Attributes()...)
+	if v, _ := attrs.Value(tracing.AttrSourceAccountID); v.AsInt64() != 1 {
+		t.Errorf("expected source account 1, got %v", v.Emit())
+	}
+	if v, _ := attrs.Value(tracing.AttrDestinationAccountID); v.AsInt64() != 2 {
+		t.Errorf("expected destination account 2, got %v", v.Emit())
+	}
+	if v, _ := attrs.Value(tracing.AttrAmount); v.AsString() != "25.5" {
+		t.Errorf("expected amount 25.5, got %v", v.Emit())
+	}
+}
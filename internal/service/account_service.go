@@ -2,67 +2,573 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 
 	"internal-transfers-system/internal/interfaces"
+	"internal-transfers-system/internal/logging"
 	"internal-transfers-system/internal/models"
 
-	"github.com/rs/zerolog/log"
+	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 )
 
 type AccountService struct {
 	accountRepo interfaces.AccountRepository
+	config      AccountServiceConfig
+}
+
+type AccountServiceConfig struct {
+	// InitialBalanceWarnThreshold makes CreateAccount attach a WarningLargeInitialBalance
+	// to accounts opened with a higher initial balance, to surface likely typos such as
+	// "1000000000" for "1000.00". Creation still succeeds. Zero disables the warning.
+	InitialBalanceWarnThreshold decimal.Decimal
 }
 
 func NewAccountService(accountRepo interfaces.AccountRepository) *AccountService {
-	return &AccountService{accountRepo: accountRepo}
+	return NewAccountServiceWithConfig(accountRepo, AccountServiceConfig{})
+}
+
+func NewAccountServiceWithConfig(accountRepo interfaces.AccountRepository, config AccountServiceConfig) *AccountService {
+	return &AccountService{accountRepo: accountRepo, config: config}
 }
 
 func (s *AccountService) CreateAccount(ctx context.Context, req *models.CreateAccountRequest) (*models.Account, error) {
-	balance, err := models.ParseMoney(req.InitialBalance)
+	account, err := newAccount(ctx, req)
 	if err != nil {
-		log.Debug().Err(err).Str("initialBalance", req.InitialBalance).Msg("Invalid initial balance format")
-		return nil, models.ErrInvalidAmount
+		return nil, err
 	}
 
-	if balance.LessThan(decimal.Zero) {
-		log.Debug().Str("initialBalance", req.InitialBalance).Msg("Initial balance cannot be negative")
-		return nil, models.ErrInvalidAmount
+	if req.AccountID == 0 {
+		if err := s.accountRepo.CreateWithGeneratedID(ctx, account); err != nil {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to create account with generated ID")
+			return nil, models.WrapError(models.CodeDatabaseError, "failed to create account", err)
+		}
+		return s.finishCreate(ctx, account), nil
 	}
 
 	exists, err := s.accountRepo.Exists(ctx, req.AccountID)
 	if err != nil {
-		log.Error().Err(err).Int64("accountID", req.AccountID).Msg("Failed to check account existence")
+		logging.FromContext(ctx).Error().Err(err).Int64("accountID", req.AccountID).Msg("Failed to check account existence")
 		return nil, models.WrapError(models.CodeDatabaseError, "failed to check account existence", err)
 	}
 	if exists {
-		log.Debug().Int64("accountID", req.AccountID).Msg("Account already exists")
+		if req.Idempotent {
+			return s.existingAccount(ctx, account)
+		}
+		logging.FromContext(ctx).Debug().Int64("accountID", req.AccountID).Msg("Account already exists")
 		return nil, models.ErrAccountAlreadyExists
 	}
 
-	account := &models.Account{
-		AccountID: req.AccountID,
-		Balance:   balance,
-	}
-
 	if err := s.accountRepo.Create(ctx, account); err != nil {
 		if isDuplicateKeyError(err) {
+			if req.Idempotent {
+				return s.existingAccount(ctx, account)
+			}
 			return nil, models.ErrAccountAlreadyExists
 		}
-		log.Error().Err(err).Int64("accountID", req.AccountID).Msg("Failed to create account")
+		logging.FromContext(ctx).Error().Err(err).Int64("accountID", req.AccountID).Msg("Failed to create account")
 		return nil, models.WrapError(models.CodeDatabaseError, "failed to create account", err)
 	}
 
-	log.Info().Int64("accountID", account.AccountID).Str("balance", account.Balance.String()).Msg("Account created successfully")
+	return s.finishCreate(ctx, account), nil
+}
 
-	return account, nil
+// existingAccount returns the stored account with want's ID, marked as Replayed, for an
+// idempotent create that found the ID taken. It fails with ErrAccountAlreadyExists if the
+// account was opened with a different initial balance, or can't be read yet. The account
+// is read in a transaction on the primary, since a replica may not have the create that
+// took the ID yet.
+func (s *AccountService) existingAccount(ctx context.Context, want *models.Account) (*models.Account, error) {
+	tx, err := s.accountRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, beginTxError(err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	existing, err := s.accountRepo.GetByIDInTx(ctx, tx, want.AccountID)
+	if errors.Is(err, models.ErrAccountNotFound) {
+		return nil, models.ErrAccountAlreadyExists
+	}
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to read existing account", err)
+	}
+
+	if !existing.InitialBalance.Equal(want.InitialBalance) {
+		logging.FromContext(ctx).Debug().
+			Int64("accountID", want.AccountID).
+			Str("initialBalance", want.InitialBalance.String()).
+			Str("existingInitialBalance", existing.InitialBalance.String()).
+			Msg("Idempotent create found the account with a different initial balance")
+		return nil, models.ErrAccountAlreadyExists
+	}
+
+	logging.FromContext(ctx).Info().Int64("accountID", want.AccountID).Msg("Returning existing account for idempotent create")
+	existing.Replayed = true
+	return existing, nil
+}
+
+// newAccount parses and checks req's amounts and returns the account to insert.
+func newAccount(ctx context.Context, req *models.CreateAccountRequest) (*models.Account, error) {
+	balance, err := models.ParseMoney(req.InitialBalance)
+	if err != nil {
+		logging.FromContext(ctx).Debug().Err(err).Str("initialBalance", req.InitialBalance).Msg("Invalid initial balance format")
+		return nil, models.InvalidAmountError("initial_balance", err)
+	}
+
+	if balance.LessThan(decimal.Zero) {
+		logging.FromContext(ctx).Debug().Str("initialBalance", req.InitialBalance).Msg("Initial balance cannot be negative")
+		return nil, models.ErrInvalidAmount
+	}
+
+	var maxBalance decimal.NullDecimal
+	if req.MaxBalance != "" {
+		maxBalance.Decimal, err = models.ParseMoney(req.MaxBalance)
+		if err != nil {
+			logging.FromContext(ctx).Debug().Err(err).Str("maxBalance", req.MaxBalance).Msg("Invalid max balance format")
+			return nil, models.InvalidAmountError("max_balance", err)
+		}
+		if maxBalance.Decimal.LessThan(balance) {
+			logging.FromContext(ctx).Debug().Str("maxBalance", req.MaxBalance).Str("initialBalance", req.InitialBalance).Msg("Invalid max balance")
+			return nil, models.ErrInvalidAmount
+		}
+		maxBalance.Valid = true
+	}
+
+	overdraftLimit := decimal.Zero
+	if req.OverdraftLimit != "" {
+		overdraftLimit, err = models.ParseMoney(req.OverdraftLimit)
+		if err != nil {
+			logging.FromContext(ctx).Debug().Err(err).Str("overdraftLimit", req.OverdraftLimit).Msg("Invalid overdraft limit format")
+			return nil, models.InvalidAmountError("overdraft_limit", err)
+		}
+		if overdraftLimit.IsNegative() {
+			logging.FromContext(ctx).Debug().Str("overdraftLimit", req.OverdraftLimit).Msg("Overdraft limit cannot be negative")
+			return nil, models.ErrInvalidAmount
+		}
+	}
+
+	return &models.Account{
+		AccountID:      req.AccountID,
+		AccountType:    req.AccountType,
+		Balance:        balance,
+		InitialBalance: balance,
+		MaxBalance:     maxBalance,
+		OverdraftLimit: overdraftLimit,
+	}, nil
+}
+
+// MaxAccountBatchSize caps how many accounts one bulk creation may contain.
+const MaxAccountBatchSize = 1000
+
+// CreateAccounts creates every account in reqs in one database transaction, with one
+// multi-row INSERT for the batch. Requests that fail their checks, reuse an account ID
+// from earlier in the batch, or name an existing account are reported in the result's
+// Failed list while the rest are created. With atomic set, the first such failure
+// instead aborts the whole batch and is returned as the error.
+func (s *AccountService) CreateAccounts(ctx context.Context, reqs []*models.CreateAccountRequest, atomic bool) (*models.AccountBatchResult, error) {
+	if len(reqs) == 0 || len(reqs) > MaxAccountBatchSize {
+		return nil, models.NewDomainError(models.CodeInvalidBatch,
+			fmt.Sprintf("account batch must contain between 1 and %d accounts", MaxAccountBatchSize))
+	}
+
+	result := &models.AccountBatchResult{}
+	reject := func(index int, accountID int64, err error) error {
+		if atomic {
+			var domainErr *models.DomainError
+			if errors.As(err, &domainErr) {
+				return models.WrapError(domainErr.Code, fmt.Sprintf("accounts[%d]: %s", index, domainErr.Message), domainErr.Cause)
+			}
+			return err
+		}
+		result.Failed = append(result.Failed, models.AccountBatchFailure{Index: index, AccountID: accountID, Err: err})
+		return nil
+	}
+
+	accounts := make([]*models.Account, 0, len(reqs))
+	indexes := make([]int, 0, len(reqs))
+	requested := make(map[int64]bool, len(reqs))
+	for i, req := range reqs {
+		account, err := newAccount(ctx, req)
+		if err == nil && req.AccountID != 0 && requested[req.AccountID] {
+			err = models.ErrAccountAlreadyExists
+		}
+		if err != nil {
+			if err := reject(i, req.AccountID, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		requested[req.AccountID] = true
+		accounts = append(accounts, account)
+		indexes = append(indexes, i)
+	}
+	if len(accounts) == 0 {
+		return result, nil
+	}
+
+	tx, err := s.accountRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, beginTxError(err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	created, err := s.accountRepo.CreateBatch(ctx, tx, accounts)
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to create accounts", err)
+	}
+	for k, account := range accounts {
+		if !created[k] {
+			if err := reject(indexes[k], account.AccountID, models.ErrAccountAlreadyExists); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		result.Created = append(result.Created, account)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to commit transaction", err)
+	}
+
+	for _, account := range result.Created {
+		s.finishCreate(ctx, account)
+	}
+	sort.Slice(result.Failed, func(i, j int) bool { return result.Failed[i].Index < result.Failed[j].Index })
+
+	logging.FromContext(ctx).Info().
+		Int("created", len(result.Created)).
+		Int("failed", len(result.Failed)).
+		Msg("Account batch created")
+
+	return result, nil
+}
+
+// finishCreate logs a newly inserted account and attaches any creation warnings.
+func (s *AccountService) finishCreate(ctx context.Context, account *models.Account) *models.Account {
+	logging.FromContext(ctx).Info().Int64("accountID", account.AccountID).Str("balance", account.Balance.String()).Msg("Account created successfully")
+
+	balance := account.Balance
+	if threshold := s.config.InitialBalanceWarnThreshold; threshold.IsPositive() && balance.GreaterThan(threshold) {
+		logging.FromContext(ctx).Warn().
+			Int64("accountID", account.AccountID).
+			Str("balance", balance.String()).
+			Str("threshold", threshold.String()).
+			Msg("Account created with unusually large initial balance")
+		account.Warnings = append(account.Warnings, models.Warning{
+			Code:    models.WarningLargeInitialBalance,
+			Field:   "initial_balance",
+			Message: fmt.Sprintf("initial_balance exceeds %s; check it was entered correctly", threshold),
+		})
+	}
+
+	return account
 }
 
 func (s *AccountService) GetAccount(ctx context.Context, accountID int64) (*models.Account, error) {
 	return s.accountRepo.GetByID(ctx, accountID)
 }
 
+// GetAccounts looks up several accounts at once. It returns the ones found and the IDs
+// of the ones that don't exist, each in the order first requested; repeated IDs are
+// looked up once. Callers cap len(ids).
+func (s *AccountService) GetAccounts(ctx context.Context, ids []int64) ([]*models.Account, []int64, error) {
+	unique := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	accounts, err := s.accountRepo.GetByIDs(ctx, unique)
+	if err != nil {
+		return nil, nil, models.WrapError(models.CodeDatabaseError, "failed to get accounts", err)
+	}
+	byID := make(map[int64]*models.Account, len(accounts))
+	for _, account := range accounts {
+		byID[account.AccountID] = account
+	}
+
+	found := make([]*models.Account, 0, len(accounts))
+	missing := []int64{}
+	for _, id := range unique {
+		if account, ok := byID[id]; ok {
+			found = append(found, account)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing, nil
+}
+
+// GetBalanceHistory returns the account's balance changes, newest first. limit and offset
+// are normalized like GetAccountTransactions'.
+func (s *AccountService) GetBalanceHistory(ctx context.Context, accountID int64, limit, offset int) ([]*models.BalanceChange, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	exists, err := s.accountRepo.Exists(ctx, accountID)
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to check account existence", err)
+	}
+	if !exists {
+		return nil, models.ErrAccountNotFound
+	}
+
+	changes, err := s.accountRepo.ListBalanceHistory(ctx, accountID, limit, offset)
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to list balance history", err)
+	}
+	return changes, nil
+}
+
+// ListAccounts returns a page of the accounts matching filter and how many match in
+// all. filter.Limit and filter.Offset are normalized like GetBalanceHistory's.
+func (s *AccountService) ListAccounts(ctx context.Context, filter models.AccountListFilter) ([]*models.Account, int64, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	accounts, total, err := s.accountRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, models.WrapError(models.CodeDatabaseError, "failed to list accounts", err)
+	}
+	return accounts, total, nil
+}
+
+const DefaultExportBatchSize = 500
+
+// StreamAccounts walks every account in account_id order, one keyset page at a time,
+// invoking fn for each. Iteration stops at the first error returned by fn or the repository.
+func (s *AccountService) StreamAccounts(ctx context.Context, batchSize int, fn func(*models.Account) error) error {
+	if batchSize <= 0 {
+		batchSize = DefaultExportBatchSize
+	}
+
+	var lastID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := s.accountRepo.ListAfter(ctx, lastID, batchSize)
+		if err != nil {
+			return models.WrapError(models.CodeDatabaseError, "failed to list accounts", err)
+		}
+
+		for _, account := range page {
+			if err := fn(account); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < batchSize {
+			return nil
+		}
+		lastID = page[len(page)-1].AccountID
+	}
+}
+
+// MaxAdjustmentBatchSize caps how many accounts a single bulk adjustment may touch,
+// bounding how many row locks one transaction holds.
+const MaxAdjustmentBatchSize = 1000
+
+// AdjustBalancesBatch applies every delta in one database transaction and records one
+// audit entry per account, all sharing a batch ID and reason. The batch is all or nothing:
+// if any account is missing or would go past its overdraft limit (or above its max balance)
+// nothing is applied.
+//
+// Accounts are locked in ascending ID order, the same order transfers use, so a batch
+// cannot deadlock with concurrent transfers. The returned adjustments are in that order too.
+func (s *AccountService) AdjustBalancesBatch(ctx context.Context, deltas []models.BalanceDelta, reason string) ([]*models.BalanceAdjustment, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(deltas) == 0 || len(deltas) > MaxAdjustmentBatchSize {
+		return nil, models.ErrInvalidAdjustment
+	}
+
+	sorted := append([]models.BalanceDelta(nil), deltas...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].AccountID < sorted[j].AccountID })
+	for i, d := range sorted {
+		if d.Delta.IsZero() || (i > 0 && d.AccountID == sorted[i-1].AccountID) {
+			return nil, models.ErrInvalidAdjustment
+		}
+	}
+
+	tx, err := s.accountRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, beginTxError(err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	// Lock and check every account before writing anything
+	newBalances := make([]decimal.Decimal, len(sorted))
+	for i, d := range sorted {
+		account, err := s.accountRepo.GetByIDForUpdate(ctx, tx, d.AccountID)
+		if err != nil {
+			return nil, err
+		}
+
+		newBalance, err := checkAdjustment(ctx, account, d.Delta)
+		if err != nil {
+			return nil, err
+		}
+		newBalances[i] = newBalance
+	}
+
+	adjustments, err := s.applyAdjustments(ctx, tx, sorted, newBalances, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to commit transaction", err)
+	}
+
+	logging.FromContext(ctx).Info().
+		Int64("batchID", adjustments[0].BatchID).
+		Int("accounts", len(adjustments)).
+		Str("reason", reason).
+		Msg("Balance adjustment batch applied")
+
+	return adjustments, nil
+}
+
+// checkAdjustment returns account's balance after adding delta, or a domain error if that
+// would take it past its overdraft limit, below its held balance, or above its max
+// balance. account must be locked in the caller's transaction.
+func checkAdjustment(ctx context.Context, account *models.Account, delta decimal.Decimal) (decimal.Decimal, error) {
+	newBalance := account.Balance.Add(delta)
+	if newBalance.Add(account.OverdraftLimit).IsNegative() {
+		logging.FromContext(ctx).Debug().
+			Int64("accountID", account.AccountID).
+			Str("balance", account.Balance.String()).
+			Str("overdraftLimit", account.OverdraftLimit.String()).
+			Str("delta", delta.String()).
+			Msg("Adjustment would exceed overdraft limit")
+		return decimal.Decimal{}, models.NewDomainError(models.CodeInsufficientBalance,
+			fmt.Sprintf("adjustment would take account %d past its overdraft limit", account.AccountID))
+	}
+	if newBalance.Add(account.OverdraftLimit).LessThan(account.HeldBalance) {
+		return decimal.Decimal{}, models.NewDomainError(models.CodeInsufficientBalance,
+			fmt.Sprintf("adjustment would take account %d below its held balance", account.AccountID))
+	}
+	if delta.IsPositive() && account.MaxBalance.Valid && newBalance.GreaterThan(account.MaxBalance.Decimal) {
+		return decimal.Decimal{}, models.NewDomainError(models.CodeDestBalanceLimit,
+			fmt.Sprintf("adjustment would exceed account %d maximum balance", account.AccountID))
+	}
+	return newBalance, nil
+}
+
+// applyAdjustments writes one adjustment batch within tx: each account in deltas gets
+// newBalances at the same index, an audit entry under a fresh batch ID, and a balance
+// history row. The accounts must already be locked and checked with checkAdjustment.
+func (s *AccountService) applyAdjustments(ctx context.Context, tx pgx.Tx, deltas []models.BalanceDelta, newBalances []decimal.Decimal, reason string) ([]*models.BalanceAdjustment, error) {
+	batchID, err := s.accountRepo.NextAdjustmentBatchID(ctx, tx)
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to allocate adjustment batch", err)
+	}
+
+	adjustments := make([]*models.BalanceAdjustment, len(deltas))
+	for i, d := range deltas {
+		if err := s.accountRepo.UpdateBalance(ctx, tx, d.AccountID, newBalances[i]); err != nil {
+			return nil, models.WrapError(models.CodeDatabaseError, "failed to update balance", err)
+		}
+
+		adjustments[i] = &models.BalanceAdjustment{
+			BatchID:      batchID,
+			AccountID:    d.AccountID,
+			Delta:        d.Delta,
+			BalanceAfter: newBalances[i],
+			Reason:       reason,
+		}
+		if err := s.accountRepo.CreateAdjustment(ctx, tx, adjustments[i]); err != nil {
+			return nil, models.WrapError(models.CodeDatabaseError, "failed to record adjustment", err)
+		}
+		if err := recordBalanceChanges(ctx, s.accountRepo, tx, &models.BalanceChange{
+			AccountID:    d.AccountID,
+			OldBalance:   newBalances[i].Sub(d.Delta),
+			NewBalance:   newBalances[i],
+			AdjustmentID: &adjustments[i].AdjustmentID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return adjustments, nil
+}
+
+// CloseAccount closes an account so it can no longer send or receive funds. The account
+// and its history stay readable. It fails with ErrAccountAlreadyClosed if the account is
+// already closed, and with ErrAccountNotEmpty if it still holds a balance unless force is
+// set, in which case the remaining balance is frozen in the closed account.
+func (s *AccountService) CloseAccount(ctx context.Context, accountID int64, force bool) (*models.Account, error) {
+	tx, err := s.accountRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, beginTxError(err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	// Checked under the row lock, so a concurrent transfer either lands before the
+	// balance check or sees the account closed
+	account, err := s.accountRepo.GetByIDForUpdate(ctx, tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.IsClosed() {
+		return nil, models.ErrAccountAlreadyClosed
+	}
+	if !account.Balance.IsZero() && !force {
+		logging.FromContext(ctx).Debug().
+			Int64("accountID", accountID).
+			Str("balance", account.Balance.String()).
+			Msg("Refusing to close account with a balance")
+		return nil, models.ErrAccountNotEmpty
+	}
+
+	closedAt, err := s.accountRepo.Close(ctx, tx, accountID)
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to close account", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to commit transaction", err)
+	}
+	account.ClosedAt = &closedAt
+
+	logging.FromContext(ctx).Info().
+		Int64("accountID", accountID).
+		Str("balance", account.Balance.String()).
+		Bool("force", force).
+		Msg("Account closed")
+
+	return account, nil
+}
+
 func isDuplicateKeyError(err error) bool {
 	if err == nil {
 		return false
@@ -70,3 +576,12 @@ func isDuplicateKeyError(err error) bool {
 	errStr := err.Error()
 	return strings.Contains(errStr, "duplicate key") || strings.Contains(errStr, "23505")
 }
+
+// beginTxError reports a failed BeginTx. ErrServiceBusy is returned as it is, so the
+// client is told to back off and try again; anything else is a database error.
+func beginTxError(err error) error {
+	if errors.Is(err, models.ErrServiceBusy) {
+		return models.ErrServiceBusy
+	}
+	return models.WrapError(models.CodeDatabaseError, "failed to begin transaction", err)
+}
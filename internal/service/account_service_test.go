@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"internal-transfers-system/internal/mocks"
@@ -37,6 +38,12 @@ func TestAccountService_CreateAccount(t *testing.T) {
 			setup:   func(*mocks.MockAccountRepository) {},
 			wantErr: models.ErrInvalidAmount,
 		},
+		{
+			name:    "too many decimal places",
+			request: &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100.001"},
+			setup:   func(*mocks.MockAccountRepository) {},
+			wantErr: models.ErrInvalidAmount,
+		},
 		{
 			name:    "negative balance",
 			request: &models.CreateAccountRequest{AccountID: 1, InitialBalance: "-100"},
@@ -77,6 +84,280 @@ func TestAccountService_CreateAccount(t *testing.T) {
 	}
 }
 
+func TestAccountService_CreateAccount_GeneratedID(t *testing.T) {
+	repo := mocks.NewMockAccountRepository()
+	svc := NewAccountService(repo)
+	ctx := context.Background()
+
+	// Client-supplied IDs keep working alongside generated ones, which skip taken IDs
+	if _, err := svc.CreateAccount(ctx, &models.CreateAccountRequest{AccountID: 2, InitialBalance: "10"}); err != nil {
+		t.Fatalf("create with ID: %v", err)
+	}
+
+	var ids []int64
+	for i := 0; i < 2; i++ {
+		acc, err := svc.CreateAccount(ctx, &models.CreateAccountRequest{InitialBalance: "100"})
+		if err != nil {
+			t.Fatalf("create without ID: %v", err)
+		}
+		ids = append(ids, acc.AccountID)
+	}
+	if ids[0] != 1 || ids[1] != 3 {
+		t.Errorf("expected generated IDs [1 3], got %v", ids)
+	}
+	if acc, ok := repo.GetAccount(3); !ok || acc.Balance.String() != "100" {
+		t.Errorf("expected generated account 3 with balance 100, got %+v", acc)
+	}
+}
+
+func TestAccountService_CreateAccount_Idempotent(t *testing.T) {
+	repo := mocks.NewMockAccountRepository()
+	svc := NewAccountService(repo)
+	ctx := context.Background()
+
+	created, err := svc.CreateAccount(ctx, &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100.00", Idempotent: true})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.Replayed {
+		t.Error("expected a newly created account not to be marked replayed")
+	}
+
+	// The balance has moved since, but the retry is matched on the opening balance
+	repo.UpdateBalance(ctx, nil, 1, decimal.NewFromInt(40))
+
+	again, err := svc.CreateAccount(ctx, &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100", Idempotent: true})
+	if err != nil {
+		t.Fatalf("idempotent repeat: %v", err)
+	}
+	if !again.Replayed || again.AccountID != 1 || !again.Balance.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("expected the existing account marked replayed, got %+v", again)
+	}
+
+	if _, err := svc.CreateAccount(ctx, &models.CreateAccountRequest{AccountID: 1, InitialBalance: "99", Idempotent: true}); !errors.Is(err, models.ErrAccountAlreadyExists) {
+		t.Errorf("expected ErrAccountAlreadyExists for a different initial balance, got %v", err)
+	}
+	if _, err := svc.CreateAccount(ctx, &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100"}); !errors.Is(err, models.ErrAccountAlreadyExists) {
+		t.Errorf("expected ErrAccountAlreadyExists without idempotent, got %v", err)
+	}
+}
+
+// laggingReplicaRepo misses accounts on lookups a replica may serve, like a replica that
+// hasn't replayed a create the primary has committed.
+type laggingReplicaRepo struct {
+	*mocks.MockAccountRepository
+}
+
+func (laggingReplicaRepo) Exists(context.Context, int64) (bool, error) { return false, nil }
+
+func (laggingReplicaRepo) GetByID(context.Context, int64) (*models.Account, error) {
+	return nil, models.ErrAccountNotFound
+}
+
+func (laggingReplicaRepo) Create(context.Context, *models.Account) error {
+	return errors.New(`duplicate key value violates unique constraint "accounts_pkey" (SQLSTATE 23505)`)
+}
+
+func TestAccountService_CreateAccount_IdempotentReadsPrimary(t *testing.T) {
+	repo := mocks.NewMockAccountRepository()
+	repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100), InitialBalance: decimal.NewFromInt(100)})
+	svc := NewAccountService(laggingReplicaRepo{repo})
+
+	// A retry of a create that committed finds the account although the replica lags
+	again, err := svc.CreateAccount(context.Background(), &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100", Idempotent: true})
+	if err != nil {
+		t.Fatalf("idempotent retry: %v", err)
+	}
+	if !again.Replayed || again.AccountID != 1 {
+		t.Errorf("expected the existing account marked replayed, got %+v", again)
+	}
+}
+
+func TestAccountService_CreateAccounts(t *testing.T) {
+	ctx := context.Background()
+	batch := func() []*models.CreateAccountRequest {
+		return []*models.CreateAccountRequest{
+			{AccountID: 10, InitialBalance: "100"},
+			{InitialBalance: "5"},
+			{AccountID: 1, InitialBalance: "1"},   // already exists
+			{AccountID: 11, InitialBalance: "-1"}, // invalid amount
+			{AccountID: 10, InitialBalance: "2"},  // repeats index 0
+			{AccountID: 12, InitialBalance: "0.5"},
+		}
+	}
+
+	t.Run("partial", func(t *testing.T) {
+		repo := mocks.NewMockAccountRepository()
+		repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1)})
+		svc := NewAccountService(repo)
+
+		result, err := svc.CreateAccounts(ctx, batch(), false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var created []int64
+		for _, acc := range result.Created {
+			created = append(created, acc.AccountID)
+		}
+		if len(created) != 3 || created[0] != 10 || created[1] != 2 || created[2] != 12 {
+			t.Errorf("expected accounts [10 2 12] created, got %v", created)
+		}
+		want := map[int]error{2: models.ErrAccountAlreadyExists, 3: models.ErrInvalidAmount, 4: models.ErrAccountAlreadyExists}
+		if len(result.Failed) != len(want) {
+			t.Fatalf("expected %d failures, got %+v", len(want), result.Failed)
+		}
+		for _, f := range result.Failed {
+			if !errors.Is(f.Err, want[f.Index]) {
+				t.Errorf("index %d: expected %v, got %v", f.Index, want[f.Index], f.Err)
+			}
+		}
+		if acc, ok := repo.GetAccount(10); !ok || acc.Balance.String() != "100" {
+			t.Errorf("expected account 10 with balance 100, got %+v", acc)
+		}
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		repo := mocks.NewMockAccountRepository()
+		repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1)})
+		svc := NewAccountService(repo)
+
+		// Invalid requests are caught before anything is written
+		_, err := svc.CreateAccounts(ctx, batch(), true)
+		if !errors.Is(err, models.ErrInvalidAmount) || !strings.Contains(err.Error(), "accounts[3]") {
+			t.Errorf("expected ErrInvalidAmount for accounts[3], got %v", err)
+		}
+		if _, ok := repo.GetAccount(10); ok {
+			t.Error("expected no account to be created")
+		}
+
+		reqs := batch()
+		_, err = svc.CreateAccounts(ctx, []*models.CreateAccountRequest{reqs[0], reqs[2]}, true)
+		if !errors.Is(err, models.ErrAccountAlreadyExists) || !strings.Contains(err.Error(), "accounts[1]") {
+			t.Errorf("expected ErrAccountAlreadyExists for accounts[1], got %v", err)
+		}
+
+		repo = mocks.NewMockAccountRepository()
+		svc = NewAccountService(repo)
+		result, err := svc.CreateAccounts(ctx, []*models.CreateAccountRequest{reqs[0], reqs[1], reqs[5]}, true)
+		if err != nil || len(result.Created) != 3 || len(result.Failed) != 0 {
+			t.Errorf("expected all three accounts created, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("batch size", func(t *testing.T) {
+		svc := NewAccountService(mocks.NewMockAccountRepository())
+		if _, err := svc.CreateAccounts(ctx, nil, false); !errors.Is(err, models.NewDomainError(models.CodeInvalidBatch, "")) {
+			t.Errorf("expected an invalid batch error, got %v", err)
+		}
+	})
+}
+
+func TestAccountService_CreateAccount_OverdraftLimit(t *testing.T) {
+	repo := mocks.NewMockAccountRepository()
+	svc := NewAccountService(repo)
+	ctx := context.Background()
+
+	acc, err := svc.CreateAccount(ctx, &models.CreateAccountRequest{AccountID: 1, InitialBalance: "10", OverdraftLimit: "90"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if acc.OverdraftLimit.String() != "90" || acc.AvailableBalance().String() != "100" {
+		t.Errorf("expected overdraft limit 90 and 100 available, got %s and %s", acc.OverdraftLimit, acc.AvailableBalance())
+	}
+	if stored, _ := repo.GetAccount(1); stored.OverdraftLimit.String() != "90" {
+		t.Errorf("expected the overdraft limit to be stored, got %s", stored.OverdraftLimit)
+	}
+
+	_, err = svc.CreateAccount(ctx, &models.CreateAccountRequest{AccountID: 2, InitialBalance: "10", OverdraftLimit: "-1"})
+	if !errors.Is(err, models.ErrInvalidAmount) {
+		t.Errorf("expected ErrInvalidAmount for a negative limit, got %v", err)
+	}
+}
+
+func TestAccountService_CreateAccount_LargeInitialBalanceWarning(t *testing.T) {
+	tests := []struct {
+		name         string
+		threshold    string
+		balance      string
+		wantWarnings int
+	}{
+		{"disabled", "0", "1000000000", 0},
+		{"below threshold", "1000000", "999999.99", 0},
+		{"at threshold", "1000000", "1000000", 0},
+		{"above threshold", "1000000", "1000000000", 1},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewAccountServiceWithConfig(mocks.NewMockAccountRepository(), AccountServiceConfig{
+				InitialBalanceWarnThreshold: decimal.RequireFromString(tt.threshold),
+			})
+			acc, err := svc.CreateAccount(context.Background(), &models.CreateAccountRequest{
+				AccountID: int64(i + 1), InitialBalance: tt.balance,
+			})
+			if err != nil {
+				t.Fatalf("create should succeed despite warnings: %v", err)
+			}
+			if len(acc.Warnings) != tt.wantWarnings {
+				t.Fatalf("expected %d warnings, got %+v", tt.wantWarnings, acc.Warnings)
+			}
+			if tt.wantWarnings > 0 && (acc.Warnings[0].Code != models.WarningLargeInitialBalance || acc.Warnings[0].Field != "initial_balance") {
+				t.Errorf("unexpected warning: %+v", acc.Warnings[0])
+			}
+		})
+	}
+}
+
+func TestAccountService_CloseAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance int64
+		force   bool
+		wantErr error
+	}{
+		{"empty account", 0, false, nil},
+		{"non-zero balance", 50, false, models.ErrAccountNotEmpty},
+		{"non-zero balance forced", 50, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockAccountRepository()
+			repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(tt.balance)})
+			svc := NewAccountService(repo)
+
+			acc, err := svc.CloseAccount(context.Background(), 1, tt.force)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if stored, _ := repo.GetAccount(1); stored.IsClosed() {
+					t.Error("expected the account to stay open")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected: %v", err)
+			}
+			if !acc.IsClosed() || acc.Status() != models.AccountStatusClosed || acc.Balance.IntPart() != tt.balance {
+				t.Errorf("unexpected closed account: %+v", acc)
+			}
+
+			// Still readable, and can't be closed twice
+			if got, err := svc.GetAccount(context.Background(), 1); err != nil || !got.IsClosed() {
+				t.Errorf("expected GetAccount to return the closed account, got %+v, %v", got, err)
+			}
+			if _, err := svc.CloseAccount(context.Background(), 1, true); !errors.Is(err, models.ErrAccountAlreadyClosed) {
+				t.Errorf("expected ErrAccountAlreadyClosed, got %v", err)
+			}
+		})
+	}
+
+	if _, err := NewAccountService(mocks.NewMockAccountRepository()).CloseAccount(context.Background(), 9, false); !errors.Is(err, models.ErrAccountNotFound) {
+		t.Errorf("expected ErrAccountNotFound, got %v", err)
+	}
+}
+
 func TestAccountService_GetAccount(t *testing.T) {
 	repo := mocks.NewMockAccountRepository()
 	repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
@@ -95,3 +376,236 @@ func TestAccountService_GetAccount(t *testing.T) {
 		t.Errorf("expected ErrAccountNotFound, got %v", err)
 	}
 }
+
+func TestAccountService_StreamAccounts(t *testing.T) {
+	repo := mocks.NewMockAccountRepository()
+	for id := int64(1); id <= 7; id++ {
+		repo.SetAccount(&models.Account{AccountID: id, Balance: decimal.NewFromInt(id * 10)})
+	}
+
+	svc := NewAccountService(repo)
+
+	var seen []int64
+	err := svc.StreamAccounts(context.Background(), 3, func(acc *models.Account) error {
+		seen = append(seen, acc.AccountID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if len(seen) != 7 {
+		t.Fatalf("expected 7 accounts, got %d", len(seen))
+	}
+	for i, id := range seen {
+		if id != int64(i+1) {
+			t.Errorf("expected ascending order, got %v", seen)
+			break
+		}
+	}
+
+	// Callback error stops iteration
+	stop := errors.New("stop")
+	count := 0
+	err = svc.StreamAccounts(context.Background(), 3, func(*models.Account) error {
+		count++
+		if count == 4 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || count != 4 {
+		t.Errorf("expected stop after 4, got count=%d err=%v", count, err)
+	}
+
+	// Repository error
+	repo.ListAfterError = errors.New("db down")
+	err = svc.StreamAccounts(context.Background(), 3, func(*models.Account) error { return nil })
+	if code, ok := models.IsDomainError(err); !ok || code != models.CodeDatabaseError {
+		t.Errorf("expected database error, got %v", err)
+	}
+}
+
+func TestAccountService_AdjustBalancesBatch(t *testing.T) {
+	newRepo := func() *mocks.MockAccountRepository {
+		repo := mocks.NewMockAccountRepository()
+		repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+		repo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(50)})
+		repo.SetAccount(&models.Account{AccountID: 3, Balance: decimal.NewFromInt(10), MaxBalance: decimal.NewNullDecimal(decimal.NewFromInt(20))})
+		return repo
+	}
+
+	t.Run("applies all deltas with one shared batch", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewAccountService(repo)
+
+		adjustments, err := svc.AdjustBalancesBatch(context.Background(), []models.BalanceDelta{
+			{AccountID: 2, Delta: decimal.NewFromInt(-50)},
+			{AccountID: 1, Delta: decimal.RequireFromString("12.5")},
+		}, " correction ")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(adjustments) != 2 || adjustments[0].AccountID != 1 || adjustments[1].AccountID != 2 {
+			t.Fatalf("expected adjustments ordered by account ID, got %+v", adjustments)
+		}
+		if adjustments[0].BatchID != adjustments[1].BatchID {
+			t.Error("expected a shared batch ID")
+		}
+		if acc, _ := repo.GetAccount(1); acc.Balance.String() != "112.5" {
+			t.Errorf("expected 112.5, got %s", acc.Balance)
+		}
+		if acc, _ := repo.GetAccount(2); !acc.Balance.IsZero() {
+			t.Errorf("expected 0, got %s", acc.Balance)
+		}
+
+		audit := repo.Adjustments()
+		if len(audit) != 2 {
+			t.Fatalf("expected 2 audit entries, got %d", len(audit))
+		}
+		for _, a := range audit {
+			if a.Reason != "correction" || a.BatchID != adjustments[0].BatchID {
+				t.Errorf("unexpected audit entry %+v", a)
+			}
+		}
+	})
+
+	rejected := []struct {
+		name    string
+		deltas  []models.BalanceDelta
+		reason  string
+		wantErr error
+	}{
+		{"negative balance", []models.BalanceDelta{{AccountID: 1, Delta: decimal.NewFromInt(5)}, {AccountID: 2, Delta: decimal.NewFromInt(-51)}}, "fix", models.ErrInsufficientBalance},
+		{"above max balance", []models.BalanceDelta{{AccountID: 1, Delta: decimal.NewFromInt(5)}, {AccountID: 3, Delta: decimal.NewFromInt(11)}}, "fix", models.ErrDestinationBalanceLimit},
+		{"missing account", []models.BalanceDelta{{AccountID: 1, Delta: decimal.NewFromInt(5)}, {AccountID: 9, Delta: decimal.NewFromInt(1)}}, "fix", models.ErrAccountNotFound},
+		{"missing reason", []models.BalanceDelta{{AccountID: 1, Delta: decimal.NewFromInt(5)}}, "  ", models.ErrInvalidAdjustment},
+		{"empty batch", nil, "fix", models.ErrInvalidAdjustment},
+		{"zero delta", []models.BalanceDelta{{AccountID: 1, Delta: decimal.Zero}}, "fix", models.ErrInvalidAdjustment},
+		{"duplicate account", []models.BalanceDelta{{AccountID: 1, Delta: decimal.NewFromInt(1)}, {AccountID: 1, Delta: decimal.NewFromInt(2)}}, "fix", models.ErrInvalidAdjustment},
+	}
+
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo()
+			svc := NewAccountService(repo)
+
+			_, err := svc.AdjustBalancesBatch(context.Background(), tt.deltas, tt.reason)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			// Nothing in the batch may have been applied
+			if acc, _ := repo.GetAccount(1); !acc.Balance.Equal(decimal.NewFromInt(100)) {
+				t.Errorf("account 1 changed to %s", acc.Balance)
+			}
+			if len(repo.Adjustments()) != 0 {
+				t.Error("expected no audit entries")
+			}
+		})
+	}
+}
+
+func TestAccountService_Reconcile(t *testing.T) {
+	newRepo := func() *mocks.MockAccountRepository {
+		repo := mocks.NewMockAccountRepository()
+		repo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+		repo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(50)})
+		repo.SetAccount(&models.Account{AccountID: 3, Balance: decimal.NewFromInt(10), HeldBalance: decimal.NewFromInt(8)})
+		return repo
+	}
+	expected := []models.ExpectedBalance{
+		{AccountID: 2, Balance: decimal.NewFromInt(50)},
+		{AccountID: 9, Balance: decimal.NewFromInt(5)},
+		{AccountID: 1, Balance: decimal.RequireFromString("112.5")},
+	}
+
+	t.Run("detect only reports without writing", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewAccountService(repo)
+
+		report, err := svc.Reconcile(context.Background(), expected, false, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []models.ReconciliationStatus{models.ReconciliationMismatched, models.ReconciliationMatched, models.ReconciliationMissing}
+		for i, item := range report.Items {
+			if item.Status != want[i] {
+				t.Errorf("item %d: expected %s, got %s", i, want[i], item.Status)
+			}
+		}
+		if d := report.Items[0].Difference(); d.String() != "12.5" {
+			t.Errorf("expected difference 12.5, got %s", d)
+		}
+		if report.BatchID != 0 || report.Reason != DefaultReconciliationReason {
+			t.Errorf("unexpected report %+v", report)
+		}
+		if acc, _ := repo.GetAccount(1); !acc.Balance.Equal(decimal.NewFromInt(100)) {
+			t.Errorf("account 1 changed to %s", acc.Balance)
+		}
+		if len(repo.Adjustments()) != 0 {
+			t.Error("expected no audit entries")
+		}
+	})
+
+	t.Run("apply corrects mismatches and is idempotent", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewAccountService(repo)
+
+		report, err := svc.Reconcile(context.Background(), expected, true, "month-end")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		item := report.Items[0]
+		if item.Status != models.ReconciliationCorrected || item.Adjustment == nil || item.Adjustment.BatchID != report.BatchID {
+			t.Fatalf("expected account 1 corrected in the report's batch, got %+v", item)
+		}
+		if acc, _ := repo.GetAccount(1); acc.Balance.String() != "112.5" {
+			t.Errorf("expected 112.5, got %s", acc.Balance)
+		}
+		audit := repo.Adjustments()
+		if len(audit) != 1 || audit[0].Reason != "month-end" || audit[0].Delta.String() != "12.5" {
+			t.Fatalf("unexpected audit entries %+v", audit)
+		}
+
+		again, err := svc.Reconcile(context.Background(), expected, true, "month-end")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if again.Count(models.ReconciliationCorrected) != 0 || again.BatchID != 0 || len(repo.Adjustments()) != 1 {
+			t.Errorf("expected a repeated import to change nothing, got %+v", again)
+		}
+	})
+
+	rejected := []struct {
+		name     string
+		expected []models.ExpectedBalance
+		wantErr  error
+	}{
+		{"below held balance", []models.ExpectedBalance{{AccountID: 1, Balance: decimal.NewFromInt(90)}, {AccountID: 3, Balance: decimal.NewFromInt(5)}}, models.ErrInsufficientBalance},
+		{"empty import", nil, models.ErrInvalidAdjustment},
+		{"duplicate account", []models.ExpectedBalance{{AccountID: 1, Balance: decimal.NewFromInt(1)}, {AccountID: 1, Balance: decimal.NewFromInt(2)}}, models.ErrInvalidAdjustment},
+	}
+
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo()
+			svc := NewAccountService(repo)
+
+			_, err := svc.Reconcile(context.Background(), tt.expected, true, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			// No correction may have been applied
+			if acc, _ := repo.GetAccount(1); !acc.Balance.Equal(decimal.NewFromInt(100)) {
+				t.Errorf("account 1 changed to %s", acc.Balance)
+			}
+			if len(repo.Adjustments()) != 0 {
+				t.Error("expected no audit entries")
+			}
+		})
+	}
+}
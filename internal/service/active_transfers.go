@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// ActiveTransfer is a transfer the service is still executing, retries included.
+type ActiveTransfer struct {
+	// OperationID identifies the transfer while it runs. It is unique within the process
+	// and unrelated to the transaction ID, which doesn't exist until the insert.
+	OperationID          string
+	SourceAccountID      int64
+	DestinationAccountID int64
+	Amount               decimal.Decimal
+	StartedAt            time.Time
+}
+
+// activeTransfers is the registry behind ActiveTransfers. The zero value is ready to use.
+type activeTransfers struct {
+	mu        sync.Mutex
+	nextID    uint64
+	transfers map[string]ActiveTransfer
+}
+
+// trackActive registers draft as running until the returned func is called. When
+// StuckTransferThreshold is set and the transfer is still running once it passes, a
+// warning is logged with the request's fields, so a transfer hung on a lock shows up
+// before the client times out.
+func (s *TransferService) trackActive(ctx context.Context, draft *models.Transaction) (done func()) {
+	r := &s.active
+	r.mu.Lock()
+	r.nextID++
+	t := ActiveTransfer{
+		OperationID:          strconv.FormatUint(r.nextID, 10),
+		SourceAccountID:      draft.SourceAccountID,
+		DestinationAccountID: draft.DestinationAccountID,
+		Amount:               draft.Amount,
+		StartedAt:            time.Now(),
+	}
+	if r.transfers == nil {
+		r.transfers = make(map[string]ActiveTransfer)
+	}
+	r.transfers[t.OperationID] = t
+	r.mu.Unlock()
+
+	var stuck *time.Timer
+	if threshold := s.config.StuckTransferThreshold; threshold > 0 {
+		stuck = time.AfterFunc(threshold, func() {
+			logging.FromContext(ctx).Warn().
+				Str("operationID", t.OperationID).
+				Int64("sourceAccountID", t.SourceAccountID).
+				Int64("destAccountID", t.DestinationAccountID).
+				Str("amount", t.Amount.String()).
+				Dur("age", time.Since(t.StartedAt)).
+				Msg("Transfer still running past the stuck threshold")
+		})
+	}
+
+	return func() {
+		if stuck != nil {
+			stuck.Stop()
+		}
+		r.mu.Lock()
+		delete(r.transfers, t.OperationID)
+		r.mu.Unlock()
+	}
+}
+
+// ActiveTransfers returns how many transfers are executing right now and the oldest of
+// them, or nil when there are none.
+func (s *TransferService) ActiveTransfers() (int, *ActiveTransfer) {
+	r := &s.active
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var oldest *ActiveTransfer
+	for _, t := range r.transfers {
+		if oldest == nil || t.StartedAt.Before(oldest.StartedAt) {
+			t := t
+			oldest = &t
+		}
+	}
+	return len(r.transfers), oldest
+}
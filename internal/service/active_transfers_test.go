@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+)
+
+// blockingHook holds every transfer inside its database transaction until release is
+// closed, signalling entered once it is there.
+type blockingHook struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHook) PreCommit(context.Context, pgx.Tx, *models.Transaction) error {
+	h.entered <- struct{}{}
+	<-h.release
+	return nil
+}
+
+func (h *blockingHook) PostCommit(context.Context, *models.Transaction) {}
+
+// chanWriter hands each log line to a channel, so a test can wait for one.
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestTransferService_ActiveTransfers(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+	config := DefaultTransferConfig()
+	config.StuckTransferThreshold = 10 * time.Millisecond
+	svc := NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), config)
+	hook := &blockingHook{entered: make(chan struct{}), release: make(chan struct{})}
+	svc.AddHook(hook)
+
+	if count, oldest := svc.ActiveTransfers(); count != 0 || oldest != nil {
+		t.Fatalf("expected no active transfers, got %d and %+v", count, oldest)
+	}
+
+	logs := make(chanWriter, 10)
+	ctx := logging.WithLogger(context.Background(), zerolog.New(logs))
+	done := make(chan error)
+	go func() {
+		_, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "25"})
+		done <- err
+	}()
+	<-hook.entered
+
+	count, oldest := svc.ActiveTransfers()
+	if count != 1 || oldest == nil {
+		t.Fatalf("expected one active transfer, got %d and %+v", count, oldest)
+	}
+	if oldest.OperationID == "" || oldest.SourceAccountID != 1 || oldest.DestinationAccountID != 2 || oldest.Amount.String() != "25" {
+		t.Errorf("unexpected active transfer %+v", oldest)
+	}
+
+	select {
+	case line := <-logs:
+		if !strings.Contains(line, `"level":"warn"`) || !strings.Contains(line, `"operationID":"`+oldest.OperationID+`"`) {
+			t.Errorf("expected a stuck transfer warning, got %s", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a warning once the transfer passed the stuck threshold")
+	}
+
+	close(hook.release)
+	if err := <-done; err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if count, oldest := svc.ActiveTransfers(); count != 0 || oldest != nil {
+		t.Errorf("expected the finished transfer to be removed, got %d and %+v", count, oldest)
+	}
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"internal-transfers-system/internal/interfaces"
+	"internal-transfers-system/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// recordBalanceChanges appends changes to the balance history within tx. Every service
+// that updates a balance calls it in the same transaction, before committing.
+func recordBalanceChanges(ctx context.Context, repo interfaces.AccountRepository, tx pgx.Tx, changes ...*models.BalanceChange) error {
+	for _, change := range changes {
+		if err := repo.RecordBalanceChange(ctx, tx, change); err != nil {
+			return models.WrapError(models.CodeDatabaseError, "failed to record balance history", err)
+		}
+	}
+	return nil
+}
+
+// postLedgerEntries writes txn's double-entry postings within tx. Every service that
+// creates a transaction calls it right after, in the same database transaction.
+func postLedgerEntries(ctx context.Context, repo interfaces.TransactionRepository, tx pgx.Tx, txn *models.Transaction) error {
+	if err := repo.CreateLedgerEntries(ctx, tx, txn.LedgerEntries()...); err != nil {
+		return models.WrapError(models.CodeDatabaseError, "failed to record ledger entries", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// ZeroBalancePolicy decides whether a debit may leave an account at exactly zero.
+// Account types listed as forbidden must keep a positive balance (e.g. accounts that
+// are closed by the bank when emptied); all other types may be drained completely.
+// The zero value allows zero for every type.
+type ZeroBalancePolicy struct {
+	forbidden map[string]bool
+}
+
+// NewZeroBalancePolicy returns a policy that forbids zeroing out the given account types.
+func NewZeroBalancePolicy(forbiddenTypes []string) ZeroBalancePolicy {
+	p := ZeroBalancePolicy{forbidden: make(map[string]bool, len(forbiddenTypes))}
+	for _, t := range forbiddenTypes {
+		p.forbidden[t] = true
+	}
+	return p
+}
+
+// allowsDebit reports whether account may be debited down to newBalance. Callers check
+// for a negative result separately; this only rejects landing on exactly zero.
+func (p ZeroBalancePolicy) allowsDebit(account *models.Account, newBalance decimal.Decimal) bool {
+	return !(newBalance.IsZero() && p.forbidden[account.AccountType])
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaxBatchTransferSize caps how many legs one batch transfer may contain, bounding how
+// many row locks and inserts a single database transaction holds.
+const MaxBatchTransferSize = 500
+
+// BatchTransfer moves funds from one source account to many destinations atomically:
+// either every leg is applied or none is. The source must cover the total of all legs.
+//
+// All involved accounts are locked in ascending ID order, like single transfers, so a
+// batch cannot deadlock with them. Transient failures retry the whole batch. The returned
+// transactions are in request order. Leg-specific errors name the failing leg's index.
+func (s *TransferService) BatchTransfer(ctx context.Context, req *models.CreateBatchTransferRequest) (txns []*models.Transaction, err error) {
+	start := time.Now()
+	s.metrics.TransferAttempted()
+	defer func() { s.metrics.TransferCompleted(time.Since(start), err) }()
+
+	if len(req.Transfers) == 0 || len(req.Transfers) > MaxBatchTransferSize {
+		return nil, models.ErrInvalidBatch
+	}
+
+	drafts := make([]*models.Transaction, len(req.Transfers))
+	for i, item := range req.Transfers {
+		if item.DestinationAccountID == req.SourceAccountID {
+			return nil, models.NewDomainError(models.CodeSameAccount,
+				fmt.Sprintf("transfers[%d]: destination cannot be the source account", i))
+		}
+		amount, err := models.ParseAmount(item.Amount)
+		if err != nil {
+			logging.FromContext(ctx).Debug().Err(err).Int("leg", i).Str("amount", item.Amount).Msg("Invalid batch transfer amount")
+			return nil, models.InvalidAmountError(fmt.Sprintf("transfers[%d].amount", i), err)
+		}
+		drafts[i] = &models.Transaction{
+			SourceAccountID:      req.SourceAccountID,
+			DestinationAccountID: item.DestinationAccountID,
+			Amount:               amount,
+		}
+	}
+
+	if err := s.checkBlackout(ctx); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= s.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := s.waitBeforeRetry(ctx, attempt); err != nil {
+				return nil, err
+			}
+		}
+
+		txns, lastErr = s.executeBatch(ctx, req.SourceAccountID, drafts)
+		if lastErr == nil {
+			return txns, nil
+		}
+		if !s.shouldRetry(ctx, lastErr) {
+			return nil, lastErr
+		}
+
+		logging.FromContext(ctx).Warn().Err(lastErr).Int("attempt", attempt+1).Int("maxRetries", s.config.MaxRetries).Msg("Batch transfer failed with retryable error")
+	}
+
+	return nil, models.WrapError(models.CodeTransactionFailed, "batch transfer failed after retries", lastErr)
+}
+
+// executeBatch applies every draft in a single database transaction and returns the
+// stored copies. The drafts themselves are not modified, so the batch can be retried.
+func (s *TransferService) executeBatch(ctx context.Context, sourceID int64, drafts []*models.Transaction) ([]*models.Transaction, error) {
+	tx, err := s.accountRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, beginTxError(err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	// Lock every distinct account once, lowest ID first, to prevent deadlocks
+	ids := []int64{sourceID}
+	total := decimal.Zero
+	credits := make(map[int64]decimal.Decimal)
+	for _, d := range drafts {
+		if _, seen := credits[d.DestinationAccountID]; !seen {
+			ids = append(ids, d.DestinationAccountID)
+		}
+		credits[d.DestinationAccountID] = credits[d.DestinationAccountID].Add(d.Amount)
+		total = total.Add(d.Amount)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	accounts := make(map[int64]*models.Account, len(ids))
+	for _, id := range ids {
+		account, err := s.accountRepo.GetByIDForUpdate(ctx, tx, id)
+		if err != nil {
+			if errors.Is(err, models.ErrAccountNotFound) {
+				return nil, models.NewDomainError(models.CodeAccountNotFound, fmt.Sprintf("account %d not found", id))
+			}
+			return nil, err
+		}
+		if account.IsClosed() {
+			return nil, models.NewDomainError(models.CodeAccountClosed, fmt.Sprintf("account %d is closed", id))
+		}
+		accounts[id] = account
+	}
+
+	if err := s.checkCooldown(ctx, tx, sourceID); err != nil {
+		return nil, err
+	}
+	if err := s.checkVelocity(ctx, tx, sourceID, total, len(drafts)); err != nil {
+		return nil, err
+	}
+
+	source := accounts[sourceID]
+	if source.AvailableBalance().LessThan(total) {
+		logging.FromContext(ctx).Debug().
+			Int64("sourceAccountID", sourceID).
+			Str("balance", source.Balance.String()).
+			Str("heldBalance", source.HeldBalance.String()).
+			Str("total", total.String()).
+			Msg("Insufficient balance for batch transfer")
+		return nil, models.ErrInsufficientBalance
+	}
+	if !s.zeroPolicy.allowsDebit(source, source.Balance.Sub(total)) {
+		logging.FromContext(ctx).Debug().
+			Int64("sourceAccountID", sourceID).
+			Str("accountType", source.AccountType).
+			Msg("Batch transfer would leave source at zero, which its account type forbids")
+		return nil, models.ErrInsufficientBalance
+	}
+
+	for i, d := range drafts {
+		dest := accounts[d.DestinationAccountID]
+		if dest.MaxBalance.Valid && dest.Balance.Add(credits[dest.AccountID]).GreaterThan(dest.MaxBalance.Decimal) {
+			return nil, models.NewDomainError(models.CodeDestBalanceLimit,
+				fmt.Sprintf("transfers[%d]: batch would exceed account %d maximum balance", i, dest.AccountID))
+		}
+	}
+
+	if err := s.accountRepo.UpdateBalance(ctx, tx, sourceID, source.Balance.Sub(total)); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to update source balance", err)
+	}
+	for _, id := range ids {
+		if id == sourceID {
+			continue
+		}
+		if err := s.accountRepo.UpdateBalance(ctx, tx, id, accounts[id].Balance.Add(credits[id])); err != nil {
+			return nil, models.WrapError(models.CodeDatabaseError, "failed to update destination balance", err)
+		}
+	}
+
+	// History is recorded per leg, so each account's entries chain from its starting
+	// balance to the one just written
+	running := make(map[int64]decimal.Decimal, len(ids))
+	for _, id := range ids {
+		running[id] = accounts[id].Balance
+	}
+
+	txns := make([]*models.Transaction, len(drafts))
+	for i, d := range drafts {
+		transaction := *d
+		transaction.Status = models.TransactionStatusCompleted
+		if err := s.transactionRepo.Create(ctx, tx, &transaction); err != nil {
+			if _, ok := models.IsDomainError(err); ok {
+				return nil, err
+			}
+			return nil, models.WrapError(models.CodeDatabaseError, "failed to create transaction record", err)
+		}
+		if err := postLedgerEntries(ctx, s.transactionRepo, tx, &transaction); err != nil {
+			return nil, err
+		}
+		destID := transaction.DestinationAccountID
+		debit := &models.BalanceChange{AccountID: sourceID, OldBalance: running[sourceID], NewBalance: running[sourceID].Sub(transaction.Amount), TransactionID: &transaction.TransactionID}
+		credit := &models.BalanceChange{AccountID: destID, OldBalance: running[destID], NewBalance: running[destID].Add(transaction.Amount), TransactionID: &transaction.TransactionID}
+		running[sourceID], running[destID] = debit.NewBalance, credit.NewBalance
+		if err := recordBalanceChanges(ctx, s.accountRepo, tx, debit, credit); err != nil {
+			return nil, err
+		}
+		if err := s.runPreCommitHooks(ctx, tx, &transaction); err != nil {
+			return nil, err
+		}
+		txns[i] = &transaction
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to commit transaction", &commitError{err: err})
+	}
+
+	logging.FromContext(ctx).Info().
+		Int64("sourceAccountID", sourceID).
+		Int("transfers", len(txns)).
+		Str("total", total.String()).
+		Msg("Batch transfer completed successfully")
+
+	for _, txn := range txns {
+		s.runPostCommitHooks(ctx, txn)
+	}
+
+	return txns, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func newBatchTestService() (*TransferService, *mocks.MockAccountRepository, *mocks.MockTransactionRepository) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+	accRepo.SetAccount(&models.Account{AccountID: 3, Balance: decimal.NewFromInt(0)})
+	accRepo.SetAccount(&models.Account{AccountID: 4, Balance: decimal.NewFromInt(0), MaxBalance: decimal.NewNullDecimal(decimal.NewFromInt(5))})
+	txnRepo := mocks.NewMockTransactionRepository()
+	return NewTransferService(accRepo, txnRepo), accRepo, txnRepo
+}
+
+func batch(source int64, legs ...models.BatchTransferItem) *models.CreateBatchTransferRequest {
+	return &models.CreateBatchTransferRequest{SourceAccountID: source, Transfers: legs}
+}
+
+func leg(dest int64, amount string) models.BatchTransferItem {
+	return models.BatchTransferItem{DestinationAccountID: dest, Amount: amount}
+}
+
+func TestTransferService_BatchTransfer(t *testing.T) {
+	svc, accRepo, _ := newBatchTestService()
+
+	txns, err := svc.BatchTransfer(context.Background(), batch(1, leg(3, "30"), leg(2, "20"), leg(3, "5.5")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(txns) != 3 {
+		t.Fatalf("expected 3 transactions, got %d", len(txns))
+	}
+	for i, want := range []int64{3, 2, 3} {
+		if txns[i].DestinationAccountID != want || txns[i].TransactionID == 0 {
+			t.Errorf("leg %d: expected a stored transaction to %d, got %+v", i, want, txns[i])
+		}
+	}
+
+	for id, want := range map[int64]string{1: "44.5", 2: "20", 3: "35.5"} {
+		if acc, _ := accRepo.GetAccount(id); acc.Balance.String() != want {
+			t.Errorf("account %d: expected %s, got %s", id, want, acc.Balance)
+		}
+	}
+}
+
+func TestTransferService_BatchTransfer_AllOrNothing(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *models.CreateBatchTransferRequest
+		wantErr  error
+		wantText string
+	}{
+		{"total exceeds source balance", batch(1, leg(2, "60"), leg(3, "41")), models.ErrInsufficientBalance, ""},
+		{"missing destination", batch(1, leg(2, "10"), leg(9, "10")), models.ErrAccountNotFound, "account 9"},
+		{"destination over max balance", batch(1, leg(2, "10"), leg(4, "3"), leg(4, "3")), models.ErrDestinationBalanceLimit, "transfers[1]"},
+		{"invalid amount", batch(1, leg(2, "10"), leg(3, "-1")), models.ErrInvalidAmount, "transfers[1]"},
+		{"destination is source", batch(1, leg(2, "10"), leg(1, "1")), models.ErrSameAccount, "transfers[1]"},
+		{"empty", batch(1), models.ErrInvalidBatch, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, accRepo, txnRepo := newBatchTestService()
+
+			_, err := svc.BatchTransfer(context.Background(), tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantText) {
+				t.Errorf("expected error to mention %q, got %v", tt.wantText, err)
+			}
+
+			// No leg may have been applied
+			for id, want := range map[int64]int64{1: 100, 2: 0, 3: 0, 4: 0} {
+				if acc, _ := accRepo.GetAccount(id); !acc.Balance.Equal(decimal.NewFromInt(want)) {
+					t.Errorf("account %d changed to %s", id, acc.Balance)
+				}
+			}
+			if txns, _ := txnRepo.GetByAccountID(context.Background(), 1, 10, 0); len(txns) != 0 {
+				t.Errorf("expected no transaction rows, got %d", len(txns))
+			}
+		})
+	}
+}
+
+func TestTransferService_BatchTransfer_BalanceHistory(t *testing.T) {
+	svc, accRepo, _ := newBatchTestService()
+
+	if _, err := svc.BatchTransfer(context.Background(), batch(1, leg(3, "30"), leg(2, "20"), leg(3, "5.5"))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Each account's entries chain from its starting balance to its final one
+	want := map[int64][]string{1: {"100", "70", "50", "44.5"}, 2: {"0", "20"}, 3: {"0", "30", "35.5"}}
+	got := make(map[int64][]string)
+	for _, change := range accRepo.BalanceHistory() {
+		chain := got[change.AccountID]
+		if len(chain) == 0 {
+			chain = append(chain, change.OldBalance.String())
+		} else if chain[len(chain)-1] != change.OldBalance.String() {
+			t.Errorf("account %d: change %+v does not follow %s", change.AccountID, change, chain[len(chain)-1])
+		}
+		got[change.AccountID] = append(chain, change.NewBalance.String())
+	}
+	for id, chain := range want {
+		if strings.Join(got[id], " ") != strings.Join(chain, " ") {
+			t.Errorf("account %d: expected history %v, got %v", id, chain, got[id])
+		}
+	}
+}
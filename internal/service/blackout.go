@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+)
+
+// BlackoutWindow is a daily period, as offsets from midnight, during which transfers are
+// refused. A window whose End is not after its Start runs past midnight, e.g. 23:55-00:05.
+type BlackoutWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseBlackoutWindow parses a window written "HH:MM-HH:MM" in 24-hour time. The start
+// is inclusive and the end exclusive; they may not be equal.
+func ParseBlackoutWindow(s string) (BlackoutWindow, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return BlackoutWindow{}, fmt.Errorf("blackout window %q must be HH:MM-HH:MM", s)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return BlackoutWindow{}, fmt.Errorf("blackout window %q must be HH:MM-HH:MM", s)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return BlackoutWindow{}, fmt.Errorf("blackout window %q must be HH:MM-HH:MM", s)
+	}
+	if start.Equal(end) {
+		return BlackoutWindow{}, fmt.Errorf("blackout window %q is empty", s)
+	}
+	midnight := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+	return BlackoutWindow{Start: start.Sub(midnight), End: end.Sub(midnight)}, nil
+}
+
+// BlackoutSchedule is the set of daily windows during which transfers are refused,
+// read on Location's wall clock. A nil Location means UTC. The zero value never blacks
+// out.
+type BlackoutSchedule struct {
+	Windows  []BlackoutWindow
+	Location *time.Location
+}
+
+// Until reports whether now falls in a blackout window and, if so, when transfers are
+// accepted again. Windows that overlap or abut are treated as one.
+func (b BlackoutSchedule) Until(now time.Time) (time.Time, bool) {
+	until, in := now, false
+	// Each pass can only move past one more window; more passes mean the windows cover
+	// the whole day, and the end of the last one found is as good an answer as any
+	for i := 0; i <= len(b.Windows); i++ {
+		end, ok := b.coveringEnd(until)
+		if !ok {
+			break
+		}
+		until, in = end, true
+	}
+	return until, in
+}
+
+// coveringEnd returns the latest end of a window covering t.
+func (b BlackoutSchedule) coveringEnd(t time.Time) (time.Time, bool) {
+	loc := b.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	y, m, d := t.In(loc).Date()
+
+	var latest time.Time
+	found := false
+	for _, w := range b.Windows {
+		// A window running past midnight may have started the day before
+		for _, day := range []int{d - 1, d} {
+			start := wallClock(y, m, day, w.Start, loc)
+			endDay := day
+			if w.End <= w.Start {
+				endDay++
+			}
+			end := wallClock(y, m, endDay, w.End, loc)
+			if !t.Before(start) && t.Before(end) && end.After(latest) {
+				latest, found = end, true
+			}
+		}
+	}
+	return latest, found
+}
+
+// wallClock returns offset past midnight on the given day as read on loc's wall clock,
+// so a window keeps its local times across daylight saving changes.
+func wallClock(y int, m time.Month, d int, offset time.Duration, loc *time.Location) time.Time {
+	return time.Date(y, m, d, int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, loc)
+}
+
+// checkBlackout fails with ErrTransferBlackout, wrapping a *models.BlackoutError, while
+// the configured blackout schedule is in effect.
+func (s *TransferService) checkBlackout(ctx context.Context) error {
+	until, ok := s.config.Blackout.Until(time.Now())
+	if !ok {
+		return nil
+	}
+	logging.FromContext(ctx).Debug().Time("until", until).Msg("Transfer refused during blackout")
+	return models.WrapError(models.CodeTransferBlackout, models.ErrTransferBlackout.Message, &models.BlackoutError{Until: until})
+}
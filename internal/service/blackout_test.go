@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func mustWindow(t *testing.T, s string) BlackoutWindow {
+	t.Helper()
+	w, err := ParseBlackoutWindow(s)
+	if err != nil {
+		t.Fatalf("parse %q: %v", s, err)
+	}
+	return w
+}
+
+func TestParseBlackoutWindow(t *testing.T) {
+	w := mustWindow(t, " 23:55 - 00:05 ")
+	if w.Start != 23*time.Hour+55*time.Minute || w.End != 5*time.Minute {
+		t.Errorf("unexpected window %+v", w)
+	}
+	for _, bad := range []string{"", "23:55", "23:55-24:00", "9-10", "12:00-12:00"} {
+		if _, err := ParseBlackoutWindow(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestBlackoutSchedule_Until(t *testing.T) {
+	day := func(h, m int) time.Time { return time.Date(2024, 3, 1, h, m, 0, 0, time.UTC) }
+	schedule := BlackoutSchedule{Windows: []BlackoutWindow{
+		mustWindow(t, "23:55-00:05"),
+		mustWindow(t, "12:00-12:30"),
+		mustWindow(t, "12:30-13:00"),
+	}}
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		want   time.Time
+		inside bool
+	}{
+		{"before midnight", day(23, 58), day(24, 5), true},
+		{"after midnight", day(0, 1), day(0, 5), true},
+		{"start is inclusive", day(23, 55), day(24, 5), true},
+		{"end is exclusive", day(0, 5), time.Time{}, false},
+		{"outside", day(9, 0), time.Time{}, false},
+		{"abutting windows merge", day(12, 10), day(13, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, inside := schedule.Until(tt.now)
+			if inside != tt.inside || (inside && !until.Equal(tt.want)) {
+				t.Errorf("expected %v until %s, got %v until %s", tt.inside, tt.want, inside, until)
+			}
+		})
+	}
+
+	t.Run("read in location", func(t *testing.T) {
+		loc := time.FixedZone("UTC+2", 2*60*60)
+		s := BlackoutSchedule{Windows: []BlackoutWindow{mustWindow(t, "23:55-00:05")}, Location: loc}
+		until, inside := s.Until(day(21, 58))
+		if !inside || !until.Equal(day(22, 5)) {
+			t.Errorf("expected the window on UTC+2's clock, got %v until %s", inside, until)
+		}
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		if _, inside := (BlackoutSchedule{}).Until(day(0, 0)); inside {
+			t.Error("expected no blackout")
+		}
+	})
+}
+
+// blackoutNow returns a schedule whose only window covers the current minute.
+func blackoutNow() BlackoutSchedule {
+	midnight := time.Now().UTC().Truncate(24 * time.Hour)
+	start := time.Now().UTC().Truncate(time.Minute).Sub(midnight)
+	return BlackoutSchedule{Windows: []BlackoutWindow{{Start: start, End: (start + 10*time.Minute) % (24 * time.Hour)}}}
+}
+
+func TestTransferService_Blackout(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+	txnRepo := mocks.NewMockTransactionRepository()
+	svc := NewTransferService(accRepo, txnRepo)
+	ctx := context.Background()
+
+	applied, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "10", IdempotencyKey: "before"})
+	if err != nil {
+		t.Fatalf("transfer before the blackout: %v", err)
+	}
+	recurring := createRecurring(t, svc, time.Now().Add(-time.Minute), nil)
+	svc.config.Blackout = blackoutNow()
+
+	_, err = svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "10"})
+	var blackout *models.BlackoutError
+	if !errors.Is(err, models.ErrTransferBlackout) || !errors.As(err, &blackout) {
+		t.Fatalf("expected ErrTransferBlackout, got %v", err)
+	}
+	if !blackout.Until.After(time.Now()) {
+		t.Errorf("expected the blackout to end in the future, got %s", blackout.Until)
+	}
+
+	if _, err := svc.Reverse(ctx, applied.TransactionID); !errors.Is(err, models.ErrTransferBlackout) {
+		t.Errorf("reverse: expected ErrTransferBlackout, got %v", err)
+	}
+	_, err = svc.BatchTransfer(ctx, &models.CreateBatchTransferRequest{
+		SourceAccountID: 1,
+		Transfers:       []models.BatchTransferItem{{DestinationAccountID: 2, Amount: "1"}},
+	})
+	if !errors.Is(err, models.ErrTransferBlackout) {
+		t.Errorf("batch: expected ErrTransferBlackout, got %v", err)
+	}
+
+	replayed, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "10", IdempotencyKey: "before"})
+	if err != nil || replayed.TransactionID != applied.TransactionID {
+		t.Errorf("expected the replay to return transaction %d, got %v, %v", applied.TransactionID, replayed, err)
+	}
+
+	// A recurring run refused by the blackout stays due rather than being skipped
+	if runs, _ := svc.RunDueRecurring(ctx, time.Now()); runs != 0 {
+		t.Errorf("expected no runs during the blackout, got %d", runs)
+	}
+	got, _ := svc.GetRecurring(ctx, recurring.RecurringID)
+	if got.Runs != 0 || !got.NextRunAt.Equal(recurring.NextRunAt) {
+		t.Errorf("expected the slot to stay due, got %+v", got)
+	}
+
+	src, _ := accRepo.GetAccount(1)
+	if src.Balance.String() != "90" {
+		t.Errorf("expected only the transfer before the blackout applied, got balance %s", src.Balance)
+	}
+}
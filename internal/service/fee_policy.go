@@ -0,0 +1,73 @@
+package service
+
+import (
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// FeePolicy prices the fee charged on each transfer and says where it goes. A fee is a
+// flat part plus a percentage of the amount, clamped to [Min, Max] and rounded to the
+// money scale. The zero value charges no fees.
+type FeePolicy struct {
+	// AccountID is the account every fee is credited to. Zero disables fees.
+	AccountID int64
+
+	// Flat is charged on every transfer. Percent is a percentage of the amount, e.g. 1.5
+	// for 1.5%.
+	Flat    decimal.Decimal
+	Percent decimal.Decimal
+
+	// Min and Max bound the fee. A zero Max leaves it uncapped.
+	Min decimal.Decimal
+	Max decimal.Decimal
+
+	// PaidBy is the side that pays. Empty means FeePayerSource.
+	PaidBy models.FeePayer
+}
+
+// Enabled reports whether the policy charges fees at all.
+func (p FeePolicy) Enabled() bool {
+	return p.AccountID != 0
+}
+
+// Payer returns the side that pays the fee.
+func (p FeePolicy) Payer() models.FeePayer {
+	if p.PaidBy == "" {
+		return models.FeePayerSource
+	}
+	return p.PaidBy
+}
+
+// Fee returns the fee on a transfer of amount. The percentage part is computed at full
+// precision and only the final fee is rounded, half away from zero, to the money scale.
+func (p FeePolicy) Fee(amount decimal.Decimal) decimal.Decimal {
+	if !p.Enabled() {
+		return decimal.Zero
+	}
+
+	fee := p.Flat.Add(amount.Mul(p.Percent).Div(decimal.NewFromInt(100)))
+	if fee.LessThan(p.Min) {
+		fee = p.Min
+	}
+	if p.Max.IsPositive() && fee.GreaterThan(p.Max) {
+		fee = p.Max
+	}
+	return fee.Round(models.MaxMoneyScale())
+}
+
+// feeFor returns the fee on draft and fails with ErrFeeExceedsAmount if it is more than
+// the amount, or the whole amount when the destination pays. Reversals and transfers to
+// or from the fee account itself are free.
+func (p FeePolicy) feeFor(draft *models.Transaction) (decimal.Decimal, error) {
+	if !p.Enabled() || draft.ReversalOf != nil ||
+		draft.SourceAccountID == p.AccountID || draft.DestinationAccountID == p.AccountID {
+		return decimal.Zero, nil
+	}
+
+	fee := p.Fee(draft.Amount)
+	if fee.GreaterThan(draft.Amount) || (p.Payer() == models.FeePayerDestination && fee.Equal(draft.Amount)) {
+		return decimal.Zero, models.ErrFeeExceedsAmount
+	}
+	return fee, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFeePolicy_Fee(t *testing.T) {
+	d := decimal.RequireFromString
+	tests := []struct {
+		name   string
+		policy FeePolicy
+		amount string
+		want   string
+	}{
+		{"disabled", FeePolicy{Flat: d("1")}, "100", "0"},
+		{"flat", FeePolicy{AccountID: 9, Flat: d("0.3")}, "100", "0.3"},
+		{"percent", FeePolicy{AccountID: 9, Percent: d("2.5")}, "10.10", "0.25"},
+		{"flat and percent", FeePolicy{AccountID: 9, Flat: d("0.3"), Percent: d("2.9")}, "100", "3.2"},
+		{"rounds half away from zero", FeePolicy{AccountID: 9, Percent: d("1")}, "0.5", "0.01"},
+		{"rounds down below half", FeePolicy{AccountID: 9, Percent: d("1.5")}, "0.33", "0"},
+		{"min", FeePolicy{AccountID: 9, Percent: d("1"), Min: d("0.5")}, "10", "0.5"},
+		{"max", FeePolicy{AccountID: 9, Percent: d("1"), Max: d("5")}, "1000", "5"},
+		{"zero max is uncapped", FeePolicy{AccountID: 9, Percent: d("1")}, "1000", "10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Fee(d(tt.amount)); got.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFeePolicy_FeeFor(t *testing.T) {
+	reversalOf := int64(1)
+	transfer := func(source, dest int64, amount string) *models.Transaction {
+		return &models.Transaction{SourceAccountID: source, DestinationAccountID: dest, Amount: decimal.RequireFromString(amount)}
+	}
+	flat := func(fee string, payer models.FeePayer) FeePolicy {
+		return FeePolicy{AccountID: 9, Flat: decimal.RequireFromString(fee), PaidBy: payer}
+	}
+	tests := []struct {
+		name    string
+		policy  FeePolicy
+		draft   *models.Transaction
+		want    string
+		wantErr error
+	}{
+		{"charged", flat("1", ""), transfer(1, 2, "10"), "1", nil},
+		{"source pays the whole amount again", flat("10", models.FeePayerSource), transfer(1, 2, "10"), "10", nil},
+		{"exceeds amount", flat("10.01", models.FeePayerSource), transfer(1, 2, "10"), "0", models.ErrFeeExceedsAmount},
+		{"destination would receive nothing", flat("10", models.FeePayerDestination), transfer(1, 2, "10"), "0", models.ErrFeeExceedsAmount},
+		{"from the fee account", flat("1", ""), transfer(9, 2, "10"), "0", nil},
+		{"to the fee account", flat("1", ""), transfer(1, 9, "10"), "0", nil},
+		{"reversal", flat("1", ""), &models.Transaction{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(10), ReversalOf: &reversalOf}, "0", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee, err := tt.policy.feeFor(tt.draft)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if fee.String() != tt.want {
+				t.Errorf("expected fee %s, got %s", tt.want, fee)
+			}
+		})
+	}
+}
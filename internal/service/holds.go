@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// PlaceHold reserves amount of accountID's available balance, e.g. for a card
+// authorization. The funds stay in the balance but transfers and withdrawals can no
+// longer spend them until the hold is released or captured. It fails with
+// ErrInsufficientBalance if the available balance can't cover amount, and with
+// ErrAccountClosed for a closed account.
+func (s *TransferService) PlaceHold(ctx context.Context, accountID int64, amount decimal.Decimal) (*models.Hold, error) {
+	if !amount.IsPositive() {
+		return nil, models.ErrInvalidAmount
+	}
+
+	tx, err := s.accountRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, beginTxError(err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	// Checked under the account's row lock, so a concurrent transfer either lands first
+	// and shrinks the available balance or sees the hold
+	account, err := s.accountRepo.GetByIDForUpdate(ctx, tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.IsClosed() {
+		return nil, models.ErrAccountClosed
+	}
+	if account.AvailableBalance().LessThan(amount) {
+		logging.FromContext(ctx).Debug().
+			Int64("accountID", accountID).
+			Str("balance", account.Balance.String()).
+			Str("heldBalance", account.HeldBalance.String()).
+			Str("amount", amount.String()).
+			Msg("Insufficient balance for hold")
+		return nil, models.ErrInsufficientBalance
+	}
+	// Judged as if every active hold were captured, so capture itself never has to fail
+	if !s.zeroPolicy.allowsDebit(account, account.AvailableBalance().Sub(amount)) {
+		logging.FromContext(ctx).Debug().
+			Int64("accountID", accountID).
+			Str("accountType", account.AccountType).
+			Msg("Hold could leave account at zero, which its account type forbids")
+		return nil, models.ErrInsufficientBalance
+	}
+
+	if err := s.accountRepo.UpdateHeldBalance(ctx, tx, accountID, account.HeldBalance.Add(amount)); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to update held balance", err)
+	}
+	hold := &models.Hold{AccountID: accountID, Amount: amount}
+	if err := s.accountRepo.CreateHold(ctx, tx, hold); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to create hold", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to commit transaction", err)
+	}
+
+	logging.FromContext(ctx).Info().
+		Int64("holdID", hold.HoldID).
+		Int64("accountID", accountID).
+		Str("amount", amount.String()).
+		Msg("Hold placed")
+
+	return hold, nil
+}
+
+// ReleaseHold returns an active hold's amount to the account's available balance without
+// moving any funds. Holds on closed accounts can still be released. It fails with
+// ErrHoldNotFound, or ErrHoldNotActive if the hold was already released or captured.
+func (s *TransferService) ReleaseHold(ctx context.Context, holdID int64) (*models.Hold, error) {
+	return s.resolveHold(ctx, holdID, models.HoldStatusReleased)
+}
+
+// CaptureHold turns an active hold into a withdrawal of its full amount, debiting the
+// balance and the held balance together. The withdrawal is recorded in the hold's
+// TransactionID and the account's balance history. It fails with ErrHoldNotFound,
+// ErrHoldNotActive, or ErrAccountClosed.
+func (s *TransferService) CaptureHold(ctx context.Context, holdID int64) (*models.Hold, error) {
+	return s.resolveHold(ctx, holdID, models.HoldStatusCaptured)
+}
+
+// resolveHold releases or captures a hold in a single database transaction. The hold is
+// locked before its account, so two requests for the same hold can't both resolve it.
+func (s *TransferService) resolveHold(ctx context.Context, holdID int64, status models.HoldStatus) (*models.Hold, error) {
+	tx, err := s.accountRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, beginTxError(err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	hold, err := s.accountRepo.GetHoldForUpdate(ctx, tx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != models.HoldStatusActive {
+		return nil, models.ErrHoldNotActive
+	}
+
+	account, err := s.accountRepo.GetByIDForUpdate(ctx, tx, hold.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The held balance is lowered first: the database requires it to stay within the
+	// balance after every statement
+	if err := s.accountRepo.UpdateHeldBalance(ctx, tx, hold.AccountID, account.HeldBalance.Sub(hold.Amount)); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to update held balance", err)
+	}
+
+	var transactionID *int64
+	if status == models.HoldStatusCaptured {
+		entry, err := s.captureHold(ctx, tx, hold, account)
+		if err != nil {
+			return nil, err
+		}
+		transactionID = &entry.TransactionID
+	}
+
+	resolvedAt, err := s.accountRepo.ResolveHold(ctx, tx, holdID, status, transactionID)
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to resolve hold", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to commit transaction", err)
+	}
+	hold.Status = status
+	hold.TransactionID = transactionID
+	hold.ResolvedAt = &resolvedAt
+
+	logging.FromContext(ctx).Info().
+		Int64("holdID", holdID).
+		Int64("accountID", hold.AccountID).
+		Str("amount", hold.Amount.String()).
+		Str("status", string(status)).
+		Msg("Hold resolved")
+
+	return hold, nil
+}
+
+// captureHold debits a hold's amount from its locked account as a withdrawal within tx
+// and records the balance change. The caller has already lowered the held balance.
+func (s *TransferService) captureHold(ctx context.Context, tx pgx.Tx, hold *models.Hold, account *models.Account) (*models.Transaction, error) {
+	if account.IsClosed() {
+		return nil, models.ErrAccountClosed
+	}
+
+	newBalance := account.Balance.Sub(hold.Amount)
+	if err := s.accountRepo.UpdateBalance(ctx, tx, account.AccountID, newBalance); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to update balance", err)
+	}
+
+	entry := &models.Transaction{
+		Type:            models.TransactionTypeWithdrawal,
+		SourceAccountID: account.AccountID,
+		Amount:          hold.Amount,
+		Status:          models.TransactionStatusCompleted,
+	}
+	if err := s.transactionRepo.Create(ctx, tx, entry); err != nil {
+		if _, ok := models.IsDomainError(err); ok {
+			return nil, err
+		}
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to create capture transaction", err)
+	}
+	if err := postLedgerEntries(ctx, s.transactionRepo, tx, entry); err != nil {
+		return nil, err
+	}
+	if err := recordBalanceChanges(ctx, s.accountRepo, tx, &models.BalanceChange{
+		AccountID: account.AccountID, OldBalance: account.Balance, NewBalance: newBalance, TransactionID: &entry.TransactionID,
+	}); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
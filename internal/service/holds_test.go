@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func newHoldTestService() (*TransferService, *mocks.MockAccountRepository, *mocks.MockTransactionRepository) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+	txnRepo := mocks.NewMockTransactionRepository()
+	return NewTransferService(accRepo, txnRepo), accRepo, txnRepo
+}
+
+func TestTransferService_PlaceHold(t *testing.T) {
+	ctx := context.Background()
+	svc, accRepo, _ := newHoldTestService()
+
+	hold, err := svc.PlaceHold(ctx, 1, decimal.NewFromInt(70))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hold.HoldID == 0 || hold.Status != models.HoldStatusActive || hold.AccountID != 1 {
+		t.Errorf("expected an active hold on account 1, got %+v", hold)
+	}
+	acc, _ := accRepo.GetAccount(1)
+	if acc.Balance.String() != "100" || acc.HeldBalance.String() != "70" {
+		t.Errorf("expected balance 100 with 70 held, got %s and %s", acc.Balance, acc.HeldBalance)
+	}
+
+	// Only 30 is available now, for further holds and for transfers alike
+	if _, err := svc.PlaceHold(ctx, 1, decimal.NewFromInt(31)); !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance for a second hold, got %v", err)
+	}
+	_, err = svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "31"})
+	if !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance for a transfer over the available balance, got %v", err)
+	}
+	if _, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "30"}); err != nil {
+		t.Errorf("expected a transfer of the available balance to succeed, got %v", err)
+	}
+}
+
+func TestTransferService_PlaceHold_Rejections(t *testing.T) {
+	ctx := context.Background()
+	svc, accRepo, _ := newHoldTestService()
+	closedAt := time.Now()
+	accRepo.SetAccount(&models.Account{AccountID: 3, Balance: decimal.NewFromInt(50), ClosedAt: &closedAt})
+
+	tests := []struct {
+		name      string
+		accountID int64
+		amount    decimal.Decimal
+		want      error
+	}{
+		{"zero amount", 1, decimal.Zero, models.ErrInvalidAmount},
+		{"over balance", 1, decimal.NewFromInt(101), models.ErrInsufficientBalance},
+		{"missing account", 99, decimal.NewFromInt(1), models.ErrAccountNotFound},
+		{"closed account", 3, decimal.NewFromInt(1), models.ErrAccountClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.PlaceHold(ctx, tt.accountID, tt.amount); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestTransferService_ReleaseHold(t *testing.T) {
+	ctx := context.Background()
+	svc, accRepo, txnRepo := newHoldTestService()
+
+	hold, err := svc.PlaceHold(ctx, 1, decimal.NewFromInt(40))
+	if err != nil {
+		t.Fatalf("place: %v", err)
+	}
+	released, err := svc.ReleaseHold(ctx, hold.HoldID)
+	if err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if released.Status != models.HoldStatusReleased || released.ResolvedAt == nil || released.TransactionID != nil {
+		t.Errorf("expected a released hold without a transaction, got %+v", released)
+	}
+	acc, _ := accRepo.GetAccount(1)
+	if acc.Balance.String() != "100" || !acc.HeldBalance.IsZero() {
+		t.Errorf("expected balance 100 with nothing held, got %s and %s", acc.Balance, acc.HeldBalance)
+	}
+	if txns, _ := txnRepo.GetByAccountID(ctx, 1, 10, 0); len(txns) != 0 {
+		t.Errorf("expected a release to record no transaction, got %d", len(txns))
+	}
+
+	if _, err := svc.ReleaseHold(ctx, hold.HoldID); !errors.Is(err, models.ErrHoldNotActive) {
+		t.Errorf("expected ErrHoldNotActive releasing twice, got %v", err)
+	}
+	if _, err := svc.CaptureHold(ctx, hold.HoldID); !errors.Is(err, models.ErrHoldNotActive) {
+		t.Errorf("expected ErrHoldNotActive capturing a released hold, got %v", err)
+	}
+	if _, err := svc.ReleaseHold(ctx, 999); !errors.Is(err, models.ErrHoldNotFound) {
+		t.Errorf("expected ErrHoldNotFound, got %v", err)
+	}
+}
+
+func TestTransferService_CaptureHold(t *testing.T) {
+	ctx := context.Background()
+	svc, accRepo, txnRepo := newHoldTestService()
+
+	hold, err := svc.PlaceHold(ctx, 1, decimal.NewFromInt(40))
+	if err != nil {
+		t.Fatalf("place: %v", err)
+	}
+	captured, err := svc.CaptureHold(ctx, hold.HoldID)
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if captured.Status != models.HoldStatusCaptured || captured.TransactionID == nil {
+		t.Fatalf("expected a captured hold with a transaction, got %+v", captured)
+	}
+
+	acc, _ := accRepo.GetAccount(1)
+	if acc.Balance.String() != "60" || !acc.HeldBalance.IsZero() {
+		t.Errorf("expected balance 60 with nothing held, got %s and %s", acc.Balance, acc.HeldBalance)
+	}
+	entry, err := txnRepo.GetByID(ctx, *captured.TransactionID)
+	if err != nil {
+		t.Fatalf("get capture transaction: %v", err)
+	}
+	if entry.Type != models.TransactionTypeWithdrawal || entry.SourceAccountID != 1 || entry.Amount.String() != "40" {
+		t.Errorf("expected a 40 withdrawal from account 1, got %+v", entry)
+	}
+
+	history := accRepo.BalanceHistory()
+	if len(history) != 1 || history[0].OldBalance.String() != "100" || history[0].NewBalance.String() != "60" {
+		t.Errorf("expected one 100 -> 60 history entry, got %+v", history)
+	}
+
+	if _, err := svc.CaptureHold(ctx, hold.HoldID); !errors.Is(err, models.ErrHoldNotActive) {
+		t.Errorf("expected ErrHoldNotActive capturing twice, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"internal-transfers-system/internal/interfaces"
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// LedgerService applies single-sided balance changes: deposits into and withdrawals out
+// of one account. Each is recorded in the transactions table with the matching type and
+// only one side set, so it shows up in the account's history next to transfers.
+type LedgerService struct {
+	accountRepo     interfaces.AccountRepository
+	transactionRepo interfaces.TransactionRepository
+	zeroPolicy      ZeroBalancePolicy
+}
+
+type LedgerServiceConfig struct {
+	// ForbidZeroBalanceTypes lists account types that a withdrawal may not leave at
+	// exactly zero, as for transfers.
+	ForbidZeroBalanceTypes []string
+}
+
+func NewLedgerService(
+	accountRepo interfaces.AccountRepository,
+	transactionRepo interfaces.TransactionRepository,
+) *LedgerService {
+	return NewLedgerServiceWithConfig(accountRepo, transactionRepo, LedgerServiceConfig{})
+}
+
+func NewLedgerServiceWithConfig(
+	accountRepo interfaces.AccountRepository,
+	transactionRepo interfaces.TransactionRepository,
+	config LedgerServiceConfig,
+) *LedgerService {
+	return &LedgerService{
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		zeroPolicy:      NewZeroBalancePolicy(config.ForbidZeroBalanceTypes),
+	}
+}
+
+// Deposit credits accountID. It fails with ErrDestinationBalanceLimit if the account
+// would exceed its max balance.
+func (s *LedgerService) Deposit(ctx context.Context, accountID int64, req *models.LedgerEntryRequest) (*models.Transaction, error) {
+	return s.apply(ctx, models.TransactionTypeDeposit, accountID, req)
+}
+
+// Withdraw debits accountID. It fails with ErrInsufficientBalance if the account's
+// available balance (not reserved by holds) is less than the amount, or it would be left
+// at zero when its type forbids that.
+func (s *LedgerService) Withdraw(ctx context.Context, accountID int64, req *models.LedgerEntryRequest) (*models.Transaction, error) {
+	return s.apply(ctx, models.TransactionTypeWithdrawal, accountID, req)
+}
+
+// apply records one deposit or withdrawal. With an idempotency key, a repeat of an
+// earlier request returns the original entry marked Replayed instead of moving funds
+// again; see lookupIdempotent.
+func (s *LedgerService) apply(ctx context.Context, kind models.TransactionType, accountID int64, req *models.LedgerEntryRequest) (*models.Transaction, error) {
+	amount, err := models.ParseAmount(req.Amount)
+	if err != nil {
+		logging.FromContext(ctx).Debug().Err(err).Str("amount", req.Amount).Msg("Invalid ledger entry amount")
+		return nil, models.InvalidAmountError("amount", err)
+	}
+
+	draft := &models.Transaction{Type: kind, Amount: amount, Category: req.Category, IdempotencyKey: req.IdempotencyKey}
+	switch kind {
+	case models.TransactionTypeDeposit:
+		draft.DestinationAccountID = accountID
+	case models.TransactionTypeWithdrawal:
+		draft.SourceAccountID = accountID
+	}
+	if draft.IdempotencyKey == "" {
+		return s.applyOnce(ctx, draft, accountID)
+	}
+
+	if existing, err := s.lookupIdempotent(ctx, draft); err != nil || existing != nil {
+		return existing, err
+	}
+	entry, err := s.applyOnce(ctx, draft, accountID)
+	// A concurrent request with the same key committed first; its entry is the result
+	if errors.Is(err, models.ErrDuplicateTransaction) {
+		existing, lookupErr := s.lookupIdempotent(ctx, draft)
+		if lookupErr != nil || existing != nil {
+			return existing, lookupErr
+		}
+	}
+	return entry, err
+}
+
+// applyOnce moves the funds for draft and records it in a single database transaction.
+func (s *LedgerService) applyOnce(ctx context.Context, draft *models.Transaction, accountID int64) (*models.Transaction, error) {
+	kind, amount := draft.Type, draft.Amount
+
+	tx, err := s.accountRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, beginTxError(err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	account, err := s.accountRepo.GetByIDForUpdate(ctx, tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.IsClosed() {
+		return nil, models.ErrAccountClosed
+	}
+
+	entry := *draft
+	entry.Status = models.TransactionStatusCompleted
+	var newBalance decimal.Decimal
+	switch kind {
+	case models.TransactionTypeDeposit:
+		newBalance = account.Balance.Add(amount)
+		if account.MaxBalance.Valid && newBalance.GreaterThan(account.MaxBalance.Decimal) {
+			return nil, models.ErrDestinationBalanceLimit
+		}
+	case models.TransactionTypeWithdrawal:
+		if account.AvailableBalance().LessThan(amount) {
+			logging.FromContext(ctx).Debug().
+				Int64("accountID", accountID).
+				Str("balance", account.Balance.String()).
+				Str("heldBalance", account.HeldBalance.String()).
+				Str("amount", amount.String()).
+				Msg("Insufficient balance for withdrawal")
+			return nil, models.ErrInsufficientBalance
+		}
+		newBalance = account.Balance.Sub(amount)
+		if !s.zeroPolicy.allowsDebit(account, newBalance) {
+			logging.FromContext(ctx).Debug().
+				Int64("accountID", accountID).
+				Str("accountType", account.AccountType).
+				Msg("Withdrawal would leave account at zero, which its account type forbids")
+			return nil, models.ErrInsufficientBalance
+		}
+	}
+
+	if err := s.accountRepo.UpdateBalance(ctx, tx, accountID, newBalance); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to update balance", err)
+	}
+	if err := s.transactionRepo.Create(ctx, tx, &entry); err != nil {
+		if _, ok := models.IsDomainError(err); ok {
+			return nil, err
+		}
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to create ledger entry", err)
+	}
+	if err := postLedgerEntries(ctx, s.transactionRepo, tx, &entry); err != nil {
+		return nil, err
+	}
+	if err := recordBalanceChanges(ctx, s.accountRepo, tx, &models.BalanceChange{
+		AccountID: accountID, OldBalance: account.Balance, NewBalance: newBalance, TransactionID: &entry.TransactionID,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to commit transaction", err)
+	}
+
+	logging.FromContext(ctx).Info().
+		Int64("transactionID", entry.TransactionID).
+		Int64("accountID", accountID).
+		Str("type", string(kind)).
+		Str("amount", amount.String()).
+		Msg("Ledger entry recorded")
+
+	return &entry, nil
+}
+
+// lookupIdempotent returns the entry already recorded under draft's idempotency key,
+// marked Replayed, or nil if there is none. The key is shared with transfers, so it
+// fails with ErrIdempotencyKeyConflict unless the earlier request was the same kind of
+// entry on the same account for the same amount.
+func (s *LedgerService) lookupIdempotent(ctx context.Context, draft *models.Transaction) (*models.Transaction, error) {
+	existing, err := s.transactionRepo.GetByIdempotencyKey(ctx, draft.IdempotencyKey)
+	if errors.Is(err, models.ErrTransferNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to look up idempotency key", err)
+	}
+
+	if existing.Type != draft.Type ||
+		existing.SourceAccountID != draft.SourceAccountID ||
+		existing.DestinationAccountID != draft.DestinationAccountID ||
+		!existing.Amount.Equal(draft.Amount) {
+		logging.FromContext(ctx).Debug().
+			Str("idempotencyKey", draft.IdempotencyKey).
+			Int64("transactionID", existing.TransactionID).
+			Msg("Idempotency key reused with a different request")
+		return nil, models.ErrIdempotencyKeyConflict
+	}
+
+	logging.FromContext(ctx).Info().
+		Str("idempotencyKey", draft.IdempotencyKey).
+		Int64("transactionID", existing.TransactionID).
+		Msg("Replaying ledger entry for repeated idempotency key")
+
+	existing.Replayed = true
+	return existing, nil
+}
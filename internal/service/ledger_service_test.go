@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func newLedgerTestService() (*LedgerService, *mocks.MockAccountRepository, *mocks.MockTransactionRepository) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0), MaxBalance: decimal.NewNullDecimal(decimal.NewFromInt(50))})
+	closedAt := time.Now()
+	accRepo.SetAccount(&models.Account{AccountID: 3, Balance: decimal.NewFromInt(20), ClosedAt: &closedAt})
+	txnRepo := mocks.NewMockTransactionRepository()
+	return NewLedgerService(accRepo, txnRepo), accRepo, txnRepo
+}
+
+func TestLedgerService_Deposit(t *testing.T) {
+	svc, accRepo, txnRepo := newLedgerTestService()
+
+	entry, err := svc.Deposit(context.Background(), 1, &models.LedgerEntryRequest{Amount: "25.5"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Type != models.TransactionTypeDeposit || entry.DestinationAccountID != 1 || entry.SourceAccountID != 0 {
+		t.Errorf("expected a deposit into account 1, got %+v", entry)
+	}
+	if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "125.5" {
+		t.Errorf("expected 125.5, got %s", acc.Balance)
+	}
+	if stored, err := txnRepo.GetByID(context.Background(), entry.TransactionID); err != nil || stored.Type != models.TransactionTypeDeposit {
+		t.Errorf("expected the deposit to be stored, got %+v err=%v", stored, err)
+	}
+}
+
+func TestLedgerService_Withdraw(t *testing.T) {
+	svc, accRepo, _ := newLedgerTestService()
+
+	entry, err := svc.Withdraw(context.Background(), 1, &models.LedgerEntryRequest{Amount: "40"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Type != models.TransactionTypeWithdrawal || entry.SourceAccountID != 1 || entry.DestinationAccountID != 0 {
+		t.Errorf("expected a withdrawal from account 1, got %+v", entry)
+	}
+	if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "60" {
+		t.Errorf("expected 60, got %s", acc.Balance)
+	}
+}
+
+func TestLedgerService_Rejections(t *testing.T) {
+	tests := []struct {
+		name      string
+		apply     func(*LedgerService) (*models.Transaction, error)
+		accountID int64
+		wantErr   error
+	}{
+		{"withdrawal exceeds balance", func(s *LedgerService) (*models.Transaction, error) {
+			return s.Withdraw(context.Background(), 1, &models.LedgerEntryRequest{Amount: "100.01"})
+		}, 1, models.ErrInsufficientBalance},
+		{"deposit exceeds max balance", func(s *LedgerService) (*models.Transaction, error) {
+			return s.Deposit(context.Background(), 2, &models.LedgerEntryRequest{Amount: "51"})
+		}, 2, models.ErrDestinationBalanceLimit},
+		{"non-positive amount", func(s *LedgerService) (*models.Transaction, error) {
+			return s.Deposit(context.Background(), 1, &models.LedgerEntryRequest{Amount: "0"})
+		}, 1, models.ErrInvalidAmount},
+		{"unknown account", func(s *LedgerService) (*models.Transaction, error) {
+			return s.Withdraw(context.Background(), 9, &models.LedgerEntryRequest{Amount: "1"})
+		}, 9, models.ErrAccountNotFound},
+		{"deposit into closed account", func(s *LedgerService) (*models.Transaction, error) {
+			return s.Deposit(context.Background(), 3, &models.LedgerEntryRequest{Amount: "1"})
+		}, 3, models.ErrAccountClosed},
+		{"withdrawal from closed account", func(s *LedgerService) (*models.Transaction, error) {
+			return s.Withdraw(context.Background(), 3, &models.LedgerEntryRequest{Amount: "1"})
+		}, 3, models.ErrAccountClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, accRepo, txnRepo := newLedgerTestService()
+			var before string
+			if acc, ok := accRepo.GetAccount(tt.accountID); ok {
+				before = acc.Balance.String()
+			}
+
+			_, err := tt.apply(svc)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if acc, ok := accRepo.GetAccount(tt.accountID); ok && acc.Balance.String() != before {
+				t.Errorf("expected balance to stay %s, got %s", before, acc.Balance)
+			}
+			if txns, _ := txnRepo.GetByAccountID(context.Background(), tt.accountID, 10, 0); len(txns) != 0 {
+				t.Errorf("expected no ledger entry, got %d", len(txns))
+			}
+		})
+	}
+}
+
+func TestLedgerService_WithdrawZeroBalancePolicy(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, AccountType: "escrow", Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, AccountType: models.DefaultAccountType, Balance: decimal.NewFromInt(100)})
+	svc := NewLedgerServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), LedgerServiceConfig{
+		ForbidZeroBalanceTypes: []string{"escrow"},
+	})
+
+	if _, err := svc.Withdraw(context.Background(), 1, &models.LedgerEntryRequest{Amount: "100"}); !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Errorf("escrow: expected ErrInsufficientBalance, got %v", err)
+	}
+	if _, err := svc.Withdraw(context.Background(), 2, &models.LedgerEntryRequest{Amount: "100"}); err != nil {
+		t.Errorf("standard: expected full withdrawal to succeed, got %v", err)
+	}
+}
+
+func TestLedgerService_IdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	svc, accRepo, txnRepo := newLedgerTestService()
+
+	first, err := svc.Deposit(ctx, 1, &models.LedgerEntryRequest{Amount: "10", IdempotencyKey: "dep-1"})
+	if err != nil {
+		t.Fatalf("first deposit: %v", err)
+	}
+	replay, err := svc.Deposit(ctx, 1, &models.LedgerEntryRequest{Amount: "10.00", IdempotencyKey: "dep-1"})
+	if err != nil {
+		t.Fatalf("replayed deposit: %v", err)
+	}
+	if !replay.Replayed || replay.TransactionID != first.TransactionID {
+		t.Errorf("expected a replay of transaction %d, got %+v", first.TransactionID, replay)
+	}
+	if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "110" {
+		t.Errorf("expected a single credit to 110, got %s", acc.Balance)
+	}
+	if history := accRepo.BalanceHistory(); len(history) != 1 {
+		t.Errorf("expected one balance change, got %d", len(history))
+	}
+
+	// Keys are shared with transfers
+	if _, err := NewTransferService(accRepo, txnRepo).Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "5", IdempotencyKey: "xfer-1",
+	}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	conflicts := []struct {
+		name  string
+		apply func() (*models.Transaction, error)
+	}{
+		{"different amount", func() (*models.Transaction, error) {
+			return svc.Deposit(ctx, 1, &models.LedgerEntryRequest{Amount: "11", IdempotencyKey: "dep-1"})
+		}},
+		{"different account", func() (*models.Transaction, error) {
+			return svc.Deposit(ctx, 2, &models.LedgerEntryRequest{Amount: "10", IdempotencyKey: "dep-1"})
+		}},
+		{"withdrawal with a deposit's key", func() (*models.Transaction, error) {
+			return svc.Withdraw(ctx, 1, &models.LedgerEntryRequest{Amount: "10", IdempotencyKey: "dep-1"})
+		}},
+		{"withdrawal with a transfer's key", func() (*models.Transaction, error) {
+			return svc.Withdraw(ctx, 1, &models.LedgerEntryRequest{Amount: "5", IdempotencyKey: "xfer-1"})
+		}},
+	}
+	for _, tt := range conflicts {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.apply(); !errors.Is(err, models.ErrIdempotencyKeyConflict) {
+				t.Errorf("expected ErrIdempotencyKeyConflict, got %v", err)
+			}
+		})
+	}
+	if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "105" {
+		t.Errorf("expected conflicts to leave the balance at 105, got %s", acc.Balance)
+	}
+}
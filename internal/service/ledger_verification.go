@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+)
+
+// MaxDriftedAccounts bounds the drifted accounts VerifyLedger lists.
+const MaxDriftedAccounts = 100
+
+// VerifyLedger checks that account balances agree with the recorded history: in total,
+// the balances should equal the initial balances plus completed deposits, less
+// completed withdrawals, plus balance adjustments; per account, the initial balance plus
+// its ledger entries and adjustments. It only reads, from a single snapshot, and logs a
+// warning when it finds drift.
+func (s *TransferService) VerifyLedger(ctx context.Context) (*models.LedgerVerification, error) {
+	v, err := s.transactionRepo.VerifyLedger(ctx, MaxDriftedAccounts)
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to verify ledger", err)
+	}
+
+	if !v.Consistent() {
+		logging.FromContext(ctx).Warn().
+			Str("totalBalance", v.TotalBalance.String()).
+			Str("expected", v.Expected().String()).
+			Str("drift", v.Drift().String()).
+			Int("driftedAccounts", len(v.DriftedAccounts)).
+			Msg("Ledger inconsistent")
+	}
+	return v, nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultReconciliationReason is the adjustment reason Reconcile records when the caller
+// gives none.
+const DefaultReconciliationReason = "reconciliation"
+
+// Reconcile compares each account's stored balance with its expected balance, in one
+// database transaction, and reports the difference per account. Accounts that do not
+// exist are reported as missing rather than failing the import.
+//
+// Without apply nothing is written: every difference is logged and reported as
+// mismatched. With apply each mismatched account is set to its expected balance through a
+// balance adjustment, all sharing one batch ID and reason, and reported as corrected.
+// Corrections are all or nothing, with the same checks as AdjustBalancesBatch. Importing
+// the same balances again finds them matched, so a retried import changes nothing.
+//
+// At most MaxAdjustmentBatchSize accounts may be imported at once, each listed once. When
+// applying, accounts are locked in ascending ID order, as transfers lock them.
+func (s *AccountService) Reconcile(ctx context.Context, expected []models.ExpectedBalance, apply bool, reason string) (*models.Reconciliation, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = DefaultReconciliationReason
+	}
+	if len(expected) == 0 || len(expected) > MaxAdjustmentBatchSize {
+		return nil, models.ErrInvalidAdjustment
+	}
+
+	sorted := append([]models.ExpectedBalance(nil), expected...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].AccountID < sorted[j].AccountID })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].AccountID == sorted[i-1].AccountID {
+			return nil, models.ErrInvalidAdjustment
+		}
+	}
+
+	tx, err := s.accountRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, beginTxError(err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	log := logging.FromContext(ctx)
+	report := &models.Reconciliation{Applied: apply, Reason: reason, Items: make([]*models.ReconciliationItem, len(sorted))}
+	var (
+		deltas      []models.BalanceDelta
+		newBalances []decimal.Decimal
+		corrected   []*models.ReconciliationItem
+	)
+	for i, e := range sorted {
+		item := &models.ReconciliationItem{AccountID: e.AccountID, Expected: e.Balance}
+		report.Items[i] = item
+
+		// A detect-only import doesn't need to hold up transfers with row locks
+		var account *models.Account
+		if apply {
+			account, err = s.accountRepo.GetByIDForUpdate(ctx, tx, e.AccountID)
+		} else {
+			account, err = s.accountRepo.GetByIDInTx(ctx, tx, e.AccountID)
+		}
+		if errors.Is(err, models.ErrAccountNotFound) {
+			item.Status = models.ReconciliationMissing
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		item.Stored = account.Balance
+		if item.Stored.Equal(item.Expected) {
+			item.Status = models.ReconciliationMatched
+			continue
+		}
+
+		log.Warn().
+			Int64("accountID", e.AccountID).
+			Str("stored", item.Stored.String()).
+			Str("expected", item.Expected.String()).
+			Bool("apply", apply).
+			Msg("Balance discrepancy found")
+		item.Status = models.ReconciliationMismatched
+		if !apply {
+			continue
+		}
+
+		delta := item.Difference()
+		newBalance, err := checkAdjustment(ctx, account, delta)
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, models.BalanceDelta{AccountID: e.AccountID, Delta: delta})
+		newBalances = append(newBalances, newBalance)
+		corrected = append(corrected, item)
+	}
+
+	if len(deltas) > 0 {
+		adjustments, err := s.applyAdjustments(ctx, tx, deltas, newBalances, reason)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, models.WrapError(models.CodeDatabaseError, "failed to commit transaction", err)
+		}
+		for i, item := range corrected {
+			item.Status = models.ReconciliationCorrected
+			item.Adjustment = adjustments[i]
+		}
+		report.BatchID = adjustments[0].BatchID
+	}
+
+	log.Info().
+		Int("accounts", len(report.Items)).
+		Int("matched", report.Count(models.ReconciliationMatched)).
+		Int("mismatched", report.Count(models.ReconciliationMismatched)).
+		Int("corrected", report.Count(models.ReconciliationCorrected)).
+		Int("missing", report.Count(models.ReconciliationMissing)).
+		Int64("batchID", report.BatchID).
+		Msg("Balance reconciliation completed")
+
+	return report, nil
+}
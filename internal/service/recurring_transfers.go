@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+)
+
+// CatchUpPolicy decides what the scheduler does with runs that fell due while it wasn't
+// running, e.g. during an outage.
+type CatchUpPolicy string
+
+const (
+	// CatchUpOnce makes a single run for all the missed slots, then resumes the schedule
+	// at its first slot after now.
+	CatchUpOnce CatchUpPolicy = "once"
+
+	// CatchUpAll makes one run for every missed slot, oldest first.
+	CatchUpAll CatchUpPolicy = "all"
+)
+
+// DefaultRecurringBatchSize bounds the schedules RunDueRecurring takes on per call when
+// RecurringBatchSize is unset.
+const DefaultRecurringBatchSize = 100
+
+// CreateRecurring stores an active schedule for recurring. A zero NextRunAt starts it
+// now; sub-second parts are dropped so every slot lands on a whole second. It fails with
+// ErrAccountNotFound if either account doesn't exist, and with ErrInvalidDateRange if
+// EndAt isn't after the first run.
+func (s *TransferService) CreateRecurring(ctx context.Context, recurring *models.RecurringTransfer) (*models.RecurringTransfer, error) {
+	if recurring.SourceAccountID == recurring.DestinationAccountID {
+		return nil, models.ErrSameAccount
+	}
+	if !recurring.Amount.IsPositive() {
+		return nil, models.ErrInvalidAmount
+	}
+	recurring.Every = recurring.Every.Truncate(time.Second)
+	if recurring.NextRunAt.IsZero() {
+		recurring.NextRunAt = time.Now()
+	}
+	recurring.NextRunAt = recurring.NextRunAt.UTC().Truncate(time.Second)
+	if recurring.EndAt != nil && !recurring.EndAt.After(recurring.NextRunAt) {
+		return nil, models.NewDomainError(models.CodeInvalidDateRange, "end_at must be after the first run")
+	}
+
+	for _, accountID := range []int64{recurring.SourceAccountID, recurring.DestinationAccountID} {
+		if err := s.ensureAccountExists(ctx, accountID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.transactionRepo.CreateRecurring(ctx, recurring); err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to create recurring transfer", err)
+	}
+
+	logging.FromContext(ctx).Info().
+		Int64("recurringID", recurring.RecurringID).
+		Int64("sourceAccountID", recurring.SourceAccountID).
+		Int64("destAccountID", recurring.DestinationAccountID).
+		Str("amount", recurring.Amount.String()).
+		Dur("every", recurring.Every).
+		Time("nextRunAt", recurring.NextRunAt).
+		Msg("Recurring transfer created")
+
+	return recurring, nil
+}
+
+// GetRecurring returns a recurring transfer, or ErrRecurringTransferNotFound.
+func (s *TransferService) GetRecurring(ctx context.Context, recurringID int64) (*models.RecurringTransfer, error) {
+	recurring, err := s.transactionRepo.GetRecurring(ctx, recurringID)
+	if err != nil && !errors.Is(err, models.ErrRecurringTransferNotFound) {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to get recurring transfer", err)
+	}
+	return recurring, err
+}
+
+// CancelRecurring stops an active recurring transfer; no further runs are made. A run the
+// scheduler already started may still complete. It fails with
+// ErrRecurringTransferNotFound, or ErrRecurringTransferNotActive if the schedule already
+// completed or was canceled.
+func (s *TransferService) CancelRecurring(ctx context.Context, recurringID int64) (*models.RecurringTransfer, error) {
+	recurring, err := s.transactionRepo.CancelRecurring(ctx, recurringID)
+	if err != nil {
+		if _, ok := models.IsDomainError(err); ok {
+			return nil, err
+		}
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to cancel recurring transfer", err)
+	}
+
+	logging.FromContext(ctx).Info().Int64("recurringID", recurringID).Msg("Recurring transfer canceled")
+	return recurring, nil
+}
+
+// RunRecurring calls RunDueRecurring every interval until ctx is cancelled. A run under
+// way when ctx is cancelled is finished first, so once RunRecurring returns no transfer
+// of the scheduler's is still using the database.
+func (s *TransferService) RunRecurring(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunDueRecurring(ctx, time.Now()); err != nil {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to list due recurring transfers")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDueRecurring makes the runs due at now, for up to RecurringBatchSize schedules, and
+// returns how many runs it made. Each run is an ordinary transfer keyed by its schedule
+// and slot, so a slot retried after a crash, or picked up by two schedulers at once, is
+// applied once. Schedules still due after the batch are left for the next call.
+//
+// A run rejected on its merits, e.g. for insufficient balance, is recorded in the
+// schedule's LastError and the schedule moves on. A run that failed for a system reason,
+// or was refused by a transfer blackout, leaves the slot due, to be retried on the next
+// call.
+func (s *TransferService) RunDueRecurring(ctx context.Context, now time.Time) (int, error) {
+	limit := s.config.RecurringBatchSize
+	if limit <= 0 {
+		limit = DefaultRecurringBatchSize
+	}
+	due, err := s.transactionRepo.ListDueRecurring(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	runs := 0
+	for _, recurring := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		runs += s.runRecurring(ctx, recurring, now)
+	}
+	return runs, nil
+}
+
+// runRecurring makes recurring's runs due at now, under the catch-up policy, and returns
+// how many it made.
+func (s *TransferService) runRecurring(ctx context.Context, recurring *models.RecurringTransfer, now time.Time) (runs int) {
+	logger := logging.FromContext(ctx).With().Int64("recurringID", recurring.RecurringID).Logger()
+	// A transfer under way is finished even if the scheduler is stopped meanwhile
+	runCtx := logging.WithLogger(context.WithoutCancel(ctx), logger)
+
+	for recurring.Status == models.RecurringStatusActive && !recurring.NextRunAt.After(now) && ctx.Err() == nil {
+		slot := recurring.NextRunAt
+		txn, err := s.Transfer(runCtx, &models.CreateTransactionRequest{
+			SourceAccountID:      recurring.SourceAccountID,
+			DestinationAccountID: recurring.DestinationAccountID,
+			Amount:               recurring.Amount.String(),
+			Category:             recurring.Category,
+			IdempotencyKey:       recurringIdempotencyKey(recurring.RecurringID, slot),
+		})
+		if err != nil && !runRejected(err) {
+			logger.Error().Err(err).Time("slot", slot).Msg("Recurring transfer run failed, will retry")
+			return runs
+		}
+
+		runs++
+		ranAt := time.Now()
+		recurring.Runs++
+		recurring.LastRunAt = &ranAt
+		recurring.LastTransactionID, recurring.LastError = nil, ""
+		if err != nil {
+			recurring.LastError = err.Error()
+			logger.Warn().Err(err).Time("slot", slot).Msg("Recurring transfer run rejected")
+		} else {
+			recurring.LastTransactionID = &txn.TransactionID
+		}
+		recurring.NextRunAt = nextRecurringRun(slot, recurring.Every, now, s.config.RecurringCatchUp)
+		if recurring.EndAt != nil && recurring.NextRunAt.After(*recurring.EndAt) {
+			recurring.Status = models.RecurringStatusCompleted
+		}
+
+		if err := s.transactionRepo.AdvanceRecurring(runCtx, recurring, slot); err != nil {
+			if errors.Is(err, models.ErrRecurringTransferNotActive) {
+				logger.Info().Time("slot", slot).Msg("Recurring transfer canceled or advanced elsewhere during its run")
+			} else {
+				logger.Error().Err(err).Time("slot", slot).Msg("Failed to advance recurring transfer")
+			}
+			return runs
+		}
+
+		if recurring.Status == models.RecurringStatusCompleted {
+			logger.Info().Int("runs", recurring.Runs).Msg("Recurring transfer completed")
+		}
+	}
+	return runs
+}
+
+// nextRecurringRun returns the slot after a run for slot, given it ran at now. Under
+// CatchUpAll that is simply the following slot, even if it is already past. Otherwise
+// slots at or before now are skipped, so one run covers every slot missed so far.
+func nextRecurringRun(slot time.Time, every time.Duration, now time.Time, policy CatchUpPolicy) time.Time {
+	next := slot.Add(every)
+	if policy == CatchUpAll || next.After(now) {
+		return next
+	}
+	missed := now.Sub(next)/every + 1
+	return next.Add(missed * every)
+}
+
+// recurringIdempotencyKey is the idempotency key of a schedule's run for slot.
+func recurringIdempotencyKey(recurringID int64, slot time.Time) string {
+	return fmt.Sprintf("recurring:%d:%d", recurringID, slot.Unix())
+}
+
+// runRejected reports whether err means a run was refused on its merits, e.g. for an
+// insufficient balance, so retrying the slot would only be refused again. Anything else,
+// including a COMMIT whose outcome is unknown or a transfer blackout, leaves the slot to
+// be retried: its idempotency key keeps a retry from applying it twice.
+func runRejected(err error) bool {
+	var ce *commitError
+	if errors.As(err, &ce) || errors.Is(err, models.ErrTransferBlackout) {
+		return false
+	}
+	_, ok := models.IsDomainError(err)
+	return ok && !failedForSystemReason(err)
+}
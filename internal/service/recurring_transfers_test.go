@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNextRecurringRun(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	tests := []struct {
+		name   string
+		slot   time.Time
+		now    time.Time
+		policy CatchUpPolicy
+		want   time.Time
+	}{
+		{"on time", start, start.Add(time.Second), CatchUpOnce, start.Add(day)},
+		{"on time, catch up all", start, start.Add(time.Second), CatchUpAll, start.Add(day)},
+		{"missed slots skipped", start, start.Add(3*day + time.Hour), CatchUpOnce, start.Add(4 * day)},
+		{"missed slot due exactly now is skipped", start, start.Add(3 * day), CatchUpOnce, start.Add(4 * day)},
+		{"missed slots run one by one", start, start.Add(3*day + time.Hour), CatchUpAll, start.Add(day)},
+		{"empty policy skips", start, start.Add(2*day + time.Hour), "", start.Add(3 * day)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextRecurringRun(tt.slot, day, tt.now, tt.policy); !got.Equal(tt.want) {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func newRecurringTestService(t *testing.T, policy CatchUpPolicy, balance int64) (*TransferService, *mocks.MockAccountRepository, *mocks.MockTransactionRepository) {
+	t.Helper()
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(balance)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+	txnRepo := mocks.NewMockTransactionRepository()
+	config := DefaultTransferConfig()
+	config.RetryBaseDelay = time.Millisecond
+	config.RecurringCatchUp = policy
+	return NewTransferServiceWithConfig(accRepo, txnRepo, config), accRepo, txnRepo
+}
+
+func createRecurring(t *testing.T, svc *TransferService, startAt time.Time, endAt *time.Time) *models.RecurringTransfer {
+	t.Helper()
+	recurring, err := svc.CreateRecurring(context.Background(), &models.RecurringTransfer{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10), Every: time.Hour, NextRunAt: startAt, EndAt: endAt,
+	})
+	if err != nil {
+		t.Fatalf("create recurring transfer: %v", err)
+	}
+	return recurring
+}
+
+func TestTransferService_RunDueRecurring(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("runs due schedule and advances it", func(t *testing.T) {
+		svc, accRepo, _ := newRecurringTestService(t, CatchUpOnce, 100)
+		created := createRecurring(t, svc, now.Add(-3*time.Hour-time.Minute), nil)
+
+		runs, err := svc.RunDueRecurring(context.Background(), now)
+		if err != nil || runs != 1 {
+			t.Fatalf("expected one run, got %d, %v", runs, err)
+		}
+		got, _ := svc.GetRecurring(context.Background(), created.RecurringID)
+		if got.Runs != 1 || got.LastTransactionID == nil || got.LastError != "" || got.LastRunAt == nil {
+			t.Errorf("unexpected schedule after run: %+v", got)
+		}
+		if want := now.Add(59 * time.Minute); !got.NextRunAt.Equal(want) {
+			t.Errorf("expected missed slots skipped to %s, got %s", want, got.NextRunAt)
+		}
+		dst, _ := accRepo.GetAccount(2)
+		if dst.Balance.String() != "10" {
+			t.Errorf("expected destination balance 10, got %s", dst.Balance)
+		}
+
+		if runs, _ := svc.RunDueRecurring(context.Background(), now); runs != 0 {
+			t.Errorf("expected nothing due until the next slot, got %d runs", runs)
+		}
+	})
+
+	t.Run("catch up all runs every missed slot", func(t *testing.T) {
+		svc, accRepo, _ := newRecurringTestService(t, CatchUpAll, 100)
+		created := createRecurring(t, svc, now.Add(-3*time.Hour-time.Minute), nil)
+
+		if runs, err := svc.RunDueRecurring(context.Background(), now); err != nil || runs != 4 {
+			t.Fatalf("expected four runs, got %d, %v", runs, err)
+		}
+		got, _ := svc.GetRecurring(context.Background(), created.RecurringID)
+		if got.Runs != 4 || !got.NextRunAt.Equal(now.Add(59*time.Minute)) {
+			t.Errorf("unexpected schedule after catching up: %+v", got)
+		}
+		dst, _ := accRepo.GetAccount(2)
+		if dst.Balance.String() != "40" {
+			t.Errorf("expected destination balance 40, got %s", dst.Balance)
+		}
+	})
+
+	t.Run("completes at end_at", func(t *testing.T) {
+		svc, _, _ := newRecurringTestService(t, CatchUpAll, 100)
+		endAt := now.Add(-40 * time.Minute)
+		created := createRecurring(t, svc, now.Add(-90*time.Minute), &endAt)
+
+		if runs, _ := svc.RunDueRecurring(context.Background(), now); runs != 1 {
+			t.Fatalf("expected only the slot before end_at to run, got %d runs", runs)
+		}
+		got, _ := svc.GetRecurring(context.Background(), created.RecurringID)
+		if got.Status != models.RecurringStatusCompleted || got.Runs != 1 {
+			t.Errorf("expected a completed schedule, got %+v", got)
+		}
+	})
+
+	t.Run("rejected run is recorded and skipped", func(t *testing.T) {
+		svc, _, _ := newRecurringTestService(t, CatchUpOnce, 5)
+		created := createRecurring(t, svc, now.Add(-time.Minute), nil)
+
+		if runs, _ := svc.RunDueRecurring(context.Background(), now); runs != 1 {
+			t.Fatalf("expected one run, got %d", runs)
+		}
+		got, _ := svc.GetRecurring(context.Background(), created.RecurringID)
+		if got.Status != models.RecurringStatusActive || got.LastTransactionID != nil || got.LastError == "" {
+			t.Errorf("expected the rejection recorded, got %+v", got)
+		}
+		if !got.NextRunAt.After(now) {
+			t.Errorf("expected the schedule to move past the rejected slot, got %s", got.NextRunAt)
+		}
+	})
+
+	t.Run("system failure leaves the slot due", func(t *testing.T) {
+		svc, _, txnRepo := newRecurringTestService(t, CatchUpOnce, 100)
+		created := createRecurring(t, svc, now.Add(-time.Minute), nil)
+		txnRepo.CreateError = errors.New("connection reset")
+
+		if runs, _ := svc.RunDueRecurring(context.Background(), now); runs != 0 {
+			t.Fatalf("expected no completed runs, got %d", runs)
+		}
+		got, _ := svc.GetRecurring(context.Background(), created.RecurringID)
+		if got.Runs != 0 || !got.NextRunAt.Equal(created.NextRunAt) {
+			t.Errorf("expected the slot to stay due, got %+v", got)
+		}
+
+		txnRepo.CreateError = nil
+		if runs, _ := svc.RunDueRecurring(context.Background(), now); runs != 1 {
+			t.Errorf("expected the slot to be retried, got %d runs", runs)
+		}
+	})
+}
+
+func TestTransferService_CancelRecurring(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	svc, accRepo, _ := newRecurringTestService(t, CatchUpOnce, 100)
+	created := createRecurring(t, svc, now.Add(-time.Minute), nil)
+
+	canceled, err := svc.CancelRecurring(context.Background(), created.RecurringID)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if canceled.Status != models.RecurringStatusCanceled || canceled.CanceledAt == nil {
+		t.Errorf("unexpected canceled schedule %+v", canceled)
+	}
+
+	if runs, _ := svc.RunDueRecurring(context.Background(), now); runs != 0 {
+		t.Errorf("expected a canceled schedule not to run, got %d runs", runs)
+	}
+	src, _ := accRepo.GetAccount(1)
+	if src.Balance.String() != "100" {
+		t.Errorf("expected source balance 100, got %s", src.Balance)
+	}
+
+	if _, err := svc.CancelRecurring(context.Background(), created.RecurringID); !errors.Is(err, models.ErrRecurringTransferNotActive) {
+		t.Errorf("cancel again: expected ErrRecurringTransferNotActive, got %v", err)
+	}
+	if _, err := svc.CancelRecurring(context.Background(), 999); !errors.Is(err, models.ErrRecurringTransferNotFound) {
+		t.Errorf("cancel unknown: expected ErrRecurringTransferNotFound, got %v", err)
+	}
+}
+
+func TestTransferService_CreateRecurring(t *testing.T) {
+	svc, _, _ := newRecurringTestService(t, CatchUpOnce, 100)
+	now := time.Now()
+
+	past := now.Add(-time.Hour)
+	_, err := svc.CreateRecurring(context.Background(), &models.RecurringTransfer{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10), Every: time.Hour, EndAt: &past,
+	})
+	if !errors.Is(err, models.ErrInvalidDateRange) {
+		t.Errorf("end_at before the first run: expected ErrInvalidDateRange, got %v", err)
+	}
+
+	_, err = svc.CreateRecurring(context.Background(), &models.RecurringTransfer{
+		SourceAccountID: 1, DestinationAccountID: 3, Amount: decimal.NewFromInt(10), Every: time.Hour,
+	})
+	if !errors.Is(err, models.ErrAccountNotFound) {
+		t.Errorf("unknown destination: expected ErrAccountNotFound, got %v", err)
+	}
+
+	created, err := svc.CreateRecurring(context.Background(), &models.RecurringTransfer{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10), Every: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.Status != models.RecurringStatusActive || created.NextRunAt.Nanosecond() != 0 || created.NextRunAt.After(now) {
+		t.Errorf("expected an active schedule due now on a whole second, got %+v", created)
+	}
+}
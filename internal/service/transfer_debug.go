@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"internal-transfers-system/internal/models"
+)
+
+type transferDebugKey struct{}
+
+// WithTransferDebug returns a context that asks Transfer to record how it executed into
+// the returned TransferDebug. Collecting the isolation level costs an extra query per
+// attempt, so only do this when the caller will report it.
+func WithTransferDebug(ctx context.Context) (context.Context, *models.TransferDebug) {
+	debug := &models.TransferDebug{}
+	return context.WithValue(ctx, transferDebugKey{}, debug), debug
+}
+
+func transferDebugFrom(ctx context.Context) *models.TransferDebug {
+	debug, _ := ctx.Value(transferDebugKey{}).(*models.TransferDebug)
+	return debug
+}
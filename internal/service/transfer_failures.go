@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+)
+
+// failedTransferRecordTimeout bounds recording a failed transfer. The request's own
+// context may already be done, e.g. when its deadline caused the failure.
+const failedTransferRecordTimeout = 5 * time.Second
+
+// failedForSystemReason reports whether err means the transfer broke rather than was
+// rejected: a database or internal error, or retries ran out. Rejections such as an
+// insufficient balance or an unknown account aren't failures. Neither is a COMMIT error
+// other than a serialization failure, since the transfer may have been applied.
+func failedForSystemReason(err error) bool {
+	var ce *commitError
+	if errors.As(err, &ce) && !models.IsSerializationFailure(ce.err) {
+		return false
+	}
+	code, ok := models.IsDomainError(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case models.CodeDatabaseError, models.CodeTransactionFailed, models.CodeInternalError:
+		return true
+	default:
+		return false
+	}
+}
+
+// recordFailedTransfer stores draft as a failed transaction in a database transaction of
+// its own, so the attempt stays on record after the transfer itself rolled back. The
+// idempotency key is left off, keeping it free for the client's retry. Errors are only
+// logged: the caller is already returning cause.
+func (s *TransferService) recordFailedTransfer(ctx context.Context, draft *models.Transaction, cause error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), failedTransferRecordTimeout)
+	defer cancel()
+
+	tx, err := s.accountRepo.BeginTx(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error().Err(err).Msg("Failed to begin transaction for failed transfer record")
+		return
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
+		}
+	}()
+
+	failed := *draft
+	failed.IdempotencyKey = ""
+	failed.Status = models.TransactionStatusFailed
+	if err := s.transactionRepo.Create(ctx, tx, &failed); err != nil {
+		logging.FromContext(ctx).Error().Err(err).Msg("Failed to record failed transfer")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		logging.FromContext(ctx).Error().Err(err).Msg("Failed to commit failed transfer record")
+		return
+	}
+
+	logging.FromContext(ctx).Warn().
+		Err(cause).
+		Int64("transactionID", failed.TransactionID).
+		Int64("sourceAccountID", failed.SourceAccountID).
+		Int64("destAccountID", failed.DestinationAccountID).
+		Str("amount", failed.Amount.String()).
+		Msg("Recorded failed transfer")
+}
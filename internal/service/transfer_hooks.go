@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TransferHook lets cross-cutting concerns (audit, outbox, metrics, webhooks) run at
+// transfer boundaries without each editing executeTransfer.
+//
+// PreCommit runs inside the database transaction after balances are updated and the
+// transaction record is inserted, so txn.TransactionID is set. Writes made through tx
+// commit or roll back with the transfer. Returning an error aborts the transfer.
+//
+// PostCommit runs after a successful commit. The transfer can no longer be undone, so
+// it has no error return; hooks must handle their own failures.
+type TransferHook interface {
+	PreCommit(ctx context.Context, tx pgx.Tx, txn *models.Transaction) error
+	PostCommit(ctx context.Context, txn *models.Transaction)
+}
+
+// AddHook registers a hook. Hooks run in registration order.
+// Register hooks during startup; the hook list is not safe to modify concurrently with transfers.
+func (s *TransferService) AddHook(hook TransferHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+func (s *TransferService) runPreCommitHooks(ctx context.Context, tx pgx.Tx, txn *models.Transaction) error {
+	for _, hook := range s.hooks {
+		if err := hook.PreCommit(ctx, tx, txn); err != nil {
+			if _, ok := models.IsDomainError(err); ok {
+				return err
+			}
+			return models.WrapError(models.CodeInternalError, "pre-commit hook failed", err)
+		}
+	}
+	return nil
+}
+
+func (s *TransferService) runPostCommitHooks(ctx context.Context, txn *models.Transaction) {
+	for _, hook := range s.hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logging.FromContext(ctx).Error().
+						Interface("panic", r).
+						Int64("transactionID", txn.TransactionID).
+						Msg("Panic recovered in post-commit transfer hook")
+				}
+			}()
+			hook.PostCommit(ctx, txn)
+		}()
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+type recordingHook struct {
+	name      string
+	events    *[]string
+	preErr    error
+	postPanic bool
+}
+
+func (h *recordingHook) PreCommit(_ context.Context, tx pgx.Tx, txn *models.Transaction) error {
+	if tx == nil || txn.TransactionID == 0 {
+		*h.events = append(*h.events, h.name+":pre:invalid")
+	}
+	*h.events = append(*h.events, h.name+":pre")
+	return h.preErr
+}
+
+func (h *recordingHook) PostCommit(_ context.Context, txn *models.Transaction) {
+	*h.events = append(*h.events, h.name+":post")
+	if h.postPanic {
+		panic("boom")
+	}
+}
+
+func newHookTestService() *TransferService {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	return NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+}
+
+var hookTestRequest = &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100"}
+
+func TestTransferHooks_Order(t *testing.T) {
+	svc := newHookTestService()
+	var events []string
+	svc.AddHook(&recordingHook{name: "a", events: &events})
+	svc.AddHook(&recordingHook{name: "b", events: &events, postPanic: true})
+	svc.AddHook(&recordingHook{name: "c", events: &events})
+
+	if _, err := svc.Transfer(context.Background(), hookTestRequest); err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+
+	want := []string{"a:pre", "b:pre", "c:pre", "a:post", "b:post", "c:post"}
+	if len(events) != len(want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, events)
+		}
+	}
+}
+
+func TestTransferHooks_PreCommitErrorAborts(t *testing.T) {
+	t.Run("plain error wrapped as internal", func(t *testing.T) {
+		svc := newHookTestService()
+		var events []string
+		svc.AddHook(&recordingHook{name: "a", events: &events, preErr: errors.New("outbox full")})
+		svc.AddHook(&recordingHook{name: "b", events: &events})
+
+		_, err := svc.Transfer(context.Background(), hookTestRequest)
+		if code, _ := models.IsDomainError(err); code != models.CodeInternalError {
+			t.Errorf("expected internal error, got %v", err)
+		}
+		if len(events) != 1 || events[0] != "a:pre" {
+			t.Errorf("expected only a:pre to run, got %v", events)
+		}
+	})
+
+	t.Run("domain error passed through", func(t *testing.T) {
+		svc := newHookTestService()
+		var events []string
+		svc.AddHook(&recordingHook{name: "a", events: &events, preErr: models.ErrInsufficientBalance})
+
+		_, err := svc.Transfer(context.Background(), hookTestRequest)
+		if !errors.Is(err, models.ErrInsufficientBalance) {
+			t.Errorf("expected ErrInsufficientBalance, got %v", err)
+		}
+	})
+}
@@ -9,12 +9,17 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"internal-transfers-system/internal/models"
 	"internal-transfers-system/internal/repository"
 	"internal-transfers-system/internal/testutil"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 var testSuite *testutil.TestContainerSuite
@@ -77,6 +82,196 @@ func TestIntegration_BasicTransfer(t *testing.T) {
 	}
 }
 
+func TestIntegration_TransferRepositorySpans(t *testing.T) {
+	transferSvc, accSvc, _ := setup(t)
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500")
+
+	recorder := tracetest.NewSpanRecorder()
+	orig := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(orig)
+
+	if _, err := transferSvc.Transfer(context.Background(), &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+	}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	var execute sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "TransferService.executeTransfer" {
+			execute = span
+		}
+	}
+	if execute == nil {
+		t.Fatal("missing executeTransfer span")
+	}
+
+	counts := make(map[string]int)
+	for _, span := range recorder.Ended() {
+		if span.Parent().SpanID() == execute.SpanContext().SpanID() {
+			counts[span.Name()]++
+		}
+	}
+	want := map[string]int{
+		"AccountRepository.BeginTx":          1,
+		"AccountRepository.GetByIDForUpdate": 2,
+		"AccountRepository.UpdateBalance":    2,
+		"TransactionRepository.Create":       1,
+	}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("expected %d %s spans under executeTransfer, got %d", n, name, counts[name])
+		}
+	}
+}
+
+func TestIntegration_BalanceHistory(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500")
+
+	var ids []int64
+	for _, amount := range []string{"100", "25.5"} {
+		txn, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+			SourceAccountID: 1, DestinationAccountID: 2, Amount: amount,
+		})
+		if err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+		ids = append(ids, txn.TransactionID)
+	}
+
+	want := map[int64][][2]string{
+		1: {{"874.5", "900"}, {"900", "1000"}},
+		2: {{"625.5", "600"}, {"600", "500"}},
+	}
+	for accountID, balances := range want {
+		history, err := accSvc.GetBalanceHistory(ctx, accountID, 10, 0)
+		if err != nil {
+			t.Fatalf("history for account %d: %v", accountID, err)
+		}
+		if len(history) != 2 {
+			t.Fatalf("account %d: expected 2 history rows, got %d", accountID, len(history))
+		}
+		for i, change := range history {
+			// Newest first, so the second transfer comes first
+			if change.TransactionID == nil || *change.TransactionID != ids[1-i] {
+				t.Errorf("account %d row %d: expected transaction %d, got %+v", accountID, i, ids[1-i], change)
+			}
+			if change.NewBalance.String() != balances[i][0] || change.OldBalance.String() != balances[i][1] {
+				t.Errorf("account %d row %d: expected %s -> %s, got %s -> %s",
+					accountID, i, balances[i][1], balances[i][0], change.OldBalance, change.NewBalance)
+			}
+		}
+
+		acc, _ := accRepo.GetByID(ctx, accountID)
+		if !acc.Balance.Equal(history[0].NewBalance) {
+			t.Errorf("account %d: latest history balance %s diverges from balance %s", accountID, history[0].NewBalance, acc.Balance)
+		}
+	}
+}
+
+func TestIntegration_LedgerEntries(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ledgerSvc := NewLedgerService(accRepo, repository.NewTransactionRepository(testSuite.Pool()))
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500")
+
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+	}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if _, err := ledgerSvc.Deposit(ctx, 1, &models.LedgerEntryRequest{Amount: "40"}); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+
+	// Every account's entries sum to its net movement since opening
+	for accountID, want := range map[int64]string{1: "-60", 2: "100"} {
+		entries, err := transferSvc.GetAccountLedger(ctx, accountID, 10, 0)
+		if err != nil {
+			t.Fatalf("ledger for account %d: %v", accountID, err)
+		}
+		sum := decimal.Zero
+		for _, entry := range entries {
+			sum = sum.Add(entry.Amount)
+		}
+		if sum.String() != want {
+			t.Errorf("account %d: expected entries to sum to %s, got %s", accountID, want, sum)
+		}
+	}
+
+	// The transfer's two sides balance
+	var total decimal.Decimal
+	var count int
+	err := testSuite.Pool().QueryRow(ctx, `SELECT COALESCE(SUM(amount), 0), COUNT(*) FROM ledger_entries WHERE transaction_id = 1`).Scan(&total, &count)
+	if err != nil {
+		t.Fatalf("query ledger entries: %v", err)
+	}
+	if count != 2 || !total.IsZero() {
+		t.Errorf("expected 2 entries summing to zero, got %d summing to %s", count, total)
+	}
+}
+
+func TestIntegration_TransferFee(t *testing.T) {
+	_, accSvc, accRepo := setup(t)
+	txnRepo := repository.NewTransactionRepository(testSuite.Pool())
+	config := DefaultTransferConfig()
+	config.Fees = FeePolicy{AccountID: 3, Flat: decimal.RequireFromString("0.30"), Percent: decimal.RequireFromString("2.9")}
+	transferSvc := NewTransferServiceWithConfig(accRepo, txnRepo, config)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "0")
+	createAccount(t, accSvc, 3, "0")
+
+	// 0.30 plus 2.9% of 33.33 (0.96657) rounds to 1.27
+	txn, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "33.33",
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if !txn.FeeAmount.Equal(decimal.RequireFromString("1.27")) {
+		t.Errorf("expected a 1.27 fee, got %s", txn.FeeAmount)
+	}
+
+	for id, want := range map[int64]string{1: "965.40", 2: "33.33", 3: "1.27"} {
+		acc, err := accRepo.GetByID(ctx, id)
+		if err != nil || !acc.Balance.Equal(decimal.RequireFromString(want)) {
+			t.Errorf("account %d: expected balance %s, got %+v, %v", id, want, acc, err)
+		}
+	}
+
+	stored, err := txnRepo.GetByID(ctx, txn.TransactionID)
+	if err != nil {
+		t.Fatalf("get transaction: %v", err)
+	}
+	if !stored.FeeAmount.Equal(txn.FeeAmount) || stored.FeeAccountID != 3 || stored.FeePaidBy != models.FeePayerSource {
+		t.Errorf("expected the fee to be stored with its account and payer, got %+v", stored)
+	}
+
+	// Three ledger entries that still sum to zero
+	var total decimal.Decimal
+	var count int
+	err = testSuite.Pool().QueryRow(ctx, `SELECT COALESCE(SUM(amount), 0), COUNT(*) FROM ledger_entries WHERE transaction_id = $1`, txn.TransactionID).Scan(&total, &count)
+	if err != nil {
+		t.Fatalf("query ledger entries: %v", err)
+	}
+	if count != 3 || !total.IsZero() {
+		t.Errorf("expected 3 entries summing to zero, got %d summing to %s", count, total)
+	}
+	if history, _ := accRepo.ListBalanceHistory(ctx, 3, 10, 0); len(history) != 1 || !history[0].NewBalance.Equal(decimal.RequireFromString("1.27")) {
+		t.Errorf("expected the fee account's balance history to record the fee, got %+v", history)
+	}
+}
+
 func TestIntegration_InsufficientBalance(t *testing.T) {
 	transferSvc, accSvc, _ := setup(t)
 
@@ -91,6 +286,80 @@ func TestIntegration_InsufficientBalance(t *testing.T) {
 	}
 }
 
+func TestIntegration_ClosedAccount(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+
+	closed, err := accSvc.CloseAccount(ctx, 2, false)
+	if err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := accSvc.CloseAccount(ctx, 2, false); !errors.Is(err, models.ErrAccountAlreadyClosed) {
+		t.Errorf("expected ErrAccountAlreadyClosed, got %v", err)
+	}
+
+	acc, err := accRepo.GetByID(ctx, 2)
+	if err != nil || acc.ClosedAt == nil || !acc.ClosedAt.Equal(*closed.ClosedAt) {
+		t.Fatalf("expected a stored closed_at, got %+v, %v", acc, err)
+	}
+
+	_, err = transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "10",
+	})
+	if !errors.Is(err, models.ErrAccountClosed) {
+		t.Errorf("expected ErrAccountClosed, got %v", err)
+	}
+	if _, err := accSvc.CloseAccount(ctx, 1, false); !errors.Is(err, models.ErrAccountNotEmpty) {
+		t.Errorf("expected ErrAccountNotEmpty, got %v", err)
+	}
+}
+
+func TestIntegration_Overdraft(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	if _, err := accSvc.CreateAccount(ctx, &models.CreateAccountRequest{
+		AccountID: 1, InitialBalance: "50", OverdraftLimit: "100",
+	}); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	createAccount(t, accSvc, 2, "0")
+
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "120",
+	}); err != nil {
+		t.Fatalf("transfer within overdraft: %v", err)
+	}
+	acc, _ := accRepo.GetByID(ctx, 1)
+	if !acc.Balance.Equal(decimal.NewFromInt(-70)) || !acc.OverdraftLimit.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected balance -70 with a 100 limit, got %s and %s", acc.Balance, acc.OverdraftLimit)
+	}
+	history, _ := accRepo.ListBalanceHistory(ctx, 1, 10, 0)
+	if len(history) == 0 || !history[0].NewBalance.Equal(decimal.NewFromInt(-70)) {
+		t.Errorf("expected the negative balance in the history, got %+v", history)
+	}
+
+	_, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "30.01",
+	})
+	if !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance beyond the overdraft, got %v", err)
+	}
+	if acc, _ := accRepo.GetByID(ctx, 1); !acc.Balance.Equal(decimal.NewFromInt(-70)) {
+		t.Errorf("expected the balance unchanged at -70, got %s", acc.Balance)
+	}
+
+	// The database enforces the limit too
+	tx, _ := accRepo.BeginTx(ctx)
+	defer tx.Rollback(ctx)
+	if err := accRepo.UpdateBalance(ctx, tx, 1, decimal.NewFromInt(-101)); err == nil {
+		t.Error("expected the CHECK constraint to reject a balance below -overdraft_limit")
+	}
+}
+
 func TestIntegration_ConcurrentTransfers(t *testing.T) {
 	transferSvc, accSvc, accRepo := setup(t)
 	ctx := context.Background()
@@ -136,6 +405,207 @@ func TestIntegration_ConcurrentTransfers(t *testing.T) {
 	}
 }
 
+func TestIntegration_ConcurrentTransfers_Serializable(t *testing.T) {
+	_, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	serializable := repository.NewAccountRepositoryWithIsolation(testSuite.Pool(), pgx.Serializable)
+	cfg := DefaultTransferConfig()
+	cfg.MaxRetries = 10
+	cfg.RetryBaseDelay = 5 * time.Millisecond
+	transferSvc := NewTransferServiceWithConfig(serializable, repository.NewTransactionRepository(testSuite.Pool()), cfg)
+
+	createAccount(t, accSvc, 1, "10000")
+	createAccount(t, accSvc, 2, "10000")
+	createAccount(t, accSvc, 3, "10000")
+
+	var wg sync.WaitGroup
+	var success atomic.Int32
+
+	// Transfers around a cycle so every account is both debited and credited concurrently
+	for i := 0; i < 30; i++ {
+		for _, pair := range [][2]int64{{1, 2}, {2, 3}, {3, 1}} {
+			wg.Add(1)
+			go func(source, dest int64) {
+				defer wg.Done()
+				_, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+					SourceAccountID: source, DestinationAccountID: dest, Amount: "10",
+				})
+				if err == nil {
+					success.Add(1)
+				} else if code, _ := models.IsDomainError(err); code != models.CodeTransactionFailed {
+					// Serialization failures must be retried, surfacing only once retries run out
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(pair[0], pair[1])
+		}
+	}
+	wg.Wait()
+
+	t.Logf("successful transfers: %d", success.Load())
+	if success.Load() == 0 {
+		t.Fatal("expected some transfers to succeed under serializable")
+	}
+
+	total := decimal.Zero
+	for _, id := range []int64{1, 2, 3} {
+		acc, err := accRepo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("get account %d: %v", id, err)
+		}
+		total = total.Add(acc.Balance)
+	}
+	if !total.Equal(decimal.NewFromInt(30000)) {
+		t.Errorf("balance not conserved: total %s", total)
+	}
+
+	// The committed transfers must account for every balance change
+	txns, _ := transferSvc.GetAccountTransactions(ctx, 1, 1000, 0)
+	var in, out int
+	for _, txn := range txns {
+		if txn.SourceAccountID == 1 {
+			out++
+		} else {
+			in++
+		}
+	}
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	if want := decimal.NewFromInt(10000 + 10*int64(in-out)); !acc1.Balance.Equal(want) {
+		t.Errorf("account 1: expected %s from its %d in / %d out transfers, got %s", want, in, out, acc1.Balance)
+	}
+}
+
+func TestIntegration_ConcurrentTransfers_Optimistic(t *testing.T) {
+	_, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	cfg := DefaultTransferConfig()
+	cfg.ConcurrencyMode = ConcurrencyOptimistic
+	cfg.MaxRetries = 20
+	cfg.RetryBaseDelay = time.Millisecond
+	transferSvc := NewTransferServiceWithConfig(accRepo, repository.NewTransactionRepository(testSuite.Pool()), cfg)
+
+	createAccount(t, accSvc, 1, "10000")
+	createAccount(t, accSvc, 2, "10000")
+	createAccount(t, accSvc, 3, "10000")
+
+	var wg sync.WaitGroup
+	var success atomic.Int32
+
+	for i := 0; i < 30; i++ {
+		for _, pair := range [][2]int64{{1, 2}, {2, 3}, {3, 1}} {
+			wg.Add(1)
+			go func(source, dest int64) {
+				defer wg.Done()
+				_, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+					SourceAccountID: source, DestinationAccountID: dest, Amount: "10",
+				})
+				if err == nil {
+					success.Add(1)
+				} else if code, _ := models.IsDomainError(err); code != models.CodeTransactionFailed {
+					// Version conflicts must be retried, surfacing only once retries run out
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(pair[0], pair[1])
+		}
+	}
+	wg.Wait()
+
+	t.Logf("successful transfers: %d", success.Load())
+	if success.Load() == 0 {
+		t.Fatal("expected some optimistic transfers to succeed")
+	}
+
+	total := decimal.Zero
+	for _, id := range []int64{1, 2, 3} {
+		acc, err := accRepo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("get account %d: %v", id, err)
+		}
+		total = total.Add(acc.Balance)
+	}
+	if !total.Equal(decimal.NewFromInt(30000)) {
+		t.Errorf("balance not conserved: total %s", total)
+	}
+
+	// No lost updates: account 1's balance must match its committed transfers exactly
+	txns, _ := transferSvc.GetAccountTransactions(ctx, 1, 1000, 0)
+	var in, out int
+	for _, txn := range txns {
+		if txn.SourceAccountID == 1 {
+			out++
+		} else {
+			in++
+		}
+	}
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	if want := decimal.NewFromInt(10000 + 10*int64(in-out)); !acc1.Balance.Equal(want) {
+		t.Errorf("account 1: expected %s from its %d in / %d out transfers, got %s", want, in, out, acc1.Balance)
+	}
+	if acc1.Version != in+out {
+		t.Errorf("account 1: expected version %d after %d transfers, got %d", in+out, in+out, acc1.Version)
+	}
+}
+
+func TestIntegration_ConcurrentTransfers_Advisory(t *testing.T) {
+	_, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	cfg := DefaultTransferConfig()
+	cfg.LockStrategy = LockAdvisory
+	transferSvc := NewTransferServiceWithConfig(accRepo, repository.NewTransactionRepository(testSuite.Pool()), cfg)
+
+	createAccount(t, accSvc, 1, "10000")
+	createAccount(t, accSvc, 2, "10000")
+	createAccount(t, accSvc, 3, "10000")
+
+	var wg sync.WaitGroup
+	var success atomic.Int32
+
+	// A cycle plus opposite directions: taking the locks in ID order must keep every
+	// transfer from waiting on another that waits on it
+	for i := 0; i < 30; i++ {
+		for _, pair := range [][2]int64{{1, 2}, {2, 3}, {3, 1}, {2, 1}, {3, 2}, {1, 3}} {
+			wg.Add(1)
+			go func(source, dest int64) {
+				defer wg.Done()
+				_, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+					SourceAccountID: source, DestinationAccountID: dest, Amount: "10",
+				})
+				if err != nil {
+					t.Errorf("transfer %d->%d failed: %v", source, dest, err)
+					return
+				}
+				success.Add(1)
+			}(pair[0], pair[1])
+		}
+	}
+	wg.Wait()
+
+	if success.Load() != 180 {
+		t.Errorf("expected all 180 transfers to succeed, got %d", success.Load())
+	}
+
+	total := decimal.Zero
+	for _, id := range []int64{1, 2, 3} {
+		acc, err := accRepo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("get account %d: %v", id, err)
+		}
+		// Every account sent and received the same amount
+		if !acc.Balance.Equal(decimal.NewFromInt(10000)) {
+			t.Errorf("account %d: expected 10000, got %s", id, acc.Balance)
+		}
+		if acc.Version != 120 {
+			t.Errorf("account %d: expected version 120 after 120 transfers, got %d", id, acc.Version)
+		}
+		total = total.Add(acc.Balance)
+	}
+	if !total.Equal(decimal.NewFromInt(30000)) {
+		t.Errorf("balance not conserved: total %s", total)
+	}
+}
+
 func TestIntegration_RaceForSameBalance(t *testing.T) {
 	transferSvc, accSvc, accRepo := setup(t)
 	ctx := context.Background()
@@ -172,3 +642,826 @@ func TestIntegration_RaceForSameBalance(t *testing.T) {
 		t.Errorf("unexpected balances: %s, %s", acc1.Balance, acc2.Balance)
 	}
 }
+
+func TestIntegration_HoldBlocksConcurrentTransfers(t *testing.T) {
+	for _, mode := range []ConcurrencyMode{ConcurrencyPessimistic, ConcurrencyOptimistic} {
+		t.Run(string(mode), func(t *testing.T) {
+			_, accSvc, accRepo := setup(t)
+			ctx := context.Background()
+
+			cfg := DefaultTransferConfig()
+			cfg.ConcurrencyMode = mode
+			cfg.MaxRetries = 20
+			cfg.RetryBaseDelay = time.Millisecond
+			transferSvc := NewTransferServiceWithConfig(accRepo, repository.NewTransactionRepository(testSuite.Pool()), cfg)
+
+			createAccount(t, accSvc, 1, "100")
+			createAccount(t, accSvc, 2, "0")
+
+			var wg sync.WaitGroup
+			var transfers atomic.Int32
+			var hold *models.Hold
+
+			// One hold of 70 races 20 transfers of 50: the hold and a transfer can't both
+			// succeed, and at most two transfers fit without it
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				placed, err := transferSvc.PlaceHold(ctx, 1, decimal.NewFromInt(70))
+				if err == nil {
+					hold = placed
+				} else if !errors.Is(err, models.ErrInsufficientBalance) {
+					t.Errorf("place hold: %v", err)
+				}
+			}()
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+						SourceAccountID: 1, DestinationAccountID: 2, Amount: "50",
+					})
+					if err == nil {
+						transfers.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+
+			acc, err := accRepo.GetByID(ctx, 1)
+			if err != nil {
+				t.Fatalf("get account: %v", err)
+			}
+			if acc.AvailableBalance().IsNegative() {
+				t.Fatalf("overdrawn: balance %s with %s held", acc.Balance, acc.HeldBalance)
+			}
+			if want := decimal.NewFromInt(100 - 50*int64(transfers.Load())); !acc.Balance.Equal(want) {
+				t.Errorf("expected balance %s after %d transfers, got %s", want, transfers.Load(), acc.Balance)
+			}
+			if hold != nil {
+				if transfers.Load() != 0 || !acc.HeldBalance.Equal(decimal.NewFromInt(70)) {
+					t.Errorf("hold placed: expected no transfers and 70 held, got %d transfers and %s held", transfers.Load(), acc.HeldBalance)
+				}
+			} else if transfers.Load() == 0 || !acc.HeldBalance.IsZero() {
+				t.Errorf("hold rejected: expected a transfer to have won and nothing held, got %d transfers and %s held", transfers.Load(), acc.HeldBalance)
+			}
+		})
+	}
+}
+
+func TestIntegration_CaptureHold(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+
+	hold, err := transferSvc.PlaceHold(ctx, 1, decimal.NewFromInt(40))
+	if err != nil {
+		t.Fatalf("place hold: %v", err)
+	}
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "61",
+	}); !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Errorf("expected the hold to block a transfer of 61, got %v", err)
+	}
+
+	captured, err := transferSvc.CaptureHold(ctx, hold.HoldID)
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if captured.Status != models.HoldStatusCaptured || captured.TransactionID == nil || captured.ResolvedAt == nil {
+		t.Fatalf("expected a captured hold with a withdrawal, got %+v", captured)
+	}
+
+	acc, _ := accRepo.GetByID(ctx, 1)
+	if !acc.Balance.Equal(decimal.NewFromInt(60)) || !acc.HeldBalance.IsZero() {
+		t.Errorf("expected balance 60 with nothing held, got %s and %s", acc.Balance, acc.HeldBalance)
+	}
+
+	entry, err := transferSvc.GetTransaction(ctx, *captured.TransactionID)
+	if err != nil {
+		t.Fatalf("get capture transaction: %v", err)
+	}
+	if entry.Type != models.TransactionTypeWithdrawal || entry.SourceAccountID != 1 || !entry.Amount.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("expected a 40 withdrawal from account 1, got %+v", entry)
+	}
+
+	history, _ := accRepo.ListBalanceHistory(ctx, 1, 10, 0)
+	if len(history) == 0 || !history[0].NewBalance.Equal(decimal.NewFromInt(60)) || *history[0].TransactionID != entry.TransactionID {
+		t.Errorf("expected the capture at the head of the balance history, got %+v", history)
+	}
+
+	if _, err := transferSvc.CaptureHold(ctx, hold.HoldID); !errors.Is(err, models.ErrHoldNotActive) {
+		t.Errorf("expected ErrHoldNotActive capturing twice, got %v", err)
+	}
+
+	// The remaining 60 is fully available again
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "60",
+	}); err != nil {
+		t.Errorf("transfer after capture: %v", err)
+	}
+}
+
+func TestIntegration_SequenceReplay(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+
+	// 20 goroutines replay the same sequence; the source lock lets exactly one through
+	var wg sync.WaitGroup
+	var success, stale atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sequence := int64(1)
+			_, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+				SourceAccountID: 1, DestinationAccountID: 2, Amount: "1", Sequence: &sequence,
+			})
+			switch {
+			case err == nil:
+				success.Add(1)
+			case errors.Is(err, models.ErrStaleSequence):
+				stale.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if success.Load() != 1 || stale.Load() != 19 {
+		t.Errorf("expected 1 success and 19 stale, got %d and %d", success.Load(), stale.Load())
+	}
+
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	if acc1.LastSequence != 1 || !acc1.Balance.Equal(decimal.NewFromInt(99)) {
+		t.Errorf("expected last sequence 1 and balance 99, got %d and %s", acc1.LastSequence, acc1.Balance)
+	}
+
+	sequence := int64(2)
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "1", Sequence: &sequence,
+	}); err != nil {
+		t.Errorf("expected next sequence to be accepted, got %v", err)
+	}
+}
+
+// serializableAccountRepo begins SERIALIZABLE transactions and runs beforeCommit
+// once, immediately before the first COMMIT, so a test can inject a conflicting writer.
+type serializableAccountRepo struct {
+	*repository.AccountRepository
+	beforeCommit func(ctx context.Context, tx pgx.Tx)
+	fired        atomic.Bool
+}
+
+func (r *serializableAccountRepo) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	tx, err := testSuite.Pool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return nil, err
+	}
+	return &hookTx{Tx: tx, repo: r}, nil
+}
+
+type hookTx struct {
+	pgx.Tx
+	repo *serializableAccountRepo
+}
+
+func (h *hookTx) Commit(ctx context.Context) error {
+	if h.repo.fired.CompareAndSwap(false, true) {
+		h.repo.beforeCommit(ctx, h.Tx)
+	}
+	return h.Tx.Commit(ctx)
+}
+
+func TestIntegration_RetryOnCommitSerializationFailure(t *testing.T) {
+	_, accSvc, baseRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500")
+	createAccount(t, accSvc, 3, "0")
+
+	// The interfering transaction reads transactions (which the transfer inserts into)
+	// before the transfer starts, so it holds an rw-dependency on the transfer.
+	other, err := testSuite.Pool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer other.Rollback(ctx)
+	var n int
+	if err := other.QueryRow(ctx, `SELECT count(*) FROM transactions`).Scan(&n); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	// Just before the transfer commits, it reads account 3 and the other transaction
+	// writes it and commits first. That closes the rw-dependency cycle, making the
+	// transfer the pivot, so Postgres fails its COMMIT with SQLSTATE 40001.
+	accRepo := &serializableAccountRepo{
+		AccountRepository: baseRepo,
+		beforeCommit: func(ctx context.Context, tx pgx.Tx) {
+			var balance decimal.Decimal
+			if err := tx.QueryRow(ctx, `SELECT balance FROM accounts WHERE account_id = 3`).Scan(&balance); err != nil {
+				t.Errorf("pivot read: %v", err)
+			}
+			if _, err := other.Exec(ctx, `UPDATE accounts SET balance = balance + 1 WHERE account_id = 3`); err != nil {
+				t.Errorf("conflicting write: %v", err)
+			}
+			if err := other.Commit(ctx); err != nil {
+				t.Errorf("conflicting commit: %v", err)
+			}
+		},
+	}
+
+	config := TransferServiceConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond, RetryOnCommitFailure: true}
+	svc := NewTransferServiceWithConfig(accRepo, repository.NewTransactionRepository(testSuite.Pool()), config)
+
+	txn, err := svc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+	})
+	if err != nil {
+		t.Fatalf("expected success after commit retry, got: %v", err)
+	}
+	if !accRepo.fired.Load() {
+		t.Fatal("commit hook did not run")
+	}
+
+	// The first attempt was rolled back, so the transfer must be applied exactly once.
+	acc1, _ := baseRepo.GetByID(ctx, 1)
+	acc2, _ := baseRepo.GetByID(ctx, 2)
+	if !acc1.Balance.Equal(decimal.NewFromInt(900)) || !acc2.Balance.Equal(decimal.NewFromInt(600)) {
+		t.Errorf("unexpected balances: %s, %s", acc1.Balance, acc2.Balance)
+	}
+
+	var count int
+	testSuite.Pool().QueryRow(ctx, `SELECT count(*) FROM transactions`).Scan(&count)
+	if count != 1 || txn.TransactionID == 0 {
+		t.Errorf("expected exactly 1 transaction row, got %d", count)
+	}
+}
+
+func TestIntegration_EffectiveDate(t *testing.T) {
+	transferSvc, accSvc, _ := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "0")
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	backdated := today.AddDate(0, 0, -3)
+
+	dated, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "10",
+		EffectiveDate: backdated.Format(models.DateLayout),
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	undated, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "10",
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	stored, err := repository.NewTransactionRepository(testSuite.Pool()).GetByID(ctx, dated.TransactionID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !stored.EffectiveDate.Equal(backdated) {
+		t.Errorf("expected effective date %s, got %s", backdated.Format(models.DateLayout), stored.EffectiveDate.Format(models.DateLayout))
+	}
+	if stored.CreatedAt.Before(today) {
+		t.Errorf("created_at must stay the system timestamp, got %s", stored.CreatedAt)
+	}
+	if !undated.EffectiveDate.Equal(today) {
+		t.Errorf("expected default effective date %s, got %s", today.Format(models.DateLayout), undated.EffectiveDate.Format(models.DateLayout))
+	}
+}
+
+func TestIntegration_Reverse(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500")
+
+	original, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	reversal, err := transferSvc.Reverse(ctx, original.TransactionID)
+	if err != nil {
+		t.Fatalf("reverse: %v", err)
+	}
+	if reversal.ReversalOf == nil || *reversal.ReversalOf != original.TransactionID {
+		t.Errorf("expected reversal_of %d, got %v", original.TransactionID, reversal.ReversalOf)
+	}
+
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	acc2, _ := accRepo.GetByID(ctx, 2)
+	if !acc1.Balance.Equal(decimal.NewFromInt(1000)) || !acc2.Balance.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("balances not restored: %s, %s", acc1.Balance, acc2.Balance)
+	}
+
+	stored, err := repository.NewTransactionRepository(testSuite.Pool()).GetByID(ctx, reversal.TransactionID)
+	if err != nil || stored.ReversalOf == nil || *stored.ReversalOf != original.TransactionID {
+		t.Errorf("reversal link not persisted: %+v, %v", stored, err)
+	}
+
+	_, err = transferSvc.Reverse(ctx, original.TransactionID)
+	if !errors.Is(err, models.ErrAlreadyReversed) {
+		t.Errorf("expected ErrAlreadyReversed, got %v", err)
+	}
+}
+
+func TestIntegration_ConcurrentReverse(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500")
+
+	original, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var success, alreadyReversed atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := transferSvc.Reverse(ctx, original.TransactionID)
+			switch {
+			case err == nil:
+				success.Add(1)
+			case errors.Is(err, models.ErrAlreadyReversed):
+				alreadyReversed.Add(1)
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if success.Load() != 1 || alreadyReversed.Load() != 9 {
+		t.Errorf("expected 1 reversal and 9 rejections, got %d and %d", success.Load(), alreadyReversed.Load())
+	}
+
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	acc2, _ := accRepo.GetByID(ctx, 2)
+	if !acc1.Balance.Equal(decimal.NewFromInt(1000)) || !acc2.Balance.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("balances not restored exactly once: %s, %s", acc1.Balance, acc2.Balance)
+	}
+}
+
+func TestIntegration_AdjustBalancesBatch(t *testing.T) {
+	_, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "50")
+	createAccount(t, accSvc, 3, "10")
+
+	// One account going negative rejects the whole batch
+	_, err := accSvc.AdjustBalancesBatch(ctx, []models.BalanceDelta{
+		{AccountID: 1, Delta: decimal.NewFromInt(5)},
+		{AccountID: 3, Delta: decimal.NewFromInt(-11)},
+	}, "bad correction")
+	if !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+	if acc, _ := accRepo.GetByID(ctx, 1); !acc.Balance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("rejected batch was partially applied: %s", acc.Balance)
+	}
+
+	adjustments, err := accSvc.AdjustBalancesBatch(ctx, []models.BalanceDelta{
+		{AccountID: 3, Delta: decimal.NewFromInt(-10)},
+		{AccountID: 1, Delta: decimal.RequireFromString("0.75")},
+		{AccountID: 2, Delta: decimal.NewFromInt(-1)},
+	}, "ledger correction")
+	if err != nil {
+		t.Fatalf("adjust: %v", err)
+	}
+
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	acc3, _ := accRepo.GetByID(ctx, 3)
+	if !acc1.Balance.Equal(decimal.RequireFromString("100.75")) || !acc3.Balance.IsZero() {
+		t.Errorf("unexpected balances: %s, %s", acc1.Balance, acc3.Balance)
+	}
+
+	var rows int
+	var reason string
+	err = testSuite.Pool().QueryRow(ctx,
+		`SELECT count(*), min(reason) FROM balance_adjustments WHERE batch_id = $1`, adjustments[0].BatchID,
+	).Scan(&rows, &reason)
+	if err != nil || rows != 3 || reason != "ledger correction" {
+		t.Errorf("expected 3 audit rows with the shared reason, got %d %q err=%v", rows, reason, err)
+	}
+}
+
+func TestIntegration_CreateAccount_Idempotent(t *testing.T) {
+	transferSvc, accSvc, _ := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "30"}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	// Matched on the stored initial_balance, not the balance after the transfer
+	acc, err := accSvc.CreateAccount(ctx, &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100.00", Idempotent: true})
+	if err != nil {
+		t.Fatalf("idempotent repeat: %v", err)
+	}
+	if !acc.Replayed || !acc.Balance.Equal(decimal.NewFromInt(70)) || !acc.InitialBalance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected the existing account, got %+v", acc)
+	}
+
+	_, err = accSvc.CreateAccount(ctx, &models.CreateAccountRequest{AccountID: 1, InitialBalance: "70", Idempotent: true})
+	if !errors.Is(err, models.ErrAccountAlreadyExists) {
+		t.Errorf("expected ErrAccountAlreadyExists for a different initial balance, got %v", err)
+	}
+}
+
+func TestIntegration_Reconcile_DetectOnly(t *testing.T) {
+	_, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "50")
+
+	report, err := accSvc.Reconcile(ctx, []models.ExpectedBalance{
+		{AccountID: 2, Balance: decimal.NewFromInt(50)},
+		{AccountID: 1, Balance: decimal.RequireFromString("99.5")},
+		{AccountID: 7, Balance: decimal.NewFromInt(1)},
+	}, false, "")
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if len(report.Items) != 3 || report.BatchID != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	want := []models.ReconciliationStatus{models.ReconciliationMismatched, models.ReconciliationMatched, models.ReconciliationMissing}
+	for i, item := range report.Items {
+		if item.Status != want[i] {
+			t.Errorf("account %d: expected %s, got %s", item.AccountID, want[i], item.Status)
+		}
+	}
+	if d := report.Items[0].Difference(); !d.Equal(decimal.RequireFromString("-0.5")) {
+		t.Errorf("expected difference -0.5, got %s", d)
+	}
+
+	if acc, _ := accRepo.GetByID(ctx, 1); !acc.Balance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("detect-only reconcile changed the balance to %s", acc.Balance)
+	}
+	var rows int
+	if err := testSuite.Pool().QueryRow(ctx, `SELECT count(*) FROM balance_adjustments`).Scan(&rows); err != nil || rows != 0 {
+		t.Errorf("expected no adjustments, got %d err=%v", rows, err)
+	}
+}
+
+func TestIntegration_Reconcile_Apply(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "50")
+	createAccount(t, accSvc, 3, "10")
+
+	expected := []models.ExpectedBalance{
+		{AccountID: 3, Balance: decimal.NewFromInt(10)},
+		{AccountID: 1, Balance: decimal.RequireFromString("120.25")},
+		{AccountID: 2, Balance: decimal.NewFromInt(45)},
+	}
+
+	// One correction going negative rejects them all
+	_, err := accSvc.Reconcile(ctx, []models.ExpectedBalance{
+		{AccountID: 1, Balance: decimal.NewFromInt(90)},
+		{AccountID: 3, Balance: decimal.NewFromInt(-1)},
+	}, true, "")
+	if !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+	if acc, _ := accRepo.GetByID(ctx, 1); !acc.Balance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("rejected reconciliation was partially applied: %s", acc.Balance)
+	}
+
+	report, err := accSvc.Reconcile(ctx, expected, true, "month-end import")
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if report.Count(models.ReconciliationCorrected) != 2 || report.Count(models.ReconciliationMatched) != 1 || report.BatchID == 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	acc1, _ := accRepo.GetByID(ctx, 1)
+	acc2, _ := accRepo.GetByID(ctx, 2)
+	if !acc1.Balance.Equal(decimal.RequireFromString("120.25")) || !acc2.Balance.Equal(decimal.NewFromInt(45)) {
+		t.Errorf("unexpected balances: %s, %s", acc1.Balance, acc2.Balance)
+	}
+
+	var rows int
+	var total decimal.Decimal
+	err = testSuite.Pool().QueryRow(ctx,
+		`SELECT count(*), sum(delta) FROM balance_adjustments WHERE batch_id = $1 AND reason = 'month-end import'`, report.BatchID,
+	).Scan(&rows, &total)
+	if err != nil || rows != 2 || !total.Equal(decimal.RequireFromString("15.25")) {
+		t.Errorf("expected 2 adjustments totalling 15.25, got %d %s err=%v", rows, total, err)
+	}
+
+	// The corrections are recorded, so the ledger still explains every balance
+	v, err := transferSvc.VerifyLedger(ctx)
+	if err != nil || !v.Consistent() {
+		t.Errorf("expected a consistent ledger, got %+v err=%v", v, err)
+	}
+
+	// Importing the same balances again changes nothing
+	again, err := accSvc.Reconcile(ctx, expected, true, "month-end import")
+	if err != nil {
+		t.Fatalf("reconcile again: %v", err)
+	}
+	if again.Count(models.ReconciliationMatched) != 3 || again.BatchID != 0 {
+		t.Errorf("expected every account matched, got %+v", again)
+	}
+}
+
+// failOnLeg aborts the batch when the transaction for the given destination is inserted.
+type failOnLeg struct{ dest int64 }
+
+func (f failOnLeg) PreCommit(_ context.Context, _ pgx.Tx, txn *models.Transaction) error {
+	if txn.DestinationAccountID == f.dest {
+		return errors.New("leg rejected")
+	}
+	return nil
+}
+func (f failOnLeg) PostCommit(context.Context, *models.Transaction) {}
+
+func TestIntegration_BatchTransfer_NoPartialApplication(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+	createAccount(t, accSvc, 3, "0")
+
+	// The second leg fails after the first leg's balances and row were written
+	transferSvc.AddHook(failOnLeg{dest: 3})
+	_, err := transferSvc.BatchTransfer(ctx, &models.CreateBatchTransferRequest{
+		SourceAccountID: 1,
+		Transfers: []models.BatchTransferItem{
+			{DestinationAccountID: 2, Amount: "10"},
+			{DestinationAccountID: 3, Amount: "20"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected the batch to fail")
+	}
+
+	for id, want := range map[int64]int64{1: 100, 2: 0, 3: 0} {
+		acc, _ := accRepo.GetByID(ctx, id)
+		if !acc.Balance.Equal(decimal.NewFromInt(want)) {
+			t.Errorf("account %d: expected %d, got %s", id, want, acc.Balance)
+		}
+	}
+
+	var rows int
+	testSuite.Pool().QueryRow(ctx, `SELECT count(*) FROM transactions`).Scan(&rows)
+	if rows != 0 {
+		t.Errorf("expected no transaction rows after rollback, got %d", rows)
+	}
+}
+
+func TestIntegration_BatchTransfer(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+
+	createAccount(t, accSvc, 1, "100")
+	createAccount(t, accSvc, 2, "0")
+	createAccount(t, accSvc, 3, "0")
+
+	txns, err := transferSvc.BatchTransfer(ctx, &models.CreateBatchTransferRequest{
+		SourceAccountID: 1,
+		Transfers: []models.BatchTransferItem{
+			{DestinationAccountID: 3, Amount: "25"},
+			{DestinationAccountID: 2, Amount: "75"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("batch transfer: %v", err)
+	}
+	if len(txns) != 2 || txns[0].TransactionID == 0 || txns[1].TransactionID == 0 {
+		t.Fatalf("expected two stored transactions, got %+v", txns)
+	}
+
+	for id, want := range map[int64]int64{1: 0, 2: 75, 3: 25} {
+		acc, _ := accRepo.GetByID(ctx, id)
+		if !acc.Balance.Equal(decimal.NewFromInt(want)) {
+			t.Errorf("account %d: expected %d, got %s", id, want, acc.Balance)
+		}
+	}
+}
+
+func TestIntegration_ConcurrentIdempotentDeposits(t *testing.T) {
+	_, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+	ledgerSvc := NewLedgerService(accRepo, repository.NewTransactionRepository(testSuite.Pool()))
+	createAccount(t, accSvc, 1, "100")
+
+	// Every replay must return the one deposit, whichever request committed it
+	const replays = 10
+	ids := make([]int64, replays)
+	var wg sync.WaitGroup
+	for i := 0; i < replays; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry, err := ledgerSvc.Deposit(ctx, 1, &models.LedgerEntryRequest{Amount: "25", IdempotencyKey: "deposit-1"})
+			if err != nil {
+				t.Errorf("deposit %d: %v", i, err)
+				return
+			}
+			ids[i] = entry.TransactionID
+		}(i)
+	}
+	wg.Wait()
+
+	for i, id := range ids {
+		if id != ids[0] {
+			t.Errorf("deposit %d returned transaction %d, expected %d", i, id, ids[0])
+		}
+	}
+	acc, _ := accRepo.GetByID(ctx, 1)
+	if !acc.Balance.Equal(decimal.NewFromInt(125)) {
+		t.Errorf("expected a single credit to 125, got %s", acc.Balance)
+	}
+	if history, _ := accRepo.ListBalanceHistory(ctx, 1, 20, 0); len(history) != 1 {
+		t.Errorf("expected one balance change, got %d", len(history))
+	}
+}
+
+func TestIntegration_VerifyLedger(t *testing.T) {
+	transferSvc, accSvc, accRepo := setup(t)
+	ctx := context.Background()
+	ledgerSvc := NewLedgerService(accRepo, repository.NewTransactionRepository(testSuite.Pool()))
+
+	createAccount(t, accSvc, 1, "1000")
+	createAccount(t, accSvc, 2, "500.25")
+	createAccount(t, accSvc, 3, "0")
+
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100"}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 3, DestinationAccountID: 1, Amount: "5"}); !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Fatalf("expected the overdrawing transfer to fail, got %v", err)
+	}
+	if _, err := ledgerSvc.Deposit(ctx, 3, &models.LedgerEntryRequest{Amount: "40"}); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	if _, err := ledgerSvc.Withdraw(ctx, 2, &models.LedgerEntryRequest{Amount: "0.25"}); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if _, err := accSvc.AdjustBalancesBatch(ctx, []models.BalanceDelta{{AccountID: 1, Delta: decimal.NewFromInt(-3)}}, "correction"); err != nil {
+		t.Fatalf("adjust: %v", err)
+	}
+
+	v, err := transferSvc.VerifyLedger(ctx)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !v.Consistent() {
+		t.Fatalf("expected a consistent ledger, got %+v", v)
+	}
+	if v.Accounts != 3 || !v.TotalBalance.Equal(decimal.NewFromInt(1537)) || !v.InitialBalances.Equal(decimal.RequireFromString("1500.25")) ||
+		!v.NetTransactions.Equal(decimal.RequireFromString("39.75")) || !v.Adjustments.Equal(decimal.NewFromInt(-3)) {
+		t.Errorf("unexpected totals %+v", v)
+	}
+
+	// A balance changed behind the ledger's back
+	if _, err := testSuite.Pool().Exec(ctx, `UPDATE accounts SET balance = balance + 1 WHERE account_id = 2`); err != nil {
+		t.Fatalf("corrupt balance: %v", err)
+	}
+
+	v, err = transferSvc.VerifyLedger(ctx)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if v.Consistent() || !v.Drift().Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected drift of 1, got %s", v.Drift())
+	}
+	if len(v.DriftedAccounts) != 1 || v.DriftedAccounts[0].AccountID != 2 ||
+		!v.DriftedAccounts[0].Balance.Equal(decimal.NewFromInt(601)) || !v.DriftedAccounts[0].Expected.Equal(decimal.NewFromInt(600)) {
+		t.Errorf("expected account 2 reported as drifted, got %+v", v.DriftedAccounts)
+	}
+}
+
+func TestIntegration_DailyLimits(t *testing.T) {
+	newService := func(t *testing.T, amountLimit string, countLimit int) *TransferService {
+		_, accSvc, accRepo := setup(t)
+		createAccount(t, accSvc, 1, "10000")
+		createAccount(t, accSvc, 2, "10000")
+		cfg := DefaultTransferConfig()
+		cfg.DailyAmountLimit = decimal.RequireFromString(amountLimit)
+		cfg.DailyCountLimit = countLimit
+		return NewTransferServiceWithConfig(accRepo, repository.NewTransactionRepository(testSuite.Pool()), cfg)
+	}
+	ctx := context.Background()
+	transfer := func(svc *TransferService, source, dest int64, amount string) error {
+		_, err := svc.Transfer(ctx, &models.CreateTransactionRequest{
+			SourceAccountID: source, DestinationAccountID: dest, Amount: amount,
+		})
+		return err
+	}
+	// sendUntilLimited transfers amount from account 1 until the daily limit trips and
+	// returns how many transfers went through first
+	sendUntilLimited := func(t *testing.T, svc *TransferService, amount string) int {
+		t.Helper()
+		for sent := 0; sent < 20; sent++ {
+			err := transfer(svc, 1, 2, amount)
+			if errors.Is(err, models.ErrVelocityLimitExceeded) {
+				return sent
+			}
+			if err != nil {
+				t.Fatalf("transfer %d: %v", sent+1, err)
+			}
+		}
+		t.Fatal("daily limit never tripped")
+		return 0
+	}
+
+	t.Run("amount", func(t *testing.T) {
+		svc := newService(t, "100", 0)
+		if sent := sendUntilLimited(t, svc, "30"); sent != 3 {
+			t.Errorf("expected 3 transfers of 30 under a limit of 100, got %d", sent)
+		}
+		if err := transfer(svc, 1, 2, "10"); err != nil {
+			t.Errorf("expected a transfer reaching the limit exactly to succeed, got %v", err)
+		}
+		// Money received doesn't count against the receiver's own limit, only sends do
+		if err := transfer(svc, 2, 1, "100"); err != nil {
+			t.Errorf("expected account 2 to have its own allowance, got %v", err)
+		}
+	})
+
+	t.Run("count", func(t *testing.T) {
+		svc := newService(t, "0", 4)
+		if sent := sendUntilLimited(t, svc, "1"); sent != 4 {
+			t.Errorf("expected 4 transfers under a count limit of 4, got %d", sent)
+		}
+
+		// Batch legs count individually
+		_, err := svc.BatchTransfer(ctx, &models.CreateBatchTransferRequest{
+			SourceAccountID: 2,
+			Transfers: []models.BatchTransferItem{
+				{DestinationAccountID: 1, Amount: "1"}, {DestinationAccountID: 1, Amount: "1"},
+				{DestinationAccountID: 1, Amount: "1"}, {DestinationAccountID: 1, Amount: "1"},
+				{DestinationAccountID: 1, Amount: "1"},
+			},
+		})
+		if !errors.Is(err, models.ErrVelocityLimitExceeded) {
+			t.Errorf("expected a 5-leg batch to exceed the count limit, got %v", err)
+		}
+	})
+
+	t.Run("account override", func(t *testing.T) {
+		svc := newService(t, "100", 0)
+		if _, err := testSuite.Pool().Exec(ctx, `UPDATE accounts SET daily_transfer_amount_limit = 25 WHERE account_id = 1`); err != nil {
+			t.Fatalf("set override: %v", err)
+		}
+		if sent := sendUntilLimited(t, svc, "10"); sent != 2 {
+			t.Errorf("expected 2 transfers of 10 under an override of 25, got %d", sent)
+		}
+
+		if _, err := testSuite.Pool().Exec(ctx, `UPDATE accounts SET daily_transfer_amount_limit = 0 WHERE account_id = 1`); err != nil {
+			t.Fatalf("lift override: %v", err)
+		}
+		if err := transfer(svc, 1, 2, "500"); err != nil {
+			t.Errorf("expected an override of 0 to lift the limit, got %v", err)
+		}
+	})
+
+	t.Run("resets daily", func(t *testing.T) {
+		svc := newService(t, "50", 0)
+		if sent := sendUntilLimited(t, svc, "50"); sent != 1 {
+			t.Fatalf("expected 1 transfer of 50 under a limit of 50, got %d", sent)
+		}
+		if _, err := testSuite.Pool().Exec(ctx, `UPDATE transactions SET created_at = created_at - interval '1 day'`); err != nil {
+			t.Fatalf("backdate: %v", err)
+		}
+		if err := transfer(svc, 1, 2, "50"); err != nil {
+			t.Errorf("expected yesterday's transfers not to count, got %v", err)
+		}
+	})
+}
@@ -2,31 +2,187 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
 	"time"
 
+	"internal-transfers-system/internal/consistency"
 	"internal-transfers-system/internal/interfaces"
+	"internal-transfers-system/internal/logging"
 	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/tracing"
 
-	"github.com/rs/zerolog/log"
+	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 )
 
+// ConcurrencyMode selects how a transfer keeps the balances it read from changing
+// underneath it.
+type ConcurrencyMode string
+
+const (
+	// ConcurrencyPessimistic locks both accounts with SELECT ... FOR UPDATE before reading
+	// them. Concurrent transfers touching the same account queue behind the lock.
+	ConcurrencyPessimistic ConcurrencyMode = "pessimistic"
+
+	// ConcurrencyOptimistic reads both accounts without locking and writes each balance
+	// only if the account's version is unchanged. A lost race fails with
+	// ErrConcurrentModification and the transfer is retried within MaxRetries.
+	ConcurrencyOptimistic ConcurrencyMode = "optimistic"
+)
+
+// LockStrategy selects how a pessimistic transfer serializes with other transfers on the
+// same accounts.
+type LockStrategy string
+
+const (
+	// LockRow reads each account with SELECT ... FOR UPDATE, holding its row lock until
+	// the transfer ends.
+	LockRow LockStrategy = "row"
+
+	// LockAdvisory takes pg_advisory_xact_lock on each account ID in ascending order, then
+	// reads the accounts without row locks. Writers other than transfers still lock rows,
+	// so each balance is written only if the account's version is unchanged, and a lost
+	// race is retried as in ConcurrencyOptimistic.
+	LockAdvisory LockStrategy = "advisory"
+)
+
 type TransferServiceConfig struct {
 	MaxRetries     int
 	RetryBaseDelay time.Duration
+
+	// RetryJitter waits a uniformly random time between zero and the exponential backoff
+	// before each retry ("full jitter"), so transfers that collided on a deadlock don't
+	// retry in lockstep and collide again. Without it the wait is exactly the backoff.
+	RetryJitter bool
+
+	// JitterRand returns a uniform random int64 in [0, n). Nil means math/rand/v2's
+	// Int64N; tests set it to make retry delays deterministic. It is called from
+	// concurrent transfers, so it must be safe for concurrent use.
+	JitterRand func(n int64) int64
+
+	// ConcurrencyMode chooses row locks or version checks for transfers. Empty means
+	// ConcurrencyPessimistic. Batch transfers, ledger entries, and adjustments always lock.
+	ConcurrencyMode ConcurrencyMode
+
+	// LockStrategy chooses row or advisory locks for pessimistic transfers. Empty means
+	// LockRow. It has no effect with ConcurrencyOptimistic.
+	LockStrategy LockStrategy
+
+	// RetryOnCommitFailure allows the whole transfer to be retried when COMMIT itself fails
+	// with a serialization failure or deadlock (SQLSTATE class 40). Any other commit error
+	// is always terminal: the outcome is unknown and a retry could apply the transfer twice.
+	RetryOnCommitFailure bool
+
+	// RetryUnseenAccounts retries account-not-found (within MaxRetries) when the request
+	// carries a read-your-writes token the database has not yet caught up to, i.e. the
+	// account may have been created but is not visible yet. Without a token, or once the
+	// database has reached the token's position, not-found is terminal.
+	RetryUnseenAccounts bool
+
+	// EffectiveDateMaxPastDays and EffectiveDateMaxFutureDays bound how far a requested
+	// effective_date may be from today (UTC). Zero for both allows only today.
+	EffectiveDateMaxPastDays   int
+	EffectiveDateMaxFutureDays int
+
+	// ForbidZeroBalanceTypes lists account types that a transfer may not leave at exactly
+	// zero; such transfers fail with ErrInsufficientBalance. Empty allows zero for all types.
+	ForbidZeroBalanceTypes []string
+
+	// SourceCooldown is the minimum time between outbound transfers from one account;
+	// transfers sent sooner fail with ErrCooldownActive. Batch transfers count as one
+	// send, and reversals are exempt. Zero disables the cooldown.
+	SourceCooldown time.Duration
+
+	// DailyAmountLimit and DailyCountLimit cap the total amount and the number of
+	// transfers one account may send per UTC day (by the database clock); a transfer that
+	// would go over either fails with ErrVelocityLimitExceeded. Batch legs count, fees and
+	// reversals don't. An account's daily_transfer_amount_limit and
+	// daily_transfer_count_limit columns override them, with 0 lifting the limit for that
+	// account. Zero disables a limit for accounts without an override.
+	DailyAmountLimit decimal.Decimal
+	DailyCountLimit  int
+
+	// MinTransferAmount and MaxTransferAmount bound the amount of a single transfer,
+	// inclusive; amounts outside them fail with ErrAmountBelowMinimum or
+	// ErrAmountAboveMaximum before any account is locked. Zero disables a bound. Batch
+	// transfers and reversals aren't limited.
+	MinTransferAmount decimal.Decimal
+	MaxTransferAmount decimal.Decimal
+
+	// Fees prices the fee charged on each single transfer and names the account it is
+	// credited to. Reversals and batch transfers are free. The zero value charges nothing.
+	Fees FeePolicy
+
+	// StuckTransferThreshold is how long a transfer may run before a warning is logged
+	// while it is still in progress. Zero disables the warning; ActiveTransfers still
+	// tracks every transfer.
+	StuckTransferThreshold time.Duration
+
+	// SlowTransferThreshold is how long a transfer may take, retries included, before a
+	// warning with its elapsed time and retry count is logged once it finishes. Zero
+	// disables the warning.
+	SlowTransferThreshold time.Duration
+
+	// RecurringCatchUp decides how RunDueRecurring treats slots missed while no scheduler
+	// ran. Empty means CatchUpOnce.
+	RecurringCatchUp CatchUpPolicy
+
+	// RecurringBatchSize bounds the schedules one RunDueRecurring call runs. Zero means
+	// DefaultRecurringBatchSize.
+	RecurringBatchSize int
+
+	// Blackout lists daily windows during which transfers, batch transfers, and reversals
+	// fail with ErrTransferBlackout. Replays of an idempotency key already applied still
+	// succeed. The zero value never blacks out.
+	Blackout BlackoutSchedule
 }
 
 func DefaultTransferConfig() TransferServiceConfig {
 	return TransferServiceConfig{
-		MaxRetries:     3,
-		RetryBaseDelay: 100 * time.Millisecond,
+		MaxRetries:           3,
+		RetryBaseDelay:       100 * time.Millisecond,
+		RetryJitter:          true,
+		RetryOnCommitFailure: true,
+		RetryUnseenAccounts:  true,
+
+		EffectiveDateMaxPastDays:   30,
+		EffectiveDateMaxFutureDays: 0,
 	}
 }
 
+// commitError marks an error returned by COMMIT so the retry loop can apply
+// commit-specific rules. It unwraps to the driver error, preserving any PgError.
+type commitError struct {
+	err error
+}
+
+func (e *commitError) Error() string { return e.err.Error() }
+func (e *commitError) Unwrap() error { return e.err }
+
+// TransferMetrics receives transfer instrumentation events. *metrics.Metrics implements it.
+type TransferMetrics interface {
+	TransferAttempted()
+	TransferRetried()
+	TransferCompleted(d time.Duration, err error)
+}
+
+type noopTransferMetrics struct{}
+
+func (noopTransferMetrics) TransferAttempted()                     {}
+func (noopTransferMetrics) TransferRetried()                       {}
+func (noopTransferMetrics) TransferCompleted(time.Duration, error) {}
+
 type TransferService struct {
 	accountRepo     interfaces.AccountRepository
 	transactionRepo interfaces.TransactionRepository
 	config          TransferServiceConfig
+	zeroPolicy      ZeroBalancePolicy
+	hooks           []TransferHook
+	metrics         TransferMetrics
+	active          activeTransfers
 }
 
 func NewTransferService(
@@ -45,134 +201,726 @@ func NewTransferServiceWithConfig(
 		accountRepo:     accountRepo,
 		transactionRepo: transactionRepo,
 		config:          config,
+		zeroPolicy:      NewZeroBalancePolicy(config.ForbidZeroBalanceTypes),
+		metrics:         noopTransferMetrics{},
 	}
 }
 
-func (s *TransferService) Transfer(ctx context.Context, req *models.CreateTransactionRequest) (*models.Transaction, error) {
+// SetMetrics sets the collector that records transfer attempts, outcomes, retries, and latency.
+func (s *TransferService) SetMetrics(m TransferMetrics) {
+	s.metrics = m
+}
+
+func (s *TransferService) Transfer(ctx context.Context, req *models.CreateTransactionRequest) (txn *models.Transaction, err error) {
+	ctx, span := tracing.Start(ctx, "TransferService.Transfer",
+		tracing.AttrSourceAccountID.Int64(req.SourceAccountID),
+		tracing.AttrDestinationAccountID.Int64(req.DestinationAccountID),
+		tracing.AttrAmount.String(req.Amount),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	start := time.Now()
+	attempts := 0
+	s.metrics.TransferAttempted()
+	defer func() {
+		elapsed := time.Since(start)
+		s.metrics.TransferCompleted(elapsed, err)
+		s.warnIfSlow(ctx, req, elapsed, attempts, err)
+	}()
+
+	if debug := transferDebugFrom(ctx); debug != nil {
+		debug.MaxRetries = s.config.MaxRetries
+	}
+
 	if req.SourceAccountID == req.DestinationAccountID {
 		return nil, models.ErrSameAccount
 	}
 
-	amount, err := models.ParseMoney(req.Amount)
+	amount, err := models.ParseAmount(req.Amount)
+	if err != nil {
+		logging.FromContext(ctx).Debug().Err(err).Str("amount", req.Amount).Msg("Invalid transfer amount")
+		return nil, models.InvalidAmountError("amount", err)
+	}
+
+	effectiveDate, err := s.parseEffectiveDate(ctx, req.EffectiveDate)
 	if err != nil {
-		log.Debug().Err(err).Str("amount", req.Amount).Msg("Invalid amount format")
-		return nil, models.ErrInvalidAmount
+		return nil, err
+	}
+
+	draft := &models.Transaction{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               amount,
+		EffectiveDate:        effectiveDate,
+		IdempotencyKey:       req.IdempotencyKey,
+		Category:             req.Category,
+	}
+	if req.Sequence != nil {
+		if *req.Sequence <= 0 {
+			return nil, models.ErrStaleSequence
+		}
+		draft.Sequence = *req.Sequence
+	}
+
+	defer s.trackActive(ctx, draft)()
+
+	if draft.IdempotencyKey != "" {
+		existing, err := s.lookupIdempotent(ctx, draft)
+		if err != nil || existing != nil {
+			return existing, err
+		}
+	}
+
+	if err := s.checkTransferLimits(ctx, amount); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkBlackout(ctx); err != nil {
+		return nil, err
 	}
-	if amount.LessThanOrEqual(decimal.Zero) {
-		log.Debug().Str("amount", req.Amount).Msg("Amount must be positive")
-		return nil, models.ErrInvalidAmount
+
+	txn, attempts, err = s.executeWithRetry(ctx, draft)
+	return txn, err
+}
+
+// warnIfSlow logs a warning when a transfer took longer than SlowTransferThreshold.
+// attempts is how many times it was executed, zero if it failed before the first.
+func (s *TransferService) warnIfSlow(ctx context.Context, req *models.CreateTransactionRequest, elapsed time.Duration, attempts int, err error) {
+	threshold := s.config.SlowTransferThreshold
+	if threshold <= 0 || elapsed <= threshold {
+		return
+	}
+	logging.FromContext(ctx).Warn().
+		Err(err).
+		Int64("sourceAccountID", req.SourceAccountID).
+		Int64("destAccountID", req.DestinationAccountID).
+		Dur("elapsed", elapsed).
+		Dur("threshold", threshold).
+		Int("retries", max(attempts-1, 0)).
+		Msg("Slow transfer")
+}
+
+// Reverse undoes a transfer by moving its amount back from the destination to the source in
+// a new compensating transaction linked to the original via ReversalOf. The destination must
+// still hold enough balance. A transaction can be reversed only once; further attempts return
+// ErrAlreadyReversed. Deposits, withdrawals and transfers that did not complete cannot be
+// reversed (ErrNotReversible).
+func (s *TransferService) Reverse(ctx context.Context, transactionID int64) (*models.Transaction, error) {
+	original, err := s.transactionRepo.GetByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Type != models.TransactionTypeTransfer || original.Status != models.TransactionStatusCompleted {
+		return nil, models.ErrNotReversible
+	}
+
+	// Fails fast before locking accounts; the unique index on reversal_of still
+	// catches two reversals racing past this check.
+	if _, err := s.transactionRepo.GetReversal(ctx, transactionID); err == nil {
+		return nil, models.ErrAlreadyReversed
+	} else if !errors.Is(err, models.ErrTransferNotFound) {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to check for existing reversal", err)
+	}
+
+	draft := &models.Transaction{
+		SourceAccountID:      original.DestinationAccountID,
+		DestinationAccountID: original.SourceAccountID,
+		Amount:               original.Amount,
+		ReversalOf:           &original.TransactionID,
+	}
+
+	if err := s.checkBlackout(ctx); err != nil {
+		return nil, err
+	}
+
+	txn, _, err := s.executeWithRetry(ctx, draft)
+	if err != nil {
+		return nil, err
 	}
 
+	logging.FromContext(ctx).Info().
+		Int64("transactionID", txn.TransactionID).
+		Int64("reversalOf", transactionID).
+		Msg("Transaction reversed")
+
+	return txn, nil
+}
+
+// executeWithRetry runs executeTransfer for draft, retrying transient failures with
+// exponential backoff. If the transfer finally fails for a system reason rather than a
+// rejection, the attempt is recorded as a failed transaction. attempts is how many times
+// executeTransfer ran.
+func (s *TransferService) executeWithRetry(ctx context.Context, draft *models.Transaction) (_ *models.Transaction, attempts int, err error) {
+	defer func() {
+		if err != nil && failedForSystemReason(err) {
+			s.recordFailedTransfer(ctx, draft, err)
+		}
+	}()
+
 	var transaction *models.Transaction
 	var lastErr error
 
+	debug := transferDebugFrom(ctx)
+
 	for attempt := 0; attempt <= s.config.MaxRetries; attempt++ {
 		if attempt > 0 {
-			delay := s.config.RetryBaseDelay * time.Duration(1<<uint(attempt-1))
-			log.Debug().Int("attempt", attempt).Dur("delay", delay).Msg("Retrying transfer after transient error")
-
-			select {
-			case <-time.After(delay):
-			case <-ctx.Done():
-				return nil, ctx.Err()
+			if err := s.waitBeforeRetry(ctx, attempt); err != nil {
+				return nil, attempts, err
 			}
 		}
 
-		transaction, lastErr = s.executeTransfer(ctx, req.SourceAccountID, req.DestinationAccountID, amount)
+		attempts = attempt + 1
+		if debug != nil {
+			debug.Attempts = attempts
+		}
+
+		transaction, lastErr = s.executeTransfer(ctx, draft, attempt+1)
 		if lastErr == nil {
-			return transaction, nil
+			return transaction, attempts, nil
 		}
 
-		if !models.IsRetryable(lastErr) {
-			return nil, lastErr
+		// A concurrent request with the same idempotency key committed first
+		if draft.IdempotencyKey != "" && errors.Is(lastErr, models.ErrDuplicateTransaction) {
+			existing, err := s.lookupIdempotent(ctx, draft)
+			if err != nil {
+				return nil, attempts, err
+			}
+			if existing != nil {
+				return existing, attempts, nil
+			}
+			return nil, attempts, lastErr
 		}
 
-		log.Warn().Err(lastErr).Int("attempt", attempt+1).Int("maxRetries", s.config.MaxRetries).Msg("Transfer failed with retryable error")
+		if !s.shouldRetry(ctx, lastErr) {
+			return nil, attempts, lastErr
+		}
+
+		logging.FromContext(ctx).Warn().Err(lastErr).Int("attempt", attempt+1).Int("maxRetries", s.config.MaxRetries).Msg("Transfer failed with retryable error")
+	}
+
+	return nil, attempts, models.WrapError(models.CodeTransactionFailed, "transfer failed after retries", lastErr)
+}
+
+// waitBeforeRetry sleeps for the delay preceding retry number attempt (starting at 1)
+// and records the retry. It returns ctx.Err() if ctx ends first.
+func (s *TransferService) waitBeforeRetry(ctx context.Context, attempt int) error {
+	delay := s.retryDelay(attempt)
+	logging.FromContext(ctx).Debug().Int("attempt", attempt).Dur("delay", delay).Msg("Retrying transfer after transient error")
+	s.metrics.TransferRetried()
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryDelay returns the wait before retry number attempt (starting at 1): the
+// exponential backoff RetryBaseDelay * 2^(attempt-1), or with RetryJitter a uniformly
+// random duration from zero up to and including it.
+func (s *TransferService) retryDelay(attempt int) time.Duration {
+	backoff := s.config.RetryBaseDelay * time.Duration(1<<uint(attempt-1))
+	if !s.config.RetryJitter || backoff <= 0 {
+		return backoff
+	}
+	randN := s.config.JitterRand
+	if randN == nil {
+		randN = rand.Int64N
+	}
+	return time.Duration(randN(int64(backoff) + 1))
+}
+
+// parseEffectiveDate parses an optional YYYY-MM-DD effective date and checks it against the
+// configured window around today (UTC). An empty value returns the zero time, leaving the
+// database to default it to the commit date.
+func (s *TransferService) parseEffectiveDate(ctx context.Context, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(models.DateLayout, value)
+	if err != nil {
+		logging.FromContext(ctx).Debug().Err(err).Str("effectiveDate", value).Msg("Invalid effective date format")
+		return time.Time{}, models.ErrInvalidEffectiveDate
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	earliest := today.AddDate(0, 0, -s.config.EffectiveDateMaxPastDays)
+	latest := today.AddDate(0, 0, s.config.EffectiveDateMaxFutureDays)
+	if date.Before(earliest) || date.After(latest) {
+		logging.FromContext(ctx).Debug().
+			Str("effectiveDate", value).
+			Str("earliest", earliest.Format(models.DateLayout)).
+			Str("latest", latest.Format(models.DateLayout)).
+			Msg("Effective date outside allowed range")
+		return time.Time{}, models.ErrInvalidEffectiveDate
+	}
+
+	return date, nil
+}
+
+// checkTransferLimits enforces MinTransferAmount and MaxTransferAmount on amount. The
+// error message names the bound that was crossed.
+func (s *TransferService) checkTransferLimits(ctx context.Context, amount decimal.Decimal) error {
+	if lower := s.config.MinTransferAmount; lower.IsPositive() && amount.LessThan(lower) {
+		logging.FromContext(ctx).Debug().Str("amount", amount.String()).Str("min", lower.String()).Msg("Transfer amount below minimum")
+		return models.NewDomainError(models.CodeAmountBelowMinimum, fmt.Sprintf("%s of %s", models.ErrAmountBelowMinimum.Message, lower))
+	}
+	if upper := s.config.MaxTransferAmount; upper.IsPositive() && amount.GreaterThan(upper) {
+		logging.FromContext(ctx).Debug().Str("amount", amount.String()).Str("max", upper.String()).Msg("Transfer amount above maximum")
+		return models.NewDomainError(models.CodeAmountAboveMaximum, fmt.Sprintf("%s of %s", models.ErrAmountAboveMaximum.Message, upper))
+	}
+	return nil
+}
+
+// lookupIdempotent returns the transaction previously created with draft's idempotency key,
+// marked as Replayed, or nil if the key is unused. Reusing a key with a different
+// source, destination, amount, or explicitly requested effective date is rejected with
+// ErrIdempotencyKeyConflict.
+func (s *TransferService) lookupIdempotent(ctx context.Context, draft *models.Transaction) (*models.Transaction, error) {
+	existing, err := s.transactionRepo.GetByIdempotencyKey(ctx, draft.IdempotencyKey)
+	if errors.Is(err, models.ErrTransferNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to look up idempotency key", err)
+	}
+
+	if existing.SourceAccountID != draft.SourceAccountID ||
+		existing.DestinationAccountID != draft.DestinationAccountID ||
+		!existing.Amount.Equal(draft.Amount) ||
+		(!draft.EffectiveDate.IsZero() && !existing.EffectiveDate.Equal(draft.EffectiveDate)) {
+		logging.FromContext(ctx).Debug().
+			Str("idempotencyKey", draft.IdempotencyKey).
+			Int64("transactionID", existing.TransactionID).
+			Msg("Idempotency key reused with a different request")
+		return nil, models.ErrIdempotencyKeyConflict
 	}
 
-	return nil, models.WrapError(models.CodeTransactionFailed, "transfer failed after retries", lastErr)
+	logging.FromContext(ctx).Info().
+		Str("idempotencyKey", draft.IdempotencyKey).
+		Int64("transactionID", existing.TransactionID).
+		Msg("Replaying transfer for repeated idempotency key")
+
+	existing.Replayed = true
+	return existing, nil
 }
 
-func (s *TransferService) executeTransfer(ctx context.Context, sourceID, destID int64, amount decimal.Decimal) (*models.Transaction, error) {
+func (s *TransferService) shouldRetry(ctx context.Context, err error) bool {
+	var ce *commitError
+	if errors.As(err, &ce) {
+		return s.config.RetryOnCommitFailure && models.IsSerializationFailure(ce.err)
+	}
+	if errors.Is(err, models.ErrAccountNotFound) {
+		return s.config.RetryUnseenAccounts && s.accountMayBeUnseen(ctx)
+	}
+	return models.IsRetryable(err)
+}
+
+// accountMayBeUnseen distinguishes "not yet visible" from "truly missing": it reports true
+// only when the request carries a consistency token beyond what the database can see yet.
+func (s *TransferService) accountMayBeUnseen(ctx context.Context) bool {
+	token, ok := consistency.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	visible, err := s.accountRepo.VisibleLSN(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Warn().Err(err).Msg("Failed to read visible WAL position; treating account as missing")
+		return false
+	}
+
+	if visible >= token {
+		return false
+	}
+
+	logging.FromContext(ctx).Debug().
+		Str("token", token.String()).
+		Str("visible", visible.String()).
+		Msg("Account not found but consistency token is ahead; retrying")
+	return true
+}
+
+// executeTransfer applies draft in a single database transaction and returns the stored
+// copy. draft itself is not modified, so it can be retried; attempt (from 1) is recorded
+// on the span.
+func (s *TransferService) executeTransfer(ctx context.Context, draft *models.Transaction, attempt int) (_ *models.Transaction, err error) {
+	sourceID, destID, amount := draft.SourceAccountID, draft.DestinationAccountID, draft.Amount
+
+	ctx, span := tracing.Start(ctx, "TransferService.executeTransfer",
+		tracing.AttrSourceAccountID.Int64(sourceID),
+		tracing.AttrDestinationAccountID.Int64(destID),
+		tracing.AttrAmount.String(amount.String()),
+		tracing.AttrAttempt.Int(attempt),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	fee, err := s.config.Fees.feeFor(draft)
+	if err != nil {
+		logging.FromContext(ctx).Debug().
+			Str("amount", amount.String()).
+			Str("fee", s.config.Fees.Fee(amount).String()).
+			Msg("Transfer fee exceeds amount")
+		return nil, err
+	}
+	feeAccountID := s.config.Fees.AccountID
+
+	// The caller may have given up during the backoff before this attempt; don't take a
+	// connection and row locks for a result nobody will read
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	tx, err := s.accountRepo.BeginTx(ctx)
 	if err != nil {
-		return nil, models.WrapError(models.CodeDatabaseError, "failed to begin transaction", err)
+		return nil, beginTxError(err)
 	}
 	defer func() {
 		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
-			log.Error().Err(err).Msg("Failed to rollback transaction")
+			logging.FromContext(ctx).Error().Err(err).Msg("Failed to rollback transaction")
 		}
 	}()
 
-	// Lock accounts in consistent order (lower ID first) to prevent deadlocks
-	firstID, secondID := sourceID, destID
-	if firstID > secondID {
-		firstID, secondID = secondID, firstID
+	if debug := transferDebugFrom(ctx); debug != nil {
+		level, err := s.accountRepo.TxIsolationLevel(ctx, tx)
+		if err != nil {
+			logging.FromContext(ctx).Warn().Err(err).Msg("Failed to read isolation level for transfer debug info")
+		}
+		debug.IsolationLevel = level
 	}
 
-	first, err := s.accountRepo.GetByIDForUpdate(ctx, tx, firstID)
-	if err != nil {
-		return nil, err
+	// Lock accounts in consistent order (lower ID first) to prevent deadlocks. The fee
+	// account, when a fee is charged, is locked in the same order.
+	ids := []int64{sourceID, destID}
+	if fee.IsPositive() {
+		ids = append(ids, feeAccountID)
 	}
-	second, err := s.accountRepo.GetByIDForUpdate(ctx, tx, secondID)
-	if err != nil {
-		return nil, err
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	if s.advisoryLocks() {
+		for _, id := range ids {
+			if err := s.accountRepo.LockAccountAdvisory(ctx, tx, id); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	var sourceAccount, destAccount *models.Account
-	if firstID == sourceID {
-		sourceAccount, destAccount = first, second
-	} else {
-		sourceAccount, destAccount = second, first
+	locked := make([]*models.Account, len(ids))
+	accounts := make(map[int64]*models.Account, len(ids))
+	for i, id := range ids {
+		account, err := s.readAccount(ctx, tx, id)
+		if err != nil {
+			if fee.IsPositive() && id == feeAccountID && errors.Is(err, models.ErrAccountNotFound) {
+				logging.FromContext(ctx).Error().Int64("feeAccountID", id).Msg("Configured fee account does not exist")
+				return nil, models.NewDomainError(models.CodeInternalError, "fee account does not exist")
+			}
+			return nil, err
+		}
+		locked[i] = account
+		accounts[id] = account
 	}
+	sourceAccount, destAccount := accounts[sourceID], accounts[destID]
 
-	if sourceAccount.Balance.LessThan(amount) {
-		log.Debug().
+	// Closing bumps the version too, so in optimistic mode a close that races this read
+	// fails the CAS below and the retry sees the account closed
+	if sourceAccount.IsClosed() || destAccount.IsClosed() {
+		logging.FromContext(ctx).Debug().
+			Int64("sourceAccountID", sourceID).
+			Int64("destAccountID", destID).
+			Msg("Transfer involves a closed account")
+		return nil, models.ErrAccountClosed
+	}
+
+	if draft.ReversalOf == nil {
+		if err := s.checkCooldown(ctx, tx, sourceID); err != nil {
+			return nil, err
+		}
+		if err := s.checkVelocity(ctx, tx, sourceID, amount, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	// Checked under the source's row lock (or version check), so two requests with the same
+	// sequence can't both pass
+	if draft.Sequence != 0 && draft.Sequence <= sourceAccount.LastSequence {
+		logging.FromContext(ctx).Debug().
+			Int64("sourceAccountID", sourceID).
+			Int64("sequence", draft.Sequence).
+			Int64("lastSequence", sourceAccount.LastSequence).
+			Msg("Stale transfer sequence")
+		return nil, models.ErrStaleSequence
+	}
+
+	// The source is debited the fee on top of the amount, or the destination receives the
+	// amount less the fee
+	debit, credit := amount, amount
+	if fee.IsPositive() {
+		if s.config.Fees.Payer() == models.FeePayerDestination {
+			credit = credit.Sub(fee)
+		} else {
+			debit = debit.Add(fee)
+		}
+	}
+
+	// Checked against the available balance: the overdraft limit may be spent, funds
+	// reserved by holds may not
+	if sourceAccount.AvailableBalance().LessThan(debit) {
+		logging.FromContext(ctx).Debug().
 			Int64("sourceAccountID", sourceID).
 			Str("balance", sourceAccount.Balance.String()).
+			Str("overdraftLimit", sourceAccount.OverdraftLimit.String()).
+			Str("heldBalance", sourceAccount.HeldBalance.String()).
 			Str("amount", amount.String()).
+			Str("fee", fee.String()).
 			Msg("Insufficient balance for transfer")
 		return nil, models.ErrInsufficientBalance
 	}
 
-	newSourceBalance := sourceAccount.Balance.Sub(amount)
-	newDestBalance := destAccount.Balance.Add(amount)
+	newSourceBalance := sourceAccount.Balance.Sub(debit)
+	newDestBalance := destAccount.Balance.Add(credit)
 
-	if err := s.accountRepo.UpdateBalance(ctx, tx, sourceAccount.AccountID, newSourceBalance); err != nil {
-		return nil, models.WrapError(models.CodeDatabaseError, "failed to update source balance", err)
+	if !s.zeroPolicy.allowsDebit(sourceAccount, newSourceBalance) {
+		logging.FromContext(ctx).Debug().
+			Int64("sourceAccountID", sourceID).
+			Str("accountType", sourceAccount.AccountType).
+			Msg("Transfer would leave source at zero, which its account type forbids")
+		return nil, models.ErrInsufficientBalance
 	}
 
-	if err := s.accountRepo.UpdateBalance(ctx, tx, destAccount.AccountID, newDestBalance); err != nil {
-		return nil, models.WrapError(models.CodeDatabaseError, "failed to update destination balance", err)
+	// Checked under the destination's row lock (or version check), so concurrent credits
+	// can't race past the ceiling
+	if destAccount.MaxBalance.Valid && newDestBalance.GreaterThan(destAccount.MaxBalance.Decimal) {
+		logging.FromContext(ctx).Debug().
+			Int64("destAccountID", destID).
+			Str("balance", destAccount.Balance.String()).
+			Str("maxBalance", destAccount.MaxBalance.Decimal.String()).
+			Str("amount", amount.String()).
+			Msg("Transfer would exceed destination max balance")
+		return nil, models.ErrDestinationBalanceLimit
 	}
 
-	transaction := &models.Transaction{
-		SourceAccountID:      sourceID,
-		DestinationAccountID: destID,
-		Amount:               amount,
+	changes := []*models.BalanceChange{
+		{AccountID: sourceID, OldBalance: sourceAccount.Balance, NewBalance: newSourceBalance},
+		{AccountID: destID, OldBalance: destAccount.Balance, NewBalance: newDestBalance},
+	}
+	if fee.IsPositive() {
+		feeAccount := accounts[feeAccountID]
+		newFeeBalance := feeAccount.Balance.Add(fee)
+		if feeAccount.IsClosed() || (feeAccount.MaxBalance.Valid && newFeeBalance.GreaterThan(feeAccount.MaxBalance.Decimal)) {
+			logging.FromContext(ctx).Error().
+				Int64("feeAccountID", feeAccountID).
+				Str("fee", fee.String()).
+				Msg("Configured fee account cannot receive fees")
+			return nil, models.NewDomainError(models.CodeInternalError, "fee account cannot receive fees")
+		}
+		changes = append(changes, &models.BalanceChange{AccountID: feeAccountID, OldBalance: feeAccount.Balance, NewBalance: newFeeBalance})
 	}
-	if err := s.transactionRepo.Create(ctx, tx, transaction); err != nil {
+
+	// Written in lock order too: in optimistic mode the UPDATEs are what take the row locks
+	newBalances := make(map[int64]decimal.Decimal, len(changes))
+	for _, change := range changes {
+		newBalances[change.AccountID] = change.NewBalance
+	}
+	for _, account := range locked {
+		if err := s.writeBalance(ctx, tx, account, newBalances[account.AccountID]); err != nil {
+			return nil, wrapBalanceError("failed to update account balance", err)
+		}
+	}
+
+	if draft.Sequence != 0 {
+		if err := s.accountRepo.UpdateLastSequence(ctx, tx, sourceID, draft.Sequence); err != nil {
+			return nil, models.WrapError(models.CodeDatabaseError, "failed to record source sequence", err)
+		}
+	}
+
+	// The row only becomes visible when tx commits, together with the balance updates, so
+	// a synchronous transfer is recorded as completed from the start
+	transaction := *draft
+	transaction.Status = models.TransactionStatusCompleted
+	if fee.IsPositive() {
+		transaction.FeeAmount = fee
+		transaction.FeeAccountID = feeAccountID
+		transaction.FeePaidBy = s.config.Fees.Payer()
+	}
+	if err := s.transactionRepo.Create(ctx, tx, &transaction); err != nil {
+		if _, ok := models.IsDomainError(err); ok {
+			return nil, err
+		}
 		return nil, models.WrapError(models.CodeDatabaseError, "failed to create transaction record", err)
 	}
+	if err := postLedgerEntries(ctx, s.transactionRepo, tx, &transaction); err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		change.TransactionID = &transaction.TransactionID
+	}
+	if err := recordBalanceChanges(ctx, s.accountRepo, tx, changes...); err != nil {
+		return nil, err
+	}
+
+	if err := s.runPreCommitHooks(ctx, tx, &transaction); err != nil {
+		return nil, err
+	}
+
+	// A transfer canceled while waiting on row locks is rolled back rather than committed
+	// behind a caller that has already been told it failed
+	if err := ctx.Err(); err != nil {
+		logging.FromContext(ctx).Debug().Err(err).Msg("Transfer canceled before commit")
+		return nil, err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return nil, models.WrapError(models.CodeDatabaseError, "failed to commit transaction", err)
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to commit transaction", &commitError{err: err})
 	}
 
-	log.Info().
+	logging.FromContext(ctx).Info().
 		Int64("transactionID", transaction.TransactionID).
 		Int64("sourceAccountID", sourceID).
 		Int64("destAccountID", destID).
 		Str("amount", amount.String()).
+		Str("fee", transaction.FeeAmount.String()).
 		Msg("Transfer completed successfully")
 
-	return transaction, nil
+	s.runPostCommitHooks(ctx, &transaction)
+
+	return &transaction, nil
+}
+
+// checkCooldown fails with ErrCooldownActive, wrapping a *models.CooldownError, if
+// sourceID sent a transfer less than SourceCooldown ago. Callers hold the source's row
+// lock (or will fail its version check), so concurrent sends from one account can't both
+// pass.
+func (s *TransferService) checkCooldown(ctx context.Context, tx pgx.Tx, sourceID int64) error {
+	if s.config.SourceCooldown <= 0 {
+		return nil
+	}
+	elapsed, sent, err := s.transactionRepo.SinceLastOutboundTransfer(ctx, tx, sourceID)
+	if err != nil {
+		return models.WrapError(models.CodeDatabaseError, "failed to check transfer cooldown", err)
+	}
+	if !sent || elapsed >= s.config.SourceCooldown {
+		return nil
+	}
+
+	retryAfter := s.config.SourceCooldown - elapsed
+	logging.FromContext(ctx).Debug().
+		Int64("sourceAccountID", sourceID).
+		Dur("retryAfter", retryAfter).
+		Msg("Source account transfer cooldown active")
+	return models.WrapError(models.CodeCooldownActive, models.ErrCooldownActive.Message, &models.CooldownError{RetryAfter: retryAfter})
+}
+
+// checkVelocity fails with ErrVelocityLimitExceeded, wrapping a *models.VelocityLimitError,
+// if sending count more transfers totalling amount would take sourceID past its daily
+// amount or count limit. Like checkCooldown, callers hold the source's row lock (or will
+// fail its version check), so concurrent sends can't both squeeze under a limit.
+func (s *TransferService) checkVelocity(ctx context.Context, tx pgx.Tx, sourceID int64, amount decimal.Decimal, count int) error {
+	sent, err := s.transactionRepo.DailyOutbound(ctx, tx, sourceID)
+	if err != nil {
+		if errors.Is(err, models.ErrAccountNotFound) {
+			return err
+		}
+		return models.WrapError(models.CodeDatabaseError, "failed to check daily transfer limits", err)
+	}
+
+	amountLimit := s.config.DailyAmountLimit
+	if sent.AmountLimit.Valid {
+		amountLimit = sent.AmountLimit.Decimal
+	}
+	countLimit := int64(s.config.DailyCountLimit)
+	if sent.CountLimit != nil {
+		countLimit = *sent.CountLimit
+	}
+
+	var message string
+	switch {
+	case amountLimit.IsPositive() && sent.Amount.Add(amount).GreaterThan(amountLimit):
+		message = fmt.Sprintf("transfer would exceed the source account's daily limit of %s; %s already sent today", amountLimit, sent.Amount)
+	case countLimit > 0 && sent.Count+int64(count) > countLimit:
+		message = fmt.Sprintf("transfer would exceed the source account's daily limit of %d transfers; %d already sent today", countLimit, sent.Count)
+	default:
+		return nil
+	}
+
+	logging.FromContext(ctx).Debug().
+		Int64("sourceAccountID", sourceID).
+		Str("sentAmount", sent.Amount.String()).
+		Int64("sentCount", sent.Count).
+		Str("amount", amount.String()).
+		Time("resetsAt", sent.ResetsAt).
+		Msg("Source account daily transfer limit reached")
+	return models.WrapError(models.CodeVelocityLimit, message, &models.VelocityLimitError{ResetsAt: sent.ResetsAt})
+}
+
+// advisoryLocks reports whether transfers serialize on advisory locks rather than row
+// locks.
+func (s *TransferService) advisoryLocks() bool {
+	return s.config.ConcurrencyMode != ConcurrencyOptimistic && s.config.LockStrategy == LockAdvisory
+}
+
+// versionChecked reports whether writeBalance checks the version readAccount read.
+func (s *TransferService) versionChecked() bool {
+	return s.config.ConcurrencyMode == ConcurrencyOptimistic || s.advisoryLocks()
+}
+
+// readAccount loads an account for executeTransfer: locked FOR UPDATE in pessimistic mode,
+// or a plain read whose Version writeBalance later checks in optimistic mode or under
+// advisory locks. All read within tx, never from a replica whose lag would fail every
+// version check.
+func (s *TransferService) readAccount(ctx context.Context, tx pgx.Tx, accountID int64) (*models.Account, error) {
+	if s.versionChecked() {
+		return s.accountRepo.GetByIDInTx(ctx, tx, accountID)
+	}
+	return s.accountRepo.GetByIDForUpdate(ctx, tx, accountID)
+}
+
+// writeBalance stores balance for an account read by readAccount. In optimistic mode or
+// under advisory locks it fails with ErrConcurrentModification if the account changed
+// since it was read.
+func (s *TransferService) writeBalance(ctx context.Context, tx pgx.Tx, account *models.Account, balance decimal.Decimal) error {
+	if s.versionChecked() {
+		return s.accountRepo.UpdateBalanceCAS(ctx, tx, account.AccountID, balance, account.Version)
+	}
+	return s.accountRepo.UpdateBalance(ctx, tx, account.AccountID, balance)
+}
+
+// wrapBalanceError wraps a writeBalance failure as a database error, except
+// ErrConcurrentModification, which is passed through so the retry loop sees it.
+func wrapBalanceError(message string, err error) error {
+	if errors.Is(err, models.ErrConcurrentModification) {
+		return err
+	}
+	return models.WrapError(models.CodeDatabaseError, message, err)
 }
 
 func (s *TransferService) GetTransaction(ctx context.Context, transactionID int64) (*models.Transaction, error) {
 	return s.transactionRepo.GetByID(ctx, transactionID)
 }
 
+// GetAccountCategoryTotals returns the account's spent and received totals per category
+// for transactions with an effective date in [from, to], ordered by category. A zero
+// from or to leaves that end of the range open.
+func (s *TransferService) GetAccountCategoryTotals(ctx context.Context, accountID int64, from, to time.Time) ([]*models.CategoryTotal, error) {
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, models.ErrInvalidDateRange
+	}
+
+	if err := s.ensureAccountExists(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	totals, err := s.transactionRepo.SumByCategory(ctx, accountID, from, to)
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to summarize transactions by category", err)
+	}
+	return totals, nil
+}
+
+// DefaultPageSize and MaxPageSize are the default listing limits. Handlers clamp
+// requested page sizes against their own configured maximums before calling the service.
 const (
 	DefaultPageSize = 20
 	MaxPageSize     = 100
@@ -182,12 +930,160 @@ func (s *TransferService) GetAccountTransactions(ctx context.Context, accountID
 	if limit <= 0 {
 		limit = DefaultPageSize
 	}
-	if limit > MaxPageSize {
-		limit = MaxPageSize
-	}
 	if offset < 0 {
 		offset = 0
 	}
 
+	if err := s.ensureAccountExists(ctx, accountID); err != nil {
+		return nil, err
+	}
+
 	return s.transactionRepo.GetByAccountID(ctx, accountID, limit, offset)
 }
+
+// GetAccountTransactionsFiltered is GetAccountTransactions restricted to the transactions
+// within filter's bounds.
+func (s *TransferService) GetAccountTransactionsFiltered(ctx context.Context, accountID int64, filter models.TransactionFilter, limit, offset int) ([]*models.Transaction, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if err := s.ensureAccountExists(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	return s.transactionRepo.GetByAccountIDFiltered(ctx, accountID, filter, limit, offset)
+}
+
+// GetAccountTransactionsWithTotal is GetAccountTransactionsFiltered (GetAccountTransactions
+// for the zero filter) that also counts every transaction matching filter, so callers can
+// tell how many pages remain. The count runs separately from the page, so a transaction
+// committed in between may be counted without appearing.
+func (s *TransferService) GetAccountTransactionsWithTotal(ctx context.Context, accountID int64, filter models.TransactionFilter, limit, offset int) ([]*models.Transaction, int64, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if err := s.ensureAccountExists(ctx, accountID); err != nil {
+		return nil, 0, err
+	}
+
+	var txns []*models.Transaction
+	var err error
+	if filter.IsZero() {
+		txns, err = s.transactionRepo.GetByAccountID(ctx, accountID, limit, offset)
+	} else {
+		txns, err = s.transactionRepo.GetByAccountIDFiltered(ctx, accountID, filter, limit, offset)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.transactionRepo.CountByAccountID(ctx, accountID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return txns, total, nil
+}
+
+// GetAccountLedger returns an account's double-entry ledger entries, newest first. Debits
+// carry a negative amount and credits a positive one. Returns ErrAccountNotFound if the
+// account doesn't exist.
+func (s *TransferService) GetAccountLedger(ctx context.Context, accountID int64, limit, offset int) ([]*models.LedgerEntry, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if err := s.ensureAccountExists(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.transactionRepo.GetLedgerByAccountID(ctx, accountID, limit, offset)
+	if err != nil {
+		return nil, models.WrapError(models.CodeDatabaseError, "failed to list ledger entries", err)
+	}
+	return entries, nil
+}
+
+// GetAccountTransactionsAfter returns a keyset page of an account's transactions, newest
+// first, starting strictly after cursor (the zero cursor starts from the newest). nextCursor
+// is the cursor for the following page, or the zero cursor when there are no more transactions.
+func (s *TransferService) GetAccountTransactionsAfter(ctx context.Context, accountID int64, cursor models.TransactionCursor, limit int) (txns []*models.Transaction, nextCursor models.TransactionCursor, err error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if cursor.IsZero() {
+		cursor = models.StartCursor()
+	}
+
+	if err := s.ensureAccountExists(ctx, accountID); err != nil {
+		return nil, models.TransactionCursor{}, err
+	}
+
+	txns, err = s.transactionRepo.GetByAccountIDAfter(ctx, accountID, cursor, limit)
+	if err != nil {
+		return nil, models.TransactionCursor{}, err
+	}
+
+	if len(txns) == limit {
+		nextCursor = models.CursorAfter(txns[len(txns)-1])
+	}
+	return txns, nextCursor, nil
+}
+
+// StreamAccountTransactions walks an account's transactions newest first, one keyset page
+// of batchSize at a time, invoking fn for each, so a long history is never held in memory
+// at once. It returns ErrAccountNotFound, before any call to fn, if the account doesn't
+// exist. Iteration stops at the first error returned by fn or the repository.
+func (s *TransferService) StreamAccountTransactions(ctx context.Context, accountID int64, batchSize int, fn func(*models.Transaction) error) error {
+	if batchSize <= 0 {
+		batchSize = DefaultExportBatchSize
+	}
+
+	if err := s.ensureAccountExists(ctx, accountID); err != nil {
+		return err
+	}
+
+	cursor := models.StartCursor()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := s.transactionRepo.GetByAccountIDAfter(ctx, accountID, cursor, batchSize)
+		if err != nil {
+			return models.WrapError(models.CodeDatabaseError, "failed to list transactions", err)
+		}
+
+		for _, txn := range page {
+			if err := fn(txn); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < batchSize {
+			return nil
+		}
+		cursor = models.CursorAfter(page[len(page)-1])
+	}
+}
+
+func (s *TransferService) ensureAccountExists(ctx context.Context, accountID int64) error {
+	exists, err := s.accountRepo.Exists(ctx, accountID)
+	if err != nil {
+		return models.WrapError(models.CodeDatabaseError, "failed to check account existence", err)
+	}
+	if !exists {
+		return models.ErrAccountNotFound
+	}
+	return nil
+}
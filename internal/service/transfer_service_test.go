@@ -1,16 +1,28 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"internal-transfers-system/internal/consistency"
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/metrics"
 	"internal-transfers-system/internal/mocks"
 	"internal-transfers-system/internal/models"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/shopspring/decimal"
 )
 
@@ -98,6 +110,45 @@ func TestTransferService_Transfer(t *testing.T) {
 			},
 			expectedError: models.ErrInsufficientBalance,
 		},
+		{
+			name: "destination at balance ceiling",
+			request: &models.CreateTransactionRequest{
+				SourceAccountID:      1,
+				DestinationAccountID: 2,
+				Amount:               "100.01",
+			},
+			setupMock: func(accRepo *mocks.MockAccountRepository, _ *mocks.MockTransactionRepository) {
+				accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+				accRepo.SetAccount(&models.Account{
+					AccountID:  2,
+					Balance:    decimal.NewFromInt(400),
+					MaxBalance: decimal.NewNullDecimal(decimal.NewFromInt(500)),
+				})
+			},
+			expectedError: models.ErrDestinationBalanceLimit,
+		},
+		{
+			name: "destination reaches balance ceiling exactly",
+			request: &models.CreateTransactionRequest{
+				SourceAccountID:      1,
+				DestinationAccountID: 2,
+				Amount:               "100",
+			},
+			setupMock: func(accRepo *mocks.MockAccountRepository, _ *mocks.MockTransactionRepository) {
+				accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+				accRepo.SetAccount(&models.Account{
+					AccountID:  2,
+					Balance:    decimal.NewFromInt(400),
+					MaxBalance: decimal.NewNullDecimal(decimal.NewFromInt(500)),
+				})
+			},
+			validate: func(t *testing.T, _ *models.Transaction, accRepo *mocks.MockAccountRepository) {
+				dst, _ := accRepo.GetAccount(2)
+				if !dst.Balance.Equal(decimal.NewFromInt(500)) {
+					t.Errorf("expected destination balance 500, got %s", dst.Balance)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -125,6 +176,25 @@ func TestTransferService_Transfer(t *testing.T) {
 	}
 }
 
+func TestTransferService_ConstraintViolation(t *testing.T) {
+	for _, want := range []*models.DomainError{models.ErrAccountNotFound, models.ErrInvalidAmount} {
+		t.Run(string(want.Code), func(t *testing.T) {
+			accRepo := mocks.NewMockAccountRepository()
+			txnRepo := mocks.NewMockTransactionRepository()
+			accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+			accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+			// The repository reports a constraint the database caught as a domain error
+			txnRepo.CreateError = want
+
+			svc := NewTransferService(accRepo, txnRepo)
+			_, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "10"})
+			if code, _ := models.IsDomainError(err); code != want.Code {
+				t.Errorf("expected %s, got %v", want.Code, err)
+			}
+		})
+	}
+}
+
 func TestTransferService_LockOrdering(t *testing.T) {
 	accRepo := mocks.NewMockAccountRepository()
 	txnRepo := mocks.NewMockTransactionRepository()
@@ -192,26 +262,1604 @@ func TestTransferService_RetryOnDeadlock(t *testing.T) {
 	}
 }
 
-func TestTransferService_GetTransaction(t *testing.T) {
+func TestTransferService_ServiceBusy(t *testing.T) {
 	accRepo := mocks.NewMockAccountRepository()
-	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	accRepo.BeginTxError = models.ErrServiceBusy
 
-	txnRepo.SetTransaction(&models.Transaction{
-		TransactionID:        1,
-		SourceAccountID:      1,
-		DestinationAccountID: 2,
-		Amount:               decimal.NewFromInt(100),
+	config := TransferServiceConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond}
+	svc := NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), config)
+
+	ctx, debug := WithTransferDebug(context.Background())
+	_, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "10"})
+	if code, _ := models.IsDomainError(err); code != models.CodeServiceBusy {
+		t.Fatalf("expected service_busy, got %v", err)
+	}
+	// Retrying would only queue for the pool again; the client is told to back off instead
+	if debug.Attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", debug.Attempts)
+	}
+}
+
+func TestTransferService_SlowTransferWarning(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+
+	// The first attempt deadlocks; the retry is slowed down like a query waiting on a lock
+	var calls atomic.Int32
+	accRepo.OnGetByIDForUpdate = func(_ context.Context, _ interface{}, id int64) (*models.Account, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("deadlock detected")
+		}
+		time.Sleep(20 * time.Millisecond)
+		acc, _ := accRepo.GetAccountUnsafe(id)
+		return acc, nil
+	}
+
+	slowTransfers := func(threshold time.Duration) []map[string]interface{} {
+		calls.Store(0)
+		var buf bytes.Buffer
+		ctx := logging.WithLogger(context.Background(), zerolog.New(&buf))
+		config := TransferServiceConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond, SlowTransferThreshold: threshold}
+		svc := NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), config)
+		if _, err := svc.Transfer(ctx, &models.CreateTransactionRequest{
+			SourceAccountID: 1, DestinationAccountID: 2, Amount: "10",
+		}); err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+
+		var warnings []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("decode log line %q: %v", line, err)
+			}
+			if entry["message"] == "Slow transfer" {
+				warnings = append(warnings, entry)
+			}
+		}
+		return warnings
+	}
+
+	warnings := slowTransfers(10 * time.Millisecond)
+	if len(warnings) != 1 {
+		t.Fatalf("expected one slow transfer warning, got %v", warnings)
+	}
+	w := warnings[0]
+	if w["level"] != "warn" || w["sourceAccountID"] != float64(1) || w["destAccountID"] != float64(2) || w["retries"] != float64(1) {
+		t.Errorf("expected a warning naming both accounts and one retry, got %v", w)
+	}
+	if elapsed, _ := w["elapsed"].(float64); elapsed < 10 {
+		t.Errorf("expected the elapsed time past the 10ms threshold, got %v", w["elapsed"])
+	}
+
+	if warnings := slowTransfers(time.Minute); len(warnings) != 0 {
+		t.Errorf("expected no warning under the threshold, got %v", warnings)
+	}
+	if warnings := slowTransfers(0); len(warnings) != 0 {
+		t.Errorf("expected no warning with the threshold disabled, got %v", warnings)
+	}
+}
+
+// commitCountingRepo counts the transactions begun and committed through it.
+type commitCountingRepo struct {
+	*mocks.MockAccountRepository
+	begun, commits atomic.Int32
+}
+
+func (r *commitCountingRepo) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	tx, err := r.MockAccountRepository.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.begun.Add(1)
+	return &commitCountingTx{Tx: tx, commits: &r.commits}, nil
+}
+
+type commitCountingTx struct {
+	pgx.Tx
+	commits *atomic.Int32
+}
+
+func (tx *commitCountingTx) Commit(ctx context.Context) error {
+	tx.commits.Add(1)
+	return tx.Tx.Commit(ctx)
+}
+
+func TestTransferService_Canceled(t *testing.T) {
+	newRepo := func() *commitCountingRepo {
+		accRepo := &commitCountingRepo{MockAccountRepository: mocks.NewMockAccountRepository()}
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+		return accRepo
+	}
+	req := &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100"}
+
+	t.Run("while waiting for a row lock", func(t *testing.T) {
+		accRepo := newRepo()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// The first lock is granted only once the caller has gone away
+		locking := make(chan struct{})
+		var calls atomic.Int32
+		accRepo.OnGetByIDForUpdate = func(ctx context.Context, _ interface{}, id int64) (*models.Account, error) {
+			if calls.Add(1) == 1 {
+				close(locking)
+				<-ctx.Done()
+			}
+			acc, _ := accRepo.GetAccountUnsafe(id)
+			return acc, nil
+		}
+
+		svc := NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), TransferServiceConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond})
+		done := make(chan error, 1)
+		go func() {
+			_, err := svc.Transfer(ctx, req)
+			done <- err
+		}()
+
+		<-locking
+		cancel()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("transfer did not return after cancellation")
+		}
+		if n := accRepo.commits.Load(); n != 0 {
+			t.Errorf("expected no commit, got %d", n)
+		}
+		if n := accRepo.begun.Load(); n != 1 {
+			t.Errorf("expected a canceled transfer not to be retried, got %d transactions", n)
+		}
+	})
+
+	t.Run("before the transaction begins", func(t *testing.T) {
+		accRepo := newRepo()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		svc := NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+		if _, err := svc.Transfer(ctx, req); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		if n := accRepo.begun.Load(); n != 0 {
+			t.Errorf("expected no transaction to begin, got %d", n)
+		}
+	})
+}
+
+func TestTransferService_RetryDelay(t *testing.T) {
+	base := 10 * time.Millisecond
+
+	t.Run("without jitter", func(t *testing.T) {
+		svc := NewTransferServiceWithConfig(nil, nil, TransferServiceConfig{RetryBaseDelay: base})
+		for attempt, want := range map[int]time.Duration{1: base, 2: 2 * base, 3: 4 * base} {
+			if got := svc.retryDelay(attempt); got != want {
+				t.Errorf("attempt %d: expected %s, got %s", attempt, want, got)
+			}
+		}
+	})
+
+	t.Run("jitter bounds", func(t *testing.T) {
+		var gotN int64
+		extreme := int64(0)
+		svc := NewTransferServiceWithConfig(nil, nil, TransferServiceConfig{
+			RetryBaseDelay: base,
+			RetryJitter:    true,
+			JitterRand:     func(n int64) int64 { gotN = n; return extreme },
+		})
+		if got := svc.retryDelay(3); got != 0 || gotN != int64(4*base)+1 {
+			t.Errorf("expected the draw over [0, %s], got %s from n=%d", 4*base, got, gotN)
+		}
+		extreme = int64(4 * base)
+		if got := svc.retryDelay(3); got != 4*base {
+			t.Errorf("expected the top of the range to be the full backoff, got %s", got)
+		}
+	})
+
+	t.Run("default source", func(t *testing.T) {
+		svc := NewTransferServiceWithConfig(nil, nil, TransferServiceConfig{RetryBaseDelay: base, RetryJitter: true})
+		for i := 0; i < 100; i++ {
+			if got := svc.retryDelay(2); got < 0 || got > 2*base {
+				t.Fatalf("expected a delay within [0, %s], got %s", 2*base, got)
+			}
+		}
+	})
+}
+
+// racingAccountRepo updates the first account read, as if another transfer committed
+// between this transfer's read and its write.
+type racingAccountRepo struct {
+	*mocks.MockAccountRepository
+	raced atomic.Bool
+}
+
+func (r *racingAccountRepo) GetByIDInTx(ctx context.Context, tx pgx.Tx, id int64) (*models.Account, error) {
+	acc, err := r.MockAccountRepository.GetByIDInTx(ctx, tx, id)
+	if err == nil && r.raced.CompareAndSwap(false, true) {
+		r.MockAccountRepository.UpdateBalance(ctx, nil, id, acc.Balance.Add(decimal.NewFromInt(1)))
+	}
+	return acc, err
+}
+
+func TestTransferService_Optimistic(t *testing.T) {
+	accRepo := &racingAccountRepo{MockAccountRepository: mocks.NewMockAccountRepository()}
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	accRepo.OnGetByIDForUpdate = func(context.Context, interface{}, int64) (*models.Account, error) {
+		t.Error("optimistic transfers must not lock accounts")
+		return nil, errors.New("unexpected lock")
+	}
+
+	config := TransferServiceConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond, ConcurrencyMode: ConcurrencyOptimistic}
+	svc := NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), config)
+
+	if _, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+	}); err != nil {
+		t.Fatalf("expected success after the version conflict was retried, got: %v", err)
+	}
+
+	// The racing +1 on account 1 must not be overwritten by the retried transfer
+	source, _ := accRepo.GetAccountUnsafe(1)
+	dest, _ := accRepo.GetAccountUnsafe(2)
+	if !source.Balance.Equal(decimal.NewFromInt(901)) || !dest.Balance.Equal(decimal.NewFromInt(600)) {
+		t.Errorf("expected balances 901 and 600, got %s and %s", source.Balance, dest.Balance)
+	}
+}
+
+func TestTransferService_AdvisoryLocks(t *testing.T) {
+	accRepo := &racingAccountRepo{MockAccountRepository: mocks.NewMockAccountRepository()}
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	accRepo.OnGetByIDForUpdate = func(context.Context, interface{}, int64) (*models.Account, error) {
+		t.Error("advisory-locked transfers must not take row locks")
+		return nil, errors.New("unexpected lock")
+	}
+
+	config := TransferServiceConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond, LockStrategy: LockAdvisory}
+	svc := NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), config)
+
+	if _, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+		SourceAccountID: 2, DestinationAccountID: 1, Amount: "100",
+	}); err != nil {
+		t.Fatalf("expected success after the version conflict was retried, got: %v", err)
+	}
+
+	// Locks are taken in account ID order on every attempt, whatever the direction
+	locks := accRepo.AdvisoryLocks()
+	if len(locks) != 4 || locks[0] != 1 || locks[1] != 2 || locks[2] != 1 || locks[3] != 2 {
+		t.Errorf("expected advisory locks [1 2 1 2] over two attempts, got %v", locks)
+	}
+
+	// The racing +1 on account 1, a writer that takes no advisory lock, must survive
+	source, _ := accRepo.GetAccountUnsafe(2)
+	dest, _ := accRepo.GetAccountUnsafe(1)
+	if !source.Balance.Equal(decimal.NewFromInt(400)) || !dest.Balance.Equal(decimal.NewFromInt(1101)) {
+		t.Errorf("expected balances 400 and 1101, got %s and %s", source.Balance, dest.Balance)
+	}
+}
+
+func TestTransferService_OptimisticRetriesExhausted(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	accRepo.UpdateBalanceCASError = models.ErrConcurrentModification
+
+	config := TransferServiceConfig{MaxRetries: 2, RetryBaseDelay: time.Millisecond, ConcurrencyMode: ConcurrencyOptimistic}
+	svc := NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), config)
+
+	_, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+	})
+	if code, _ := models.IsDomainError(err); code != models.CodeTransactionFailed {
+		t.Fatalf("expected transaction_failed once retries run out, got: %v", err)
+	}
+	if !errors.Is(err, models.ErrConcurrentModification) {
+		t.Errorf("expected the last conflict as the cause, got: %v", err)
+	}
+}
+
+func TestTransferService_BalanceHistory(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	svc := NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+
+	txn, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+		SourceAccountID: 2, DestinationAccountID: 1, Amount: "100",
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	history := accRepo.BalanceHistory()
+	if len(history) != 2 {
+		t.Fatalf("expected one change per account, got %d", len(history))
+	}
+	for _, change := range history {
+		if change.TransactionID == nil || *change.TransactionID != txn.TransactionID {
+			t.Errorf("expected change linked to transaction %d, got %+v", txn.TransactionID, change)
+		}
+		want := map[int64][2]int64{1: {1000, 1100}, 2: {500, 400}}[change.AccountID]
+		if !change.OldBalance.Equal(decimal.NewFromInt(want[0])) || !change.NewBalance.Equal(decimal.NewFromInt(want[1])) {
+			t.Errorf("account %d: expected %d -> %d, got %s -> %s", change.AccountID, want[0], want[1], change.OldBalance, change.NewBalance)
+		}
+	}
+
+	// A failure to record history fails the transfer, so it rolls back with the balances
+	accRepo.RecordChangeError = errors.New("insert failed")
+	_, err = svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+		SourceAccountID: 2, DestinationAccountID: 1, Amount: "100",
 	})
+	if code, _ := models.IsDomainError(err); code != models.CodeDatabaseError {
+		t.Errorf("expected database_error when history can't be recorded, got: %v", err)
+	}
+}
 
+func TestTransferService_Status(t *testing.T) {
+	ctx := context.Background()
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	txnRepo := mocks.NewMockTransactionRepository()
 	svc := NewTransferService(accRepo, txnRepo)
 
-	txn, err := svc.GetTransaction(context.Background(), 1)
-	if err != nil || txn.TransactionID != 1 {
-		t.Errorf("expected transaction 1, got err=%v", err)
+	txn, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100"})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if txn.Status != models.TransactionStatusCompleted {
+		t.Errorf("expected a completed transfer, got %q", txn.Status)
+	}
+	stored, err := svc.GetTransaction(ctx, txn.TransactionID)
+	if err != nil || stored.Status != models.TransactionStatusCompleted {
+		t.Errorf("expected the stored transfer to be completed, got %+v, %v", stored, err)
 	}
 
-	_, err = svc.GetTransaction(context.Background(), 999)
-	if !errors.Is(err, models.ErrTransferNotFound) {
-		t.Errorf("expected ErrTransferNotFound, got %v", err)
+	// Rejections aren't failures and leave nothing behind
+	if _, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "5000"}); !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+	if txns, _ := txnRepo.GetByAccountID(ctx, 1, 10, 0); len(txns) != 1 {
+		t.Errorf("expected a rejected transfer to record nothing, got %d transactions", len(txns))
+	}
+
+	// A database error records a failed row without the idempotency key
+	accRepo.UpdateBalanceError = errors.New("disk full")
+	req := &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "50", IdempotencyKey: "key-1"}
+	if _, err := svc.Transfer(ctx, req); err == nil {
+		t.Fatal("expected the transfer to fail")
+	}
+	txns, _ := txnRepo.GetByAccountID(ctx, 1, 10, 0)
+	if len(txns) != 2 || txns[0].Status != models.TransactionStatusFailed || txns[0].IdempotencyKey != "" {
+		t.Fatalf("expected a failed row without an idempotency key, got %+v", txns)
+	}
+	failed := txns[0]
+	if failed.SourceAccountID != 1 || failed.DestinationAccountID != 2 || failed.Amount.String() != "50" {
+		t.Errorf("expected the failed row to describe the attempt, got %+v", failed)
+	}
+
+	// The client's retry with the same key goes through
+	accRepo.UpdateBalanceError = nil
+	retried, err := svc.Transfer(ctx, req)
+	if err != nil || retried.Replayed || retried.Status != models.TransactionStatusCompleted {
+		t.Errorf("expected the retry to complete as a new transfer, got %+v, %v", retried, err)
+	}
+}
+
+func TestTransferService_FailedReversalDoesNotBlockReversal(t *testing.T) {
+	ctx := context.Background()
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	txnRepo := mocks.NewMockTransactionRepository()
+	svc := NewTransferService(accRepo, txnRepo)
+
+	txn, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100"})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	accRepo.UpdateBalanceError = errors.New("disk full")
+	if _, err := svc.Reverse(ctx, txn.TransactionID); err == nil {
+		t.Fatal("expected the reversal to fail")
+	}
+	accRepo.UpdateBalanceError = nil
+
+	reversal, err := svc.Reverse(ctx, txn.TransactionID)
+	if err != nil {
+		t.Fatalf("expected the reversal to succeed after a failed attempt, got %v", err)
+	}
+	if reversal.Status != models.TransactionStatusCompleted || *reversal.ReversalOf != txn.TransactionID {
+		t.Errorf("expected a completed reversal of %d, got %+v", txn.TransactionID, reversal)
+	}
+}
+
+func TestFailedForSystemReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"database error", models.WrapError(models.CodeDatabaseError, "update failed", errors.New("disk full")), true},
+		{"retries exhausted", models.WrapError(models.CodeTransactionFailed, "transfer failed after retries", errors.New("deadlock detected")), true},
+		{"hook error", models.WrapError(models.CodeInternalError, "pre-commit hook failed", errors.New("boom")), true},
+		{"rejection", models.ErrInsufficientBalance, false},
+		{"missing account", models.ErrAccountNotFound, false},
+		{"cancelled", context.Canceled, false},
+		{"commit outcome unknown", models.WrapError(models.CodeDatabaseError, "failed to commit transaction", &commitError{err: errors.New("connection reset")}), false},
+		{"commit serialization failure", models.WrapError(models.CodeDatabaseError, "failed to commit transaction", &commitError{err: &pgconn.PgError{Code: "40001"}}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failedForSystemReason(tt.err); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTransferService_LedgerEntries(t *testing.T) {
+	ctx := context.Background()
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+	txnRepo := mocks.NewMockTransactionRepository()
+	svc := NewTransferService(accRepo, txnRepo)
+
+	txn, err := svc.Transfer(ctx, &models.CreateTransactionRequest{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: "100.25",
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	entries := txnRepo.LedgerEntries()
+	if len(entries) != 2 {
+		t.Fatalf("expected a debit and a credit, got %d entries", len(entries))
+	}
+	debit, credit := entries[0], entries[1]
+	if debit.AccountID != 1 || debit.Amount.String() != "-100.25" || !debit.IsDebit() {
+		t.Errorf("expected a -100.25 debit of account 1, got %+v", debit)
+	}
+	if credit.AccountID != 2 || credit.Amount.String() != "100.25" || credit.IsDebit() {
+		t.Errorf("expected a 100.25 credit of account 2, got %+v", credit)
+	}
+	if debit.TransactionID != txn.TransactionID || credit.TransactionID != txn.TransactionID {
+		t.Errorf("expected both entries to reference transaction %d, got %d and %d", txn.TransactionID, debit.TransactionID, credit.TransactionID)
+	}
+	if !debit.Amount.Add(credit.Amount).IsZero() {
+		t.Errorf("expected the entries to balance, got %s + %s", debit.Amount, credit.Amount)
+	}
+
+	ledger, err := svc.GetAccountLedger(ctx, 2, 10, 0)
+	if err != nil || len(ledger) != 1 || ledger[0].EntryID != credit.EntryID {
+		t.Errorf("expected account 2's ledger to hold only the credit, got %+v, %v", ledger, err)
+	}
+	if _, err := svc.GetAccountLedger(ctx, 99, 10, 0); !errors.Is(err, models.ErrAccountNotFound) {
+		t.Errorf("expected ErrAccountNotFound, got %v", err)
+	}
+}
+
+func TestTransferService_Fees(t *testing.T) {
+	ctx := context.Background()
+	newService := func(payer models.FeePayer) (*TransferService, *mocks.MockAccountRepository, *mocks.MockTransactionRepository) {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+		accRepo.SetAccount(&models.Account{AccountID: 9, Balance: decimal.NewFromInt(0)})
+		txnRepo := mocks.NewMockTransactionRepository()
+		config := DefaultTransferConfig()
+		config.Fees = FeePolicy{AccountID: 9, Flat: decimal.NewFromInt(1), Percent: decimal.NewFromInt(2), PaidBy: payer}
+		return NewTransferServiceWithConfig(accRepo, txnRepo, config), accRepo, txnRepo
+	}
+	balances := func(accRepo *mocks.MockAccountRepository) []string {
+		var got []string
+		for _, id := range []int64{1, 2, 9} {
+			acc, _ := accRepo.GetAccount(id)
+			got = append(got, acc.Balance.String())
+		}
+		return got
+	}
+
+	tests := []struct {
+		payer        models.FeePayer
+		wantBalances []string
+		wantEntries  []string
+	}{
+		{models.FeePayerSource, []string{"48", "50", "2"}, []string{"-52", "50", "2"}},
+		{models.FeePayerDestination, []string{"50", "48", "2"}, []string{"-50", "48", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.payer), func(t *testing.T) {
+			svc, accRepo, txnRepo := newService(tt.payer)
+
+			// 1 flat plus 2% of 50
+			txn, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "50"})
+			if err != nil {
+				t.Fatalf("transfer: %v", err)
+			}
+			if txn.Amount.String() != "50" || txn.FeeAmount.String() != "2" || txn.FeeAccountID != 9 || txn.FeePaidBy != tt.payer {
+				t.Errorf("expected a 50 transfer with a 2 fee to account 9, got %+v", txn)
+			}
+			if got := balances(accRepo); fmt.Sprint(got) != fmt.Sprint(tt.wantBalances) {
+				t.Errorf("expected balances %v, got %v", tt.wantBalances, got)
+			}
+
+			var entries []string
+			for _, entry := range txnRepo.LedgerEntries() {
+				entries = append(entries, entry.Amount.String())
+			}
+			if fmt.Sprint(entries) != fmt.Sprint(tt.wantEntries) {
+				t.Errorf("expected ledger entries %v, got %v", tt.wantEntries, entries)
+			}
+			if history := accRepo.BalanceHistory(); len(history) != 3 {
+				t.Errorf("expected a balance change for each of the three accounts, got %d", len(history))
+			}
+
+			stored, _ := txnRepo.GetByID(ctx, txn.TransactionID)
+			if !stored.FeeAmount.Equal(txn.FeeAmount) {
+				t.Errorf("expected the fee to be stored, got %s", stored.FeeAmount)
+			}
+		})
+	}
+
+	t.Run("reversal is free", func(t *testing.T) {
+		svc, accRepo, _ := newService(models.FeePayerSource)
+		txn, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "50"})
+		if err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+		reversal, err := svc.Reverse(ctx, txn.TransactionID)
+		if err != nil {
+			t.Fatalf("reverse: %v", err)
+		}
+		// The amount comes back; the fee is not refunded
+		if !reversal.FeeAmount.IsZero() || fmt.Sprint(balances(accRepo)) != "[98 0 2]" {
+			t.Errorf("expected a free reversal leaving [98 0 2], got a %s fee and %v", reversal.FeeAmount, balances(accRepo))
+		}
+	})
+
+	t.Run("fee counts against the balance", func(t *testing.T) {
+		svc, accRepo, _ := newService(models.FeePayerSource)
+		_, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "99"})
+		if !errors.Is(err, models.ErrInsufficientBalance) {
+			t.Errorf("expected ErrInsufficientBalance for 99 plus a 2.98 fee, got %v", err)
+		}
+		if got := balances(accRepo); fmt.Sprint(got) != "[100 0 0]" {
+			t.Errorf("expected balances unchanged, got %v", got)
+		}
+	})
+
+	t.Run("fee exceeds amount", func(t *testing.T) {
+		svc, _, _ := newService(models.FeePayerDestination)
+		_, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "1"})
+		if !errors.Is(err, models.ErrFeeExceedsAmount) {
+			t.Errorf("expected ErrFeeExceedsAmount for a 1.02 fee on 1, got %v", err)
+		}
+	})
+
+	t.Run("missing fee account", func(t *testing.T) {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+		config := DefaultTransferConfig()
+		config.Fees = FeePolicy{AccountID: 9, Flat: decimal.NewFromInt(1)}
+		svc := NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), config)
+
+		_, err := svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "10"})
+		if code, _ := models.IsDomainError(err); code != models.CodeInternalError || errors.Is(err, models.ErrAccountNotFound) {
+			t.Errorf("expected an internal error rather than account not found, got %v", err)
+		}
+	})
+}
+
+func TestTransferService_Metrics(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+
+	var calls atomic.Int32
+	accRepo.OnGetByIDForUpdate = func(_ context.Context, _ interface{}, id int64) (*models.Account, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("deadlock detected")
+		}
+		acc, _ := accRepo.GetAccountUnsafe(id)
+		return acc, nil
+	}
+
+	m := metrics.New()
+	config := TransferServiceConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond}
+	svc := NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), config)
+	svc.SetMetrics(m)
+
+	ctx := context.Background()
+	svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100"})
+	svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "5000"})
+	svc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 1, Amount: "1"})
+
+	if got := testutil.ToFloat64(m.TransferAttempts); got != 3 {
+		t.Errorf("attempts: expected 3, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.TransferSuccesses); got != 1 {
+		t.Errorf("successes: expected 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.TransferRetries); got != 1 {
+		t.Errorf("retries: expected 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.TransferFailures.WithLabelValues(string(models.CodeInsufficientBalance))); got != 1 {
+		t.Errorf("insufficient_balance failures: expected 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.TransferFailures.WithLabelValues(string(models.CodeSameAccount))); got != 1 {
+		t.Errorf("same_account failures: expected 1, got %v", got)
+	}
+}
+
+func TestTransferService_GetTransaction(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+
+	txnRepo.SetTransaction(&models.Transaction{
+		TransactionID:        1,
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+	})
+
+	svc := NewTransferService(accRepo, txnRepo)
+
+	txn, err := svc.GetTransaction(context.Background(), 1)
+	if err != nil || txn.TransactionID != 1 {
+		t.Errorf("expected transaction 1, got err=%v", err)
+	}
+
+	_, err = svc.GetTransaction(context.Background(), 999)
+	if !errors.Is(err, models.ErrTransferNotFound) {
+		t.Errorf("expected ErrTransferNotFound, got %v", err)
+	}
+}
+
+func TestTransferService_GetAccountCategoryTotals(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(1000)})
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	for i, txn := range []*models.Transaction{
+		{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.RequireFromString("10.50"), Category: "rent", EffectiveDate: day(1)},
+		{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.RequireFromString("4.50"), Category: "rent", EffectiveDate: day(5)},
+		{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(7), Category: "rent", EffectiveDate: day(5)},
+		{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(3), EffectiveDate: day(10)},
+		{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(99), Category: "food", EffectiveDate: day(20)},
+	} {
+		txn.TransactionID = int64(i + 1)
+		txnRepo.SetTransaction(txn)
+	}
+	svc := NewTransferService(accRepo, txnRepo)
+	ctx := context.Background()
+
+	t.Run("whole history", func(t *testing.T) {
+		totals, err := svc.GetAccountCategoryTotals(ctx, 1, time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(totals) != 3 {
+			t.Fatalf("expected 3 categories, got %d", len(totals))
+		}
+		if totals[0].Category != "" || !totals[0].Received.Equal(decimal.NewFromInt(3)) {
+			t.Errorf("expected uncategorized first with 3 received, got %+v", totals[0])
+		}
+		rent := totals[2]
+		if rent.Category != "rent" || !rent.Spent.Equal(decimal.NewFromInt(15)) ||
+			!rent.Received.Equal(decimal.NewFromInt(7)) || rent.Count != 3 {
+			t.Errorf("unexpected rent totals: %+v", rent)
+		}
+	})
+
+	t.Run("date range is inclusive", func(t *testing.T) {
+		totals, err := svc.GetAccountCategoryTotals(ctx, 1, day(5), day(10))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(totals) != 2 || totals[1].Category != "rent" || totals[1].Count != 2 {
+			t.Errorf("expected uncategorized and two rent entries, got %+v", totals)
+		}
+	})
+
+	t.Run("inverted range", func(t *testing.T) {
+		_, err := svc.GetAccountCategoryTotals(ctx, 1, day(10), day(5))
+		if !errors.Is(err, models.ErrInvalidDateRange) {
+			t.Errorf("expected ErrInvalidDateRange, got %v", err)
+		}
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		_, err := svc.GetAccountCategoryTotals(ctx, 999, time.Time{}, time.Time{})
+		if !errors.Is(err, models.ErrAccountNotFound) {
+			t.Errorf("expected ErrAccountNotFound, got %v", err)
+		}
+	})
+}
+
+func TestTransferService_RetryOnCommitFailure(t *testing.T) {
+	serializationErr := &pgconn.PgError{Code: "40001", Message: "could not serialize access due to read/write dependencies among transactions"}
+
+	tests := []struct {
+		name       string
+		retry      bool
+		commitErrs []error
+		wantErr    bool
+	}{
+		{"serialization failure retried", true, []error{serializationErr}, false},
+		{"serialization failure terminal when disabled", false, []error{serializationErr}, true},
+		{"unknown commit outcome never retried", true, []error{errors.New("connection reset by peer")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accRepo := mocks.NewMockAccountRepository()
+			txnRepo := mocks.NewMockTransactionRepository()
+			accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+			accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+			accRepo.CommitErrors = tt.commitErrs
+
+			config := TransferServiceConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond, RetryOnCommitFailure: tt.retry}
+			svc := NewTransferServiceWithConfig(accRepo, txnRepo, config)
+
+			_, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+				SourceAccountID:      1,
+				DestinationAccountID: 2,
+				Amount:               "100.00",
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr=%v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr && !errors.Is(err, tt.commitErrs[0]) {
+				t.Errorf("expected commit error to be preserved, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTransferService_IdempotencyKey(t *testing.T) {
+	newService := func() (*TransferService, *mocks.MockAccountRepository) {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+		return NewTransferService(accRepo, mocks.NewMockTransactionRepository()), accRepo
+	}
+	request := func(amount string) *models.CreateTransactionRequest {
+		return &models.CreateTransactionRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               amount,
+			IdempotencyKey:       "key-123",
+		}
+	}
+
+	t.Run("first seen", func(t *testing.T) {
+		svc, _ := newService()
+		txn, err := svc.Transfer(context.Background(), request("100"))
+		if err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		if txn.Replayed {
+			t.Error("first request must not be marked replayed")
+		}
+	})
+
+	t.Run("replay returns original without moving money", func(t *testing.T) {
+		svc, accRepo := newService()
+		first, _ := svc.Transfer(context.Background(), request("100"))
+
+		second, err := svc.Transfer(context.Background(), request("100.00"))
+		if err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		if !second.Replayed || second.TransactionID != first.TransactionID {
+			t.Errorf("expected replay of %d, got %+v", first.TransactionID, second)
+		}
+		src, _ := accRepo.GetAccount(1)
+		if !src.Balance.Equal(decimal.NewFromInt(900)) {
+			t.Errorf("expected money moved once (900), got %s", src.Balance)
+		}
+	})
+
+	t.Run("different body conflicts", func(t *testing.T) {
+		svc, _ := newService()
+		svc.Transfer(context.Background(), request("100"))
+
+		_, err := svc.Transfer(context.Background(), request("200"))
+		if !errors.Is(err, models.ErrIdempotencyKeyConflict) {
+			t.Errorf("expected ErrIdempotencyKeyConflict, got %v", err)
+		}
+	})
+
+	t.Run("concurrent insert of same key replays winner", func(t *testing.T) {
+		accRepo := mocks.NewMockAccountRepository()
+		txnRepo := mocks.NewMockTransactionRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+		svc := NewTransferService(accRepo, txnRepo)
+
+		// Simulate a racing request committing between our lookup and insert
+		accRepo.OnGetByIDForUpdate = func(_ context.Context, _ interface{}, id int64) (*models.Account, error) {
+			if id == 1 {
+				txnRepo.SetTransaction(&models.Transaction{
+					TransactionID: 77, SourceAccountID: 1, DestinationAccountID: 2,
+					Amount: decimal.NewFromInt(100), IdempotencyKey: "key-123",
+				})
+			}
+			acc, _ := accRepo.GetAccountUnsafe(id)
+			return acc, nil
+		}
+
+		txn, err := svc.Transfer(context.Background(), request("100"))
+		if err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		if !txn.Replayed || txn.TransactionID != 77 {
+			t.Errorf("expected replay of 77, got %+v", txn)
+		}
+	})
+}
+
+func TestTransferService_EffectiveDate(t *testing.T) {
+	newService := func() *TransferService {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(500)})
+		config := DefaultTransferConfig()
+		config.EffectiveDateMaxPastDays = 7
+		config.EffectiveDateMaxFutureDays = 1
+		return NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), config)
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	request := func(effectiveDate string) *models.CreateTransactionRequest {
+		return &models.CreateTransactionRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               "100",
+			EffectiveDate:        effectiveDate,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		date    string
+		want    time.Time
+		wantErr bool
+	}{
+		{"defaults to today", "", today, false},
+		{"back-dated within window", today.AddDate(0, 0, -7).Format(models.DateLayout), today.AddDate(0, 0, -7), false},
+		{"future within window", today.AddDate(0, 0, 1).Format(models.DateLayout), today.AddDate(0, 0, 1), false},
+		{"too far back", today.AddDate(0, 0, -8).Format(models.DateLayout), time.Time{}, true},
+		{"too far ahead", today.AddDate(0, 0, 2).Format(models.DateLayout), time.Time{}, true},
+		{"malformed", "2024/01/01", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn, err := newService().Transfer(context.Background(), request(tt.date))
+			if tt.wantErr {
+				if !errors.Is(err, models.ErrInvalidEffectiveDate) {
+					t.Errorf("expected ErrInvalidEffectiveDate, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected: %v", err)
+			}
+			if !txn.EffectiveDate.Equal(tt.want) {
+				t.Errorf("expected effective date %s, got %s", tt.want.Format(models.DateLayout), txn.EffectiveDate.Format(models.DateLayout))
+			}
+		})
+	}
+
+	t.Run("idempotent replay with different date conflicts", func(t *testing.T) {
+		svc := newService()
+		req := request(today.Format(models.DateLayout))
+		req.IdempotencyKey = "key-1"
+		if _, err := svc.Transfer(context.Background(), req); err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+
+		req = request(today.AddDate(0, 0, -1).Format(models.DateLayout))
+		req.IdempotencyKey = "key-1"
+		_, err := svc.Transfer(context.Background(), req)
+		if !errors.Is(err, models.ErrIdempotencyKeyConflict) {
+			t.Errorf("expected ErrIdempotencyKeyConflict, got %v", err)
+		}
+	})
+}
+
+func TestTransferService_Reverse(t *testing.T) {
+	newService := func(destBalance int64) (*TransferService, *mocks.MockAccountRepository) {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(900)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(destBalance)})
+		txnRepo := mocks.NewMockTransactionRepository()
+		txnRepo.SetTransaction(&models.Transaction{
+			TransactionID: 5, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100),
+		})
+		return NewTransferService(accRepo, txnRepo), accRepo
+	}
+
+	t.Run("moves funds back and links original", func(t *testing.T) {
+		svc, accRepo := newService(600)
+		txn, err := svc.Reverse(context.Background(), 5)
+		if err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		if txn.ReversalOf == nil || *txn.ReversalOf != 5 {
+			t.Errorf("expected reversal_of 5, got %v", txn.ReversalOf)
+		}
+		src, _ := accRepo.GetAccount(1)
+		dst, _ := accRepo.GetAccount(2)
+		if !src.Balance.Equal(decimal.NewFromInt(1000)) || !dst.Balance.Equal(decimal.NewFromInt(500)) {
+			t.Errorf("unexpected balances: %s, %s", src.Balance, dst.Balance)
+		}
+	})
+
+	t.Run("already reversed", func(t *testing.T) {
+		svc, _ := newService(600)
+		if _, err := svc.Reverse(context.Background(), 5); err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		_, err := svc.Reverse(context.Background(), 5)
+		if !errors.Is(err, models.ErrAlreadyReversed) {
+			t.Errorf("expected ErrAlreadyReversed, got %v", err)
+		}
+	})
+
+	t.Run("destination spent the funds", func(t *testing.T) {
+		svc, _ := newService(50)
+		_, err := svc.Reverse(context.Background(), 5)
+		if !errors.Is(err, models.ErrInsufficientBalance) {
+			t.Errorf("expected ErrInsufficientBalance, got %v", err)
+		}
+	})
+
+	t.Run("unknown transaction", func(t *testing.T) {
+		svc, _ := newService(600)
+		_, err := svc.Reverse(context.Background(), 99)
+		if !errors.Is(err, models.ErrTransferNotFound) {
+			t.Errorf("expected ErrTransferNotFound, got %v", err)
+		}
+	})
+
+	t.Run("deposit is not reversible", func(t *testing.T) {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(100)})
+		txnRepo := mocks.NewMockTransactionRepository()
+		txnRepo.SetTransaction(&models.Transaction{
+			TransactionID: 6, Type: models.TransactionTypeDeposit, DestinationAccountID: 2, Amount: decimal.NewFromInt(100),
+		})
+		_, err := NewTransferService(accRepo, txnRepo).Reverse(context.Background(), 6)
+		if !errors.Is(err, models.ErrNotReversible) {
+			t.Errorf("expected ErrNotReversible, got %v", err)
+		}
+	})
+
+	t.Run("failed transfer is not reversible", func(t *testing.T) {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(900)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(600)})
+		txnRepo := mocks.NewMockTransactionRepository()
+		txnRepo.SetTransaction(&models.Transaction{
+			TransactionID: 7, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100),
+			Status: models.TransactionStatusFailed,
+		})
+		_, err := NewTransferService(accRepo, txnRepo).Reverse(context.Background(), 7)
+		if !errors.Is(err, models.ErrNotReversible) {
+			t.Errorf("expected ErrNotReversible, got %v", err)
+		}
+		src, _ := accRepo.GetAccount(1)
+		dst, _ := accRepo.GetAccount(2)
+		if !src.Balance.Equal(decimal.NewFromInt(900)) || !dst.Balance.Equal(decimal.NewFromInt(600)) {
+			t.Errorf("expected balances unchanged, got %s, %s", src.Balance, dst.Balance)
+		}
+	})
+}
+
+func TestTransferService_Sequence(t *testing.T) {
+	seq := func(n int64) *int64 { return &n }
+	newService := func() (*TransferService, *mocks.MockAccountRepository) {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100), LastSequence: 5})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+		return NewTransferService(accRepo, mocks.NewMockTransactionRepository()), accRepo
+	}
+	transfer := func(svc *TransferService, amount string, sequence *int64) error {
+		_, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+			SourceAccountID: 1, DestinationAccountID: 2, Amount: amount, Sequence: sequence,
+		})
+		return err
+	}
+
+	t.Run("accepts a higher sequence and records it", func(t *testing.T) {
+		svc, accRepo := newService()
+		if err := transfer(svc, "10", seq(6)); err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		if err := transfer(svc, "10", seq(20)); err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		if acc, _ := accRepo.GetAccount(1); acc.LastSequence != 20 || acc.Balance.String() != "80" {
+			t.Errorf("expected last sequence 20 and balance 80, got %d and %s", acc.LastSequence, acc.Balance)
+		}
+	})
+
+	t.Run("rejects a repeated or lower sequence", func(t *testing.T) {
+		for _, n := range []int64{5, 4} {
+			svc, accRepo := newService()
+			if err := transfer(svc, "10", seq(n)); !errors.Is(err, models.ErrStaleSequence) {
+				t.Errorf("sequence %d: expected ErrStaleSequence, got %v", n, err)
+			}
+			if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "100" || acc.LastSequence != 5 {
+				t.Errorf("sequence %d: expected account unchanged, got balance %s sequence %d", n, acc.Balance, acc.LastSequence)
+			}
+		}
+	})
+
+	t.Run("failed transfer does not consume the sequence", func(t *testing.T) {
+		svc, accRepo := newService()
+		if err := transfer(svc, "500", seq(6)); !errors.Is(err, models.ErrInsufficientBalance) {
+			t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+		}
+		if acc, _ := accRepo.GetAccount(1); acc.LastSequence != 5 {
+			t.Errorf("expected last sequence to stay 5, got %d", acc.LastSequence)
+		}
+		if err := transfer(svc, "10", seq(6)); err != nil {
+			t.Errorf("expected sequence 6 to still be usable, got %v", err)
+		}
+	})
+
+	t.Run("transfers without a sequence are unaffected", func(t *testing.T) {
+		svc, accRepo := newService()
+		if err := transfer(svc, "10", nil); err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		if acc, _ := accRepo.GetAccount(1); acc.LastSequence != 5 {
+			t.Errorf("expected last sequence to stay 5, got %d", acc.LastSequence)
+		}
+	})
+}
+
+func TestTransferService_ZeroBalancePolicy(t *testing.T) {
+	newService := func(sourceType string) (*TransferService, *mocks.MockAccountRepository) {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, AccountType: sourceType, Balance: decimal.NewFromInt(100)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, AccountType: models.DefaultAccountType, Balance: decimal.NewFromInt(0)})
+		cfg := DefaultTransferConfig()
+		cfg.ForbidZeroBalanceTypes = []string{"escrow"}
+		return NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), cfg), accRepo
+	}
+	transfer := func(svc *TransferService, amount string) error {
+		_, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+			SourceAccountID: 1, DestinationAccountID: 2, Amount: amount,
+		})
+		return err
+	}
+
+	t.Run("forbidden type cannot be zeroed", func(t *testing.T) {
+		svc, accRepo := newService("escrow")
+		if err := transfer(svc, "100"); !errors.Is(err, models.ErrInsufficientBalance) {
+			t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+		}
+		if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "100" {
+			t.Errorf("expected balance unchanged, got %s", acc.Balance)
+		}
+		if err := transfer(svc, "99.99"); err != nil {
+			t.Errorf("expected a transfer leaving a positive balance to succeed, got %v", err)
+		}
+	})
+
+	t.Run("other types may be zeroed", func(t *testing.T) {
+		svc, accRepo := newService(models.DefaultAccountType)
+		if err := transfer(svc, "100"); err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		if acc, _ := accRepo.GetAccount(1); !acc.Balance.IsZero() {
+			t.Errorf("expected zero balance, got %s", acc.Balance)
+		}
+	})
+
+	t.Run("batch total", func(t *testing.T) {
+		svc, _ := newService("escrow")
+		_, err := svc.BatchTransfer(context.Background(), &models.CreateBatchTransferRequest{
+			SourceAccountID: 1,
+			Transfers: []models.BatchTransferItem{
+				{DestinationAccountID: 2, Amount: "60"},
+				{DestinationAccountID: 2, Amount: "40"},
+			},
+		})
+		if !errors.Is(err, models.ErrInsufficientBalance) {
+			t.Errorf("expected ErrInsufficientBalance, got %v", err)
+		}
+	})
+}
+
+func TestTransferService_TransferLimits(t *testing.T) {
+	newService := func(lower, upper string) (*TransferService, *mocks.MockAccountRepository) {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100000)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+		cfg := DefaultTransferConfig()
+		cfg.MinTransferAmount = decimal.RequireFromString(lower)
+		cfg.MaxTransferAmount = decimal.RequireFromString(upper)
+		return NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), cfg), accRepo
+	}
+
+	tests := []struct {
+		name   string
+		min    string
+		max    string
+		amount string
+		want   error
+	}{
+		{"at minimum", "1", "1000", "1", nil},
+		{"just below minimum", "1", "1000", "0.99", models.ErrAmountBelowMinimum},
+		{"at maximum", "1", "1000", "1000", nil},
+		{"just above maximum", "1", "1000", "1000.01", models.ErrAmountAboveMaximum},
+		{"minimum only", "5", "0", "50000", nil},
+		{"maximum only", "0", "10", "0.01", nil},
+		{"limits disabled", "0", "0", "99999", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, accRepo := newService(tt.min, tt.max)
+			_, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+				SourceAccountID: 1, DestinationAccountID: 2, Amount: tt.amount,
+			})
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "100000" {
+				t.Errorf("expected a rejected transfer to leave the balance unchanged, got %s", acc.Balance)
+			}
+		})
+	}
+
+	svc, _ := newService("1", "1000")
+	_, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "2000"})
+	if err == nil || !strings.Contains(err.Error(), "maximum transfer amount of 1000") {
+		t.Errorf("expected the error to name the maximum, got %v", err)
+	}
+}
+
+func TestTransferService_DailyLimits(t *testing.T) {
+	newService := func(amountLimit string, countLimit int) (*TransferService, *mocks.MockTransactionRepository) {
+		accRepo := mocks.NewMockAccountRepository()
+		accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(10000)})
+		accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+		txnRepo := mocks.NewMockTransactionRepository()
+		cfg := DefaultTransferConfig()
+		cfg.DailyAmountLimit = decimal.RequireFromString(amountLimit)
+		cfg.DailyCountLimit = countLimit
+		return NewTransferServiceWithConfig(accRepo, txnRepo, cfg), txnRepo
+	}
+	transfer := func(svc *TransferService, amount string) error {
+		_, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+			SourceAccountID: 1, DestinationAccountID: 2, Amount: amount,
+		})
+		return err
+	}
+
+	t.Run("amount", func(t *testing.T) {
+		svc, _ := newService("100", 0)
+		if err := transfer(svc, "60"); err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		if err := transfer(svc, "40.01"); !errors.Is(err, models.ErrVelocityLimitExceeded) {
+			t.Fatalf("expected ErrVelocityLimitExceeded, got %v", err)
+		}
+		if err := transfer(svc, "40"); err != nil {
+			t.Errorf("expected a transfer reaching the limit exactly to succeed, got %v", err)
+		}
+	})
+
+	t.Run("count", func(t *testing.T) {
+		svc, _ := newService("0", 2)
+		for i := 0; i < 2; i++ {
+			if err := transfer(svc, "1"); err != nil {
+				t.Fatalf("transfer %d: %v", i+1, err)
+			}
+		}
+		err := transfer(svc, "1")
+		var velocity *models.VelocityLimitError
+		if !errors.As(err, &velocity) || !velocity.ResetsAt.After(time.Now()) {
+			t.Errorf("expected a velocity error resetting in the future, got %v", err)
+		}
+	})
+
+	t.Run("batch legs count", func(t *testing.T) {
+		svc, _ := newService("0", 2)
+		_, err := svc.BatchTransfer(context.Background(), &models.CreateBatchTransferRequest{
+			SourceAccountID: 1,
+			Transfers: []models.BatchTransferItem{
+				{DestinationAccountID: 2, Amount: "1"},
+				{DestinationAccountID: 2, Amount: "1"},
+				{DestinationAccountID: 2, Amount: "1"},
+			},
+		})
+		if !errors.Is(err, models.ErrVelocityLimitExceeded) {
+			t.Errorf("expected ErrVelocityLimitExceeded, got %v", err)
+		}
+	})
+
+	t.Run("account override", func(t *testing.T) {
+		svc, txnRepo := newService("100", 0)
+		unlimited := int64(0)
+		txnRepo.DailyLimits = map[int64]models.DailyOutbound{1: {AmountLimit: decimal.NewNullDecimal(decimal.Zero), CountLimit: &unlimited}}
+		if err := transfer(svc, "500"); err != nil {
+			t.Fatalf("expected an override of 0 to lift the limit, got %v", err)
+		}
+
+		one := int64(1)
+		txnRepo.DailyLimits = map[int64]models.DailyOutbound{1: {CountLimit: &one}}
+		if err := transfer(svc, "1"); !errors.Is(err, models.ErrVelocityLimitExceeded) {
+			t.Errorf("expected the account's count limit of 1 to apply, got %v", err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		svc, _ := newService("0", 0)
+		for i := 0; i < 5; i++ {
+			if err := transfer(svc, "1000"); err != nil {
+				t.Fatalf("transfer %d: %v", i+1, err)
+			}
+		}
+	})
+}
+
+func TestTransferService_Overdraft(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(50), OverdraftLimit: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+	svc := NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+	transfer := func(source, dest int64, amount string) error {
+		_, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+			SourceAccountID: source, DestinationAccountID: dest, Amount: amount,
+		})
+		return err
+	}
+
+	if err := transfer(1, 2, "120"); err != nil {
+		t.Fatalf("transfer into overdraft: %v", err)
+	}
+	if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "-70" {
+		t.Errorf("expected -70, got %s", acc.Balance)
+	}
+	if err := transfer(1, 2, "30.01"); !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance past the limit, got %v", err)
+	}
+	if err := transfer(1, 2, "30"); err != nil {
+		t.Errorf("expected a transfer up to the limit to succeed, got %v", err)
+	}
+	if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "-100" {
+		t.Errorf("expected -100, got %s", acc.Balance)
+	}
+
+	// Accounts without a limit still can't go negative
+	if err := transfer(2, 1, "150.01"); !errors.Is(err, models.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance without an overdraft, got %v", err)
+	}
+}
+
+func TestTransferService_ClosedAccount(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.Zero})
+	accRepo.SetAccount(&models.Account{AccountID: 3, Balance: decimal.Zero})
+	if _, err := NewAccountService(accRepo).CloseAccount(context.Background(), 2, false); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	for _, mode := range []ConcurrencyMode{ConcurrencyPessimistic, ConcurrencyOptimistic} {
+		cfg := DefaultTransferConfig()
+		cfg.ConcurrencyMode = mode
+		svc := NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), cfg)
+
+		for _, req := range []*models.CreateTransactionRequest{
+			{SourceAccountID: 1, DestinationAccountID: 2, Amount: "10"},
+			{SourceAccountID: 2, DestinationAccountID: 1, Amount: "10"},
+		} {
+			if _, err := svc.Transfer(context.Background(), req); !errors.Is(err, models.ErrAccountClosed) {
+				t.Errorf("%s: %d -> %d: expected ErrAccountClosed, got %v", mode, req.SourceAccountID, req.DestinationAccountID, err)
+			}
+		}
+	}
+
+	svc := NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+	_, err := svc.BatchTransfer(context.Background(), &models.CreateBatchTransferRequest{
+		SourceAccountID: 1,
+		Transfers: []models.BatchTransferItem{
+			{DestinationAccountID: 3, Amount: "10"},
+			{DestinationAccountID: 2, Amount: "10"},
+		},
+	})
+	if !errors.Is(err, models.ErrAccountClosed) {
+		t.Errorf("batch: expected ErrAccountClosed, got %v", err)
+	}
+
+	if acc, _ := accRepo.GetAccount(1); acc.Balance.String() != "100" {
+		t.Errorf("expected source balance unchanged, got %s", acc.Balance)
+	}
+}
+
+func TestTransferService_SourceCooldown(t *testing.T) {
+	newService := func(cooldown time.Duration) *TransferService {
+		accRepo := mocks.NewMockAccountRepository()
+		for id := int64(1); id <= 3; id++ {
+			accRepo.SetAccount(&models.Account{AccountID: id, Balance: decimal.NewFromInt(100)})
+		}
+		cfg := DefaultTransferConfig()
+		cfg.SourceCooldown = cooldown
+		return NewTransferServiceWithConfig(accRepo, mocks.NewMockTransactionRepository(), cfg)
+	}
+	transfer := func(svc *TransferService, source, dest int64) (*models.Transaction, error) {
+		return svc.Transfer(context.Background(), &models.CreateTransactionRequest{
+			SourceAccountID: source, DestinationAccountID: dest, Amount: "1",
+		})
+	}
+
+	t.Run("second send within window", func(t *testing.T) {
+		svc := newService(time.Minute)
+		first, err := transfer(svc, 1, 2)
+		if err != nil {
+			t.Fatalf("first transfer: %v", err)
+		}
+
+		_, err = transfer(svc, 1, 3)
+		var cooldown *models.CooldownError
+		if !errors.Is(err, models.ErrCooldownActive) || !errors.As(err, &cooldown) {
+			t.Fatalf("expected ErrCooldownActive, got %v", err)
+		}
+		if cooldown.RetryAfter <= 0 || cooldown.RetryAfter > time.Minute {
+			t.Errorf("expected RetryAfter within the window, got %s", cooldown.RetryAfter)
+		}
+
+		// Receiving doesn't start a cooldown, and reversals are exempt
+		if _, err := transfer(svc, 2, 3); err != nil {
+			t.Errorf("expected account 2 to send freely, got %v", err)
+		}
+		if _, err := svc.Reverse(context.Background(), first.TransactionID); err != nil {
+			t.Errorf("expected the reversal to bypass the cooldown, got %v", err)
+		}
+
+		_, err = svc.BatchTransfer(context.Background(), &models.CreateBatchTransferRequest{
+			SourceAccountID: 1,
+			Transfers:       []models.BatchTransferItem{{DestinationAccountID: 3, Amount: "1"}},
+		})
+		if !errors.Is(err, models.ErrCooldownActive) {
+			t.Errorf("batch: expected ErrCooldownActive, got %v", err)
+		}
+	})
+
+	t.Run("window elapsed", func(t *testing.T) {
+		svc := newService(20 * time.Millisecond)
+		if _, err := transfer(svc, 1, 2); err != nil {
+			t.Fatalf("first transfer: %v", err)
+		}
+		time.Sleep(30 * time.Millisecond)
+		if _, err := transfer(svc, 1, 2); err != nil {
+			t.Errorf("expected a send after the window to succeed, got %v", err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		svc := newService(0)
+		for i := 0; i < 3; i++ {
+			if _, err := transfer(svc, 1, 2); err != nil {
+				t.Fatalf("transfer %d: %v", i, err)
+			}
+		}
+	})
+}
+
+func TestTransferService_RetryUnseenAccount(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		visible   consistency.LSN
+		wantErr   error
+		wantCalls int32
+	}{
+		{"token ahead of database retries until visible", consistency.WithToken(context.Background(), 100), 50, nil, 2},
+		{"token already satisfied is truly missing", consistency.WithToken(context.Background(), 100), 100, models.ErrAccountNotFound, 1},
+		{"no token is truly missing", context.Background(), 0, models.ErrAccountNotFound, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accRepo := mocks.NewMockAccountRepository()
+			txnRepo := mocks.NewMockTransactionRepository()
+			accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+			accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+
+			// Account 2 is not visible on the first attempt
+			var attempts atomic.Int32
+			accRepo.OnGetByIDForUpdate = func(_ context.Context, _ interface{}, id int64) (*models.Account, error) {
+				if id == 1 {
+					attempts.Add(1)
+				}
+				if id == 2 && attempts.Load() == 1 {
+					return nil, models.ErrAccountNotFound
+				}
+				acc, _ := accRepo.GetAccountUnsafe(id)
+				return acc, nil
+			}
+			accRepo.OnVisibleLSN = func(context.Context) (consistency.LSN, error) { return tt.visible, nil }
+
+			config := DefaultTransferConfig()
+			config.RetryBaseDelay = time.Millisecond
+			svc := NewTransferServiceWithConfig(accRepo, txnRepo, config)
+
+			_, err := svc.Transfer(tt.ctx, &models.CreateTransactionRequest{
+				SourceAccountID: 1, DestinationAccountID: 2, Amount: "100",
+			})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if attempts.Load() != tt.wantCalls {
+				t.Errorf("expected %d attempts, got %d", tt.wantCalls, attempts.Load())
+			}
+		})
+	}
+}
+
+func TestTransferService_GetAccountTransactionsAfter_StableUnderInserts(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+	for i := int64(1); i <= 10; i++ {
+		txnRepo.SetTransaction(&models.Transaction{TransactionID: i, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(i)})
+	}
+	svc := NewTransferService(accRepo, txnRepo)
+	ctx := context.Background()
+
+	var seen []int64
+	var cursor models.TransactionCursor
+	nextID := int64(11)
+	for page := 0; ; page++ {
+		txns, next, err := svc.GetAccountTransactionsAfter(ctx, 1, cursor, 4)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		for _, txn := range txns {
+			seen = append(seen, txn.TransactionID)
+		}
+
+		// New transactions land between page fetches; with offset paging these
+		// would shift older rows and cause duplicates
+		for i := 0; i < 3; i++ {
+			txnRepo.SetTransaction(&models.Transaction{TransactionID: nextID, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1)})
+			nextID++
+		}
+
+		if next.IsZero() {
+			break
+		}
+		cursor = next
+	}
+
+	if len(seen) != 10 {
+		t.Fatalf("expected the original 10 transactions, got %v", seen)
+	}
+	for i, id := range seen {
+		if id != int64(10-i) {
+			t.Fatalf("expected descending 10..1 without gaps or duplicates, got %v", seen)
+		}
+	}
+
+	if _, _, err := svc.GetAccountTransactionsAfter(ctx, 999, models.TransactionCursor{}, 4); !errors.Is(err, models.ErrAccountNotFound) {
+		t.Errorf("expected ErrAccountNotFound, got %v", err)
+	}
+}
+
+func TestTransferService_GetAccountTransactionsAfter_CreatedAtTies(t *testing.T) {
+	accRepo := mocks.NewMockAccountRepository()
+	txnRepo := mocks.NewMockTransactionRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(1000)})
+
+	// A batch of 6 transfers committed together shares one created_at, bracketed by an
+	// older and a newer transfer. ID 1 was created last, so created_at decides first.
+	batch := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	txnRepo.SetTransaction(&models.Transaction{TransactionID: 1, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1), CreatedAt: batch.Add(time.Second)})
+	for i := int64(2); i <= 7; i++ {
+		txnRepo.SetTransaction(&models.Transaction{TransactionID: i, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1), CreatedAt: batch})
+	}
+	txnRepo.SetTransaction(&models.Transaction{TransactionID: 8, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1), CreatedAt: batch.Add(-time.Second)})
+	svc := NewTransferService(accRepo, txnRepo)
+
+	var seen []int64
+	var cursor models.TransactionCursor
+	for {
+		txns, next, err := svc.GetAccountTransactionsAfter(context.Background(), 1, cursor, 4)
+		if err != nil {
+			t.Fatalf("unexpected: %v", err)
+		}
+		for _, txn := range txns {
+			seen = append(seen, txn.TransactionID)
+		}
+		if next.IsZero() {
+			break
+		}
+		cursor = next
+	}
+
+	want := []int64{1, 7, 6, 5, 4, 3, 2, 8}
+	if len(seen) != len(want) {
+		t.Fatalf("expected %v, got %v", want, seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, seen)
+		}
+	}
+
+	offsetPage, _ := svc.GetAccountTransactions(context.Background(), 1, 8, 0)
+	for i, txn := range offsetPage {
+		if txn.TransactionID != want[i] {
+			t.Fatalf("offset paging must use the same order; got %d at %d", txn.TransactionID, i)
+		}
+	}
+}
+
+func TestTransferService_LogsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Logger
+	log.Logger = zerolog.New(&buf).Level(zerolog.DebugLevel)
+	defer func() { log.Logger = orig }()
+
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+	transferSvc := NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+	accountSvc := NewAccountService(accRepo)
+
+	ctx := logging.WithRequestID(context.Background(), "req-42")
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "10"}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if _, err := transferSvc.Transfer(ctx, &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "abc"}); err == nil {
+		t.Fatal("expected invalid amount error")
+	}
+	if _, err := accountSvc.CreateAccount(ctx, &models.CreateAccountRequest{AccountID: 3, InitialBalance: "5"}); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) < 3 {
+		t.Fatalf("expected at least 3 service log lines, got %q", buf.String())
+	}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		if entry["request_id"] != "req-42" {
+			t.Errorf("expected request_id req-42 on %q", line)
+		}
 	}
 }
@@ -59,6 +59,11 @@ func (s *TestContainerSuite) Pool() *pgxpool.Pool {
 
 func (s *TestContainerSuite) Clean() error {
 	_, err := s.pool.Exec(context.Background(), `
+		TRUNCATE recurring_transfers RESTART IDENTITY CASCADE;
+		TRUNCATE ledger_entries RESTART IDENTITY CASCADE;
+		TRUNCATE holds RESTART IDENTITY CASCADE;
+		TRUNCATE balance_history RESTART IDENTITY CASCADE;
+		TRUNCATE balance_adjustments RESTART IDENTITY CASCADE;
 		TRUNCATE transactions RESTART IDENTITY CASCADE;
 		TRUNCATE accounts RESTART IDENTITY CASCADE;
 	`)
@@ -78,10 +83,23 @@ func (s *TestContainerSuite) runMigrations(ctx context.Context) error {
 	_, err := s.pool.Exec(ctx, `
 		CREATE TABLE IF NOT EXISTS accounts (
 			account_id BIGINT PRIMARY KEY,
-			balance NUMERIC NOT NULL CHECK (balance >= 0),
+			account_type TEXT NOT NULL DEFAULT 'standard',
+			balance NUMERIC NOT NULL,
+			initial_balance NUMERIC NOT NULL,
+			max_balance NUMERIC NULL CHECK (max_balance IS NULL OR max_balance >= 0),
+			last_sequence BIGINT NOT NULL DEFAULT 0 CHECK (last_sequence >= 0),
+			version INT NOT NULL DEFAULT 0,
+			closed_at TIMESTAMPTZ NULL,
+			held_balance NUMERIC NOT NULL DEFAULT 0,
+			overdraft_limit NUMERIC NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0),
 			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			CHECK (balance >= -overdraft_limit),
+			CHECK (held_balance >= 0 AND held_balance <= balance + overdraft_limit)
 		);
+
+		CREATE SEQUENCE IF NOT EXISTS accounts_account_id_seq OWNED BY accounts.account_id;
+		ALTER TABLE accounts ALTER COLUMN account_id SET DEFAULT nextval('accounts_account_id_seq');
 		
 		CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
 		BEGIN NEW.updated_at = now(); RETURN NEW; END;
@@ -90,18 +108,115 @@ func (s *TestContainerSuite) runMigrations(ctx context.Context) error {
 		DROP TRIGGER IF EXISTS trg_accounts_updated ON accounts;
 		CREATE TRIGGER trg_accounts_updated BEFORE UPDATE ON accounts
 		FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+		CREATE INDEX IF NOT EXISTS idx_accounts_balance_id ON accounts (balance, account_id);
+		CREATE INDEX IF NOT EXISTS idx_accounts_created_at_id ON accounts (created_at, account_id);
 		
 		CREATE TABLE IF NOT EXISTS transactions (
 			transaction_id BIGSERIAL PRIMARY KEY,
+			type TEXT NOT NULL DEFAULT 'transfer' CHECK (type IN ('transfer', 'deposit', 'withdrawal')),
+			source_account_id BIGINT NULL REFERENCES accounts(account_id),
+			destination_account_id BIGINT NULL REFERENCES accounts(account_id),
+			amount NUMERIC NOT NULL CHECK (amount > 0),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			idempotency_key TEXT NULL,
+			effective_date DATE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC')::date,
+			reversal_of BIGINT NULL REFERENCES transactions(transaction_id),
+			category TEXT NULL CHECK (category IS NULL OR length(category) BETWEEN 1 AND 64),
+			status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+			fee_amount NUMERIC NOT NULL DEFAULT 0 CHECK (fee_amount >= 0),
+			fee_account_id BIGINT NULL REFERENCES accounts(account_id),
+			fee_paid_by TEXT NULL CHECK (fee_paid_by IN ('source', 'destination')),
+			CHECK (source_account_id <> destination_account_id),
+			CONSTRAINT transactions_sides_match_type CHECK (
+				(type = 'transfer' AND source_account_id IS NOT NULL AND destination_account_id IS NOT NULL) OR
+				(type = 'deposit' AND source_account_id IS NULL AND destination_account_id IS NOT NULL) OR
+				(type = 'withdrawal' AND source_account_id IS NOT NULL AND destination_account_id IS NULL)
+			),
+			CONSTRAINT transactions_fee_consistent CHECK (
+				(fee_amount = 0 AND fee_account_id IS NULL AND fee_paid_by IS NULL) OR
+				(fee_amount > 0 AND fee_account_id IS NOT NULL AND fee_paid_by IS NOT NULL AND type = 'transfer'
+					AND fee_account_id <> source_account_id AND fee_account_id <> destination_account_id
+					AND (fee_paid_by = 'source' OR fee_amount < amount))
+			)
+		);
+		
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_idempotency_key
+			ON transactions(idempotency_key) WHERE idempotency_key IS NOT NULL;
+		
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reversal_of
+			ON transactions(reversal_of) WHERE reversal_of IS NOT NULL AND status <> 'failed';
+		
+		CREATE INDEX IF NOT EXISTS idx_txn_source ON transactions(source_account_id, created_at DESC, transaction_id DESC);
+		CREATE INDEX IF NOT EXISTS idx_txn_dest ON transactions(destination_account_id, created_at DESC, transaction_id DESC);
+		
+		CREATE SEQUENCE IF NOT EXISTS balance_adjustment_batch_seq;
+		
+		CREATE TABLE IF NOT EXISTS balance_adjustments (
+			adjustment_id BIGSERIAL PRIMARY KEY,
+			batch_id BIGINT NOT NULL,
+			account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+			delta NUMERIC NOT NULL CHECK (delta <> 0),
+			balance_after NUMERIC NOT NULL,
+			reason TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			UNIQUE (batch_id, account_id)
+		);
+		
+		CREATE TABLE IF NOT EXISTS balance_history (
+			change_id BIGSERIAL PRIMARY KEY,
+			account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+			old_balance NUMERIC NOT NULL,
+			new_balance NUMERIC NOT NULL,
+			transaction_id BIGINT NULL REFERENCES transactions(transaction_id),
+			adjustment_id BIGINT NULL REFERENCES balance_adjustments(adjustment_id),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			CHECK ((transaction_id IS NULL) <> (adjustment_id IS NULL))
+		);
+		
+		CREATE TABLE IF NOT EXISTS holds (
+			hold_id BIGSERIAL PRIMARY KEY,
+			account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+			amount NUMERIC NOT NULL CHECK (amount > 0),
+			status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'released', 'captured')),
+			transaction_id BIGINT NULL REFERENCES transactions(transaction_id),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			resolved_at TIMESTAMPTZ NULL,
+			CHECK ((status = 'captured') = (transaction_id IS NOT NULL))
+		);
+		
+		CREATE TABLE IF NOT EXISTS ledger_entries (
+			entry_id BIGSERIAL PRIMARY KEY,
+			transaction_id BIGINT NOT NULL REFERENCES transactions(transaction_id),
+			account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+			amount NUMERIC NOT NULL CHECK (amount <> 0),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			UNIQUE (transaction_id, account_id)
+		);
+		
+		CREATE INDEX IF NOT EXISTS idx_ledger_entries_account ON ledger_entries (account_id, entry_id DESC);
+		
+		CREATE TABLE IF NOT EXISTS recurring_transfers (
+			recurring_id BIGSERIAL PRIMARY KEY,
 			source_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
 			destination_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
 			amount NUMERIC NOT NULL CHECK (amount > 0),
+			category TEXT NULL CHECK (category IS NULL OR length(category) BETWEEN 1 AND 64),
+			interval_seconds BIGINT NOT NULL CHECK (interval_seconds >= 60),
+			next_run_at TIMESTAMPTZ NOT NULL,
+			end_at TIMESTAMPTZ NULL,
+			status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'canceled')),
+			runs INT NOT NULL DEFAULT 0,
+			last_run_at TIMESTAMPTZ NULL,
+			last_transaction_id BIGINT NULL REFERENCES transactions(transaction_id),
+			last_error TEXT NULL,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-			CHECK (source_account_id <> destination_account_id)
+			canceled_at TIMESTAMPTZ NULL,
+			CHECK (source_account_id <> destination_account_id),
+			CHECK ((status = 'canceled') = (canceled_at IS NOT NULL))
 		);
 		
-		CREATE INDEX IF NOT EXISTS idx_txn_source ON transactions(source_account_id, created_at DESC);
-		CREATE INDEX IF NOT EXISTS idx_txn_dest ON transactions(destination_account_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_recurring_transfers_due ON recurring_transfers (next_run_at) WHERE status = 'active';
 	`)
 	return err
 }
@@ -0,0 +1,112 @@
+// Package tracing sets up OpenTelemetry tracing and holds the helpers the handler,
+// service, and repository layers use to create spans.
+//
+// Spans are always created through the global tracer provider. Until Setup installs an
+// exporting provider, that is OpenTelemetry's no-op provider, so instrumented code costs
+// next to nothing when tracing is disabled.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies this service's tracer.
+const InstrumentationName = "internal-transfers-system"
+
+// Span attribute keys shared across layers.
+const (
+	AttrAccountID            = attribute.Key("account.id")
+	AttrSourceAccountID      = attribute.Key("transfer.source_account_id")
+	AttrDestinationAccountID = attribute.Key("transfer.destination_account_id")
+	AttrAmount               = attribute.Key("transfer.amount")
+	AttrAttempt              = attribute.Key("transfer.attempt")
+	AttrTransactionID        = attribute.Key("transaction.id")
+)
+
+// Propagator reads and writes W3C traceparent/tracestate and baggage headers.
+var Propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{}, propagation.Baggage{},
+)
+
+// Config controls span export.
+type Config struct {
+	// Enabled turns on export. When false, Setup leaves the no-op provider in place.
+	Enabled bool
+
+	// Endpoint is the OTLP/HTTP collector address as host:port.
+	Endpoint string
+
+	// Insecure sends spans over plain HTTP instead of HTTPS.
+	Insecure bool
+
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+
+	// SampleRatio is the fraction of new traces to record, from 0 to 1. Requests that
+	// arrive with a sampled traceparent are always recorded.
+	SampleRatio float64
+}
+
+// Tracer returns the tracer for this service from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Start starts a span named name as a child of any span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RecordError records err on span and marks the span failed. A nil err is ignored.
+func RecordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// End records err on span, if any, and ends it. It is meant to be deferred with a named
+// error result: defer func() { tracing.End(span, err) }().
+func End(span trace.Span, err error) {
+	RecordError(span, err)
+	span.End()
+}
+
+// Setup installs the global propagator and, when cfg.Enabled, a tracer provider that
+// batches spans to an OTLP/HTTP collector. The returned function flushes pending spans and
+// must be called on shutdown; it is a no-op when tracing is disabled.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(Propagator)
+
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+)
+
+// AccountIDValidator decides which client-chosen account IDs are acceptable, for
+// organizations whose account numbers follow a format. ValidateAccountID returns nil for
+// a valid ID, or an error whose message is reported against account_id.
+type AccountIDValidator interface {
+	ValidateAccountID(id int64) error
+}
+
+// Account ID validators accepted by AccountIDValidatorByName.
+const (
+	AccountIDPositive = "positive"
+	AccountIDLuhn     = "luhn"
+)
+
+var errAccountIDNotPositive = errors.New("must be a positive integer")
+
+// PositiveAccountID accepts any positive ID. It is the default.
+type PositiveAccountID struct{}
+
+func (PositiveAccountID) ValidateAccountID(id int64) error {
+	if id <= 0 {
+		return errAccountIDNotPositive
+	}
+	return nil
+}
+
+// LuhnAccountID accepts positive IDs whose last decimal digit is the Luhn (mod 10) check
+// digit of the digits before it, such as 79927398713. It catches any single mistyped
+// digit and most swaps of adjacent digits.
+type LuhnAccountID struct{}
+
+func (LuhnAccountID) ValidateAccountID(id int64) error {
+	if id <= 0 {
+		return errAccountIDNotPositive
+	}
+	if !luhnValid(strconv.FormatInt(id, 10)) {
+		return errors.New("must end in a valid Luhn (mod 10) check digit")
+	}
+	return nil
+}
+
+// luhnValid reports whether the last of digits is the Luhn check digit of the rest.
+func luhnValid(digits string) bool {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		// Double every second digit counting left from the check digit
+		if (len(digits)-1-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// AccountIDValidatorByName returns the validator named "positive" or "luhn". ok is false
+// for any other name.
+func AccountIDValidatorByName(name string) (v AccountIDValidator, ok bool) {
+	switch name {
+	case AccountIDPositive:
+		return PositiveAccountID{}, true
+	case AccountIDLuhn:
+		return LuhnAccountID{}, true
+	}
+	return nil, false
+}
+
+// accountIDValidator holds the AccountIDValidator ValidateCreateAccount applies.
+var accountIDValidator atomic.Pointer[AccountIDValidator]
+
+// SetAccountIDValidator changes how ValidateCreateAccount checks client-chosen account
+// IDs for every request validated afterwards; nil restores PositiveAccountID. It is meant
+// to be called once at startup from configuration.
+func SetAccountIDValidator(v AccountIDValidator) {
+	if v == nil {
+		accountIDValidator.Store(nil)
+		return
+	}
+	accountIDValidator.Store(&v)
+}
+
+// currentAccountIDValidator returns the validator set by SetAccountIDValidator.
+func currentAccountIDValidator() AccountIDValidator {
+	if v := accountIDValidator.Load(); v != nil {
+		return *v
+	}
+	return PositiveAccountID{}
+}
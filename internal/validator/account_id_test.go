@@ -0,0 +1,86 @@
+package validator
+
+import (
+	"testing"
+
+	"internal-transfers-system/internal/models"
+)
+
+func TestPositiveAccountID(t *testing.T) {
+	for _, id := range []int64{1, 42, 79927398710} {
+		if err := (PositiveAccountID{}).ValidateAccountID(id); err != nil {
+			t.Errorf("expected %d to be valid, got %v", id, err)
+		}
+	}
+	for _, id := range []int64{0, -1} {
+		if err := (PositiveAccountID{}).ValidateAccountID(id); err == nil {
+			t.Errorf("expected %d to be rejected", id)
+		}
+	}
+}
+
+func TestLuhnAccountID(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    int64
+		valid bool
+	}{
+		{"textbook example", 79927398713, true},
+		{"short number", 18, true},
+		{"check digit zero", 109, true},
+		{"failing checksum", 79927398710, false},
+		{"mistyped digit", 79927398813, false},
+		{"swapped digits", 79927398731, false},
+		{"single nonzero digit", 5, false},
+		{"negative", -18, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (LuhnAccountID{}).ValidateAccountID(tt.id)
+			if (err == nil) != tt.valid {
+				t.Errorf("ValidateAccountID(%d) = %v, want valid=%v", tt.id, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestAccountIDValidatorByName(t *testing.T) {
+	if v, ok := AccountIDValidatorByName("positive"); !ok || v != (PositiveAccountID{}) {
+		t.Errorf("expected the positive validator, got %v %v", v, ok)
+	}
+	if v, ok := AccountIDValidatorByName("luhn"); !ok || v != (LuhnAccountID{}) {
+		t.Errorf("expected the Luhn validator, got %v %v", v, ok)
+	}
+	if _, ok := AccountIDValidatorByName("mod97"); ok {
+		t.Error("expected an unknown name to be rejected")
+	}
+}
+
+func TestValidateCreateAccount_AccountIDValidator(t *testing.T) {
+	check := func(id int64) ValidationErrors {
+		return ValidateCreateAccount(&models.CreateAccountRequest{AccountID: id, InitialBalance: "100"})
+	}
+
+	// The default accepts any positive ID
+	if errs := check(79927398710); len(errs) != 0 {
+		t.Errorf("expected the default validator to accept any positive ID, got %v", errs)
+	}
+	if errs := check(-5); len(errs) != 1 || errs[0].Field != "account_id" || errs[0].Message != "must be a positive integer" {
+		t.Errorf("expected a positive integer error, got %v", errs)
+	}
+
+	SetAccountIDValidator(LuhnAccountID{})
+	defer SetAccountIDValidator(nil)
+
+	if errs := check(79927398713); len(errs) != 0 {
+		t.Errorf("expected a valid check digit to pass, got %v", errs)
+	}
+	errs := check(79927398710)
+	if len(errs) != 1 || errs[0].Field != "account_id" || errs[0].Message != "must end in a valid Luhn (mod 10) check digit" {
+		t.Errorf("expected a checksum error on account_id, got %v", errs)
+	}
+	// Omitted IDs are generated by the server and not checked
+	if errs := check(0); len(errs) != 0 {
+		t.Errorf("expected an omitted ID to pass, got %v", errs)
+	}
+}
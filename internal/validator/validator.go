@@ -1,13 +1,22 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"internal-transfers-system/internal/models"
 
 	"github.com/shopspring/decimal"
 )
 
+// accountTypePattern restricts account types to short identifiers so they can be listed
+// safely in configuration.
+var accountTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
 type ValidationError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
@@ -30,50 +39,384 @@ func (e ValidationErrors) HasErrors() bool {
 	return len(e) > 0
 }
 
+// Mode selects whether a validator reports every problem or stops at the first one.
+type Mode int
+
+const (
+	// CollectAll checks every field and returns all errors. Suited to forms, where the
+	// user wants to fix everything at once.
+	CollectAll Mode = iota
+
+	// FailFast returns as soon as one check fails, skipping the remaining (and more
+	// expensive, e.g. decimal parsing) checks on obviously bad input.
+	FailFast
+)
+
+// ParseMode parses "collect-all" or "fail-fast". ok is false for any other value.
+func ParseMode(s string) (mode Mode, ok bool) {
+	switch s {
+	case "collect-all":
+		return CollectAll, true
+	case "fail-fast":
+		return FailFast, true
+	}
+	return CollectAll, false
+}
+
+// String returns the name accepted by ParseMode.
+func (m Mode) String() string {
+	if m == FailFast {
+		return "fail-fast"
+	}
+	return "collect-all"
+}
+
+// stop reports whether validation should end early under mode.
+func (m Mode) stop(errs ValidationErrors) bool {
+	return m == FailFast && len(errs) > 0
+}
+
+// appendAmountError adds an error for field if value is not a positive amount, with the
+// same reason wording the services use.
+func appendAmountError(errs ValidationErrors, field, value string) ValidationErrors {
+	var moneyErr *models.MoneyError
+	if _, err := models.ParseAmount(value); errors.As(err, &moneyErr) {
+		errs = append(errs, ValidationError{Field: field, Message: moneyErr.Reason.Description()})
+	}
+	return errs
+}
+
+// MaxCategoryLength bounds a transaction category, matching the database CHECK.
+const MaxCategoryLength = 64
+
+// appendCategoryError adds an error if category is set but blank or too long.
+func appendCategoryError(errs ValidationErrors, category string) ValidationErrors {
+	if category == "" {
+		return errs
+	}
+	if strings.TrimSpace(category) == "" || utf8.RuneCountInString(category) > MaxCategoryLength {
+		errs = append(errs, ValidationError{Field: "category", Message: fmt.Sprintf("must be 1-%d non-blank characters", MaxCategoryLength)})
+	}
+	return errs
+}
+
 func ValidateCreateAccount(req *models.CreateAccountRequest) ValidationErrors {
+	return ValidateCreateAccountWithMode(req, CollectAll)
+}
+
+func ValidateCreateAccountWithMode(req *models.CreateAccountRequest, mode Mode) ValidationErrors {
 	var errs ValidationErrors
 
-	if req.AccountID <= 0 {
-		errs = append(errs, ValidationError{Field: "account_id", Message: "must be a positive integer"})
+	// Zero means omitted: the server generates the ID, which the AccountIDValidator
+	// doesn't see
+	if req.AccountID != 0 {
+		if err := currentAccountIDValidator().ValidateAccountID(req.AccountID); err != nil {
+			errs = append(errs, ValidationError{Field: "account_id", Message: err.Error()})
+		}
+	}
+	if mode.stop(errs) {
+		return errs
+	}
+
+	balance, balanceErr := models.ParseMoney(req.InitialBalance)
+	var moneyErr *models.MoneyError
+	if errors.As(balanceErr, &moneyErr) {
+		errs = append(errs, ValidationError{Field: "initial_balance", Message: moneyErr.Reason.Description()})
+	} else if balance.LessThan(decimal.Zero) {
+		errs = append(errs, ValidationError{Field: "initial_balance", Message: "cannot be negative"})
+	}
+	if mode.stop(errs) {
+		return errs
 	}
 
-	if req.InitialBalance == "" {
-		errs = append(errs, ValidationError{Field: "initial_balance", Message: "is required"})
-	} else {
-		balance, err := decimal.NewFromString(req.InitialBalance)
-		if err != nil {
-			errs = append(errs, ValidationError{Field: "initial_balance", Message: "must be a valid decimal number"})
-		} else if balance.LessThan(decimal.Zero) {
-			errs = append(errs, ValidationError{Field: "initial_balance", Message: "cannot be negative"})
+	if req.MaxBalance != "" {
+		maxBalance, err := models.ParseMoney(req.MaxBalance)
+		if errors.As(err, &moneyErr) {
+			errs = append(errs, ValidationError{Field: "max_balance", Message: moneyErr.Reason.Description()})
+		} else if maxBalance.LessThan(decimal.Zero) {
+			errs = append(errs, ValidationError{Field: "max_balance", Message: "cannot be negative"})
+		} else if balanceErr == nil && balance.GreaterThan(maxBalance) {
+			errs = append(errs, ValidationError{Field: "max_balance", Message: "cannot be less than initial_balance"})
 		}
 	}
+	if mode.stop(errs) {
+		return errs
+	}
+
+	if req.OverdraftLimit != "" {
+		overdraftLimit, err := models.ParseMoney(req.OverdraftLimit)
+		if errors.As(err, &moneyErr) {
+			errs = append(errs, ValidationError{Field: "overdraft_limit", Message: moneyErr.Reason.Description()})
+		} else if overdraftLimit.LessThan(decimal.Zero) {
+			errs = append(errs, ValidationError{Field: "overdraft_limit", Message: "cannot be negative"})
+		}
+	}
+	if mode.stop(errs) {
+		return errs
+	}
+
+	if req.AccountType != "" && !accountTypePattern.MatchString(req.AccountType) {
+		errs = append(errs, ValidationError{Field: "account_type", Message: "must be 1-32 lowercase letters, digits, or underscores, starting with a letter"})
+	}
 
 	return errs
 }
 
 func ValidateCreateTransaction(req *models.CreateTransactionRequest) ValidationErrors {
+	return ValidateCreateTransactionWithMode(req, CollectAll)
+}
+
+func ValidateCreateTransactionWithMode(req *models.CreateTransactionRequest, mode Mode) ValidationErrors {
 	var errs ValidationErrors
 
 	if req.SourceAccountID <= 0 {
 		errs = append(errs, ValidationError{Field: "source_account_id", Message: "must be a positive integer"})
 	}
+	if mode.stop(errs) {
+		return errs
+	}
 
 	if req.DestinationAccountID <= 0 {
 		errs = append(errs, ValidationError{Field: "destination_account_id", Message: "must be a positive integer"})
 	}
+	if mode.stop(errs) {
+		return errs
+	}
 
 	if req.SourceAccountID > 0 && req.DestinationAccountID > 0 && req.SourceAccountID == req.DestinationAccountID {
 		errs = append(errs, ValidationError{Field: "destination_account_id", Message: "cannot be the same as source_account_id"})
 	}
+	if mode.stop(errs) {
+		return errs
+	}
+
+	errs = appendAmountError(errs, "amount", req.Amount)
+	if mode.stop(errs) {
+		return errs
+	}
+
+	if req.EffectiveDate != "" {
+		if _, err := time.Parse(models.DateLayout, req.EffectiveDate); err != nil {
+			errs = append(errs, ValidationError{Field: "effective_date", Message: "must be a date in YYYY-MM-DD format"})
+		}
+	}
+	if mode.stop(errs) {
+		return errs
+	}
+
+	if req.Sequence != nil && *req.Sequence <= 0 {
+		errs = append(errs, ValidationError{Field: "sequence", Message: "must be a positive integer"})
+	}
+	if mode.stop(errs) {
+		return errs
+	}
+
+	errs = appendCategoryError(errs, req.Category)
+
+	return errs
+}
 
-	if req.Amount == "" {
-		errs = append(errs, ValidationError{Field: "amount", Message: "is required"})
-	} else {
-		amount, err := decimal.NewFromString(req.Amount)
-		if err != nil {
-			errs = append(errs, ValidationError{Field: "amount", Message: "must be a valid decimal number"})
-		} else if amount.LessThanOrEqual(decimal.Zero) {
-			errs = append(errs, ValidationError{Field: "amount", Message: "must be greater than zero"})
+func ValidateLedgerEntry(req *models.LedgerEntryRequest) ValidationErrors {
+	var errs ValidationErrors
+
+	errs = appendAmountError(errs, "amount", req.Amount)
+	errs = appendCategoryError(errs, req.Category)
+
+	return errs
+}
+
+func ValidateCreateHold(req *models.CreateHoldRequest) ValidationErrors {
+	var errs ValidationErrors
+
+	errs = appendAmountError(errs, "amount", req.Amount)
+
+	return errs
+}
+
+func ValidateCreateRecurringTransfer(req *models.CreateRecurringTransferRequest) ValidationErrors {
+	var errs ValidationErrors
+
+	if req.SourceAccountID <= 0 {
+		errs = append(errs, ValidationError{Field: "source_account_id", Message: "must be a positive integer"})
+	}
+	if req.DestinationAccountID <= 0 {
+		errs = append(errs, ValidationError{Field: "destination_account_id", Message: "must be a positive integer"})
+	} else if req.SourceAccountID == req.DestinationAccountID {
+		errs = append(errs, ValidationError{Field: "destination_account_id", Message: "cannot be the same as source_account_id"})
+	}
+	errs = appendAmountError(errs, "amount", req.Amount)
+	errs = appendCategoryError(errs, req.Category)
+
+	every, err := time.ParseDuration(req.Every)
+	if err != nil || every < models.MinRecurringInterval || every%time.Second != 0 {
+		errs = append(errs, ValidationError{Field: "every", Message: fmt.Sprintf("must be a duration such as 24h or 30m, in whole seconds and at least %s", models.MinRecurringInterval)})
+	}
+
+	var startAt, endAt time.Time
+	if req.StartAt != "" {
+		if startAt, err = time.Parse(time.RFC3339, req.StartAt); err != nil {
+			errs = append(errs, ValidationError{Field: "start_at", Message: "must be an RFC 3339 timestamp"})
+		}
+	}
+	if req.EndAt != "" {
+		if endAt, err = time.Parse(time.RFC3339, req.EndAt); err != nil {
+			errs = append(errs, ValidationError{Field: "end_at", Message: "must be an RFC 3339 timestamp"})
+		} else if !startAt.IsZero() && !endAt.After(startAt) {
+			errs = append(errs, ValidationError{Field: "end_at", Message: "must be after start_at"})
+		}
+	}
+
+	return errs
+}
+
+// ValidateCreateAccountBatch checks the size of a bulk account creation and every request
+// in it. It returns errors about the batch as a whole, and each request's own errors by
+// index (nil when the request is valid) with fields such as "accounts[2].initial_balance".
+func ValidateCreateAccountBatch(reqs []models.CreateAccountRequest, maxItems int) (ValidationErrors, []ValidationErrors) {
+	return ValidateCreateAccountBatchWithMode(reqs, maxItems, CollectAll)
+}
+
+func ValidateCreateAccountBatchWithMode(reqs []models.CreateAccountRequest, maxItems int, mode Mode) (ValidationErrors, []ValidationErrors) {
+	if len(reqs) == 0 {
+		return ValidationErrors{{Field: "accounts", Message: "must contain at least one account"}}, nil
+	}
+	if len(reqs) > maxItems {
+		return ValidationErrors{{Field: "accounts", Message: fmt.Sprintf("cannot contain more than %d accounts", maxItems)}}, nil
+	}
+
+	itemErrs := make([]ValidationErrors, len(reqs))
+	for i := range reqs {
+		for _, e := range ValidateCreateAccountWithMode(&reqs[i], mode) {
+			e.Field = fmt.Sprintf("accounts[%d].%s", i, e.Field)
+			itemErrs[i] = append(itemErrs[i], e)
+		}
+	}
+	return nil, itemErrs
+}
+
+func ValidateCreateBatchTransfer(req *models.CreateBatchTransferRequest, maxItems int) ValidationErrors {
+	return ValidateCreateBatchTransferWithMode(req, maxItems, CollectAll)
+}
+
+func ValidateCreateBatchTransferWithMode(req *models.CreateBatchTransferRequest, maxItems int, mode Mode) ValidationErrors {
+	var errs ValidationErrors
+
+	if req.SourceAccountID <= 0 {
+		errs = append(errs, ValidationError{Field: "source_account_id", Message: "must be a positive integer"})
+	}
+	if mode.stop(errs) {
+		return errs
+	}
+
+	if len(req.Transfers) == 0 {
+		errs = append(errs, ValidationError{Field: "transfers", Message: "must contain at least one transfer"})
+	} else if len(req.Transfers) > maxItems {
+		errs = append(errs, ValidationError{Field: "transfers", Message: fmt.Sprintf("cannot contain more than %d transfers", maxItems)})
+	}
+	if mode.stop(errs) {
+		return errs
+	}
+
+	for i, item := range req.Transfers {
+		field := fmt.Sprintf("transfers[%d]", i)
+		if item.DestinationAccountID <= 0 {
+			errs = append(errs, ValidationError{Field: field + ".destination_account_id", Message: "must be a positive integer"})
+		} else if item.DestinationAccountID == req.SourceAccountID {
+			errs = append(errs, ValidationError{Field: field + ".destination_account_id", Message: "cannot be the same as source_account_id"})
+		}
+
+		errs = appendAmountError(errs, field+".amount", item.Amount)
+		if mode.stop(errs) {
+			return errs
+		}
+	}
+
+	return errs
+}
+
+func ValidateBatchAdjust(req *models.BatchAdjustRequest, maxItems int) ValidationErrors {
+	return ValidateBatchAdjustWithMode(req, maxItems, CollectAll)
+}
+
+func ValidateBatchAdjustWithMode(req *models.BatchAdjustRequest, maxItems int, mode Mode) ValidationErrors {
+	var errs ValidationErrors
+
+	if strings.TrimSpace(req.Reason) == "" {
+		errs = append(errs, ValidationError{Field: "reason", Message: "is required"})
+	}
+	if mode.stop(errs) {
+		return errs
+	}
+
+	if len(req.Adjustments) == 0 {
+		errs = append(errs, ValidationError{Field: "adjustments", Message: "must contain at least one adjustment"})
+	} else if len(req.Adjustments) > maxItems {
+		errs = append(errs, ValidationError{Field: "adjustments", Message: fmt.Sprintf("cannot contain more than %d adjustments", maxItems)})
+	}
+	if mode.stop(errs) {
+		return errs
+	}
+
+	seen := make(map[int64]bool, len(req.Adjustments))
+	for i, item := range req.Adjustments {
+		field := fmt.Sprintf("adjustments[%d]", i)
+		if item.AccountID <= 0 {
+			errs = append(errs, ValidationError{Field: field + ".account_id", Message: "must be a positive integer"})
+		} else if seen[item.AccountID] {
+			errs = append(errs, ValidationError{Field: field + ".account_id", Message: "is listed more than once"})
+		}
+		seen[item.AccountID] = true
+
+		if item.Delta == "" {
+			errs = append(errs, ValidationError{Field: field + ".delta", Message: "is required"})
+		} else if delta, err := decimal.NewFromString(item.Delta); err != nil {
+			errs = append(errs, ValidationError{Field: field + ".delta", Message: "must be a valid decimal number"})
+		} else if delta.IsZero() {
+			errs = append(errs, ValidationError{Field: field + ".delta", Message: "cannot be zero"})
+		}
+		if mode.stop(errs) {
+			return errs
+		}
+	}
+
+	return errs
+}
+
+// ValidateReconcile checks a reconciliation import: between one and maxItems accounts,
+// each listed once with a decimal expected_balance, which may be negative. Fields are
+// reported as "accounts[2].expected_balance".
+func ValidateReconcile(items []models.ReconcileItem, maxItems int) ValidationErrors {
+	return ValidateReconcileWithMode(items, maxItems, CollectAll)
+}
+
+func ValidateReconcileWithMode(items []models.ReconcileItem, maxItems int, mode Mode) ValidationErrors {
+	if len(items) == 0 {
+		return ValidationErrors{{Field: "accounts", Message: "must contain at least one account"}}
+	}
+	if len(items) > maxItems {
+		return ValidationErrors{{Field: "accounts", Message: fmt.Sprintf("cannot contain more than %d accounts", maxItems)}}
+	}
+
+	var errs ValidationErrors
+	seen := make(map[int64]bool, len(items))
+	for i, item := range items {
+		field := fmt.Sprintf("accounts[%d]", i)
+		if item.AccountID <= 0 {
+			errs = append(errs, ValidationError{Field: field + ".account_id", Message: "must be a positive integer"})
+		} else if seen[item.AccountID] {
+			errs = append(errs, ValidationError{Field: field + ".account_id", Message: "is listed more than once"})
+		}
+		seen[item.AccountID] = true
+		if mode.stop(errs) {
+			return errs
+		}
+
+		var moneyErr *models.MoneyError
+		if _, err := models.ParseMoney(item.ExpectedBalance); errors.As(err, &moneyErr) {
+			errs = append(errs, ValidationError{Field: field + ".expected_balance", Message: moneyErr.Reason.Description()})
+		}
+		if mode.stop(errs) {
+			return errs
 		}
 	}
 
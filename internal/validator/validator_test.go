@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"strings"
 	"testing"
 
 	"internal-transfers-system/internal/models"
@@ -13,11 +14,22 @@ func TestValidateCreateAccount(t *testing.T) {
 		wantErr bool
 	}{
 		{"valid", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "1000"}, false},
-		{"zero id", &models.CreateAccountRequest{AccountID: 0, InitialBalance: "1000"}, true},
+		{"cents", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "0.01"}, false},
+		{"balance beyond scale", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100.001"}, true},
+		{"max balance beyond scale", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100.00", MaxBalance: "500.999"}, true},
+		{"omitted id", &models.CreateAccountRequest{AccountID: 0, InitialBalance: "1000"}, false},
 		{"negative id", &models.CreateAccountRequest{AccountID: -1, InitialBalance: "1000"}, true},
 		{"missing balance", &models.CreateAccountRequest{AccountID: 1, InitialBalance: ""}, true},
 		{"invalid balance", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "abc"}, true},
 		{"negative balance", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "-100"}, true},
+		{"valid max balance", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100", MaxBalance: "500"}, false},
+		{"max balance below initial", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "600", MaxBalance: "500"}, true},
+		{"invalid max balance", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100", MaxBalance: "abc"}, true},
+		{"valid overdraft limit", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "0", OverdraftLimit: "250.50"}, false},
+		{"negative overdraft limit", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100", OverdraftLimit: "-1"}, true},
+		{"invalid overdraft limit", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100", OverdraftLimit: "abc"}, true},
+		{"valid account type", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100", AccountType: "escrow_2"}, false},
+		{"invalid account type", &models.CreateAccountRequest{AccountID: 1, InitialBalance: "100", AccountType: "Escrow Account"}, true},
 	}
 
 	for _, tt := range tests {
@@ -43,6 +55,15 @@ func TestValidateCreateTransaction(t *testing.T) {
 		{"missing amount", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: ""}, true},
 		{"zero amount", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "0"}, true},
 		{"negative amount", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "-100"}, true},
+		{"two decimal places", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100.00"}, false},
+		{"too many decimal places", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100.001"}, true},
+		{"valid effective date", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100", EffectiveDate: "2024-03-31"}, false},
+		{"invalid effective date", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100", EffectiveDate: "31/03/2024"}, true},
+		{"positive sequence", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100", Sequence: int64Ptr(1)}, false},
+		{"zero sequence", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100", Sequence: int64Ptr(0)}, true},
+		{"category", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100", Category: "rent"}, false},
+		{"blank category", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100", Category: "   "}, true},
+		{"category too long", &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "100", Category: strings.Repeat("x", MaxCategoryLength+1)}, true},
 	}
 
 	for _, tt := range tests {
@@ -54,3 +75,216 @@ func TestValidateCreateTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateLedgerEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  string
+		wantErr bool
+	}{
+		{"valid", "10.25", false},
+		{"missing", "", true},
+		{"not a number", "ten", true},
+		{"zero", "0", true},
+		{"negative", "-5", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateLedgerEntry(&models.LedgerEntryRequest{Amount: tt.amount})
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("wantErr=%v, got %v errors", tt.wantErr, len(errs))
+			}
+		})
+	}
+}
+
+func TestValidateCreateRecurringTransfer(t *testing.T) {
+	valid := func() models.CreateRecurringTransferRequest {
+		return models.CreateRecurringTransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "10", Every: "24h"}
+	}
+	tests := []struct {
+		name      string
+		modify    func(*models.CreateRecurringTransferRequest)
+		wantField string
+	}{
+		{"valid", func(*models.CreateRecurringTransferRequest) {}, ""},
+		{"valid with window", func(r *models.CreateRecurringTransferRequest) {
+			r.StartAt, r.EndAt = "2024-03-01T09:00:00Z", "2024-06-01T09:00:00Z"
+		}, ""},
+		{"same account", func(r *models.CreateRecurringTransferRequest) { r.DestinationAccountID = 1 }, "destination_account_id"},
+		{"bad amount", func(r *models.CreateRecurringTransferRequest) { r.Amount = "0" }, "amount"},
+		{"missing every", func(r *models.CreateRecurringTransferRequest) { r.Every = "" }, "every"},
+		{"every too short", func(r *models.CreateRecurringTransferRequest) { r.Every = "30s" }, "every"},
+		{"every not whole seconds", func(r *models.CreateRecurringTransferRequest) { r.Every = "1m500ms" }, "every"},
+		{"bad start_at", func(r *models.CreateRecurringTransferRequest) { r.StartAt = "2024-03-01" }, "start_at"},
+		{"end_at before start_at", func(r *models.CreateRecurringTransferRequest) {
+			r.StartAt, r.EndAt = "2024-03-01T09:00:00Z", "2024-03-01T08:00:00Z"
+		}, "end_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+			errs := ValidateCreateRecurringTransfer(&req)
+			if tt.wantField == "" {
+				if len(errs) > 0 {
+					t.Errorf("expected no errors, got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Field != tt.wantField {
+				t.Errorf("expected one %s error, got %v", tt.wantField, errs)
+			}
+		})
+	}
+}
+
+func TestValidationMode(t *testing.T) {
+	account := &models.CreateAccountRequest{AccountID: -1, InitialBalance: "abc", MaxBalance: "xyz"}
+	if errs := ValidateCreateAccountWithMode(account, CollectAll); len(errs) != 3 {
+		t.Errorf("collect-all: expected 3 errors, got %v", errs)
+	}
+	if errs := ValidateCreateAccountWithMode(account, FailFast); len(errs) != 1 || errs[0].Field != "account_id" {
+		t.Errorf("fail-fast: expected only account_id, got %v", errs)
+	}
+
+	txn := &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 0, Amount: "", EffectiveDate: "bad"}
+	if errs := ValidateCreateTransactionWithMode(txn, CollectAll); len(errs) != 3 {
+		t.Errorf("collect-all: expected 3 errors, got %v", errs)
+	}
+	if errs := ValidateCreateTransactionWithMode(txn, FailFast); len(errs) != 1 || errs[0].Field != "destination_account_id" {
+		t.Errorf("fail-fast: expected only destination_account_id, got %v", errs)
+	}
+
+	for _, name := range []string{"collect-all", "fail-fast"} {
+		if mode, ok := ParseMode(name); !ok || mode.String() != name {
+			t.Errorf("ParseMode(%q) = %v, %v", name, mode, ok)
+		}
+	}
+	if _, ok := ParseMode("fast"); ok {
+		t.Error("expected unknown mode to be rejected")
+	}
+}
+
+func TestValidateBatchAdjust(t *testing.T) {
+	item := func(id int64, delta string) models.BalanceAdjustmentItem {
+		return models.BalanceAdjustmentItem{AccountID: id, Delta: delta}
+	}
+	tests := []struct {
+		name     string
+		req      *models.BatchAdjustRequest
+		wantErrs int
+	}{
+		{"valid", &models.BatchAdjustRequest{Reason: "fix", Adjustments: []models.BalanceAdjustmentItem{item(1, "10"), item(2, "-3.5")}}, 0},
+		{"missing reason", &models.BatchAdjustRequest{Adjustments: []models.BalanceAdjustmentItem{item(1, "10")}}, 1},
+		{"empty", &models.BatchAdjustRequest{Reason: "fix"}, 1},
+		{"too many", &models.BatchAdjustRequest{Reason: "fix", Adjustments: []models.BalanceAdjustmentItem{item(1, "1"), item(2, "1"), item(3, "1")}}, 1},
+		{"bad items", &models.BatchAdjustRequest{Reason: "fix", Adjustments: []models.BalanceAdjustmentItem{item(0, "1"), item(2, "0")}}, 2},
+		{"duplicate and invalid delta", &models.BatchAdjustRequest{Reason: "fix", Adjustments: []models.BalanceAdjustmentItem{item(2, "1"), item(2, "x")}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateBatchAdjust(tt.req, 2)
+			if len(errs) != tt.wantErrs {
+				t.Errorf("expected %d errors, got %v", tt.wantErrs, errs)
+			}
+		})
+	}
+
+	bad := &models.BatchAdjustRequest{Adjustments: []models.BalanceAdjustmentItem{item(0, "0")}}
+	if errs := ValidateBatchAdjustWithMode(bad, 2, FailFast); len(errs) != 1 {
+		t.Errorf("expected fail-fast to stop at 1 error, got %v", errs)
+	}
+}
+
+func TestValidateReconcile(t *testing.T) {
+	item := func(id int64, balance string) models.ReconcileItem {
+		return models.ReconcileItem{AccountID: id, ExpectedBalance: balance}
+	}
+	tests := []struct {
+		name     string
+		items    []models.ReconcileItem
+		wantErrs int
+	}{
+		{"valid", []models.ReconcileItem{item(1, "10"), item(2, "-3.5")}, 0},
+		{"zero balance", []models.ReconcileItem{item(1, "0")}, 0},
+		{"empty", nil, 1},
+		{"too many", []models.ReconcileItem{item(1, "1"), item(2, "1"), item(3, "1")}, 1},
+		{"bad items", []models.ReconcileItem{item(0, "1"), item(2, "")}, 2},
+		{"duplicate and invalid balance", []models.ReconcileItem{item(2, "1"), item(2, "x")}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateReconcile(tt.items, 2)
+			if len(errs) != tt.wantErrs {
+				t.Errorf("expected %d errors, got %v", tt.wantErrs, errs)
+			}
+		})
+	}
+
+	errs := ValidateReconcile([]models.ReconcileItem{item(1, "1"), item(2, "1.005")}, 2)
+	if len(errs) != 1 || errs[0].Field != "accounts[1].expected_balance" {
+		t.Errorf("expected an accounts[1].expected_balance error, got %v", errs)
+	}
+	bad := []models.ReconcileItem{item(0, "x")}
+	if errs := ValidateReconcileWithMode(bad, 2, FailFast); len(errs) != 1 {
+		t.Errorf("expected fail-fast to stop at 1 error, got %v", errs)
+	}
+}
+
+func TestValidateCreateBatchTransfer(t *testing.T) {
+	item := func(dest int64, amount string) models.BatchTransferItem {
+		return models.BatchTransferItem{DestinationAccountID: dest, Amount: amount}
+	}
+	tests := []struct {
+		name     string
+		req      *models.CreateBatchTransferRequest
+		wantErrs int
+	}{
+		{"valid", &models.CreateBatchTransferRequest{SourceAccountID: 1, Transfers: []models.BatchTransferItem{item(2, "10"), item(2, "0.5")}}, 0},
+		{"zero source", &models.CreateBatchTransferRequest{Transfers: []models.BatchTransferItem{item(2, "10")}}, 1},
+		{"empty", &models.CreateBatchTransferRequest{SourceAccountID: 1}, 1},
+		{"too many", &models.CreateBatchTransferRequest{SourceAccountID: 1, Transfers: []models.BatchTransferItem{item(2, "1"), item(3, "1"), item(4, "1")}}, 1},
+		{"bad legs", &models.CreateBatchTransferRequest{SourceAccountID: 1, Transfers: []models.BatchTransferItem{item(1, "1"), item(0, "x")}}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateCreateBatchTransfer(tt.req, 2)
+			if len(errs) != tt.wantErrs {
+				t.Errorf("expected %d errors, got %v", tt.wantErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateCreateAccountBatch(t *testing.T) {
+	reqs := []models.CreateAccountRequest{
+		{AccountID: 1, InitialBalance: "10"},
+		{AccountID: -1, InitialBalance: "abc"},
+		{InitialBalance: "5"},
+	}
+	batchErrs, itemErrs := ValidateCreateAccountBatch(reqs, 3)
+	if len(batchErrs) != 0 || len(itemErrs) != 3 {
+		t.Fatalf("expected per-item errors only, got %v and %v", batchErrs, itemErrs)
+	}
+	if len(itemErrs[0]) != 0 || len(itemErrs[2]) != 0 {
+		t.Errorf("expected items 0 and 2 to be valid, got %v", itemErrs)
+	}
+	if len(itemErrs[1]) != 2 || itemErrs[1][0].Field != "accounts[1].account_id" {
+		t.Errorf("expected two errors prefixed accounts[1], got %v", itemErrs[1])
+	}
+
+	for name, reqs := range map[string][]models.CreateAccountRequest{"empty": nil, "too many": append(reqs, reqs[0])} {
+		batchErrs, itemErrs := ValidateCreateAccountBatch(reqs, 3)
+		if len(batchErrs) != 1 || batchErrs[0].Field != "accounts" || itemErrs != nil {
+			t.Errorf("%s: expected one batch error, got %v and %v", name, batchErrs, itemErrs)
+		}
+	}
+}
+
+func int64Ptr(n int64) *int64 { return &n }
@@ -0,0 +1,198 @@
+// Package webhook pushes completed transfers to downstream systems over HTTP.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"internal-transfers-system/internal/logging"
+	"internal-transfers-system/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the request body, keyed
+// with the shared secret. Receivers should recompute it and compare in constant time.
+const SignatureHeader = "X-Webhook-Signature"
+
+// Event is the JSON body POSTed for each completed transfer.
+type Event struct {
+	TransactionID        int64  `json:"transaction_id"`
+	SourceAccountID      int64  `json:"source_account_id"`
+	DestinationAccountID int64  `json:"destination_account_id"`
+	Amount               string `json:"amount"`
+	Timestamp            string `json:"timestamp"`
+}
+
+// Config configures a Notifier.
+type Config struct {
+	// URLs receive every event. Secret keys the signature.
+	URLs   []string
+	Secret string
+
+	// QueueSize bounds the events waiting for delivery. When the queue is full new
+	// events are dropped and logged rather than holding up the transfer.
+	QueueSize int
+
+	// MaxAttempts bounds deliveries of one event to one URL. Retries back off
+	// exponentially from RetryBaseDelay.
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+
+	// Timeout bounds each HTTP request.
+	Timeout time.Duration
+}
+
+// Notifier is a TransferHook that queues an Event for every committed transfer and
+// delivers it from a background worker, so a slow or failing endpoint never blocks or
+// fails a transfer. Delivery is best effort: events still queued when Run stops, or
+// that run out of attempts, are logged and dropped.
+type Notifier struct {
+	cfg    Config
+	client *http.Client
+	events chan Event
+}
+
+// NewNotifier returns a Notifier for cfg. Nothing is delivered until Run is started.
+func NewNotifier(cfg Config) *Notifier {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	return &Notifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		events: make(chan Event, cfg.QueueSize),
+	}
+}
+
+// PreCommit does nothing: events are only sent for transfers that committed.
+func (n *Notifier) PreCommit(context.Context, pgx.Tx, *models.Transaction) error {
+	return nil
+}
+
+// PostCommit queues an event for txn without waiting for delivery.
+func (n *Notifier) PostCommit(ctx context.Context, txn *models.Transaction) {
+	timestamp := txn.CreatedAt
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	event := Event{
+		TransactionID:        txn.TransactionID,
+		SourceAccountID:      txn.SourceAccountID,
+		DestinationAccountID: txn.DestinationAccountID,
+		Amount:               models.FormatMoney(txn.Amount),
+		Timestamp:            timestamp.UTC().Format(models.TimestampLayout),
+	}
+
+	select {
+	case n.events <- event:
+	default:
+		logging.FromContext(ctx).Warn().
+			Int64("transactionID", txn.TransactionID).
+			Msg("Webhook queue full, dropping event")
+	}
+}
+
+// Run delivers queued events until ctx is cancelled. Events still queued then are
+// dropped.
+func (n *Notifier) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-n.events:
+			n.deliver(ctx, event)
+		case <-ctx.Done():
+			if dropped := len(n.events); dropped > 0 {
+				log.Warn().Int("events", dropped).Msg("Webhook notifier stopped with undelivered events")
+			}
+			return
+		}
+	}
+}
+
+// deliver POSTs event to every URL, retrying each independently.
+func (n *Notifier) deliver(ctx context.Context, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Error().Err(err).Int64("transactionID", event.TransactionID).Msg("Failed to encode webhook event")
+		return
+	}
+	signature := Sign(n.cfg.Secret, body)
+
+	for _, url := range n.cfg.URLs {
+		if err := n.deliverTo(ctx, url, body, signature); err != nil {
+			log.Error().Err(err).
+				Str("url", url).
+				Int64("transactionID", event.TransactionID).
+				Msg("Webhook delivery failed")
+		}
+	}
+}
+
+// deliverTo POSTs body to url, retrying network errors, 429, and 5xx responses up to
+// MaxAttempts times. Any other non-2xx response is final.
+func (n *Notifier) deliverTo(ctx context.Context, url string, body []byte, signature string) error {
+	var lastErr error
+	for attempt := 0; attempt < n.cfg.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := n.cfg.RetryBaseDelay * time.Duration(1<<(attempt-1))
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		retry, err := n.post(ctx, url, body, signature)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			return err
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", n.cfg.MaxAttempts, lastErr)
+}
+
+// post sends one request and reports whether a failure is worth retrying.
+func (n *Notifier) post(ctx context.Context, url string, body []byte, signature string) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, signature)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+}
+
+// Sign returns the SignatureHeader value for body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
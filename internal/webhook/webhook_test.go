@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"internal-transfers-system/internal/mocks"
+	"internal-transfers-system/internal/models"
+	"internal-transfers-system/internal/service"
+
+	"github.com/shopspring/decimal"
+)
+
+const testSecret = "webhook-secret"
+
+// receiver records what a webhook endpoint was sent, answering with the statuses in
+// order and 200 once they run out.
+type receiver struct {
+	statuses  []int
+	calls     atomic.Int32
+	bodies    chan []byte
+	signature chan string
+}
+
+func newReceiver(statuses ...int) (*receiver, *httptest.Server) {
+	r := &receiver{statuses: statuses, bodies: make(chan []byte, 10), signature: make(chan string, 10)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := int(r.calls.Add(1))
+		body, _ := io.ReadAll(req.Body)
+		if n <= len(r.statuses) {
+			w.WriteHeader(r.statuses[n-1])
+			return
+		}
+		r.bodies <- body
+		r.signature <- req.Header.Get(SignatureHeader)
+	}))
+	return r, srv
+}
+
+func testConfig(urls ...string) Config {
+	return Config{URLs: urls, Secret: testSecret, QueueSize: 10, MaxAttempts: 3, RetryBaseDelay: time.Millisecond, Timeout: time.Second}
+}
+
+func startNotifier(t *testing.T, cfg Config) *Notifier {
+	t.Helper()
+	n := NewNotifier(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go n.Run(ctx)
+	return n
+}
+
+func TestNotifier_PayloadAndSignature(t *testing.T) {
+	r, srv := newReceiver()
+	defer srv.Close()
+	n := startNotifier(t, testConfig(srv.URL))
+
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	n.PostCommit(context.Background(), &models.Transaction{
+		TransactionID: 9, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.RequireFromString("12.50"), CreatedAt: createdAt,
+	})
+
+	select {
+	case body := <-r.bodies:
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Fatalf("decode %s: %v", body, err)
+		}
+		want := Event{TransactionID: 9, SourceAccountID: 1, DestinationAccountID: 2, Amount: "12.5", Timestamp: createdAt.Format(models.TimestampLayout)}
+		if event != want {
+			t.Errorf("expected %+v, got %+v", want, event)
+		}
+		if sig := <-r.signature; sig != Sign(testSecret, body) {
+			t.Errorf("signature %q doesn't match the body", sig)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the webhook to be delivered")
+	}
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	r, srv := newReceiver(http.StatusInternalServerError, http.StatusTooManyRequests)
+	defer srv.Close()
+	n := startNotifier(t, testConfig(srv.URL))
+
+	n.PostCommit(context.Background(), &models.Transaction{TransactionID: 1, Amount: decimal.NewFromInt(1)})
+
+	select {
+	case <-r.bodies:
+		if got := r.calls.Load(); got != 3 {
+			t.Errorf("expected delivery on the third attempt, got %d calls", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the webhook to be delivered after retries")
+	}
+}
+
+func TestNotifier_ClientErrorIsFinal(t *testing.T) {
+	r, srv := newReceiver(http.StatusBadRequest)
+	defer srv.Close()
+	n := NewNotifier(testConfig(srv.URL))
+
+	n.deliver(context.Background(), Event{TransactionID: 1})
+	if got := r.calls.Load(); got != 1 {
+		t.Errorf("expected a 400 not to be retried, got %d calls", got)
+	}
+}
+
+func TestNotifier_FullQueueDoesNotBlock(t *testing.T) {
+	cfg := testConfig("http://127.0.0.1:1")
+	cfg.QueueSize = 1
+	n := NewNotifier(cfg) // never started, so nothing drains the queue
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			n.PostCommit(context.Background(), &models.Transaction{TransactionID: int64(i)})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("PostCommit blocked on a full queue")
+	}
+	if got := len(n.events); got != 1 {
+		t.Errorf("expected one queued event, got %d", got)
+	}
+}
+
+func TestNotifier_FailingWebhookKeepsTransfer(t *testing.T) {
+	r, srv := newReceiver(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	defer srv.Close()
+
+	accRepo := mocks.NewMockAccountRepository()
+	accRepo.SetAccount(&models.Account{AccountID: 1, Balance: decimal.NewFromInt(100)})
+	accRepo.SetAccount(&models.Account{AccountID: 2, Balance: decimal.NewFromInt(0)})
+	svc := service.NewTransferService(accRepo, mocks.NewMockTransactionRepository())
+	svc.AddHook(startNotifier(t, testConfig(srv.URL)))
+
+	txn, err := svc.Transfer(context.Background(), &models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "40"})
+	if err != nil {
+		t.Fatalf("expected the transfer to succeed, got %v", err)
+	}
+	if txn.Status != models.TransactionStatusCompleted {
+		t.Errorf("expected a completed transfer, got %s", txn.Status)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for r.calls.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := r.calls.Load(); got != 3 {
+		t.Fatalf("expected every attempt to be made, got %d", got)
+	}
+	src, _ := accRepo.GetAccount(1)
+	dst, _ := accRepo.GetAccount(2)
+	if src.Balance.String() != "60" || dst.Balance.String() != "40" {
+		t.Errorf("expected balances 60 and 40 to stand, got %s and %s", src.Balance, dst.Balance)
+	}
+}
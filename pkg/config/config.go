@@ -2,17 +2,29 @@ package pkg
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pankajvermacr7/go-kit/pgx"
+	"github.com/shopspring/decimal"
 )
 
 // Config holds all configuration for the application.
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Log      LogConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Transfer   TransferConfig
+	Account    AccountConfig
+	Pagination PaginationConfig
+	Money      MoneyConfig
+	Tracing    TracingConfig
+	Metrics    MetricsConfig
+	Webhook    WebhookConfig
+	Log        LogConfig
 }
 
 // ServerConfig holds HTTP server configuration.
@@ -22,15 +34,97 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"15s"`
 	WriteTimeout time.Duration `envconfig:"SERVER_WRITE_TIMEOUT" default:"15s"`
 	IdleTimeout  time.Duration `envconfig:"SERVER_IDLE_TIMEOUT" default:"60s"`
+
+	// RequestTimeout bounds each request's context, so database work for a slow request is
+	// cancelled and answered with 504. Zero disables it.
+	RequestTimeout time.Duration `envconfig:"SERVER_REQUEST_TIMEOUT" default:"10s"`
+
+	// MaxRequestBody caps JSON request bodies in bytes. Larger bodies are rejected with 413.
+	MaxRequestBody int64 `envconfig:"SERVER_MAX_REQUEST_BODY" default:"1048576"`
+
+	// AdminToken is the bearer token required by admin endpoints such as the account export.
+	// When empty, admin endpoints are disabled.
+	AdminToken string `envconfig:"SERVER_ADMIN_TOKEN"`
+
+	// RouteMetricsEnabled turns on per-route request counts and latency percentiles,
+	// logged as one summary line per route every RouteMetricsInterval.
+	RouteMetricsEnabled  bool          `envconfig:"SERVER_ROUTE_METRICS_ENABLED" default:"false"`
+	RouteMetricsInterval time.Duration `envconfig:"SERVER_ROUTE_METRICS_INTERVAL" default:"1m"`
+
+	// ConsistencyTokensEnabled returns an X-Consistency-Token (commit LSN) on successful
+	// writes and accepts it on reads for read-your-writes guarantees.
+	ConsistencyTokensEnabled bool `envconfig:"SERVER_CONSISTENCY_TOKENS_ENABLED" default:"false"`
+
+	// ValidationFailFast makes request validation stop at the first error instead of
+	// reporting all of them. Clients can override it per request with X-Validation-Mode.
+	ValidationFailFast bool `envconfig:"SERVER_VALIDATION_FAIL_FAST" default:"false"`
+
+	// RateLimitEnabled turns on a per-client-IP token bucket allowing RateLimitRPS
+	// requests per second sustained with bursts of up to RateLimitBurst.
+	RateLimitEnabled bool    `envconfig:"SERVER_RATE_LIMIT_ENABLED" default:"false"`
+	RateLimitRPS     float64 `envconfig:"SERVER_RATE_LIMIT_RPS" default:"50"`
+	RateLimitBurst   int     `envconfig:"SERVER_RATE_LIMIT_BURST" default:"100"`
+
+	// AccountCreateRateLimitEnabled adds a separate, stricter per-client-IP limit on
+	// account creation routes, independent of (and applied after) the global rate limit.
+	AccountCreateRateLimitEnabled bool    `envconfig:"SERVER_ACCOUNT_CREATE_RATE_LIMIT_ENABLED" default:"false"`
+	AccountCreateRateLimitRPS     float64 `envconfig:"SERVER_ACCOUNT_CREATE_RATE_LIMIT_RPS" default:"0.2"`
+	AccountCreateRateLimitBurst   int     `envconfig:"SERVER_ACCOUNT_CREATE_RATE_LIMIT_BURST" default:"5"`
+
+	// DebugResponsesEnabled lets clients send "X-Debug: true" to get diagnostic details
+	// (isolation level, retry budget, attempts) in transfer responses. Never enable in production.
+	DebugResponsesEnabled bool `envconfig:"SERVER_DEBUG_RESPONSES_ENABLED" default:"false"`
+
+	// IDEncoding is how transaction IDs appear in the API: "raw" integers, or "opaque"
+	// strings derived from OpaqueIDSalt. Changing the salt invalidates IDs already issued.
+	IDEncoding   string `envconfig:"SERVER_ID_ENCODING" default:"raw"`
+	OpaqueIDSalt string `envconfig:"SERVER_OPAQUE_ID_SALT"`
+
+	// GRPCPort, when set, serves the gRPC API on Host at this port next to HTTP. Zero
+	// disables it. It can't be combined with opaque IDs.
+	GRPCPort int `envconfig:"SERVER_GRPC_PORT" default:"0"`
+
+	// TLSCertFile and TLSKeyFile are PEM files of the HTTP server's certificate chain and
+	// private key. With both set the server speaks HTTPS only; with neither, plain HTTP.
+	TLSCertFile string `envconfig:"SERVER_TLS_CERT_FILE"`
+	TLSKeyFile  string `envconfig:"SERVER_TLS_KEY_FILE"`
+
+	// TLSClientCAFile, when set, turns on mutual TLS: clients must present a certificate
+	// signed by one of the PEM certificates in this file. Requires TLSCertFile.
+	TLSClientCAFile string `envconfig:"SERVER_TLS_CLIENT_CA_FILE"`
 }
 
+// ID encodings accepted in SERVER_ID_ENCODING.
+const (
+	IDEncodingRaw    = "raw"
+	IDEncodingOpaque = "opaque"
+)
+
+// minOpaqueIDSaltLength mirrors idcodec.MinSaltLength so a short salt fails at load time.
+const minOpaqueIDSaltLength = 16
+
 // Address returns the server address in host:port format.
 func (s ServerConfig) Address() string {
 	return fmt.Sprintf("%s:%d", s.Host, s.Port)
 }
 
+// TLSEnabled reports whether the HTTP server serves HTTPS.
+func (s ServerConfig) TLSEnabled() bool {
+	return s.TLSCertFile != "" && s.TLSKeyFile != ""
+}
+
+// GRPCAddress returns the gRPC server address in host:port format.
+func (s ServerConfig) GRPCAddress() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.GRPCPort)
+}
+
 // DatabaseConfig holds database configuration aligned with go-kit/pgx.Config.
 type DatabaseConfig struct {
+	// Driver selects the storage backend: "postgres", or "memory" to keep everything in
+	// process for local development, with nothing persisted and the DB_* settings below
+	// unused.
+	Driver string `envconfig:"DB_DRIVER" default:"postgres"`
+
 	Host           string        `envconfig:"DB_HOST" default:"localhost"`
 	Port           int           `envconfig:"DB_PORT" default:"5432"`
 	Username       string        `envconfig:"DB_USERNAME" default:"postgres"`
@@ -40,9 +134,60 @@ type DatabaseConfig struct {
 	MaxConns       int           `envconfig:"DB_MAX_CONNS" default:"10"`
 	Timeout        time.Duration `envconfig:"DB_TIMEOUT" default:"5s"`
 	MigrationsPath string        `envconfig:"DB_MIGRATIONS_PATH" default:"migrations"`
+
+	// MinConns is how many connections each pool opens at startup and keeps open, so a
+	// burst of requests doesn't wait on new connections. It is capped at the pool's size.
+	MinConns int `envconfig:"DB_MIN_CONNS" default:"2"`
+
+	// MaxConnLifetime and MaxConnIdleTime close connections that have been open, or
+	// unused, for longer. HealthCheckPeriod is how often idle connections are checked and
+	// the pool is topped back up to MinConns.
+	MaxConnLifetime   time.Duration `envconfig:"DB_MAX_CONN_LIFETIME" default:"1h"`
+	MaxConnIdleTime   time.Duration `envconfig:"DB_MAX_CONN_IDLE_TIME" default:"30m"`
+	HealthCheckPeriod time.Duration `envconfig:"DB_HEALTH_CHECK_PERIOD" default:"1m"`
+
+	// AcquireTimeout is how long a request waits for a free connection in the transfer
+	// pool before it is answered 503 with Retry-After. Zero waits until the request times
+	// out.
+	AcquireTimeout time.Duration `envconfig:"DB_ACQUIRE_TIMEOUT" default:"2s"`
+
+	// ReadMaxConns and ExportMaxConns size separate pools for lightweight reads and for
+	// long exports, so neither can exhaust the MaxConns pool that transfers lock rows
+	// through. Zero shares the transfer pool (reads) or the read pool (exports).
+	ReadMaxConns   int `envconfig:"DB_READ_MAX_CONNS" default:"5"`
+	ExportMaxConns int `envconfig:"DB_EXPORT_MAX_CONNS" default:"2"`
+
+	// ReplicaHost, when set, names a streaming read replica that serves account lookups
+	// and transaction listings, reached with the primary's credentials and database.
+	// ReplicaPort defaults to Port, and ReplicaMaxConns sizes the replica's pool.
+	ReplicaHost     string `envconfig:"DB_REPLICA_HOST"`
+	ReplicaPort     int    `envconfig:"DB_REPLICA_PORT"`
+	ReplicaMaxConns int    `envconfig:"DB_REPLICA_MAX_CONNS" default:"5"`
+
+	// IsolationLevel is the isolation level for read-write transactions: "read committed",
+	// "repeatable read", or "serializable" (underscores are accepted in place of spaces).
+	// Stricter levels raise more serialization failures, which transfers retry.
+	IsolationLevel string `envconfig:"DB_ISOLATION_LEVEL" default:"read committed"`
 }
 
-// ToPgxConfig converts DatabaseConfig to go-kit/pgx.Config.
+// normalizeIsolationLevel returns level as Postgres names it, or an error if it isn't
+// one of the supported levels.
+func normalizeIsolationLevel(level string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(strings.ReplaceAll(level, "_", " ")))
+	switch normalized {
+	case "read committed", "repeatable read", "serializable":
+		return normalized, nil
+	}
+	return "", fmt.Errorf("unsupported DB_ISOLATION_LEVEL %q", level)
+}
+
+// Storage backends accepted in DB_DRIVER.
+const (
+	DBDriverPostgres = "postgres"
+	DBDriverMemory   = "memory"
+)
+
+// ToPgxConfig converts DatabaseConfig to go-kit/pgx.Config, which migrations run through.
 func (d DatabaseConfig) ToPgxConfig() pgx.Config {
 	return pgx.Config{
 		Host:     d.Host,
@@ -56,6 +201,45 @@ func (d DatabaseConfig) ToPgxConfig() pgx.Config {
 	}
 }
 
+// PoolConfig returns the pgxpool configuration for a pool of maxConns connections, with
+// MinConns capped at maxConns.
+func (d DatabaseConfig) PoolConfig(maxConns int) (*pgxpool.Config, error) {
+	cfg, err := pgxpool.ParseConfig(d.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("parse database config: %w", err)
+	}
+	cfg.MaxConns = int32(maxConns)
+	cfg.MinConns = int32(min(d.MinConns, maxConns))
+	cfg.MaxConnLifetime = d.MaxConnLifetime
+	cfg.MaxConnIdleTime = d.MaxConnIdleTime
+	cfg.HealthCheckPeriod = d.HealthCheckPeriod
+	cfg.ConnConfig.ConnectTimeout = d.Timeout
+	return cfg, nil
+}
+
+// Replica returns the configuration for the read replica, or false if none is set. It
+// differs from d only in Host and Port.
+func (d DatabaseConfig) Replica() (DatabaseConfig, bool) {
+	if d.ReplicaHost == "" {
+		return DatabaseConfig{}, false
+	}
+	replica := d
+	replica.Host = d.ReplicaHost
+	if d.ReplicaPort != 0 {
+		replica.Port = d.ReplicaPort
+	}
+	return replica, true
+}
+
+// ReplicaDSN returns the read replica's connection string, or "" if none is set.
+func (d DatabaseConfig) ReplicaDSN() string {
+	replica, ok := d.Replica()
+	if !ok {
+		return ""
+	}
+	return replica.DSN()
+}
+
 // DSN returns the database connection string.
 func (d DatabaseConfig) DSN() string {
 	return fmt.Sprintf(
@@ -64,10 +248,200 @@ func (d DatabaseConfig) DSN() string {
 	)
 }
 
+// TransferConfig holds retry behaviour for the transfer service.
+type TransferConfig struct {
+	MaxRetries     int           `envconfig:"TRANSFER_MAX_RETRIES" default:"3"`
+	RetryBaseDelay time.Duration `envconfig:"TRANSFER_RETRY_BASE_DELAY" default:"100ms"`
+
+	// RetryJitter randomizes each retry's wait between zero and the exponential backoff.
+	RetryJitter bool `envconfig:"TRANSFER_RETRY_JITTER" default:"true"`
+
+	// ConcurrencyMode is "pessimistic" (SELECT ... FOR UPDATE) or "optimistic" (version
+	// checked updates, retried on conflict).
+	ConcurrencyMode string `envconfig:"TRANSFER_CONCURRENCY_MODE" default:"pessimistic"`
+
+	// LockStrategy is "row" (SELECT ... FOR UPDATE) or "advisory" (pg_advisory_xact_lock
+	// per account, with version checked updates). Only used in pessimistic mode.
+	LockStrategy string `envconfig:"TRANSFER_LOCK_STRATEGY" default:"row"`
+
+	// RetryOnCommitFailure retries the whole transfer when COMMIT fails with a
+	// serialization failure or deadlock. Other commit failures are never retried.
+	RetryOnCommitFailure bool `envconfig:"TRANSFER_RETRY_ON_COMMIT_FAILURE" default:"true"`
+
+	// RetryUnseenAccounts retries account-not-found while the request's consistency token
+	// is ahead of what the database can see.
+	RetryUnseenAccounts bool `envconfig:"TRANSFER_RETRY_UNSEEN_ACCOUNTS" default:"true"`
+
+	// EffectiveDateMaxPastDays and EffectiveDateMaxFutureDays bound a requested
+	// effective_date relative to today (UTC).
+	EffectiveDateMaxPastDays   int `envconfig:"TRANSFER_EFFECTIVE_DATE_MAX_PAST_DAYS" default:"30"`
+	EffectiveDateMaxFutureDays int `envconfig:"TRANSFER_EFFECTIVE_DATE_MAX_FUTURE_DAYS" default:"0"`
+
+	// ForbidZeroBalanceTypes is a comma-separated list of account types that transfers
+	// and withdrawals may not leave at exactly zero. Empty allows zero for all types.
+	ForbidZeroBalanceTypes []string `envconfig:"TRANSFER_FORBID_ZERO_BALANCE_TYPES"`
+
+	// SourceCooldown is the minimum time between two outbound transfers from the same
+	// account. Zero disables it.
+	SourceCooldown time.Duration `envconfig:"TRANSFER_SOURCE_COOLDOWN" default:"0"`
+
+	// StuckThreshold is how long a transfer may run before a warning is logged while it
+	// is still in progress. Zero disables the warning.
+	StuckThreshold time.Duration `envconfig:"TRANSFER_STUCK_THRESHOLD" default:"5s"`
+
+	// SlowThreshold is how long a finished transfer may have taken, retries included,
+	// before a warning is logged. Zero disables the warning.
+	SlowThreshold time.Duration `envconfig:"SLOW_TRANSFER_THRESHOLD" default:"500ms"`
+
+	// MinAmount and MaxAmount are decimal bounds on a single transfer's amount, inclusive.
+	// Zero disables a bound.
+	MinAmount string `envconfig:"TRANSFER_MIN_AMOUNT" default:"0"`
+	MaxAmount string `envconfig:"TRANSFER_MAX_AMOUNT" default:"0"`
+
+	// DailyAmountLimit (a decimal) and DailyCountLimit cap what one account may send per
+	// UTC day. Zero disables a limit; accounts may override either in the database.
+	DailyAmountLimit string `envconfig:"TRANSFER_DAILY_AMOUNT_LIMIT" default:"0"`
+	DailyCountLimit  int    `envconfig:"TRANSFER_DAILY_COUNT_LIMIT" default:"0"`
+
+	// FeeAccountID is the account transfer fees are credited to. Zero disables fees.
+	FeeAccountID int64 `envconfig:"TRANSFER_FEE_ACCOUNT_ID" default:"0"`
+
+	// FeeFlat, FeePercent, FeeMin, and FeeMax are decimal amounts pricing each fee: flat
+	// plus percent of the amount, clamped to [min, max]. An empty or zero max is uncapped.
+	FeeFlat    string `envconfig:"TRANSFER_FEE_FLAT" default:"0"`
+	FeePercent string `envconfig:"TRANSFER_FEE_PERCENT" default:"0"`
+	FeeMin     string `envconfig:"TRANSFER_FEE_MIN" default:"0"`
+	FeeMax     string `envconfig:"TRANSFER_FEE_MAX" default:"0"`
+
+	// FeePaidBy is "source" (fee added to the debit) or "destination" (fee taken out of
+	// the credit).
+	FeePaidBy string `envconfig:"TRANSFER_FEE_PAID_BY" default:"source"`
+
+	// RecurringPollInterval is how often this instance looks for due recurring transfers.
+	// Zero disables its scheduler; schedules can still be created and canceled.
+	RecurringPollInterval time.Duration `envconfig:"TRANSFER_RECURRING_POLL_INTERVAL" default:"1m"`
+
+	// RecurringCatchUp is "once" (a single run covers every slot missed while no scheduler
+	// ran) or "all" (every missed slot is run).
+	RecurringCatchUp string `envconfig:"TRANSFER_RECURRING_CATCH_UP" default:"once"`
+
+	// RecurringBatchSize bounds the schedules run per poll.
+	RecurringBatchSize int `envconfig:"TRANSFER_RECURRING_BATCH_SIZE" default:"100"`
+
+	// BlackoutWindows is a comma-separated list of daily "HH:MM-HH:MM" windows during
+	// which transfers are refused, e.g. "23:55-00:05". Empty disables blackouts.
+	BlackoutWindows []string `envconfig:"TRANSFER_BLACKOUT_WINDOWS"`
+
+	// BlackoutTimezone is the IANA time zone BlackoutWindows are read in.
+	BlackoutTimezone string `envconfig:"TRANSFER_BLACKOUT_TIMEZONE" default:"UTC"`
+}
+
+// AccountConfig holds account creation settings.
+type AccountConfig struct {
+	// InitialBalanceWarnThreshold is a decimal amount above which a new account's initial
+	// balance is accepted with a warning in the response and a warn log. Empty disables.
+	InitialBalanceWarnThreshold string `envconfig:"ACCOUNT_INITIAL_BALANCE_WARN_THRESHOLD"`
+
+	// IDValidator checks the account IDs clients choose: "positive" accepts any positive
+	// integer, and "luhn" also requires the last digit to be a Luhn (mod 10) check digit.
+	// Server-generated IDs are not checked.
+	IDValidator string `envconfig:"ACCOUNT_ID_VALIDATOR" default:"positive"`
+}
+
+// PaginationConfig holds per-endpoint page size limits.
+type PaginationConfig struct {
+	DefaultSize int `envconfig:"PAGE_DEFAULT_SIZE" default:"20"`
+	MaxListing  int `envconfig:"PAGE_MAX_LISTING" default:"100"`
+	MaxBatchGet int `envconfig:"PAGE_MAX_BATCH_GET" default:"100"`
+	MaxExport   int `envconfig:"PAGE_MAX_EXPORT" default:"500"`
+}
+
+// MoneyConfig holds the precision policy for monetary amounts.
+type MoneyConfig struct {
+	// MaxScale is the most decimal places an amount may have (0-18). Amounts with more are
+	// rejected, never rounded.
+	MaxScale int `envconfig:"MONEY_MAX_SCALE" default:"2"`
+
+	// MinorUnits is the number of decimal places of the accounts' currency, e.g. 2 for
+	// USD or 0 for JPY (0-18). Responses pad amounts to it; -1 shows them as stored.
+	MinorUnits int `envconfig:"MONEY_MINOR_UNITS" default:"-1"`
+
+	// Currency is the ISO 4217 code of the accounts' currency, reported with amounts
+	// when a client asks for them as objects.
+	Currency string `envconfig:"MONEY_CURRENCY" default:"USD"`
+}
+
+// isCurrencyCode reports whether code has the shape of an ISO 4217 code: three
+// uppercase letters.
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// TracingConfig holds OpenTelemetry trace export configuration.
+type TracingConfig struct {
+	// Enabled exports spans to an OTLP/HTTP collector at Endpoint (host:port).
+	Enabled  bool   `envconfig:"TRACING_ENABLED" default:"false"`
+	Endpoint string `envconfig:"TRACING_OTLP_ENDPOINT" default:"localhost:4318"`
+	Insecure bool   `envconfig:"TRACING_OTLP_INSECURE" default:"true"`
+
+	ServiceName string `envconfig:"TRACING_SERVICE_NAME" default:"internal-transfers-system"`
+
+	// SampleRatio is the fraction of new traces recorded (0-1). Requests arriving with a
+	// sampled traceparent are always recorded.
+	SampleRatio float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+// MetricsConfig selects where the service's metrics go.
+type MetricsConfig struct {
+	// Backend is "prometheus" (served at /metrics), "statsd" (pushed over UDP to
+	// StatsDAddr), or "none".
+	Backend string `envconfig:"METRICS_BACKEND" default:"prometheus"`
+
+	StatsDAddr   string `envconfig:"METRICS_STATSD_ADDR" default:"localhost:8125"`
+	StatsDPrefix string `envconfig:"METRICS_STATSD_PREFIX" default:"transfers"`
+}
+
+// Metrics backends accepted in METRICS_BACKEND.
+const (
+	MetricsBackendPrometheus = "prometheus"
+	MetricsBackendStatsD     = "statsd"
+	MetricsBackendNone       = "none"
+)
+
+// WebhookConfig holds completed-transfer webhook configuration.
+type WebhookConfig struct {
+	// URLs is a comma-separated list of endpoints POSTed an event for every completed
+	// transfer. Empty disables webhooks. Secret signs each body and is required with URLs.
+	URLs   []string `envconfig:"WEBHOOK_URLS"`
+	Secret string   `envconfig:"WEBHOOK_SECRET"`
+
+	QueueSize      int           `envconfig:"WEBHOOK_QUEUE_SIZE" default:"1000"`
+	MaxAttempts    int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"5"`
+	RetryBaseDelay time.Duration `envconfig:"WEBHOOK_RETRY_BASE_DELAY" default:"500ms"`
+	Timeout        time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"5s"`
+}
+
 // LogConfig holds logging configuration.
 type LogConfig struct {
 	Level  string `envconfig:"LOG_LEVEL" default:"info"`
 	Format string `envconfig:"LOG_FORMAT" default:"json"` // json or console
+
+	// SampleRate is the fraction of successful requests given a request log line, from
+	// 0 to 1; 4xx and 5xx responses are always logged. SkipPaths are never logged.
+	SampleRate float64  `envconfig:"LOG_SAMPLE_RATE" default:"1"`
+	SkipPaths  []string `envconfig:"LOG_SKIP_PATHS" default:"/health,/ready"`
+
+	// SlowRequestThreshold is how long a request may take before its log line is a
+	// warning. Slow requests are logged whatever SampleRate says. Zero disables it.
+	SlowRequestThreshold time.Duration `envconfig:"LOG_SLOW_REQUEST_THRESHOLD" default:"1s"`
 }
 
 // Load loads configuration from environment variables.
@@ -77,14 +451,298 @@ func Load() (*Config, error) {
 	if err := envconfig.Process("", &cfg.Server); err != nil {
 		return nil, fmt.Errorf("loading server config: %w", err)
 	}
+	if cfg.Server.RequestTimeout < 0 {
+		return nil, fmt.Errorf("loading server config: SERVER_REQUEST_TIMEOUT must not be negative")
+	}
+	if cfg.Server.MaxRequestBody <= 0 {
+		return nil, fmt.Errorf("loading server config: SERVER_MAX_REQUEST_BODY must be positive")
+	}
+	if cfg.Server.RouteMetricsEnabled && cfg.Server.RouteMetricsInterval <= 0 {
+		return nil, fmt.Errorf("loading server config: SERVER_ROUTE_METRICS_INTERVAL must be positive when SERVER_ROUTE_METRICS_ENABLED is set")
+	}
+	switch cfg.Server.IDEncoding {
+	case IDEncodingRaw:
+	case IDEncodingOpaque:
+		if len(cfg.Server.OpaqueIDSalt) < minOpaqueIDSaltLength {
+			return nil, fmt.Errorf("loading server config: SERVER_OPAQUE_ID_SALT must be at least %d characters when SERVER_ID_ENCODING=opaque", minOpaqueIDSaltLength)
+		}
+	default:
+		return nil, fmt.Errorf("loading server config: SERVER_ID_ENCODING %q must be raw or opaque", cfg.Server.IDEncoding)
+	}
+	if cfg.Server.GRPCPort < 0 || cfg.Server.GRPCPort > 65535 {
+		return nil, fmt.Errorf("loading server config: SERVER_GRPC_PORT must be between 0 and 65535, got %d", cfg.Server.GRPCPort)
+	}
+	if cfg.Server.GRPCPort != 0 && cfg.Server.GRPCPort == cfg.Server.Port {
+		return nil, fmt.Errorf("loading server config: SERVER_GRPC_PORT must differ from SERVER_PORT")
+	}
+	if cfg.Server.GRPCPort != 0 && cfg.Server.IDEncoding == IDEncodingOpaque {
+		// The gRPC messages carry transaction IDs as integers, which would expose the raw IDs
+		return nil, fmt.Errorf("loading server config: SERVER_GRPC_PORT cannot be set with SERVER_ID_ENCODING=opaque")
+	}
+	if (cfg.Server.TLSCertFile == "") != (cfg.Server.TLSKeyFile == "") {
+		return nil, fmt.Errorf("loading server config: SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE must be set together")
+	}
+	if cfg.Server.TLSClientCAFile != "" && !cfg.Server.TLSEnabled() {
+		return nil, fmt.Errorf("loading server config: SERVER_TLS_CLIENT_CA_FILE requires SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE")
+	}
 
 	if err := envconfig.Process("", &cfg.Database); err != nil {
 		return nil, fmt.Errorf("loading database config: %w", err)
 	}
+	if d := cfg.Database.Driver; d != DBDriverPostgres && d != DBDriverMemory {
+		return nil, fmt.Errorf("loading database config: DB_DRIVER %q must be postgres or memory", d)
+	}
+	level, err := normalizeIsolationLevel(cfg.Database.IsolationLevel)
+	if err != nil {
+		return nil, fmt.Errorf("loading database config: %w", err)
+	}
+	cfg.Database.IsolationLevel = level
+	if cfg.Database.ReadMaxConns < 0 || cfg.Database.ExportMaxConns < 0 {
+		return nil, fmt.Errorf("loading database config: DB_READ_MAX_CONNS and DB_EXPORT_MAX_CONNS cannot be negative")
+	}
+	if cfg.Database.MaxConns <= 0 {
+		return nil, fmt.Errorf("loading database config: DB_MAX_CONNS must be positive")
+	}
+	if cfg.Database.ReplicaHost != "" && cfg.Database.ReplicaMaxConns <= 0 {
+		return nil, fmt.Errorf("loading database config: DB_REPLICA_MAX_CONNS must be positive when DB_REPLICA_HOST is set")
+	}
+	if cfg.Database.ReplicaPort < 0 {
+		return nil, fmt.Errorf("loading database config: DB_REPLICA_PORT must not be negative")
+	}
+	if cfg.Database.MinConns < 0 || cfg.Database.MinConns > cfg.Database.MaxConns {
+		return nil, fmt.Errorf("loading database config: DB_MIN_CONNS must be between 0 and DB_MAX_CONNS")
+	}
+	if cfg.Database.MaxConnLifetime <= 0 || cfg.Database.MaxConnIdleTime <= 0 || cfg.Database.HealthCheckPeriod <= 0 {
+		return nil, fmt.Errorf("loading database config: DB_MAX_CONN_LIFETIME, DB_MAX_CONN_IDLE_TIME, and DB_HEALTH_CHECK_PERIOD must be positive")
+	}
+	if cfg.Database.AcquireTimeout < 0 {
+		return nil, fmt.Errorf("loading database config: DB_ACQUIRE_TIMEOUT must not be negative")
+	}
+
+	if err := envconfig.Process("", &cfg.Transfer); err != nil {
+		return nil, fmt.Errorf("loading transfer config: %w", err)
+	}
+	if m := cfg.Transfer.ConcurrencyMode; m != "pessimistic" && m != "optimistic" {
+		return nil, fmt.Errorf("loading transfer config: TRANSFER_CONCURRENCY_MODE %q must be pessimistic or optimistic", m)
+	}
+	if s := cfg.Transfer.LockStrategy; s != "row" && s != "advisory" {
+		return nil, fmt.Errorf("loading transfer config: TRANSFER_LOCK_STRATEGY %q must be row or advisory", s)
+	}
+	if cfg.Transfer.LockStrategy == "advisory" && cfg.Transfer.ConcurrencyMode != "pessimistic" {
+		return nil, fmt.Errorf("loading transfer config: TRANSFER_LOCK_STRATEGY=advisory requires TRANSFER_CONCURRENCY_MODE=pessimistic")
+	}
+	if cfg.Transfer.SourceCooldown < 0 {
+		return nil, fmt.Errorf("loading transfer config: TRANSFER_SOURCE_COOLDOWN must not be negative")
+	}
+	if cfg.Transfer.StuckThreshold < 0 {
+		return nil, fmt.Errorf("loading transfer config: TRANSFER_STUCK_THRESHOLD must not be negative")
+	}
+	if cfg.Transfer.SlowThreshold < 0 {
+		return nil, fmt.Errorf("loading transfer config: SLOW_TRANSFER_THRESHOLD must not be negative")
+	}
+	if cfg.Transfer.RecurringPollInterval < 0 {
+		return nil, fmt.Errorf("loading transfer config: TRANSFER_RECURRING_POLL_INTERVAL must not be negative")
+	}
+	if c := cfg.Transfer.RecurringCatchUp; c != "once" && c != "all" {
+		return nil, fmt.Errorf("loading transfer config: TRANSFER_RECURRING_CATCH_UP %q must be once or all", c)
+	}
+	if cfg.Transfer.RecurringBatchSize <= 0 {
+		return nil, fmt.Errorf("loading transfer config: TRANSFER_RECURRING_BATCH_SIZE must be positive")
+	}
+	if err := validateTransferLimits(&cfg.Transfer); err != nil {
+		return nil, fmt.Errorf("loading transfer config: %w", err)
+	}
+	if err := validateFees(&cfg.Transfer); err != nil {
+		return nil, fmt.Errorf("loading transfer config: %w", err)
+	}
+	if err := validateBlackout(&cfg.Transfer); err != nil {
+		return nil, fmt.Errorf("loading transfer config: %w", err)
+	}
+
+	if err := envconfig.Process("", &cfg.Account); err != nil {
+		return nil, fmt.Errorf("loading account config: %w", err)
+	}
+	if t := cfg.Account.InitialBalanceWarnThreshold; t != "" {
+		if d, err := decimal.NewFromString(t); err != nil || d.IsNegative() {
+			return nil, fmt.Errorf("loading account config: ACCOUNT_INITIAL_BALANCE_WARN_THRESHOLD %q is not a non-negative decimal", t)
+		}
+	}
+	if v := cfg.Account.IDValidator; v != "positive" && v != "luhn" {
+		return nil, fmt.Errorf("loading account config: ACCOUNT_ID_VALIDATOR %q must be positive or luhn", v)
+	}
+
+	if err := envconfig.Process("", &cfg.Pagination); err != nil {
+		return nil, fmt.Errorf("loading pagination config: %w", err)
+	}
+	if p := cfg.Pagination; p.DefaultSize <= 0 || p.MaxListing <= 0 || p.MaxBatchGet <= 0 || p.MaxExport <= 0 {
+		return nil, fmt.Errorf("loading pagination config: PAGE_DEFAULT_SIZE, PAGE_MAX_LISTING, PAGE_MAX_BATCH_GET, and PAGE_MAX_EXPORT must be positive")
+	}
+	if cfg.Pagination.DefaultSize > cfg.Pagination.MaxListing {
+		return nil, fmt.Errorf("loading pagination config: PAGE_DEFAULT_SIZE must not exceed PAGE_MAX_LISTING")
+	}
+
+	if err := envconfig.Process("", &cfg.Money); err != nil {
+		return nil, fmt.Errorf("loading money config: %w", err)
+	}
+	if cfg.Money.MaxScale < 0 || cfg.Money.MaxScale > 18 {
+		return nil, fmt.Errorf("loading money config: MONEY_MAX_SCALE must be between 0 and 18, got %d", cfg.Money.MaxScale)
+	}
+	if cfg.Money.MinorUnits < -1 || cfg.Money.MinorUnits > 18 {
+		return nil, fmt.Errorf("loading money config: MONEY_MINOR_UNITS must be between 0 and 18, or -1 to disable, got %d", cfg.Money.MinorUnits)
+	}
+	if !isCurrencyCode(cfg.Money.Currency) {
+		return nil, fmt.Errorf("loading money config: MONEY_CURRENCY %q must be a three-letter ISO 4217 code such as USD", cfg.Money.Currency)
+	}
+
+	if err := envconfig.Process("", &cfg.Tracing); err != nil {
+		return nil, fmt.Errorf("loading tracing config: %w", err)
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		return nil, fmt.Errorf("loading tracing config: TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", cfg.Tracing.SampleRatio)
+	}
+
+	if err := envconfig.Process("", &cfg.Metrics); err != nil {
+		return nil, fmt.Errorf("loading metrics config: %w", err)
+	}
+	switch cfg.Metrics.Backend {
+	case MetricsBackendPrometheus, MetricsBackendNone:
+	case MetricsBackendStatsD:
+		if _, _, err := net.SplitHostPort(cfg.Metrics.StatsDAddr); err != nil {
+			return nil, fmt.Errorf("loading metrics config: METRICS_STATSD_ADDR %q must be host:port", cfg.Metrics.StatsDAddr)
+		}
+	default:
+		return nil, fmt.Errorf("loading metrics config: METRICS_BACKEND %q must be prometheus, statsd, or none", cfg.Metrics.Backend)
+	}
+
+	if err := envconfig.Process("", &cfg.Webhook); err != nil {
+		return nil, fmt.Errorf("loading webhook config: %w", err)
+	}
+	if err := validateWebhooks(&cfg.Webhook); err != nil {
+		return nil, fmt.Errorf("loading webhook config: %w", err)
+	}
 
 	if err := envconfig.Process("", &cfg.Log); err != nil {
 		return nil, fmt.Errorf("loading log config: %w", err)
 	}
+	if cfg.Log.SampleRate < 0 || cfg.Log.SampleRate > 1 {
+		return nil, fmt.Errorf("loading log config: LOG_SAMPLE_RATE must be between 0 and 1")
+	}
+	if cfg.Log.SlowRequestThreshold < 0 {
+		return nil, fmt.Errorf("loading log config: LOG_SLOW_REQUEST_THRESHOLD must not be negative")
+	}
 
 	return &cfg, nil
 }
+
+// validateTransferLimits checks both transfer amount bounds are non-negative decimals and,
+// when both are set, that the max is no lower than the min, and that neither daily limit
+// is negative.
+func validateTransferLimits(t *TransferConfig) error {
+	parse := func(name, value string) (decimal.Decimal, error) {
+		if value == "" {
+			value = "0"
+		}
+		d, err := decimal.NewFromString(value)
+		if err != nil || d.IsNegative() {
+			return decimal.Decimal{}, fmt.Errorf("%s %q is not a non-negative decimal", name, value)
+		}
+		return d, nil
+	}
+	lower, err := parse("TRANSFER_MIN_AMOUNT", t.MinAmount)
+	if err != nil {
+		return err
+	}
+	upper, err := parse("TRANSFER_MAX_AMOUNT", t.MaxAmount)
+	if err != nil {
+		return err
+	}
+	if upper.IsPositive() && upper.LessThan(lower) {
+		return fmt.Errorf("TRANSFER_MAX_AMOUNT must not be less than TRANSFER_MIN_AMOUNT")
+	}
+	if _, err := parse("TRANSFER_DAILY_AMOUNT_LIMIT", t.DailyAmountLimit); err != nil {
+		return err
+	}
+	if t.DailyCountLimit < 0 {
+		return fmt.Errorf("TRANSFER_DAILY_COUNT_LIMIT must not be negative")
+	}
+	return nil
+}
+
+// validateFees checks the transfer fee settings: every amount a non-negative decimal, a
+// percentage of at most 100, a max no lower than the min, and a known payer.
+func validateFees(t *TransferConfig) error {
+	if t.FeeAccountID < 0 {
+		return fmt.Errorf("TRANSFER_FEE_ACCOUNT_ID must not be negative")
+	}
+	amounts := map[string]string{
+		"TRANSFER_FEE_FLAT":    t.FeeFlat,
+		"TRANSFER_FEE_PERCENT": t.FeePercent,
+		"TRANSFER_FEE_MIN":     t.FeeMin,
+		"TRANSFER_FEE_MAX":     t.FeeMax,
+	}
+	parsed := make(map[string]decimal.Decimal, len(amounts))
+	for name, value := range amounts {
+		if value == "" {
+			value = "0"
+		}
+		d, err := decimal.NewFromString(value)
+		if err != nil || d.IsNegative() {
+			return fmt.Errorf("%s %q is not a non-negative decimal", name, value)
+		}
+		parsed[name] = d
+	}
+	if parsed["TRANSFER_FEE_PERCENT"].GreaterThan(decimal.NewFromInt(100)) {
+		return fmt.Errorf("TRANSFER_FEE_PERCENT must be at most 100")
+	}
+	if max := parsed["TRANSFER_FEE_MAX"]; max.IsPositive() && max.LessThan(parsed["TRANSFER_FEE_MIN"]) {
+		return fmt.Errorf("TRANSFER_FEE_MAX must not be less than TRANSFER_FEE_MIN")
+	}
+	if t.FeePaidBy != "source" && t.FeePaidBy != "destination" {
+		return fmt.Errorf("TRANSFER_FEE_PAID_BY %q must be source or destination", t.FeePaidBy)
+	}
+	return nil
+}
+
+// validateBlackout checks the time zone loads and every window is two distinct
+// HH:MM times.
+func validateBlackout(t *TransferConfig) error {
+	if _, err := time.LoadLocation(t.BlackoutTimezone); err != nil {
+		return fmt.Errorf("TRANSFER_BLACKOUT_TIMEZONE %q is not a known time zone", t.BlackoutTimezone)
+	}
+	for _, window := range t.BlackoutWindows {
+		from, to, ok := strings.Cut(strings.TrimSpace(window), "-")
+		if !ok {
+			return fmt.Errorf("TRANSFER_BLACKOUT_WINDOWS entry %q must be HH:MM-HH:MM", window)
+		}
+		start, err1 := time.Parse("15:04", strings.TrimSpace(from))
+		end, err2 := time.Parse("15:04", strings.TrimSpace(to))
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("TRANSFER_BLACKOUT_WINDOWS entry %q must be HH:MM-HH:MM", window)
+		}
+		if start.Equal(end) {
+			return fmt.Errorf("TRANSFER_BLACKOUT_WINDOWS entry %q is empty", window)
+		}
+	}
+	return nil
+}
+
+// validateWebhooks checks that every webhook URL is absolute http(s), that a secret is
+// set when there are URLs, and that the delivery settings are positive.
+func validateWebhooks(w *WebhookConfig) error {
+	if len(w.URLs) == 0 {
+		return nil
+	}
+	for _, raw := range w.URLs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("WEBHOOK_URLS entry %q must be an absolute http or https URL", raw)
+		}
+	}
+	if w.Secret == "" {
+		return fmt.Errorf("WEBHOOK_SECRET is required when WEBHOOK_URLS is set")
+	}
+	if w.QueueSize <= 0 || w.MaxAttempts <= 0 || w.RetryBaseDelay <= 0 || w.Timeout <= 0 {
+		return fmt.Errorf("WEBHOOK_QUEUE_SIZE, WEBHOOK_MAX_ATTEMPTS, WEBHOOK_RETRY_BASE_DELAY, and WEBHOOK_TIMEOUT must be positive")
+	}
+	return nil
+}
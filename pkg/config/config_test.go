@@ -0,0 +1,357 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_PoolConfig(t *testing.T) {
+	t.Setenv("DB_MAX_CONNS", "20")
+	t.Setenv("DB_MIN_CONNS", "4")
+	t.Setenv("DB_MAX_CONN_LIFETIME", "45m")
+	t.Setenv("DB_MAX_CONN_IDLE_TIME", "5m")
+	t.Setenv("DB_HEALTH_CHECK_PERIOD", "15s")
+	t.Setenv("DB_TIMEOUT", "3s")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	pool, err := cfg.Database.PoolConfig(cfg.Database.MaxConns)
+	if err != nil {
+		t.Fatalf("pool config: %v", err)
+	}
+	if pool.MaxConns != 20 || pool.MinConns != 4 {
+		t.Errorf("expected 4-20 connections, got %d-%d", pool.MinConns, pool.MaxConns)
+	}
+	if pool.MaxConnLifetime != 45*time.Minute || pool.MaxConnIdleTime != 5*time.Minute || pool.HealthCheckPeriod != 15*time.Second {
+		t.Errorf("unexpected lifetimes: lifetime %s, idle %s, health check %s", pool.MaxConnLifetime, pool.MaxConnIdleTime, pool.HealthCheckPeriod)
+	}
+	if pool.ConnConfig.ConnectTimeout != 3*time.Second {
+		t.Errorf("expected a 3s connect timeout, got %s", pool.ConnConfig.ConnectTimeout)
+	}
+
+	// A smaller pool, such as the export pool, can't be asked to hold more than it allows
+	small, err := cfg.Database.PoolConfig(2)
+	if err != nil {
+		t.Fatalf("pool config: %v", err)
+	}
+	if small.MaxConns != 2 || small.MinConns != 2 {
+		t.Errorf("expected MinConns capped at 2, got %d-%d", small.MinConns, small.MaxConns)
+	}
+}
+
+func TestLoad_PoolConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name, env, value, want string
+	}{
+		{"min above max", "DB_MIN_CONNS", "11", "DB_MIN_CONNS"},
+		{"negative min", "DB_MIN_CONNS", "-1", "DB_MIN_CONNS"},
+		{"zero lifetime", "DB_MAX_CONN_LIFETIME", "0", "DB_MAX_CONN_LIFETIME"},
+		{"zero health check", "DB_HEALTH_CHECK_PERIOD", "0s", "DB_HEALTH_CHECK_PERIOD"},
+		{"negative acquire timeout", "DB_ACQUIRE_TIMEOUT", "-1s", "DB_ACQUIRE_TIMEOUT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DB_MAX_CONNS", "10")
+			t.Setenv(tt.env, tt.value)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error naming %s, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoad_Replica(t *testing.T) {
+	t.Setenv("DB_HOST", "primary")
+	t.Setenv("DB_PORT", "5432")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if _, ok := cfg.Database.Replica(); ok || cfg.Database.ReplicaDSN() != "" {
+		t.Error("expected no replica by default")
+	}
+
+	t.Setenv("DB_REPLICA_HOST", "replica")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	replica, ok := cfg.Database.Replica()
+	if !ok || replica.Host != "replica" || replica.Port != 5432 || replica.Database != cfg.Database.Database {
+		t.Errorf("expected the replica on the primary's port and database, got %+v", replica)
+	}
+	if !strings.Contains(cfg.Database.ReplicaDSN(), "@replica:5432/") || !strings.Contains(cfg.Database.DSN(), "@primary:5432/") {
+		t.Errorf("unexpected DSNs %q and %q", cfg.Database.DSN(), cfg.Database.ReplicaDSN())
+	}
+
+	t.Setenv("DB_REPLICA_PORT", "6432")
+	cfg, _ = Load()
+	if replica, _ := cfg.Database.Replica(); replica.Port != 6432 {
+		t.Errorf("expected replica port 6432, got %d", replica.Port)
+	}
+
+	t.Setenv("DB_REPLICA_MAX_CONNS", "0")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "DB_REPLICA_MAX_CONNS") {
+		t.Errorf("expected an error naming DB_REPLICA_MAX_CONNS, got %v", err)
+	}
+}
+
+func TestLoad_MoneyMinorUnits(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Money.MinorUnits != -1 {
+		t.Errorf("expected amounts shown as stored by default, got minor units %d", cfg.Money.MinorUnits)
+	}
+
+	t.Setenv("MONEY_MINOR_UNITS", "0")
+	if cfg, err := Load(); err != nil || cfg.Money.MinorUnits != 0 {
+		t.Errorf("expected minor units 0, got %v", err)
+	}
+
+	t.Setenv("MONEY_MINOR_UNITS", "-2")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "MONEY_MINOR_UNITS") {
+		t.Errorf("expected an error naming MONEY_MINOR_UNITS, got %v", err)
+	}
+}
+
+func TestLoad_MoneyCurrency(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Money.Currency != "USD" {
+		t.Errorf("expected USD by default, got %q", cfg.Money.Currency)
+	}
+
+	t.Setenv("MONEY_CURRENCY", "JPY")
+	if cfg, err := Load(); err != nil || cfg.Money.Currency != "JPY" {
+		t.Errorf("expected JPY, got %v", err)
+	}
+
+	for _, invalid := range []string{"usd", "US", "EURO", "U$D"} {
+		t.Setenv("MONEY_CURRENCY", invalid)
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "MONEY_CURRENCY") {
+			t.Errorf("expected %q to be rejected, got %v", invalid, err)
+		}
+	}
+}
+
+func TestLoad_GRPCPort(t *testing.T) {
+	t.Setenv("SERVER_GRPC_PORT", "9090")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := cfg.Server.GRPCAddress(); got != "0.0.0.0:9090" {
+		t.Errorf("expected gRPC address 0.0.0.0:9090, got %s", got)
+	}
+
+	t.Setenv("SERVER_GRPC_PORT", "8080")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SERVER_GRPC_PORT") {
+		t.Errorf("expected an error for a gRPC port shared with HTTP, got %v", err)
+	}
+
+	t.Setenv("SERVER_GRPC_PORT", "9090")
+	t.Setenv("SERVER_ID_ENCODING", "opaque")
+	t.Setenv("SERVER_OPAQUE_ID_SALT", "0123456789abcdef")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SERVER_ID_ENCODING=opaque") {
+		t.Errorf("expected an error for gRPC with opaque IDs, got %v", err)
+	}
+}
+
+func TestLoad_TransferLimits(t *testing.T) {
+	t.Setenv("TRANSFER_MIN_AMOUNT", "0.50")
+	t.Setenv("TRANSFER_MAX_AMOUNT", "10000")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Transfer.MinAmount != "0.50" || cfg.Transfer.MaxAmount != "10000" {
+		t.Errorf("expected limits 0.50 and 10000, got %q and %q", cfg.Transfer.MinAmount, cfg.Transfer.MaxAmount)
+	}
+
+	t.Setenv("TRANSFER_MAX_AMOUNT", "0.25")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "TRANSFER_MAX_AMOUNT") {
+		t.Errorf("expected an error for a max below the min, got %v", err)
+	}
+
+	t.Setenv("TRANSFER_MAX_AMOUNT", "0")
+	t.Setenv("TRANSFER_MIN_AMOUNT", "-1")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "TRANSFER_MIN_AMOUNT") {
+		t.Errorf("expected an error for a negative min, got %v", err)
+	}
+
+	t.Setenv("TRANSFER_MIN_AMOUNT", "0")
+	t.Setenv("TRANSFER_DAILY_COUNT_LIMIT", "-1")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "TRANSFER_DAILY_COUNT_LIMIT") {
+		t.Errorf("expected an error for a negative daily count limit, got %v", err)
+	}
+}
+
+func TestLoad_TLS(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Server.TLSEnabled() {
+		t.Error("expected plain HTTP by default")
+	}
+
+	t.Setenv("SERVER_TLS_CERT_FILE", "/etc/tls/server.pem")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SERVER_TLS_KEY_FILE") {
+		t.Errorf("expected an error for a certificate without a key, got %v", err)
+	}
+
+	t.Setenv("SERVER_TLS_KEY_FILE", "/etc/tls/server-key.pem")
+	if cfg, err := Load(); err != nil || !cfg.Server.TLSEnabled() {
+		t.Errorf("expected TLS enabled, got %v", err)
+	}
+
+	t.Setenv("SERVER_TLS_CERT_FILE", "")
+	t.Setenv("SERVER_TLS_KEY_FILE", "")
+	t.Setenv("SERVER_TLS_CLIENT_CA_FILE", "/etc/tls/ca.pem")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SERVER_TLS_CLIENT_CA_FILE") {
+		t.Errorf("expected an error for a client CA without TLS, got %v", err)
+	}
+}
+
+func TestLoad_DBDriver(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Database.Driver != DBDriverPostgres {
+		t.Errorf("expected the postgres driver by default, got %q", cfg.Database.Driver)
+	}
+
+	t.Setenv("DB_DRIVER", "memory")
+	if cfg, err := Load(); err != nil || cfg.Database.Driver != DBDriverMemory {
+		t.Errorf("expected the memory driver, got %v", err)
+	}
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "DB_DRIVER") {
+		t.Errorf("expected an error for an unknown driver, got %v", err)
+	}
+}
+
+func TestLoad_AccountIDValidator(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Account.IDValidator != "positive" {
+		t.Errorf("expected the positive validator by default, got %q", cfg.Account.IDValidator)
+	}
+
+	t.Setenv("ACCOUNT_ID_VALIDATOR", "luhn")
+	if cfg, err := Load(); err != nil || cfg.Account.IDValidator != "luhn" {
+		t.Errorf("expected the luhn validator, got %v", err)
+	}
+
+	t.Setenv("ACCOUNT_ID_VALIDATOR", "mod97")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "ACCOUNT_ID_VALIDATOR") {
+		t.Errorf("expected an error for an unknown validator, got %v", err)
+	}
+}
+
+func TestLoad_LockStrategy(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Transfer.LockStrategy != "row" {
+		t.Errorf("expected row locks by default, got %q", cfg.Transfer.LockStrategy)
+	}
+
+	t.Setenv("TRANSFER_LOCK_STRATEGY", "advisory")
+	if cfg, err := Load(); err != nil || cfg.Transfer.LockStrategy != "advisory" {
+		t.Errorf("expected advisory locks, got %v", err)
+	}
+
+	t.Setenv("TRANSFER_CONCURRENCY_MODE", "optimistic")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "TRANSFER_LOCK_STRATEGY") {
+		t.Errorf("expected advisory locks to be rejected in optimistic mode, got %v", err)
+	}
+
+	t.Setenv("TRANSFER_CONCURRENCY_MODE", "pessimistic")
+	t.Setenv("TRANSFER_LOCK_STRATEGY", "table")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "TRANSFER_LOCK_STRATEGY") {
+		t.Errorf("expected an error for an unknown strategy, got %v", err)
+	}
+}
+
+func TestLoad_SlowThresholds(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Transfer.SlowThreshold != 500*time.Millisecond || cfg.Log.SlowRequestThreshold != time.Second {
+		t.Errorf("expected 500ms and 1s defaults, got %s and %s", cfg.Transfer.SlowThreshold, cfg.Log.SlowRequestThreshold)
+	}
+
+	t.Setenv("SLOW_TRANSFER_THRESHOLD", "-1s")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SLOW_TRANSFER_THRESHOLD") {
+		t.Errorf("expected a negative transfer threshold to be rejected, got %v", err)
+	}
+
+	t.Setenv("SLOW_TRANSFER_THRESHOLD", "0")
+	t.Setenv("LOG_SLOW_REQUEST_THRESHOLD", "-1s")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "LOG_SLOW_REQUEST_THRESHOLD") {
+		t.Errorf("expected a negative request threshold to be rejected, got %v", err)
+	}
+}
+
+func TestLoad_PaginationInvalid(t *testing.T) {
+	tests := []struct {
+		name, env, value, want string
+	}{
+		{"zero default size", "PAGE_DEFAULT_SIZE", "0", "must be positive"},
+		{"zero listing max", "PAGE_MAX_LISTING", "0", "must be positive"},
+		{"zero batch get max", "PAGE_MAX_BATCH_GET", "0", "must be positive"},
+		{"negative export size", "PAGE_MAX_EXPORT", "-1", "must be positive"},
+		{"default above listing max", "PAGE_DEFAULT_SIZE", "101", "PAGE_DEFAULT_SIZE must not exceed PAGE_MAX_LISTING"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoad_RouteMetricsInterval(t *testing.T) {
+	tests := []struct {
+		name, enabled, interval string
+		wantErr                 bool
+	}{
+		{"enabled with default interval", "true", "", false},
+		{"enabled with zero interval", "true", "0s", true},
+		{"enabled with negative interval", "true", "-1m", true},
+		{"disabled with zero interval", "false", "0s", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SERVER_ROUTE_METRICS_ENABLED", tt.enabled)
+			if tt.interval != "" {
+				t.Setenv("SERVER_ROUTE_METRICS_INTERVAL", tt.interval)
+			}
+			_, err := Load()
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "SERVER_ROUTE_METRICS_INTERVAL")) {
+				t.Errorf("expected an error naming SERVER_ROUTE_METRICS_INTERVAL, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}